@@ -8,6 +8,6 @@ import (
 
 func main() {
 	if err := cli.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cli.ExitStatus(err))
 	}
 }
@@ -2,12 +2,17 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/transcript"
 )
 
 // Task 4.1: Focused tests for API methods with new SDK client
@@ -41,7 +46,7 @@ func TestDetectModes_WithSDKClient(t *testing.T) {
 	var result *review.DetectionResult
 	var detectErr error
 	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
-		result, detectErr = wrapper.DetectModes(ctx, client, "diff content here")
+		result, detectErr = wrapper.DetectModes(ctx, client, diff.NewDiff("diff content here"))
 		return nil
 	})
 
@@ -126,7 +131,7 @@ func TestRunReview_WithSDKClient(t *testing.T) {
 	var result *review.Result
 	var reviewErr error
 	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
-		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeSecurity, "diff content here")
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeSecurity, diff.NewDiff("diff content here"))
 		return nil
 	})
 
@@ -196,7 +201,7 @@ func TestRunReview_NoIssues(t *testing.T) {
 	var result *review.Result
 	var reviewErr error
 	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
-		result, reviewErr = wrapper.RunReview(ctx, client, review.ModePerformance, "diff content here")
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModePerformance, diff.NewDiff("diff content here"))
 		return nil
 	})
 
@@ -216,6 +221,49 @@ func TestRunReview_NoIssues(t *testing.T) {
 	}
 }
 
+// TestRunReview_NormalizesSeverities verifies RunReview() maps non-canonical
+// severities onto high/medium/low and flags unknown ones.
+func TestRunReview_NormalizesSeverities(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	jsonResponse := `{
+		"summary": "Found issues",
+		"issues": [
+			{"severity": "critical", "description": "hardcoded secret"},
+			{"severity": "p3", "description": "odd naming"}
+		]
+	}`
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: jsonResponse},
+		},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+
+	var result *review.Result
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		var reviewErr error
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeSecurity, diff.NewDiff("diff content here"))
+		return reviewErr
+	})
+	if err != nil {
+		t.Fatalf("RunReview() error = %v, want nil", err)
+	}
+
+	if len(result.Issues) != 2 {
+		t.Fatalf("RunReview() issues count = %d, want 2", len(result.Issues))
+	}
+	if result.Issues[0].Severity != review.SeverityHigh || result.Issues[0].RawSeverity != "critical" {
+		t.Errorf("critical should be normalized to high, got %+v", result.Issues[0])
+	}
+	if !result.Issues[1].SeverityUnknown || result.Issues[1].RawSeverity != "p3" {
+		t.Errorf("unknown severity should be flagged, got %+v", result.Issues[1])
+	}
+}
+
 // TestGenerateCommitMessage_WithSDKClient verifies GenerateCommitMessage() works
 // correctly with the Claude Code SDK client.
 func TestGenerateCommitMessage_WithSDKClient(t *testing.T) {
@@ -241,7 +289,7 @@ func TestGenerateCommitMessage_WithSDKClient(t *testing.T) {
 	var result *CommitMessage
 	var commitErr error
 	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
-		result, commitErr = wrapper.GenerateCommitMessage(ctx, client, "diff content here", "")
+		result, commitErr = wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff("diff content here"), "")
 		return nil
 	})
 
@@ -299,7 +347,7 @@ func TestGenerateCommitMessage_WithContext(t *testing.T) {
 	var commitErr error
 	commitContext := "User reported auth timeouts in production"
 	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
-		result, commitErr = wrapper.GenerateCommitMessage(ctx, client, "diff content here", commitContext)
+		result, commitErr = wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff("diff content here"), commitContext)
 		return nil
 	})
 
@@ -326,22 +374,22 @@ func TestGenerateCommitMessage_WithContext(t *testing.T) {
 // correctly as an SDK-agnostic utility function.
 func TestTruncateDiff_SDKAgnostic(t *testing.T) {
 	// Small diff - should be unchanged
-	smallDiff := "diff --git a/file.go b/file.go\n+added line"
-	result := truncateDiff(smallDiff)
+	smallDiff := "diff --git a/file.go b/file.go\n+added line\n"
+	result := truncateDiff(diff.NewDiff(smallDiff), MaxDiffSize)
 	if result != smallDiff {
 		t.Errorf("truncateDiff() modified small diff unexpectedly")
 	}
 
 	// Exactly at MaxDiffSize - should be unchanged
 	atLimit := makeStringOfLength(MaxDiffSize)
-	result = truncateDiff(atLimit)
+	result = truncateDiff(diff.NewDiff(atLimit), MaxDiffSize)
 	if result != atLimit {
 		t.Errorf("truncateDiff() modified diff at exact limit")
 	}
 
 	// Over MaxDiffSize - should be truncated with marker
 	overLimit := makeStringOfLength(MaxDiffSize + 5000)
-	result = truncateDiff(overLimit)
+	result = truncateDiff(diff.NewDiff(overLimit), MaxDiffSize)
 	if len(result) > MaxDiffSize+100 { // Allow for truncation marker
 		t.Errorf("truncateDiff() result too long: %d bytes", len(result))
 	}
@@ -353,3 +401,207 @@ func TestTruncateDiff_SDKAgnostic(t *testing.T) {
 		t.Errorf("truncateDiff() should end with truncation marker")
 	}
 }
+
+// TestRunReview_CapsSuggestions verifies RunReview() keeps at most the
+// configured number of suggestions.
+func TestRunReview_CapsSuggestions(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	jsonResponse := `{
+		"summary": "Looks fine",
+		"issues": [],
+		"suggestions": ["first", "second", "third"]
+	}`
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: jsonResponse},
+		},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	wrapper.SetMaxSuggestions(2)
+
+	var result *review.Result
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		var reviewErr error
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeStyle, diff.NewDiff("diff content here"))
+		return reviewErr
+	})
+	if err != nil {
+		t.Fatalf("RunReview() error = %v, want nil", err)
+	}
+
+	if len(result.Suggestions) != 2 || result.Suggestions[1] != "second" {
+		t.Errorf("RunReview() suggestions = %v, want the first 2", result.Suggestions)
+	}
+}
+
+// TestRunReview_DropsUnconfidentIssues verifies RunReview() drops the issues
+// reported with a confidence below the configured minimum.
+func TestRunReview_DropsUnconfidentIssues(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	jsonResponse := `{
+		"summary": "Two issues",
+		"issues": [
+			{"severity": "medium", "description": "Input is not validated", "location": "api.go:10", "confidence": 0.9},
+			{"severity": "medium", "description": "Caller might pass nil", "location": "api.go:20", "confidence": 30},
+			{"severity": "low", "description": "Unclear name", "location": "api.go:30"}
+		]
+	}`
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: jsonResponse},
+		},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	wrapper.SetMinConfidence(0.5)
+
+	var result *review.Result
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		var reviewErr error
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeErrors, diff.NewDiff("diff content here"))
+		return reviewErr
+	})
+	if err != nil {
+		t.Fatalf("RunReview() error = %v, want nil", err)
+	}
+
+	if len(result.Issues) != 2 || result.Issues[0].Location != "api.go:10" || result.Issues[1].Location != "api.go:30" {
+		t.Errorf("RunReview() issues = %+v, want the confident issue and the one without a confidence", result.Issues)
+	}
+	if result.Unconfident != 1 {
+		t.Errorf("Unconfident = %d, want 1", result.Unconfident)
+	}
+}
+
+// TestOffline verifies a client set offline reviews without connecting and
+// refuses the calls that need the AI.
+func TestOffline(t *testing.T) {
+	ctx := context.Background()
+	text := "diff --git a/a_test.js b/a_test.js\n--- a/a_test.js\n+++ b/a_test.js\n@@ -1,0 +1,1 @@\n+describe.only(\"login\", () => {\n"
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	wrapper.SetOffline(true)
+	wrapper.SetLintIssues([]review.Issue{{Severity: review.SeverityLow, Description: "unused variable"}})
+
+	err := wrapper.RunWithClient(ctx, func(client claudecode.Client) error {
+		if client != nil {
+			t.Error("RunWithClient() connected a client offline")
+		}
+		detected, err := wrapper.DetectModes(ctx, client, diff.NewDiff(text))
+		if err != nil || !slices.Contains(detected.Modes, review.ModeTesting) {
+			t.Errorf("DetectModes() = %+v, %v; want the heuristic modes", detected, err)
+		}
+		result, err := wrapper.RunReview(ctx, client, review.ModeTesting, diff.NewDiff(text))
+		if err != nil || result.Status != review.StatusIssues || len(result.Issues) != 1 {
+			t.Errorf("RunReview() = %+v, %v; want the focused test", result, err)
+		}
+		if lint, _ := wrapper.RunReview(ctx, client, review.ModeLint, diff.NewDiff(text)); len(lint.Issues) != 1 {
+			t.Errorf("RunReview(lint) = %+v, want the linters' finding", lint)
+		}
+		if _, err := wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff(text), ""); !errors.Is(err, ErrOffline) {
+			t.Errorf("GenerateCommitMessage() error = %v, want ErrOffline", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunWithClient() error = %v", err)
+	}
+
+	if est := wrapper.EstimateReviews([]review.Mode{review.ModeTesting}, diff.NewDiff(text)); est.Requests != 0 {
+		t.Errorf("EstimateReviews() = %+v, want no requests offline", est)
+	}
+}
+
+// TestRunReview_RecordsTranscript verifies the prompt and raw response of a
+// review are written to the transcript.
+func TestRunReview_RecordsTranscript(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: `{"summary": "Looks fine", "issues": []}`},
+		},
+	}
+	close(transport.msgChan)
+
+	dir := t.TempDir()
+	log, err := transcript.Open(dir, "revi review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	wrapper.SetTranscript(log)
+
+	err = claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		_, reviewErr := wrapper.RunReview(ctx, client, review.ModeStyle, diff.NewDiff("diff content here"))
+		return reviewErr
+	})
+	if err != nil {
+		t.Fatalf("RunReview() error = %v", err)
+	}
+
+	run, err := transcript.Read(dir, log.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Entries) != 3 {
+		t.Fatalf("entries = %+v, want the start, the prompt and the response", run.Entries)
+	}
+	prompt, response := run.Entries[1], run.Entries[2]
+	if prompt.Kind != transcript.KindPrompt || !strings.Contains(prompt.Text, "diff content here") || prompt.Mode != "style" {
+		t.Errorf("prompt = %+v, want the style prompt with the diff", prompt)
+	}
+	if response.Kind != transcript.KindResponse || !strings.Contains(response.Text, "Looks fine") || response.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("response = %+v, want the raw response", response)
+	}
+}
+
+// TestPromoteSuggestion_WithSDKClient verifies PromoteSuggestion() returns an
+// issue with a normalized severity and the fix attempt.
+func TestPromoteSuggestion_WithSDKClient(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	jsonResponse := `{
+		"severity": "warning",
+		"description": "Error from Close is ignored",
+		"location": "main.go:12",
+		"fix": {"available": true, "code": "defer func() { _ = f.Close() }()", "file_path": "main.go", "start_line": 12, "end_line": 12}
+	}`
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: jsonResponse},
+		},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+
+	var issue *review.Issue
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		var promoteErr error
+		issue, promoteErr = wrapper.PromoteSuggestion(ctx, client, review.ModeErrors, diff.NewDiff("diff content here"), "Check the error from Close")
+		return promoteErr
+	})
+	if err != nil {
+		t.Fatalf("PromoteSuggestion() error = %v, want nil", err)
+	}
+
+	if issue.Severity != review.SeverityMedium || issue.RawSeverity != "warning" {
+		t.Errorf("severity = %q (raw %q), want medium (raw warning)", issue.Severity, issue.RawSeverity)
+	}
+	if issue.Location != "main.go:12" {
+		t.Errorf("location = %q, want main.go:12", issue.Location)
+	}
+	if issue.Fix == nil || !issue.Fix.Available || issue.Fix.StartLine != 12 {
+		t.Errorf("expected an available fix at line 12, got %+v", issue.Fix)
+	}
+}
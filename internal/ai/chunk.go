@@ -0,0 +1,243 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+// MaxParallelChunks is the maximum number of chunks of a large diff that are
+// reviewed at the same time. Each chunk beyond the first uses its own connection.
+const MaxParallelChunks = 4
+
+// splitDiff splits a diff into chunks of at most maxSize bytes. Whole files are
+// kept together where possible; a file larger than maxSize is split between its
+// hunks, repeating the file header in each chunk. Only a single hunk larger than
+// maxSize is truncated. Text that is not a git diff falls back to truncation.
+func splitDiff(d *diff.Diff, maxSize int) []*diff.Diff {
+	text := d.String()
+	if len(text) <= maxSize {
+		return []*diff.Diff{d}
+	}
+	if len(d.Files) == 0 {
+		return []*diff.Diff{diff.NewDiff(truncateTo(text, maxSize))}
+	}
+
+	// Break the diff into pieces that each fit in a chunk
+	var pieces []*diff.File
+	for _, f := range d.Files {
+		if len(f.String()) <= maxSize {
+			pieces = append(pieces, f)
+			continue
+		}
+		header := len((&diff.File{Header: f.Header}).String())
+		size := header
+		var hunks []*diff.Hunk
+		for _, h := range f.Hunks {
+			hunk := len(h.String())
+			if len(hunks) > 0 && size+hunk > maxSize {
+				pieces = append(pieces, &diff.File{Path: f.Path, Header: f.Header, Hunks: hunks})
+				hunks, size = nil, header
+			}
+			hunks = append(hunks, h)
+			size += hunk
+		}
+		if len(hunks) > 0 {
+			pieces = append(pieces, &diff.File{Path: f.Path, Header: f.Header, Hunks: hunks})
+		}
+	}
+
+	// Pack the pieces into as few chunks as possible, keeping their order
+	var chunks []*diff.Diff
+	cur, size := &diff.Diff{}, 0
+	for _, piece := range pieces {
+		piece = truncateFile(piece, maxSize)
+		n := len(piece.String())
+		if size > 0 && size+n > maxSize {
+			chunks = append(chunks, cur)
+			cur, size = &diff.Diff{}, 0
+		}
+		cur.Files = append(cur.Files, piece)
+		size += n
+	}
+	if size > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// truncateFile returns f, or a copy truncated with truncateTo if its text
+// is longer than limit
+func truncateFile(f *diff.File, limit int) *diff.File {
+	text := f.String()
+	if len(text) <= limit {
+		return f
+	}
+	truncated := diff.NewDiff(truncateTo(text, limit))
+	if len(truncated.Files) != 1 {
+		return f
+	}
+	return truncated.Files[0]
+}
+
+// truncateDiff returns the text of a diff shortened to limit bytes if it
+// exceeds the limit. The content of its least valuable files, such as
+// vendored code and test fixtures, is left out first (see diff.Fit), so the
+// files that matter are not lost for coming last; what is still over the
+// limit is truncated at a line boundary. Reviews split large diffs into
+// chunks instead; see splitDiff.
+func truncateDiff(d *diff.Diff, limit int) string {
+	return truncateTo(diff.Fit(d, limit).String(), limit)
+}
+
+// truncateTo truncates a diff to limit bytes, at a line boundary if one is
+// within the last 1000 bytes, and appends a truncation marker
+func truncateTo(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+
+	// Find a good truncation point (end of a line) within the last 1000 chars
+	truncateAt := limit
+	for i := limit; i > limit-1000 && i > 0; i-- {
+		if text[i] == '\n' {
+			truncateAt = i
+			break
+		}
+	}
+
+	return text[:truncateAt] + "\n\n[... diff truncated due to size limits ...]"
+}
+
+// reviewChunks reviews each chunk of a large diff and merges the results.
+// The given client reviews chunks alongside up to MaxParallelChunks-1 extra
+// connections opened for the duration of the review.
+func (c *ClientWrapper) reviewChunks(ctx context.Context, client claudecode.Client, mode review.Mode, chunks []*diff.Diff) (*review.Result, error) {
+	debugLog("RunReview: reviewing %s diff in %d chunks", mode, len(chunks))
+
+	results := make([]*review.Result, len(chunks))
+	errs := make([]error, len(chunks))
+	jobs := make(chan int)
+
+	work := func(client claudecode.Client) {
+		for i := range jobs {
+			part := fmt.Sprintf("part %d of %d", i+1, len(chunks))
+			results[i], errs[i] = c.reviewDiff(ctx, client, mode, chunks[i], part)
+		}
+	}
+
+	workers := min(len(chunks), MaxParallelChunks)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	go func() {
+		defer wg.Done()
+		work(client)
+	}()
+	for w := 1; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			// If the connection fails, the other workers take its chunks
+			err := c.connectChunk(ctx, func(client claudecode.Client) error {
+				work(client)
+				return nil
+			})
+			if err != nil {
+				debugLog("RunReview: extra connection for %s failed: %v", mode, err)
+			}
+		}()
+	}
+
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return mergeChunkResults(mode, results, errs)
+}
+
+// connectChunk runs fn with a new client connection for reviewing a chunk
+func (c *ClientWrapper) connectChunk(ctx context.Context, fn func(client claudecode.Client) error) error {
+	return c.connectModel(ctx, c.model, fn)
+}
+
+// connectModel runs fn with a new client connection using model
+func (c *ClientWrapper) connectModel(ctx context.Context, model string, fn func(client claudecode.Client) error) error {
+	if c.offline {
+		return ErrOffline
+	}
+	if c.connect != nil {
+		return c.connect(ctx, fn)
+	}
+	return claudecode.WithClient(ctx, fn, claudecode.WithModel(model))
+}
+
+// mergeChunkResults combines the results of reviewing each chunk into a single
+// result. Chunks that failed are noted in the summary; the review fails only if
+// every chunk failed. An expired login in any chunk is returned as ErrAuthRequired.
+func mergeChunkResults(mode review.Mode, results []*review.Result, errs []error) (*review.Result, error) {
+	merged := &review.Result{Mode: mode}
+	var summaries []string
+	seen := make(map[string]bool)
+	failed := 0
+	firstErr := ""
+
+	for i, result := range results {
+		if errors.Is(errs[i], review.ErrAuthRequired) {
+			return &review.Result{Mode: mode, Status: review.StatusFailed, Error: errs[i].Error()}, errs[i]
+		}
+
+		var errMsg string
+		switch {
+		case errs[i] != nil:
+			errMsg = errs[i].Error()
+		case result == nil:
+			errMsg = "chunk was not reviewed"
+		case result.Status == review.StatusFailed:
+			errMsg = result.Error
+		}
+		if errMsg != "" {
+			failed++
+			if firstErr == "" {
+				firstErr = errMsg
+			}
+			continue
+		}
+
+		merged.Issues = append(merged.Issues, result.Issues...)
+		if result.Summary != "" {
+			summaries = append(summaries, result.Summary)
+		}
+		for _, suggestion := range result.Suggestions {
+			if !seen[suggestion] {
+				seen[suggestion] = true
+				merged.Suggestions = append(merged.Suggestions, suggestion)
+			}
+		}
+	}
+
+	if failed == len(results) {
+		merged.Status = review.StatusFailed
+		merged.Error = fmt.Sprintf("all %d diff chunks failed: %s", failed, firstErr)
+		return merged, nil
+	}
+
+	merged.Summary = strings.Join(summaries, " ")
+	if failed > 0 {
+		merged.Summary = strings.TrimSpace(fmt.Sprintf("%s (%d of %d diff chunks could not be reviewed: %s)",
+			merged.Summary, failed, len(results), firstErr))
+	}
+	if len(merged.Issues) > 0 {
+		merged.Status = review.StatusIssues
+	} else {
+		merged.Status = review.StatusNoIssues
+	}
+	return merged, nil
+}
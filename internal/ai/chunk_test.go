@@ -0,0 +1,284 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+// fileDiff returns a diff for path with one hunk per entry in hunks,
+// each hunk adding the given number of lines
+func fileDiff(path string, hunks ...int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+	for i, lines := range hunks {
+		fmt.Fprintf(&b, "@@ -%d,0 +%d,%d @@\n", i*100+1, i*100+1, lines)
+		for j := 0; j < lines; j++ {
+			fmt.Fprintf(&b, "+line %d of hunk %d in %s\n", j, i, path)
+		}
+	}
+	return b.String()
+}
+
+// newChunkTransport returns a mock transport that answers n queries with response
+func newChunkTransport(response string, n int) *mockTransport {
+	transport := &mockTransport{
+		msgChan: make(chan claudecode.Message, 2*n),
+		errChan: make(chan error, 1),
+	}
+	for i := 0; i < n; i++ {
+		transport.msgChan <- &claudecode.AssistantMessage{
+			Content: []claudecode.ContentBlock{&claudecode.TextBlock{Text: response}},
+		}
+		transport.msgChan <- &claudecode.ResultMessage{}
+	}
+	return transport
+}
+
+// =============================================================================
+// Tests for splitDiff
+// =============================================================================
+
+func TestSplitDiff_SmallDiffUnchanged(t *testing.T) {
+	text := fileDiff("a.go", 3)
+	chunks := chunkTexts(splitDiff(diff.NewDiff(text), 1000))
+	if len(chunks) != 1 || chunks[0] != text {
+		t.Errorf("splitDiff() = %q, want the input as a single chunk", chunks)
+	}
+}
+
+func TestSplitDiff_GroupsWholeFiles(t *testing.T) {
+	a, b, c := fileDiff("a.go", 5), fileDiff("b.go", 5), fileDiff("c.go", 5)
+	maxSize := len(a) + len(b) + 10
+
+	chunks := chunkTexts(splitDiff(diff.NewDiff(a+b+c), maxSize))
+	if len(chunks) != 2 {
+		t.Fatalf("splitDiff() returned %d chunks, want 2", len(chunks))
+	}
+	if !strings.Contains(chunks[0], "a/a.go") || !strings.Contains(chunks[0], "a/b.go") {
+		t.Errorf("first chunk should hold a.go and b.go, got:\n%s", chunks[0])
+	}
+	if !strings.Contains(chunks[1], "a/c.go") {
+		t.Errorf("second chunk should hold c.go, got:\n%s", chunks[1])
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > maxSize {
+			t.Errorf("chunk %d is %d bytes, want <= %d", i, len(chunk), maxSize)
+		}
+	}
+}
+
+func TestSplitDiff_SplitsLargeFileByHunk(t *testing.T) {
+	text := fileDiff("big.go", 20, 20, 20)
+	maxSize := len(text) / 2
+
+	chunks := chunkTexts(splitDiff(diff.NewDiff(text), maxSize))
+	if len(chunks) < 2 {
+		t.Fatalf("splitDiff() returned %d chunks, want at least 2", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if !strings.HasPrefix(chunk, "diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@") {
+			t.Errorf("chunk %d should start with the file header, got:\n%s", i, chunk)
+		}
+		if strings.Contains(chunk, "truncated") {
+			t.Errorf("chunk %d should not be truncated", i)
+		}
+	}
+
+	// Every hunk is reviewed exactly once
+	joined := strings.Join(chunks, "")
+	for hunk := 0; hunk < 3; hunk++ {
+		want := fmt.Sprintf("+line 19 of hunk %d in big.go", hunk)
+		if strings.Count(joined, want) != 1 {
+			t.Errorf("expected %q exactly once across chunks", want)
+		}
+	}
+}
+
+func TestSplitDiff_NonDiffFallsBackToTruncation(t *testing.T) {
+	chunks := chunkTexts(splitDiff(diff.NewDiff(makeStringOfLength(2000)), 1000))
+	if len(chunks) != 1 || !strings.HasSuffix(chunks[0], "[... diff truncated due to size limits ...]") {
+		t.Errorf("splitDiff() should truncate text that is not a git diff, got %d chunks", len(chunks))
+	}
+}
+
+// chunkTexts returns the text of each chunk returned by splitDiff
+func chunkTexts(chunks []*diff.Diff) []string {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.String()
+	}
+	return texts
+}
+
+// =============================================================================
+// Tests for mergeChunkResults
+// =============================================================================
+
+func TestMergeChunkResults_CombinesIssues(t *testing.T) {
+	results := []*review.Result{
+		{Status: review.StatusIssues, Summary: "First part.", Issues: []review.Issue{{Severity: "high"}}, Suggestions: []string{"Add tests"}},
+		{Status: review.StatusNoIssues, Summary: "Second part.", Suggestions: []string{"Add tests", "Use constants"}},
+	}
+
+	merged, err := mergeChunkResults(review.ModeStyle, results, make([]error, 2))
+	if err != nil {
+		t.Fatalf("mergeChunkResults() error = %v", err)
+	}
+	if merged.Mode != review.ModeStyle || merged.Status != review.StatusIssues {
+		t.Errorf("merged = %+v, want style with issues", merged)
+	}
+	if len(merged.Issues) != 1 {
+		t.Errorf("merged issues = %d, want 1", len(merged.Issues))
+	}
+	if len(merged.Suggestions) != 2 {
+		t.Errorf("merged suggestions = %v, want duplicates removed", merged.Suggestions)
+	}
+	if merged.Summary != "First part. Second part." {
+		t.Errorf("merged summary = %q", merged.Summary)
+	}
+}
+
+func TestMergeChunkResults_PartialFailure(t *testing.T) {
+	results := []*review.Result{
+		{Status: review.StatusNoIssues, Summary: "Looks fine."},
+		{Status: review.StatusFailed, Error: "timeout"},
+	}
+
+	merged, err := mergeChunkResults(review.ModeStyle, results, make([]error, 2))
+	if err != nil {
+		t.Fatalf("mergeChunkResults() error = %v", err)
+	}
+	if merged.Status != review.StatusNoIssues {
+		t.Errorf("status = %v, want %v", merged.Status, review.StatusNoIssues)
+	}
+	if !strings.Contains(merged.Summary, "1 of 2 diff chunks could not be reviewed") {
+		t.Errorf("summary should report the failed chunk, got %q", merged.Summary)
+	}
+}
+
+func TestMergeChunkResults_AllFailed(t *testing.T) {
+	results := []*review.Result{nil, {Status: review.StatusFailed, Error: "timeout"}}
+	errs := []error{errors.New("parse error"), nil}
+
+	merged, err := mergeChunkResults(review.ModeStyle, results, errs)
+	if err != nil {
+		t.Fatalf("mergeChunkResults() error = %v", err)
+	}
+	if merged.Status != review.StatusFailed || merged.Error == "" {
+		t.Errorf("merged = %+v, want a failed result", merged)
+	}
+}
+
+func TestMergeChunkResults_AuthRequired(t *testing.T) {
+	results := []*review.Result{{Status: review.StatusNoIssues}, {Status: review.StatusFailed}}
+	errs := []error{nil, review.ErrAuthRequired}
+
+	merged, err := mergeChunkResults(review.ModeStyle, results, errs)
+	if !errors.Is(err, review.ErrAuthRequired) {
+		t.Errorf("expected ErrAuthRequired, got %v", err)
+	}
+	if merged == nil || merged.Status != review.StatusFailed {
+		t.Errorf("merged = %+v, want a failed result", merged)
+	}
+}
+
+// =============================================================================
+// Tests for chunked RunReview
+// =============================================================================
+
+func TestRunReview_ChunksLargeDiff(t *testing.T) {
+	ctx := context.Background()
+	response := `{"summary": "Found an issue", "issues": [{"severity": "low", "description": "naming"}], "suggestions": ["Rename"]}`
+
+	// Three files, each just under MaxDiffSize, so each needs its own chunk
+	lines := MaxDiffSize / 40
+	text := fileDiff("a.go", lines) + fileDiff("b.go", lines) + fileDiff("c.go", lines)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		return claudecode.WithClientTransport(ctx, newChunkTransport(response, 3), fn)
+	}
+
+	var result *review.Result
+	var reviewErr error
+	err := claudecode.WithClientTransport(ctx, newChunkTransport(response, 3), func(client claudecode.Client) error {
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeStyle, diff.NewDiff(text))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithClientTransport() error = %v", err)
+	}
+	if reviewErr != nil {
+		t.Fatalf("RunReview() error = %v", reviewErr)
+	}
+
+	if result.Status != review.StatusIssues {
+		t.Errorf("status = %v, want %v", result.Status, review.StatusIssues)
+	}
+	if len(result.Issues) != 3 {
+		t.Errorf("issues = %d, want one per chunk (3)", len(result.Issues))
+	}
+	if len(result.Suggestions) != 1 {
+		t.Errorf("suggestions = %v, want duplicates removed", result.Suggestions)
+	}
+}
+
+func TestRunReview_UsesModeModel(t *testing.T) {
+	ctx := context.Background()
+	response := `{"summary": "Found an issue", "issues": [{"severity": "low", "description": "naming"}]}`
+
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetModeModels(map[review.Mode]string{review.ModeStyle: "haiku"})
+	connections := 0
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		connections++
+		return claudecode.WithClientTransport(ctx, newChunkTransport(response, 1), fn)
+	}
+	if got := wrapper.ModelFor(review.ModeStyle); got != "haiku" {
+		t.Errorf("ModelFor(style) = %q, want haiku", got)
+	}
+	if got := wrapper.ModelFor(review.ModeSecurity); got != "claude-opus-4-5-20251101" {
+		t.Errorf("ModelFor(security) = %q, want the main model", got)
+	}
+
+	transport := newChunkTransport(response, 1)
+	var result *review.Result
+	var reviewErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeStyle, diff.NewDiff(fileDiff("a.go", 3)))
+		return nil
+	})
+	if err != nil || reviewErr != nil {
+		t.Fatalf("RunReview() error = %v, %v", err, reviewErr)
+	}
+	if result.Status != review.StatusIssues || len(result.Issues) != 1 {
+		t.Errorf("result = %+v, want the issue from the style model", result)
+	}
+	if connections != 1 || len(transport.messagesReceived) != 0 {
+		t.Errorf("connections = %d, caller's queries = %d; want the review on its own connection", connections, len(transport.messagesReceived))
+	}
+}
+
+func TestRunReview_ModeModelConnectionFails(t *testing.T) {
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetModeModels(map[review.Mode]string{review.ModeDocs: "haiku"})
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		return errors.New("connection refused")
+	}
+
+	result, err := wrapper.RunReview(context.Background(), nil, review.ModeDocs, diff.NewDiff(fileDiff("a.go", 3)))
+	if err != nil {
+		t.Fatalf("RunReview() error = %v", err)
+	}
+	if result.Status != review.StatusFailed || !strings.Contains(result.Error, "connection refused") {
+		t.Errorf("result = %+v, want a failed review naming the connection error", result)
+	}
+}
@@ -6,13 +6,19 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/telemetry"
+	"github.com/buker/revi/internal/transcript"
 )
 
 // debugEnabled checks if DEBUG environment variable is set
@@ -25,15 +31,60 @@ func debugLog(format string, args ...interface{}) {
 	}
 }
 
-// MaxDiffSize is the maximum size of a diff that can be sent to Claude.
+// MaxDiffSize is the default maximum size of a diff that can be sent to Claude
+// in one prompt; see SetMaxDiffSize.
 // This is set conservatively to avoid context limits (~100K chars is approximately 25K tokens).
 const MaxDiffSize = 100000
 
+// ErrOffline is returned by the calls a client set offline cannot make
+// without the AI
+var ErrOffline = errors.New("the AI is not available offline")
+
 // ClientWrapper stores configuration for Claude Code SDK client interactions.
 // The actual SDK client is provided via WithClient() pattern for lifecycle management.
 type ClientWrapper struct {
 	model          string
 	streamCallback StreamCallback
+	// connect opens extra connections for chunked reviews and diff summaries;
+	// nil opens them with claudecode.WithClient
+	connect func(ctx context.Context, fn func(client claudecode.Client) error) error
+	// severities maps reported severities onto high/medium/low; nil uses the defaults
+	severities *review.SeverityNormalizer
+	// maxSuggestions caps the suggestions kept per review; 0 keeps all
+	maxSuggestions int
+	// minConfidence is the confidence below which reported issues are
+	// dropped; 0 keeps all
+	minConfidence float64
+	// summaryModel summarizes the files of diffs too large for a commit message
+	// request; empty uses model
+	summaryModel string
+	// skipSummaries truncates diffs too large for a commit message request
+	// instead of summarizing them, so the message takes a single call
+	skipSummaries bool
+	// rateLimits pauses every call of this client while one is backing off
+	// from a rate limit
+	rateLimits *rateLimitCoordinator
+	// retry sets how failed calls are retried
+	retry RetryPolicy
+	// guidance is the project's own text added to the review and commit prompts
+	guidance Guidance
+	// repoRoot is the repository that reported file paths are made relative to
+	repoRoot string
+	// goModule describes the Go module being reviewed; nil if it is not one
+	goModule *review.GoModule
+	// modeModels are the models reviewing some modes instead of model
+	modeModels map[review.Mode]string
+	// lintIssues are what the project's linters reported on the changed files
+	lintIssues []review.Issue
+	// offline reviews with the deterministic checks only, never calling the AI
+	offline bool
+	// transcript records every prompt and response; nil records nothing
+	transcript *transcript.Log
+	// sendSecrets puts diffs in prompts as they are instead of redacting
+	// their secrets
+	sendSecrets bool
+	// maxDiffSize is the most of a diff put in one prompt; 0 uses MaxDiffSize
+	maxDiffSize int
 }
 
 // NewClientWrapper creates a new ClientWrapper with the specified model.
@@ -41,7 +92,9 @@ type ClientWrapper struct {
 // Returns a wrapper that stores configuration; actual client is created via WithClient().
 func NewClientWrapper(model string) *ClientWrapper {
 	return &ClientWrapper{
-		model: model,
+		model:      model,
+		rateLimits: newRateLimitCoordinator(),
+		retry:      DefaultRetryPolicy(),
 	}
 }
 
@@ -50,6 +103,162 @@ func (c *ClientWrapper) SetStreamCallback(callback StreamCallback) {
 	c.streamCallback = callback
 }
 
+// SetSeverityNormalizer sets how issue severities reported by the model are
+// mapped onto high/medium/low. Without one, review.DefaultSeverityMap is used.
+func (c *ClientWrapper) SetSeverityNormalizer(n *review.SeverityNormalizer) {
+	c.severities = n
+}
+
+// SetMaxSuggestions limits how many suggestions each review may return.
+// Zero or less keeps all of them.
+func (c *ClientWrapper) SetMaxSuggestions(n int) {
+	c.maxSuggestions = n
+}
+
+// SetMinConfidence drops the issues a review reports with a confidence below
+// min, from 0 to 1. Zero or less keeps all of them.
+func (c *ClientWrapper) SetMinConfidence(min float64) {
+	c.minConfidence = min
+}
+
+// SetSummaryModel sets the model, typically a cheaper one, that summarizes each
+// file of a diff too large to send whole when generating a commit message.
+// Empty uses the main model.
+func (c *ClientWrapper) SetSummaryModel(model string) {
+	c.summaryModel = model
+}
+
+// SetSkipSummaries makes GenerateCommitMessage truncate diffs too large to
+// send whole instead of summarizing them file by file, so that generating a
+// message takes a single request.
+func (c *ClientWrapper) SetSkipSummaries(skip bool) {
+	c.skipSummaries = skip
+}
+
+// SetRetryPolicy sets how often and for how long failed calls are retried,
+// e.g. a tighter budget in CI. DefaultRetryPolicy is used until it is called.
+func (c *ClientWrapper) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// SetModeModels sets the models reviewing some modes instead of the main
+// model, e.g. a cheaper one for style and docs. RunReview opens a connection
+// with a mode's model, since the caller's uses the main model.
+func (c *ClientWrapper) SetModeModels(models map[review.Mode]string) {
+	c.modeModels = models
+}
+
+// ModelFor returns the model reviewing mode.
+func (c *ClientWrapper) ModelFor(mode review.Mode) string {
+	if model := c.modeModels[mode]; model != "" {
+		return model
+	}
+	return c.model
+}
+
+// SetGuidance sets project-specific guidance added to the review and commit
+// message prompts, typically loaded with LoadGuidance.
+func (c *ClientWrapper) SetGuidance(g Guidance) {
+	c.guidance = g
+}
+
+// SetRepoRoot sets the root of the repository being reviewed. File paths in
+// review results are made relative to it, since models sometimes report
+// absolute paths or keep the a/ and b/ prefixes of the diff.
+func (c *ClientWrapper) SetRepoRoot(root string) {
+	c.repoRoot = root
+}
+
+// SetGoModule sets the Go module being reviewed, typically loaded with
+// review.LoadGoModule. Its path and Go version are added to the review prompts
+// so fixes use the right import paths and language features, and fixes that
+// import packages unknown to it are marked unavailable.
+func (c *ClientWrapper) SetGoModule(m *review.GoModule) {
+	c.goModule = m
+}
+
+// GoModule returns the module set with SetGoModule.
+func (c *ClientWrapper) GoModule() *review.GoModule {
+	return c.goModule
+}
+
+// SetLintIssues sets what the project's linters reported on the changed
+// files. They are added to the review prompts, so fixes build on them, and
+// RunReview returns them as the result of review.ModeLint without calling
+// the AI.
+func (c *ClientWrapper) SetLintIssues(issues []review.Issue) {
+	c.lintIssues = issues
+}
+
+// HasLintIssues reports whether the linters reported anything, and so
+// whether review.ModeLint has a result
+func (c *ClientWrapper) HasLintIssues() bool {
+	return len(c.lintIssues) > 0
+}
+
+// SetOffline makes the client work without the AI. RunWithClient runs its
+// function without connecting, DetectModes selects modes with
+// review.HeuristicDetector, RunReview reports what review.OfflineResult finds
+// and the linters' findings, and everything else fails with ErrOffline.
+func (c *ClientWrapper) SetOffline(offline bool) {
+	c.offline = offline
+}
+
+// SetTranscript records every prompt sent to the model and its response in
+// log. A nil log records nothing.
+func (c *ClientWrapper) SetTranscript(log *transcript.Log) {
+	c.transcript = log
+}
+
+// SetMaxDiffSize sets the most bytes of a diff put in one prompt. Larger
+// diffs are reviewed in chunks of this size, summarized file by file for a
+// commit message, and otherwise shortened by truncateDiff. Zero or less uses
+// MaxDiffSize.
+func (c *ClientWrapper) SetMaxDiffSize(n int) {
+	c.maxDiffSize = n
+}
+
+// diffLimit returns the size set with SetMaxDiffSize, or MaxDiffSize
+func (c *ClientWrapper) diffLimit() int {
+	if c.maxDiffSize > 0 {
+		return c.maxDiffSize
+	}
+	return MaxDiffSize
+}
+
+// SetRedactSecrets sets whether the secrets review.RedactDiff recognizes are
+// replaced in diffs before they are put in a prompt, which they are unless
+// this turns it off. The local secrets scanner still sees them.
+func (c *ClientWrapper) SetRedactSecrets(redact bool) {
+	c.sendSecrets = !redact
+}
+
+// RedactsSecrets reports whether secrets are replaced in diffs before they
+// are sent; see SetRedactSecrets
+func (c *ClientWrapper) RedactsSecrets() bool {
+	return !c.sendSecrets
+}
+
+// redact returns diff with its secrets replaced, unless SetRedactSecrets
+// turned redaction off
+func (c *ClientWrapper) redact(diff *diff.Diff) *diff.Diff {
+	if c.sendSecrets {
+		return diff
+	}
+	diff, _ = review.RedactDiff(diff)
+	return diff
+}
+
+// Offline reports whether the client was set offline with SetOffline
+func (c *ClientWrapper) Offline() bool {
+	return c.offline
+}
+
+// Guidance returns the guidance set with SetGuidance.
+func (c *ClientWrapper) Guidance() Guidance {
+	return c.guidance
+}
+
 // Model returns the configured model name.
 func (c *ClientWrapper) Model() string {
 	return c.model
@@ -64,6 +273,9 @@ func (c *ClientWrapper) StreamCallback() StreamCallback {
 // This wraps claudecode.WithClient() and passes the model configuration.
 // The client connection is automatically managed - connected before fn runs, disconnected after.
 func (c *ClientWrapper) RunWithClient(ctx context.Context, fn func(client claudecode.Client) error) error {
+	if c.offline {
+		return fn(nil)
+	}
 	opts := []claudecode.Option{
 		claudecode.WithModel(c.model),
 	}
@@ -71,10 +283,32 @@ func (c *ClientWrapper) RunWithClient(ctx context.Context, fn func(client claude
 	return claudecode.WithClient(ctx, fn, opts...)
 }
 
+// Connect returns a client connected with the model, for callers keeping one
+// open across many calls, such as revi daemon; the caller disconnects it. The
+// connection lasts as long as ctx. A client set offline connects nothing and
+// returns nil, as RunWithClient passes to fn.
+func (c *ClientWrapper) Connect(ctx context.Context) (claudecode.Client, error) {
+	if c.offline {
+		return nil, nil
+	}
+	client := claudecode.NewClient(claudecode.WithModel(c.model))
+	if err := client.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect client: %w", err)
+	}
+	return client, nil
+}
+
 // DetectModes asks Claude to analyze the diff and detect relevant review modes.
 // Requires a connected SDK client - use within RunWithClient callback.
-func (c *ClientWrapper) DetectModes(ctx context.Context, client claudecode.Client, diff string) (*review.DetectionResult, error) {
-	diff = truncateDiff(diff)
+func (c *ClientWrapper) DetectModes(ctx context.Context, client claudecode.Client, diff *diff.Diff) (_ *review.DetectionResult, err error) {
+	if c.offline {
+		modes, reasoning, err := review.NewHeuristicDetector().Detect(ctx, diff)
+		return &review.DetectionResult{Modes: modes, Reasoning: reasoning}, err
+	}
+	ctx, span := telemetry.Start(ctx, "revi.detect", attribute.String("revi.model", c.model))
+	defer func() { telemetry.End(span, err) }()
+
+	text := truncateDiff(c.redact(diff), c.diffLimit())
 
 	prompt := fmt.Sprintf(`Analyze the following git diff and determine which review modes are relevant.
 
@@ -85,15 +319,16 @@ Available modes:
 - errors: Missing error checks, swallowed exceptions, unhelpful error messages, edge cases
 - testing: Untested code paths, missing assertions, test quality, coverage gaps
 - docs: Missing comments, unclear names, outdated comments, API documentation
+- secrets: Hardcoded credentials, API keys, tokens, private keys, connection strings with passwords
 
-Respond with ONLY valid JSON in this exact format:
-{"modes": ["mode1", "mode2"], "reasoning": "brief explanation"}
+Respond with ONLY a JSON object matching this JSON Schema:
+%s
 
 Git diff:
-%s`, diff)
+%s`, detectionSchema, text)
 
 	var response string
-	err := executeWithRetry(ctx, func() error {
+	err = executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
 		var callErr error
 		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, review.Mode(""))
 		return callErr
@@ -103,93 +338,156 @@ Git diff:
 		return nil, fmt.Errorf("failed to detect modes: %w", err)
 	}
 
-	// Strip markdown code fences if present
-	response = stripMarkdownCodeFences(response)
-
 	var result review.DetectionResult
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
+	if err := decodeResponse(response, detectionSchema, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse detection result: %w (response: %s)", err, response)
 	}
 
+	modes := make([]string, len(result.Modes))
+	for i, mode := range result.Modes {
+		modes[i] = string(mode)
+	}
+	span.SetAttributes(attribute.StringSlice("revi.modes", modes))
 	return &result, nil
 }
 
-// RunReview runs a specific review mode on the diff.
+// RunReview runs a specific review mode on the diff. The lint mode returns
+// the issues set with SetLintIssues instead.
+// Diffs larger than the limit set with SetMaxDiffSize are split into chunks
+// (see splitDiff) that are reviewed in parallel and merged into a single
+// result. The possible secrets
+// found by review.ScanSecrets are added to the result of the secrets mode,
+// even if the model missed them or the review failed.
 // Requires a connected SDK client - use within RunWithClient callback.
-func (c *ClientWrapper) RunReview(ctx context.Context, client claudecode.Client, mode review.Mode, diff string) (*review.Result, error) {
-	diff = truncateDiff(diff)
-	modeInfo := review.GetModeInfo(mode)
+func (c *ClientWrapper) RunReview(ctx context.Context, client claudecode.Client, mode review.Mode, diff *diff.Diff) (*review.Result, error) {
+	if mode == review.ModeLint {
+		return c.lintResult(), nil
+	}
+	if c.offline {
+		return review.OfflineResult(mode, diff), nil
+	}
+	result, err := c.runReview(ctx, client, mode, diff)
+	if mode == review.ModeSecrets && result != nil {
+		review.MergeSecrets(result, review.ScanSecrets(diff))
+	}
+	return result, err
+}
 
-	prompt := fmt.Sprintf(`You are a code reviewer focused ONLY on %s concerns.
+// lintResult returns the issues set with SetLintIssues as a review result
+func (c *ClientWrapper) lintResult() *review.Result {
+	result := &review.Result{
+		Mode:    review.ModeLint,
+		Status:  review.StatusNoIssues,
+		Summary: "The linters reported nothing on the changed files",
+		Issues:  slices.Clone(c.lintIssues),
+	}
+	if len(result.Issues) > 0 {
+		result.Status = review.StatusIssues
+		result.Summary = fmt.Sprintf("%d finding(s) reported by the linters", len(result.Issues))
+	}
+	return result
+}
 
-Focus areas: %s
+// runReview is RunReview without the scanner's findings
+func (c *ClientWrapper) runReview(ctx context.Context, client claudecode.Client, mode review.Mode, diff *diff.Diff) (result *review.Result, err error) {
+	if model := c.ModelFor(mode); model != c.model {
+		return c.reviewWithModel(ctx, model, mode, diff)
+	}
 
-Review the following git diff and respond with ONLY valid JSON in this exact format:
-{
-  "mode": "%s",
-  "status": "issues_found" or "no_issues",
-  "summary": "brief 1-2 sentence summary",
-  "issues": [
-    {
-      "severity": "high|medium|low",
-      "description": "issue description",
-      "location": "file:line if known",
-      "fix": {
-        "available": true or false,
-        "code": "replacement code with proper indentation (only if available=true)",
-        "file_path": "path/to/file.go (only if available=true)",
-        "start_line": 42,
-        "end_line": 42,
-        "explanation": "why this fix works (only if available=true)",
-        "reason": "why fix unavailable (only if available=false)",
-        "alternatives": ["manual step 1", "manual step 2"]
-      }
-    }
-  ],
-  "suggestions": ["suggestion 1", "suggestion 2"]
+	chunks := splitDiff(diff, c.diffLimit())
+	ctx, span := telemetry.Start(ctx, "revi.review",
+		attribute.String("revi.mode", string(mode)),
+		attribute.String("revi.model", c.model),
+		attribute.Int("revi.chunks", len(chunks)),
+	)
+	defer func() {
+		if result != nil {
+			span.SetAttributes(attribute.String("revi.status", string(result.Status)), attribute.Int("revi.issues", len(result.Issues)))
+		}
+		telemetry.End(span, err)
+	}()
+
+	if len(chunks) == 1 {
+		result, err = c.reviewDiff(ctx, client, mode, chunks[0], "")
+	} else {
+		result, err = c.reviewChunks(ctx, client, mode, chunks)
+	}
+	if result != nil && c.maxSuggestions > 0 && len(result.Suggestions) > c.maxSuggestions {
+		result.Suggestions = result.Suggestions[:c.maxSuggestions]
+	}
+	result.DropUnconfident(c.minConfidence)
+	return result, err
 }
 
-Important:
-- Only report issues related to %s
-- Be concise and actionable
-- If no issues found, return empty issues array and status "no_issues"
-- EVERY issue MUST have a concrete fix with available=true. Do NOT report issues you cannot fix.
-- For each issue, include a "fix" object:
-  - The fix MUST be real, working code - NEVER use TODO comments, placeholder text, or "implement this" stubs
-  - Set available=true and provide the complete corrected code in the "code" field
-  - The code field must contain the exact replacement text with proper indentation
-  - Include file_path, start_line, end_line, and explanation for all fixes
-  - Only set available=false in rare cases where the fix truly requires human judgment (e.g., business logic decisions, choosing between multiple valid architectures). In these cases, explain clearly in "reason" why you cannot decide.
-  - If you cannot provide a real fix for an issue, do NOT report that issue at all
-- Do NOT include fixes that say "add validation here" or "handle error" - show the actual code
+// reviewWithModel runs runReview for mode on a connection of its own using
+// model, the mode's model set with SetModeModels
+func (c *ClientWrapper) reviewWithModel(ctx context.Context, model string, mode review.Mode, diff *diff.Diff) (result *review.Result, err error) {
+	debugLog("RunReview: reviewing %s with %s", mode, model)
+	modal := *c
+	modal.model = model
+	modal.modeModels = nil
+
+	connected := false
+	connErr := c.connectModel(ctx, model, func(client claudecode.Client) error {
+		connected = true
+		result, err = modal.runReview(ctx, client, mode, diff)
+		return nil
+	})
+	if !connected {
+		return &review.Result{
+			Mode:   mode,
+			Status: review.StatusFailed,
+			Error:  fmt.Sprintf("failed to connect with %s: %v", model, connErr),
+		}, nil
+	}
+	return result, err
+}
 
-Git diff:
-%s`, modeInfo.Name, modeInfo.Description, mode, modeInfo.Name, diff)
+// reviewDiff reviews a diff that fits in a single request. part describes which
+// chunk of a larger diff this is (e.g. "part 2 of 3"), or is empty.
+func (c *ClientWrapper) reviewDiff(ctx context.Context, client claudecode.Client, mode review.Mode, diff *diff.Diff, part string) (*review.Result, error) {
+	prompt := c.reviewPrompt(mode, diff, part)
 
 	var response string
-	err := executeWithRetry(ctx, func() error {
+	err := executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
 		var callErr error
 		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, mode)
 		return callErr
 	}, c.streamCallback)
 
 	if err != nil {
-		return &review.Result{
+		result := &review.Result{
 			Mode:   mode,
 			Status: review.StatusFailed,
 			Error:  err.Error(),
-		}, nil
+		}
+		// Surface expired credentials so callers can pause the remaining work
+		if errors.Is(err, review.ErrAuthRequired) {
+			return result, err
+		}
+		return result, nil
 	}
 
-	// Strip markdown code fences if present
-	response = stripMarkdownCodeFences(response)
-
-	var result review.Result
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse review result: %w (response: %s)", err, response)
+	result, err := decodeReview(response)
+	if err != nil {
+		// Keep what the model said so it can still be read
+		return &review.Result{
+			Mode:   mode,
+			Status: review.StatusFailed,
+			Error:  fmt.Sprintf("failed to parse review result: %v", err),
+			Raw:    strings.TrimSpace(response),
+		}, nil
 	}
 
 	result.Mode = mode
+	severities := c.severities
+	if severities == nil {
+		severities = review.DefaultSeverityNormalizer()
+	}
+	severities.NormalizeResult(&result)
+	review.NormalizeConfidence(&result)
+	review.NormalizePaths(&result, c.repoRoot)
+	c.goModule.ValidateFixes(&result)
 	if len(result.Issues) > 0 {
 		result.Status = review.StatusIssues
 	} else {
@@ -199,6 +497,80 @@ Git diff:
 	return &result, nil
 }
 
+// reviewPrompt returns the prompt asking for a review of diff in mode. part is
+// as for reviewDiff.
+func (c *ClientWrapper) reviewPrompt(mode review.Mode, diff *diff.Diff, part string) string {
+	modeInfo := review.GetModeInfo(mode)
+
+	partNote := ""
+	if part != "" {
+		partNote = fmt.Sprintf("\nThis diff is %s of a larger change that is reviewed in parts. Review only the files and hunks shown.\n", part)
+	}
+	limitNote := ""
+	if c.maxSuggestions > 0 {
+		limitNote = fmt.Sprintf("- Return at most %d suggestions, most valuable first\n", c.maxSuggestions)
+	}
+
+	return fmt.Sprintf(`You are a code reviewer focused ONLY on %s concerns.
+
+Focus areas: %s
+
+Review the following git diff and respond with ONLY a JSON object matching this JSON Schema, with "mode" set to "%s":
+%s
+
+Important:
+- Only report issues related to %s
+- Ignore hunks that only change whitespace, reformat code without changing it, or move code verbatim
+- Be concise and actionable
+- If no issues found, return empty issues array and status "no_issues"
+- EVERY issue MUST have a concrete fix with available=true. Do NOT report issues you cannot fix.
+- For each issue, include a "fix" object:
+  - The fix MUST be real, working code - NEVER use TODO comments, placeholder text, or "implement this" stubs
+  - Set available=true and provide the complete corrected code in the "code" field
+  - The code field must contain the exact replacement text with proper indentation
+  - Include file_path, start_line, end_line, and explanation for all fixes
+  - Only set available=false in rare cases where the fix truly requires human judgment (e.g., business logic decisions, choosing between multiple valid architectures). In these cases, explain clearly in "reason" why you cannot decide.
+  - If you cannot provide a real fix for an issue, do NOT report that issue at all
+- Do NOT include fixes that say "add validation here" or "handle error" - show the actual code
+- Set each issue's "confidence" to how sure you are that it is a real problem, from 0 to 1. Give speculative issues, which depend on code or intent the diff does not show, a low confidence
+%s%s%s%s%s%s
+Git diff:
+%s`, modeInfo.Name, modeInfo.Description, mode, reviewSchema, modeInfo.Name, limitNote, goModuleSection(c.goModule), guidanceSection(c.guidance.reviewGuidance(mode)), lintSection(c.lintIssues), secretsSection(mode, diff), partNote, c.redact(diff).String())
+}
+
+// lintSection lists what the project's linters reported for inclusion in a
+// review prompt, or returns "" if they reported nothing
+func lintSection(issues []review.Issue) string {
+	if len(issues) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nThe project's linters reported these findings on the changed files. They are reported separately, so do not repeat them, but take them into account: where a fix touches these lines, make it resolve them too.\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- %s: %s\n", issue.Location, issue.Description)
+	}
+	return b.String()
+}
+
+// secretsSection lists the possible secrets the local scanner found in diff
+// for inclusion in the prompt of a secrets review, or returns "" for other
+// modes and when it found none
+func secretsSection(mode review.Mode, diff *diff.Diff) string {
+	if mode != review.ModeSecrets {
+		return ""
+	}
+	found := review.ScanSecrets(diff)
+	if len(found) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nA local scanner flagged these added lines as possible secrets. They are reported whatever you decide; report each you confirm at the same location with a fix that reads the value from the environment or configuration, and look for any it missed:\n")
+	for _, issue := range found {
+		fmt.Fprintf(&b, "- %s: %s\n", issue.Location, issue.Description)
+	}
+	return b.String()
+}
+
 // CommitMessage represents a generated commit message.
 type CommitMessage struct {
 	Type    string `json:"type"`
@@ -223,50 +595,32 @@ func (m *CommitMessage) String() string {
 
 // GenerateCommitMessage generates a conventional commit message for the diff.
 // If context is provided, it will be included in the prompt to explain
-// the reasoning behind the change.
+// the reasoning behind the change. Diffs over the SetMaxDiffSize limit are first
+// summarized file by file (see SetSummaryModel) so the message covers every
+// file; if summarizing fails or is skipped (see SetSkipSummaries), the diff is
+// truncated instead.
 // Requires a connected SDK client - use within RunWithClient callback.
-func (c *ClientWrapper) GenerateCommitMessage(ctx context.Context, client claudecode.Client, diff string, commitContext string) (*CommitMessage, error) {
-	debugLog("GenerateCommitMessage called (diff length: %d, context: %q)", len(diff), commitContext)
-	diff = truncateDiff(diff)
-	debugLog("Diff after truncation: %d bytes", len(diff))
-
-	contextSection := ""
-	if commitContext != "" {
-		contextSection = fmt.Sprintf(`
-Context (why this change was made):
-%s
-
-`, commitContext)
+func (c *ClientWrapper) GenerateCommitMessage(ctx context.Context, client claudecode.Client, diff *diff.Diff, commitContext string) (*CommitMessage, error) {
+	debugLog("GenerateCommitMessage called (diff length: %d, context: %q)", len(diff.String()), commitContext)
+
+	changes := "Git diff:\n" + truncateDiff(c.redact(diff), c.diffLimit())
+	if len(diff.String()) > c.diffLimit() && !c.skipSummaries {
+		summaries, err := c.summarizeDiff(ctx, client, diff)
+		if err != nil {
+			debugLog("Summarizing diff failed, truncating instead: %v", err)
+		} else {
+			changes = fmt.Sprintf("The diff is too large to include. Summaries of the changes to each of its %d files:\n%s",
+				len(summaries), truncateTo(formatSummaries(summaries), c.diffLimit()))
+		}
 	}
+	debugLog("Changes prepared: %d bytes", len(changes))
 
-	prompt := fmt.Sprintf(`Generate a conventional commit message for the following git diff.
-%s
-Respond with ONLY valid JSON in this exact format:
-{
-  "type": "feat|fix|docs|style|refactor|perf|test|chore",
-  "scope": "optional scope",
-  "subject": "imperative mood, lowercase, no period, max 50 chars",
-  "body": "optional longer description explaining WHY this change was made"
-}
-
-Commit types:
-- feat: new feature
-- fix: bug fix
-- docs: documentation only
-- style: formatting, no code change
-- refactor: code change that neither fixes bug nor adds feature
-- perf: performance improvement
-- test: adding or fixing tests
-- chore: maintenance tasks
-
-Git diff:
-%s`, contextSection, diff)
-
+	prompt := c.commitPrompt(changes, commitContext)
 	debugLog("Prompt prepared (length: %d bytes)", len(prompt))
 
 	var response string
 	debugLog("Calling executeWithRetry...")
-	err := executeWithRetry(ctx, func() error {
+	err := executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
 		debugLog("Inside retry function, calling callAPIWithStreaming...")
 		var callErr error
 		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, review.Mode(""))
@@ -281,13 +635,9 @@ Git diff:
 
 	debugLog("Response received: %s", response)
 
-	// Strip markdown code fences if present
-	response = stripMarkdownCodeFences(response)
-	debugLog("Response after stripping markdown: %s", response)
-
 	var msg CommitMessage
-	if err := json.Unmarshal([]byte(response), &msg); err != nil {
-		debugLog("JSON unmarshal failed: %v", err)
+	if err := decodeResponse(response, commitMessageSchema, &msg); err != nil {
+		debugLog("Decoding response failed: %v", err)
 		return nil, fmt.Errorf("failed to parse commit message: %w (response: %s)", err, response)
 	}
 
@@ -295,11 +645,61 @@ Git diff:
 	return &msg, nil
 }
 
+// commitPrompt builds the prompt asking for a commit message for changes, the
+// diff or its file summaries, with the reason for the change if one is given
+func (c *ClientWrapper) commitPrompt(changes, commitContext string) string {
+	contextSection := ""
+	if commitContext != "" {
+		contextSection = fmt.Sprintf(`
+Context (why this change was made):
+%s
+
+`, commitContext)
+	}
+
+	return fmt.Sprintf(`Generate a conventional commit message for the following git diff.
+%s
+Respond with ONLY a JSON object matching this JSON Schema:
+%s
+
+Commit types:
+- feat: new feature
+- fix: bug fix
+- docs: documentation only
+- style: formatting, no code change
+- refactor: code change that neither fixes bug nor adds feature
+- perf: performance improvement
+- test: adding or fixing tests
+- chore: maintenance tasks
+%s
+%s`, contextSection, commitMessageSchema, guidanceSection(c.guidance.Commit), changes)
+}
+
 // callAPIWithStreaming makes a streaming request via the Claude Code SDK.
 // It sends progressive content updates via the streamCallback and returns the complete response.
+// If the CLI answers through its structured output tool, the response is that
+// tool's input as JSON rather than the text around it; see decodeResponse.
+// The prompt and the response or error are recorded in the transcript set
+// with SetTranscript.
 func (c *ClientWrapper) callAPIWithStreaming(ctx context.Context, client claudecode.Client, prompt string, mode review.Mode) (string, error) {
 	debugLog("callAPIWithStreaming: starting (prompt length: %d, mode: %s)", len(prompt), mode)
+	if c.offline {
+		return "", ErrOffline
+	}
+
+	c.transcript.Record(transcript.KindPrompt, string(mode), c.model, prompt)
+	response, err := c.streamQuery(ctx, client, prompt, mode)
+	if err != nil {
+		c.transcript.Record(transcript.KindError, string(mode), c.model, err.Error())
+	} else {
+		c.transcript.Record(transcript.KindResponse, string(mode), c.model, response)
+	}
+	return response, err
+}
 
+// streamQuery sends prompt on client and collects the response, as
+// callAPIWithStreaming describes
+func (c *ClientWrapper) streamQuery(ctx context.Context, client claudecode.Client, prompt string, mode review.Mode) (string, error) {
 	// Send query to Claude
 	debugLog("callAPIWithStreaming: sending query to Claude...")
 	if err := client.Query(ctx, prompt); err != nil {
@@ -309,6 +709,7 @@ func (c *ClientWrapper) callAPIWithStreaming(ctx context.Context, client claudec
 	debugLog("callAPIWithStreaming: query sent successfully")
 
 	var contentBuilder strings.Builder
+	var structured string
 
 	// Receive and process messages from the response channel
 	debugLog("callAPIWithStreaming: starting to receive messages...")
@@ -323,10 +724,23 @@ func (c *ClientWrapper) callAPIWithStreaming(ctx context.Context, client claudec
 			// Process content blocks in assistant messages
 			for i, block := range m.Content {
 				debugLog("callAPIWithStreaming: processing block #%d (type: %T)", i, block)
-				if textBlock, ok := block.(*claudecode.TextBlock); ok {
-					debugLog("callAPIWithStreaming: TextBlock content length: %d", len(textBlock.Text))
-					contentBuilder.WriteString(textBlock.Text)
-					sendStreamContent(c.streamCallback, mode, textBlock.Text)
+				switch b := block.(type) {
+				case *claudecode.TextBlock:
+					debugLog("callAPIWithStreaming: TextBlock content length: %d", len(b.Text))
+					contentBuilder.WriteString(b.Text)
+					sendStreamContent(c.streamCallback, mode, b.Text)
+				case *claudecode.ToolUseBlock:
+					if b.Name != structuredOutputTool {
+						continue
+					}
+					data, err := json.Marshal(b.Input)
+					if err != nil {
+						debugLog("callAPIWithStreaming: encoding structured output failed: %v", err)
+						continue
+					}
+					debugLog("callAPIWithStreaming: structured output length: %d", len(data))
+					structured = string(data)
+					sendStreamContent(c.streamCallback, mode, structured)
 				}
 			}
 		case *claudecode.ResultMessage:
@@ -336,10 +750,15 @@ func (c *ClientWrapper) callAPIWithStreaming(ctx context.Context, client claudec
 				debugLog("callAPIWithStreaming: error result, returning error")
 				if contentBuilder.Len() > 0 {
 					sendStreamContent(c.streamCallback, mode, "...")
+					// Keep the CLI's explanation so the error can be classified
+					return "", fmt.Errorf("API error in result message: %s", strings.TrimSpace(contentBuilder.String()))
 				}
 				return "", fmt.Errorf("API error in result message")
 			}
 			// Success case: operation completed successfully, return the collected content
+			if structured != "" {
+				return structured, nil
+			}
 			result := contentBuilder.String()
 			debugLog("callAPIWithStreaming: success result, returning content (length: %d)", len(result))
 			return result, nil
@@ -349,26 +768,10 @@ func (c *ClientWrapper) callAPIWithStreaming(ctx context.Context, client claudec
 	}
 
 	debugLog("callAPIWithStreaming: channel closed, returning collected content (length: %d)", contentBuilder.Len())
-	return contentBuilder.String(), nil
-}
-
-// truncateDiff truncates a diff to MaxDiffSize if it exceeds the limit.
-// It attempts to truncate at a line boundary for cleaner output.
-func truncateDiff(diff string) string {
-	if len(diff) <= MaxDiffSize {
-		return diff
-	}
-
-	// Find a good truncation point (end of a line) within the last 1000 chars
-	truncateAt := MaxDiffSize
-	for i := MaxDiffSize; i > MaxDiffSize-1000 && i > 0; i-- {
-		if diff[i] == '\n' {
-			truncateAt = i
-			break
-		}
+	if structured != "" {
+		return structured, nil
 	}
-
-	return diff[:truncateAt] + "\n\n[... diff truncated due to size limits ...]"
+	return contentBuilder.String(), nil
 }
 
 // stripMarkdownCodeFences removes markdown code fence wrappers from AI responses.
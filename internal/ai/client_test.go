@@ -2,11 +2,13 @@ package ai
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
 )
 
@@ -53,7 +55,7 @@ func TestTruncateDiff_AtBoundary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := truncateDiff(tt.input)
+			result := truncateDiff(diff.NewDiff(tt.input), MaxDiffSize)
 			if tt.wantFull {
 				if result != tt.input {
 					t.Errorf("truncateDiff() changed input when it should not")
@@ -78,7 +80,7 @@ func TestTruncateDiff_LineBreakBoundary(t *testing.T) {
 	}
 	lines += "extra content to exceed limit"
 
-	result := truncateDiff(lines)
+	result := truncateDiff(diff.NewDiff(lines), MaxDiffSize)
 
 	// Should truncate at a newline boundary
 	if len(result) <= MaxDiffSize {
@@ -95,6 +97,36 @@ func TestTruncateDiff_LineBreakBoundary(t *testing.T) {
 	}
 }
 
+func TestTruncateDiff_LeavesOutVendoredCodeFirst(t *testing.T) {
+	text := fileDiff("vendor/lib/lib.go", 200) + fileDiff("main.go", 20)
+	result := truncateDiff(diff.NewDiff(text), len(text)/2)
+	if !strings.Contains(result, "+line 19 of hunk 0 in main.go") {
+		t.Errorf("truncateDiff() should keep main.go, which comes last:\n%s", result)
+	}
+	if strings.Contains(result, "in vendor/lib/lib.go") || strings.Contains(result, "diff truncated") {
+		t.Errorf("truncateDiff() should leave out only the vendored code:\n%s", result)
+	}
+}
+
+func TestSetMaxDiffSize(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	if client.diffLimit() != MaxDiffSize {
+		t.Errorf("diffLimit() = %d, want MaxDiffSize by default", client.diffLimit())
+	}
+	client.SetMaxDiffSize(5000)
+	if client.diffLimit() != 5000 {
+		t.Errorf("diffLimit() = %d, want 5000", client.diffLimit())
+	}
+	text := fileDiff("a.go", 100) + fileDiff("b.go", 100)
+	if est := client.EstimateReviews([]review.Mode{review.ModeStyle}, diff.NewDiff(text)); est.Requests != 2 {
+		t.Errorf("EstimateReviews() = %d request(s), want a chunk per file over the limit", est.Requests)
+	}
+	client.SetMaxDiffSize(0)
+	if client.diffLimit() != MaxDiffSize {
+		t.Errorf("diffLimit() = %d, want MaxDiffSize for 0", client.diffLimit())
+	}
+}
+
 func TestCommitMessage_String(t *testing.T) {
 	// Verify CommitMessage structure and String() method
 	cm := &CommitMessage{
@@ -295,3 +327,21 @@ func TestStripMarkdownCodeFences(t *testing.T) {
 		})
 	}
 }
+
+func TestRunReview_LintModeReturnsLinterFindings(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	result, err := client.RunReview(context.Background(), nil, review.ModeLint, diff.NewDiff("diff"))
+	if err != nil || result.Status != review.StatusNoIssues || len(result.Issues) != 0 {
+		t.Errorf("RunReview(lint) without findings = %+v, %v", result, err)
+	}
+
+	client.SetLintIssues([]review.Issue{{Severity: review.SeverityLow, Description: "unreachable code (go vet)", Location: "main.go:12"}})
+	// No AI call is made, so no connected client is needed
+	result, err = client.RunReview(context.Background(), nil, review.ModeLint, diff.NewDiff("diff"))
+	if err != nil {
+		t.Fatalf("RunReview(lint) error = %v", err)
+	}
+	if result.Mode != review.ModeLint || result.Status != review.StatusIssues || len(result.Issues) != 1 || result.Issues[0].Location != "main.go:12" {
+		t.Errorf("RunReview(lint) = %+v", result)
+	}
+}
@@ -2,11 +2,14 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/review"
 )
 
 // TestClassifyError_CLINotFound tests that CLINotFoundError is classified correctly.
@@ -48,7 +51,7 @@ func TestExecuteWithRetry_CLINotFound(t *testing.T) {
 		return claudecode.NewCLINotFoundError("", "Claude Code CLI not found")
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -73,7 +76,7 @@ func TestExecuteWithRetry_ProcessError_RetriesOnce(t *testing.T) {
 		return claudecode.NewProcessError("subprocess crashed", 1, "signal: killed")
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -101,3 +104,35 @@ func TestExecuteWithRetry_ProcessError_RetriesOnce(t *testing.T) {
 		t.Errorf("error message should contain stderr content, got: %q", errMsg)
 	}
 }
+
+// TestClassifyError_AuthError tests that expired or missing credentials are
+// classified as authentication errors rather than generic subprocess failures.
+func TestClassifyError_AuthError(t *testing.T) {
+	tests := []error{
+		claudecode.NewProcessError("subprocess failed", 1, "Invalid API key · Please run /login"),
+		errors.New("API error in result message: OAuth token has expired. Please obtain a new token or refresh your existing token."),
+	}
+	for _, err := range tests {
+		if errType := classifyError(err); errType != errTypeAuth {
+			t.Errorf("classifyError(%q) = %v, want errTypeAuth", err, errType)
+		}
+	}
+}
+
+// TestExecuteWithRetry_AuthError tests that authentication errors are not retried
+// and are reported as review.ErrAuthRequired so callers can pause.
+func TestExecuteWithRetry_AuthError(t *testing.T) {
+	callCount := 0
+	fn := func() error {
+		callCount++
+		return claudecode.NewProcessError("subprocess failed", 1, "Invalid API key · Please run /login")
+	}
+
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
+	if !errors.Is(err, review.ErrAuthRequired) {
+		t.Fatalf("expected review.ErrAuthRequired, got %v", err)
+	}
+	if callCount != 1 {
+		t.Errorf("expected 1 call (no retry for auth errors), got %d", callCount)
+	}
+}
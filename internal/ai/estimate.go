@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+// charsPerToken is the average number of characters per token of code and
+// English prose, used to estimate prompt sizes without a tokenizer
+const charsPerToken = 4
+
+// outputTokensPerReview is the expected length of a review response. Responses
+// are short JSON documents, so this is an upper estimate rather than an average.
+const outputTokensPerReview = 1500
+
+// outputTokensPerMessage is the expected length of a commit message response
+const outputTokensPerMessage = 300
+
+// outputTokensPerSummary is the expected length of the file summaries of a
+// diff chunk, a line for each file
+const outputTokensPerSummary = 1000
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64
+	Output float64
+}
+
+// modelPrices lists the published prices of Claude models by model ID prefix,
+// most specific first. Aliases such as "sonnet" match only exactly.
+var modelPrices = []struct {
+	prefix string
+	price  ModelPrice
+}{
+	{"claude-opus-4-5", ModelPrice{Input: 5, Output: 25}},
+	{"claude-opus-4", ModelPrice{Input: 15, Output: 75}},
+	{"claude-sonnet-4", ModelPrice{Input: 3, Output: 15}},
+	{"claude-3-7-sonnet", ModelPrice{Input: 3, Output: 15}},
+	{"claude-3-5-sonnet", ModelPrice{Input: 3, Output: 15}},
+	{"claude-haiku-4-5", ModelPrice{Input: 1, Output: 5}},
+	{"claude-3-5-haiku", ModelPrice{Input: 0.8, Output: 4}},
+	{"claude-3-haiku", ModelPrice{Input: 0.25, Output: 1.25}},
+}
+
+// modelAliases maps the model aliases accepted by the Claude CLI to the prices
+// of the models they currently select
+var modelAliases = map[string]ModelPrice{
+	"opus":   {Input: 5, Output: 25},
+	"sonnet": {Input: 3, Output: 15},
+	"haiku":  {Input: 1, Output: 5},
+}
+
+// PriceOf returns the price of model. Returns false for unknown models.
+func PriceOf(model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if price, ok := modelAliases[model]; ok {
+		return price, true
+	}
+	for _, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// CostEstimate is the expected size and price of a set of model requests
+type CostEstimate struct {
+	Requests     int     // Model requests made
+	InputTokens  int     // Tokens sent in prompts
+	OutputTokens int     // Tokens expected in responses
+	USD          float64 // Expected price, 0 if Priced is false
+	Priced       bool    // Whether the price of every model involved is known
+}
+
+// Add returns the combined estimate of e and other
+func (e CostEstimate) Add(other CostEstimate) CostEstimate {
+	if e.Requests == 0 {
+		return other
+	}
+	if other.Requests == 0 {
+		return e
+	}
+	return CostEstimate{
+		Requests:     e.Requests + other.Requests,
+		InputTokens:  e.InputTokens + other.InputTokens,
+		OutputTokens: e.OutputTokens + other.OutputTokens,
+		USD:          e.USD + other.USD,
+		Priced:       e.Priced && other.Priced,
+	}
+}
+
+// String describes the estimate, e.g.
+// "~52.3K input + ~9K output tokens in 6 requests, about $0.49"
+func (e CostEstimate) String() string {
+	s := fmt.Sprintf("~%s input + ~%s output tokens in %d request(s)",
+		formatTokens(e.InputTokens), formatTokens(e.OutputTokens), e.Requests)
+	if !e.Priced {
+		return s + ", cost unknown for this model"
+	}
+	return s + fmt.Sprintf(", about $%.2f", e.USD)
+}
+
+// formatTokens abbreviates a token count to thousands or millions
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000_000), ".0") + "M"
+	case n >= 1_000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1_000), ".0") + "K"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// EstimateTokens approximates the number of tokens in text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateReviews estimates the cost of reviewing diff in each of modes with
+// RunReview, splitting it into the same chunks RunReview would and pricing
+// each mode at its model's rates. Retries, promoted suggestions and the
+// tokens the Claude CLI adds to each session are not counted. Offline
+// reviews cost nothing.
+func (c *ClientWrapper) EstimateReviews(modes []review.Mode, diff *diff.Diff) CostEstimate {
+	var est CostEstimate
+	chunks := splitDiff(diff, c.diffLimit())
+	for _, mode := range modes {
+		// Linter findings are reported without calling the AI
+		if mode == review.ModeLint || c.offline {
+			continue
+		}
+		var modeEst CostEstimate
+		for i, chunk := range chunks {
+			part := ""
+			if len(chunks) > 1 {
+				part = fmt.Sprintf("part %d of %d", i+1, len(chunks))
+			}
+			modeEst.Requests++
+			modeEst.InputTokens += EstimateTokens(c.reviewPrompt(mode, chunk, part))
+			modeEst.OutputTokens += outputTokensPerReview
+		}
+		est = est.Add(modeEst.priced(c.ModelFor(mode)))
+	}
+	if est.Requests == 0 {
+		_, est.Priced = PriceOf(c.model)
+	}
+	return est
+}
+
+// EstimateCommitMessage estimates the cost of GenerateCommitMessage for diff
+// and commitContext, including the file summaries of diffs too large to send
+// whole unless they are skipped. The prompt of a summarized diff is counted at
+// the size of the truncated diff, the most it can take.
+func (c *ClientWrapper) EstimateCommitMessage(diff *diff.Diff, commitContext string) CostEstimate {
+	est := CostEstimate{
+		Requests:     1,
+		InputTokens:  EstimateTokens(c.commitPrompt("Git diff:\n"+truncateDiff(diff, c.diffLimit()), commitContext)),
+		OutputTokens: outputTokensPerMessage,
+	}.priced(c.model)
+	if len(diff.String()) <= c.diffLimit() || c.skipSummaries {
+		return est
+	}
+
+	var summaries CostEstimate
+	for _, chunk := range splitDiff(diff, c.diffLimit()) {
+		summaries.Requests++
+		summaries.InputTokens += EstimateTokens(summaryPrompt(chunk.String()))
+		summaries.OutputTokens += outputTokensPerSummary
+	}
+	return est.Add(summaries.priced(c.summaryModelName()))
+}
+
+// priced returns e with its price at the rates of model, if they are known
+func (e CostEstimate) priced(model string) CostEstimate {
+	price, ok := PriceOf(model)
+	e.Priced = ok
+	if ok {
+		e.USD = (float64(e.InputTokens)*price.Input + float64(e.OutputTokens)*price.Output) / 1_000_000
+	}
+	return e
+}
@@ -0,0 +1,172 @@
+package ai
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+func TestPriceOf(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelPrice
+		ok    bool
+	}{
+		{"claude-opus-4-5-20251101", ModelPrice{5, 25}, true},
+		{"claude-opus-4-1-20250805", ModelPrice{15, 75}, true},
+		{"claude-sonnet-4-5", ModelPrice{3, 15}, true},
+		{"claude-haiku-4-5-20251001", ModelPrice{1, 5}, true},
+		{"claude-3-5-haiku-latest", ModelPrice{0.8, 4}, true},
+		{" Sonnet ", ModelPrice{3, 15}, true},
+		{"sonnet-custom", ModelPrice{}, false},
+		{"gpt-4o", ModelPrice{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := PriceOf(tt.model)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("PriceOf(%q) = %v, %v; want %v, %v", tt.model, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEstimateReviews(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	text := fileDiff("main.go", 10)
+	modes := []review.Mode{review.ModeSecurity, review.ModeStyle}
+
+	est := client.EstimateReviews(modes, diff.NewDiff(text))
+	if est.Requests != 2 {
+		t.Errorf("Requests = %d, want 2", est.Requests)
+	}
+	want := EstimateTokens(client.reviewPrompt(review.ModeSecurity, diff.NewDiff(text), "")) + EstimateTokens(client.reviewPrompt(review.ModeStyle, diff.NewDiff(text), ""))
+	if est.InputTokens != want {
+		t.Errorf("InputTokens = %d, want %d", est.InputTokens, want)
+	}
+	if est.OutputTokens != 2*outputTokensPerReview {
+		t.Errorf("OutputTokens = %d, want %d", est.OutputTokens, 2*outputTokensPerReview)
+	}
+	if !est.Priced {
+		t.Fatal("Priced = false for a known model")
+	}
+	wantUSD := (float64(est.InputTokens)*3 + float64(est.OutputTokens)*15) / 1_000_000
+	if math.Abs(est.USD-wantUSD) > 1e-9 {
+		t.Errorf("USD = %f, want %f", est.USD, wantUSD)
+	}
+}
+
+func TestEstimateReviews_LintModeIsFree(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	text := fileDiff("main.go", 10)
+	est := client.EstimateReviews([]review.Mode{review.ModeSecurity, review.ModeLint}, diff.NewDiff(text))
+	if est.Requests != 1 {
+		t.Errorf("Requests = %d, want 1 since linter findings need no AI call", est.Requests)
+	}
+}
+
+func TestEstimateReviews_ChunksLargeDiffs(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	text := fileDiff("a.go", MaxDiffSize/40) + fileDiff("b.go", MaxDiffSize/40)
+	chunks := splitDiff(diff.NewDiff(text), MaxDiffSize)
+	if len(chunks) < 2 {
+		t.Fatalf("test diff split into %d chunk(s), want several", len(chunks))
+	}
+
+	est := client.EstimateReviews([]review.Mode{review.ModeSecurity}, diff.NewDiff(text))
+	if est.Requests != len(chunks) {
+		t.Errorf("Requests = %d, want one per chunk (%d)", est.Requests, len(chunks))
+	}
+	if est.InputTokens < EstimateTokens(text) {
+		t.Errorf("InputTokens = %d, want at least the diff's %d", est.InputTokens, EstimateTokens(text))
+	}
+}
+
+func TestEstimateReviews_PricesModeModels(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	client.SetModeModels(map[review.Mode]string{review.ModeStyle: "claude-haiku-4-5"})
+	text := fileDiff("main.go", 10)
+
+	est := client.EstimateReviews([]review.Mode{review.ModeSecurity, review.ModeStyle}, diff.NewDiff(text))
+	security := EstimateTokens(client.reviewPrompt(review.ModeSecurity, diff.NewDiff(text), ""))
+	style := EstimateTokens(client.reviewPrompt(review.ModeStyle, diff.NewDiff(text), ""))
+	wantUSD := (float64(security)*3+float64(outputTokensPerReview)*15)/1_000_000 +
+		(float64(style)*1+float64(outputTokensPerReview)*5)/1_000_000
+	if !est.Priced || math.Abs(est.USD-wantUSD) > 1e-9 {
+		t.Errorf("estimate = %+v, want USD %f with style at haiku prices", est, wantUSD)
+	}
+}
+
+func TestEstimateReviews_UnknownModel(t *testing.T) {
+	est := NewClientWrapper("local-model").EstimateReviews([]review.Mode{review.ModeSecurity}, diff.NewDiff(fileDiff("main.go", 3)))
+	if est.Priced || est.USD != 0 {
+		t.Errorf("estimate = %+v, want unpriced", est)
+	}
+	if !strings.Contains(est.String(), "cost unknown") {
+		t.Errorf("String() = %q, want it to say the cost is unknown", est.String())
+	}
+}
+
+func TestEstimateCommitMessage(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	text := fileDiff("main.go", 10)
+
+	est := client.EstimateCommitMessage(diff.NewDiff(text), "users asked for it")
+	if est.Requests != 1 {
+		t.Errorf("Requests = %d, want 1", est.Requests)
+	}
+	if want := EstimateTokens(client.commitPrompt("Git diff:\n"+text, "users asked for it")); est.InputTokens != want {
+		t.Errorf("InputTokens = %d, want %d", est.InputTokens, want)
+	}
+	if !est.Priced || est.USD == 0 {
+		t.Errorf("estimate = %+v, want priced", est)
+	}
+}
+
+func TestEstimateCommitMessage_SummarizesLargeDiffs(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	client.SetSummaryModel("claude-haiku-4-5")
+	text := fileDiff("a.go", MaxDiffSize/40) + fileDiff("b.go", MaxDiffSize/40)
+	chunks := splitDiff(diff.NewDiff(text), MaxDiffSize)
+
+	est := client.EstimateCommitMessage(diff.NewDiff(text), "")
+	if est.Requests != len(chunks)+1 {
+		t.Errorf("Requests = %d, want a summary per chunk and the message (%d)", est.Requests, len(chunks)+1)
+	}
+
+	client.SetSkipSummaries(true)
+	if est := client.EstimateCommitMessage(diff.NewDiff(text), ""); est.Requests != 1 {
+		t.Errorf("Requests = %d with summaries skipped, want 1", est.Requests)
+	}
+}
+
+func TestCostEstimate_Add(t *testing.T) {
+	priced := CostEstimate{Requests: 1, InputTokens: 100, OutputTokens: 10, USD: 0.5, Priced: true}
+	unpriced := CostEstimate{Requests: 2, InputTokens: 200, OutputTokens: 20}
+
+	got := priced.Add(unpriced)
+	want := CostEstimate{Requests: 3, InputTokens: 300, OutputTokens: 30, USD: 0.5, Priced: false}
+	if got != want {
+		t.Errorf("Add = %+v, want %+v", got, want)
+	}
+	if got := priced.Add(CostEstimate{}); got != priced {
+		t.Errorf("Add(empty) = %+v, want %+v", got, priced)
+	}
+}
+
+func TestCostEstimate_String(t *testing.T) {
+	est := CostEstimate{Requests: 6, InputTokens: 52_300, OutputTokens: 9_000, USD: 0.4869, Priced: true}
+	want := "~52.3K input + ~9K output tokens in 6 request(s), about $0.49"
+	if got := est.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := formatTokens(1_500_000); got != "1.5M" {
+		t.Errorf("formatTokens(1500000) = %q, want 1.5M", got)
+	}
+	if got := formatTokens(999); got != "999" {
+		t.Errorf("formatTokens(999) = %q, want 999", got)
+	}
+}
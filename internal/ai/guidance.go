@@ -0,0 +1,134 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// GuidanceDir is the directory, relative to the repository root, that
+// LoadGuidance reads prompt fragments from
+const GuidanceDir = ".revi/prompts"
+
+// Guidance is project-specific text added to the prompts, such as conventions
+// the reviewers should respect ("we use sqlc, don't flag raw SQL strings in
+// *.sql.go")
+type Guidance struct {
+	Review string                 // Added to every review prompt
+	Modes  map[review.Mode]string // Added to the review prompts of one mode
+	Commit string                 // Added to the commit message prompt
+}
+
+// LoadGuidance reads the prompt fragments in dir: review.md for every review,
+// <mode>.md (e.g. security.md) for the reviews of one mode, and commit.md for
+// commit messages. A missing dir is not an error; other Markdown files are,
+// so that misspelled names are not silently ignored.
+func LoadGuidance(dir string) (Guidance, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return Guidance{}, nil
+	}
+	if err != nil {
+		return Guidance{}, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var g Guidance
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".md" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return Guidance{}, fmt.Errorf("failed to read prompt fragment: %w", err)
+		}
+		text := strings.TrimSpace(string(data))
+
+		switch base := strings.TrimSuffix(name, ".md"); {
+		case base == "review":
+			g.Review = text
+		case base == "commit":
+			g.Commit = text
+		case review.GetModeInfo(review.Mode(base)).Name != "":
+			if g.Modes == nil {
+				g.Modes = make(map[review.Mode]string)
+			}
+			g.Modes[review.Mode(base)] = text
+		default:
+			return Guidance{}, fmt.Errorf("unknown prompt fragment %s: expected review.md, commit.md or one of %s",
+				filepath.Join(dir, name), strings.Join(modeFragmentNames(), ", "))
+		}
+	}
+	return g, nil
+}
+
+// modeFragmentNames returns the file names of the per-mode prompt fragments
+func modeFragmentNames() []string {
+	var names []string
+	for _, mode := range review.AllModes() {
+		names = append(names, string(mode)+".md")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge returns g with the fragments of other added after its own
+func (g Guidance) Merge(other Guidance) Guidance {
+	merged := Guidance{
+		Review: joinGuidance(g.Review, other.Review),
+		Commit: joinGuidance(g.Commit, other.Commit),
+	}
+	for _, modes := range []map[review.Mode]string{g.Modes, other.Modes} {
+		for mode, text := range modes {
+			if merged.Modes == nil {
+				merged.Modes = make(map[review.Mode]string)
+			}
+			merged.Modes[mode] = joinGuidance(merged.Modes[mode], text)
+		}
+	}
+	return merged
+}
+
+// joinGuidance joins two fragments, either of which may be empty
+func joinGuidance(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n\n" + b
+}
+
+// reviewGuidance returns the guidance for reviews of mode
+func (g Guidance) reviewGuidance(mode review.Mode) string {
+	return joinGuidance(g.Review, g.Modes[mode])
+}
+
+// guidanceSection formats a fragment for inclusion in a prompt, or returns ""
+// if there is none
+func guidanceSection(text string) string {
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("\nProject-specific guidance from the team maintaining this code (follow it over the general rules above):\n%s\n", text)
+}
+
+// goModuleSection describes the Go module being reviewed for inclusion in a
+// prompt, or returns "" if it is not one
+func goModuleSection(m *review.GoModule) string {
+	if m == nil {
+		return ""
+	}
+	section := fmt.Sprintf("\nThis code is the Go module %s: import its own packages by paths under %s and only import packages from the standard library or the modules required in go.mod.\n", m.Path, m.Path)
+	if m.GoVersion != "" {
+		section += fmt.Sprintf("go.mod declares go %s: use only language features and standard library APIs available in that version.\n", m.GoVersion)
+	}
+	return section
+}
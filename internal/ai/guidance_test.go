@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+)
+
+// writeFragments writes the named prompt fragments to a new directory
+func writeFragments(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadGuidance(t *testing.T) {
+	dir := writeFragments(t, map[string]string{
+		"review.md":   "We use sqlc; don't flag raw SQL strings in *.sql.go.\n",
+		"security.md": "Requests are authenticated by the gateway.",
+		"commit.md":   "Use the Jira key as the scope.",
+		"notes.txt":   "ignored",
+	})
+
+	g, err := LoadGuidance(dir)
+	if err != nil {
+		t.Fatalf("LoadGuidance() error = %v", err)
+	}
+	if g.Review != "We use sqlc; don't flag raw SQL strings in *.sql.go." {
+		t.Errorf("Review = %q", g.Review)
+	}
+	if g.Modes[review.ModeSecurity] != "Requests are authenticated by the gateway." || len(g.Modes) != 1 {
+		t.Errorf("Modes = %v", g.Modes)
+	}
+	if g.Commit != "Use the Jira key as the scope." {
+		t.Errorf("Commit = %q", g.Commit)
+	}
+}
+
+func TestLoadGuidance_MissingDir(t *testing.T) {
+	g, err := LoadGuidance(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("LoadGuidance() error = %v", err)
+	}
+	if g.Review != "" || g.Commit != "" || g.Modes != nil {
+		t.Errorf("LoadGuidance() = %+v, want no guidance", g)
+	}
+}
+
+func TestLoadGuidance_RejectsUnknownFragment(t *testing.T) {
+	dir := writeFragments(t, map[string]string{"securty.md": "typo"})
+	_, err := LoadGuidance(dir)
+	if err == nil || !strings.Contains(err.Error(), "securty.md") || !strings.Contains(err.Error(), "security.md") {
+		t.Errorf("LoadGuidance() error = %v, want one naming the file and the accepted names", err)
+	}
+}
+
+func TestGuidance_Merge(t *testing.T) {
+	files := Guidance{Review: "from files", Modes: map[review.Mode]string{review.ModeStyle: "tabs"}}
+	configured := Guidance{Review: "from config", Modes: map[review.Mode]string{review.ModeStyle: "gofmt", review.ModeDocs: "godoc"}, Commit: "scope"}
+
+	g := files.Merge(configured)
+	if g.Review != "from files\n\nfrom config" {
+		t.Errorf("Review = %q", g.Review)
+	}
+	if g.Modes[review.ModeStyle] != "tabs\n\ngofmt" || g.Modes[review.ModeDocs] != "godoc" {
+		t.Errorf("Modes = %v", g.Modes)
+	}
+	if g.Commit != "scope" {
+		t.Errorf("Commit = %q", g.Commit)
+	}
+}
+
+func TestReviewPrompt_IncludesGuidance(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	if prompt := client.reviewPrompt(review.ModeSecurity, diff.NewDiff("diff"), ""); strings.Contains(prompt, "Project-specific guidance") {
+		t.Error("prompt without guidance should not have a guidance section")
+	}
+
+	client.SetGuidance(Guidance{
+		Review: "We use sqlc.",
+		Modes:  map[review.Mode]string{review.ModeSecurity: "Auth is done by the gateway.", review.ModeStyle: "Tabs."},
+	})
+	prompt := client.reviewPrompt(review.ModeSecurity, diff.NewDiff("diff"), "")
+	for _, want := range []string{"Project-specific guidance", "We use sqlc.", "Auth is done by the gateway."} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "Tabs.") {
+		t.Error("prompt should not include guidance for other modes")
+	}
+	if strings.Index(prompt, "We use sqlc.") > strings.Index(prompt, "Git diff:") {
+		t.Error("guidance should come before the diff")
+	}
+}
+
+func TestGenerateCommitMessage_IncludesGuidance(t *testing.T) {
+	ctx := context.Background()
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetGuidance(Guidance{Review: "review only", Commit: "Use the Jira key as the scope."})
+
+	transport := newChunkTransport(summaryCommitResponse, 1)
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		_, err := wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff(fileDiff("main.go", 3)), "")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", err)
+	}
+	prompt := lastPrompt(transport)
+	if !strings.Contains(prompt, "Use the Jira key as the scope.") {
+		t.Errorf("prompt missing the commit guidance:\n%.500s", prompt)
+	}
+	if strings.Contains(prompt, "review only") {
+		t.Error("commit prompt should not include review guidance")
+	}
+}
+
+func TestReviewPrompt_IncludesGoModule(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	if prompt := client.reviewPrompt(review.ModeStyle, diff.NewDiff("diff"), ""); strings.Contains(prompt, "Go module") {
+		t.Error("prompt outside a Go module should not describe one")
+	}
+
+	client.SetGoModule(&review.GoModule{Path: "github.com/acme/shop", GoVersion: "1.21"})
+	prompt := client.reviewPrompt(review.ModeStyle, diff.NewDiff("diff"), "")
+	for _, want := range []string{"Go module github.com/acme/shop", "go 1.21"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestReviewPrompt_ListsScannedSecrets(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	text := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n package main\n+var key = \"AKIA" + "Q7ZJ3KX9M2PL4WRT\"\n"
+
+	prompt := client.reviewPrompt(review.ModeSecrets, diff.NewDiff(text), "")
+	if !strings.Contains(prompt, "- main.go:2: Possible AWS access key ID") {
+		t.Errorf("secrets prompt should list the scanner's findings:\n%s", prompt)
+	}
+	if strings.Index(prompt, "main.go:2") > strings.Index(prompt, "Git diff:") {
+		t.Error("findings should come before the diff")
+	}
+	if prompt := client.reviewPrompt(review.ModeSecurity, diff.NewDiff(text), ""); strings.Contains(prompt, "local scanner") {
+		t.Error("only the secrets prompt should list the scanner's findings")
+	}
+}
+
+func TestReviewPrompt_RedactsSecrets(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	key := "AKIA" + "Q7ZJ3KX9M2PL4WRT"
+	text := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n package main\n+var key = \"" + key + "\"\n"
+
+	prompt := client.reviewPrompt(review.ModeSecrets, diff.NewDiff(text), "")
+	if strings.Contains(prompt, key) || !strings.Contains(prompt, "+var key = \""+review.Redacted+"\"") {
+		t.Errorf("prompt should have the key redacted:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "- main.go:2: Possible AWS access key ID") {
+		t.Error("the scanner should still report the redacted key")
+	}
+	if strings.Contains(prompt, key[:4]) {
+		t.Errorf("prompt should not show the start of the key in the scanner's findings:\n%s", prompt)
+	}
+
+	client.SetRedactSecrets(false)
+	if prompt := client.reviewPrompt(review.ModeSecrets, diff.NewDiff(text), ""); !strings.Contains(prompt, key) {
+		t.Error("prompt should keep the key with redaction turned off")
+	}
+}
+
+func TestReviewPrompt_ListsLinterFindings(t *testing.T) {
+	client := NewClientWrapper("claude-sonnet-4-5")
+	if prompt := client.reviewPrompt(review.ModeErrors, diff.NewDiff("diff"), ""); strings.Contains(prompt, "linters reported") {
+		t.Error("prompt without linter findings should not have a linter section")
+	}
+
+	client.SetLintIssues([]review.Issue{{Severity: review.SeverityLow, Description: "error return value not checked (errcheck)", Location: "db.go:7"}})
+	prompt := client.reviewPrompt(review.ModeErrors, diff.NewDiff("diff"), "")
+	if !strings.Contains(prompt, "- db.go:7: error return value not checked (errcheck)") {
+		t.Errorf("prompt should list the linter findings:\n%s", prompt)
+	}
+	if strings.Index(prompt, "db.go:7") > strings.Index(prompt, "Git diff:") {
+		t.Error("linter findings should come before the diff")
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
 )
 
@@ -103,13 +104,13 @@ func TestModelOverridePropagates(t *testing.T) {
 // TestEmptyDiffHandling verifies the SDK client handles empty diffs gracefully.
 func TestEmptyDiffHandling(t *testing.T) {
 	// Test truncateDiff with empty string
-	result := truncateDiff("")
+	result := truncateDiff(diff.NewDiff(""), MaxDiffSize)
 	if result != "" {
 		t.Errorf("truncateDiff(\"\") = %q, want empty string", result)
 	}
 
 	// Test truncateDiff with whitespace-only string
-	result = truncateDiff("   \n\t  ")
+	result = truncateDiff(diff.NewDiff("   \n\t  "), MaxDiffSize)
 	if result != "   \n\t  " {
 		t.Errorf("truncateDiff(whitespace) changed content unexpectedly")
 	}
@@ -142,7 +143,7 @@ func TestVeryLargeDiffAtMaxDiffSizeBoundary(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			input := makeStringOfLength(tc.inputSize)
-			result := truncateDiff(input)
+			result := truncateDiff(diff.NewDiff(input), MaxDiffSize)
 
 			if tc.wantTruncate {
 				// Should be truncated with marker
@@ -279,7 +280,7 @@ func TestContextCancellationDuringRetry(t *testing.T) {
 		return claudecode.NewProcessError("subprocess failed", 1, "error")
 	}
 
-	err := executeWithRetry(ctx, fn, nil)
+	err := executeWithRetry(ctx, DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error for canceled context")
 	}
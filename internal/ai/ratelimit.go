@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// resumeSpacing is the delay between calls resuming after a rate-limit backoff,
+// so they do not all hit the API again at the same moment
+const resumeSpacing = 500 * time.Millisecond
+
+// rateLimitCoordinator shares rate-limit backoff between the concurrent calls
+// of a client. When one call is rate limited, every call waits for the backoff
+// window to end before its next attempt, and the waiting calls then resume one
+// at a time in the order they started waiting.
+type rateLimitCoordinator struct {
+	spacing time.Duration
+
+	mu         sync.Mutex
+	until      time.Time // end of the current backoff window
+	next       time.Time // earliest start of the next resumed call
+	generation int       // incremented whenever a backoff window starts or grows
+}
+
+// newRateLimitCoordinator creates a coordinator with no backoff in effect.
+func newRateLimitCoordinator() *rateLimitCoordinator {
+	return &rateLimitCoordinator{spacing: resumeSpacing}
+}
+
+// pause starts a backoff window of d, or extends the current window if it
+// would end sooner. Calls already waiting queue again behind the new window.
+func (r *rateLimitCoordinator) pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := time.Now().Add(d)
+	if end.After(r.until) {
+		debugLog("rate limited, pausing calls for %s", d)
+		r.until = end
+		r.next = end
+		r.generation++
+	}
+}
+
+// wait blocks until no backoff window is in effect and the calls that started
+// waiting earlier have resumed. Returns the context's error if ctx is done first.
+func (r *rateLimitCoordinator) wait(ctx context.Context) error {
+	for {
+		delay, generation := r.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleepWithContext(ctx, delay); err != nil {
+			return err
+		}
+		r.mu.Lock()
+		extended := r.generation != generation
+		r.mu.Unlock()
+		if !extended {
+			return nil
+		}
+	}
+}
+
+// reserve returns how long the caller must wait for its turn to resume and the
+// backoff generation the turn belongs to
+func (r *rateLimitCoordinator) reserve() (time.Duration, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if !now.Before(r.next) {
+		return 0, r.generation
+	}
+	start := r.next
+	r.next = start.Add(r.spacing)
+	return start.Sub(now), r.generation
+}
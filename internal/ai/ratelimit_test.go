@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+)
+
+// TestClassifyError_RateLimit tests that rate-limit messages from the CLI are
+// classified as rate limits rather than generic process errors.
+func TestClassifyError_RateLimit(t *testing.T) {
+	errs := []error{
+		claudecode.NewProcessError("subprocess failed", 1, `API Error: 429 {"type":"error","error":{"type":"rate_limit_error"}}`),
+		errors.New("Too Many Requests"),
+	}
+	for _, err := range errs {
+		if got := classifyError(err); got != errTypeRateLimit {
+			t.Errorf("classifyError(%q) = %v, want errTypeRateLimit", err, got)
+		}
+	}
+}
+
+// TestRateLimitCoordinator_NoBackoff tests that calls proceed immediately when
+// nothing has been rate limited.
+func TestRateLimitCoordinator_NoBackoff(t *testing.T) {
+	r := newRateLimitCoordinator()
+	start := time.Now()
+	if err := r.wait(context.Background()); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("wait() took %v without a backoff window", elapsed)
+	}
+}
+
+// TestRateLimitCoordinator_ResumesInOrder tests that calls waiting for a
+// backoff window resume after it, one at a time, in the order they waited.
+func TestRateLimitCoordinator_ResumesInOrder(t *testing.T) {
+	r := newRateLimitCoordinator()
+	r.spacing = 20 * time.Millisecond
+	start := time.Now()
+	r.pause(100 * time.Millisecond)
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := r.wait(context.Background()); err != nil {
+				t.Errorf("wait() error = %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}(i)
+		// Stagger arrivals so the waiting order is known
+		time.Sleep(10 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("calls resumed after %v, want the window plus spacing (>= 140ms)", elapsed)
+	}
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Errorf("resume order = %v, want [0 1 2]", order)
+	}
+}
+
+// TestRateLimitCoordinator_ContextCanceled tests that waiting stops when the
+// context is canceled.
+func TestRateLimitCoordinator_ContextCanceled(t *testing.T) {
+	r := newRateLimitCoordinator()
+	r.pause(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// TestExecuteWithRetry_RateLimitPausesOtherCalls tests that a rate-limited call
+// holds back other calls sharing its coordinator until the backoff ends.
+func TestExecuteWithRetry_RateLimitPausesOtherCalls(t *testing.T) {
+	r := newRateLimitCoordinator()
+	limited := make(chan struct{})
+	calls := 0
+	fn := func() error {
+		calls++
+		if calls == 1 {
+			close(limited)
+			return errors.New("rate limit exceeded")
+		}
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- executeWithRetry(context.Background(), DefaultRetryPolicy(), r, fn, nil) }()
+	<-limited
+	for paused := false; !paused; {
+		r.mu.Lock()
+		paused = r.generation > 0
+		r.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	// A call starting during the backoff waits for it
+	start := time.Now()
+	if err := executeWithRetry(context.Background(), DefaultRetryPolicy(), r, func() error { return nil }, nil); err != nil {
+		t.Fatalf("executeWithRetry() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < initialBackoff/2 {
+		t.Errorf("other call ran after %v, want it to wait for the backoff", elapsed)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("rate-limited call error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("rate-limited call made %d attempts, want 2", calls)
+	}
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// decodeReview decodes a review response, repairing it if it is not valid
+// JSON. When even the repaired response does not decode, the issues that do
+// are salvaged from its "issues" array; the error is returned if there are
+// none.
+func decodeReview(response string) (review.Result, error) {
+	var result review.Result
+	err := decodeResponse(response, reviewSchema, &result)
+	if err == nil {
+		return result, nil
+	}
+	debugLog("decodeReview: %v, repairing the response", err)
+	text := stripMarkdownCodeFences(response)
+	if start := strings.IndexByte(text, '{'); start >= 0 {
+		var repaired review.Result
+		if decodeResponse(repairJSON(text[start:]), reviewSchema, &repaired) == nil {
+			return repaired, nil
+		}
+	}
+	if issues := salvageIssues(text); len(issues) > 0 {
+		return review.Result{
+			Summary: "The review response was malformed; only the issues that could be read are shown.",
+			Issues:  issues,
+		}, nil
+	}
+	return review.Result{}, err
+}
+
+// danglingKey matches a key at the end of an object cut short before its
+// value, after the brace or comma preceding it
+var danglingKey = regexp.MustCompile(`([{,])\s*"(?:[^"\\]|\\.)*"\s*:?$`)
+
+// repairJSON fixes the mistakes models make when writing a JSON object by
+// hand: commas before a closing bracket, and strings, arrays and objects left
+// open when the response was cut short. Text after the object is dropped.
+func repairJSON(text string) string {
+	var b strings.Builder
+	var closers []byte
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			closers = append(closers, '}')
+		case c == '[':
+			closers = append(closers, ']')
+		case c == '}' || c == ']':
+			trimTrailingComma(&b)
+			if len(closers) > 0 {
+				closers = closers[:len(closers)-1]
+			}
+		}
+		b.WriteByte(c)
+		if !inString && len(closers) == 0 && (c == '}' || c == ']') {
+			return b.String()
+		}
+	}
+
+	if escaped {
+		s := b.String()
+		b.Reset()
+		b.WriteString(s[:len(s)-1])
+	}
+	if inString {
+		b.WriteByte('"')
+	}
+	// A key left without its value cannot be completed
+	s := strings.TrimRight(b.String(), " \t\r\n")
+	if len(closers) > 0 && closers[len(closers)-1] == '}' {
+		if loc := danglingKey.FindStringSubmatchIndex(s); loc != nil {
+			s = s[:loc[3]]
+		}
+	}
+	b.Reset()
+	b.WriteString(s)
+	trimTrailingComma(&b)
+	for i := len(closers) - 1; i >= 0; i-- {
+		b.WriteByte(closers[i])
+	}
+	return b.String()
+}
+
+// trimTrailingComma removes a comma, and the whitespace after it, from the
+// end of b
+func trimTrailingComma(b *strings.Builder) {
+	s := strings.TrimRight(b.String(), " \t\r\n")
+	if !strings.HasSuffix(s, ",") {
+		return
+	}
+	b.Reset()
+	b.WriteString(s[:len(s)-1])
+}
+
+// salvageIssues returns the objects of the "issues" array of text that are
+// valid issues, skipping those that are not
+func salvageIssues(text string) []review.Issue {
+	_, rest, ok := strings.Cut(text, `"issues"`)
+	if !ok {
+		return nil
+	}
+	start := strings.IndexByte(rest, '[')
+	if start < 0 {
+		return nil
+	}
+
+	var issues []review.Issue
+	for rest = rest[start+1:]; ; {
+		rest = strings.TrimLeft(rest, " \t\r\n,")
+		if !strings.HasPrefix(rest, "{") {
+			return issues
+		}
+		end := objectEnd(rest)
+		if end == 0 {
+			end = len(rest)
+		}
+		var issue review.Issue
+		if decodeResponse(repairJSON(rest[:end]), issueSchema, &issue) == nil {
+			issues = append(issues, issue)
+		}
+		rest = rest[end:]
+	}
+}
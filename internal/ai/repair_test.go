@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"a": [1, 2,], "b": {"c": 3,},}`:   `{"a": [1, 2], "b": {"c": 3}}`,
+		`{"summary": "cut short`:            `{"summary": "cut short"}`,
+		`{"issues": [{"severity": "low"`:    `{"issues": [{"severity": "low"}]}`,
+		`{"issues": [{"severity":`:          `{"issues": [{}]}`,
+		`{"a": 1, "b`:                       `{"a": 1}`,
+		`{"a": ["x", "y`:                    `{"a": ["x", "y"]}`,
+		`{"a": "}"} trailing text {"b": 1}`: `{"a": "}"}`,
+		`{"a": "escaped \"quote\" and \\"}`: `{"a": "escaped \"quote\" and \\"}`,
+	}
+	for text, want := range tests {
+		if got := repairJSON(text); got != want {
+			t.Errorf("repairJSON(%q) = %q, want %q", text, got, want)
+		}
+	}
+	if got := repairJSON(`{"a": [1, 2,], "b": {"c": 3,},}`); !json.Valid([]byte(got)) {
+		t.Errorf("repairJSON() = %q, want valid JSON", got)
+	}
+}
+
+func TestDecodeReview(t *testing.T) {
+	// Cut short in the second issue
+	result, err := decodeReview(`{"summary": "Two issues", "issues": [{"severity": "high", "description": "SQL injection"}, {"severity": "low", "description": "Nam`)
+	if err != nil {
+		t.Fatalf("decodeReview() error = %v", err)
+	}
+	if result.Summary != "Two issues" || len(result.Issues) != 2 || result.Issues[0].Description != "SQL injection" {
+		t.Errorf("decodeReview() = %+v, want the repaired result", result)
+	}
+
+	// The result is broken beyond repair, but its first issue is not
+	result, err = decodeReview(`{"summary": 42, "issues": [{"severity": "high", "description": "SQL injection"}, {"severity": 1}]}`)
+	if err != nil {
+		t.Fatalf("decodeReview() error = %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Description != "SQL injection" || result.Summary == "" {
+		t.Errorf("decodeReview() = %+v, want the salvaged issue", result)
+	}
+
+	if _, err := decodeReview("The diff looks fine to me."); err == nil {
+		t.Error("decodeReview() of prose without JSON should fail")
+	}
+}
+
+// TestRunReview_UnparseableResponse verifies that a response that cannot be
+// read fails only its review, keeping the response.
+func TestRunReview_UnparseableResponse(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	response := "I reviewed the diff and the query on line 42 is unsafe."
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{&claudecode.TextBlock{Text: response}},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	var result *review.Result
+	var reviewErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		result, reviewErr = wrapper.RunReview(ctx, client, review.ModeSecurity, diff.NewDiff("diff content here"))
+		return nil
+	})
+	if err != nil || reviewErr != nil {
+		t.Fatalf("RunReview() error = %v, %v, want the failure in the result", err, reviewErr)
+	}
+	if result.Status != review.StatusFailed || result.Raw != response || result.Mode != review.ModeSecurity {
+		t.Errorf("RunReview() = %+v, want a failed result with the response", result)
+	}
+}
@@ -5,26 +5,57 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/review"
 )
 
-// Retry configuration constants
+// Retry configuration defaults
 const (
 	maxRateLimitRetries = 3
 	maxNetworkRetries   = 1
 	maxProcessRetries   = 1
 	initialBackoff      = 1 * time.Second
-	networkRetryDelay   = 2 * time.Second
-	processRetryDelay   = 2 * time.Second
+	retryDelay          = 2 * time.Second
 )
 
+// RetryPolicy sets how often and for how long failed AI calls are retried.
+// Counts are per call and per kind of failure.
+type RetryPolicy struct {
+	RateLimitRetries int           // Retries after a rate limit
+	NetworkRetries   int           // Retries after a network or connection error
+	ProcessRetries   int           // Retries after the Claude Code CLI subprocess fails
+	Backoff          time.Duration // First rate-limit backoff, doubled on each retry
+	Delay            time.Duration // Wait before retrying network and subprocess failures
+	Deadline         time.Duration // Longest time one call may spend retrying; 0 for no limit
+}
+
+// DefaultRetryPolicy returns the policy used unless SetRetryPolicy is called:
+// three rate-limit retries starting at a one second backoff, and one retry of
+// network and subprocess failures after two seconds, with no deadline.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		RateLimitRetries: maxRateLimitRetries,
+		NetworkRetries:   maxNetworkRetries,
+		ProcessRetries:   maxProcessRetries,
+		Backoff:          initialBackoff,
+		Delay:            retryDelay,
+	}
+}
+
+// allows reports whether a retry waiting wait may start, given that the call
+// started at start
+func (p RetryPolicy) allows(start time.Time, wait time.Duration) bool {
+	return p.Deadline <= 0 || time.Since(start)+wait <= p.Deadline
+}
+
 // Error messages for user-friendly output
 const (
 	errMsgCLINotFound = "claude Code CLI not found, install with: npm install -g @anthropic-ai/claude-code"
-	errMsgAuth        = "claude CLI authentication required, run 'claude login' to authenticate"
-	errMsgRateLimit   = "rate limit exceeded after 3 retries"
+	errMsgRateLimit   = "rate limit exceeded after %d retries"
 	errMsgNetwork     = "network error: %s"
 	errMsgConnection  = "connection to Claude Code CLI failed: %s"
 	errMsgProcess     = "claude Code CLI subprocess failed: %s"
@@ -37,19 +68,30 @@ type StreamCallback func(content StreamContent)
 
 // executeWithRetry wraps an API call with retry logic based on error type.
 // It handles CLI errors, subprocess failures, network errors, and timeouts
-// according to the claude-code-sdk-go error types.
-func executeWithRetry(ctx context.Context, fn func() error, streamCallback StreamCallback) error {
+// according to the claude-code-sdk-go error types. Rate-limit backoff goes
+// through rateLimits, so concurrent calls sharing it wait for the backoff too;
+// a nil coordinator backs off this call only. policy sets how many retries each
+// kind of failure gets and how long they may take.
+func executeWithRetry(ctx context.Context, policy RetryPolicy, rateLimits *rateLimitCoordinator, fn func() error, streamCallback StreamCallback) error {
+	if rateLimits == nil {
+		rateLimits = newRateLimitCoordinator()
+	}
 	var lastErr error
 	rateLimitRetries := 0
 	networkRetries := 0
 	processRetries := 0
-	backoff := initialBackoff
+	backoff := policy.Backoff
+	start := time.Now()
 
 	for {
 		// Check context before attempting
 		if err := ctx.Err(); err != nil {
 			return err
 		}
+		// Hold off while any call sharing the coordinator is backing off
+		if err := rateLimits.wait(ctx); err != nil {
+			return err
+		}
 
 		lastErr = fn()
 		if lastErr == nil {
@@ -66,46 +108,44 @@ func executeWithRetry(ctx context.Context, fn func() error, streamCallback Strea
 
 		case errTypeAuth:
 			// Authentication required - no retry, guide user to login
-			return errors.New(errMsgAuth)
+			return review.ErrAuthRequired
 
 		case errTypeRateLimit:
-			// Rate limit - retry with exponential backoff
-			rateLimitRetries++
-			if rateLimitRetries > maxRateLimitRetries {
-				return errors.New(errMsgRateLimit)
-			}
-			if err := sleepWithContext(ctx, backoff); err != nil {
-				return err
+			// Rate limit - pause all calls and retry with exponential backoff
+			if rateLimitRetries >= policy.RateLimitRetries || !policy.allows(start, backoff) {
+				return fmt.Errorf(errMsgRateLimit, rateLimitRetries)
 			}
+			rateLimitRetries++
+			rateLimits.pause(backoff)
 			backoff *= 2 // Exponential backoff
 
 		case errTypeConnection:
-			// Connection error - retry once
+			// Connection error - retry after a delay
 			networkRetries++
-			if networkRetries > maxNetworkRetries {
+			if networkRetries > policy.NetworkRetries || !policy.allows(start, policy.Delay) {
 				return fmt.Errorf(errMsgConnection, extractErrorMsg(lastErr))
 			}
-			if err := sleepWithContext(ctx, networkRetryDelay); err != nil {
+			if err := sleepWithContext(ctx, policy.Delay); err != nil {
 				return err
 			}
 
 		case errTypeProcess:
-			// Subprocess crash - retry once
+			// Subprocess crash - retry after a delay
 			processRetries++
-			if processRetries > maxProcessRetries {
+			if processRetries > policy.ProcessRetries || !policy.allows(start, policy.Delay) {
 				return fmt.Errorf(errMsgProcess, extractProcessErrorMsg(lastErr))
 			}
-			if err := sleepWithContext(ctx, processRetryDelay); err != nil {
+			if err := sleepWithContext(ctx, policy.Delay); err != nil {
 				return err
 			}
 
 		case errTypeNetwork:
-			// Network error - retry once
+			// Network error - retry after a delay
 			networkRetries++
-			if networkRetries > maxNetworkRetries {
+			if networkRetries > policy.NetworkRetries || !policy.allows(start, policy.Delay) {
 				return fmt.Errorf(errMsgNetwork, extractNetworkErrorMsg(lastErr))
 			}
-			if err := sleepWithContext(ctx, networkRetryDelay); err != nil {
+			if err := sleepWithContext(ctx, policy.Delay); err != nil {
 				return err
 			}
 
@@ -159,6 +199,17 @@ func classifyError(err error) errorType {
 		return errTypeCLINotFound
 	}
 
+	// Authentication failures surface as process errors or error results,
+	// so check for them before the generic process classification
+	if isAuthError(err) {
+		return errTypeAuth
+	}
+
+	// Rate limits are reported the same way
+	if isRateLimitError(err) {
+		return errTypeRateLimit
+	}
+
 	var processErr *claudecode.ProcessError
 	if errors.As(err, &processErr) {
 		return errTypeProcess
@@ -189,6 +240,70 @@ func classifyError(err error) errorType {
 	return errTypeUnknown
 }
 
+// authErrorPatterns are lowercase fragments of the messages the Claude Code CLI
+// emits when its credentials are missing, invalid or expired
+var authErrorPatterns = []string{
+	"invalid api key",
+	"please run /login",
+	"claude login",
+	"not logged in",
+	"authentication_error",
+	"authentication failed",
+	"oauth token has expired",
+	"token expired",
+}
+
+// isAuthError checks if an error indicates missing or expired credentials
+func isAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	text := err.Error()
+	var processErr *claudecode.ProcessError
+	if errors.As(err, &processErr) {
+		text += " " + processErr.Stderr
+	}
+	text = strings.ToLower(text)
+
+	for _, pattern := range authErrorPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitErrorPatterns are lowercase fragments of the messages the Claude
+// Code CLI emits when the API rejects a request for exceeding a rate limit
+var rateLimitErrorPatterns = []string{
+	"rate limit",
+	"rate_limit_error",
+	"too many requests",
+	"api error: 429",
+}
+
+// isRateLimitError checks if an error indicates the request was rate limited
+func isRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	text := err.Error()
+	var processErr *claudecode.ProcessError
+	if errors.As(err, &processErr) {
+		text += " " + processErr.Stderr
+	}
+	text = strings.ToLower(text)
+
+	for _, pattern := range rateLimitErrorPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 // isNetworkError checks if an error is a network-related error
 func isNetworkError(err error) bool {
 	if err == nil {
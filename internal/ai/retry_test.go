@@ -19,7 +19,7 @@ func TestExecuteWithRetry_NetworkError_RetriesOnceAfter2s(t *testing.T) {
 		return &net.DNSError{Err: "no such host", IsNotFound: true}
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -52,7 +52,7 @@ func TestExecuteWithRetry_Success(t *testing.T) {
 		return nil
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -70,7 +70,7 @@ func TestExecuteWithRetry_ContextCanceled(t *testing.T) {
 		return errors.New("should not be called")
 	}
 
-	err := executeWithRetry(ctx, fn, nil)
+	err := executeWithRetry(ctx, DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error for canceled context, got nil")
 	}
@@ -87,7 +87,7 @@ func TestExecuteWithRetry_TimeoutError(t *testing.T) {
 		return context.DeadlineExceeded
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -109,7 +109,7 @@ func TestExecuteWithRetry_UnknownError(t *testing.T) {
 		return unknownErr
 	}
 
-	err := executeWithRetry(context.Background(), fn, nil)
+	err := executeWithRetry(context.Background(), DefaultRetryPolicy(), nil, fn, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -120,3 +120,53 @@ func TestExecuteWithRetry_UnknownError(t *testing.T) {
 		t.Errorf("error message = %q, want %q", err.Error(), unknownErr.Error())
 	}
 }
+
+// TestExecuteWithRetry_Policy tests that the policy sets the retry counts and delays.
+func TestExecuteWithRetry_Policy(t *testing.T) {
+	policy := RetryPolicy{RateLimitRetries: 2, NetworkRetries: 3, Backoff: time.Millisecond, Delay: time.Millisecond}
+
+	callCount := 0
+	err := executeWithRetry(context.Background(), policy, nil, func() error {
+		callCount++
+		return &net.DNSError{Err: "no such host"}
+	}, nil)
+	if err == nil || callCount != 4 {
+		t.Errorf("network error: %d calls, err = %v, want 4 calls and an error", callCount, err)
+	}
+
+	callCount = 0
+	err = executeWithRetry(context.Background(), policy, nil, func() error {
+		callCount++
+		return errors.New("rate limit exceeded")
+	}, nil)
+	if callCount != 3 || err == nil || err.Error() != "rate limit exceeded after 2 retries" {
+		t.Errorf("rate limit: %d calls, err = %v, want 3 calls and the retry count", callCount, err)
+	}
+
+	policy.RateLimitRetries = 0
+	callCount = 0
+	_ = executeWithRetry(context.Background(), policy, nil, func() error {
+		callCount++
+		return errors.New("rate limit exceeded")
+	}, nil)
+	if callCount != 1 {
+		t.Errorf("rate limit without retries: %d calls, want 1", callCount)
+	}
+}
+
+// TestExecuteWithRetry_Deadline tests that no retry starts that would end after the deadline.
+func TestExecuteWithRetry_Deadline(t *testing.T) {
+	policy := RetryPolicy{NetworkRetries: 10, Delay: 20 * time.Millisecond, Deadline: 50 * time.Millisecond}
+	callCount := 0
+	start := time.Now()
+	err := executeWithRetry(context.Background(), policy, nil, func() error {
+		callCount++
+		return &net.DNSError{Err: "no such host"}
+	}, nil)
+	if err == nil || callCount < 2 || callCount > 3 {
+		t.Errorf("%d calls, err = %v, want the retries fitting in the deadline and an error", callCount, err)
+	}
+	if elapsed := time.Since(start); elapsed > policy.Deadline {
+		t.Errorf("retried for %s, want at most %s", elapsed, policy.Deadline)
+	}
+}
@@ -0,0 +1,270 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// structuredOutputTool is the tool the Claude CLI calls with the response
+// when it is asked for output matching a JSON Schema. Its input is the
+// response, already checked against the schema.
+const structuredOutputTool = "StructuredOutput"
+
+// jsonSchema is the subset of JSON Schema revi describes its responses with
+type jsonSchema struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+}
+
+// detectionSchema describes a review.DetectionResult
+var detectionSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"modes":     {Type: "array", Items: &jsonSchema{Type: "string", Enum: modeNames()}},
+		"reasoning": {Type: "string", Description: "brief explanation"},
+	},
+	Required: []string{"modes"},
+}
+
+// fixSchema describes a review.Fix
+var fixSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"available":    {Type: "boolean"},
+		"code":         {Type: "string", Description: "replacement code with proper indentation (only if available=true)"},
+		"file_path":    {Type: "string", Description: "path/to/file.go (only if available=true)"},
+		"start_line":   {Type: "integer"},
+		"end_line":     {Type: "integer"},
+		"explanation":  {Type: "string", Description: "why this fix works (only if available=true)"},
+		"reason":       {Type: "string", Description: "why fix unavailable (only if available=false)"},
+		"alternatives": {Type: "array", Items: &jsonSchema{Type: "string", Description: "manual step"}},
+	},
+	Required: []string{"available"},
+}
+
+// issueSchema describes a review.Issue as reported by the model
+var issueSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"severity":    {Type: "string", Enum: []string{"high", "medium", "low"}},
+		"description": {Type: "string", Description: "issue description"},
+		"location":    {Type: "string", Description: "file:line if known"},
+		"confidence":  {Type: "number", Description: "how sure you are the issue is real, from 0 to 1"},
+		"fix":         fixSchema,
+	},
+	Required: []string{"severity", "description"},
+}
+
+// reviewSchema describes a review.Result as reported by the model
+var reviewSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"mode":        {Type: "string", Enum: modeNames()},
+		"status":      {Type: "string", Enum: []string{string(review.StatusIssues), string(review.StatusNoIssues)}},
+		"summary":     {Type: "string", Description: "brief 1-2 sentence summary"},
+		"issues":      {Type: "array", Items: issueSchema},
+		"suggestions": {Type: "array", Items: &jsonSchema{Type: "string"}},
+	},
+	Required: []string{"summary"},
+}
+
+// commitMessageSchema describes a CommitMessage
+var commitMessageSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"type":    {Type: "string", Enum: []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "chore"}},
+		"scope":   {Type: "string", Description: "optional scope"},
+		"subject": {Type: "string", Description: "imperative mood, lowercase, no period, max 50 chars"},
+		"body":    {Type: "string", Description: "optional longer description explaining WHY this change was made"},
+	},
+	Required: []string{"type", "subject"},
+}
+
+// fileSummariesSchema describes the response to a summarizeChunk prompt
+var fileSummariesSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"files": {Type: "array", Items: &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"path":    {Type: "string"},
+				"summary": {Type: "string"},
+			},
+			Required: []string{"path"},
+		}},
+	},
+	Required: []string{"files"},
+}
+
+// commitGroupsSchema describes the response to a SuggestCommitGroups prompt
+var commitGroupsSchema = &jsonSchema{
+	Type: "object",
+	Properties: map[string]*jsonSchema{
+		"groups": {Type: "array", Items: &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"files":  {Type: "array", Items: &jsonSchema{Type: "string", Description: "path as in the diff"}},
+				"reason": {Type: "string", Description: "one line saying what the commit does"},
+			},
+			Required: []string{"files"},
+		}},
+	},
+	Required: []string{"groups"},
+}
+
+// modeNames returns the names of all review modes
+func modeNames() []string {
+	var names []string
+	for _, mode := range review.AllModes() {
+		names = append(names, string(mode))
+	}
+	return names
+}
+
+// String returns s as indented JSON for inclusion in a prompt
+func (s *jsonSchema) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// check reports the first way v, a decoded JSON value, does not match s.
+// Missing required fields and values of the wrong type are errors; enums are
+// left to callers, which already tolerate unknown severities and modes. A
+// null value matches any schema.
+func (s *jsonSchema) check(v any, path string) error {
+	if v == nil {
+		return nil
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not an object", path)
+		}
+		for _, name := range s.Required {
+			if obj[name] == nil {
+				return fmt.Errorf("%s has no %q", path, name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			if err := s.Properties[name].check(obj[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s is not an array", path)
+		}
+		for i, item := range items {
+			if err := s.Items.check(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s is not a string", path)
+		}
+	case "integer", "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s is not a number", path)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s is not a boolean", path)
+		}
+	}
+	return nil
+}
+
+// decodeResponse decodes response, the answer to a prompt asking for JSON
+// matching s, into v, and reports an error if it does not match s. A
+// response the CLI returned through its structured output tool (see
+// callAPIWithStreaming) decodes as is. When the model answered in text
+// instead, the JSON is taken from a markdown code fence or, failing that,
+// from the first JSON object in the text.
+func decodeResponse(response string, s *jsonSchema, v any) error {
+	text := findJSON(response)
+
+	var raw any
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return err
+	}
+	if err := s.check(raw, "response"); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(text), v)
+}
+
+// findJSON returns the JSON in response, or response with any code fence
+// removed if none is found
+func findJSON(response string) string {
+	text := strings.TrimSpace(response)
+	if json.Valid([]byte(text)) {
+		return text
+	}
+	stripped := stripMarkdownCodeFences(text)
+	if json.Valid([]byte(stripped)) {
+		return stripped
+	}
+	if obj := extractJSON(text); obj != "" {
+		return obj
+	}
+	return stripped
+}
+
+// extractJSON returns the first complete JSON object in text, such as a
+// response that explains itself before or after the JSON, or "" if it has
+// none
+func extractJSON(text string) string {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		if end := objectEnd(text[start:]); end > 0 && json.Valid([]byte(text[start:start+end])) {
+			return text[start : start+end]
+		}
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return ""
+}
+
+// objectEnd returns the length of the object text starts with, by matching
+// its braces outside strings, or 0 if it is not closed
+func objectEnd(text string) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
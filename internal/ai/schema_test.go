@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string
+	}{
+		{"plain", `{"type": "fix", "subject": "handle nil config"}`, ""},
+		{"fenced", "```json\n{\"type\": \"fix\", \"subject\": \"handle nil config\"}\n```", ""},
+		{"prose around", "Here is the commit message:\n{\"type\": \"fix\", \"subject\": \"handle nil config\", \"body\": \"Keeps {braces} in strings\"}\nLet me know if it needs changes.", ""},
+		{"missing required", `{"type": "fix"}`, `response has no "subject"`},
+		{"wrong type", `{"type": "fix", "subject": 42}`, "response.subject is not a string"},
+		{"no json", "I cannot write a commit message for this diff.", "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg CommitMessage
+			err := decodeResponse(tt.response, commitMessageSchema, &msg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeResponse() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeResponse() error = %v", err)
+			}
+			if msg.Type != "fix" || msg.Subject != "handle nil config" {
+				t.Errorf("decodeResponse() = %+v, want the fix message", msg)
+			}
+		})
+	}
+}
+
+func TestDecodeResponse_NestedSchema(t *testing.T) {
+	var result review.Result
+	err := decodeResponse(`{"summary": "s", "issues": [{"severity": "low", "description": "d", "fix": {"available": "yes"}}]}`, reviewSchema, &result)
+	if err == nil || err.Error() != "response.issues[0].fix.available is not a boolean" {
+		t.Errorf("decodeResponse() error = %v, want the path of the bad value", err)
+	}
+
+	// Unknown enum values are left to the severity normalizer
+	err = decodeResponse(`{"summary": "s", "issues": [{"severity": "critical", "description": "d", "location": null}]}`, reviewSchema, &result)
+	if err != nil || result.Issues[0].Severity != "critical" {
+		t.Errorf("decodeResponse() = %+v, %v, want the issue decoded as reported", result, err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		`Result: {"a": "}"} done`:           `{"a": "}"}`,
+		`use {braces} then {"a": {"b": 1}}`: `{"a": {"b": 1}}`,
+		`{"a": "\"}"}`:                      `{"a": "\"}"}`,
+		`{"a": 1`:                           "",
+		"no object":                         "",
+	}
+	for text, want := range tests {
+		if got := extractJSON(text); got != want {
+			t.Errorf("extractJSON(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// TestDetectModes_StructuredOutput verifies that the input of the CLI's
+// structured output tool is used over the text around it.
+func TestDetectModes_StructuredOutput(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{
+			&claudecode.TextBlock{Text: "Looking at the diff, security applies."},
+			&claudecode.ToolUseBlock{
+				ToolUseID: "tool_1",
+				Name:      structuredOutputTool,
+				Input: map[string]any{
+					"modes":     []any{"security"},
+					"reasoning": "touches authentication",
+				},
+			},
+		},
+	}
+	transport.msgChan <- &claudecode.ResultMessage{}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	var result *review.DetectionResult
+	var detectErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		result, detectErr = wrapper.DetectModes(ctx, client, diff.NewDiff("diff content here"))
+		return nil
+	})
+	if err != nil || detectErr != nil {
+		t.Fatalf("DetectModes() error = %v, %v", err, detectErr)
+	}
+	if len(result.Modes) != 1 || result.Modes[0] != review.ModeSecurity || result.Reasoning != "touches authentication" {
+		t.Errorf("DetectModes() = %+v, want the structured output", result)
+	}
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// CommitGroup is a set of files whose changes belong in one commit.
+type CommitGroup struct {
+	Files  []string `json:"files"`
+	Reason string   `json:"reason"`
+}
+
+// SuggestCommitGroups asks how the changes of a diff divide into logically
+// separate commits, for staging and committing them one group at a time.
+// The groups are as the model gave them; files may be missing, repeated or
+// unknown, so callers should normalize them against the files of the diff.
+// Requires a connected SDK client - use within RunWithClient callback.
+func (c *ClientWrapper) SuggestCommitGroups(ctx context.Context, client claudecode.Client, diff *diff.Diff) ([]CommitGroup, error) {
+	paths := diff.Paths()
+	debugLog("SuggestCommitGroups called (%d files, diff length: %d)", len(paths), len(diff.String()))
+
+	prompt := fmt.Sprintf(`The following git diff mixes several changes. Group its files into logically separate commits, each of which could be reviewed and reverted on its own, in the order they should be committed (e.g. a refactoring before the feature relying on it).
+
+Respond with ONLY a JSON object matching this JSON Schema:
+%s
+
+Put every file in exactly one group. Keep files together when one does not build or make sense without the other. A single group is fine if the changes are one logical change.
+
+Files:
+%s
+
+Git diff:
+%s`, commitGroupsSchema, strings.Join(paths, "\n"), truncateDiff(c.redact(diff), c.diffLimit()))
+
+	var response string
+	err := executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
+		var callErr error
+		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, "")
+		return callErr
+	}, c.streamCallback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group changes: %w", err)
+	}
+
+	var parsed struct {
+		Groups []CommitGroup `json:"groups"`
+	}
+	if err := decodeResponse(response, commitGroupsSchema, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse commit groups: %w (response: %s)", err, response)
+	}
+	return parsed.Groups, nil
+}
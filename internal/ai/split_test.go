@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+func TestSuggestCommitGroups(t *testing.T) {
+	transport := newMockTransport()
+	ctx := context.Background()
+
+	transport.msgChan <- &claudecode.AssistantMessage{
+		Content: []claudecode.ContentBlock{&claudecode.TextBlock{Text: "```json\n" + `{"groups": [
+  {"files": ["db/migrate.sql"], "reason": "add the users table"},
+  {"files": ["api/users.go", "api/users_test.go"], "reason": "serve users"}
+]}` + "\n```"}},
+	}
+	close(transport.msgChan)
+
+	wrapper := NewClientWrapper("claude-sonnet-4-20250514")
+	var groups []CommitGroup
+	var groupErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		groups, groupErr = wrapper.SuggestCommitGroups(ctx, client, diff.NewDiff("diff --git a/db/migrate.sql b/db/migrate.sql\n"))
+		return nil
+	})
+	if err != nil || groupErr != nil {
+		t.Fatalf("SuggestCommitGroups() error = %v, %v", err, groupErr)
+	}
+	if len(groups) != 2 || groups[0].Reason != "add the users table" || !slices.Equal(groups[1].Files, []string{"api/users.go", "api/users_test.go"}) {
+		t.Errorf("SuggestCommitGroups() = %+v", groups)
+	}
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+)
+
+// PromoteSuggestion turns a review suggestion into a tracked issue with a
+// severity, a location and a fix attempt for the diff.
+// Requires a connected SDK client - use within RunWithClient callback.
+func (c *ClientWrapper) PromoteSuggestion(ctx context.Context, client claudecode.Client, mode review.Mode, diff *diff.Diff, suggestion string) (*review.Issue, error) {
+	modeInfo := review.GetModeInfo(mode)
+
+	prompt := fmt.Sprintf(`You are a code reviewer focused ONLY on %s concerns.
+
+During review of the git diff below you made this suggestion:
+%s
+
+Turn the suggestion into a single concrete issue and respond with ONLY valid JSON in this exact format:
+{
+  "severity": "high|medium|low",
+  "description": "issue description",
+  "location": "file:line if known",
+  "fix": {
+    "available": true or false,
+    "code": "replacement code with proper indentation (only if available=true)",
+    "file_path": "path/to/file.go (only if available=true)",
+    "start_line": 42,
+    "end_line": 42,
+    "explanation": "why this fix works (only if available=true)",
+    "reason": "why fix unavailable (only if available=false)",
+    "alternatives": ["manual step 1", "manual step 2"]
+  }
+}
+
+Important:
+- The fix MUST be real, working code - NEVER use TODO comments, placeholder text, or "implement this" stubs
+- Only set available=false when the fix truly requires human judgment, and explain why in "reason"
+%s%s
+Git diff:
+%s`, modeInfo.Name, suggestion, goModuleSection(c.goModule), guidanceSection(c.guidance.reviewGuidance(mode)), truncateDiff(c.redact(diff), c.diffLimit()))
+
+	var response string
+	err := executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
+		var callErr error
+		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, mode)
+		return callErr
+	}, c.streamCallback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to promote suggestion: %w", err)
+	}
+
+	var issue review.Issue
+	if err := decodeResponse(response, issueSchema, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse promoted suggestion: %w (response: %s)", err, response)
+	}
+	if issue.Description == "" {
+		issue.Description = suggestion
+	}
+
+	severities := c.severities
+	if severities == nil {
+		severities = review.DefaultSeverityNormalizer()
+	}
+	result := review.Result{Issues: []review.Issue{issue}}
+	severities.NormalizeResult(&result)
+	review.NormalizePaths(&result, c.repoRoot)
+	c.goModule.ValidateFixes(&result)
+	return &result.Issues[0], nil
+}
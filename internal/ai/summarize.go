@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// FileSummary describes the changes made to one file of a diff.
+type FileSummary struct {
+	Path    string `json:"path"`
+	Summary string `json:"summary"`
+}
+
+// summarizeDiff summarizes each file of a diff too large for a single request,
+// so a commit message can reflect every file instead of a truncated diff. The
+// diff is split into chunks (see splitDiff) summarized in parallel with the
+// summary model. Summaries of a file split across chunks are joined.
+func (c *ClientWrapper) summarizeDiff(ctx context.Context, client claudecode.Client, diff *diff.Diff) ([]FileSummary, error) {
+	chunks := splitDiff(diff, c.diffLimit())
+	debugLog("summarizeDiff: summarizing %d chunks with %s", len(chunks), c.summaryModelName())
+
+	// Summaries are intermediate output, so they are not streamed to the caller
+	quiet := *c
+	quiet.streamCallback = nil
+	quiet.model = c.summaryModelName()
+
+	summaries := make([][]FileSummary, len(chunks))
+	errs := make([]error, len(chunks))
+	done := make([]bool, len(chunks))
+	jobs := make(chan int, len(chunks))
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+
+	work := func(client claudecode.Client) {
+		for i := range jobs {
+			summaries[i], errs[i] = quiet.summarizeChunk(ctx, client, chunks[i])
+			done[i] = true
+		}
+	}
+
+	workers := min(len(chunks), MaxParallelChunks)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	first := 0
+	if c.summaryModelName() == c.model {
+		// The caller's connection already uses the right model
+		first = 1
+		go func() {
+			defer wg.Done()
+			work(client)
+		}()
+	}
+	for w := first; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			// If the connection fails, the other workers take its chunks
+			err := c.connectModel(ctx, c.summaryModelName(), func(client claudecode.Client) error {
+				work(client)
+				return nil
+			})
+			if err != nil {
+				debugLog("summarizeDiff: connection failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var merged []FileSummary
+	index := make(map[string]int)
+	for i := range chunks {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if !done[i] {
+			return nil, fmt.Errorf("part %d of %d was not summarized", i+1, len(chunks))
+		}
+		for _, s := range summaries[i] {
+			if j, ok := index[s.Path]; ok {
+				merged[j].Summary += "; " + s.Summary
+				continue
+			}
+			index[s.Path] = len(merged)
+			merged = append(merged, s)
+		}
+	}
+	return merged, nil
+}
+
+// summarizeChunk asks for a one-line summary of each file in a chunk of a diff
+func (c *ClientWrapper) summarizeChunk(ctx context.Context, client claudecode.Client, chunk *diff.Diff) ([]FileSummary, error) {
+	prompt := summaryPrompt(c.redact(chunk).String())
+
+	var response string
+	err := executeWithRetry(ctx, c.retry, c.rateLimits, func() error {
+		var callErr error
+		response, callErr = c.callAPIWithStreaming(ctx, client, prompt, "")
+		return callErr
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize diff: %w", err)
+	}
+
+	var parsed struct {
+		Files []FileSummary `json:"files"`
+	}
+	if err := decodeResponse(response, fileSummariesSchema, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse diff summary: %w (response: %s)", err, response)
+	}
+	return parsed.Files, nil
+}
+
+// summaryPrompt builds the prompt asking for a summary of each file in chunk
+func summaryPrompt(chunk string) string {
+	return fmt.Sprintf(`Summarize the changes made to each file in the following git diff.
+
+Respond with ONLY valid JSON in this exact format:
+{
+  "files": [
+    {"path": "path/to/file.go", "summary": "one line describing what changed and why, if apparent"}
+  ]
+}
+
+Include every file in the diff, in the order they appear.
+
+Git diff:
+%s`, chunk)
+}
+
+// formatSummaries renders file summaries as a list for the commit message prompt
+func formatSummaries(summaries []FileSummary) string {
+	var b strings.Builder
+	for _, s := range summaries {
+		fmt.Fprintf(&b, "- %s: %s\n", s.Path, s.Summary)
+	}
+	return b.String()
+}
+
+// summaryModelName returns the model used for file summaries, defaulting to
+// the main model
+func (c *ClientWrapper) summaryModelName() string {
+	if c.summaryModel == "" {
+		return c.model
+	}
+	return c.summaryModel
+}
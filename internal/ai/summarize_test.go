@@ -0,0 +1,141 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+const summaryCommitResponse = `{"type": "feat", "subject": "add generated fixtures"}`
+
+// largeCommitDiff returns a diff of three files that needs three chunks
+func largeCommitDiff() string {
+	lines := MaxDiffSize / 40
+	return fileDiff("a.go", lines) + fileDiff("b.go", lines) + fileDiff("c.go", lines)
+}
+
+// lastPrompt returns the last message sent through transport as text
+func lastPrompt(transport *mockTransport) string {
+	if len(transport.messagesReceived) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v", transport.messagesReceived[len(transport.messagesReceived)-1].Message)
+}
+
+func TestGenerateCommitMessage_SummarizesLargeDiff(t *testing.T) {
+	ctx := context.Background()
+	summary := `{"files": [{"path": "a.go", "summary": "adds fixture lines"}]}`
+
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetSummaryModel("claude-haiku-4-5-20251001")
+	var connections atomic.Int32
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		connections.Add(1)
+		return claudecode.WithClientTransport(ctx, newChunkTransport(summary, 3), fn)
+	}
+
+	transport := newChunkTransport(summaryCommitResponse, 1)
+	var msg *CommitMessage
+	var genErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		msg, genErr = wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff(largeCommitDiff()), "")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithClientTransport() error = %v", err)
+	}
+	if genErr != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", genErr)
+	}
+	if msg.Subject != "add generated fixtures" {
+		t.Errorf("GenerateCommitMessage() subject = %q", msg.Subject)
+	}
+
+	// The summary model needs its own connections; the caller's only writes the message
+	if connections.Load() == 0 {
+		t.Error("expected summaries to use connections for the summary model")
+	}
+	if len(transport.messagesReceived) != 1 {
+		t.Fatalf("caller's connection received %d queries, want 1", len(transport.messagesReceived))
+	}
+	prompt := lastPrompt(transport)
+	if !strings.Contains(prompt, "- a.go: adds fixture lines; adds fixture lines; adds fixture lines") {
+		t.Errorf("expected merged file summaries in the prompt, got:\n%.500s", prompt)
+	}
+	if strings.Contains(prompt, "Git diff:") {
+		t.Error("expected the diff to be replaced by summaries")
+	}
+}
+
+func TestGenerateCommitMessage_FallsBackToTruncation(t *testing.T) {
+	ctx := context.Background()
+
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetSummaryModel("claude-haiku-4-5-20251001")
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		return errors.New("connection refused")
+	}
+
+	transport := newChunkTransport(summaryCommitResponse, 1)
+	var genErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		_, genErr = wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff(largeCommitDiff()), "")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithClientTransport() error = %v", err)
+	}
+	if genErr != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", genErr)
+	}
+	if prompt := lastPrompt(transport); !strings.Contains(prompt, "content omitted from review: over the diff size limit") {
+		t.Errorf("expected the shortened diff in the prompt, got:\n%.500s", prompt)
+	}
+}
+
+func TestGenerateCommitMessage_SkipSummariesTruncates(t *testing.T) {
+	ctx := context.Background()
+
+	wrapper := NewClientWrapper("claude-opus-4-5-20251101")
+	wrapper.SetSummaryModel("claude-haiku-4-5-20251001")
+	wrapper.SetSkipSummaries(true)
+	summaryCalls := 0
+	wrapper.connect = func(ctx context.Context, fn func(client claudecode.Client) error) error {
+		summaryCalls++
+		return errors.New("summaries should be skipped")
+	}
+
+	transport := newChunkTransport(summaryCommitResponse, 1)
+	var genErr error
+	err := claudecode.WithClientTransport(ctx, transport, func(client claudecode.Client) error {
+		_, genErr = wrapper.GenerateCommitMessage(ctx, client, diff.NewDiff(largeCommitDiff()), "")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithClientTransport() error = %v", err)
+	}
+	if genErr != nil {
+		t.Fatalf("GenerateCommitMessage() error = %v", genErr)
+	}
+	if summaryCalls != 0 {
+		t.Errorf("expected no summary requests, got %d", summaryCalls)
+	}
+	if prompt := lastPrompt(transport); !strings.Contains(prompt, "content omitted from review: over the diff size limit") {
+		t.Errorf("expected the shortened diff in the prompt, got:\n%.500s", prompt)
+	}
+}
+
+func TestFormatSummaries(t *testing.T) {
+	got := formatSummaries([]FileSummary{{Path: "a.go", Summary: "adds x"}, {Path: "b.go", Summary: "removes y"}})
+	want := "- a.go: adds x\n- b.go: removes y\n"
+	if got != want {
+		t.Errorf("formatSummaries() = %q, want %q", got, want)
+	}
+}
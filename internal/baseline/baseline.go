@@ -0,0 +1,213 @@
+// Package baseline records the issues a repository already had when it
+// started using revi. Issues found again later are still reported, but
+// high-severity ones among them do not block commits, so revi can be adopted
+// on an existing codebase without first fixing everything it finds.
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// FileName is the name of the baseline file read from the repository root.
+const FileName = ".revi-baseline.json"
+
+// Entry is an issue recorded in the baseline. Only the fingerprint is used
+// for matching; the other fields tell readers of the file what it stands for.
+type Entry struct {
+	Fingerprint string      `json:"fingerprint"`
+	Mode        review.Mode `json:"mode"`
+	Severity    string      `json:"severity"`
+	File        string      `json:"file,omitempty"`
+	Description string      `json:"description"`
+}
+
+// Baseline is the set of issues recorded for a repository.
+type Baseline struct {
+	Issues []Entry `json:"issues"`
+
+	known map[string]bool
+}
+
+// Fingerprint identifies an issue found by mode across reviews. It covers the
+// mode, the file and the description with numbers and case left out, so the
+// issue keeps its fingerprint when the code around it moves.
+func Fingerprint(mode review.Mode, issue review.Issue) string {
+	file, _ := issue.FileLine()
+	sum := sha256.Sum256([]byte(string(mode) + "\x00" + strings.TrimPrefix(file, "./") + "\x00" + normalize(issue.Description)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalize lowercases description, drops its digits and collapses its
+// whitespace
+func normalize(description string) string {
+	description = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, description)
+	return strings.Join(strings.Fields(description), " ")
+}
+
+// New returns a baseline holding every issue in results. An issue several
+// modes reported is recorded for each of them, so it is recognized whichever
+// reports it next.
+func New(results []*review.Result) *Baseline {
+	b := &Baseline{}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			for _, mode := range append([]review.Mode{r.Mode}, issue.AlsoReportedBy...) {
+				fp := Fingerprint(mode, issue)
+				if seen[fp] {
+					continue
+				}
+				seen[fp] = true
+				file, _ := issue.FileLine()
+				b.Issues = append(b.Issues, Entry{
+					Fingerprint: fp,
+					Mode:        mode,
+					Severity:    issue.Severity,
+					File:        file,
+					Description: issue.Description,
+				})
+			}
+		}
+	}
+	// A stable order keeps changes to the file readable in review
+	sort.Slice(b.Issues, func(i, j int) bool {
+		a, c := b.Issues[i], b.Issues[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		if a.Mode != c.Mode {
+			return a.Mode < c.Mode
+		}
+		return a.Fingerprint < c.Fingerprint
+	})
+	b.index()
+	return b
+}
+
+// Load reads the baseline file at path. A missing file yields nil.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	b.index()
+	return &b, nil
+}
+
+// Read reads the issues of an earlier run from the file at path: a baseline
+// file, or the output of "revi review --output json". Unlike Load, a missing
+// file is an error.
+func Read(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var file struct {
+		Issues  []Entry          `json:"issues"`
+		Results []*review.Result `json:"results"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if file.Issues == nil && file.Results == nil {
+		return nil, fmt.Errorf("%s holds neither a baseline nor review results", path)
+	}
+	if file.Results != nil {
+		return New(file.Results), nil
+	}
+	b := &Baseline{Issues: file.Issues}
+	b.index()
+	return b, nil
+}
+
+// index builds the set of known fingerprints. It is built up front because
+// reviews of several modes look issues up concurrently.
+func (b *Baseline) index() {
+	b.known = make(map[string]bool, len(b.Issues))
+	for _, e := range b.Issues {
+		b.known[e.Fingerprint] = true
+	}
+}
+
+// Write saves the baseline to the file at path.
+func (b *Baseline) Write(path string) error {
+	if b.Issues == nil {
+		b.Issues = []Entry{}
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// Contains reports whether issue, found by mode, is recorded in the baseline.
+func (b *Baseline) Contains(mode review.Mode, issue review.Issue) bool {
+	if b == nil {
+		return false
+	}
+	return b.known[Fingerprint(mode, issue)]
+}
+
+// Drop returns result without the issues recorded in the baseline, counting
+// them in Known. It returns a copy so that result stays as it was for those
+// keeping it, such as the review history. A result left without issues is
+// marked as having none.
+func (b *Baseline) Drop(result *review.Result) *review.Result {
+	if b == nil || result == nil || len(result.Issues) == 0 {
+		return result
+	}
+	dropped := *result
+	dropped.Issues = nil
+	for _, issue := range result.Issues {
+		if b.Contains(result.Mode, issue) {
+			dropped.Known++
+			continue
+		}
+		dropped.Issues = append(dropped.Issues, issue)
+	}
+	if len(dropped.Issues) == 0 && dropped.Status == review.StatusIssues {
+		dropped.Status = review.StatusNoIssues
+	}
+	return &dropped
+}
+
+// Mark flags the issues of result that are recorded in the baseline.
+func (b *Baseline) Mark(result *review.Result) {
+	if b == nil || result == nil {
+		return
+	}
+	for i := range result.Issues {
+		if b.Contains(result.Mode, result.Issues[i]) {
+			result.Issues[i].Baseline = true
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestFingerprint(t *testing.T) {
+	issue := review.Issue{Severity: "high", Description: "SQL injection in query on line 12", Location: "db/query.go:12"}
+	fp := Fingerprint(review.ModeSecurity, issue)
+
+	moved := issue
+	moved.Location = "./db/query.go:30"
+	moved.Description = "SQL  injection in query on line 30"
+	if got := Fingerprint(review.ModeSecurity, moved); got != fp {
+		t.Errorf("moving the issue changed its fingerprint: %s != %s", got, fp)
+	}
+
+	for name, other := range map[string]review.Issue{
+		"file":        {Description: issue.Description, Location: "db/other.go:12"},
+		"description": {Description: "Missing bounds check", Location: issue.Location},
+	} {
+		if Fingerprint(review.ModeSecurity, other) == fp {
+			t.Errorf("a different %s should change the fingerprint", name)
+		}
+	}
+	if Fingerprint(review.ModeErrors, issue) == fp {
+		t.Error("a different mode should change the fingerprint")
+	}
+}
+
+func TestNew_WriteLoad(t *testing.T) {
+	results := []*review.Result{
+		nil,
+		{Mode: review.ModeSecurity, Issues: []review.Issue{
+			{Severity: "high", Description: "Hardcoded key", Location: "b.go:1"},
+			{Severity: "high", Description: "Hardcoded key", Location: "b.go:9"},
+		}},
+		{Mode: review.ModeStyle, Issues: []review.Issue{{Severity: "low", Description: "Long line", Location: "a.go:3"}}},
+	}
+	b := New(results)
+	if len(b.Issues) != 2 {
+		t.Fatalf("New() recorded %d issues, want 2 (duplicates merged)", len(b.Issues))
+	}
+	if b.Issues[0].File != "a.go" || b.Issues[1].File != "b.go" {
+		t.Errorf("issues not sorted by file: %+v", b.Issues)
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := b.Write(path); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !loaded.Contains(review.ModeSecurity, review.Issue{Description: "Hardcoded key", Location: "b.go:20"}) {
+		t.Error("loaded baseline should contain the recorded issue")
+	}
+	if loaded.Contains(review.ModeSecurity, review.Issue{Description: "Weak hash", Location: "b.go:20"}) {
+		t.Error("loaded baseline should not contain an unrecorded issue")
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	b, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil || b != nil {
+		t.Fatalf("Load() = %v, %v; want nil, nil", b, err)
+	}
+	if b.Contains(review.ModeSecurity, review.Issue{Description: "x"}) {
+		t.Error("a nil baseline should contain nothing")
+	}
+	b.Mark(&review.Result{Issues: []review.Issue{{Description: "x"}}})
+}
+
+func TestNew_RecordsEveryModeOfMergedIssues(t *testing.T) {
+	b := New([]*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{
+		{Severity: "medium", Description: "Missing input validation", Location: "api.go:4", AlsoReportedBy: []review.Mode{review.ModeErrors}},
+	}}})
+	issue := review.Issue{Description: "Missing input validation", Location: "api.go:9"}
+	if !b.Contains(review.ModeSecurity, issue) || !b.Contains(review.ModeErrors, issue) {
+		t.Errorf("baseline %+v should contain the issue for both modes", b.Issues)
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "review.json")
+	content := `{"results": [{"mode": "security", "status": "issues", "issues": [{"severity": "high", "description": "Hardcoded key", "location": "b.go:1"}]}], "summary": {}}`
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := Read(output)
+	if err != nil {
+		t.Fatalf("Read(JSON output) failed: %v", err)
+	}
+	if !b.Contains(review.ModeSecurity, review.Issue{Description: "Hardcoded key", Location: "b.go:7"}) {
+		t.Errorf("baseline %+v should contain the issue of the JSON output", b.Issues)
+	}
+
+	file := filepath.Join(dir, FileName)
+	if err := New([]*review.Result{{Mode: review.ModeStyle, Issues: []review.Issue{{Description: "Long line", Location: "a.go:3"}}}}).Write(file); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := Read(file); err != nil || !b.Contains(review.ModeStyle, review.Issue{Description: "Long line", Location: "a.go:3"}) {
+		t.Errorf("Read(baseline file) = %+v, %v; want the recorded issue", b, err)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(other, []byte(`{"name": "x"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(other); err == nil {
+		t.Error("Read() of unrelated JSON should fail")
+	}
+	if _, err := Read(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Read() of a missing file should fail")
+	}
+}
+
+func TestBaseline_Drop(t *testing.T) {
+	b := New([]*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{{Description: "Hardcoded key", Location: "b.go:1"}}}})
+	result := &review.Result{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{
+		{Description: "Hardcoded key", Location: "b.go:5"},
+		{Description: "Weak hash", Location: "b.go:9"},
+	}}
+
+	dropped := b.Drop(result)
+	if len(dropped.Issues) != 1 || dropped.Issues[0].Description != "Weak hash" || dropped.Known != 1 {
+		t.Errorf("Drop() = %+v, want only the new issue", dropped)
+	}
+	if len(result.Issues) != 2 || result.Known != 0 {
+		t.Errorf("Drop() changed its argument: %+v", result)
+	}
+
+	only := b.Drop(&review.Result{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: result.Issues[:1]})
+	if only.Status != review.StatusNoIssues {
+		t.Errorf("status = %s, want %s once every issue is known", only.Status, review.StatusNoIssues)
+	}
+	var none *Baseline
+	if got := none.Drop(result); got != result {
+		t.Error("a nil baseline should return the result as it is")
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline [review-id]",
+	Short: "Record the issues of a past review as the repository's baseline",
+	Long: `Record the issues found by a past review in .revi-baseline.json in the
+repository root, replacing its previous content. Without an ID, the most
+recent review is used.
+
+Issues recorded in the baseline are still reported when found again, but
+high-severity ones no longer block commits: only newly introduced ones do.
+This lets a codebase with known problems adopt revi without a wall of blocks.
+Review the existing code once, run "revi baseline", and commit the file so
+everyone shares it. --no-ignore disregards the baseline.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := git.OpenCurrent()
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		store, err := historyStore(repo)
+		if err != nil {
+			return err
+		}
+		entry, err := baselineEntry(store, args)
+		if err != nil {
+			return err
+		}
+		root, err := repo.Root()
+		if err != nil {
+			return err
+		}
+
+		b := baseline.New(entry.Results)
+		if err := b.Write(filepath.Join(root, baseline.FileName)); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded %d issue(s) from review %s in %s.\n", len(b.Issues), entry.ID, baseline.FileName)
+		return nil
+	},
+}
+
+// baselineEntry returns the review named by args, or the most recent one
+func baselineEntry(store *history.Store, args []string) (history.Entry, error) {
+	if len(args) == 1 {
+		entry, err := store.Get(args[0])
+		if err != nil {
+			return history.Entry{}, withCode(CodeInvalidInput, err)
+		}
+		return entry, nil
+	}
+	entries, err := store.List()
+	if err != nil {
+		return history.Entry{}, err
+	}
+	if len(entries) == 0 {
+		return history.Entry{}, withCode(CodeInvalidInput, errors.New(`no reviews recorded yet; run "revi review" first`))
+	}
+	return entries[len(entries)-1], nil
+}
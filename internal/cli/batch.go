@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/report"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// batchReviewFlags are the review flags passed on to the review of each
+// repository of a batch
+var batchReviewFlags = append([]string{"range"}, mrReviewFlags...)
+
+// batchGlobalFlags are the global flags passed on to the review of each
+// repository of a batch
+var batchGlobalFlags = []string{"model", "config", "timeout", "force-unlock", "debug", "rate-limit-retries", "retry-backoff", "retry-deadline"}
+
+func init() {
+	batchCmd.Flags().IntP("jobs", "j", 1, "Repositories reviewed at once")
+	batchCmd.Flags().String("repos-from", "", "Read repository paths from a file, one per line (- for standard input)")
+	batchCmd.Flags().String("report", "", "Write the consolidated results to a Markdown file")
+}
+
+// addBatchReviewFlags shares batchReviewFlags with the review command. It is
+// called once the review command's flags are defined.
+func addBatchReviewFlags() {
+	for _, name := range batchReviewFlags {
+		batchCmd.Flags().AddFlag(reviewCmd.Flags().Lookup(name))
+	}
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [repository|glob]...",
+	Short: "Review several repositories and report on all of them",
+	Long: `Review the staged changes, or with --range a revision range, of each
+repository given and print a consolidated report, for sweeping one change
+across many repositories.
+
+Repositories are given as paths or globs such as "services/*"; directories a
+glob matches that are not git repositories are skipped. Each one is reviewed
+by "revi review" run in that repository, so its own .revi.yaml and prompt
+guidance apply. Repositories are reviewed one at a time unless --jobs is set.
+
+  revi batch services/* --range main..HEAD --jobs 4 --report sweep.md`,
+	RunE: runBatch,
+}
+
+// repoReviewer reviews the repository at dir, passing args on to revi review
+type repoReviewer func(ctx context.Context, dir string, args []string) batchRepo
+
+// batchRepo is the outcome of reviewing one repository of a batch
+type batchRepo struct {
+	Repo    string           `json:"repo"`
+	Results []*review.Result `json:"results"`
+	Summary review.Summary   `json:"summary"`
+	Blocked bool             `json:"blocked"`
+	Partial bool             `json:"partial,omitempty"`
+	Error   *batchError      `json:"error,omitempty"` // Why the repository could not be reviewed
+}
+
+// batchError is why a repository of a batch could not be reviewed
+type batchError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// failed returns true if the repository could not be reviewed for a reason
+// other than having nothing to review
+func (r batchRepo) failed() bool {
+	return r.Error != nil && r.Error.Code != CodeNoChanges
+}
+
+// note describes why the repository has no results, or returns ""
+func (r batchRepo) note() string {
+	switch {
+	case r.Error == nil:
+		return ""
+	case r.Error.Code == CodeNoChanges:
+		return "No changes"
+	default:
+		return "Failed: " + r.Error.Message
+	}
+}
+
+// batchReport is the JSON output of revi batch
+type batchReport struct {
+	Repositories []batchRepo    `json:"repositories"`
+	Summary      review.Summary `json:"summary"`
+	Blocked      bool           `json:"blocked"`
+	Failed       int            `json:"failed"` // Repositories that could not be reviewed
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	repos, err := batchRepos(cmd, args)
+	if err != nil {
+		return err
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	if jobs < 1 {
+		return withCode(CodeInvalidInput, fmt.Errorf("--jobs must be at least 1, got %d", jobs))
+	}
+	reviewArgs, err := batchReviewArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	outcomes := runBatchReviews(ctx, repos, reviewArgs, jobs, execReview, os.Stderr)
+
+	var all []*review.Result
+	var results []report.RepoResults
+	blocked, failed := 0, 0
+	for _, o := range outcomes {
+		all = append(all, o.Results...)
+		results = append(results, report.RepoResults{Repo: o.Repo, Results: o.Results, Note: o.note()})
+		if o.Blocked {
+			blocked++
+		}
+		if o.failed() {
+			failed++
+		}
+	}
+
+	if path, _ := cmd.Flags().GetString("report"); path != "" {
+		labels, _ := severityLabels(config.Get())
+		if err := writePatchFile(path, func(f *os.File) error {
+			return report.WriteBatchMarkdown(f, results, labels)
+		}); err != nil {
+			return fmt.Errorf("failed to write review report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote review report to %s\n", path)
+	}
+
+	if isJSONOutput(cmd) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(batchReport{
+			Repositories: outcomes,
+			Summary:      review.Summarize(all),
+			Blocked:      blocked > 0,
+			Failed:       failed,
+		}); err != nil {
+			return fmt.Errorf("failed to write JSON output: %w", err)
+		}
+	} else {
+		printBatchResults(outcomes, review.Summarize(all))
+	}
+
+	switch {
+	case failed > 0:
+		return fmt.Errorf("%d of %d repositories could not be reviewed", failed, len(outcomes))
+	case blocked > 0:
+		return withCode(CodeBlocked, fmt.Errorf("high-severity issues found in %d of %d repositories", blocked, len(outcomes)))
+	}
+	return nil
+}
+
+// batchRepos returns the repositories named by args and --repos-from, with
+// globs expanded to the git repositories they match
+func batchRepos(cmd *cobra.Command, args []string) ([]string, error) {
+	patterns := append([]string(nil), args...)
+	if from, _ := cmd.Flags().GetString("repos-from"); from != "" {
+		listed, err := readRepoList(from)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, listed...)
+	}
+
+	var repos []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, withCode(CodeInvalidInput, fmt.Errorf("invalid repository glob %q: %w", pattern, err))
+			}
+			matches = gitRepos(matches)
+		}
+		for _, repo := range matches {
+			if !seen[repo] {
+				seen[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	if len(repos) == 0 {
+		return nil, withCode(CodeInvalidInput, errors.New("no repositories to review: pass paths or globs, or --repos-from"))
+	}
+	return repos, nil
+}
+
+// readRepoList reads repository paths, one per line, from the file at path or
+// from standard input if path is "-". Blank lines and lines starting with #
+// are skipped.
+func readRepoList(path string) ([]string, error) {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, fmt.Errorf("failed to read repository list: %w", err))
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var repos []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			repos = append(repos, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read repository list: %w", err)
+	}
+	return repos, nil
+}
+
+// gitRepos returns the paths that are the root of a git repository
+func gitRepos(paths []string) []string {
+	var repos []string
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+			repos = append(repos, p)
+		}
+	}
+	return repos
+}
+
+// batchReviewArgs returns the flags passed on to the review of each
+// repository: the review and global flags set on the command line. The
+// config file is made absolute, since each review runs in its repository.
+func batchReviewArgs(cmd *cobra.Command) ([]string, error) {
+	return forwardFlags(cmd, append(batchReviewFlags, batchGlobalFlags...))
+}
+
+// forwardFlags returns the flags of names set on cmd as arguments to pass on
+// to a revi subprocess, with the config file's path made absolute
+func forwardFlags(cmd *cobra.Command, names []string) ([]string, error) {
+	forward := make(map[string]bool)
+	for _, name := range names {
+		forward[name] = true
+	}
+
+	var args []string
+	var err error
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if !forward[flag.Name] {
+			return
+		}
+		// Repeatable flags are passed once per value
+		if values, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range values.GetSlice() {
+				args = append(args, "--"+flag.Name+"="+value)
+			}
+			return
+		}
+		value := flag.Value.String()
+		if flag.Name == "config" {
+			if value, err = filepath.Abs(value); err != nil {
+				return
+			}
+		}
+		args = append(args, "--"+flag.Name+"="+value)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file: %w", err)
+	}
+	return args, nil
+}
+
+// runBatchReviews reviews repos with review, at most jobs at once, and
+// returns the outcomes in the order of repos. A line is written to progress
+// as each repository finishes.
+func runBatchReviews(ctx context.Context, repos []string, args []string, jobs int, reviewRepo repoReviewer, progress io.Writer) []batchRepo {
+	outcomes := make([]batchRepo, len(repos))
+	slots := make(chan struct{}, jobs)
+
+	var mu sync.Mutex
+	done := 0
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			outcome := reviewRepo(ctx, repo, args)
+			outcome.Repo = repo
+			outcomes[i] = outcome
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			status := "done"
+			if note := outcome.note(); note != "" {
+				status = strings.ToLower(note[:1]) + note[1:]
+			}
+			fmt.Fprintf(progress, "[%d/%d] %s: %s\n", done, len(repos), repo, status)
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// execReview reviews the repository at dir by running revi review in it with
+// JSON output
+func execReview(ctx context.Context, dir string, args []string) batchRepo {
+	exe, err := os.Executable()
+	if err != nil {
+		return batchRepo{Error: &batchError{Code: CodeInternal, Message: err.Error()}}
+	}
+	cmd := exec.CommandContext(ctx, exe, append([]string{"review", "--output", outputJSON}, args...)...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	return parseReviewOutput(stdout.Bytes(), stderr.Bytes(), runErr)
+}
+
+// parseReviewOutput returns the outcome of a revi review run from its JSON
+// output and exit error. Runs blocked by high-severity issues exit with an
+// error but still report their results.
+func parseReviewOutput(stdout, stderr []byte, runErr error) batchRepo {
+	var rep jsonReport
+	if len(bytes.TrimSpace(stdout)) > 0 && json.Unmarshal(stdout, &rep) == nil {
+		return batchRepo{Results: rep.Results, Summary: rep.Summary, Blocked: rep.Blocked, Partial: rep.Partial}
+	}
+
+	// Errors are written to stderr as the last line, after any warnings
+	lines := strings.Split(strings.TrimSpace(string(stderr)), "\n")
+	var jerr jsonError
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &jerr); err == nil && jerr.Error.Code != "" {
+		return batchRepo{Error: &batchError{Code: jerr.Error.Code, Message: jerr.Error.Message}}
+	}
+	message := strings.TrimSpace(string(stderr))
+	if message == "" && runErr != nil {
+		message = runErr.Error()
+	}
+	if message == "" {
+		message = "revi review produced no output"
+	}
+	return batchRepo{Error: &batchError{Code: CodeInternal, Message: message}}
+}
+
+// printBatchResults prints a line with the issues found in each repository,
+// followed by the totals
+func printBatchResults(repos []batchRepo, total review.Summary) {
+	fmt.Println("revi - Batch Code Review")
+	printRule("-")
+	for _, repo := range repos {
+		if note := repo.note(); note != "" {
+			fmt.Printf("%s: %s\n", repo.Repo, note)
+			continue
+		}
+		fmt.Printf("%s: %d high, %d medium, %d low\n", repo.Repo,
+			repo.Summary.HighSeverity, repo.Summary.MediumSeverity, repo.Summary.LowSeverity)
+	}
+	fmt.Printf("\nTotal: %d issue(s) in %d repositories (%d high, %d medium, %d low)\n",
+		total.IssuesFound, len(repos), total.HighSeverity, total.MediumSeverity, total.LowSeverity)
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"sync"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/review"
+)
+
+// blameContext is how many lines above and below an issue's line are blamed
+// to find its last author
+const blameContext = 3
+
+// issueBlamer finds who last changed the lines around issues, blaming each file
+// at most once
+type issueBlamer struct {
+	blame func(path string) ([]git.LineAuthor, error)
+	mu    sync.Mutex
+	files map[string][]git.LineAuthor
+}
+
+// newIssueBlamer returns a blamer for repo, or nil unless report.blame is set
+func newIssueBlamer(cfg *config.Config, repo *git.Repository) *issueBlamer {
+	if !cfg.Report.Blame {
+		return nil
+	}
+	return &issueBlamer{blame: repo.Blame, files: make(map[string][]git.LineAuthor)}
+}
+
+// author returns the last author of the lines around issue's location at HEAD,
+// or "" if the location has no line or the file is new
+func (b *issueBlamer) author(issue review.Issue) string {
+	path, line := issue.FileLine()
+	if path == "" || line <= 0 {
+		return ""
+	}
+
+	b.mu.Lock()
+	lines, ok := b.files[path]
+	if !ok {
+		var err error
+		if lines, err = b.blame(path); err != nil {
+			debugLog("No author for %s: %v", path, err)
+		}
+		b.files[path] = lines
+	}
+	b.mu.Unlock()
+
+	// Lines changed by the diff may have moved, so the surrounding lines at
+	// HEAD approximate who owns the code
+	author, found := git.LastAuthor(lines, line-blameContext, line+blameContext)
+	if !found {
+		return ""
+	}
+	return author.String()
+}
+
+// withBlame wraps run so that the issues it returns name the last author of
+// their location. run is returned unchanged if blamer is nil.
+func withBlame(blamer *issueBlamer, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if blamer == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		if result != nil {
+			for i := range result.Issues {
+				result.Issues[i].Author = blamer.author(result.Issues[i])
+			}
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// ciVars are environment variables set by common CI providers. CI itself is
+// checked separately since some systems set it to "false".
+var ciVars = []string{
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"BUILDKITE",
+	"CIRCLECI",
+	"JENKINS_URL",
+	"TF_BUILD",
+	"TEAMCITY_VERSION",
+	"BITBUCKET_BUILD_NUMBER",
+	"TRAVIS",
+	"DRONE",
+}
+
+// detectCI reports whether the environment looks like a CI system
+func detectCI() bool {
+	if v := strings.ToLower(os.Getenv("CI")); v != "" && v != "false" && v != "0" {
+		return true
+	}
+	for _, name := range ciVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isCI reports whether revi runs in CI, where nothing may wait for input on
+// stdin. --ci forces the answer either way; otherwise it is detected from the
+// environment.
+func isCI(cmd *cobra.Command) bool {
+	if flag := cmd.Flags().Lookup("ci"); flag != nil && flag.Changed {
+		ci, _ := cmd.Flags().GetBool("ci")
+		return ci
+	}
+	return detectCI()
+}
+
+// ciRequested reports whether --ci was given explicitly. Besides never
+// prompting, revi review then prints a compact report and exits with the
+// status configured for the most severe issue found.
+func ciRequested(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("ci")
+	if flag == nil || !flag.Changed {
+		return false
+	}
+	ci, _ := cmd.Flags().GetBool("ci")
+	return ci
+}
+
+// validateCIExitCodes rejects exit statuses a shell cannot report as such
+func validateCIExitCodes(codes config.CIExitCodes) error {
+	for _, c := range []struct {
+		severity string
+		status   int
+	}{
+		{review.SeverityHigh, codes.High},
+		{review.SeverityMedium, codes.Medium},
+		{review.SeverityLow, codes.Low},
+	} {
+		if c.status < 0 || c.status > 125 {
+			return withCode(CodeInvalidInput, fmt.Errorf("invalid ci.exit_codes.%s %d, expected 0 to 125", c.severity, c.status))
+		}
+	}
+	return nil
+}
+
+// ciExitError returns an error carrying the exit status for the most severe
+// issues in summary, or nil if that status is 0
+func ciExitError(codes config.CIExitCodes, summary review.Summary) error {
+	status, severity := 0, ""
+	for _, c := range []struct {
+		severity string
+		count    int
+		status   int
+	}{
+		{review.SeverityLow, summary.LowSeverity, codes.Low},
+		{review.SeverityMedium, summary.MediumSeverity, codes.Medium},
+		// High-severity issues recorded in the baseline do not block
+		{review.SeverityHigh, summary.HighSeverity - summary.BaselineHigh, codes.High},
+	} {
+		if c.count > 0 && c.status >= status && c.status > 0 {
+			status, severity = c.status, c.severity
+		}
+	}
+	if status == 0 {
+		return nil
+	}
+	return withExitStatus(status, withCode(CodeBlocked, fmt.Errorf("%s-severity issues found", severity)))
+}
@@ -1,9 +1,40 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/fix"
+	"github.com/buker/revi/internal/forge"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/history"
+	"github.com/buker/revi/internal/permalink"
+	"github.com/buker/revi/internal/porcelain"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/source"
+	"github.com/buker/revi/internal/suppress"
+	"github.com/buker/revi/internal/transcript"
+	"github.com/buker/revi/internal/update"
+	gogit "github.com/go-git/go-git/v5"
 	"github.com/spf13/cobra"
 )
 
@@ -56,6 +87,7 @@ func newReviewCmdForTest() *cobra.Command {
 	cmd.Flags().Bool("errors", false, "")
 	cmd.Flags().Bool("testing", false, "")
 	cmd.Flags().Bool("docs", false, "")
+	cmd.Flags().Bool("secrets", false, "")
 	// Disabled flags
 	cmd.Flags().Bool("no-security", false, "")
 	cmd.Flags().Bool("no-performance", false, "")
@@ -63,6 +95,7 @@ func newReviewCmdForTest() *cobra.Command {
 	cmd.Flags().Bool("no-errors", false, "")
 	cmd.Flags().Bool("no-testing", false, "")
 	cmd.Flags().Bool("no-docs", false, "")
+	cmd.Flags().Bool("no-secrets", false, "")
 	return cmd
 }
 
@@ -119,11 +152,12 @@ func TestFilterModesByFlags_DisableMultipleModes(t *testing.T) {
 	_ = cmd.Flags().Set("no-security", "true")
 	_ = cmd.Flags().Set("no-performance", "true")
 	_ = cmd.Flags().Set("no-docs", "true")
+	_ = cmd.Flags().Set("no-secrets", "true")
 
 	detected := review.AllModes()
 	result := filterModesByFlags(cmd, detected)
 
-	// Should have 3 modes (6 total - 3 disabled)
+	// Should have 3 modes (7 total - 4 disabled)
 	if len(result) != 3 {
 		t.Errorf("expected 3 modes after filtering, got %d: %v", len(result), result)
 	}
@@ -133,6 +167,7 @@ func TestFilterModesByFlags_DisableMultipleModes(t *testing.T) {
 		review.ModeSecurity:    true,
 		review.ModePerformance: true,
 		review.ModeDocs:        true,
+		review.ModeSecrets:     true,
 	}
 	for _, mode := range result {
 		if disabledModes[mode] {
@@ -234,6 +269,16 @@ func TestRootCmd_HasMessageFlag(t *testing.T) {
 	}
 }
 
+func TestRootCmd_HasYesFlag(t *testing.T) {
+	flag := rootCmd.Flags().Lookup("yes")
+	if flag == nil {
+		t.Fatal("expected --yes flag on root command")
+	}
+	if flag.Shorthand != "y" {
+		t.Errorf("expected shorthand 'y' for yes, got %q", flag.Shorthand)
+	}
+}
+
 func TestRootCmd_HasModelFlag(t *testing.T) {
 	flag := rootCmd.PersistentFlags().Lookup("model")
 	if flag == nil {
@@ -269,6 +314,63 @@ func TestVersionCmd_DoesNotPanic(t *testing.T) {
 	versionCmd.Run(versionCmd, []string{})
 }
 
+func TestBuildInfo_UsesLdflagsValues(t *testing.T) {
+	origVersion, origCommit, origDate := Version, Commit, Date
+	defer func() { Version, Commit, Date = origVersion, origCommit, origDate }()
+
+	Version, Commit, Date = "v1.2.3", "abc1234", "2025-01-02T03:04:05Z"
+
+	info := buildInfo()
+	if info.Version != "v1.2.3" || info.Commit != "abc1234" || info.Date != "2025-01-02T03:04:05Z" {
+		t.Errorf("buildInfo() = %+v, want ldflags values", info)
+	}
+	if info.GoVersion == "" || !strings.Contains(info.Platform, "/") {
+		t.Errorf("buildInfo() should include go version and platform, got %+v", info)
+	}
+}
+
+func TestWriteVersion_JSON(t *testing.T) {
+	info := versionInfo{
+		Version:   "v1.2.3",
+		GoVersion: "go1.25.0",
+		Platform:  "linux/amd64",
+		Update:    &update.Status{Latest: "v1.3.0", Available: true},
+	}
+
+	var buf bytes.Buffer
+	if err := writeVersion(&buf, info, true); err != nil {
+		t.Fatalf("writeVersion() failed: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	for _, key := range []string{"version", "go_version", "platform", "update"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("JSON output missing %q: %s", key, buf.String())
+		}
+	}
+	if _, ok := got["commit"]; ok {
+		t.Error("empty commit should be omitted")
+	}
+}
+
+func TestWriteVersion_TextReportsUpdate(t *testing.T) {
+	info := versionInfo{
+		Version: "v1.2.3",
+		Update:  &update.Status{Latest: "v1.3.0", URL: "https://example.com/v1.3.0", Available: true},
+	}
+
+	var buf bytes.Buffer
+	if err := writeVersion(&buf, info, false); err != nil {
+		t.Fatalf("writeVersion() failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "revi version v1.2.3") || !strings.Contains(buf.String(), "newer version is available: v1.3.0") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
 // =============================================================================
 // Tests for commit command structure
 // =============================================================================
@@ -284,6 +386,81 @@ func TestCommitCmd_HasSameFlags(t *testing.T) {
 	if message == nil {
 		t.Error("expected --message flag on commit command")
 	}
+
+	yes := commitCmd.Flags().Lookup("yes")
+	if yes == nil {
+		t.Error("expected --yes flag on commit command")
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	cfg := config.CommitConfig{
+		Trailers:     []string{"Reviewed-by: Jane Doe <jane@example.com>"},
+		IssueTrailer: "Refs",
+		IssuePattern: "[A-Z][A-Z0-9]+-[0-9]+",
+	}
+	tests := []struct {
+		name   string
+		cfg    config.CommitConfig
+		branch string
+		want   []string
+	}{
+		{"issue in branch", cfg, "feature/PROJ-123-login", []string{"Reviewed-by: Jane Doe <jane@example.com>", "Refs: PROJ-123"}},
+		{"no issue", cfg, "fix-login", []string{"Reviewed-by: Jane Doe <jane@example.com>"}},
+		{"detached HEAD", cfg, "", []string{"Reviewed-by: Jane Doe <jane@example.com>"}},
+		{"no issue trailer", config.CommitConfig{IssuePattern: cfg.IssuePattern}, "PROJ-1", nil},
+		{"invalid pattern", config.CommitConfig{IssueTrailer: "Refs", IssuePattern: "[A-Z"}, "PROJ-1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commitTrailers(tt.cfg, tt.branch, nil); !slices.Equal(got, tt.want) {
+				t.Errorf("commitTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitTrailers_Mailmap(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := gogit.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	mailmap := "Jane Doe <jane@example.com> <jane@laptop.local>\nJoe Smith <joe@example.com>\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mailmap"), []byte(mailmap), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.CommitConfig{Trailers: []string{
+		"Co-authored-by: jd <jane@laptop.local>",
+		"Reviewed-by: joe <joe@example.com>",
+		"Reviewed-by: Other Person <other@example.com>",
+		"Refs: PROJ-1",
+	}}
+	want := []string{
+		"Co-authored-by: Jane Doe <jane@example.com>",
+		"Reviewed-by: Joe Smith <joe@example.com>",
+		"Reviewed-by: Other Person <other@example.com>",
+		"Refs: PROJ-1",
+	}
+	if got := commitTrailers(cfg, "", repo.Mailmap()); !slices.Equal(got, want) {
+		t.Errorf("commitTrailers() = %q, want %q", got, want)
+	}
+}
+
+func TestCommitRegenerator_ReportsFailure(t *testing.T) {
+	aiClient := ai.NewClientWrapper("claude-sonnet-4-5")
+	aiClient.SetOffline(true)
+	staged := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n")
+
+	regenerate := commitRegenerator(context.Background(), aiClient, nil, staged, "")
+	message, err := regenerate("feat: add b", "mention the migration")
+	if !errors.Is(err, ai.ErrOffline) || message != "" {
+		t.Errorf("regenerate() = %q, %v; want ErrOffline rather than a message built without AI", message, err)
+	}
 }
 
 // =============================================================================
@@ -300,6 +477,153 @@ func TestReviewCmd_HasFixFlag(t *testing.T) {
 	}
 }
 
+func TestReviewCmd_HasFixAllFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("fix-all") == nil {
+		t.Fatal("expected --fix-all flag on review command")
+	}
+}
+
+func TestReviewCmd_HasFixVerifyFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("fix-verify") == nil {
+		t.Fatal("expected --fix-verify flag on review command")
+	}
+}
+
+func TestReviewCmd_HasScreenReaderFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("screen-reader") == nil {
+		t.Fatal("expected --screen-reader flag on review command")
+	}
+}
+
+func TestSeverityLabels_RejectsUnknownLevel(t *testing.T) {
+	cfg := &config.Config{UI: config.UIConfig{SeverityLabels: map[string]string{"critical": "CRIT"}}}
+	if _, err := severityLabels(cfg); err == nil {
+		t.Fatal("expected error for a label on an unknown severity")
+	}
+}
+
+func TestPromptGuidance(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(ai.GuidanceDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ai.GuidanceDir, "review.md"), []byte("from the file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Prompts: config.PromptsConfig{Review: "from config", Modes: map[string]string{"Security": "gateway auth"}}}
+	g, err := promptGuidance(cfg)
+	if err != nil {
+		t.Fatalf("promptGuidance() error = %v", err)
+	}
+	if g.Review != "from the file\n\nfrom config" || g.Modes[review.ModeSecurity] != "gateway auth" {
+		t.Errorf("promptGuidance() = %+v", g)
+	}
+
+	cfg.Prompts.Modes = map[string]string{"speed": "x"}
+	if _, err := promptGuidance(cfg); errorCode(err) != CodeInvalidInput {
+		t.Errorf("promptGuidance() error = %v, want an invalid input error for an unknown mode", err)
+	}
+}
+
+func TestReviewCmd_HasReportFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("report") == nil {
+		t.Error("expected review command to have --report flag")
+	}
+}
+
+func TestReviewCmd_HasPatchExportFlags(t *testing.T) {
+	for _, name := range []string{"patch-out", "unapplied-patch-out"} {
+		if reviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on review command", name)
+		}
+	}
+}
+
+func TestRootCmd_HasForceUnlockFlag(t *testing.T) {
+	if rootCmd.PersistentFlags().Lookup("force-unlock") == nil {
+		t.Error("expected persistent --force-unlock flag on root command")
+	}
+}
+
+func TestRootCmd_HasConfigFlag(t *testing.T) {
+	if rootCmd.PersistentFlags().Lookup("config") == nil {
+		t.Error("expected persistent --config flag on root command")
+	}
+}
+
+func TestReviewCmd_HasDiffSourceFlags(t *testing.T) {
+	for _, name := range []string{"staged", "working-tree", "unstaged", "snapshot", "range", "commit", "patch", "stdin", "pr"} {
+		if reviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on review command", name)
+		}
+	}
+}
+
+func TestCommitCmds_HaveDiffSourceFlags(t *testing.T) {
+	for _, cmd := range []*cobra.Command{rootCmd, commitCmd} {
+		for _, name := range []string{"working-tree", "range", "patch", "pr"} {
+			if cmd.Flags().Lookup(name) == nil {
+				t.Errorf("expected --%s flag on %s command", name, cmd.Name())
+			}
+		}
+	}
+}
+
+func TestSelectSource(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "staged"},
+		{[]string{"--unstaged"}, "working-tree"},
+		{[]string{"--range", "main..HEAD"}, "range"},
+		{[]string{"--patch", "change.patch"}, "patch"},
+	}
+
+	for _, tt := range tests {
+		cmd := &cobra.Command{Use: "test"}
+		addSourceFlags(cmd)
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("ParseFlags(%v) failed: %v", tt.args, err)
+		}
+		_, kind, err := selectSource(cmd, nil)
+		if err != nil {
+			t.Fatalf("selectSource(%v) failed: %v", tt.args, err)
+		}
+		if kind.Name != tt.want {
+			t.Errorf("selectSource(%v) = %s, want %s", tt.args, kind.Name, tt.want)
+		}
+	}
+}
+
+func TestSelectSource_EmptyValue(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	addSourceFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--range", ""}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if _, _, err := selectSource(cmd, nil); errorCode(err) != CodeInvalidInput {
+		t.Errorf("expected invalid_input error, got: %v", err)
+	}
+}
+
+func TestReviewCmd_HasPreviewContextFlag(t *testing.T) {
+	flag := reviewCmd.Flags().Lookup("preview-context")
+	if flag == nil {
+		t.Fatal("expected --preview-context flag on review command")
+	}
+	if flag.DefValue != "3" {
+		t.Errorf("--preview-context default = %s, want 3", flag.DefValue)
+	}
+}
+
+func TestReviewCmd_HasCrossCheckModelFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("cross-check-model") == nil {
+		t.Error("expected --cross-check-model flag on review command")
+	}
+}
+
 func TestReviewCmd_HasBlockFlags(t *testing.T) {
 	block := reviewCmd.Flags().Lookup("block")
 	if block == nil {
@@ -338,8 +662,11 @@ func TestReviewCmd_HasAllModeFlags(t *testing.T) {
 func TestConfigCmd_HasSubcommands(t *testing.T) {
 	subcommands := configCmd.Commands()
 	expected := map[string]bool{
-		"show": false,
-		"path": false,
+		"show":     false,
+		"path":     false,
+		"init":     false,
+		"set":      false,
+		"validate": false,
 	}
 
 	for _, cmd := range subcommands {
@@ -354,3 +681,1720 @@ func TestConfigCmd_HasSubcommands(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigSetCmd_RejectsUnknownKey(t *testing.T) {
+	t.Chdir(t.TempDir())
+	err := configSetCmd.RunE(configSetCmd, []string{"review.nope", "1"})
+	if errorCode(err) != CodeInvalidInput {
+		t.Errorf("RunE() error = %v, want an invalid input error", err)
+	}
+}
+
+func TestConfigValidateCmd_ReportsProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revi.yaml")
+	if err := os.WriteFile(path, []byte("review:\n  bloc: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := configValidateCmd.RunE(configValidateCmd, []string{path})
+	if errorCode(err) != CodeInvalidInput || !strings.Contains(err.Error(), "did you mean review.block?") {
+		t.Errorf("RunE() error = %v, want an invalid input error suggesting review.block", err)
+	}
+
+	if err := os.WriteFile(path, []byte("review:\n  block: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := configValidateCmd.RunE(configValidateCmd, []string{path}); err != nil {
+		t.Errorf("RunE() error = %v for a valid file", err)
+	}
+}
+
+func TestConfigInitCmd_RefusesToOverwrite(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := configInitCmd.RunE(configInitCmd, nil); err != nil {
+		t.Fatalf("first init error = %v", err)
+	}
+	err := configInitCmd.RunE(configInitCmd, nil)
+	if errorCode(err) != CodeInvalidInput || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("second init error = %v, want an invalid input error suggesting --force", err)
+	}
+}
+
+// =============================================================================
+// Tests for hook command
+// =============================================================================
+
+func TestHookCmd_HasSubcommands(t *testing.T) {
+	for _, name := range []string{"install", "uninstall", "run"} {
+		found := false
+		for _, sub := range hookCmd.Commands() {
+			if sub.Name() == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected hook %s subcommand", name)
+		}
+	}
+}
+
+func TestRunHook_UnknownHook(t *testing.T) {
+	err := runHook(hookRunCmd, []string{"post-merge"})
+	if errorCode(err) != CodeInvalidInput {
+		t.Errorf("expected invalid_input error, got: %v", err)
+	}
+}
+
+func TestRunHook_PrepareCommitMsgSkipsGivenMessage(t *testing.T) {
+	// A message from -m must be left alone, without touching git or the AI
+	if err := runHook(hookRunCmd, []string{"prepare-commit-msg", "/nonexistent/COMMIT_EDITMSG", "message"}); err != nil {
+		t.Errorf("runHook() = %v, want nil", err)
+	}
+}
+
+// =============================================================================
+// Tests for issue links
+// =============================================================================
+
+func TestWithIssueLinks(t *testing.T) {
+	linker := permalink.New(permalink.Templates[permalink.GitHub], "https://github.com/o/r", "abc123")
+	run := withIssueLinks(linker, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		return &review.Result{Mode: mode, Issues: []review.Issue{
+			{Severity: "high", Description: "with line", Location: "main.go:12"},
+			{Severity: "low", Description: "file only", Location: "README.md"},
+			{Severity: "low", Description: "no location"},
+		}}, nil
+	})
+
+	result, err := run(context.Background(), review.ModeSecurity)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	want := []string{
+		"https://github.com/o/r/blob/abc123/main.go#L12",
+		"https://github.com/o/r/blob/abc123/README.md",
+		"",
+	}
+	for i, issue := range result.Issues {
+		if issue.URL != want[i] {
+			t.Errorf("issue %d URL = %q, want %q", i, issue.URL, want[i])
+		}
+	}
+}
+
+func TestIssueLinker_Disabled(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Report.Links.Provider = "none"
+	if linker := issueLinker(cfg, nil); linker != nil {
+		t.Error("expected no linker when links are disabled")
+	}
+}
+
+// =============================================================================
+// Tests for issue suppression
+// =============================================================================
+
+func TestReviewCmd_HasNoIgnoreFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("no-ignore") == nil {
+		t.Error("expected --no-ignore flag on review command")
+	}
+}
+
+func TestWithSuppression(t *testing.T) {
+	rules, err := suppress.Parse(strings.NewReader("vendor/**\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := withSuppression(suppress.New(rules, &diff.Diff{}), func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		return &review.Result{Mode: mode, Status: review.StatusIssues, Issues: []review.Issue{
+			{Severity: "high", Description: "vendored", Location: "vendor/lib.go:3"},
+			{Severity: "low", Description: "ours", Location: "main.go:1"},
+		}}, nil
+	})
+
+	result, err := run(context.Background(), review.ModeSecurity)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Description != "ours" {
+		t.Errorf("Issues = %v, want only the non-vendored issue", result.Issues)
+	}
+	if summary := review.Summarize([]*review.Result{result}); summary.Suppressed != 1 {
+		t.Errorf("Summary.Suppressed = %d, want 1", summary.Suppressed)
+	}
+}
+
+func TestReviewCmd_HasPromoteSuggestionsFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("promote-suggestions") == nil {
+		t.Error("expected --promote-suggestions flag on review command")
+	}
+}
+
+func TestReviewCmd_HasInlineFlag(t *testing.T) {
+	flag := reviewCmd.Flags().Lookup("inline")
+	if flag == nil {
+		t.Fatal("expected --inline flag on review command")
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("--inline default = %s, want false", flag.DefValue)
+	}
+}
+
+// =============================================================================
+// Tests for CI detection
+// =============================================================================
+
+// clearCIEnv unsets the variables detectCI looks at for the rest of the test
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range append([]string{"CI"}, ciVars...) {
+		t.Setenv(name, "")
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  bool
+	}{
+		{"no variables", "", "", false},
+		{"CI true", "CI", "true", true},
+		{"CI 1", "CI", "1", true},
+		{"CI false", "CI", "false", false},
+		{"CI 0", "CI", "0", false},
+		{"GitHub Actions", "GITHUB_ACTIONS", "true", true},
+		{"GitLab", "GITLAB_CI", "true", true},
+		{"Jenkins", "JENKINS_URL", "https://ci.example.com/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearCIEnv(t)
+			if tt.key != "" {
+				t.Setenv(tt.key, tt.value)
+			}
+			if got := detectCI(); got != tt.want {
+				t.Errorf("detectCI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCI_FlagOverridesEnvironment(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Bool("ci", false, "")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("ParseFlags() error = %v", err)
+		}
+		return cmd
+	}
+
+	clearCIEnv(t)
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if !isCI(newCmd()) {
+		t.Error("isCI() = false with GITHUB_ACTIONS set, want true")
+	}
+	if isCI(newCmd("--ci=false")) {
+		t.Error("isCI() = true with --ci=false, want false")
+	}
+
+	clearCIEnv(t)
+	if isCI(newCmd()) {
+		t.Error("isCI() = true without CI variables, want false")
+	}
+	if !isCI(newCmd("--ci")) {
+		t.Error("isCI() = false with --ci, want true")
+	}
+}
+
+func TestCIRequested_OnlyWithExplicitFlag(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"--ci"}, true},
+		{[]string{"--ci=false"}, false},
+	} {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Bool("ci", false, "")
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("ParseFlags() error = %v", err)
+		}
+		if got := ciRequested(cmd); got != tt.want {
+			t.Errorf("ciRequested(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCIExitError(t *testing.T) {
+	codes := config.CIExitCodes{High: 2, Medium: 1, Low: 0}
+	tests := []struct {
+		name    string
+		summary review.Summary
+		codes   config.CIExitCodes
+		want    int
+	}{
+		{"no issues", review.Summary{}, codes, 0},
+		{"low only", review.Summary{LowSeverity: 3}, codes, 0},
+		{"medium", review.Summary{MediumSeverity: 1, LowSeverity: 2}, codes, 1},
+		{"high", review.Summary{HighSeverity: 1, MediumSeverity: 1}, codes, 2},
+		{"custom low", review.Summary{LowSeverity: 1}, config.CIExitCodes{High: 3, Medium: 2, Low: 1}, 1},
+		{"high mapped to zero", review.Summary{HighSeverity: 1, MediumSeverity: 1}, config.CIExitCodes{High: 0, Medium: 1}, 1},
+		{"high in baseline", review.Summary{HighSeverity: 1, BaselineHigh: 1, LowSeverity: 1}, codes, 0},
+		{"new high beside baseline", review.Summary{HighSeverity: 2, BaselineHigh: 1}, codes, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ciExitError(tt.codes, tt.summary)
+			if got := ExitStatus(err); got != tt.want {
+				t.Errorf("ExitStatus(ciExitError()) = %d, want %d", got, tt.want)
+			}
+			if err != nil && errorCode(err) != CodeBlocked {
+				t.Errorf("errorCode() = %q, want %q", errorCode(err), CodeBlocked)
+			}
+		})
+	}
+}
+
+func TestValidateCIExitCodes(t *testing.T) {
+	if err := validateCIExitCodes(config.CIExitCodes{High: 2, Medium: 1}); err != nil {
+		t.Errorf("validateCIExitCodes() error = %v", err)
+	}
+	err := validateCIExitCodes(config.CIExitCodes{High: 300})
+	if errorCode(err) != CodeInvalidInput {
+		t.Errorf("validateCIExitCodes(300) = %v, want an invalid input error", err)
+	}
+}
+
+func TestReviewCmd_HasExitStatusFlags(t *testing.T) {
+	for _, name := range []string{"exit-high", "exit-medium", "exit-low"} {
+		if reviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected review command to have --%s flag", name)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for confirming the estimated review cost
+// =============================================================================
+
+func TestCostNeedsConfirmation(t *testing.T) {
+	cfg := &config.Config{Review: config.ReviewConfig{ConfirmCost: 2}}
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		est  ai.CostEstimate
+		want bool
+	}{
+		{"below threshold", cfg, ai.CostEstimate{USD: 1.5, Priced: true}, false},
+		{"above threshold", cfg, ai.CostEstimate{USD: 2.5, Priced: true}, true},
+		{"unknown price", cfg, ai.CostEstimate{InputTokens: 1_000_000}, false},
+		{"disabled", &config.Config{}, ai.CostEstimate{USD: 100, Priced: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := costNeedsConfirmation(tt.cfg, tt.est); got != tt.want {
+				t.Errorf("costNeedsConfirmation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostLimitError(t *testing.T) {
+	cfg := &config.Config{Review: config.ReviewConfig{ConfirmCost: 2}}
+	err := costLimitError(cfg, ai.CostEstimate{USD: 3.25, Priced: true})
+	if errorCode(err) != CodeCostLimit {
+		t.Errorf("errorCode() = %q, want %q", errorCode(err), CodeCostLimit)
+	}
+	if !strings.Contains(err.Error(), "$3.25") || !strings.Contains(err.Error(), "$2.00") {
+		t.Errorf("error = %q, want the estimate and the threshold", err)
+	}
+}
+
+func TestConfirmCost(t *testing.T) {
+	cfg := &config.Config{Review: config.ReviewConfig{ConfirmCost: 2}}
+	for input, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		var out bytes.Buffer
+		if got := confirmCost(strings.NewReader(input), &out, cfg); got != want {
+			t.Errorf("confirmCost(%q) = %v, want %v", input, got, want)
+		}
+		if !strings.Contains(out.String(), "$2.00") {
+			t.Errorf("prompt = %q, want the threshold", out.String())
+		}
+	}
+}
+
+func TestReviewCost_CountsCrossCheckedModes(t *testing.T) {
+	staged := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n")
+	modes := []review.Mode{review.ModeSecurity, review.ModeStyle}
+	client := ai.NewClientWrapper("claude-sonnet-4-5")
+
+	plain := reviewCost(&config.Config{}, client, staged, modes)
+	if plain.Requests != 2 {
+		t.Fatalf("Requests = %d, want 2", plain.Requests)
+	}
+
+	cfg := &config.Config{Review: config.ReviewConfig{CrossCheck: config.CrossCheckConfig{Model: "claude-opus-4-5", Modes: []string{"Security"}}}}
+	checked := reviewCost(cfg, client, staged, modes)
+	if checked.Requests != 3 {
+		t.Errorf("Requests = %d, want 3 with security cross-checked", checked.Requests)
+	}
+	if checked.USD <= plain.USD {
+		t.Errorf("USD = %f, want more than %f with a cross-check", checked.USD, plain.USD)
+	}
+}
+
+func TestReviewCost_Offline(t *testing.T) {
+	staged := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n")
+	client := ai.NewClientWrapper("claude-sonnet-4-5")
+	client.SetOffline(true)
+
+	cfg := &config.Config{Review: config.ReviewConfig{Offline: true, CrossCheck: config.CrossCheckConfig{Model: "claude-opus-4-5", Modes: []string{"security"}}}}
+	if est := reviewCost(cfg, client, staged, []review.Mode{review.ModeSecurity}); est.Requests != 0 || est.USD != 0 {
+		t.Errorf("estimate = %+v, want nothing offline", est)
+	}
+}
+
+func TestWithCrossCheck_Offline(t *testing.T) {
+	calls := 0
+	run := func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		calls++
+		return &review.Result{Mode: mode, Status: review.StatusNoIssues}, nil
+	}
+	cfg := &config.Config{Review: config.ReviewConfig{Offline: true, CrossCheck: config.CrossCheckConfig{Model: "claude-opus-4-5", Modes: []string{"security"}}}}
+
+	wrapped, err := withCrossCheck(cfg, &diff.Diff{}, run)
+	if err != nil {
+		t.Fatalf("withCrossCheck() error = %v", err)
+	}
+	if _, err := wrapped(context.Background(), review.ModeSecurity); err != nil || calls != 1 {
+		t.Errorf("wrapped review: err = %v, calls = %d; want the primary review only", err, calls)
+	}
+}
+
+func TestReviewCmd_HasOfflineFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("offline") == nil {
+		t.Error("expected review command to have --offline flag")
+	}
+}
+
+func TestReviewCmd_HasConfirmCostFlag(t *testing.T) {
+	if reviewCmd.Flags().Lookup("confirm-cost") == nil {
+		t.Error("expected review command to have --confirm-cost flag")
+	}
+}
+
+// =============================================================================
+// Tests for per-mode models
+// =============================================================================
+
+func TestSetModeModels(t *testing.T) {
+	cfg := &config.Config{AI: config.AIConfig{Models: map[string]string{"Style": "haiku", "docs": " "}}}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("model", "", "")
+		return cmd
+	}
+
+	client := ai.NewClientWrapper("claude-opus-4-5")
+	if err := setModeModels(newCmd(), client, cfg); err != nil {
+		t.Fatalf("setModeModels() error = %v", err)
+	}
+	if got := client.ModelFor(review.ModeStyle); got != "haiku" {
+		t.Errorf("ModelFor(style) = %q, want haiku", got)
+	}
+	if got := client.ModelFor(review.ModeDocs); got != "claude-opus-4-5" {
+		t.Errorf("ModelFor(docs) = %q, want the main model for an empty entry", got)
+	}
+
+	cmd := newCmd()
+	_ = cmd.Flags().Set("model", "claude-opus-4-5")
+	client = ai.NewClientWrapper("claude-opus-4-5")
+	if err := setModeModels(cmd, client, cfg); err != nil {
+		t.Fatalf("setModeModels(--model) error = %v", err)
+	}
+	if got := client.ModelFor(review.ModeStyle); got != "claude-opus-4-5" {
+		t.Errorf("ModelFor(style) = %q, want --model to review every mode", got)
+	}
+
+	cfg.AI.Models = map[string]string{"speed": "haiku"}
+	if err := setModeModels(newCmd(), client, cfg); errorCode(err) != CodeInvalidInput {
+		t.Errorf("setModeModels(unknown mode) = %v, want %s", err, CodeInvalidInput)
+	}
+}
+
+// =============================================================================
+// Tests for the retry policy
+// =============================================================================
+
+func TestRetryPolicy_FromConfig(t *testing.T) {
+	cfg := &config.Config{AI: config.AIConfig{Retry: config.RetryConfig{RateLimit: 1, Network: 2, Process: 0, Backoff: time.Second, Delay: time.Minute, Deadline: time.Hour}}}
+	want := ai.RetryPolicy{RateLimitRetries: 1, NetworkRetries: 2, Backoff: time.Second, Delay: time.Minute, Deadline: time.Hour}
+	if got := retryPolicy(cfg); got != want {
+		t.Errorf("retryPolicy() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRootCmd_HasRetryFlags(t *testing.T) {
+	for _, name := range []string{"rate-limit-retries", "retry-backoff", "retry-deadline"} {
+		if rootCmd.PersistentFlags().Lookup(name) == nil {
+			t.Errorf("expected root command to have --%s flag", name)
+		}
+		if !slices.Contains(batchGlobalFlags, name) {
+			t.Errorf("expected --%s to be passed on to batch reviews", name)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for review history
+// =============================================================================
+
+func TestRootCmd_HasHistoryCommand(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range historyCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, name := range []string{"list", "show"} {
+		if !names[name] {
+			t.Errorf("expected history %s subcommand", name)
+		}
+	}
+}
+
+func TestReviewRecord_Wrap(t *testing.T) {
+	rec := &reviewRecord{}
+	run := rec.wrap(func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		return &review.Result{Mode: mode, Status: review.StatusNoIssues}, nil
+	})
+	for _, mode := range []review.Mode{review.ModeSecurity, review.ModeStyle} {
+		if _, err := run(context.Background(), mode); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+	}
+	rec.setFixesApplied(2)
+
+	if len(rec.entry.Results) != 2 || rec.entry.Modes[1] != review.ModeStyle {
+		t.Errorf("recorded %v with %d results, want both modes", rec.entry.Modes, len(rec.entry.Results))
+	}
+	if rec.entry.FixesApplied != 2 {
+		t.Errorf("FixesApplied = %d, want 2", rec.entry.FixesApplied)
+	}
+
+	// A nil record passes reviews through unchanged
+	var none *reviewRecord
+	if _, err := none.wrap(run)(context.Background(), review.ModeDocs); err != nil {
+		t.Errorf("nil record wrap error = %v", err)
+	}
+	none.setFixesApplied(1)
+	none.save()
+}
+
+func TestWriteHistoryList_NewestFirst(t *testing.T) {
+	entries := []history.Entry{
+		{ID: "aaaa1111", Time: time.Now().Add(-time.Hour), Source: "staged changes", Modes: []review.Mode{review.ModeSecurity}},
+		{ID: "bbbb2222", Time: time.Now(), Source: "commit HEAD", Modes: []review.Mode{review.ModeStyle, review.ModeDocs},
+			Commit: "0123456789abcdef", Results: []*review.Result{{Issues: []review.Issue{{Severity: "low"}}}}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeHistoryList(&buf, entries); err != nil {
+		t.Fatalf("writeHistoryList() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two rows, got:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "bbbb2222") || !strings.Contains(lines[1], "style,docs") || !strings.Contains(lines[1], "01234567") {
+		t.Errorf("newest entry should come first with its modes and commit, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "aaaa1111") || !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("older entry without a commit should show -, got %q", lines[2])
+	}
+}
+
+// =============================================================================
+// Tests for transcript command
+// =============================================================================
+
+func TestWriteTranscript(t *testing.T) {
+	start := time.Date(2026, 10, 18, 9, 30, 0, 0, time.Local)
+	run := transcript.Run{ID: "20261018-093000-abc123", Entries: []transcript.Entry{
+		{Time: start, Kind: transcript.KindStart, Text: "revi review --all"},
+		{Time: start.Add(time.Second), Kind: transcript.KindPrompt, Mode: "security", Model: "claude-sonnet-4-5", Text: "Review this diff\n", Redacted: 1},
+		{Time: start.Add(5 * time.Second), Kind: transcript.KindResponse, Mode: "security", Model: "claude-sonnet-4-5", Text: `{"issues": []}`},
+	}}
+
+	var buf bytes.Buffer
+	writeTranscript(&buf, run)
+	for _, want := range []string{
+		"Run 20261018-093000-abc123",
+		"Command: revi review --all",
+		"09:30:01 prompt (security, claude-sonnet-4-5), 1 secret(s) redacted",
+		"Review this diff\n\n09:30:05 response (security, claude-sonnet-4-5)",
+		`{"issues": []}`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("transcript missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeTranscriptList(&buf, []transcript.Run{run}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "revi review --all") {
+		t.Errorf("list = %q, want a header and the run with its command", buf.String())
+	}
+}
+
+func TestTranscriptCmd_HasSubcommands(t *testing.T) {
+	for _, name := range []string{"list", "show"} {
+		if cmd, _, err := transcriptCmd.Find([]string{name}); err != nil || cmd.Name() != name {
+			t.Errorf("expected transcript %s subcommand", name)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for snapshot command
+// =============================================================================
+
+func TestSnapshotReviewCmd_SharesReviewFlags(t *testing.T) {
+	for _, name := range []string{"all", "fix", "no-tui", "security", snapshotSource} {
+		if snapshotReviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on snapshot review command", name)
+		}
+	}
+	for _, name := range []string{"staged", "working-tree", "unstaged", "range", "commit", "patch", "stdin", "pr"} {
+		if snapshotReviewCmd.Flags().Lookup(name) != nil {
+			t.Errorf("snapshot review should not accept --%s", name)
+		}
+	}
+	if err := snapshotReviewCmd.ValidateFlagGroups(); err != nil {
+		t.Errorf("ValidateFlagGroups() error = %v", err)
+	}
+}
+
+// =============================================================================
+// Tests for merge request reviews
+// =============================================================================
+
+// fakeForge records comments and rejects line comments on rejectPath
+type fakeForge struct {
+	comments   []forge.Comment
+	rejectPath string
+}
+
+func (f *fakeForge) Name() string { return "fake" }
+
+func (f *fakeForge) MergeRequest(ctx context.Context, number int) (*forge.MergeRequest, error) {
+	return &forge.MergeRequest{Number: number}, nil
+}
+
+func (f *fakeForge) Diff(ctx context.Context, mr *forge.MergeRequest) (string, error) {
+	return "", nil
+}
+
+func (f *fakeForge) Comment(ctx context.Context, mr *forge.MergeRequest, c forge.Comment) error {
+	if c.Path != "" && c.Path == f.rejectPath {
+		return forge.ErrLineNotInDiff
+	}
+	f.comments = append(f.comments, c)
+	return nil
+}
+
+func TestMRReviewCmd_SharesReviewFlags(t *testing.T) {
+	for _, name := range append([]string{"no-comment"}, mrReviewFlags...) {
+		if mrReviewCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on mr review command", name)
+		}
+	}
+}
+
+func TestPostReviewComments(t *testing.T) {
+	host := &fakeForge{rejectPath: "old.go"}
+	results := []*review.Result{{
+		Mode:    review.ModeSecurity,
+		Status:  review.StatusIssues,
+		Summary: "One injection",
+		Issues: []review.Issue{
+			{Severity: "high", Description: "SQL injection", Location: "db.go:12",
+				Fix: &review.Fix{Available: true, Code: "db.Query(q, id)", Explanation: "use a parameter"}},
+			{Severity: "low", Description: "Outside the diff", Location: "old.go:3"},
+			{Severity: "medium", Description: "No location"},
+		},
+	}}
+
+	posted, err := postReviewComments(context.Background(), host, &forge.MergeRequest{Number: 1}, results)
+	if err != nil {
+		t.Fatalf("postReviewComments() error = %v", err)
+	}
+	if posted != 2 || len(host.comments) != 2 {
+		t.Fatalf("posted %d comments (%d recorded), want a line comment and the summary", posted, len(host.comments))
+	}
+
+	line := host.comments[0]
+	if line.Path != "db.go" || line.Line != 12 {
+		t.Errorf("line comment at %s:%d, want db.go:12", line.Path, line.Line)
+	}
+	for _, want := range []string{"[HIGH] Security", "SQL injection", "use a parameter", "db.Query(q, id)"} {
+		if !strings.Contains(line.Body, want) {
+			t.Errorf("line comment should contain %q, got:\n%s", want, line.Body)
+		}
+	}
+
+	summary := host.comments[1]
+	if summary.Path != "" {
+		t.Errorf("summary should be a general comment, got path %q", summary.Path)
+	}
+	for _, want := range []string{"3 issue(s) found: 1 high, 1 medium, 1 low", "One injection", "Outside the diff", "`old.go:3`", "No location"} {
+		if !strings.Contains(summary.Body, want) {
+			t.Errorf("summary should contain %q, got:\n%s", want, summary.Body)
+		}
+	}
+	if strings.Contains(summary.Body, "SQL injection") {
+		t.Errorf("issues commented on their line should not be repeated in the summary:\n%s", summary.Body)
+	}
+}
+
+// =============================================================================
+// Tests for issue blame
+// =============================================================================
+
+func TestWithBlame(t *testing.T) {
+	now := time.Now()
+	blamed := 0
+	blamer := &issueBlamer{
+		blame: func(path string) ([]git.LineAuthor, error) {
+			blamed++
+			if path != "main.go" {
+				return nil, fmt.Errorf("%s is not in HEAD", path)
+			}
+			lines := make([]git.LineAuthor, 20)
+			for i := range lines {
+				lines[i] = git.LineAuthor{Name: "Old", Email: "old@example.com", When: now.Add(-time.Hour)}
+			}
+			lines[13] = git.LineAuthor{Name: "Recent", Email: "recent@example.com", When: now}
+			return lines, nil
+		},
+		files: make(map[string][]git.LineAuthor),
+	}
+	run := withBlame(blamer, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		return &review.Result{Mode: mode, Issues: []review.Issue{
+			{Description: "near the recent change", Location: "main.go:12"},
+			{Description: "far from it", Location: "main.go:3"},
+			{Description: "new file", Location: "new.go:1"},
+			{Description: "no line", Location: "main.go"},
+		}}, nil
+	})
+
+	result, err := run(context.Background(), review.ModeSecurity)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	want := []string{"Recent <recent@example.com>", "Old <old@example.com>", "", ""}
+	for i, issue := range result.Issues {
+		if issue.Author != want[i] {
+			t.Errorf("issue %d Author = %q, want %q", i, issue.Author, want[i])
+		}
+	}
+	if blamed != 2 {
+		t.Errorf("blamed %d times, want each file once", blamed)
+	}
+}
+
+func TestNewIssueBlamer_Disabled(t *testing.T) {
+	if newIssueBlamer(&config.Config{}, nil) != nil {
+		t.Error("expected no blamer unless report.blame is set")
+	}
+}
+
+// =============================================================================
+// Tests for fix undo
+// =============================================================================
+
+func TestRootCmd_HasFixCommand(t *testing.T) {
+	names := make(map[string]bool)
+	for _, sub := range fixCmd.Commands() {
+		names[sub.Name()] = true
+	}
+	for _, name := range []string{"list", "undo"} {
+		if !names[name] {
+			t.Errorf("expected fix %s subcommand", name)
+		}
+	}
+}
+
+func TestWriteFixList_NewestFirst(t *testing.T) {
+	entries := []fix.JournalEntry{
+		{ID: "1", Path: "/repo/a.go", Fix: &review.Fix{StartLine: 3, EndLine: 4, Explanation: "close the file\nin every path"}},
+		{ID: "2", Path: "/elsewhere/b.go"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeFixList(&buf, "/repo", entries); err != nil {
+		t.Fatalf("writeFixList() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two rows, got:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "2 ") || !strings.Contains(lines[1], "/elsewhere/b.go") {
+		t.Errorf("newest fix should come first with its absolute path, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], " a.go ") || !strings.Contains(lines[2], "3-4") || !strings.HasSuffix(lines[2], "close the file") {
+		t.Errorf("older fix should show its relative path, lines and explanation, got %q", lines[2])
+	}
+}
+
+// =============================================================================
+// Tests for the workflow timeout
+// =============================================================================
+
+func TestWorkflowContext_Timeout(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("timeout", 0, "")
+
+	ctx, cancel := workflowContext(cmd)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline without --timeout")
+	}
+	cancel()
+
+	_ = cmd.Flags().Set("timeout", "1ms")
+	ctx, cancel = workflowContext(cmd)
+	defer cancel()
+	<-ctx.Done()
+	if !timedOut(ctx) {
+		t.Error("timedOut() = false after the deadline passed")
+	}
+
+	err := timeoutError(cmd)
+	if errorCode(err) != CodeTimedOut || !strings.Contains(err.Error(), "partial review (timed out after 1ms)") {
+		t.Errorf("timeoutError() = %v (%s)", err, errorCode(err))
+	}
+}
+
+// =============================================================================
+// Tests for revi batch
+// =============================================================================
+
+func TestBatchRepos_ExpandsGlobsToRepositories(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"api", "web", "notes"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(dir, name, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := filepath.Join(dir, "repos.txt")
+	if err := os.WriteFile(list, []byte("# platform repos\n"+filepath.Join(dir, "api")+"\n\n/srv/billing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("repos-from", "", "")
+	_ = cmd.Flags().Set("repos-from", list)
+	repos, err := batchRepos(cmd, []string{filepath.Join(dir, "*")})
+	if err != nil {
+		t.Fatalf("batchRepos() error: %v", err)
+	}
+	want := []string{filepath.Join(dir, "api"), filepath.Join(dir, "web"), "/srv/billing"}
+	if strings.Join(repos, ",") != strings.Join(want, ",") {
+		t.Errorf("batchRepos() = %v, want %v", repos, want)
+	}
+
+	if _, err := batchRepos(&cobra.Command{Use: "test"}, nil); errorCode(err) != CodeInvalidInput {
+		t.Errorf("batchRepos() without repositories error = %v, want an invalid_input error", err)
+	}
+}
+
+func TestBatchReviewArgs_ForwardsChangedFlags(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("range", "", "")
+	cmd.Flags().String("config", "", "")
+	cmd.Flags().String("model", "", "")
+	cmd.Flags().Int("jobs", 1, "")
+	cmd.Flags().StringArray("path", nil, "")
+	_ = cmd.Flags().Set("range", "main..HEAD")
+	_ = cmd.Flags().Set("config", "ci.yaml")
+	_ = cmd.Flags().Set("jobs", "4")
+	_ = cmd.Flags().Set("path", "api/**")
+	_ = cmd.Flags().Set("path", "*.sql")
+
+	args, err := batchReviewArgs(cmd)
+	if err != nil {
+		t.Fatalf("batchReviewArgs() error: %v", err)
+	}
+	abs, _ := filepath.Abs("ci.yaml")
+	want := []string{"--config=" + abs, "--path=api/**", "--path=*.sql", "--range=main..HEAD"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("batchReviewArgs() = %v, want %v", args, want)
+	}
+}
+
+func TestScopeDiff(t *testing.T) {
+	staged := diff.NewDiff("diff --git a/api/handler.go b/api/handler.go\n--- a/api/handler.go\n+++ b/api/handler.go\n@@ -1 +1 @@\n-old\n+new\n" +
+		"diff --git a/api/gen/types.go b/api/gen/types.go\n--- a/api/gen/types.go\n+++ b/api/gen/types.go\n@@ -1 +1 @@\n-old\n+new\n" +
+		"diff --git a/web/app.js b/web/app.js\n--- a/web/app.js\n+++ b/web/app.js\n@@ -1 +1 @@\n-old\n+new\n")
+	tests := []struct {
+		name             string
+		paths            []string
+		include, exclude []string
+		want             []string
+	}{
+		{"unscoped", nil, nil, nil, []string{"api/handler.go", "api/gen/types.go", "web/app.js"}},
+		{"config", nil, []string{"web/**"}, nil, []string{"web/app.js"}},
+		{"flag replaces include", []string{"api/**"}, []string{"web/**"}, nil, []string{"api/handler.go", "api/gen/types.go"}},
+		{"exclude applies to the flag", []string{"api/**"}, nil, []string{"api/gen/**"}, []string{"api/handler.go"}},
+		{"nothing matches", []string{"docs/**"}, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringArray("path", nil, "")
+			cmd.Flags().String("output", "json", "")
+			for _, p := range tt.paths {
+				_ = cmd.Flags().Set("path", p)
+			}
+			cfg := &config.Config{Review: config.ReviewConfig{Include: tt.include, Exclude: tt.exclude}}
+
+			got, ok := scopeDiff(cmd, cfg, staged)
+			if ok != (tt.want != nil) {
+				t.Fatalf("scopeDiff() ok = %v, want %v", ok, tt.want != nil)
+			}
+			if ok && !slices.Equal(got.Paths(), tt.want) {
+				t.Errorf("scopeDiff() paths = %q, want %q", got.Paths(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatchReviews_KeepsRepositoryOrder(t *testing.T) {
+	repos := []string{"slow", "fast", "empty"}
+	reviewRepo := func(ctx context.Context, dir string, args []string) batchRepo {
+		switch dir {
+		case "slow":
+			time.Sleep(20 * time.Millisecond)
+			return batchRepo{Summary: review.Summary{HighSeverity: 1}, Blocked: true}
+		case "empty":
+			return batchRepo{Error: &batchError{Code: CodeNoChanges, Message: "no staged changes found"}}
+		}
+		return batchRepo{}
+	}
+
+	var progress bytes.Buffer
+	outcomes := runBatchReviews(context.Background(), repos, nil, 3, reviewRepo, &progress)
+	for i, repo := range repos {
+		if outcomes[i].Repo != repo {
+			t.Errorf("outcome %d is for %q, want %q", i, outcomes[i].Repo, repo)
+		}
+	}
+	if !outcomes[0].Blocked || outcomes[2].note() != "No changes" || outcomes[2].failed() {
+		t.Errorf("outcomes = %+v", outcomes)
+	}
+	if got := strings.Count(progress.String(), "\n"); got != 3 {
+		t.Errorf("expected a progress line per repository, got:\n%s", progress.String())
+	}
+}
+
+func TestParseReviewOutput(t *testing.T) {
+	var report bytes.Buffer
+	results := []*review.Result{{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{{Severity: review.SeverityHigh, Description: "SQL injection"}}}}
+	if err := writeJSONReport(&report, results, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := parseReviewOutput(report.Bytes(), []byte("Warning: something\n"), fmt.Errorf("exit status 1"))
+	if got.Error != nil || !got.Blocked || got.Summary.HighSeverity != 1 || len(got.Results) != 1 {
+		t.Errorf("blocked review parsed as %+v", got)
+	}
+
+	var stderr bytes.Buffer
+	stderr.WriteString("Warning: tracing disabled\n")
+	writeJSONError(&stderr, withCode(CodeNoChanges, fmt.Errorf("no staged changes found")))
+	got = parseReviewOutput(nil, stderr.Bytes(), fmt.Errorf("exit status 1"))
+	if got.Error == nil || got.Error.Code != CodeNoChanges || got.failed() {
+		t.Errorf("review without changes parsed as %+v", got)
+	}
+
+	got = parseReviewOutput(nil, []byte("panic: boom\n"), fmt.Errorf("exit status 2"))
+	if !got.failed() || got.Error.Message != "panic: boom" {
+		t.Errorf("crashed review parsed as %+v", got)
+	}
+}
+
+func TestRootCmd_HasBatchCommand(t *testing.T) {
+	for _, name := range []string{"jobs", "repos-from", "report", "range", "all", "no-block"} {
+		if batchCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag on batch command", name)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for revi baseline
+// =============================================================================
+
+func TestBaselineEntry(t *testing.T) {
+	store := history.Open(t.TempDir())
+	if _, err := baselineEntry(store, nil); errorCode(err) != CodeInvalidInput {
+		t.Fatalf("baselineEntry() with no history: error code %q, want %q", errorCode(err), CodeInvalidInput)
+	}
+
+	for _, source := range []string{"first", "second"} {
+		if err := store.Add(&history.Entry{Source: source}); err != nil {
+			t.Fatalf("Add() failed: %v", err)
+		}
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+
+	latest, err := baselineEntry(store, nil)
+	if err != nil || latest.Source != "second" {
+		t.Errorf("baselineEntry() = %q, %v; want the most recent review", latest.Source, err)
+	}
+	named, err := baselineEntry(store, []string{entries[0].ID})
+	if err != nil || named.Source != "first" {
+		t.Errorf("baselineEntry(%s) = %q, %v; want the named review", entries[0].ID, named.Source, err)
+	}
+	if _, err := baselineEntry(store, []string{"nope"}); errorCode(err) != CodeInvalidInput {
+		t.Errorf("baselineEntry(unknown) error code %q, want %q", errorCode(err), CodeInvalidInput)
+	}
+}
+
+func TestKnownIssues(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := gogit.PlainInit(dir, false); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCmd := func(from string) *cobra.Command {
+		cmd := &cobra.Command{Use: "review"}
+		cmd.Flags().String("baseline", "", "")
+		_ = cmd.Flags().Set("baseline", from)
+		return cmd
+	}
+	known := review.Issue{Description: "Hardcoded key", Location: "b.go:1"}
+
+	if got, err := knownIssues(&cobra.Command{Use: "mr"}, repo); got != nil || err != nil {
+		t.Errorf("knownIssues() without --baseline = %v, %v; want nil", got, err)
+	}
+	if _, err := knownIssues(newCmd("last"), repo); errorCode(err) != CodeInvalidInput {
+		t.Errorf("knownIssues(last) with no history: error code %q, want %q", errorCode(err), CodeInvalidInput)
+	}
+
+	store, err := historyStore(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(&history.Entry{Results: []*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{known}}}}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := store.List()
+	for _, from := range []string{"last", entries[0].ID} {
+		b, err := knownIssues(newCmd(from), repo)
+		if err != nil || !b.Contains(review.ModeSecurity, known) {
+			t.Errorf("knownIssues(%s) = %v, %v; want the recorded review", from, b, err)
+		}
+	}
+
+	output := filepath.Join(dir, "review.json")
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, []*review.Result{{Mode: review.ModeStyle, Issues: []review.Issue{{Description: "Long line", Location: "a.go:3"}}}}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := knownIssues(newCmd(output), repo); err != nil || !b.Contains(review.ModeStyle, review.Issue{Description: "Long line", Location: "a.go:3"}) {
+		t.Errorf("knownIssues(%s) = %v, %v; want the issues of the JSON output", output, b, err)
+	}
+	if _, err := knownIssues(newCmd("nope"), repo); errorCode(err) != CodeInvalidInput {
+		t.Errorf("knownIssues(nope) error code %q, want %q", errorCode(err), CodeInvalidInput)
+	}
+}
+
+func TestWithKnownIssues_KeepsRecordedIssues(t *testing.T) {
+	known := review.Issue{Description: "Hardcoded key", Location: "b.go:1"}
+	filter := suppress.New(nil, &diff.Diff{})
+	filter.SetKnown(baseline.New([]*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{known}}}))
+
+	var recorded *review.Result
+	run := withKnownIssues(filter, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		recorded = &review.Result{Mode: mode, Status: review.StatusIssues, Issues: []review.Issue{known, {Description: "Weak hash", Location: "b.go:9"}}}
+		return recorded, nil
+	})
+
+	result, err := run(context.Background(), review.ModeSecurity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Known != 1 {
+		t.Errorf("result = %+v, want only the new issue", result)
+	}
+	if len(recorded.Issues) != 2 {
+		t.Errorf("recorded issues = %+v, want both kept for the history", recorded.Issues)
+	}
+}
+
+// =============================================================================
+// Tests for the review status file and terminal notifications
+// =============================================================================
+
+func TestReviewOutcome(t *testing.T) {
+	issues := []*review.Result{{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming"}}}}
+	clean := []*review.Result{{Mode: review.ModeStyle, Status: review.StatusNoIssues}}
+
+	if got := reviewOutcome(issues, true); got != statusBlocked {
+		t.Errorf("blocked outcome = %q, want %q", got, statusBlocked)
+	}
+	if got := reviewOutcome(issues, false); got != statusIssues {
+		t.Errorf("outcome with issues = %q, want %q", got, statusIssues)
+	}
+	if got := reviewOutcome(clean, false); got != statusClean {
+		t.Errorf("clean outcome = %q, want %q", got, statusClean)
+	}
+}
+
+func TestWriteReviewStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), statusFile)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	summary := review.Summary{HighSeverity: 2, MediumSeverity: 1}
+	if err := writeReviewStatus(path, statusBlocked, summary, now); err != nil {
+		t.Fatalf("writeReviewStatus() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read status file: %v", err)
+	}
+	want := "blocked\nhigh=2\nmedium=1\nlow=0\ntimed_out=0\ntime=2026-03-01T12:00:00Z\n"
+	if string(data) != want {
+		t.Errorf("status file = %q, want %q", data, want)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary status file should be renamed into place")
+	}
+}
+
+func TestNotifyTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	notifyTerminal(&buf, true, "2;revi: {status}", statusClean)
+	if got := buf.String(); got != "\x1b]2;revi: clean\x07\a" {
+		t.Errorf("notifyTerminal() wrote %q", got)
+	}
+
+	buf.Reset()
+	notifyTerminal(&buf, false, "", statusBlocked)
+	if buf.Len() != 0 {
+		t.Errorf("notifyTerminal() without bell or OSC wrote %q", buf.String())
+	}
+}
+
+// =============================================================================
+// Tests for --show-payload
+// =============================================================================
+
+func TestBuildPayload(t *testing.T) {
+	raw := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n@@ -9 +9 @@\n-c\n+ c\n" +
+		"diff --git a/go.sum b/go.sum\n# content omitted from review: lockfile\n" +
+		"diff --git a/fmt.go b/fmt.go\n--- a/fmt.go\n+++ b/fmt.go\n@@ -1 +1 @@\n-x\n+ x\n")
+	sent := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/go.sum b/go.sum\n# content omitted from review: lockfile\n")
+	client := ai.NewClientWrapper("claude-sonnet-4-5")
+	client.SetGuidance(ai.Guidance{Review: "Prefer errors.Is", Modes: map[review.Mode]string{review.ModeSecurity: "No eval"}, Commit: "Use JIRA keys"})
+	cfg := &config.Config{Review: config.ReviewConfig{CrossCheck: config.CrossCheckConfig{Model: "claude-haiku-4-5", Modes: []string{"security"}}}}
+
+	p := buildPayload(client, raw, sent, false).withCrossCheck(cfg)
+	if p.Bytes != len(sent.String()) || len(p.Files) != 3 {
+		t.Fatalf("payload = %+v", p)
+	}
+	if f := p.Files[0]; f.Hunks != 1 || f.Dropped != 1 {
+		t.Errorf("main.go = %+v, want 1 hunk sent and 1 left out", f)
+	}
+	if f := p.Files[1]; f.Omitted != "lockfile" {
+		t.Errorf("go.sum = %+v, want its content withheld", f)
+	}
+	if f := p.Files[2]; f.Hunks != 0 || f.Dropped != 1 {
+		t.Errorf("fmt.go = %+v, want it left out", f)
+	}
+
+	text := strings.Join(p.lines(), "\n")
+	for _, want := range []string{
+		"2 file(s)", "claude-sonnet-4-5", "claude-haiku-4-5 (modes: security)",
+		"main.go: 1 hunk(s)", "go.sum: name only, content withheld: lockfile", "fmt.go: not sent, 1 hunk(s) left out",
+		"review (16 B), security (7 B)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("payload missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "commit (") {
+		t.Errorf("commit guidance is not sent with reviews:\n%s", text)
+	}
+
+	commit := strings.Join(buildPayload(client, raw, raw, true).lines(), "\n")
+	if !strings.Contains(commit, "commit (13 B)") || strings.Contains(commit, "review (") {
+		t.Errorf("commit payload should list only the commit guidance:\n%s", commit)
+	}
+}
+
+func TestBuildPayload_ListsRedactedSecrets(t *testing.T) {
+	sent := diff.NewDiff("diff --git a/.env b/.env\n--- a/.env\n+++ b/.env\n@@ -1 +1,2 @@\n # Database\n+DB_PASSWORD=hunter2hunter2\n")
+	client := ai.NewClientWrapper("claude-sonnet-4-5")
+
+	p := buildPayload(client, sent, sent, true)
+	if len(p.Redacted) != 1 || p.Redacted[0].Location != ".env:2" {
+		t.Fatalf("redacted = %+v, want the password at .env:2", p.Redacted)
+	}
+	if text := strings.Join(p.lines(), "\n"); !strings.Contains(text, "1 secret(s) redacted") || !strings.Contains(text, ".env:2: .env value") {
+		t.Errorf("payload should list the redacted secret:\n%s", text)
+	}
+
+	client.SetRedactSecrets(false)
+	if p := buildPayload(client, sent, sent, true); len(p.Redacted) != 0 || p.redactionLines() != nil {
+		t.Errorf("redacted = %+v, want nothing with redaction turned off", p.Redacted)
+	}
+}
+
+func TestConfirmPayload(t *testing.T) {
+	p := payload{Model: "claude-sonnet-4-5"}
+	for input, want := range map[string]bool{"y\n": true, "yes\n": true, "n\n": false, "": false} {
+		var out bytes.Buffer
+		if got := confirmPayload(strings.NewReader(input), &out, p); got != want {
+			t.Errorf("confirmPayload(%q) = %v, want %v", input, got, want)
+		}
+		if !strings.Contains(out.String(), "claude-sonnet-4-5") {
+			t.Errorf("prompt = %q, want the model", out.String())
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for n, want := range map[int]string{512: "512 B", 2048: "2.0 KB", 3 << 20: "3.0 MB"} {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for --dry-run
+// =============================================================================
+
+func TestCommitPlan_Lines(t *testing.T) {
+	p := commitPlan{
+		Source:    "staged changes",
+		Files:     []planFile{{Path: "auth/login.go", Status: diff.FileModified, Added: 12, Removed: 3}},
+		Modes:     []review.Mode{review.ModeSecurity, review.ModeErrors},
+		Reasoning: "login handling changed",
+		Message:   ai.CostEstimate{Requests: 1, InputTokens: 900, OutputTokens: 300, USD: 0.01, Priced: true},
+		Review:    ai.CostEstimate{Requests: 2, InputTokens: 3000, OutputTokens: 3000},
+		Commit:    "fix(auth): reject expired sessions\n\nRefs: PROJ-1",
+	}
+	text := strings.Join(p.lines(), "\n")
+	for _, want := range []string{
+		"Plan for staged changes:",
+		"Files (1):",
+		"modified auth/login.go (+12 -3)",
+		"Review modes: security, errors",
+		"  login handling changed",
+		"Commit message: ~900 input + ~300 output tokens in 1 request(s), about $0.01",
+		"cost unknown for this model",
+		"  fix(auth): reject expired sessions\n\n  Refs: PROJ-1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("plan missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestWriteCommitPlan_Porcelain(t *testing.T) {
+	var out bytes.Buffer
+	writeCommitPlan(io.Discard, porcelain.NewWriter(&out, strings.NewReader("")), commitPlan{Source: "staged changes", Commit: "docs: fix typo"})
+
+	if !strings.Contains(out.String(), porcelain.FormatLine(porcelain.KindMessage, "docs: fix typo")) {
+		t.Errorf("porcelain output missing the message record:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), porcelain.FormatLine(porcelain.KindNote, "Dry run - commit not created.")) {
+		t.Errorf("porcelain output missing the dry run note:\n%s", out.String())
+	}
+}
+
+// =============================================================================
+// Tests for --porcelain
+// =============================================================================
+
+func TestWritePorcelainIssues(t *testing.T) {
+	issue := review.Issue{
+		Severity:    "high",
+		Description: "Token logged\nin plain text",
+		Location:    "auth/login.go:42",
+		Fix:         &review.Fix{Available: true},
+	}
+	results := []*review.Result{
+		{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{issue}},
+		nil,
+	}
+
+	var out bytes.Buffer
+	pw := porcelain.NewWriter(&out, strings.NewReader(""))
+	writePorcelainIssues(pw, results)
+	writePorcelainResult(pw, statusIssues, review.Summarize(results))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d records, want version, issue and result:\n%s", len(lines), out.String())
+	}
+	kind, fields := porcelain.ParseLine(lines[1])
+	want := []string{"security", "high", "auth/login.go", "42", "yes", baseline.Fingerprint(review.ModeSecurity, issue), issue.Description}
+	if kind != porcelain.KindIssue || strings.Join(fields, "|") != strings.Join(want, "|") {
+		t.Errorf("issue record = %s %q, want %q", kind, fields, want)
+	}
+	if lines[2] != "result\tissues\t1\t0\t0\t0" {
+		t.Errorf("result record = %q", lines[2])
+	}
+}
+
+func TestConfirmPayloadPorcelain(t *testing.T) {
+	p := payload{Model: "claude-sonnet-4-5", Bytes: 10, Files: []payloadFile{{Path: "main.go", Hunks: 1, Bytes: 10}}}
+
+	var out bytes.Buffer
+	if !confirmPayloadPorcelain(porcelain.NewWriter(&out, strings.NewReader("y\n")), p) {
+		t.Error("confirmPayloadPorcelain() = false for the answer \"y\"")
+	}
+	if !strings.Contains(out.String(), "note\t  main.go: 1 hunk(s), 10 B\n") {
+		t.Errorf("output has no note for main.go:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "prompt\tpayload\tn/y\tSend this to claude-sonnet-4-5?\n") {
+		t.Errorf("output has no payload prompt:\n%s", out.String())
+	}
+}
+
+// =============================================================================
+// Tests for the split command
+// =============================================================================
+
+func TestRootCmd_HasSplitCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"split"})
+	if err != nil || cmd != splitCmd {
+		t.Fatalf("Find(split) = %v, %v, want the split command", cmd, err)
+	}
+	for _, name := range []string{"message", "yes", "no-verify", "fast"} {
+		if splitCmd.Flags().Lookup(name) == nil {
+			t.Errorf("split command should have --%s", name)
+		}
+	}
+}
+
+func TestAskSplitCommit(t *testing.T) {
+	tests := map[string]splitChoice{"y\n": splitCommit, "YES\n": splitCommit, "q\n": splitQuit, "n\n": splitSkip, "\n": splitSkip, "": splitSkip}
+	for input, want := range tests {
+		var out bytes.Buffer
+		if got := askSplitCommit(bufio.NewReader(strings.NewReader(input)), &out); got != want {
+			t.Errorf("askSplitCommit(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestSplitGroupPaths_IncludesRenamedFrom(t *testing.T) {
+	d := diff.NewDiff("diff --git a/old.go b/new.go\nsimilarity index 90%\nrename from old.go\nrename to new.go\n" +
+		"diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n")
+	got := splitGroupPaths(d, ai.CommitGroup{Files: []string{"new.go", "main.go"}})
+	if !slices.Equal(got, []string{"new.go", "old.go", "main.go"}) {
+		t.Errorf("splitGroupPaths() = %q", got)
+	}
+}
+
+func TestPrintSplitPlan(t *testing.T) {
+	var out bytes.Buffer
+	printSplitPlan(&out, []ai.CommitGroup{
+		{Files: []string{"db/migrate.sql"}, Reason: "add the users table"},
+		{Files: []string{"api/users.go"}, Reason: "serve users"},
+	})
+	for _, want := range []string{"2 commit(s)", "1. add the users table", "     db/migrate.sql", "2. serve users"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("plan should contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// =============================================================================
+// Tests for the stage command
+// =============================================================================
+
+func TestRootCmd_HasStageCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"stage"})
+	if err != nil || cmd != stageCmd {
+		t.Fatalf("Find(stage) = %v, %v, want the stage command", cmd, err)
+	}
+}
+
+func TestHunkItems(t *testing.T) {
+	items := hunkItems([]git.Hunk{{Path: "main.go", OldStart: 3, NewStart: 3, Removed: []string{"old"}, Added: []string{"new", "more"}, Staged: true}})
+	if len(items) != 1 {
+		t.Fatalf("hunkItems() returned %d items, want 1", len(items))
+	}
+	got := items[0]
+	if got.Path != "main.go" || got.Header != "@@ -3,1 +3,2 @@" || !got.Staged || !slices.Equal(got.Lines, []string{"-old", "+new", "+more"}) {
+		t.Errorf("hunkItems() = %+v", got)
+	}
+}
+
+// =============================================================================
+// Tests for the serve command
+// =============================================================================
+
+func TestRootCmd_HasServeCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"serve"})
+	if err != nil || cmd != serveCmd {
+		t.Fatalf("Find(serve) = %v, %v, want the serve command", cmd, err)
+	}
+	for _, name := range []string{"lsp", "staged"} {
+		if serveCmd.Flags().Lookup(name) == nil {
+			t.Errorf("serve command should have --%s", name)
+		}
+	}
+}
+
+func TestRunServe_RequiresProtocol(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("lsp", false, "")
+	if err := runServe(cmd, nil); errorCode(err) != CodeInvalidInput {
+		t.Errorf("runServe() without --lsp = %v, want an invalid input error", err)
+	}
+}
+
+// =============================================================================
+// Tests for the daemon command
+// =============================================================================
+
+func TestRootCmd_HasDaemonCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"daemon"})
+	if err != nil || cmd != daemonCmd {
+		t.Fatalf("Find(daemon) = %v, %v, want the daemon command", cmd, err)
+	}
+	for _, name := range append([]string{"listen"}, mrReviewFlags...) {
+		if daemonCmd.Flags().Lookup(name) == nil {
+			t.Errorf("daemon command should have --%s", name)
+		}
+	}
+}
+
+func TestDaemonSource(t *testing.T) {
+	tests := []struct {
+		req      daemonReviewRequest
+		wantKind string
+	}{
+		{daemonReviewRequest{}, "staged"},
+		{daemonReviewRequest{Source: "working-tree"}, "working-tree"},
+		{daemonReviewRequest{Source: "range", Arg: "main..HEAD"}, "range"},
+		{daemonReviewRequest{Diff: "diff --git a/a b/a\n"}, "diff"},
+	}
+	for _, tt := range tests {
+		_, kind, err := daemonSource(nil, tt.req)
+		if err != nil || kind.Name != tt.wantKind {
+			t.Errorf("daemonSource(%+v) = %q, %v, want %q", tt.req, kind.Name, err, tt.wantKind)
+		}
+	}
+
+	for _, req := range []daemonReviewRequest{
+		{Source: "stdin"},
+		{Source: "patch", Arg: "-"},
+		{Source: "patch", Arg: "/etc/passwd"},
+		{Source: "pr", Arg: "1"},
+		{Source: "range"},
+		{Source: "nope"},
+		{Source: "staged", Diff: "diff --git a/a b/a\n"},
+	} {
+		if _, _, err := daemonSource(nil, req); errorCode(err) != CodeInvalidInput {
+			t.Errorf("daemonSource(%+v) error = %v, want an invalid input error", req, err)
+		}
+	}
+}
+
+func TestListenDaemon_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revi", "daemon.sock")
+	listener, err := listenDaemon("unix:"+path, false)
+	if err != nil {
+		t.Fatalf("listenDaemon() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v, %v, want 0600", info, err)
+	}
+	if _, err := listenDaemon("unix:"+path, false); errorCode(err) != CodeLocked {
+		t.Errorf("second listenDaemon() error = %v, want a locked error", err)
+	}
+	listener.Close()
+
+	// A stale file in the way is replaced
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	listener, err = listenDaemon("unix:"+path, false)
+	if err != nil {
+		t.Fatalf("listenDaemon() over a stale file error = %v", err)
+	}
+	listener.Close()
+}
+
+func TestListenDaemon_NonLoopbackNeedsToken(t *testing.T) {
+	if _, err := listenDaemon("0.0.0.0:0", false); errorCode(err) != CodeInvalidInput {
+		t.Errorf("listenDaemon() on every interface error = %v, want an invalid input error without a token", err)
+	}
+	listener, err := listenDaemon("0.0.0.0:0", true)
+	if err != nil {
+		t.Fatalf("listenDaemon() with a token error = %v", err)
+	}
+	listener.Close()
+}
+
+func TestWriteDaemonToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revi", "daemon.token")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := writeDaemonToken(path)
+	if err != nil {
+		t.Fatalf("writeDaemonToken() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) != token || len(token) != 64 {
+		t.Errorf("token file = %q, %v, want the token %q", data, err, token)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("token file mode = %v, %v, want 0600", info, err)
+	}
+	if again, _ := writeDaemonToken(path); again == token {
+		t.Error("writeDaemonToken() made up the same token twice")
+	}
+}
+
+func TestDaemonHandler(t *testing.T) {
+	aiClient, err := ai.NewClient("test-model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &daemon{cmd: daemonCmd, aiClient: aiClient, token: "secret"}
+	listener, err := listenDaemon("127.0.0.1:0", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveDaemon(ctx, listener, d.handler()) }()
+	base := "http://" + listener.Addr().String()
+	send := func(method, path, contentType, body string, edit func(r *http.Request)) (int, jsonError) {
+		t.Helper()
+		req, err := http.NewRequest(method, base+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if edit != nil {
+			edit(req)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out jsonError
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/health", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health daemonHealth
+	_ = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || health.Model != "test-model" || health.Version != Version {
+		t.Errorf("GET /health = %d %+v", resp.StatusCode, health)
+	}
+
+	for _, body := range []string{`{"source": "stdin"}`, `{"source": "patch", "arg": "/etc/passwd"}`, `{"unknown": true}`, `not json`} {
+		if status, out := send(http.MethodPost, "/review", "application/json", body, nil); status != http.StatusBadRequest || out.Error.Code != CodeInvalidInput {
+			t.Errorf("POST /review %s = %d %+v, want 400 invalid_input", body, status, out)
+		}
+	}
+
+	// What a web page could send without the browser asking the daemon first
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		if status, out := send(http.MethodPost, "/review", contentType, `{}`, nil); status != http.StatusBadRequest || out.Error.Code != CodeInvalidInput {
+			t.Errorf("POST /review as %q = %d %+v, want 400 invalid_input", contentType, status, out)
+		}
+	}
+	if status, out := send(http.MethodPost, "/review", "application/json", `{}`, func(r *http.Request) { r.Host = "evil.example:8080" }); status != http.StatusBadRequest || out.Error.Code != CodeInvalidInput {
+		t.Errorf("POST /review for another host = %d %+v, want 400 invalid_input", status, out)
+	}
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		edit := func(r *http.Request) { r.Header.Set("Authorization", auth) }
+		for _, path := range []string{"/health", "/review"} {
+			method := http.MethodPost
+			if path == "/health" {
+				method = http.MethodGet
+			}
+			if status, out := send(method, path, "application/json", `{}`, edit); status != http.StatusUnauthorized || out.Error.Code != CodeUnauthorized {
+				t.Errorf("%s %s with Authorization %q = %d %+v, want 401 unauthorized", method, path, auth, status, out)
+			}
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serveDaemon() = %v after cancel, want nil", err)
+	}
+}
+
+func TestDaemonAllowedHost(t *testing.T) {
+	d := &daemon{host: "revi.internal"}
+	for _, host := range []string{"localhost", "localhost:8080", "127.0.0.1:8080", "[::1]:8080", "[::1]", "revi.internal:8080"} {
+		if !d.allowedHost(host) {
+			t.Errorf("allowedHost(%q) = false, want true", host)
+		}
+	}
+	for _, host := range []string{"", "evil.example", "localhost.evil.example", "10.0.0.5:8080"} {
+		if d.allowedHost(host) {
+			t.Errorf("allowedHost(%q) = true, want false", host)
+		}
+	}
+	if got := listenHost("0.0.0.0:8080"); got != "" {
+		t.Errorf("listenHost() of every interface = %q, want none", got)
+	}
+	if got := listenHost("unix:/tmp/revi.sock"); got != "" {
+		t.Errorf("listenHost() of a socket = %q, want none", got)
+	}
+}
+
+// brokenClient is a client whose queries fail as those on an exited CLI do
+type brokenClient struct {
+	claudecode.Client
+	disconnected bool
+}
+
+func (c *brokenClient) Query(ctx context.Context, prompt string) error {
+	return errors.New("transport not connected or stdin closed")
+}
+
+func (c *brokenClient) Disconnect() error {
+	c.disconnected = true
+	return nil
+}
+
+func TestDaemonConnected_ReconnectsBrokenClient(t *testing.T) {
+	var opened []*brokenClient
+	d := &daemon{connect: func() (claudecode.Client, error) {
+		opened = append(opened, &brokenClient{})
+		return opened[len(opened)-1], nil
+	}}
+
+	client, err := d.connected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := d.connected(); again != client || len(opened) != 1 {
+		t.Fatalf("connected() opened %d clients, want the first kept while it works", len(opened))
+	}
+
+	// A request ending does not break the client
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = client.Query(ctx, "review")
+	if again, _ := d.connected(); again != client {
+		t.Error("connected() replaced a client whose query failed because its request ended")
+	}
+
+	if err := client.Query(context.Background(), "review"); err == nil {
+		t.Fatal("Query() on a broken client succeeded")
+	}
+	replaced, err := d.connected()
+	if err != nil || replaced == client || len(opened) != 2 || !opened[0].disconnected {
+		t.Errorf("connected() = %v, %v after a failed query, want a new client and the broken one disconnected", replaced, err)
+	}
+
+	offline := &daemon{connect: func() (claudecode.Client, error) { return nil, nil }}
+	if client, err := offline.connected(); client != nil || err != nil {
+		t.Errorf("connected() offline = %v, %v, want no client", client, err)
+	}
+}
+
+func TestDaemonStatus(t *testing.T) {
+	tests := map[ErrorCode]int{
+		CodeInvalidInput: http.StatusBadRequest,
+		CodeLocked:       http.StatusConflict,
+		CodeUnauthorized: http.StatusUnauthorized,
+		CodeNoChanges:    http.StatusUnprocessableEntity,
+		CodeTimedOut:     http.StatusGatewayTimeout,
+		CodeInternal:     http.StatusInternalServerError,
+	}
+	for code, want := range tests {
+		if got := daemonStatus(code); got != want {
+			t.Errorf("daemonStatus(%s) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for linters
+// =============================================================================
+
+func TestRunLinters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("linters in this test use sh syntax")
+	}
+	staged := diff.NewDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n" +
+		"diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n")
+	cfg := &config.Config{Lint: config.LintConfig{Linters: []string{"echo 'main.go:3:1: unused x'; echo {files} >&2; exit 1"}, Timeout: time.Minute}}
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("output", "json", "")
+
+	aiClient := ai.NewClientWrapper("claude-sonnet-4-5")
+	runLinters(cmd, context.Background(), cfg, aiClient, t.TempDir(), source.Kind{Name: "range"}, staged)
+	if aiClient.HasLintIssues() {
+		t.Error("linters should not run on changes outside the working tree")
+	}
+
+	runLinters(cmd, context.Background(), cfg, aiClient, t.TempDir(), source.Kind{Name: source.Default}, staged)
+	if !aiClient.HasLintIssues() {
+		t.Fatal("linter findings on staged files should be given to the AI client")
+	}
+	modes := withLintMode(aiClient, []review.Mode{review.ModeSecurity})
+	if !slices.Equal(modes, []review.Mode{review.ModeSecurity, review.ModeLint}) {
+		t.Errorf("withLintMode() = %v, want the lint mode added", modes)
+	}
+	if got := withLintMode(aiClient, modes); len(got) != 2 {
+		t.Errorf("withLintMode() = %v, want the lint mode added once", got)
+	}
+	if got := withLintMode(ai.NewClientWrapper("claude-sonnet-4-5"), nil); len(got) != 0 {
+		t.Errorf("withLintMode() without findings = %v, want no modes", got)
+	}
+}
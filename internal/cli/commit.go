@@ -6,8 +6,14 @@ import (
 
 func init() {
 	// Share the flags with the commit subcommand
-	commitCmd.Flags().BoolP("dry-run", "n", false, "Preview commit message without committing")
+	commitCmd.Flags().BoolP("dry-run", "n", false, "Show the files, review modes, estimated cost and commit message without committing")
 	commitCmd.Flags().StringP("message", "m", "", "Context explaining why this change was made")
+	commitCmd.Flags().BoolP("yes", "y", false, "Commit without asking for confirmation")
+	commitCmd.Flags().Bool("no-verify", false, "Skip the pre-commit and commit-msg hooks")
+	commitCmd.Flags().Bool("fast", false, "Generate the message in a single AI call, truncating large diffs instead of summarizing them")
+	commitCmd.Flags().Bool("show-payload", false, "List what will be sent to the AI and ask before sending it")
+	addSourceFlags(commitCmd)
+	addPorcelainFlag(commitCmd)
 }
 
 var commitCmd = &cobra.Command{
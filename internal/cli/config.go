@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/fix"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +16,114 @@ var configCmd = &cobra.Command{
 	Long:  `View and manage revi configuration settings.`,
 }
 
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented config file with every setting",
+	Long: `Write .revi.yaml to the current directory, or ~/.revi.yaml with --global,
+listing every setting with its default value and a short description.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, _ := cmd.Flags().GetBool("global")
+		force, _ := cmd.Flags().GetBool("force")
+		path := config.ProjectConfigPath
+		if global {
+			path = config.GetDefaultConfigPath()
+		}
+
+		err := config.WriteSkeleton(path, force)
+		if errors.Is(err, config.ErrConfigExists) {
+			return withCode(CodeInvalidInput, fmt.Errorf("%w; pass --force to overwrite it", err))
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", path)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a value in the config file",
+	Long: `Set a single value in the config file in use, or in ./.revi.yaml if there
+is none. With --global the value is set in ~/.revi.yaml. The rest of the file,
+comments included, is kept.
+
+Keys are the dotted paths used in the config file, such as review.block or
+ai.model. Lists are comma-separated, and entries of maps are set one at a time:
+
+  revi config set review.cross_check.modes security,errors
+  revi config set review.severity_map.p0 high
+
+Available keys:
+  ` + strings.Join(config.Keys(), "\n  "),
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return config.Keys(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		global, _ := cmd.Flags().GetBool("global")
+		path := configSetPath(global)
+
+		err := config.SetValue(path, args[0], args[1])
+		if errors.Is(err, config.ErrUnknownKey) || errors.Is(err, config.ErrInvalidValue) {
+			return withCode(CodeInvalidInput, err)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Set %s to %s in %s\n", args[0], args[1], path)
+		return nil
+	},
+}
+
+// configSetPath returns the file revi config set writes to: ~/.revi.yaml if
+// global, otherwise the config file in use or ./.revi.yaml if there is none
+func configSetPath(global bool) string {
+	if global {
+		return config.GetDefaultConfigPath()
+	}
+	if path := config.GetConfigPath(); path != "" {
+		return path
+	}
+	return config.ProjectConfigPath
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the config file for unknown keys and invalid values",
+	Long: `Check the config file in use, or the given file, for keys revi does not
+know, such as a misspelled review.block, and for values it cannot use: model
+names that are not Claude models, severities other than high, medium and low,
+unknown review modes, and numbers and durations out of range.
+
+The other commands refuse to run with such a config file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.GetConfigPath()
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if path == "" {
+			fmt.Println("No config file found; the defaults are in use.")
+			return nil
+		}
+
+		problems, err := config.ValidateFile(path)
+		if err != nil {
+			return withCode(CodeInvalidInput, err)
+		}
+		if len(problems) > 0 {
+			return withCode(CodeInvalidInput, config.ProblemsError(path, problems))
+		}
+		fmt.Printf("%s is valid\n", path)
+		return nil
+	},
+}
+
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current configuration",
@@ -22,8 +133,56 @@ var configShowCmd = &cobra.Command{
 		fmt.Println("----------------------")
 		fmt.Printf("Review enabled:  %v\n", cfg.Review.Enabled)
 		fmt.Printf("Review block:    %v\n", cfg.Review.Block)
+		fmt.Printf("Sampling:        %v\n", cfg.Review.Sampling)
+		if len(cfg.Review.CriticalPaths) > 0 {
+			fmt.Printf("Critical paths:  %s\n", strings.Join(cfg.Review.CriticalPaths, ", "))
+		}
+		if len(cfg.Review.Include) > 0 {
+			fmt.Printf("Include:         %s\n", strings.Join(cfg.Review.Include, ", "))
+		}
+		if len(cfg.Review.Exclude) > 0 {
+			fmt.Printf("Exclude:         %s\n", strings.Join(cfg.Review.Exclude, ", "))
+		}
+		fmt.Printf("Max suggestions: %d\n", cfg.Review.MaxSuggestions)
+		if cfg.Review.MinConfidence > 0 {
+			fmt.Printf("Min confidence:  %v\n", cfg.Review.MinConfidence)
+		}
+		if cfg.Review.Concurrency > 0 {
+			fmt.Printf("Concurrency:     %d\n", cfg.Review.Concurrency)
+		}
+		if cfg.Review.Pacing > 0 {
+			fmt.Printf("Pacing:          %s\n", cfg.Review.Pacing)
+		}
+		if cfg.Review.TimeoutSeconds > 0 {
+			fmt.Printf("Mode timeout:    %s\n", modeTimeout(cfg))
+		}
+		if cfg.Review.Offline {
+			fmt.Println("Offline:         true (no AI)")
+		}
 		fmt.Printf("Commit enabled:  %v\n", cfg.Commit.Enabled)
+		fmt.Printf("Auto-confirm:    %v\n", cfg.Commit.AutoConfirm)
+		if len(cfg.Commit.Trailers) > 0 {
+			fmt.Printf("Trailers:        %s\n", strings.Join(cfg.Commit.Trailers, "; "))
+		}
+		if cfg.Commit.IssueTrailer != "" {
+			fmt.Printf("Issue trailer:   %s (from branch names matching %s)\n", cfg.Commit.IssueTrailer, cfg.Commit.IssuePattern)
+		}
+		fmt.Printf("Preview context: %d\n", cfg.Fix.PreviewContext)
+		if policy, err := fix.NewPolicy(cfg.Fix.AutoApply); err == nil && len(policy) > 0 {
+			fmt.Printf("Auto-apply:      %s\n", policy)
+		}
+		if len(cfg.Lint.Linters) > 0 {
+			fmt.Printf("Linters:         %s\n", strings.Join(cfg.Lint.Linters, ", "))
+		}
+		fmt.Printf("Issue links:     %s\n", cfg.Report.Links.Provider)
+		fmt.Printf("History:         %v\n", cfg.History.Enabled)
+		fmt.Printf("Forge:           %s\n", cfg.Forge.Provider)
 		fmt.Printf("AI model:        %s\n", cfg.AI.Model)
+		if cfg.AI.TranscriptDir != "" {
+			fmt.Printf("Transcripts:     %s\n", cfg.AI.TranscriptDir)
+		}
+		fmt.Printf("Redact secrets:  %v\n", cfg.AI.RedactSecrets)
+		fmt.Printf("Max diff chars:  %d\n", cfg.AI.MaxDiffChars)
 		fmt.Println("\nReview modes:")
 		fmt.Printf("  Security:      %v\n", cfg.Review.Modes.Security)
 		fmt.Printf("  Performance:   %v\n", cfg.Review.Modes.Performance)
@@ -31,6 +190,7 @@ var configShowCmd = &cobra.Command{
 		fmt.Printf("  Errors:        %v\n", cfg.Review.Modes.Errors)
 		fmt.Printf("  Testing:       %v\n", cfg.Review.Modes.Testing)
 		fmt.Printf("  Docs:          %v\n", cfg.Review.Modes.Docs)
+		fmt.Printf("  Secrets:       %v\n", cfg.Review.Modes.Secrets)
 		return nil
 	},
 }
@@ -42,8 +202,8 @@ var configPathCmd = &cobra.Command{
 		path := config.GetConfigPath()
 		if path == "" {
 			fmt.Println("No config file found. Create one at:")
-			fmt.Println("  ~/.revi.yaml (global)")
-			fmt.Println("  ./.revi.yaml (project)")
+			fmt.Println("  ~/.revi.yaml (global, revi config init --global)")
+			fmt.Println("  ./.revi.yaml (project, revi config init)")
 		} else {
 			fmt.Printf("Config file: %s\n", path)
 		}
@@ -52,6 +212,13 @@ var configPathCmd = &cobra.Command{
 }
 
 func init() {
+	configInitCmd.Flags().Bool("global", false, "Write ~/.revi.yaml instead of ./.revi.yaml")
+	configInitCmd.Flags().Bool("force", false, "Overwrite an existing config file")
+	configSetCmd.Flags().Bool("global", false, "Set the value in ~/.revi.yaml")
+
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
 }
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+)
+
+// reviewCost estimates the cost of reviewing diff in modes with aiClient,
+// including the second reviews of modes cross-checked with another model
+func reviewCost(cfg *config.Config, aiClient *ai.Client, diff *diff.Diff, modes []review.Mode) ai.CostEstimate {
+	est := aiClient.EstimateReviews(modes, diff)
+
+	model := cfg.Review.CrossCheck.Model
+	if model == "" || aiClient.Offline() {
+		return est
+	}
+	checked := make(map[review.Mode]bool)
+	for _, m := range cfg.Review.CrossCheck.Modes {
+		checked[review.Mode(strings.ToLower(strings.TrimSpace(m)))] = true
+	}
+	var crossChecked []review.Mode
+	for _, mode := range modes {
+		if checked[mode] {
+			crossChecked = append(crossChecked, mode)
+		}
+	}
+	secondary := ai.NewClientWrapper(model)
+	secondary.SetMaxSuggestions(cfg.Review.MaxSuggestions)
+	secondary.SetGuidance(aiClient.Guidance())
+	secondary.SetGoModule(aiClient.GoModule())
+	secondary.SetRedactSecrets(aiClient.RedactsSecrets())
+	secondary.SetMaxDiffSize(cfg.AI.MaxDiffChars)
+	return est.Add(secondary.EstimateReviews(crossChecked, diff))
+}
+
+// costNeedsConfirmation reports whether est is above review.confirm_cost.
+// Estimates without a known price never need confirmation.
+func costNeedsConfirmation(cfg *config.Config, est ai.CostEstimate) bool {
+	return cfg.Review.ConfirmCost > 0 && est.Priced && est.USD > cfg.Review.ConfirmCost
+}
+
+// costLimitError is returned when reviews estimated above review.confirm_cost
+// cannot be confirmed because nobody is there to ask
+func costLimitError(cfg *config.Config, est ai.CostEstimate) error {
+	return withCode(CodeCostLimit, fmt.Errorf(
+		"estimated review cost $%.2f is above review.confirm_cost ($%.2f); raise --confirm-cost or set it to 0 to review without confirmation",
+		est.USD, cfg.Review.ConfirmCost))
+}
+
+// confirmCost asks on out whether to start reviews estimated above
+// review.confirm_cost and reads the answer from in
+func confirmCost(in io.Reader, out io.Writer, cfg *config.Config) bool {
+	fmt.Fprintf(out, "The estimated cost is above $%.2f. Start the reviews? [y/N] ", cfg.Review.ConfirmCost)
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
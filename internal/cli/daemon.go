@@ -0,0 +1,604 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+	"github.com/spf13/cobra"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/commit"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/source"
+)
+
+// daemonSocket is the socket revi daemon listens on by default, relative to
+// the git directory
+const daemonSocket = "revi/daemon.sock"
+
+// daemonTokenFile is where revi daemon writes the token requests must carry,
+// relative to the git directory, when daemonTokenEnv sets none
+const daemonTokenFile = "revi/daemon.token"
+
+// daemonTokenEnv names the environment variable setting the token requests to
+// revi daemon must carry. Without it, the daemon makes up a token for each
+// run and writes it to daemonTokenFile.
+const daemonTokenEnv = "REVI_DAEMON_TOKEN"
+
+// daemonRefusedSources are the diff sources requests may not name: stdin is
+// the daemon's own rather than the client's, and patch and pr would read
+// files and merge requests of the client's choosing into the prompt
+var daemonRefusedSources = []string{"stdin", "patch", "pr"}
+
+// maxDaemonRequestSize bounds request bodies, which may carry a diff
+const maxDaemonRequestSize = 32 << 20
+
+func init() {
+	daemonCmd.Flags().String("listen", "", "Address to listen on: HOST:PORT, or unix:PATH for a socket (default unix:.git/"+daemonSocket+")")
+}
+
+// addDaemonReviewFlags shares mrReviewFlags with the review command, as the
+// settings of every review the daemon runs. It is called once the review
+// command's flags are defined.
+func addDaemonReviewFlags() {
+	for _, name := range mrReviewFlags {
+		daemonCmd.Flags().AddFlag(reviewCmd.Flags().Lookup(name))
+	}
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Serve reviews and commit messages over a local HTTP API",
+	Long: `Keep a Claude session open and serve reviews and commit messages of the
+current repository over HTTP, so editor plugins and scripts get results
+without starting revi and the Claude CLI for every request.
+
+The daemon listens on a unix socket in the git directory unless --listen
+names another socket or a TCP address. A TCP address must be a loopback one
+unless $REVI_DAEMON_TOKEN sets the token. Requests are handled one at a time,
+and the review flags given to the daemon apply to every review.
+
+Every request carries "Authorization: Bearer TOKEN", with the token from
+$REVI_DAEMON_TOKEN or, without it, the one the daemon writes to
+.git/revi/daemon.token when it starts. Requests must name localhost, a
+loopback address or the --listen host as their Host, and POST bodies must be
+application/json.
+
+  GET  /health          version and model
+  POST /review          {"source": "working-tree"} or {"diff": "..."}; the JSON
+                        report of revi review --output json
+  POST /commit-message  {"context": "why"}; a message for the staged changes
+
+"source" names a diff source such as staged (the default), working-tree or
+range, with "arg" giving its value, e.g. {"source": "range", "arg": "main..HEAD"}.
+The stdin, patch and pr sources are refused. Errors are answered with the JSON
+error of --output json.
+
+  curl --unix-socket .git/revi/daemon.sock -H "Authorization: Bearer $(cat .git/revi/daemon.token)" \
+    -H 'Content-Type: application/json' -d '{}' http://localhost/review`,
+	RunE: runDaemon,
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cfg := config.Get()
+
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	repo.SetContentFilter(contentFilter(cfg))
+	repo.SetSyntaxContext(cfg.Review.SyntaxContext)
+
+	aiClient, err := newReviewClient(cfg, cfg.AI.Model)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AI client: %w", err)
+	}
+	if err := setModeModels(cmd, aiClient, cfg); err != nil {
+		return err
+	}
+	if _, err := severityLabels(cfg); err != nil {
+		return err
+	}
+
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return err
+	}
+	addr, _ := cmd.Flags().GetString("listen")
+	if addr == "" {
+		addr = "unix:" + filepath.Join(gitDir, daemonSocket)
+	}
+	token := os.Getenv(daemonTokenEnv)
+	listener, err := listenDaemon(addr, token != "")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	tokenNote := "$" + daemonTokenEnv
+	if token == "" {
+		tokenPath := filepath.Join(gitDir, daemonTokenFile)
+		if token, err = writeDaemonToken(tokenPath); err != nil {
+			return err
+		}
+		defer os.Remove(tokenPath)
+		tokenNote = tokenPath
+	}
+
+	d := &daemon{
+		cmd:      cmd,
+		repo:     repo,
+		aiClient: aiClient,
+		token:    token,
+		host:     listenHost(addr),
+		connect:  func() (claudecode.Client, error) { return aiClient.Connect(ctx) },
+	}
+	if _, err := d.connected(); err != nil {
+		return err
+	}
+	defer d.disconnect()
+	fmt.Fprintf(os.Stderr, "revi daemon listening on %s, with the token in %s\n", addr, tokenNote)
+	err = serveDaemon(ctx, listener, d.handler())
+	// Stopping with Ctrl-C or SIGTERM is not a failure
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "revi daemon stopped")
+	return nil
+}
+
+// listenDaemon listens on addr: a unix socket for "unix:PATH", which only the
+// user can connect to, and a TCP address otherwise. A TCP address other than
+// a loopback one is refused unless tokenSet, since anyone who can reach it
+// could start reviews. A socket left behind by a daemon that did not stop
+// cleanly is replaced.
+func listenDaemon(addr string, tokenSet bool) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, fmt.Errorf("failed to listen on %s: %w", addr, err))
+		}
+		if tcp, ok := listener.Addr().(*net.TCPAddr); !tokenSet && (!ok || !tcp.IP.IsLoopback()) {
+			listener.Close()
+			return nil, withCode(CodeInvalidInput, fmt.Errorf("%s is not a loopback address; set %s to listen on it", addr, daemonTokenEnv))
+		}
+		return listener, nil
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, withCode(CodeLocked, fmt.Errorf("a daemon is already listening on %s", path))
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, withCode(CodeInvalidInput, fmt.Errorf("failed to listen on %s: %w", path, err))
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// listenHost returns the host of the TCP address addr, which requests may
+// name besides localhost; empty for a socket or an address of every interface
+func listenHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || strings.HasPrefix(addr, "unix:") {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		return ""
+	}
+	return host
+}
+
+// writeDaemonToken makes up a token and writes it to path, readable by the
+// user only
+func writeDaemonToken(path string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to make up a daemon token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	// A token file left behind is replaced rather than written through, so
+	// its permissions are the ones set here
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove stale token: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to write daemon token: %w", err)
+	}
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write daemon token: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write daemon token: %w", err)
+	}
+	return token, nil
+}
+
+// serveDaemon serves handler on listener until ctx is done, then lets the
+// requests in progress finish
+func serveDaemon(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	err := server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-stopped
+		return nil
+	}
+	return err
+}
+
+// daemon serves the requests of revi daemon on a connected client
+type daemon struct {
+	cmd      *cobra.Command
+	repo     *git.Repository
+	aiClient *ai.Client
+	token    string // Bearer token every request must carry
+	host     string // Host given to --listen, which requests may name besides localhost
+	// connect opens a client for requests to share; nil when the AI is offline
+	connect func() (claudecode.Client, error)
+	client  *watchedClient // Open client, nil until connected
+	mu      sync.Mutex     // Held while a request uses the client
+}
+
+// watchedClient is the daemon's client, noting when a query on it fails for
+// a reason other than its request ending: the Claude Code CLI exited or its
+// pipe broke, and every later call on the client would fail too
+type watchedClient struct {
+	claudecode.Client
+	broken atomic.Bool
+}
+
+func (c *watchedClient) Query(ctx context.Context, prompt string) error {
+	return c.watch(ctx, c.Client.Query(ctx, prompt))
+}
+
+func (c *watchedClient) QueryWithSession(ctx context.Context, prompt string, sessionID string) error {
+	return c.watch(ctx, c.Client.QueryWithSession(ctx, prompt, sessionID))
+}
+
+func (c *watchedClient) QueryStream(ctx context.Context, messages <-chan claudecode.StreamMessage) error {
+	return c.watch(ctx, c.Client.QueryStream(ctx, messages))
+}
+
+// watch notes err as a broken client unless ctx ending caused it
+func (c *watchedClient) watch(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() == nil {
+		c.broken.Store(true)
+	}
+	return err
+}
+
+// connected returns the client for a request, first replacing one a query
+// broke. It is nil when the AI is offline. Callers hold mu.
+func (d *daemon) connected() (claudecode.Client, error) {
+	if d.client != nil && !d.client.broken.Load() {
+		return d.client, nil
+	}
+	d.disconnect()
+	client, err := d.connect()
+	if err != nil || client == nil {
+		return nil, err
+	}
+	d.client = &watchedClient{Client: client}
+	return d.client, nil
+}
+
+// disconnect closes the open client, if any
+func (d *daemon) disconnect() {
+	if d.client != nil {
+		// The client may have broken already; there is nothing left to close
+		_ = d.client.Disconnect()
+		d.client = nil
+	}
+}
+
+// daemonReviewRequest is the body of POST /review
+type daemonReviewRequest struct {
+	Source string `json:"source,omitempty"` // Diff source such as working-tree; staged by default
+	Arg    string `json:"arg,omitempty"`    // Value of a source that takes one, such as a range
+	Diff   string `json:"diff,omitempty"`   // Unified diff to review instead of a source
+}
+
+// daemonCommitRequest is the body of POST /commit-message
+type daemonCommitRequest struct {
+	Context string `json:"context,omitempty"` // Why the changes were made
+}
+
+// daemonCommitResponse is the answer to POST /commit-message
+type daemonCommitResponse struct {
+	Message  string `json:"message"`
+	Fallback bool   `json:"fallback,omitempty"` // The AI failed and the message was generated without it
+}
+
+// daemonHealth is the answer to GET /health
+type daemonHealth struct {
+	Version string `json:"version"`
+	Model   string `json:"model"`
+}
+
+func (d *daemon) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeDaemonJSON(w, daemonHealth{Version: Version, Model: d.aiClient.Model()})
+	})
+	mux.HandleFunc("POST /review", func(w http.ResponseWriter, r *http.Request) {
+		var req daemonReviewRequest
+		if err := decodeDaemonRequest(w, r, &req); err != nil {
+			writeDaemonError(w, err)
+			return
+		}
+		d.serve(w, r, func(ctx context.Context) (any, error) { return d.review(ctx, req) })
+	})
+	mux.HandleFunc("POST /commit-message", func(w http.ResponseWriter, r *http.Request) {
+		var req daemonCommitRequest
+		if err := decodeDaemonRequest(w, r, &req); err != nil {
+			writeDaemonError(w, err)
+			return
+		}
+		d.serve(w, r, func(ctx context.Context) (any, error) { return d.commitMessage(ctx, req) })
+	})
+	return d.guard(mux)
+}
+
+// guard passes requests on to next only if they name a host the daemon
+// serves and carry its token, so neither a web page posting to localhost nor
+// one on a rebound DNS name can start reviews
+func (d *daemon) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !d.allowedHost(r.Host) {
+			writeDaemonError(w, withCode(CodeInvalidInput, fmt.Errorf("requests to the daemon must be for localhost, not %q", r.Host)))
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || d.token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(d.token)) != 1 {
+			writeDaemonError(w, withCode(CodeUnauthorized, errors.New("missing or wrong daemon token")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether host, the Host of a request, is localhost, a
+// loopback address or the host given to --listen
+func (d *daemon) allowedHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	return strings.EqualFold(host, "localhost") || (d.host != "" && strings.EqualFold(host, d.host))
+}
+
+// serve runs handle with the client to itself, within --timeout of the
+// request, and writes its answer
+func (d *daemon) serve(w http.ResponseWriter, r *http.Request, handle func(ctx context.Context) (any, error)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ctx := r.Context()
+	if timeout, _ := d.cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	answer, err := handle(ctx)
+	if err != nil {
+		writeDaemonError(w, err)
+		return
+	}
+	writeDaemonJSON(w, answer)
+}
+
+// review reviews the diff req asks for, as revi review --output json does
+func (d *daemon) review(ctx context.Context, req daemonReviewRequest) (*jsonReport, error) {
+	cfg := config.Get()
+	src, kind, err := daemonSource(d.repo, req)
+	if err != nil {
+		return nil, err
+	}
+	release, err := acquireRepoLock(d.cmd, d.repo)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	staged, err := sourceDiff(src)
+	if err != nil {
+		return nil, err
+	}
+	rec := newReviewRecord(d.repo, src.Describe(), staged)
+	if rec != nil && kind.Name == "commit" {
+		rec.entry.Commit, _ = d.repo.CommitHash(req.Arg)
+	}
+	defer rec.save()
+
+	staged, skipped := narrowDiff(d.cmd, cfg, staged)
+	if skipped != "" {
+		return &jsonReport{Results: []*review.Result{}}, nil
+	}
+	staged, sampling := sampleDiff(d.cmd, cfg, staged)
+	filter, err := issueFilter(d.cmd, d.repo, staged)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := d.connected()
+	if err != nil {
+		return nil, err
+	}
+	results, err := runReviews(d.cmd, ctx, d.aiClient, client, issueLinker(cfg, d.repo), newIssueBlamer(cfg, d.repo), filter, rec, staged)
+	if err != nil && timedOut(ctx) {
+		return nil, timeoutError(d.cmd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := authFailure(results); err != nil {
+		return nil, err
+	}
+
+	blocked := review.ShouldBlockAt(results, isBlockEnabled(d.cmd), blockThreshold())
+	reportReviewStatus(d.repo, results, blocked)
+	if results == nil {
+		results = []*review.Result{}
+	}
+	summary := review.Summarize(results)
+	return &jsonReport{Results: results, Summary: summary, Blocked: blocked, Partial: summary.TimedOutReviews > 0, Sampling: sampling}, nil
+}
+
+// daemonSource returns the diff source req names: its diff if it has one,
+// and otherwise the registered source of that name, the staged changes if it
+// names none. The sources in daemonRefusedSources are refused.
+func daemonSource(repo *git.Repository, req daemonReviewRequest) (source.Source, source.Kind, error) {
+	if req.Diff != "" {
+		if req.Source != "" {
+			return nil, source.Kind{}, withCode(CodeInvalidInput, errors.New("a review request takes a source or a diff, not both"))
+		}
+		return source.NewReader("diff from request", strings.NewReader(req.Diff)), source.Kind{Name: "diff"}, nil
+	}
+
+	name := req.Source
+	if name == "" {
+		name = source.Default
+	}
+	kind, ok := source.Lookup(name)
+	if !ok {
+		return nil, source.Kind{}, withCode(CodeInvalidInput, fmt.Errorf("unknown diff source %q", name))
+	}
+	if slices.Contains(daemonRefusedSources, name) {
+		return nil, source.Kind{}, withCode(CodeInvalidInput, fmt.Errorf("diff source %q is not available to daemon requests", name))
+	}
+	if kind.Arg != "" && req.Arg == "" {
+		return nil, source.Kind{}, withCode(CodeInvalidInput, fmt.Errorf("diff source %q requires an arg", name))
+	}
+	return kind.New(repo, req.Arg), kind, nil
+}
+
+// commitMessage generates a message for the staged changes. If the AI fails
+// and commit.fallback is set, a message is generated without it instead.
+func (d *daemon) commitMessage(ctx context.Context, req daemonCommitRequest) (*daemonCommitResponse, error) {
+	kind, _ := source.Lookup(source.Default)
+	staged, err := sourceDiff(kind.New(d.repo, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := d.connected()
+	if err != nil {
+		return nil, err
+	}
+	d.aiClient.SetSummaryModel(config.Get().Commit.SummaryModel)
+	msg, err := d.aiClient.GenerateCommitMessage(ctx, client, staged, req.Context)
+	if err == nil {
+		return &daemonCommitResponse{Message: addTrailers(d.repo, msg.String())}, nil
+	}
+	if timedOut(ctx) {
+		return nil, withCode(CodeTimedOut, fmt.Errorf("commit message generation timed out: %w", err))
+	}
+	if ctx.Err() != nil || !config.Get().Commit.Fallback {
+		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v\nUsing a commit message generated without AI.\n", err)
+	return &daemonCommitResponse{Message: addTrailers(d.repo, commit.FallbackMessage(staged, req.Context).String()), Fallback: true}, nil
+}
+
+// decodeDaemonRequest reads the JSON body of r into v. An empty body leaves v
+// as it is. Bodies of any other content type are refused, as a web page can
+// post those to the daemon without the browser asking it first.
+func decodeDaemonRequest(w http.ResponseWriter, r *http.Request, v any) error {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return withCode(CodeInvalidInput, errors.New("request body must be application/json"))
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDaemonRequestSize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return withCode(CodeInvalidInput, fmt.Errorf("invalid request body: %w", err))
+	}
+	return nil
+}
+
+// daemonStatus returns the HTTP status answering an error of code
+func daemonStatus(code ErrorCode) int {
+	switch code {
+	case CodeInvalidInput:
+		return http.StatusBadRequest
+	case CodeAuthRequired, CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeLocked:
+		return http.StatusConflict
+	case CodeNoChanges, CodeCostLimit, CodeNotAGitRepo:
+		return http.StatusUnprocessableEntity
+	case CodeTimedOut:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeDaemonError answers with err as the JSON error of --output json
+func writeDaemonError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(daemonStatus(errorCode(err)))
+	writeJSONError(w, err)
+}
+
+// writeDaemonJSON answers with v as JSON
+func writeDaemonJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// The client may have gone; there is no one left to tell
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/buker/revi/internal/fix"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/telemetry"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func init() {
+	fixCmd.AddCommand(fixListCmd)
+	fixCmd.AddCommand(fixUndoCmd)
+}
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Manage fixes applied by revi review --fix",
+	Long: `Manage the fixes applied during the last "revi review --fix" session or
+TUI review. Each applied fix is recorded with the file content it replaced in
+the repository's git directory, so it can be undone without going through git.`,
+}
+
+var fixListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the fixes that can be undone",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := git.OpenCurrent()
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		journal, err := loadFixJournal(repo)
+		if err != nil {
+			return err
+		}
+
+		entries := journal.Entries()
+		if isJSONOutput(cmd) {
+			if entries == nil {
+				entries = []fix.JournalEntry{}
+			}
+			return writeJSON(os.Stdout, entries)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No applied fixes to undo.")
+			return nil
+		}
+		root, _ := repo.Root()
+		return writeFixList(os.Stdout, root, entries)
+	},
+}
+
+var fixUndoCmd = &cobra.Command{
+	Use:   "undo [id]",
+	Short: "Restore the file changed by an applied fix",
+	Long: `Restore the file changed by an applied fix to its content before the fix.
+Without an ID, the most recently applied fix is undone. Fixes to the same file
+are undone in reverse order, and a file edited since the fix is left alone.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := git.OpenCurrent()
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		journal, err := loadFixJournal(repo)
+		if err != nil {
+			return err
+		}
+
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		entry, err := journal.Undo(id)
+		if errors.Is(err, fix.ErrFixNotFound) && id == "" {
+			return withCode(CodeInvalidInput, fmt.Errorf("no applied fixes to undo"))
+		}
+		if err != nil {
+			return withCode(CodeInvalidInput, err)
+		}
+		if err := saveFixJournal(repo, journal); err != nil {
+			return err
+		}
+
+		root, _ := repo.Root()
+		fmt.Printf("Undid fix %s in %s\n", entry.ID, relativePath(root, entry.Path))
+		return nil
+	},
+}
+
+// fixJournalPath returns the path of the fix journal in repo's git directory
+func fixJournalPath(repo *git.Repository) (string, error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	return filepath.Join(gitDir, fix.JournalFile), nil
+}
+
+// loadFixJournal reads the journal of the last fix session in repo
+func loadFixJournal(repo *git.Repository) (*fix.Journal, error) {
+	path, err := fixJournalPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	return fix.LoadJournal(path)
+}
+
+// saveFixJournal stores journal in repo's git directory for "revi fix undo"
+func saveFixJournal(repo *git.Repository, journal *fix.Journal) error {
+	path, err := fixJournalPath(repo)
+	if err != nil {
+		return err
+	}
+	return journal.Save(path)
+}
+
+// keepFixJournal saves the fixes applied during a review session, replacing
+// the previous session's, so they can be undone after revi exits. Sessions
+// that applied no fixes leave the previous journal in place.
+func keepFixJournal(repo *git.Repository, journal *fix.Journal) {
+	if journal.Len() == 0 {
+		return
+	}
+	if err := saveFixJournal(repo, journal); err != nil {
+		fmt.Fprintf(os.Stderr, "Applied fixes cannot be undone with revi fix undo: %v\n", err)
+	}
+}
+
+// fixUndoer returns a function that undoes the most recent journaled
+// application of a fix
+func fixUndoer(applier *fix.Applier, journal *fix.Journal) func(*review.Fix) error {
+	return func(f *review.Fix) error {
+		id, ok := journal.Lookup(f)
+		if !ok {
+			return fix.ErrFixNotFound
+		}
+		_, err := applier.Undo(id)
+		return err
+	}
+}
+
+// tracedFixApplier returns apply recording each application of a fix as a
+// span under ctx
+func tracedFixApplier(ctx context.Context, apply func(*review.Fix) error) func(*review.Fix) error {
+	return func(f *review.Fix) error {
+		_, span := telemetry.Start(ctx, "revi.fix",
+			attribute.String("revi.file", f.FilePath),
+			attribute.Int("revi.start_line", f.StartLine),
+			attribute.Int("revi.end_line", f.EndLine),
+		)
+		err := apply(f)
+		span.SetAttributes(attribute.Bool("revi.rejected", errors.Is(err, fix.ErrFixRejected)))
+		telemetry.End(span, err)
+		return err
+	}
+}
+
+// writeFixList writes one line per undoable fix, most recent first
+func writeFixList(w io.Writer, root string, entries []fix.JournalEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tFILE\tLINES\tEXPLANATION")
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		lines, explanation := "-", ""
+		if e.Fix != nil {
+			lines = fmt.Sprintf("%d-%d", e.Fix.StartLine, e.Fix.EndLine)
+			explanation, _, _ = strings.Cut(e.Fix.Explanation, "\n")
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.ID, relativePath(root, e.Path), lines, explanation)
+	}
+	return tw.Flush()
+}
+
+// relativePath returns path relative to root, or path itself if it is not below root
+func relativePath(root, path string) string {
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/history"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/source"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	historyListCmd.Flags().IntP("limit", "n", 20, "Number of most recent reviews to list (0 lists all)")
+	historyPruneCmd.Flags().Int("max-entries", 0, "Reviews to keep, instead of history.max_entries")
+	historyPruneCmd.Flags().Duration("max-age", 0, "Drop reviews older than this, instead of history.max_age")
+	historyPruneCmd.Flags().Int("max-size-mb", 0, "Size to keep the history under, instead of history.max_size_mb")
+
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse past reviews",
+	Long: `Browse the reviews recorded in the repository's git directory.
+
+Every "revi review" run is recorded with the modes it ran, its findings and
+the fixes applied. Reviews of staged changes are linked to the commit revi
+creates from them, and --commit reviews to the reviewed commit.`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent reviews",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openHistory()
+		if err != nil {
+			return err
+		}
+		entries, err := store.List()
+		if err != nil {
+			return err
+		}
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+
+		if isJSONOutput(cmd) {
+			if entries == nil {
+				entries = []history.Entry{}
+			}
+			return writeJSON(os.Stdout, entries)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No reviews recorded yet.")
+			return nil
+		}
+		return writeHistoryList(os.Stdout, entries)
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the findings of a past review",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openHistory()
+		if err != nil {
+			return err
+		}
+		entry, err := store.Get(args[0])
+		if err != nil {
+			return withCode(CodeInvalidInput, err)
+		}
+
+		if isJSONOutput(cmd) {
+			return writeJSON(os.Stdout, entry)
+		}
+		if isCSVOutput(cmd) {
+			return writeCSVReport(os.Stdout, entry.Results)
+		}
+		printHistoryEntry(entry)
+		return nil
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop old reviews from the history",
+	Long: `Drop the reviews that the history.max_entries, history.max_age and
+history.max_size_mb limits no longer allow, oldest first. The limits are also
+applied whenever a review is recorded; prune applies them right away, e.g.
+after lowering them. Flags override the configured limits for this run.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openHistory()
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("max-entries") {
+			n, _ := cmd.Flags().GetInt("max-entries")
+			store.SetMaxEntries(n)
+		}
+		if cmd.Flags().Changed("max-age") {
+			d, _ := cmd.Flags().GetDuration("max-age")
+			store.SetMaxAge(d)
+		}
+		if cmd.Flags().Changed("max-size-mb") {
+			mb, _ := cmd.Flags().GetInt("max-size-mb")
+			store.SetMaxSize(int64(mb) << 20)
+		}
+
+		pruned, err := store.Prune()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Pruned %d review(s) from the history.\n", pruned)
+		return nil
+	},
+}
+
+// openHistory returns the history store of the repository in the current directory
+func openHistory() (*history.Store, error) {
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	return historyStore(repo)
+}
+
+// historyStore returns the history store in repo's git directory, keeping the
+// runs that history.max_entries, history.max_age and history.max_size_mb allow
+func historyStore(repo *git.Repository) (*history.Store, error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	store := history.Open(gitDir)
+	cfg := config.Get().History
+	store.SetMaxEntries(cfg.MaxEntries)
+	store.SetMaxAge(cfg.MaxAge)
+	store.SetMaxSize(int64(cfg.MaxSizeMB) << 20)
+	return store, nil
+}
+
+// writeJSON writes v to w as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+	return nil
+}
+
+// writeHistoryList writes one line per entry, newest first
+func writeHistoryList(w io.Writer, entries []history.Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tDATE\tSOURCE\tMODES\tISSUES\tFIXES\tCOMMIT")
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		modes := make([]string, len(e.Modes))
+		for j, m := range e.Modes {
+			modes[j] = string(m)
+		}
+		commit := shortHash(e.Commit)
+		if commit == "" {
+			commit = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			e.ID, e.Time.Local().Format("2006-01-02 15:04"), e.Source,
+			strings.Join(modes, ","), e.Issues(), e.FixesApplied, commit)
+	}
+	return tw.Flush()
+}
+
+// printHistoryEntry prints a recorded review in the format of a text-mode review
+func printHistoryEntry(e history.Entry) {
+	fmt.Printf("Review %s\n", e.ID)
+	fmt.Printf("Date:    %s\n", e.Time.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("Source:  %s\n", e.Source)
+	if e.Commit != "" {
+		fmt.Printf("Commit:  %s\n", e.Commit)
+	}
+	if e.FixesApplied > 0 {
+		fmt.Printf("Fixes:   %d applied\n", e.FixesApplied)
+	}
+	for _, r := range e.Results {
+		if r != nil {
+			printReviewResult(r)
+		}
+	}
+}
+
+// reviewRecord collects the results of a review run for the history. A nil
+// *reviewRecord records nothing.
+type reviewRecord struct {
+	store *history.Store
+	mu    sync.Mutex
+	entry history.Entry
+}
+
+// startReviewRecord begins recording a review of diff from src, or returns nil
+// if history.enabled is off or the history cannot be opened
+func startReviewRecord(cmd *cobra.Command, repo *git.Repository, src source.Source, kind source.Kind, diff *diff.Diff) *reviewRecord {
+	rec := newReviewRecord(repo, src.Describe(), diff)
+	if rec != nil && kind.Name == "commit" {
+		ref, _ := cmd.Flags().GetString(kind.Name)
+		rec.entry.Commit, _ = repo.CommitHash(ref)
+	}
+	return rec
+}
+
+// newReviewRecord begins recording a review of diff described by source, or
+// returns nil if history.enabled is off or the history cannot be opened
+func newReviewRecord(repo *git.Repository, source string, diff *diff.Diff) *reviewRecord {
+	if !config.Get().History.Enabled {
+		return nil
+	}
+	store, err := historyStore(repo)
+	if err != nil {
+		debugLog("review history disabled: %v", err)
+		return nil
+	}
+
+	// Drop runs that aged out since the last one was recorded
+	if _, err := store.Prune(); err != nil {
+		debugLog("failed to prune review history: %v", err)
+	}
+
+	rec := &reviewRecord{store: store}
+	rec.entry.Source = source
+	rec.entry.DiffHash = history.HashDiff(diff.String())
+	return rec
+}
+
+// wrap records the result of every review made through run
+func (r *reviewRecord) wrap(run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if r == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		if result != nil {
+			r.mu.Lock()
+			r.entry.Modes = append(r.entry.Modes, mode)
+			r.entry.Results = append(r.entry.Results, result)
+			r.mu.Unlock()
+		}
+		return result, err
+	}
+}
+
+// setFixesApplied records how many fixes were applied after the review
+func (r *reviewRecord) setFixesApplied(n int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.entry.FixesApplied = n
+	r.mu.Unlock()
+}
+
+// save adds the run to the history if any review finished. Failures are only
+// logged, since the review itself succeeded.
+func (r *reviewRecord) save() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entry.Results) == 0 {
+		return
+	}
+	if err := r.store.Add(&r.entry); err != nil {
+		debugLog("failed to record review history: %v", err)
+	}
+}
+
+// linkReviewedCommit records commit on the latest review of diff, so the
+// history shows which commit the reviewed changes went into
+func linkReviewedCommit(repo *git.Repository, diff *diff.Diff, commit string) {
+	if !config.Get().History.Enabled {
+		return
+	}
+	store, err := historyStore(repo)
+	if err != nil {
+		debugLog("review history disabled: %v", err)
+		return
+	}
+	if _, err := store.LinkCommit(history.HashDiff(diff.String()), commit); err != nil {
+		debugLog("failed to link commit to review history: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/hook"
+	"github.com/buker/revi/internal/source"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	hookInstallCmd.Flags().Bool("force", false, "Back up and replace existing hooks not installed by revi")
+
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+}
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage git hooks that run revi",
+	Long: `Install git hooks so that "git commit" runs revi.
+
+The pre-commit hook reviews the staged changes and aborts the commit when
+high-severity issues are found. The prepare-commit-msg hook fills in a
+generated commit message when none was given with -m, -F or a template.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit and prepare-commit-msg hooks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		written, err := hook.Install(dir, force)
+		if errors.Is(err, hook.ErrForeignHook) {
+			return withCode(CodeInvalidInput, err)
+		}
+		if err != nil {
+			return err
+		}
+		for _, path := range written {
+			fmt.Printf("Installed %s\n", path)
+		}
+		return nil
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks installed by revi",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		removed, err := hook.Uninstall(dir)
+		for _, path := range removed {
+			fmt.Printf("Removed %s\n", path)
+		}
+		if err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			fmt.Println("No revi hooks installed.")
+		}
+		return nil
+	},
+}
+
+var hookRunCmd = &cobra.Command{
+	Use:   "run <hook> [args...]",
+	Short: "Run a hook (called by the installed hook scripts)",
+	Long: `Run the named hook non-interactively with the arguments git passed to it.
+
+Only high-severity issues found by the pre-commit hook abort the commit; if
+revi itself fails (for example, Claude is unreachable), a warning is printed
+and the commit goes ahead.`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: hook.Names(),
+	RunE:      runHook,
+}
+
+// hooksDir returns the hooks directory of the repository in the current directory
+func hooksDir() (string, error) {
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+	return repo.HooksDir()
+}
+
+func runHook(cmd *cobra.Command, args []string) error {
+	var err error
+	switch args[0] {
+	case hook.PreCommit:
+		err = runPreCommitHook()
+	case hook.PrepareCommitMsg:
+		err = runPrepareCommitMsgHook(args[1:])
+	default:
+		return withCode(CodeInvalidInput, fmt.Errorf("unknown hook %q, expected one of %v", args[0], hook.Names()))
+	}
+
+	switch errorCode(err) {
+	case CodeBlocked:
+		return err
+	case CodeNoChanges:
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "revi: %s hook skipped: %v\n", args[0], err)
+	}
+	return nil
+}
+
+// runPreCommitHook reviews the staged changes with plain text output, unless
+// commit.fast leaves reviews for later
+func runPreCommitHook() error {
+	if config.Get().Commit.Fast {
+		fmt.Fprintln(os.Stderr, `revi: commit.fast is set, skipping the review; run "revi review" before pushing`)
+		return nil
+	}
+	if err := reviewCmd.Flags().Set("no-tui", "true"); err != nil {
+		return err
+	}
+	return runReview(reviewCmd, nil)
+}
+
+// runPrepareCommitMsgHook writes a generated commit message into the message
+// file git passes as the first argument. Commits whose message already comes
+// from somewhere (-m, -F, a template, a merge, --amend) are left alone.
+func runPrepareCommitMsgHook(args []string) error {
+	if len(args) == 0 {
+		return withCode(CodeInvalidInput, fmt.Errorf("prepare-commit-msg requires the commit message file"))
+	}
+	msgFile := args[0]
+	if len(args) > 1 && args[1] != "" {
+		debugLog("Commit message source is %q, not generating a message", args[1])
+		return nil
+	}
+
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	repo.SetContentFilter(contentFilter(config.Get()))
+	kind, _ := source.Lookup(source.Default)
+	diff, err := sourceDiff(kind.New(repo, ""))
+	if err != nil {
+		return err
+	}
+
+	guidance, err := promptGuidance(config.Get())
+	if err != nil {
+		return err
+	}
+	aiClient, err := ai.NewClient(config.Get().AI.Model)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AI client: %w", err)
+	}
+	aiClient.SetGuidance(guidance)
+	aiClient.SetRetryPolicy(retryPolicy(config.Get()))
+	aiClient.SetRedactSecrets(config.Get().AI.RedactSecrets)
+	aiClient.SetMaxDiffSize(config.Get().AI.MaxDiffChars)
+	setTranscript(aiClient)
+	message, err := generateCommitMessage(context.Background(), aiClient, diff, "", config.Get().Commit.Fast)
+	if err != nil {
+		return err
+	}
+	message = addTrailers(repo, message)
+
+	existing, err := os.ReadFile(msgFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read commit message file: %w", err)
+	}
+	if err := os.WriteFile(msgFile, []byte(hook.PrepareMessage(string(existing), message)), 0644); err != nil {
+		return fmt.Errorf("failed to write commit message file: %w", err)
+	}
+	return nil
+}
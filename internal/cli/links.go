@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/permalink"
+	"github.com/buker/revi/internal/review"
+)
+
+// issueLinker returns a builder for permalinks to the current commit on the
+// repository host configured in report.links, or nil if links are disabled or
+// the host cannot be determined
+func issueLinker(cfg *config.Config, repo *git.Repository) *permalink.Builder {
+	return issueLinkerAt(cfg, repo, "")
+}
+
+// issueLinkerAt is issueLinker for links to commit, or to HEAD if commit is empty
+func issueLinkerAt(cfg *config.Config, repo *git.Repository, commit string) *permalink.Builder {
+	links := cfg.Report.Links
+	if links.Provider == "none" {
+		return nil
+	}
+
+	repoURL := links.RepoURL
+	if repoURL == "" {
+		remote, err := repo.RemoteURL("origin")
+		if err != nil {
+			debugLog("No issue links: %v", err)
+			return nil
+		}
+		repoURL = permalink.RepoURL(remote)
+	}
+
+	template := links.Template
+	if template == "" {
+		provider := links.Provider
+		if provider == "" || provider == "auto" {
+			provider = permalink.DetectProvider(repoURL)
+		}
+		template = permalink.Templates[provider]
+	}
+	if repoURL == "" || template == "" {
+		debugLog("No issue links: unknown repository host %q", repoURL)
+		return nil
+	}
+
+	if commit == "" {
+		var err error
+		if commit, err = repo.HeadCommit(); err != nil {
+			debugLog("No issue links: %v", err)
+			return nil
+		}
+	}
+	return permalink.New(template, repoURL, commit)
+}
+
+// withIssueLinks wraps run so that the issues it returns link to their location.
+// run is returned unchanged if linker is nil.
+func withIssueLinks(linker *permalink.Builder, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if linker == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		if result != nil {
+			for i := range result.Issues {
+				linkIssue(linker, &result.Issues[i])
+			}
+		}
+		return result, err
+	}
+}
+
+// linkIssue sets the URL of issue to its location, if it has one
+func linkIssue(linker *permalink.Builder, issue *review.Issue) {
+	if path, line := issue.FileLine(); path != "" {
+		issue.URL = linker.Link(path, line)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/lint"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/source"
+)
+
+// lintKinds are the sources whose changes are in the working tree, where the
+// linters can check them
+var lintKinds = map[string]bool{
+	source.Default:     true,
+	"amend":            true,
+	"working-tree":     true,
+	"snapshot":         true,
+	"merge-resolution": true,
+}
+
+// runLinters runs lint.linters in root on the files the staged diff adds or
+// changes and gives what they report to aiClient. Linters that fail are
+// warned about and do not stop the review.
+func runLinters(cmd *cobra.Command, ctx context.Context, cfg *config.Config, aiClient *ai.Client, root string, kind source.Kind, staged *diff.Diff) {
+	if len(cfg.Lint.Linters) == 0 || root == "" || !lintKinds[kind.Name] {
+		return
+	}
+	var files []string
+	for _, f := range staged.Files {
+		if f.Status() != diff.FileDeleted && !f.IsBinary() {
+			files = append(files, f.Path)
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	debugLog("Running linters %v on %d file(s)", cfg.Lint.Linters, len(files))
+	findings, errs := lint.Run(ctx, root, cfg.Lint.Linters, files, cfg.Lint.Timeout)
+	if !isJSONOutput(cmd) {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	aiClient.SetLintIssues(lint.Issues(findings))
+}
+
+// withLintMode returns modes with the lint mode added when the linters
+// reported something, so their findings are listed with the reviews
+func withLintMode(aiClient *ai.Client, modes []review.Mode) []review.Mode {
+	if !aiClient.HasLintIssues() || slices.Contains(modes, review.ModeLint) {
+		return modes
+	}
+	return append(modes, review.ModeLint)
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/forge"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/permalink"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// mrReviewFlags are the review flags that also apply to merge request reviews
+var mrReviewFlags = []string{
+	"all", "block", "no-block", "ignore-whitespace", "path", "cross-check-model",
+	"promote-suggestions", "no-ignore", "sampling", "concurrency", "confirm-cost",
+	"security", "no-security", "performance", "no-performance", "style", "no-style",
+	"errors", "no-errors", "testing", "no-testing", "docs", "no-docs",
+	"secrets", "no-secrets",
+}
+
+func init() {
+	mrReviewCmd.Flags().Bool("no-comment", false, "Print the findings without commenting on the merge request")
+
+	mrCmd.AddCommand(mrReviewCmd)
+}
+
+// addMRReviewFlags shares mrReviewFlags with the review command. It is called
+// once the review command's flags are defined.
+func addMRReviewFlags() {
+	for _, name := range mrReviewFlags {
+		mrReviewCmd.Flags().AddFlag(reviewCmd.Flags().Lookup(name))
+	}
+}
+
+var mrCmd = &cobra.Command{
+	Use:   "mr",
+	Short: "Review merge requests on GitLab",
+	Long: `Review merge requests on the GitLab instance hosting the origin remote,
+including self-hosted instances.
+
+The access token is read from ` + forge.GitLabTokenEnv + ` and needs the api scope.
+Set forge.provider to gitlab when the host name does not contain "gitlab",
+and forge.url when GitLab is served below a path.`,
+}
+
+var mrReviewCmd = &cobra.Command{
+	Use:   "review <number>",
+	Short: "Review a merge request and comment on it",
+	Long: `Fetch the changes of a merge request, review them, and post the findings
+as comments: issues on lines of the diff are attached to those lines, and a
+summary note lists the rest. Use --no-comment to only print the findings.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMRReview,
+}
+
+func runMRReview(cmd *cobra.Command, args []string) error {
+	ctx, cancel := workflowContext(cmd)
+	defer cancel()
+	cfg := config.Get()
+
+	number, err := strconv.Atoi(strings.TrimPrefix(args[0], "!"))
+	if err != nil || number <= 0 {
+		return withCode(CodeInvalidInput, fmt.Errorf("invalid merge request number %q", args[0]))
+	}
+
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	host, err := openForge(cfg, repo)
+	if err != nil {
+		return withCode(CodeInvalidInput, err)
+	}
+
+	mr, err := host.MergeRequest(ctx, number)
+	if err != nil {
+		return err
+	}
+	text, err := host.Diff(ctx, mr)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(text) == "" {
+		return withCode(CodeNoChanges, fmt.Errorf("merge request !%d has no changes", number))
+	}
+	diff := diff.NewDiff(text)
+
+	rec := newReviewRecord(repo, fmt.Sprintf("merge request !%d", number), diff)
+	if rec != nil {
+		rec.entry.Commit = mr.HeadSHA
+	}
+	defer rec.save()
+
+	diff, skipped := narrowDiff(cmd, cfg, diff)
+	if skipped != "" {
+		if isJSONOutput(cmd) {
+			return writeJSONReport(os.Stdout, nil, false, nil)
+		}
+		fmt.Println(skipped)
+		return nil
+	}
+	diff, sampling := sampleDiff(cmd, cfg, diff)
+
+	filter, err := issueFilter(cmd, repo, diff)
+	if err != nil {
+		return err
+	}
+	aiClient, err := newReviewClient(cfg, cfg.AI.Model)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AI client: %w", err)
+	}
+	if err := setModeModels(cmd, aiClient, cfg); err != nil {
+		return err
+	}
+
+	if !isJSONOutput(cmd) {
+		fmt.Printf("Reviewing !%d: %s\n", mr.Number, mr.Title)
+	}
+	// Blame reflects the local checkout, not the merge request's commits
+	results, err := collectReviews(cmd, ctx, aiClient, issueLinkerAt(cfg, repo, mr.HeadSHA), nil, filter, rec, diff)
+	if err != nil {
+		return err
+	}
+
+	blocked := review.ShouldBlockAt(results, isBlockEnabled(cmd), blockThreshold())
+	if isJSONOutput(cmd) {
+		if err := writeJSONReport(os.Stdout, results, blocked, sampling); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			if r != nil {
+				printReviewResult(r)
+			}
+		}
+	}
+
+	// A partial review is not posted, since it would read as a complete one
+	partial := review.Summarize(results).TimedOutReviews > 0
+	if noComment, _ := cmd.Flags().GetBool("no-comment"); !noComment && !partial {
+		posted, err := postReviewComments(ctx, host, mr, results)
+		if err != nil {
+			return err
+		}
+		if !isJSONOutput(cmd) {
+			fmt.Printf("\nPosted %d comment(s) on %s\n", posted, mr.URL)
+		}
+	}
+
+	if blocked {
+		return blockedError(results)
+	}
+	if partial {
+		return timeoutError(cmd)
+	}
+	return nil
+}
+
+// openForge returns the forge hosting the origin remote, as configured in forge
+func openForge(cfg *config.Config, repo *git.Repository) (forge.Forge, error) {
+	remote, err := repo.RemoteURL("origin")
+	if err != nil {
+		return nil, err
+	}
+	repoURL := permalink.RepoURL(remote)
+	if repoURL == "" {
+		return nil, fmt.Errorf("cannot determine the web URL of remote %q", remote)
+	}
+	return forge.New(forge.Options{
+		Provider: cfg.Forge.Provider,
+		RepoURL:  repoURL,
+		BaseURL:  cfg.Forge.URL,
+		Token:    os.Getenv(forge.GitLabTokenEnv),
+	})
+}
+
+// postReviewComments comments on each issue at its line and then posts a summary
+// note, which also lists the issues that could not be attached to a line.
+// Returns the number of comments posted.
+func postReviewComments(ctx context.Context, host forge.Forge, mr *forge.MergeRequest, results []*review.Result) (int, error) {
+	posted := 0
+	var unplaced []string
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			path, line := issue.FileLine()
+			if path != "" && line > 0 {
+				err := host.Comment(ctx, mr, forge.Comment{Path: path, Line: line, Body: issueCommentBody(r.Mode, issue)})
+				if err == nil {
+					posted++
+					continue
+				}
+				if !errors.Is(err, forge.ErrLineNotInDiff) {
+					return posted, err
+				}
+				debugLog("Commenting in the summary instead: %v", err)
+			}
+			unplaced = append(unplaced, issueLine(r.Mode, issue))
+		}
+	}
+
+	if err := host.Comment(ctx, mr, forge.Comment{Body: reviewSummaryBody(results, unplaced)}); err != nil {
+		return posted, err
+	}
+	return posted + 1, nil
+}
+
+// issueCommentBody formats an issue as a Markdown comment on its line
+func issueCommentBody(mode review.Mode, issue review.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**[%s] %s:** %s", strings.ToUpper(issue.Severity), review.GetModeInfo(mode).Name, issue.Description)
+	if fix := issue.Fix; fix != nil && fix.Available && fix.Code != "" {
+		b.WriteString("\n\nSuggested fix")
+		if fix.Explanation != "" {
+			fmt.Fprintf(&b, " (%s)", fix.Explanation)
+		}
+		fmt.Fprintf(&b, ":\n\n```\n%s\n```", strings.TrimRight(fix.Code, "\n"))
+	}
+	return b.String()
+}
+
+// issueLine formats an issue as a Markdown list item for the summary note
+func issueLine(mode review.Mode, issue review.Issue) string {
+	line := fmt.Sprintf("- **[%s] %s:** %s", strings.ToUpper(issue.Severity), review.GetModeInfo(mode).Name, issue.Description)
+	if issue.Location != "" {
+		line += fmt.Sprintf(" (`%s`)", issue.Location)
+	}
+	return line
+}
+
+// reviewSummaryBody formats the summary note of a merge request review
+func reviewSummaryBody(results []*review.Result, unplaced []string) string {
+	summary := review.Summarize(results)
+
+	var b strings.Builder
+	b.WriteString("### revi review\n\n")
+	if summary.IssuesFound == 0 {
+		b.WriteString("No issues found.\n")
+	} else {
+		fmt.Fprintf(&b, "%d issue(s) found: %d high, %d medium, %d low.\n",
+			summary.IssuesFound, summary.HighSeverity, summary.MediumSeverity, summary.LowSeverity)
+	}
+
+	b.WriteString("\n")
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		name := review.GetModeInfo(r.Mode).Name
+		switch {
+		case r.Status == review.StatusFailed:
+			fmt.Fprintf(&b, "- **%s:** review failed (%s)\n", name, r.Error)
+		case r.Summary != "":
+			fmt.Fprintf(&b, "- **%s:** %s\n", name, r.Summary)
+		default:
+			fmt.Fprintf(&b, "- **%s:** %d issue(s)\n", name, len(r.Issues))
+		}
+	}
+
+	if len(unplaced) > 0 {
+		b.WriteString("\nIssues outside the diff:\n\n")
+		b.WriteString(strings.Join(unplaced, "\n"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
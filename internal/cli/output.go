@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/lock"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+)
+
+// ErrorCode identifies the kind of failure in structured error output, so
+// wrappers can branch on it instead of parsing messages.
+type ErrorCode string
+
+const (
+	CodeNoChanges    ErrorCode = "no_changes"     // Nothing to review or commit
+	CodeAuthRequired ErrorCode = "auth_required"  // Claude CLI is not logged in or the login expired
+	CodeBlocked      ErrorCode = "blocked"        // High-severity issues blocked the run
+	CodeLocked       ErrorCode = "locked"         // Another revi run holds the repository lock
+	CodeNotAGitRepo  ErrorCode = "not_a_git_repo" // The working directory is not a git repository
+	CodeInvalidInput ErrorCode = "invalid_input"  // Flags or arguments were invalid
+	CodeTimedOut     ErrorCode = "timed_out"      // The --timeout budget ran out; any results are partial
+	CodeCostLimit    ErrorCode = "cost_limit"     // The estimated cost needed confirmation that could not be asked for
+	CodeUnauthorized ErrorCode = "unauthorized"   // A revi daemon request lacked the daemon's token
+	CodeInternal     ErrorCode = "error"          // Any other failure
+)
+
+// codedError attaches an ErrorCode to an error
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode returns err annotated with code for structured error output
+func withCode(code ErrorCode, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// exitError attaches the status the process exits with to an error
+type exitError struct {
+	status int
+	err    error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitStatus returns err annotated with the status revi exits with
+func withExitStatus(status int, err error) error {
+	return &exitError{status: status, err: err}
+}
+
+// ExitStatus returns the status the process should exit with after err:
+// 0 for nil, the status attached to err if there is one, and 1 otherwise.
+func ExitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.status
+	}
+	return 1
+}
+
+// errorCode returns the ErrorCode for err, using an explicit code if one was
+// attached and otherwise recognizing known sentinel errors
+func errorCode(err error) ErrorCode {
+	var coded *codedError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, review.ErrAuthRequired):
+		return CodeAuthRequired
+	case errors.Is(err, lock.ErrLocked):
+		return CodeLocked
+	case errors.Is(err, git.ErrNotAGitRepo):
+		return CodeNotAGitRepo
+	case errors.Is(err, git.ErrNoStagedChanges),
+		errors.Is(err, git.ErrNoWorkingTreeChanges),
+		errors.Is(err, git.ErrNoChangesBetween):
+		return CodeNoChanges
+	default:
+		return CodeInternal
+	}
+}
+
+// jsonError is the structured form of an error written in JSON output mode
+type jsonError struct {
+	Error struct {
+		Code    ErrorCode `json:"code"`
+		Message string    `json:"message"`
+	} `json:"error"`
+}
+
+// writeJSONError writes err to w as a single-line JSON object
+func writeJSONError(w io.Writer, err error) {
+	var out jsonError
+	out.Error.Code = errorCode(err)
+	out.Error.Message = err.Error()
+	// Write errors are intentionally ignored - there is nowhere left to report them
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// jsonReport is the structured form of review results written in JSON output mode
+type jsonReport struct {
+	Results  []*review.Result   `json:"results"`
+	Summary  review.Summary     `json:"summary"`
+	Blocked  bool               `json:"blocked"`
+	Partial  bool               `json:"partial,omitempty"` // Some reviews timed out before finishing
+	Sampling *diff.SampleReport `json:"sampling,omitempty"`
+}
+
+// writeJSONReport writes review results and their summary to w as JSON.
+// sampling records which hunks were reviewed when review.sampling is set, or is nil.
+func writeJSONReport(w io.Writer, results []*review.Result, blocked bool, sampling *diff.SampleReport) error {
+	if results == nil {
+		results = []*review.Result{}
+	}
+	summary := review.Summarize(results)
+	report := jsonReport{
+		Results:  results,
+		Summary:  summary,
+		Blocked:  blocked,
+		Partial:  summary.TimedOutReviews > 0,
+		Sampling: sampling,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+	return nil
+}
+
+// outputFormat returns the validated value of --output
+func outputFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("output")
+	switch format {
+	case "", outputText:
+		return outputText, nil
+	case outputJSON, outputCSV:
+		return format, nil
+	default:
+		return "", withCode(CodeInvalidInput, fmt.Errorf("invalid output format %q, expected text, json or csv", format))
+	}
+}
+
+// isJSONOutput returns true if --output json was requested
+func isJSONOutput(cmd *cobra.Command) bool {
+	format, err := outputFormat(cmd)
+	return err == nil && format == outputJSON
+}
+
+// isCSVOutput returns true if --output csv was requested
+func isCSVOutput(cmd *cobra.Command) bool {
+	format, err := outputFormat(cmd)
+	return err == nil && format == outputCSV
+}
+
+// csvHeader names the columns written by writeCSVReport
+var csvHeader = []string{"mode", "severity", "file", "line", "description", "fix_available", "fingerprint"}
+
+// writeCSVReport writes the issues of results to w as CSV, one row per issue
+// under a header row, for triaging in a spreadsheet. The fingerprint matches
+// the one in the baseline and triage files. The line is empty for issues
+// without one.
+func writeCSVReport(w io.Writer, results []*review.Result) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(csvHeader)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			file, line := issue.FileLine()
+			lineText := ""
+			if line > 0 {
+				lineText = strconv.Itoa(line)
+			}
+			fixAvailable := issue.Fix != nil && issue.Fix.Available
+			_ = cw.Write([]string{
+				string(r.Mode), issue.Severity, file, lineText, issue.Description,
+				strconv.FormatBool(fixAvailable), baseline.Fingerprint(r.Mode, issue),
+			})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/lock"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// =============================================================================
+// Tests for errorCode function
+// =============================================================================
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"explicit code", withCode(CodeBlocked, errors.New("high-severity issues found")), CodeBlocked},
+		{"wrapped explicit code", fmt.Errorf("run: %w", withCode(CodeNoChanges, errors.New("none"))), CodeNoChanges},
+		{"auth", fmt.Errorf("review: %w", review.ErrAuthRequired), CodeAuthRequired},
+		{"locked", &lock.LockedError{Path: ".git/revi.lock"}, CodeLocked},
+		{"not a repo", fmt.Errorf("failed to open git repository: %w", git.ErrNotAGitRepo), CodeNotAGitRepo},
+		{"no staged changes", git.ErrNoStagedChanges, CodeNoChanges},
+		{"other", errors.New("boom"), CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"plain error", errors.New("boom"), 1},
+		{"attached status", withExitStatus(2, errors.New("high")), 2},
+		{"wrapped status", fmt.Errorf("review: %w", withExitStatus(3, errors.New("medium"))), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitStatus(tt.err); got != tt.want {
+				t.Errorf("ExitStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Tests for JSON output helpers
+// =============================================================================
+
+func TestWriteJSONError(t *testing.T) {
+	var buf bytes.Buffer
+	writeJSONError(&buf, withCode(CodeNoChanges, errors.New("no staged changes found")))
+
+	var out struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%s)", err, buf.String())
+	}
+	if out.Error.Code != string(CodeNoChanges) {
+		t.Errorf("code = %q, want %q", out.Error.Code, CodeNoChanges)
+	}
+	if out.Error.Message != "no staged changes found" {
+		t.Errorf("message = %q", out.Error.Message)
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	results := []*review.Result{
+		{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "high", Description: "SQL injection"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, results, true, nil); err != nil {
+		t.Fatalf("writeJSONReport() failed: %v", err)
+	}
+
+	var out struct {
+		Results []review.Result `json:"results"`
+		Summary struct {
+			HighSeverity int `json:"high_severity"`
+		} `json:"summary"`
+		Blocked bool `json:"blocked"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(out.Results) != 1 || out.Summary.HighSeverity != 1 || !out.Blocked {
+		t.Errorf("unexpected report: %s", buf.String())
+	}
+}
+
+func TestWriteJSONReport_MarksPartialReview(t *testing.T) {
+	results := []*review.Result{
+		{Mode: review.ModeSecurity, Status: review.StatusNoIssues},
+		{Mode: review.ModeStyle, Status: review.StatusTimedOut},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, results, false, nil); err != nil {
+		t.Fatalf("writeJSONReport() failed: %v", err)
+	}
+
+	var out struct {
+		Summary struct {
+			TimedOutReviews int `json:"timed_out_reviews"`
+		} `json:"summary"`
+		Partial bool `json:"partial"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if !out.Partial || out.Summary.TimedOutReviews != 1 {
+		t.Errorf("expected a partial report with 1 timed out review: %s", buf.String())
+	}
+}
+
+func TestWriteJSONReport_RecordsSampling(t *testing.T) {
+	sampling := &diff.SampleReport{Rate: 0.25, Hunks: 8, Reviewed: 3, Critical: 1}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, nil, false, sampling); err != nil {
+		t.Fatalf("writeJSONReport() failed: %v", err)
+	}
+
+	var out struct {
+		Sampling *diff.SampleReport `json:"sampling"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if out.Sampling == nil || out.Sampling.Rate != 0.25 || out.Sampling.Reviewed != 3 {
+		t.Errorf("unexpected sampling: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeJSONReport(&buf, nil, false, nil); err != nil {
+		t.Fatalf("writeJSONReport() failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "sampling") {
+		t.Errorf("sampling should be omitted when disabled: %s", buf.String())
+	}
+}
+
+func TestWriteCSVReport(t *testing.T) {
+	issue := review.Issue{
+		Severity:    "high",
+		Description: "Password compared with \"==\", not constant time",
+		Location:    "auth/login.go:42",
+		Fix:         &review.Fix{Available: true},
+	}
+	results := []*review.Result{
+		{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{issue}},
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "Long function"}}},
+		nil,
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSVReport(&buf, results); err != nil {
+		t.Fatalf("writeCSVReport() failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want a header and 2 issues: %v", len(rows), rows)
+	}
+	if strings.Join(rows[0], ",") != "mode,severity,file,line,description,fix_available,fingerprint" {
+		t.Errorf("header = %v", rows[0])
+	}
+	want := []string{"security", "high", "auth/login.go", "42", issue.Description, "true", baseline.Fingerprint(review.ModeSecurity, issue)}
+	if strings.Join(rows[1], "|") != strings.Join(want, "|") {
+		t.Errorf("row = %v, want %v", rows[1], want)
+	}
+	if rows[2][2] != "" || rows[2][3] != "" || rows[2][5] != "false" {
+		t.Errorf("issue without location or fix: %v", rows[2])
+	}
+}
+
+func TestOutputFormat_AcceptsCSV(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", "csv", "")
+
+	if format, err := outputFormat(cmd); err != nil || format != outputCSV {
+		t.Errorf("outputFormat() = %q, %v, want %q", format, err, outputCSV)
+	}
+	if !isCSVOutput(cmd) || isJSONOutput(cmd) {
+		t.Error("expected only isCSVOutput to report csv output")
+	}
+}
+
+func TestOutputFormat_RejectsUnknownFormat(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", "yaml", "")
+
+	_, err := outputFormat(cmd)
+	if err == nil {
+		t.Fatal("expected error for unknown output format")
+	}
+	if errorCode(err) != CodeInvalidInput {
+		t.Errorf("errorCode() = %q, want %q", errorCode(err), CodeInvalidInput)
+	}
+}
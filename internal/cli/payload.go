@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/porcelain"
+	"github.com/buker/revi/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// payloadFile is a file of the changes under review, as far as the AI gets to
+// see it
+type payloadFile struct {
+	Path    string
+	Hunks   int    // Hunks sent
+	Dropped int    // Hunks left out by --ignore-whitespace or review.sampling
+	Bytes   int    // Size of the file's part of the diff sent
+	Omitted string // Why the file's content was withheld by the diff settings, if it was
+}
+
+// payload describes what a run sends to the AI: the diff, file by file, and
+// the project context added to the prompts
+type payload struct {
+	Model      string // Model receiving the prompts
+	CrossCheck string // Second model receiving the prompts of some modes, if any
+	Files      []payloadFile
+	Bytes      int      // Size of the diff sent
+	Guidance   []string // Project guidance added to the prompts, with its size
+	GoModule   string   // Go module described in the prompts, if any
+	// Redacted are the secrets replaced in the diff before it is sent
+	Redacted []review.Redaction
+}
+
+// buildPayload describes the payload of a run that sends sent, the part of
+// the diff raw left after filtering, to aiClient: for reviews, or for a commit
+// message if commit is set
+func buildPayload(aiClient *ai.Client, raw, sent *diff.Diff, commit bool) payload {
+	p := payload{Model: aiClient.Model(), Bytes: len(sent.String())}
+	if aiClient.RedactsSecrets() {
+		_, p.Redacted = review.RedactDiff(sent)
+	}
+
+	for _, f := range raw.Files {
+		file := payloadFile{Path: f.Path, Omitted: git.OmittedReason(f.Header)}
+		if s := sent.File(f.Path); s != nil {
+			file.Hunks = len(s.Hunks)
+			file.Bytes = len(s.String())
+		}
+		file.Dropped = len(f.Hunks) - file.Hunks
+		p.Files = append(p.Files, file)
+	}
+
+	guidance := aiClient.Guidance()
+	if commit {
+		if guidance.Commit != "" {
+			p.Guidance = append(p.Guidance, fmt.Sprintf("commit (%s)", formatSize(len(guidance.Commit))))
+		}
+		return p
+	}
+	if guidance.Review != "" {
+		p.Guidance = append(p.Guidance, fmt.Sprintf("review (%s)", formatSize(len(guidance.Review))))
+	}
+	modes := make([]review.Mode, 0, len(guidance.Modes))
+	for mode := range guidance.Modes {
+		modes = append(modes, mode)
+	}
+	slices.Sort(modes)
+	for _, mode := range modes {
+		p.Guidance = append(p.Guidance, fmt.Sprintf("%s (%s)", mode, formatSize(len(guidance.Modes[mode]))))
+	}
+	if module := aiClient.GoModule(); module != nil {
+		p.GoModule = module.Path
+		if module.GoVersion != "" {
+			p.GoModule += ", go " + module.GoVersion
+		}
+	}
+	return p
+}
+
+// lines returns the payload described line by line, starting with a summary
+func (p payload) lines() []string {
+	sent := 0
+	for _, f := range p.Files {
+		if f.Hunks > 0 || f.Omitted != "" {
+			sent++
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("%d file(s), %s of diff, sent to %s through the Claude Code CLI", sent, formatSize(p.Bytes), p.Model),
+	}
+	if p.CrossCheck != "" {
+		lines = append(lines, "Also sent to the cross-check model "+p.CrossCheck)
+	}
+	for _, f := range p.Files {
+		var detail string
+		switch {
+		case f.Omitted != "":
+			detail = "name only, content withheld: " + f.Omitted
+		case f.Hunks == 0:
+			detail = fmt.Sprintf("not sent, %d hunk(s) left out", f.Dropped)
+		default:
+			detail = fmt.Sprintf("%d hunk(s), %s", f.Hunks, formatSize(f.Bytes))
+			if f.Dropped > 0 {
+				detail += fmt.Sprintf("; %d hunk(s) left out", f.Dropped)
+			}
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", f.Path, detail))
+	}
+	lines = append(lines, p.redactionLines()...)
+	if len(p.Guidance) > 0 {
+		lines = append(lines, "Project guidance: "+strings.Join(p.Guidance, ", "))
+	}
+	if p.GoModule != "" {
+		lines = append(lines, "Go module: "+p.GoModule)
+	}
+	return lines
+}
+
+// redactionLines lists the secrets redacted from the diff, if any
+func (p payload) redactionLines() []string {
+	if len(p.Redacted) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("%d secret(s) redacted before sending (ai.redact_secrets):", len(p.Redacted))}
+	for _, r := range p.Redacted {
+		lines = append(lines, fmt.Sprintf("  %s: %s", r.Location, r.Kind))
+	}
+	return lines
+}
+
+// withCrossCheck adds the cross-check model of cfg, if one is set, to p
+func (p payload) withCrossCheck(cfg *config.Config) payload {
+	if model := cfg.Review.CrossCheck.Model; model != "" {
+		p.CrossCheck = fmt.Sprintf("%s (modes: %s)", model, strings.Join(cfg.Review.CrossCheck.Modes, ", "))
+	}
+	return p
+}
+
+// panel returns the lines of p for the TUI if --show-payload is set, or nil
+func (p payload) panel(cmd *cobra.Command) []string {
+	if show, _ := cmd.Flags().GetBool("show-payload"); !show {
+		return nil
+	}
+	return p.lines()
+}
+
+// writePayload writes p to w under a heading
+func writePayload(w io.Writer, p payload) {
+	fmt.Fprintln(w, "Payload:")
+	for _, line := range p.lines() {
+		fmt.Fprintln(w, "  "+line)
+	}
+}
+
+// showPayload writes p to stderr if --show-payload is set and asks whether to
+// send it, unless nobody can answer: in CI and with --output json or csv the
+// run goes ahead. With --porcelain, p is written as note records and the
+// parent process is asked. Returns false if the user declined. Without
+// --show-payload, only the secrets redacted from p are listed.
+func showPayload(cmd *cobra.Command, p payload) bool {
+	if show, _ := cmd.Flags().GetBool("show-payload"); !show {
+		reportRedactions(cmd, p)
+		return true
+	}
+	if pw := porcelainWriter(cmd); pw != nil {
+		return confirmPayloadPorcelain(pw, p)
+	}
+	writePayload(os.Stderr, p)
+	if isCI(cmd) || isJSONOutput(cmd) || isCSVOutput(cmd) {
+		return true
+	}
+	return confirmPayload(os.Stdin, os.Stderr, p)
+}
+
+// reportRedactions lists the secrets redacted from p on stderr, or as note
+// records with --porcelain
+func reportRedactions(cmd *cobra.Command, p payload) {
+	lines := p.redactionLines()
+	if pw := porcelainWriter(cmd); pw != nil {
+		for _, line := range lines {
+			pw.Write(porcelain.KindNote, line)
+		}
+		return
+	}
+	for _, line := range lines {
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
+
+// confirmPayload asks on out whether to send p and reads the answer from in
+func confirmPayload(in io.Reader, out io.Writer, p payload) bool {
+	fmt.Fprintf(out, "Send this to %s? [y/N] ", p.Model)
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
+// formatSize returns n bytes in B, KB or MB
+func formatSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
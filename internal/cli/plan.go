@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/porcelain"
+	"github.com/buker/revi/internal/review"
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+)
+
+// planFile is a file of the changes a --dry-run would commit
+type planFile struct {
+	Path    string
+	Status  diff.FileStatus
+	Added   int
+	Removed int
+}
+
+// commitPlan is what a commit run would do, printed by --dry-run instead of
+// committing: the files, how revi review would review them, the estimated
+// cost of both and the message generated for them
+type commitPlan struct {
+	Source    string // Changes the message describes
+	Files     []planFile
+	Modes     []review.Mode   // Modes a review of the changes would run
+	Reasoning string          // Why the modes were selected
+	Message   ai.CostEstimate // Cost of generating the commit message
+	Review    ai.CostEstimate // Cost of reviewing the changes in Modes
+	Commit    string          // Generated commit message
+}
+
+// buildCommitPlan describes the commit of staged, the diff of source, with
+// message. The review modes are detected as revi review detects them, which
+// takes an AI call.
+func buildCommitPlan(ctx context.Context, aiClient *ai.Client, source string, staged *diff.Diff, userContext, message string) commitPlan {
+	p := commitPlan{Source: source, Commit: message}
+	for _, f := range staged.Files {
+		added, removed := f.Stat()
+		p.Files = append(p.Files, planFile{Path: f.Path, Status: f.Status(), Added: added, Removed: removed})
+	}
+	p.Modes, p.Reasoning = planModes(ctx, aiClient, staged)
+	p.Message = aiClient.EstimateCommitMessage(staged, userContext)
+	p.Review = reviewCost(config.Get(), aiClient, staged, p.Modes)
+	return p
+}
+
+// planModes returns the modes revi review would run on staged and why they were
+// selected, falling back to the heuristic detector if the AI is unavailable
+func planModes(ctx context.Context, aiClient *ai.Client, staged *diff.Diff) ([]review.Mode, string) {
+	var modes []review.Mode
+	var reasoning string
+	err := aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
+		var err error
+		modes, reasoning, err = claudeModeDetector(aiClient, client).Detect(ctx, staged)
+		return err
+	})
+	if err != nil {
+		debugLog("mode detection for the plan failed: %v", err)
+		modes, reasoning, _ = review.NewHeuristicDetector().Detect(ctx, staged)
+	}
+	return modes, reasoning
+}
+
+// lines returns the plan described line by line
+func (p commitPlan) lines() []string {
+	lines := []string{fmt.Sprintf("Plan for %s:", p.Source), fmt.Sprintf("Files (%d):", len(p.Files))}
+	for _, f := range p.Files {
+		lines = append(lines, fmt.Sprintf("  %-8s %s (+%d -%d)", f.Status, f.Path, f.Added, f.Removed))
+	}
+
+	names := make([]string, len(p.Modes))
+	for i, mode := range p.Modes {
+		names[i] = string(mode)
+	}
+	lines = append(lines, "Review modes: "+strings.Join(names, ", "))
+	if p.Reasoning != "" {
+		lines = append(lines, "  "+p.Reasoning)
+	}
+
+	lines = append(lines,
+		"Estimated cost:",
+		"  Commit message: "+p.Message.String(),
+		"  Review:         "+p.Review.String(),
+		"Commit message:",
+	)
+	for _, line := range strings.Split(p.Commit, "\n") {
+		lines = append(lines, strings.TrimRight("  "+line, " "))
+	}
+	return lines
+}
+
+// writeCommitPlan writes p to w, or as note records and the message record
+// with --porcelain
+func writeCommitPlan(w io.Writer, pw *porcelain.Writer, p commitPlan) {
+	if pw != nil {
+		for _, line := range p.lines() {
+			pw.Write(porcelain.KindNote, line)
+		}
+		pw.Write(porcelain.KindMessage, p.Commit)
+		pw.Write(porcelain.KindNote, "Dry run - commit not created.")
+		return
+	}
+	for _, line := range p.lines() {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Dry run - commit not created.")
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/fix"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/porcelain"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/suppress"
+	"github.com/spf13/cobra"
+)
+
+// errReviewCancelled is returned by collectReviews when the parent process
+// declined to start reviews above review.confirm_cost
+var errReviewCancelled = errors.New("review cancelled")
+
+// porcelainOut writes the records of the run once --porcelain was seen
+var porcelainOut *porcelain.Writer
+
+// addPorcelainFlag adds --porcelain to cmd
+func addPorcelainFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("porcelain", false, "Speak a stable line protocol on stdin and stdout, for other programs to embed revi")
+}
+
+// porcelainWriter returns the writer of --porcelain records, or nil if
+// --porcelain is not set. The version record is written on first use.
+func porcelainWriter(cmd *cobra.Command) *porcelain.Writer {
+	if on, _ := cmd.Flags().GetBool("porcelain"); !on {
+		return nil
+	}
+	if porcelainOut == nil {
+		porcelainOut = porcelain.NewWriter(os.Stdout, os.Stdin)
+	}
+	return porcelainOut
+}
+
+// printNote prints msg, or writes it as a note record with --porcelain
+func printNote(pw *porcelain.Writer, msg string) {
+	if pw != nil {
+		pw.Write(porcelain.KindNote, msg)
+		return
+	}
+	fmt.Println(msg)
+}
+
+// writePorcelainResult writes the outcome of a review and its issue counts
+func writePorcelainResult(pw *porcelain.Writer, outcome string, summary review.Summary) {
+	pw.Write(porcelain.KindResult, outcome,
+		strconv.Itoa(summary.HighSeverity), strconv.Itoa(summary.MediumSeverity),
+		strconv.Itoa(summary.LowSeverity), strconv.Itoa(summary.TimedOutReviews))
+}
+
+// writePorcelainIssues writes an issue record for every issue of results
+func writePorcelainIssues(pw *porcelain.Writer, results []*review.Result) {
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			file, line := issue.FileLine()
+			lineText := ""
+			if line > 0 {
+				lineText = strconv.Itoa(line)
+			}
+			fixAvailable := "no"
+			if issue.Fix != nil && issue.Fix.Available {
+				fixAvailable = "yes"
+			}
+			pw.Write(porcelain.KindIssue, string(r.Mode), issue.Severity, file, lineText,
+				fixAvailable, baseline.Fingerprint(r.Mode, issue), issue.Description)
+		}
+	}
+}
+
+// confirmPayloadPorcelain lists p as note records and asks the parent process
+// whether to send it
+func confirmPayloadPorcelain(pw *porcelain.Writer, p payload) bool {
+	for _, line := range p.lines() {
+		pw.Write(porcelain.KindNote, line)
+	}
+	return pw.Confirm("payload", fmt.Sprintf("Send this to %s?", p.Model))
+}
+
+// runReviewPorcelain runs the review with --porcelain: progress, issues and
+// the result are written as records, and the parent process answers the
+// prompts for the cost and, with --fix, for each fix
+func runReviewPorcelain(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, filter *suppress.Filter, rec *reviewRecord, rep *reviewReport, diff *diff.Diff, pw *porcelain.Writer) error {
+	results, err := collectReviews(cmd, ctx, aiClient, issueLinker(config.Get(), repo), newIssueBlamer(config.Get(), repo), filter, rec, diff)
+	if errors.Is(err, errReviewCancelled) {
+		writePorcelainResult(pw, "cancelled", review.Summary{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := rep.write(results); err != nil {
+		return err
+	}
+	writePorcelainIssues(pw, results)
+
+	fixEnabled, _ := cmd.Flags().GetBool("fix")
+	fixAll, _ := cmd.Flags().GetBool("fix-all")
+	if fixEnabled || fixAll {
+		if err := applyFixesPorcelain(ctx, pw, repo, rec, results, fixAll); err != nil {
+			return err
+		}
+	}
+
+	summary := review.Summarize(results)
+	blocked := review.ShouldBlockAt(results, isBlockEnabled(cmd), blockThreshold())
+	reportReviewStatus(repo, results, blocked)
+	writePorcelainResult(pw, reviewOutcome(results, blocked), summary)
+	if ciRequested(cmd) {
+		if err := ciExitError(config.Get().CI.ExitCodes, summary); err != nil && isBlockEnabled(cmd) {
+			return err
+		}
+	} else if blocked {
+		return blockedError(results)
+	}
+	if summary.TimedOutReviews > 0 {
+		return timeoutError(cmd)
+	}
+	return nil
+}
+
+// applyFixesPorcelain applies the available fixes of results: all of them
+// with fixAll, otherwise those selected by fix.auto_apply and those the parent
+// process accepts. Each gets a fix record. The parent is then asked whether
+// to stage the files the fixes changed.
+func applyFixesPorcelain(ctx context.Context, pw *porcelain.Writer, repo *git.Repository, rec *reviewRecord, results []*review.Result, fixAll bool) error {
+	policy, err := fix.NewPolicy(config.Get().Fix.AutoApply)
+	if err != nil {
+		return withCode(CodeInvalidInput, fmt.Errorf("invalid fix.auto_apply: %w", err))
+	}
+	repoRoot, err := repo.Root()
+	if err != nil {
+		return fmt.Errorf("failed to get repository root: %w", err)
+	}
+	journal := fix.NewJournal()
+	applier := fix.NewApplier(repoRoot)
+	applier.SetJournal(journal)
+	setFixVerifier(applier, repoRoot)
+	apply := tracedFixApplier(ctx, applier.Apply)
+
+	applied := 0
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			if issue.Fix == nil || !issue.Fix.Available {
+				continue
+			}
+			fp := baseline.Fingerprint(r.Mode, issue)
+			if !fixAll && !policy.Allows(r.Mode, issue) &&
+				!pw.Confirm("fix:"+fp, fmt.Sprintf("Apply the fix for %q?", issue.Description)) {
+				pw.Write(porcelain.KindFix, fp, "skipped", "")
+				continue
+			}
+			if err := apply(issue.Fix); err != nil {
+				pw.Write(porcelain.KindFix, fp, "failed", err.Error())
+				continue
+			}
+			pw.Write(porcelain.KindFix, fp, "applied", issue.Fix.FilePath)
+			applied++
+		}
+	}
+	rec.setFixesApplied(applied)
+	keepFixJournal(repo, journal)
+
+	paths := journal.Paths()
+	if len(paths) == 0 || !pw.Confirm("stage", fmt.Sprintf("Stage the %d file(s) changed by fixes?", len(paths))) {
+		return nil
+	}
+	if err := repo.StageFiles(paths); err != nil {
+		return err
+	}
+	pw.Write(porcelain.KindNote, fmt.Sprintf("Staged %d file(s).", len(paths)))
+	return nil
+}
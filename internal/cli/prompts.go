@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/review"
+)
+
+// promptGuidance returns the project's prompt guidance: the fragments in
+// .revi/prompts/ at the repository root, followed by the prompts.* settings
+func promptGuidance(cfg *config.Config) (ai.Guidance, error) {
+	guidance, err := ai.LoadGuidance(filepath.Join(currentRepoRoot(), ai.GuidanceDir))
+	if err != nil {
+		return ai.Guidance{}, withCode(CodeInvalidInput, err)
+	}
+
+	configured := ai.Guidance{Review: cfg.Prompts.Review, Commit: cfg.Prompts.Commit}
+	for name, text := range cfg.Prompts.Modes {
+		mode := review.Mode(strings.ToLower(strings.TrimSpace(name)))
+		if review.GetModeInfo(mode).Name == "" {
+			return ai.Guidance{}, withCode(CodeInvalidInput, fmt.Errorf("invalid prompts.modes: unknown review mode %q", name))
+		}
+		if configured.Modes == nil {
+			configured.Modes = make(map[review.Mode]string)
+		}
+		configured.Modes[mode] = text
+	}
+	return guidance.Merge(configured), nil
+}
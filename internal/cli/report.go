@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/report"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/source"
+	"github.com/buker/revi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// reviewReport writes the results of a review run to the Markdown file named
+// by --report. A nil *reviewReport writes nothing.
+type reviewReport struct {
+	path   string
+	source string
+
+	mu      sync.Mutex
+	results []*review.Result
+}
+
+// newReviewReport returns the report requested with --report for a review of
+// src, or nil if none was requested
+func newReviewReport(cmd *cobra.Command, src source.Source) *reviewReport {
+	path, _ := cmd.Flags().GetString("report")
+	if path == "" {
+		return nil
+	}
+	return &reviewReport{path: path, source: src.Describe()}
+}
+
+// wrap collects the result of every review made through run, for workflows
+// that do not return their results
+func (r *reviewReport) wrap(run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if r == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		if result != nil {
+			r.mu.Lock()
+			r.results = append(r.results, result)
+			r.mu.Unlock()
+		}
+		return result, err
+	}
+}
+
+// collected returns the results collected through wrap
+func (r *reviewReport) collected() []*review.Result {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results
+}
+
+// write writes results to the report file. The message is printed to stderr
+// so it does not mix with JSON output.
+func (r *reviewReport) write(results []*review.Result) error {
+	if r == nil {
+		return nil
+	}
+	labels, _ := severityLabels(config.Get())
+	if err := writePatchFile(r.path, func(f *os.File) error {
+		return report.WriteMarkdown(f, r.source, results, labels)
+	}); err != nil {
+		return fmt.Errorf("failed to write review report: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote review report to %s\n", r.path)
+	return nil
+}
+
+// exportedIssuesFile is where the issues selected in the TUI are exported,
+// relative to the repository root
+const exportedIssuesFile = "revi-issues.md"
+
+// issueExporter returns the function the TUI exports the selected issues with,
+// as a Markdown report in the repository root
+func issueExporter(repo *git.Repository) tui.IssueExporter {
+	return func(results []*review.Result) (string, error) {
+		root, err := repo.Root()
+		if err != nil {
+			return "", err
+		}
+		labels, _ := severityLabels(config.Get())
+		if err := writePatchFile(filepath.Join(root, exportedIssuesFile), func(f *os.File) error {
+			return report.WriteMarkdown(f, "selected issues", results, labels)
+		}); err != nil {
+			return "", fmt.Errorf("failed to export issues: %w", err)
+		}
+		return exportedIssuesFile, nil
+	}
+}
@@ -2,31 +2,86 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/buker/revi/internal/ai"
 	"github.com/buker/revi/internal/config"
 	"github.com/buker/revi/internal/fix"
 	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/permalink"
+	"github.com/buker/revi/internal/porcelain"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/suppress"
+	"github.com/buker/revi/internal/telemetry"
 	"github.com/buker/revi/internal/tui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func init() {
-	// Fix flag
+	// Fix flags
 	reviewCmd.Flags().BoolP("fix", "f", false, "Interactively fix detected issues")
+	reviewCmd.Flags().Bool("fix-all", false, "Apply every available fix without prompting (implies --fix)")
+	reviewCmd.Flags().String("patch-out", "", "Write fixes applied with --fix to a patch file")
+	reviewCmd.Flags().String("unapplied-patch-out", "", "Write suggested fixes that were not applied to a patch file")
+	reviewCmd.Flags().String("report", "", "Write the review results to a Markdown file")
+	reviewCmd.Flags().Int("preview-context", 3, "Unchanged lines to show around each fix preview")
+	_ = viper.BindPFlag("fix.preview_context", reviewCmd.Flags().Lookup("preview-context"))
+	reviewCmd.Flags().String("fix-verify", "", "Command run after each fix, such as \"go test ./...\"; failing fixes are rolled back")
+	_ = viper.BindPFlag("fix.verify", reviewCmd.Flags().Lookup("fix-verify"))
 
 	// Block flags
-	reviewCmd.Flags().BoolP("block", "b", true, "Exit with error if high-severity issues found")
+	reviewCmd.Flags().BoolP("block", "b", true, "Exit with error if issues of review.block_threshold (high by default) are found")
 	reviewCmd.Flags().BoolP("no-block", "B", false, "Don't exit with error on issues")
+	reviewCmd.Flags().Int("exit-high", 2, "Exit status with --ci when high-severity issues are found")
+	_ = viper.BindPFlag("ci.exit_codes.high", reviewCmd.Flags().Lookup("exit-high"))
+	reviewCmd.Flags().Int("exit-medium", 1, "Exit status with --ci when medium-severity issues are the most severe found")
+	_ = viper.BindPFlag("ci.exit_codes.medium", reviewCmd.Flags().Lookup("exit-medium"))
+	reviewCmd.Flags().Int("exit-low", 0, "Exit status with --ci when low-severity issues are the most severe found")
+	_ = viper.BindPFlag("ci.exit_codes.low", reviewCmd.Flags().Lookup("exit-low"))
 
-	// TUI flag
+	// TUI flags
 	reviewCmd.Flags().Bool("no-tui", false, "Disable TUI (use plain text output)")
+	reviewCmd.Flags().Bool("inline", false, "Render the TUI inline instead of on the alternate screen")
+	_ = viper.BindPFlag("ui.inline", reviewCmd.Flags().Lookup("inline"))
+	reviewCmd.Flags().Bool("screen-reader", false, "Plain, linear text output for screen readers (implies --no-tui)")
+	_ = viper.BindPFlag("ui.screen_reader", reviewCmd.Flags().Lookup("screen-reader"))
+
+	// Diff source flags
+	addSourceFlags(reviewCmd)
+
+	// Noise filtering flag
+	reviewCmd.Flags().Bool("ignore-whitespace", false, "Skip whitespace-only, reformat-only and moved hunks")
+	reviewCmd.Flags().StringArray("path", nil, "Review only the files matching this glob, such as 'internal/api/**' (repeatable; replaces review.include)")
+	reviewCmd.Flags().String("cross-check-model", "", "Re-run cross-checked modes with this model and compare findings")
+	_ = viper.BindPFlag("review.cross_check.model", reviewCmd.Flags().Lookup("cross-check-model"))
+	reviewCmd.Flags().Bool("promote-suggestions", false, "Turn every suggestion into an issue with a fix attempt")
+	reviewCmd.Flags().Bool("blame", false, "Show who last changed the lines around each issue")
+	_ = viper.BindPFlag("report.blame", reviewCmd.Flags().Lookup("blame"))
+	reviewCmd.Flags().Bool("show-payload", false, "List what will be sent to the AI and ask before sending it")
+	reviewCmd.Flags().Bool("no-ignore", false, "Report issues suppressed by .reviignore and revi:ignore annotations")
+	reviewCmd.Flags().String("baseline", "", "Report only issues new since an earlier run: a JSON results or baseline file, a review ID, or \"last\"")
+	reviewCmd.MarkFlagsMutuallyExclusive("baseline", "no-ignore")
+	reviewCmd.Flags().Float64("sampling", 1, "Fraction of hunks outside review.critical_paths to review (1 reviews everything)")
+	_ = viper.BindPFlag("review.sampling", reviewCmd.Flags().Lookup("sampling"))
+	reviewCmd.Flags().Int("concurrency", 0, "Maximum number of review modes running at once (0 runs all at once)")
+	_ = viper.BindPFlag("review.concurrency", reviewCmd.Flags().Lookup("concurrency"))
+	reviewCmd.Flags().Bool("offline", false, "Review without the AI, with the rule checks, secrets scanner and linters only")
+	_ = viper.BindPFlag("review.offline", reviewCmd.Flags().Lookup("offline"))
+	reviewCmd.Flags().Float64("confirm-cost", 2, "Ask before running reviews estimated to cost more than this many USD (0 never asks)")
+	_ = viper.BindPFlag("review.confirm_cost", reviewCmd.Flags().Lookup("confirm-cost"))
+	addPorcelainFlag(reviewCmd)
 
 	// Review mode flags
 	reviewCmd.Flags().Bool("security", false, "Enable security review")
@@ -41,7 +96,15 @@ func init() {
 	reviewCmd.Flags().Bool("no-testing", false, "Disable testing review")
 	reviewCmd.Flags().Bool("docs", false, "Enable documentation review")
 	reviewCmd.Flags().Bool("no-docs", false, "Disable documentation review")
+	reviewCmd.Flags().Bool("secrets", false, "Enable secrets review")
+	reviewCmd.Flags().Bool("no-secrets", false, "Disable secrets review")
 	reviewCmd.Flags().BoolP("all", "a", false, "Run all review modes")
+
+	// Commands reviewing other diffs share these flags
+	addSnapshotReviewFlags()
+	addMRReviewFlags()
+	addBatchReviewFlags()
+	addDaemonReviewFlags()
 }
 
 var reviewCmd = &cobra.Command{
@@ -51,60 +114,363 @@ var reviewCmd = &cobra.Command{
 
 This command analyzes your staged git changes using specialized review agents
 (security, performance, style, error handling, testing, documentation).
+Use --working-tree to review changes that have not been staged yet,
+--range/--commit to review committed changes such as a feature branch,
+--patch/--stdin to review a diff produced elsewhere, or --pr to fetch and
+review a pull request.
 
-Use --fix to interactively apply suggested fixes after the review.`,
+Use --fix to interactively apply suggested fixes after the review, or
+--fix-all to apply every available fix without prompting.`,
 	RunE: runReview,
 }
 
 func runReview(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx, cancel := workflowContext(cmd)
+	defer cancel()
 	cfg := config.Get()
 
 	// Initialize AI client wrapper with model configuration
-	aiClient, err := ai.NewClient(cfg.AI.Model)
+	aiClient, err := newReviewClient(cfg, cfg.AI.Model)
 	if err != nil {
 		return fmt.Errorf("failed to initialize AI client: %w", err)
 	}
+	if err := setModeModels(cmd, aiClient, cfg); err != nil {
+		return err
+	}
+	if _, err := severityLabels(cfg); err != nil {
+		return err
+	}
+	if ciRequested(cmd) {
+		if err := validateCIExitCodes(cfg.CI.ExitCodes); err != nil {
+			return err
+		}
+	}
 
 	// Open git repository
 	repo, err := git.OpenCurrent()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
+	repo.SetContentFilter(contentFilter(cfg))
+	repo.SetSyntaxContext(cfg.Review.SyntaxContext)
 
-	// Check for staged changes
-	hasStagedChanges, err := repo.HasStagedChanges()
+	// Prevent concurrent runs from applying fixes to the same files
+	release, err := acquireRepoLock(cmd, repo)
 	if err != nil {
-		return fmt.Errorf("failed to check staged changes: %w", err)
+		return err
 	}
-	if !hasStagedChanges {
-		return fmt.Errorf("no staged changes found. Use 'git add' to stage files")
+	defer release()
+
+	// Get the changes to review
+	src, kind, err := selectSource(cmd, repo)
+	if err != nil {
+		return err
+	}
+	diff, err := sourceDiff(src)
+	if err != nil {
+		return err
+	}
+
+	// Record the run under the hash of the unfiltered diff, which is what
+	// gets committed
+	rec := startReviewRecord(cmd, repo, src, kind, diff)
+	defer rec.save()
+	rep := newReviewReport(cmd, src)
+
+	raw := diff
+	pw := porcelainWriter(cmd)
+	diff, skipped := narrowDiff(cmd, cfg, diff)
+	if skipped != "" {
+		if pw != nil {
+			writePorcelainResult(pw, statusClean, review.Summary{})
+			return nil
+		}
+		if isJSONOutput(cmd) {
+			return writeJSONReport(os.Stdout, nil, false, nil)
+		}
+		if isCSVOutput(cmd) {
+			return writeCSVReport(os.Stdout, nil)
+		}
+		fmt.Println(skipped)
+		return nil
 	}
 
-	// Get staged diff
-	diff, err := repo.GetStagedDiff()
+	diff, sampling := sampleDiff(cmd, cfg, diff)
+	root, _ := repo.Root()
+	runLinters(cmd, ctx, cfg, aiClient, root, kind, diff)
+
+	// Nothing reaches the AI before --show-payload has listed it, and
+	// nothing reaches it at all offline
+	var panel []string
+	if !cfg.Review.Offline {
+		sent := buildPayload(aiClient, raw, diff, false).withCrossCheck(cfg)
+		if !showPayload(cmd, sent) {
+			if pw != nil {
+				writePorcelainResult(pw, "cancelled", review.Summary{})
+				return nil
+			}
+			fmt.Println("Review cancelled; nothing was sent.")
+			return nil
+		}
+		panel = sent.panel(cmd)
+	}
+
+	// Load .reviignore rules and inline annotations before reviewing
+	filter, err := issueFilter(cmd, repo, diff)
 	if err != nil {
-		return fmt.Errorf("failed to get staged diff: %w", err)
+		return err
+	}
+
+	if pw != nil {
+		return runReviewPorcelain(cmd, ctx, aiClient, repo, filter, rec, rep, diff, pw)
+	}
+	if isJSONOutput(cmd) || isCSVOutput(cmd) {
+		return runReviewJSON(cmd, ctx, aiClient, repo, filter, rec, rep, diff, sampling)
 	}
 
 	noTUI, err := cmd.Flags().GetBool("no-tui")
 	if err != nil {
 		return fmt.Errorf("failed to get no-tui flag: %w", err)
 	}
+	// CI has no terminal to drive the TUI unless --no-tui=false asks for it
+	if !cmd.Flags().Changed("no-tui") && isCI(cmd) {
+		noTUI = true
+	}
+	if cfg.UI.ScreenReader {
+		noTUI = true
+	}
 	if noTUI {
-		return runReviewTextMode(cmd, ctx, aiClient, repo, diff)
+		return runReviewTextMode(cmd, ctx, aiClient, repo, filter, rec, rep, diff)
+	}
+
+	return runReviewTUI(cmd, ctx, aiClient, repo, filter, rec, rep, diff, panel)
+}
+
+// narrowDiff scopes the staged diff to the review paths and removes its noise.
+// If nothing is left to review, it returns why instead.
+func narrowDiff(cmd *cobra.Command, cfg *config.Config, staged *diff.Diff) (*diff.Diff, string) {
+	staged, ok := scopeDiff(cmd, cfg, staged)
+	if !ok {
+		return nil, "No changed files match the review paths; nothing to review."
+	}
+	staged, ok = filterDiffNoise(cmd, cfg, staged)
+	if !ok {
+		return nil, "Only whitespace, formatting, or moved code changed; nothing to review."
+	}
+	return staged, ""
+}
+
+// scopeDiff keeps the files of the staged diff matching --path, or
+// review.include if it is not given, and not matching review.exclude.
+// Returns false if nothing is left to review.
+func scopeDiff(cmd *cobra.Command, cfg *config.Config, staged *diff.Diff) (*diff.Diff, bool) {
+	include := cfg.Review.Include
+	if paths, _ := cmd.Flags().GetStringArray("path"); len(paths) > 0 {
+		include = paths
+	}
+	if len(include) == 0 && len(cfg.Review.Exclude) == 0 {
+		return staged, true
 	}
 
-	return runReviewTUI(cmd, ctx, aiClient, repo, diff)
+	scoped := staged.Scope(include, cfg.Review.Exclude)
+	if len(scoped.Files) == 0 {
+		return nil, false
+	}
+	if len(scoped.Files) == len(staged.Files) {
+		return staged, true
+	}
+	if !isJSONOutput(cmd) {
+		fmt.Fprintf(os.Stderr, "Reviewing %d of %d changed file(s) matching the review paths\n", len(scoped.Files), len(staged.Files))
+	}
+	return scoped, true
 }
 
-// runReviewTUI runs the review workflow with the interactive TUI
-func runReviewTUI(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, diff string) error {
+// filterDiffNoise removes whitespace-only, reformat-only and pure-move hunks from
+// the staged diff when --ignore-whitespace or review.ignore_whitespace is set.
+// Returns false if nothing is left to review.
+func filterDiffNoise(cmd *cobra.Command, cfg *config.Config, staged *diff.Diff) (*diff.Diff, bool) {
+	ignore, _ := cmd.Flags().GetBool("ignore-whitespace")
+	if !ignore && !cfg.Review.IgnoreWhitespace {
+		return staged, true
+	}
+
+	filtered, report := diff.FilterNoise(staged)
+	if report.Filtered() > 0 && !isJSONOutput(cmd) {
+		fmt.Fprintf(os.Stderr, "Ignoring %d whitespace-only, %d reformat-only and %d moved hunk(s)\n",
+			report.Whitespace, report.Reformat, report.Move)
+	}
+	if filtered.IsEmpty() {
+		return nil, false
+	}
+	return filtered, true
+}
+
+// reviewLimiter returns the limiter for review.concurrency and review.pacing,
+// or nil if reviews are not limited
+func reviewLimiter(cfg *config.Config) *review.Limiter {
+	return review.NewLimiter(cfg.Review.Concurrency, cfg.Review.Pacing)
+}
+
+// contentFilter returns the files whose content is left out of staged diffs
+// under the diff settings
+func contentFilter(cfg *config.Config) git.ContentFilter {
+	return git.ContentFilter{
+		Binary:    cfg.Diff.OmitBinary,
+		Lockfiles: cfg.Diff.OmitLockfiles,
+		Generated: cfg.Diff.OmitGenerated,
+		Paths:     cfg.Diff.OmitPaths,
+	}
+}
+
+// modeTimeout returns the longest one review mode may run under
+// review.timeout_seconds, or 0 if there is no limit
+func modeTimeout(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.Review.TimeoutSeconds) * time.Second
+}
+
+// newReviewClient creates an AI client for reviews with model, normalizing issue
+// severities with review.severity_map on top of the default mapping
+func newReviewClient(cfg *config.Config, model string) (*ai.Client, error) {
+	severities, err := review.NewSeverityNormalizer(cfg.Review.SeverityMap)
+	if err != nil {
+		return nil, withCode(CodeInvalidInput, fmt.Errorf("invalid review.severity_map: %w", err))
+	}
+	guidance, err := promptGuidance(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ai.NewClient(model)
+	if err != nil {
+		return nil, err
+	}
+	client.SetSeverityNormalizer(severities)
+	client.SetRetryPolicy(retryPolicy(cfg))
+	client.SetMaxSuggestions(cfg.Review.MaxSuggestions)
+	client.SetMinConfidence(cfg.Review.MinConfidence)
+	client.SetOffline(cfg.Review.Offline)
+	client.SetRedactSecrets(cfg.AI.RedactSecrets)
+	client.SetMaxDiffSize(cfg.AI.MaxDiffChars)
+	client.SetGuidance(guidance)
+	setTranscript(client)
+	root := currentRepoRoot()
+	client.SetRepoRoot(root)
+	if root != "" {
+		module, err := review.LoadGoModule(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: reviewing without Go module context: %v\n", err)
+		}
+		client.SetGoModule(module)
+	}
+	return client, nil
+}
+
+// retryPolicy returns how AI calls are retried under ai.retry
+func retryPolicy(cfg *config.Config) ai.RetryPolicy {
+	return ai.RetryPolicy{
+		RateLimitRetries: cfg.AI.Retry.RateLimit,
+		NetworkRetries:   cfg.AI.Retry.Network,
+		ProcessRetries:   cfg.AI.Retry.Process,
+		Backoff:          cfg.AI.Retry.Backoff,
+		Delay:            cfg.AI.Retry.Delay,
+		Deadline:         cfg.AI.Retry.Deadline,
+	}
+}
+
+// setModeModels makes client review the modes listed in ai.models with their
+// own model. An explicit --model reviews every mode with that model.
+func setModeModels(cmd *cobra.Command, client *ai.Client, cfg *config.Config) error {
+	if flag := cmd.Flag("model"); flag != nil && flag.Changed {
+		return nil
+	}
+	models := make(map[review.Mode]string, len(cfg.AI.Models))
+	for name, model := range cfg.AI.Models {
+		mode := review.Mode(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(review.AllModes(), mode) {
+			return withCode(CodeInvalidInput, fmt.Errorf("invalid ai.models: unknown review mode %q", name))
+		}
+		if model = strings.TrimSpace(model); model != "" {
+			models[mode] = model
+			debugLog("Reviewing %s with %s", mode, model)
+		}
+	}
+	client.SetModeModels(models)
+	return nil
+}
+
+// currentRepoRoot returns the root of the repository in the working directory,
+// or "" outside one
+func currentRepoRoot() string {
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return ""
+	}
+	root, err := repo.Root()
+	if err != nil {
+		return ""
+	}
+	return root
+}
+
+// sampleDiff reviews only a random review.sampling fraction of the hunks outside
+// review.critical_paths, to keep the cost of reviewing high-volume repositories
+// down. Returns a nil report when sampling is disabled.
+func sampleDiff(cmd *cobra.Command, cfg *config.Config, staged *diff.Diff) (*diff.Diff, *diff.SampleReport) {
+	rate := cfg.Review.Sampling
+	if rate < 0 || rate >= 1 {
+		return staged, nil
+	}
+
+	rnd := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	sampled, report := diff.Sample(staged, rate, cfg.Review.CriticalPaths, rnd)
+	if !isJSONOutput(cmd) {
+		fmt.Fprintf(os.Stderr, "Sampling %.0f%% of low-risk hunks: reviewing %d of %d hunk(s), %d in critical paths\n",
+			rate*100, report.Reviewed, report.Hunks, report.Critical)
+	}
+	return sampled, &report
+}
+
+// runReviewTUI runs the review workflow with the interactive TUI. The lines of
+// payloadInfo, if any, are shown in a panel listing what is sent to the AI.
+func runReviewTUI(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, filter *suppress.Filter, rec *reviewRecord, rep *reviewReport, diff *diff.Diff, payloadInfo []string) error {
 	allModes, _ := cmd.Flags().GetBool("all")
 	blockOnIssues := isBlockEnabled(cmd)
 
 	// Create the TUI program
-	program := tui.NewProgram()
+	var program *tui.Program
+	if config.Get().UI.Inline {
+		program = tui.NewInlineProgram()
+	} else {
+		program = tui.NewProgram()
+	}
+	program.SetLimiter(reviewLimiter(config.Get()))
+	program.SetModeTimeout(modeTimeout(config.Get()))
+	labels, _ := severityLabels(config.Get())
+	program.SetSeverityLabels(labels)
+	program.SetStreamLines(config.Get().UI.StreamLines)
+	program.SetPayload(payloadInfo)
+	program.SetCostCheck(func(modes []review.Mode) (string, bool) {
+		est := reviewCost(config.Get(), aiClient, diff, modes)
+		return est.String(), costNeedsConfirmation(config.Get(), est)
+	})
+	journal := fix.NewJournal()
+	if repoRoot, err := repo.Root(); err == nil {
+		applier := fix.NewApplier(repoRoot)
+		applier.SetJournal(journal)
+		setFixVerifier(applier, repoRoot)
+		program.SetFixPreviewer(fixPreviewer(applier))
+		program.SetFixSplitter(applier.Split)
+		program.SetFixApplier(tracedFixApplier(ctx, applier.Apply))
+		program.SetFixUndoer(fixUndoer(applier, journal))
+	}
+	defer func() {
+		rec.setFixesApplied(journal.Len())
+		keepFixJournal(repo, journal)
+	}()
+
+	// Forward streamed review output to the progress view
+	aiClient.SetStreamCallback(func(content ai.StreamContent) {
+		program.QueueStreamContent(content.Mode, content.Content)
+	})
 
 	// Use WithClient pattern to manage SDK client lifecycle
 	// Single subprocess spawned for entire review workflow
@@ -113,14 +479,11 @@ func runReviewTUI(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client,
 		// Define mode detection function
 		detectFunc := func(ctx context.Context) ([]review.Mode, string, error) {
 			if allModes {
-				return review.AllModes(), "All modes enabled", nil
+				return withLintMode(aiClient, review.AllModes()), "All modes enabled", nil
 			}
 
 			// Create detector that uses the connected client
-			detectorFunc := func(ctx context.Context, diff string) (*review.DetectionResult, error) {
-				return aiClient.DetectModes(ctx, client, diff)
-			}
-			detector := review.NewClaudeDetector(detectorFunc)
+			detector := claudeModeDetector(aiClient, client)
 			modes, reasoning, err := detector.Detect(ctx, diff)
 			if err != nil {
 				// Fallback to heuristic
@@ -131,13 +494,34 @@ func runReviewTUI(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client,
 				}
 			}
 			modes = filterModesByFlags(cmd, modes)
-			return modes, reasoning, nil
+			return withLintMode(aiClient, modes), reasoning, nil
 		}
 
 		// Define review function that uses the connected client
-		reviewFunc := func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		reviewFunc, err := withCrossCheck(config.Get(), diff, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
 			return aiClient.RunReview(ctx, client, mode, diff)
+		})
+		if err != nil {
+			return err
+		}
+		if promote, _ := cmd.Flags().GetBool("promote-suggestions"); promote {
+			reviewFunc = withPromotedSuggestions(aiClient, client, diff, reviewFunc)
 		}
+		linker := issueLinker(config.Get(), repo)
+		reviewFunc = rep.wrap(withKnownIssues(filter, rec.wrap(withBlame(newIssueBlamer(config.Get(), repo), withIssueLinks(linker, withSuppression(filter, reviewFunc))))))
+
+		// Suggestions can be promoted to issues from the issues table
+		program.SetSuggestionPromoter(func(mode review.Mode, suggestion string) (*review.Issue, error) {
+			issue, err := aiClient.PromoteSuggestion(ctx, client, mode, diff, suggestion)
+			if err == nil && linker != nil {
+				linkIssue(linker, issue)
+			}
+			return issue, err
+		})
+
+		program.SetIssueTriager(issueTriager(repo))
+		program.SetIssueExporter(issueExporter(repo))
+		program.SetBlockThreshold(blockThreshold())
 
 		// Run the TUI workflow
 		if err := program.RunReviewOnly(ctx, detectFunc, reviewFunc, blockOnIssues); err != nil {
@@ -152,26 +536,224 @@ func runReviewTUI(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client,
 	if err != nil {
 		return err
 	}
+	if results := program.GetResults(); len(results) > 0 {
+		reportReviewStatus(repo, results, blocked)
+	}
+	if err := rep.write(rep.collected()); err != nil {
+		return err
+	}
 
 	if blocked {
-		return fmt.Errorf("high-severity issues found")
+		return blockedError(program.GetResults())
+	}
+
+	return nil
+}
+
+// withCrossCheck wraps run so that modes listed in review.cross_check.modes are
+// also reviewed with the cross-check model, and the two results are merged with
+// review.CrossCheck. Returns run unchanged if no cross-check model is configured
+// or the review is offline.
+func withCrossCheck(cfg *config.Config, diff *diff.Diff, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) (func(ctx context.Context, mode review.Mode) (*review.Result, error), error) {
+	model := cfg.Review.CrossCheck.Model
+	if model == "" || cfg.Review.Offline {
+		return run, nil
+	}
+
+	checked := make(map[review.Mode]bool)
+	for _, m := range cfg.Review.CrossCheck.Modes {
+		checked[review.Mode(strings.ToLower(strings.TrimSpace(m)))] = true
+	}
+	for mode := range checked {
+		if review.GetModeInfo(mode).Name == "" {
+			return nil, fmt.Errorf("invalid cross-check mode: %s", mode)
+		}
+	}
+
+	secondary, err := newReviewClient(cfg, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cross-check AI client: %w", err)
+	}
+
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		if !checked[mode] {
+			return run(ctx, mode)
+		}
+
+		// Run the cross-check model in its own session alongside the primary review
+		type outcome struct {
+			result *review.Result
+			err    error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			var result *review.Result
+			err := secondary.RunWithClient(ctx, func(client claudecode.Client) error {
+				var err error
+				result, err = secondary.RunReview(ctx, client, mode, diff)
+				return err
+			})
+			done <- outcome{result, err}
+		}()
+
+		primary, err := run(ctx, mode)
+		second := <-done
+		if err != nil {
+			return primary, err
+		}
+		if second.err != nil {
+			// Keep the primary findings if the cross-check model fails
+			return primary, nil
+		}
+		return review.CrossCheck(primary, second.result), nil
+	}, nil
+}
+
+// runReviewJSON runs the review without interactive output and writes the results
+// to stdout as a single JSON object, or as CSV with --output csv
+func runReviewJSON(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, filter *suppress.Filter, rec *reviewRecord, rep *reviewReport, diff *diff.Diff, sampling *diff.SampleReport) error {
+	results, err := collectReviews(cmd, ctx, aiClient, issueLinker(config.Get(), repo), newIssueBlamer(config.Get(), repo), filter, rec, diff)
+	if err != nil {
+		return err
+	}
+	if err := rep.write(results); err != nil {
+		return err
+	}
+
+	blocked := review.ShouldBlockAt(results, isBlockEnabled(cmd), blockThreshold())
+	reportReviewStatus(repo, results, blocked)
+	if isCSVOutput(cmd) {
+		err = writeCSVReport(os.Stdout, results)
+	} else {
+		err = writeJSONReport(os.Stdout, results, blocked, sampling)
+	}
+	if err != nil {
+		return err
+	}
+	if ciRequested(cmd) {
+		if err := ciExitError(config.Get().CI.ExitCodes, review.Summarize(results)); err != nil && isBlockEnabled(cmd) {
+			return err
+		}
+	} else if blocked {
+		return blockedError(results)
+	}
+	if review.Summarize(results).TimedOutReviews > 0 {
+		return timeoutError(cmd)
+	}
+	return nil
+}
+
+// collectReviews detects the review modes for diff and runs them without any
+// output, returning the results. Issues link to their location with linker and
+// name its last author with blamer, either of which may be nil. With
+// --porcelain the modes and their progress are written as records, and the
+// parent process is asked about runs above review.confirm_cost; if it
+// declines, errReviewCancelled is returned.
+func collectReviews(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, linker *permalink.Builder, blamer *issueBlamer, filter *suppress.Filter, rec *reviewRecord, diff *diff.Diff) ([]*review.Result, error) {
+	var results []*review.Result
+	err := aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
+		var err error
+		results, err = runReviews(cmd, ctx, aiClient, client, linker, blamer, filter, rec, diff)
+		return err
+	})
+	// Reviews cut short by --timeout are returned as partial results
+	if err != nil && timedOut(ctx) && results == nil {
+		return nil, timeoutError(cmd)
+	}
+	if err != nil && !timedOut(ctx) {
+		return nil, err
+	}
+
+	if err := authFailure(results); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
+// authFailure returns review.ErrAuthRequired if a review failed on expired
+// credentials, so that wrappers can prompt for login
+func authFailure(results []*review.Result) error {
+	for _, r := range results {
+		if r != nil && r.Status == review.StatusFailed && r.Error == review.ErrAuthRequired.Error() {
+			return review.ErrAuthRequired
+		}
+	}
 	return nil
 }
 
+// runReviews detects the review modes for diff and runs them on the connected
+// client, as collectReviews describes. Reviews cut short by ctx return their
+// partial results.
+func runReviews(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, client claudecode.Client, linker *permalink.Builder, blamer *issueBlamer, filter *suppress.Filter, rec *reviewRecord, diff *diff.Diff) ([]*review.Result, error) {
+	allModes, _ := cmd.Flags().GetBool("all")
+
+	modes := review.AllModes()
+	if !allModes {
+		var err error
+		modes, _, err = claudeModeDetector(aiClient, client).Detect(ctx, diff)
+		if err != nil {
+			// Fallback to heuristic
+			modes, _, _ = review.NewHeuristicDetector().Detect(ctx, diff)
+		}
+		modes = filterModesByFlags(cmd, modes)
+	}
+	modes = withLintMode(aiClient, modes)
+	pw := porcelainWriter(cmd)
+	var progress review.StatusCallback
+	if pw != nil {
+		names := make([]string, len(modes))
+		for i, mode := range modes {
+			names[i] = string(mode)
+		}
+		pw.Write(porcelain.KindModes, names...)
+		progress = func(mode review.Mode, status review.Status) {
+			pw.Write(porcelain.KindProgress, string(mode), string(status))
+		}
+	}
+	// Only the parent process of --porcelain can confirm an expensive run here
+	if est := reviewCost(config.Get(), aiClient, diff, modes); costNeedsConfirmation(config.Get(), est) {
+		if pw == nil {
+			return nil, costLimitError(config.Get(), est)
+		}
+		question := fmt.Sprintf("Estimated %s, above $%.2f. Start the reviews?", est, config.Get().Review.ConfirmCost)
+		if !pw.Confirm("cost", question) {
+			return nil, errReviewCancelled
+		}
+	}
+
+	reviewFunc, err := withCrossCheck(config.Get(), diff, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		return aiClient.RunReview(ctx, client, mode, diff)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if promote, _ := cmd.Flags().GetBool("promote-suggestions"); promote {
+		reviewFunc = withPromotedSuggestions(aiClient, client, diff, reviewFunc)
+	}
+	reviewFunc = withKnownIssues(filter, rec.wrap(withBlame(blamer, withIssueLinks(linker, withSuppression(filter, reviewFunc)))))
+	runner := review.NewRunner(runnerFunc(reviewFunc), progress)
+	runner.SetLimiter(reviewLimiter(config.Get()))
+	runner.SetModeTimeout(modeTimeout(config.Get()))
+	return runner.Run(ctx, modes, diff), nil
+}
+
 // runReviewTextMode runs the review workflow with plain text output (original behavior)
-func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, diff string) error {
-	fmt.Println("revi - AI Code Review")
-	fmt.Println(strings.Repeat("-", 40))
+func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Client, repo *git.Repository, filter *suppress.Filter, rec *reviewRecord, rep *reviewReport, diff *diff.Diff) error {
+	// --ci prints one line per issue instead of the full report
+	compact := ciRequested(cmd)
+	if !compact {
+		fmt.Println("revi - AI Code Review")
+		printRule("-")
 
-	// Detect review modes
-	fmt.Println("\nAnalyzing diff...")
+		// Detect review modes
+		fmt.Println("\nAnalyzing diff...")
+	}
 
 	var modes []review.Mode
 	var reasoning string
 	var results []*review.Result
 	var runErr error
+	var cancelled bool
 
 	allModes, _ := cmd.Flags().GetBool("all")
 
@@ -183,10 +765,7 @@ func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Cli
 			reasoning = "All modes enabled"
 		} else {
 			// Create detector that uses the connected client
-			detectorFunc := func(ctx context.Context, diff string) (*review.DetectionResult, error) {
-				return aiClient.DetectModes(ctx, client, diff)
-			}
-			detector := review.NewClaudeDetector(detectorFunc)
+			detector := claudeModeDetector(aiClient, client)
 			var err error
 			modes, reasoning, err = detector.Detect(ctx, diff)
 			if err != nil {
@@ -196,36 +775,159 @@ func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Cli
 			}
 			modes = filterModesByFlags(cmd, modes)
 		}
+		modes = withLintMode(aiClient, modes)
 
 		fmt.Printf("Detected: %s\n", reasoning)
-		fmt.Printf("Running %d review(s)...\n\n", len(modes))
+		est := reviewCost(config.Get(), aiClient, diff, modes)
+		fmt.Printf("Estimated: %s\n", est)
+		if costNeedsConfirmation(config.Get(), est) {
+			if isCI(cmd) {
+				return costLimitError(config.Get(), est)
+			}
+			if !confirmCost(os.Stdin, os.Stdout, config.Get()) {
+				cancelled = true
+				return nil
+			}
+		}
+		if compact {
+			fmt.Printf("Running %d review(s)...\n", len(modes))
+		} else {
+			fmt.Printf("Running %d review(s)...\n\n", len(modes))
+		}
 
 		// Run reviews using the connected client
+		reviewFunc, err := withCrossCheck(config.Get(), diff, func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+			return aiClient.RunReview(ctx, client, mode, diff)
+		})
+		if err != nil {
+			return err
+		}
+		if promote, _ := cmd.Flags().GetBool("promote-suggestions"); promote {
+			reviewFunc = withPromotedSuggestions(aiClient, client, diff, reviewFunc)
+		}
+		reviewFunc = withKnownIssues(filter, rec.wrap(withBlame(newIssueBlamer(config.Get(), repo), withIssueLinks(issueLinker(config.Get(), repo), withSuppression(filter, reviewFunc)))))
 		runner := review.NewRunner(
-			func(ctx context.Context, mode review.Mode, diff string) (*review.Result, error) {
-				return aiClient.RunReview(ctx, client, mode, diff)
-			},
+			runnerFunc(reviewFunc),
 			func(mode review.Mode, status review.Status) {
 				info := review.GetModeInfo(mode)
 				fmt.Printf("%s: %s\n", info.Name, status)
 			},
 		)
+		runner.SetLimiter(reviewLimiter(config.Get()))
+		runner.SetModeTimeout(modeTimeout(config.Get()))
 
 		results = runner.Run(ctx, modes, diff)
 		return nil
 	})
 
-	if err != nil {
+	// Reviews cut short by --timeout are reported as partial results
+	if err != nil && timedOut(ctx) && results == nil {
+		return timeoutError(cmd)
+	}
+	if err != nil && !timedOut(ctx) {
 		return err
 	}
 	if runErr != nil {
 		return runErr
 	}
+	if cancelled {
+		fmt.Println("Review cancelled.")
+		return nil
+	}
 
+	summary := review.Summarize(results)
+	if compact {
+		printCompactResults(results, summary)
+	} else {
+		printResults(results, summary)
+	}
+	if err := rep.write(results); err != nil {
+		return err
+	}
+	reportReviewStatus(repo, results, review.ShouldBlockAt(results, isBlockEnabled(cmd), blockThreshold()))
+
+	// Run interactive fix phase if requested
+	fixEnabled, _ := cmd.Flags().GetBool("fix")
+	fixAll, _ := cmd.Flags().GetBool("fix-all")
+	if (fixEnabled || fixAll) && summary.IssuesFound > 0 {
+		// Collect all issues from results
+		var allIssues []review.Issue
+		for _, r := range results {
+			if r != nil && len(r.Issues) > 0 {
+				allIssues = append(allIssues, r.Issues...)
+			}
+		}
+
+		if len(allIssues) > 0 {
+			// Get repository root for the applier
+			repoRoot, err := repo.Root()
+			if err != nil {
+				return fmt.Errorf("failed to get repository root: %w", err)
+			}
+
+			journal := fix.NewJournal()
+			applier := fix.NewApplier(repoRoot)
+			applier.SetJournal(journal)
+			setFixVerifier(applier, repoRoot)
+			// In CI every prompt is answered "no", so only fixes selected by
+			// fix.auto_apply are applied
+			var input io.Reader = os.Stdin
+			ci := isCI(cmd)
+			if ci {
+				input = strings.NewReader("")
+			}
+			fixer := fix.NewInteractiveFixer(input, os.Stdout, tracedFixApplier(ctx, applier.Apply))
+			fixer.SetPreviewer(fixPreviewer(applier))
+			fixer.SetSplitter(applier.Split)
+			labels, _ := severityLabels(config.Get())
+			fixer.SetSeverityLabels(labels)
+			fixer.SetPlain(config.Get().UI.ScreenReader)
+			if err := setAutoApply(fixer, results); err != nil {
+				return err
+			}
+			var stats fix.Stats
+			if fixAll {
+				stats = fix.ApplyAll(os.Stdout, allIssues, labels, tracedFixApplier(ctx, applier.Apply))
+			} else {
+				stats = fixer.Run(allIssues)
+			}
+			rec.setFixesApplied(stats.Applied)
+			keepFixJournal(repo, journal)
+
+			if err := exportFixPatches(cmd, fixer, applier, journal, repoRoot, allIssues); err != nil {
+				return err
+			}
+			if !ci {
+				if err := stageFixes(ctx, fixer, repo, journal); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Check if should block
+	blockOnIssues := isBlockEnabled(cmd)
+	if compact {
+		if err := ciExitError(config.Get().CI.ExitCodes, summary); err != nil && blockOnIssues {
+			return err
+		}
+	} else if review.ShouldBlockAt(results, blockOnIssues, blockThreshold()) {
+		return blockedError(results)
+	}
+	if summary.TimedOutReviews > 0 {
+		return timeoutError(cmd)
+	}
+
+	return nil
+}
+
+// printResults prints every review's findings followed by a summary
+func printResults(results []*review.Result, summary review.Summary) {
 	// Print results
-	fmt.Println("\n" + strings.Repeat("=", 40))
+	fmt.Println()
+	printRule("=")
 	fmt.Println("REVIEW RESULTS")
-	fmt.Println(strings.Repeat("=", 40))
+	printRule("=")
 
 	for _, r := range results {
 		if r == nil {
@@ -235,7 +937,6 @@ func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Cli
 	}
 
 	// Print summary
-	summary := review.Summarize(results)
 	fmt.Println("\n" + strings.Repeat("-", 40))
 	fmt.Println("SUMMARY")
 	fmt.Println(strings.Repeat("-", 40))
@@ -243,46 +944,311 @@ func runReviewTextMode(cmd *cobra.Command, ctx context.Context, aiClient *ai.Cli
 	fmt.Printf("Issues found:     %d\n", summary.IssuesFound)
 	if summary.IssuesFound > 0 {
 		fmt.Printf("  High severity:  %d\n", summary.HighSeverity)
+		if summary.BaselineHigh > 0 {
+			fmt.Printf("    In baseline:  %d (not blocking)\n", summary.BaselineHigh)
+		}
 		fmt.Printf("  Medium:         %d\n", summary.MediumSeverity)
 		fmt.Printf("  Low:            %d\n", summary.LowSeverity)
 	}
+	if summary.Suppressed > 0 {
+		fmt.Printf("Suppressed:       %d\n", summary.Suppressed)
+	}
+	if summary.Merged > 0 {
+		fmt.Printf("Merged:           %d (reported by more than one mode)\n", summary.Merged)
+	}
+	if summary.Unconfident > 0 {
+		fmt.Printf("Low confidence:   %d (dropped)\n", summary.Unconfident)
+	}
+	if summary.Known > 0 {
+		fmt.Printf("Already known:    %d (reported by the --baseline run)\n", summary.Known)
+	}
+	if summary.UnknownSeverity > 0 {
+		fmt.Printf("  Unrecognized:   %d (counted as %s)\n", summary.UnknownSeverity, review.UnknownSeverity)
+	}
 	if summary.FailedReviews > 0 {
 		fmt.Printf("Failed reviews:   %d\n", summary.FailedReviews)
 	}
+	if summary.TimedOutReviews > 0 {
+		fmt.Printf("Timed out:        %d (partial review)\n", summary.TimedOutReviews)
+	}
+}
 
-	// Run interactive fix phase if requested
-	fixEnabled, _ := cmd.Flags().GetBool("fix")
-	if fixEnabled && summary.IssuesFound > 0 {
-		// Collect all issues from results
-		var allIssues []review.Issue
-		for _, r := range results {
-			if r != nil && len(r.Issues) > 0 {
-				allIssues = append(allIssues, r.Issues...)
+// printCompactResults prints one line per issue and failed review, followed
+// by a one-line summary, for CI logs
+func printCompactResults(results []*review.Result, summary review.Summary) {
+	labels, _ := severityLabels(config.Get())
+	fmt.Println()
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		name := review.GetModeInfo(r.Mode).Name
+		switch r.Status {
+		case review.StatusFailed:
+			fmt.Printf("FAILED %s: %s\n", name, r.Error)
+		case review.StatusTimedOut:
+			fmt.Printf("TIMED OUT %s (partial review)\n", name)
+		}
+		for _, issue := range r.Issues {
+			loc := ""
+			if issue.Location != "" {
+				loc = " " + issue.Location
+			}
+			description, _, _ := strings.Cut(issue.Description, "\n")
+			if issue.Baseline {
+				description += " (baseline)"
+			}
+			if also := issue.AlsoReportedByNames(); also != "" {
+				description += " (also " + also + ")"
 			}
+			if issue.Speculative() {
+				description += " (speculative)"
+			}
+			fmt.Printf("%s %s%s: %s\n", labels.Label(issue.Severity), name, loc, description)
 		}
+	}
 
-		if len(allIssues) > 0 {
-			// Get repository root for the applier
-			repoRoot, err := repo.Root()
-			if err != nil {
-				return fmt.Errorf("failed to get repository root: %w", err)
+	line := fmt.Sprintf("%d issue(s) (%d high, %d medium, %d low) in %d review(s)",
+		summary.IssuesFound, summary.HighSeverity, summary.MediumSeverity, summary.LowSeverity, summary.TotalReviews)
+	if summary.FailedReviews > 0 {
+		line += fmt.Sprintf(", %d failed", summary.FailedReviews)
+	}
+	if summary.TimedOutReviews > 0 {
+		line += fmt.Sprintf(", %d timed out", summary.TimedOutReviews)
+	}
+	if summary.Suppressed > 0 {
+		line += fmt.Sprintf(", %d suppressed", summary.Suppressed)
+	}
+	if summary.Merged > 0 {
+		line += fmt.Sprintf(", %d merged", summary.Merged)
+	}
+	if summary.Unconfident > 0 {
+		line += fmt.Sprintf(", %d dropped for low confidence", summary.Unconfident)
+	}
+	if summary.Known > 0 {
+		line += fmt.Sprintf(", %d already known", summary.Known)
+	}
+	if summary.BaselineHigh > 0 {
+		line += fmt.Sprintf(", %d high in baseline", summary.BaselineHigh)
+	}
+	fmt.Println(line)
+}
+
+// fixPreviewer returns a function that renders fixes as unified diff hunks with
+// fix.preview_context unchanged lines around each change
+func fixPreviewer(applier *fix.Applier) func(*review.Fix) (string, error) {
+	contextLines := config.Get().Fix.PreviewContext
+	return func(f *review.Fix) (string, error) {
+		hunk, err := applier.PreviewHunk(f, contextLines)
+		if err != nil {
+			return "", err
+		}
+		return hunk.String(), nil
+	}
+}
+
+// severityLabels returns the labels set with ui.severity_labels
+func severityLabels(cfg *config.Config) (review.SeverityLabels, error) {
+	labels, err := review.NewSeverityLabels(cfg.UI.SeverityLabels)
+	if err != nil {
+		return nil, withCode(CodeInvalidInput, fmt.Errorf("invalid ui.severity_labels: %w", err))
+	}
+	return labels, nil
+}
+
+// printRule prints a horizontal rule of ch, except in screen reader mode,
+// which leaves out decorations
+func printRule(ch string) {
+	if config.Get().UI.ScreenReader {
+		return
+	}
+	fmt.Println(strings.Repeat(ch, 40))
+}
+
+// setFixVerifier makes applier run the fix.verify command in repoRoot after
+// each fix and roll back fixes it rejects
+func setFixVerifier(applier *fix.Applier, repoRoot string) {
+	cfg := config.Get().Fix
+	if strings.TrimSpace(cfg.Verify) == "" {
+		return
+	}
+	applier.SetVerifier(fix.CommandVerifier(repoRoot, cfg.Verify, cfg.VerifyTimeout))
+}
+
+// setAutoApply makes fixer apply the fixes selected by fix.auto_apply without
+// prompting. Issues carry no mode, so fixes are matched to their review by pointer.
+func setAutoApply(fixer *fix.InteractiveFixer, results []*review.Result) error {
+	policy, err := fix.NewPolicy(config.Get().Fix.AutoApply)
+	if err != nil {
+		return withCode(CodeInvalidInput, fmt.Errorf("invalid fix.auto_apply: %w", err))
+	}
+	if len(policy) == 0 {
+		return nil
+	}
+
+	modes := make(map[*review.Fix]review.Mode)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			if issue.Fix != nil {
+				modes[issue.Fix] = r.Mode
 			}
+		}
+	}
+	fixer.SetAutoApply(func(issue review.Issue) bool {
+		mode, ok := modes[issue.Fix]
+		return ok && policy.Allows(mode, issue)
+	})
+	return nil
+}
 
-			applier := fix.NewApplier(repoRoot)
-			fixer := fix.NewInteractiveFixer(os.Stdin, os.Stdout, applier.Apply)
-			fixer.Run(allIssues)
+// exportFixPatches writes applied fixes and unapplied suggested fixes to patch files.
+// Paths come from --patch-out and --unapplied-patch-out; when --patch-out is not set
+// and fixes were applied, the user is asked whether to save them, except in CI.
+func exportFixPatches(cmd *cobra.Command, fixer *fix.InteractiveFixer, applier *fix.Applier, journal *fix.Journal, repoRoot string, issues []review.Issue) error {
+	appliedPath, _ := cmd.Flags().GetString("patch-out")
+	unappliedPath, _ := cmd.Flags().GetString("unapplied-patch-out")
+
+	if appliedPath == "" && journal.Len() > 0 && !isCI(cmd) {
+		appliedPath = fixer.Ask("\nSave applied fixes as a patch file? Enter a path (leave empty to skip): ")
+	}
+
+	if appliedPath != "" && journal.Len() > 0 {
+		if err := writePatchFile(appliedPath, func(f *os.File) error {
+			return journal.WritePatch(f, repoRoot)
+		}); err != nil {
+			return fmt.Errorf("failed to write applied fixes patch: %w", err)
 		}
+		fmt.Printf("Wrote %d applied fix(es) to %s\n", journal.Len(), appliedPath)
 	}
 
-	// Check if should block
-	blockOnIssues := isBlockEnabled(cmd)
-	if review.ShouldBlock(results, blockOnIssues) {
-		return fmt.Errorf("high-severity issues found")
+	if unappliedPath == "" {
+		return nil
+	}
+
+	applied := make(map[*review.Fix]bool)
+	for _, e := range journal.Entries() {
+		applied[e.Fix] = true
+	}
+	var unapplied []*review.Fix
+	for i := range issues {
+		if f := issues[i].Fix; f != nil && f.Available && !applied[f] {
+			unapplied = append(unapplied, f)
+		}
+	}
+
+	var skipped []error
+	if err := writePatchFile(unappliedPath, func(f *os.File) error {
+		skipped = applier.SuggestedPatch(f, unapplied)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write unapplied fixes patch: %w", err)
+	}
+	for _, e := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipped fix in patch: %v\n", e)
+	}
+	fmt.Printf("Wrote unapplied fixes to %s\n", unappliedPath)
+
+	return nil
+}
+
+// stageFixes offers to stage the files changed by applied fixes so they are part
+// of the pending commit, or to stage them and amend the last commit. Amending is
+// only offered when nothing else is staged, so it cannot pick up unrelated changes.
+func stageFixes(ctx context.Context, fixer *fix.InteractiveFixer, repo *git.Repository, journal *fix.Journal) error {
+	paths := journal.Paths()
+	if len(paths) == 0 {
+		return nil
+	}
+
+	staged, err := repo.HasStagedChanges()
+	if err != nil {
+		return err
+	}
+	subject, headErr := repo.HeadSubject()
+	canAmend := !staged && headErr == nil
+
+	fmt.Printf("\nApplied fixes changed %d file(s); they are not staged yet.\n", len(paths))
+	question := "Stage them for the pending commit? [y/N] "
+	if canAmend {
+		fmt.Println("  [s] stage them for the next commit")
+		fmt.Printf("  [a] stage them and amend the last commit (%s)\n", subject)
+		fmt.Println("  [n] leave them unstaged")
+		question = "Choice [s/a/N]: "
 	}
 
+	amend := false
+	switch strings.ToLower(fixer.Ask(question)) {
+	case "y", "yes", "s", "stage":
+	case "a", "amend":
+		if !canAmend {
+			return nil
+		}
+		amend = true
+	default:
+		return nil
+	}
+
+	if err := repo.StageFiles(paths); err != nil {
+		return err
+	}
+	if !amend {
+		fmt.Printf("Staged %d file(s).\n", len(paths))
+		return nil
+	}
+
+	_, span := telemetry.Start(ctx, "revi.commit", attribute.Bool("revi.amend", true))
+	hash, err := repo.AmendHead()
+	telemetry.End(span, err)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Amended commit: %s\n", shortHash(hash))
 	return nil
 }
 
+// writePatchFile creates path and passes it to write, closing it afterwards.
+func writePatchFile(path string, write func(f *os.File) error) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	return write(f)
+}
+
+// modeDetector returns detector narrowed to the relevant modes for doc-only
+// and test-only diffs, unless review.smart_skip is off. The secrets mode is
+// added whenever the local scanner finds a possible secret.
+func modeDetector(detector review.Detector) review.Detector {
+	if config.Get().Review.SmartSkip {
+		detector = review.NewSmartSkipDetector(detector)
+	}
+	return review.NewSecretsDetector(detector)
+}
+
+// claudeModeDetector returns the mode detector of modeDetector asking
+// aiClient on the connected client which modes a diff needs
+func claudeModeDetector(aiClient *ai.Client, client claudecode.Client) review.Detector {
+	return modeDetector(review.NewClaudeDetector(func(ctx context.Context, diff *diff.Diff) (*review.DetectionResult, error) {
+		return aiClient.DetectModes(ctx, client, diff)
+	}))
+}
+
+// runnerFunc adapts run, which reviews the diff given to the runner already,
+// to a review.ReviewFunc
+func runnerFunc(run func(ctx context.Context, mode review.Mode) (*review.Result, error)) review.ReviewFunc {
+	return func(ctx context.Context, mode review.Mode, _ *diff.Diff) (*review.Result, error) {
+		return run(ctx, mode)
+	}
+}
+
 func filterModesByFlags(cmd *cobra.Command, detected []review.Mode) []review.Mode {
 	enabled := make(map[review.Mode]bool)
 	disabled := make(map[review.Mode]bool)
@@ -306,6 +1272,9 @@ func filterModesByFlags(cmd *cobra.Command, detected []review.Mode) []review.Mod
 	if docs, _ := cmd.Flags().GetBool("docs"); docs {
 		enabled[review.ModeDocs] = true
 	}
+	if secrets, _ := cmd.Flags().GetBool("secrets"); secrets {
+		enabled[review.ModeSecrets] = true
+	}
 
 	// Check disabled flags
 	if noSec, _ := cmd.Flags().GetBool("no-security"); noSec {
@@ -326,25 +1295,68 @@ func filterModesByFlags(cmd *cobra.Command, detected []review.Mode) []review.Mod
 	if noDocs, _ := cmd.Flags().GetBool("no-docs"); noDocs {
 		disabled[review.ModeDocs] = true
 	}
+	if noSecrets, _ := cmd.Flags().GetBool("no-secrets"); noSecrets {
+		disabled[review.ModeSecrets] = true
+	}
 
 	return review.FilterModes(detected, enabled, disabled)
 }
 
+// isBlockEnabled reports whether issues fail the run: --no-block and --block
+// win over the block rule for the current branch
 func isBlockEnabled(cmd *cobra.Command) bool {
 	noBlock, _ := cmd.Flags().GetBool("no-block")
 	if noBlock {
 		return false
 	}
+	if rules := config.AppliedBranchRules(); rules.Block != nil && !cmd.Flags().Changed("block") {
+		return *rules.Block
+	}
 	block, _ := cmd.Flags().GetBool("block")
 	return block
 }
 
+// blockThreshold returns the lowest severity whose issues fail the run: the
+// block_threshold rule for the current branch, or review.block_threshold
+func blockThreshold() string {
+	threshold := config.AppliedBranchRules().BlockThreshold
+	if threshold == "" {
+		threshold = config.Get().Review.BlockThreshold
+	}
+	if threshold = strings.ToLower(strings.TrimSpace(threshold)); threshold != "" {
+		return threshold
+	}
+	return review.SeverityHigh
+}
+
+// blockedError reports the issues of results that fail the run
+func blockedError(results []*review.Result) error {
+	return withCode(CodeBlocked, errors.New(review.GetBlockReasonAt(results, blockThreshold())))
+}
+
 func printReviewResult(r *review.Result) {
 	info := review.GetModeInfo(r.Mode)
-	fmt.Printf("\n=== %s Review ===\n", info.Name)
+	if config.Get().UI.ScreenReader {
+		fmt.Printf("\n%s review\n", info.Name)
+	} else {
+		fmt.Printf("\n=== %s Review ===\n", info.Name)
+	}
+	// Invalid labels are reported before reviewing; elsewhere they fall back
+	// to the level names
+	labels, _ := severityLabels(config.Get())
 
 	if r.Status == review.StatusFailed {
 		fmt.Printf("Status: FAILED (%s)\n", r.Error)
+		if r.Raw != "" {
+			fmt.Println("\nResponse:")
+			for _, line := range strings.Split(r.Raw, "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		return
+	}
+	if r.Status == review.StatusTimedOut {
+		fmt.Println("Status: TIMED OUT (partial review)")
 		return
 	}
 
@@ -365,8 +1377,30 @@ func printReviewResult(r *review.Result) {
 			if issue.Location != "" {
 				loc = fmt.Sprintf(" (%s)", issue.Location)
 			}
-			fmt.Printf("  - [%s] %s%s\n",
-				strings.ToUpper(issue.Severity), issue.Description, loc)
+			badge := ""
+			if issue.Agreement != "" {
+				badge = fmt.Sprintf(" {%s}", strings.ReplaceAll(issue.Agreement, "_", " "))
+			}
+			if issue.SeverityUnknown {
+				badge += fmt.Sprintf(" (unrecognized severity %q)", issue.RawSeverity)
+			}
+			if issue.Baseline {
+				badge += " (baseline)"
+			}
+			if also := issue.AlsoReportedByNames(); also != "" {
+				badge += " (also " + also + ")"
+			}
+			if issue.Speculative() {
+				badge += fmt.Sprintf(" (speculative, %s confidence)", issue.ConfidenceLabel())
+			}
+			fmt.Printf("  - [%s] %s%s%s\n",
+				labels.Label(issue.Severity), issue.Description, loc, badge)
+			if issue.URL != "" {
+				fmt.Printf("    %s\n", issue.URL)
+			}
+			if issue.Author != "" {
+				fmt.Printf("    Last changed by %s\n", issue.Author)
+			}
 		}
 	}
 
@@ -5,15 +5,26 @@ package cli
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/commit"
 	"github.com/buker/revi/internal/config"
 	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/lock"
+	"github.com/buker/revi/internal/porcelain"
+	"github.com/buker/revi/internal/telemetry"
+	"github.com/buker/revi/internal/tui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,9 +33,18 @@ var (
 	// Version is set at build time via -ldflags
 	Version = "dev"
 
+	// Commit is the git commit the binary was built from, set via -ldflags
+	Commit = ""
+
+	// Date is the build time in RFC 3339 format, set via -ldflags
+	Date = ""
+
 	// debug controls debug logging output
 	debug bool
 
+	// configPath is the --config file read instead of searching for .revi.yaml
+	configPath string
+
 	rootCmd = &cobra.Command{
 		Use:   "revi",
 		Short: "AI-powered commit message generator",
@@ -33,29 +53,78 @@ var (
 Usage:
   revi           Generate commit message and commit
   revi review    Run AI code reviews on staged changes`,
-		RunE: runFullWorkflow,
+		PersistentPreRunE: preRun,
+		RunE:              runFullWorkflow,
 	}
 )
 
 func init() {
-	cobra.OnInitialize(config.Init)
+	cobra.OnInitialize(initConfig)
 
 	// Persistent flags available to all commands
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file to use instead of searching for .revi.yaml")
 	rootCmd.PersistentFlags().String("model", "", "AI model to use (default: claude-opus-4-5-20251101)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug logging")
+	rootCmd.PersistentFlags().StringP("output", "o", outputText, "Output format: text, json or csv")
+	rootCmd.PersistentFlags().Bool("force-unlock", false, "Remove the repository lock left by another revi run")
+	rootCmd.PersistentFlags().Bool("ci", false, "Never prompt or start the TUI (detected from CI environment variables by default)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Time limit for the whole run, e.g. 5m; reviews still running are reported as timed out (0 for no limit)")
+	rootCmd.PersistentFlags().Int("rate-limit-retries", 3, "Retries of an AI call after a rate limit")
+	rootCmd.PersistentFlags().Duration("retry-backoff", time.Second, "First backoff after a rate limit, doubled on each retry")
+	rootCmd.PersistentFlags().Duration("retry-deadline", 0, "Longest one AI call may spend retrying, e.g. 1m (0 for no limit)")
 
 	// Root command flags
-	rootCmd.Flags().BoolP("dry-run", "n", false, "Preview commit message without committing")
+	rootCmd.Flags().BoolP("dry-run", "n", false, "Show the files, review modes, estimated cost and commit message without committing")
 	rootCmd.Flags().StringP("message", "m", "", "Context explaining why this change was made")
+	rootCmd.Flags().BoolP("yes", "y", false, "Commit without asking for confirmation")
+	rootCmd.Flags().Bool("no-verify", false, "Skip the pre-commit and commit-msg hooks")
+	rootCmd.Flags().Bool("fast", false, "Generate the message in a single AI call, truncating large diffs instead of summarizing them")
+	rootCmd.Flags().Bool("show-payload", false, "List what will be sent to the AI and ask before sending it")
+	addSourceFlags(rootCmd)
+	addPorcelainFlag(rootCmd)
 
 	// Bind persistent flags to viper
 	_ = viper.BindPFlag("ai.model", rootCmd.PersistentFlags().Lookup("model"))
+	_ = viper.BindPFlag("ai.retry.rate_limit", rootCmd.PersistentFlags().Lookup("rate-limit-retries"))
+	_ = viper.BindPFlag("ai.retry.backoff", rootCmd.PersistentFlags().Lookup("retry-backoff"))
+	_ = viper.BindPFlag("ai.retry.deadline", rootCmd.PersistentFlags().Lookup("retry-deadline"))
 
 	// Add subcommands
 	rootCmd.AddCommand(reviewCmd)
 	rootCmd.AddCommand(commitCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(mrCmd)
+	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(baselineCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(stageCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(transcriptCmd)
+}
+
+// initConfig loads the configuration, from the --config file if one is given,
+// and applies the branches rules for the current branch
+func initConfig() {
+	config.SetConfigFile(configPath)
+	config.Init()
+
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return
+	}
+	branch, err := repo.CurrentBranch()
+	if err != nil {
+		return
+	}
+	if pattern := config.ApplyBranchRules(branch); pattern != "" {
+		debugLog("Applying the branches rules for %s to %s", pattern, branch)
+	}
 }
 
 // debugLog prints a debug message if debug mode is enabled
@@ -65,24 +134,150 @@ func debugLog(format string, args ...interface{}) {
 	}
 }
 
+// acquireRepoLock takes the per-repository lock, removing any existing lock first
+// if --force-unlock is set. The returned function releases the lock.
+func acquireRepoLock(cmd *cobra.Command, repo *git.Repository) (func(), error) {
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+
+	if force, _ := cmd.Flags().GetBool("force-unlock"); force {
+		if err := lock.ForceUnlock(gitDir); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := lock.Acquire(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := l.Release(); err != nil {
+			debugLog("failed to release lock: %v", err)
+		}
+	}, nil
+}
+
+// workflowContext returns the context bounding a run's AI and network calls,
+// with the --timeout deadline if one is set
+func workflowContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	// The command's context carries the run's root span
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// timedOut returns true if ctx ended because the --timeout deadline passed
+func timedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// timeoutError reports that the --timeout budget ran out before the run finished
+func timeoutError(cmd *cobra.Command) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	return withCode(CodeTimedOut, fmt.Errorf("partial review (timed out after %s)", timeout))
+}
+
+// preRun validates global flags before any command runs and starts the run's
+// root span, which Execute ends. In JSON output mode and with --porcelain
+// cobra's own error and usage printing is disabled, since Execute reports
+// errors as JSON or as an error record instead.
+func preRun(cmd *cobra.Command, args []string) error {
+	ctx := telemetry.ParentFromEnv(cmd.Context())
+	ctx, _ = telemetry.Start(ctx, cmd.CommandPath(), attribute.String("revi.version", Version))
+	cmd.SetContext(ctx)
+
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	if format == outputJSON {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	if on, _ := cmd.Flags().GetBool("porcelain"); on {
+		if format != outputText {
+			return withCode(CodeInvalidInput, fmt.Errorf("--porcelain cannot be combined with --output %s", format))
+		}
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		// The version record comes first
+		porcelainWriter(cmd)
+	}
+	// The config commands still run with an invalid config file, to fix it
+	if err := config.LoadError(); err != nil && !(errors.Is(err, config.ErrInvalidConfig) && cmd.Parent() == configCmd) {
+		return withCode(CodeInvalidInput, err)
+	}
+	return nil
+}
+
 // Execute runs the root command and returns any error encountered.
 // This is the main entry point for the CLI application.
+// With --output json, errors are written to stderr as JSON objects with an error code,
+// and with --porcelain as an error record on stdout.
 func Execute() error {
-	return rootCmd.Execute()
+	shutdown, err := telemetry.Init(context.Background(), Version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tracing disabled: %v\n", err)
+	}
+	defer flushTraces(shutdown)
+
+	cmd, err := rootCmd.ExecuteC()
+	closeTranscript()
+	if ctx := cmd.Context(); ctx != nil {
+		telemetry.End(trace.SpanFromContext(ctx), err)
+	}
+	if err != nil && isJSONOutput(cmd) {
+		writeJSONError(os.Stderr, err)
+	}
+	if err != nil && porcelainOut != nil {
+		porcelainOut.Write(porcelain.KindError, string(errorCode(err)), err.Error())
+	}
+	return err
+}
+
+// flushTraces exports the spans still buffered, giving up after a few seconds
+// so an unreachable collector cannot hold up the exit
+func flushTraces(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		debugLog("failed to export traces: %v", err)
+	}
 }
 
 func runFullWorkflow(cmd *cobra.Command, args []string) error {
 	debugLog("Starting runFullWorkflow")
-	ctx := context.Background()
+	ctx, cancel := workflowContext(cmd)
+	defer cancel()
 	cfg := config.Get()
 	debugLog("Config loaded: model=%s", cfg.AI.Model)
 
 	// Initialize AI client wrapper with model configuration
 	debugLog("Initializing AI client...")
+	guidance, err := promptGuidance(cfg)
+	if err != nil {
+		return err
+	}
 	aiClient, err := ai.NewClient(cfg.AI.Model)
 	if err != nil {
 		return fmt.Errorf("failed to initialize AI client: %w", err)
 	}
+	aiClient.SetGuidance(guidance)
+	aiClient.SetRetryPolicy(retryPolicy(cfg))
+	aiClient.SetRedactSecrets(cfg.AI.RedactSecrets)
+	aiClient.SetMaxDiffSize(cfg.AI.MaxDiffChars)
+	setTranscript(aiClient)
+	if err := setModeModels(cmd, aiClient, cfg); err != nil {
+		return err
+	}
 	debugLog("AI client initialized")
 
 	// Open git repository
@@ -91,37 +286,208 @@ func runFullWorkflow(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
+	repo.SetContentFilter(contentFilter(config.Get()))
+	repo.SetSyntaxContext(config.Get().Review.SyntaxContext)
 	debugLog("Git repository opened")
 
-	// Check for staged changes
-	debugLog("Checking for staged changes...")
-	hasStagedChanges, err := repo.HasStagedChanges()
+	// Prevent concurrent runs from committing at the same time
+	release, err := acquireRepoLock(cmd, repo)
 	if err != nil {
-		return fmt.Errorf("failed to check staged changes: %w", err)
-	}
-	if !hasStagedChanges {
-		return fmt.Errorf("no staged changes found. Use 'git add' to stage files")
+		return err
 	}
-	debugLog("Staged changes found")
+	defer release()
 
-	// Get staged diff
-	debugLog("Getting staged diff...")
-	diff, err := repo.GetStagedDiff()
+	// Get the diff from the selected source (staged changes by default)
+	src, kind, err := selectSource(cmd, repo)
 	if err != nil {
-		return fmt.Errorf("failed to get staged diff: %w", err)
+		return err
+	}
+	debugLog("Getting diff from %s...", src.Describe())
+	diff, err := sourceDiff(src)
+	if err != nil {
+		return err
+	}
+	debugLog("Diff retrieved (%d file(s))", len(diff.Files))
+
+	// With --porcelain stdout carries records only
+	pw := porcelainWriter(cmd)
+
+	// Nothing reaches the AI before --show-payload has listed it
+	if !showPayload(cmd, buildPayload(aiClient, diff, diff, true)) {
+		printNote(pw, "Commit cancelled; nothing was sent.")
+		return nil
 	}
-	debugLog("Staged diff retrieved (length: %d bytes)", len(diff))
 
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	userContext, _ := cmd.Flags().GetString("message")
 
-	fmt.Println("Generating commit message...")
+	if pw == nil {
+		fmt.Println("Generating commit message...")
+	}
+
+	commitMessage, err := generateCommitMessage(ctx, aiClient, diff, userContext, config.IsFastCommitEnabled(cmd))
+	if err != nil && timedOut(ctx) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		return withCode(CodeTimedOut, fmt.Errorf("commit message generation timed out after %s", timeout))
+	}
+	if err != nil {
+		return err
+	}
+	commitMessage = addTrailers(repo, commitMessage)
+
+	// A dry run shows what the commit would involve instead of making it
+	if dryRun {
+		if pw == nil {
+			fmt.Println("Detecting review modes...")
+			fmt.Println()
+		}
+		writeCommitPlan(os.Stdout, pw, buildCommitPlan(ctx, aiClient, src.Describe(), diff, userContext, commitMessage))
+		return nil
+	}
+
+	// Display commit message
+	if pw != nil {
+		pw.Write(porcelain.KindMessage, commitMessage)
+	} else {
+		fmt.Println()
+		fmt.Println(strings.Repeat("-", 40))
+		fmt.Println("Commit message:")
+		fmt.Println()
+		fmt.Println("  " + strings.ReplaceAll(commitMessage, "\n", "\n  "))
+		fmt.Println()
+		fmt.Println(strings.Repeat("-", 40))
+	}
+
+	// Only the staged index can be committed
+	if !kind.Committable {
+		printNote(pw, fmt.Sprintf("Message generated for %s; commit not created.", src.Describe()))
+		return nil
+	}
+
+	// Ask for confirmation unless auto-confirm is enabled
+	autoConfirm := config.IsAutoConfirmEnabled(cmd)
+	if pw == nil && useCommitTUI(cmd) {
+		confirmed, message, err := confirmCommitTUI(autoConfirm, commitMessage, commitRegenerator(ctx, aiClient, repo, diff, userContext))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Commit cancelled.")
+			return nil
+		}
+		commitMessage = message
+	} else if autoConfirm {
+		debugLog("Auto-confirm enabled, skipping confirmation prompt")
+	} else if pw != nil {
+		if !pw.Confirm("commit", "Proceed with commit?") {
+			printNote(pw, "Commit cancelled.")
+			return nil
+		}
+	} else if isCI(cmd) {
+		fmt.Println("Running in CI; commit not created. Pass --yes to commit without confirmation.")
+		return nil
+	} else {
+		fmt.Print("\nProceed with commit? [y/N] ")
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+
+		if response != "y" && response != "yes" {
+			fmt.Println("Commit cancelled.")
+			return nil
+		}
+	}
+
+	// Create the commit, or replace the last one
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	repo.SetSkipHooks(noVerify)
+	_, span := telemetry.Start(ctx, "revi.commit")
+	var hash string
+	if kind.Amends {
+		hash, err = repo.AmendHeadMessage(commitMessage)
+	} else {
+		hash, err = repo.Commit(commitMessage)
+	}
+	telemetry.End(span, err)
+	if err != nil && kind.Amends {
+		return fmt.Errorf("failed to amend commit: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if pw != nil {
+		pw.Write(porcelain.KindCommit, hash)
+	} else if kind.Amends {
+		fmt.Printf("Amended commit: %s\n", shortHash(hash))
+	} else {
+		fmt.Printf("Created commit: %s\n", shortHash(hash))
+	}
+	linkReviewedCommit(repo, diff, hash)
+	return nil
+}
+
+// useCommitTUI reports whether the commit is confirmed on the TUI confirm
+// screen rather than with a text prompt: only when a user at a terminal can
+// drive it
+func useCommitTUI(cmd *cobra.Command) bool {
+	return !isCI(cmd) && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// confirmCommitTUI shows message on the TUI commit confirm screen and returns
+// whether the user committed, and the message as they left it. regenerate
+// generates the message again when the user asks with feedback. autoConfirm
+// accepts the message without showing the screen.
+func confirmCommitTUI(autoConfirm bool, message string, regenerate tui.CommitRegenerator) (bool, string, error) {
+	var program *tui.Program
+	if config.Get().UI.Inline {
+		program = tui.NewInlineProgram()
+	} else {
+		program = tui.NewProgram()
+	}
+	program.SetAutoConfirm(autoConfirm)
+	program.SetCommitRegenerator(regenerate)
+	if err := program.RunCommitConfirm(message); err != nil {
+		return false, "", fmt.Errorf("TUI error: %w", err)
+	}
+	return program.IsConfirmed(), program.GetCommitMessage(), nil
+}
+
+// commitRegenerator returns the function the commit confirm screen uses to
+// generate the message for diff again, revised as the user's feedback asks.
+// Unlike generateCommitMessage it does not fall back to a message built
+// without AI, so a failure is shown on the screen and the message kept.
+func commitRegenerator(ctx context.Context, aiClient *ai.Client, repo *git.Repository, diff *diff.Diff, userContext string) tui.CommitRegenerator {
+	generator := commit.NewGenerator(aiClient)
+	return func(message, feedback string) (string, error) {
+		var regenerated string
+		err := aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
+			msg, err := generator.Regenerate(ctx, client, diff, userContext, message, feedback)
+			if err != nil {
+				return fmt.Errorf("failed to regenerate commit message: %w", err)
+			}
+			regenerated = addTrailers(repo, msg.String())
+			return nil
+		})
+		return regenerated, err
+	}
+}
+
+// generateCommitMessage asks the AI for a conventional commit message for diff.
+// userContext explains why the change was made and may be empty. Diffs too
+// large to send whole are summarized per file with commit.summary_model first.
+// If the AI backend fails, the message is built from the diff's file list
+// instead, unless commit.fallback is off. fast skips the summaries, so the
+// message takes a single AI call.
+func generateCommitMessage(ctx context.Context, aiClient *ai.Client, diff *diff.Diff, userContext string, fast bool) (string, error) {
+	aiClient.SetSummaryModel(config.Get().Commit.SummaryModel)
+	aiClient.SetSkipSummaries(fast)
 
 	// Use WithClient pattern to manage SDK client lifecycle
 	// Single subprocess spawned for entire workflow, automatically cleaned up
 	var commitMessage string
 	debugLog("Calling aiClient.RunWithClient...")
-	err = aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
+	err := aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
 		debugLog("Inside RunWithClient callback")
 		// Generate commit message with connected client
 		debugLog("Calling GenerateCommitMessage...")
@@ -138,43 +504,63 @@ func runFullWorkflow(cmd *cobra.Command, args []string) error {
 
 	if err != nil {
 		debugLog("RunWithClient returned error: %v", err)
-		return err
+		// A cancelled or timed-out run is not the backend's fault
+		if ctx.Err() != nil || !config.Get().Commit.Fallback {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\nUsing a commit message generated without AI.\n", err)
+		return commit.FallbackMessage(diff, userContext).String(), nil
 	}
 	debugLog("RunWithClient completed successfully")
+	return commitMessage, nil
+}
 
-	// Display commit message
-	fmt.Println()
-	fmt.Println(strings.Repeat("-", 40))
-	fmt.Println("Commit message:")
-	fmt.Println()
-	fmt.Println("  " + strings.ReplaceAll(commitMessage, "\n", "\n  "))
-	fmt.Println()
-	fmt.Println(strings.Repeat("-", 40))
-
-	// Ask for confirmation
-	fmt.Print("\nProceed with commit? [y/N] ")
-	reader := bufio.NewReader(os.Stdin)
-	response, _ := reader.ReadString('\n')
-	response = strings.TrimSpace(strings.ToLower(response))
-
-	if response != "y" && response != "yes" {
-		fmt.Println("Commit cancelled.")
-		return nil
+// addTrailers appends the trailers commitTrailers returns for the current
+// branch to message
+func addTrailers(repo *git.Repository, message string) string {
+	branch, err := repo.CurrentBranch()
+	if err != nil {
+		debugLog("no branch for issue trailers: %v", err)
 	}
+	return commit.AddTrailers(message, commitTrailers(config.Get().Commit, branch, repo.Mailmap()))
+}
 
-	if dryRun {
-		fmt.Println("Dry run - commit not created.")
-		return nil
+// commitTrailers returns commit.trailers, with identities such as those of
+// Co-authored-by and Reviewed-by resolved through mailmap, followed by a
+// commit.issue_trailer trailer for each issue ID commit.issue_pattern finds
+// in branch, such as "Refs: PROJ-123" on feature/PROJ-123-login
+func commitTrailers(cfg config.CommitConfig, branch string, mailmap *git.Mailmap) []string {
+	var trailers []string
+	for _, trailer := range cfg.Trailers {
+		trailers = append(trailers, canonicalTrailer(trailer, mailmap))
 	}
-
-	// Create the commit
-	hash, err := repo.Commit(commitMessage)
+	if cfg.IssueTrailer == "" || branch == "" {
+		return trailers
+	}
+	pattern, err := regexp.Compile(cfg.IssuePattern)
 	if err != nil {
-		return fmt.Errorf("failed to create commit: %w", err)
+		debugLog("no issue trailers: invalid commit.issue_pattern: %v", err)
+		return trailers
+	}
+	for _, id := range commit.IssueIDs(branch, pattern) {
+		trailers = append(trailers, cfg.IssueTrailer+": "+id)
 	}
+	return trailers
+}
 
-	fmt.Printf("Created commit: %s\n", shortHash(hash))
-	return nil
+// canonicalTrailer returns trailer with its value resolved through mailmap if
+// it is an identity, "Name <email>", and unchanged otherwise
+func canonicalTrailer(trailer string, mailmap *git.Mailmap) string {
+	key, value, ok := strings.Cut(trailer, ":")
+	if !ok {
+		return trailer
+	}
+	value = strings.TrimSpace(value)
+	resolved := mailmap.ResolveIdentity(value)
+	if resolved == value {
+		return trailer
+	}
+	return key + ": " + resolved
 }
 
 // shortHash returns a shortened version of a git hash (first 8 chars).
@@ -185,11 +571,3 @@ func shortHash(hash string) string {
 	}
 	return hash
 }
-
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print the version number",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("revi version %s\n", Version)
-	},
-}
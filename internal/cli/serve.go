@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/lsp"
+	"github.com/buker/revi/internal/review"
+)
+
+func init() {
+	serveCmd.Flags().Bool("lsp", false, "Speak the Language Server Protocol over stdin and stdout")
+	serveCmd.Flags().Bool("staged", false, "Review the staged changes instead of the working tree")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve --lsp",
+	Short: "Show review findings in your editor",
+	Long: `Run revi as a language server, so that editors such as VS Code and Neovim
+show the issues of revi's reviews inline.
+
+The working tree's changes are reviewed when the editor connects, each time a
+file is saved, and when the editor runs the revi.review command. Issues are
+published as diagnostics of the files they are in, high-severity issues as
+errors, and available fixes are offered as quick fixes until the file is
+edited. Each review runs "revi review" in the repository, so its .revi.yaml
+and prompt guidance apply, and costs as much as running it by hand.
+
+Neovim, for example:
+
+  vim.lsp.start({ name = "revi", cmd = { "revi", "serve", "--lsp" } })`,
+	RunE: runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if useLSP, _ := cmd.Flags().GetBool("lsp"); !useLSP {
+		return withCode(CodeInvalidInput, errors.New("revi serve needs a protocol to speak: pass --lsp"))
+	}
+	reviewArgs, err := forwardFlags(cmd, batchGlobalFlags)
+	if err != nil {
+		return err
+	}
+	source := "--working-tree"
+	if staged, _ := cmd.Flags().GetBool("staged"); staged {
+		source = "--staged"
+	}
+	reviewArgs = append(reviewArgs, source, "--no-block")
+
+	server := lsp.NewServer(func(ctx context.Context, root string) ([]*review.Result, error) {
+		debugLog("Running revi review %v in %s", reviewArgs, root)
+		outcome := execReview(ctx, root, reviewArgs)
+		if outcome.Error != nil {
+			return nil, errors.New(outcome.Error.Message)
+		}
+		return outcome.Results, nil
+	}, currentRepoRoot())
+	server.SetRootResolver(repoRootOf)
+	server.SetVersion(Version)
+
+	// stdout carries the protocol, so nothing else may be printed to it
+	if err := server.Serve(cmd.Context(), os.Stdin, os.Stdout); err != nil {
+		return fmt.Errorf("language server stopped: %w", err)
+	}
+	return nil
+}
+
+// repoRootOf returns the root of the repository at dir, since issue
+// locations are relative to it
+func repoRootOf(dir string) (string, error) {
+	repo, err := git.Open(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository at %s: %w", dir, err)
+	}
+	return repo.Root()
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/source"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// snapshotSource is the diff source reviewed by "revi snapshot review"
+const snapshotSource = "snapshot"
+
+func init() {
+	snapshotCmd.AddCommand(snapshotBeginCmd)
+	snapshotCmd.AddCommand(snapshotReviewCmd)
+}
+
+// addSnapshotReviewFlags shares the review command's flags, except for the
+// diff source, which is always the snapshot. It is called once the review
+// command's flags are defined.
+func addSnapshotReviewFlags() {
+	otherSources := make(map[string]bool)
+	for _, kind := range source.Kinds() {
+		for _, name := range kind.FlagNames() {
+			otherSources[name] = kind.Name != snapshotSource
+		}
+	}
+	reviewCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !otherSources[flag.Name] {
+			snapshotReviewCmd.Flags().AddFlag(flag)
+		}
+	})
+	_ = snapshotReviewCmd.Flags().MarkHidden(snapshotSource)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Review what a build or code generator changed",
+	Long: `Record the working tree before running a command, then review only what
+the command changed, regardless of what is staged:
+
+  revi snapshot begin
+  make generate
+  revi snapshot review
+
+Ignored files are not part of the snapshot. Files that were unmodified at
+"begin" are compared with the index, so avoid staging them in between.`,
+}
+
+var snapshotBeginCmd = &cobra.Command{
+	Use:   "begin",
+	Short: "Record the current state of the working tree",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := git.OpenCurrent()
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		snap, err := repo.TakeSnapshot()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recorded the working tree (%d file(s) differ from the index).\n", len(snap.Files))
+		fmt.Println(`Run your build, then "revi snapshot review" to review what it changed.`)
+		return nil
+	},
+}
+
+var snapshotReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Review the changes made since snapshot begin",
+	Long: `Review the working tree changes made since "revi snapshot begin".
+
+Accepts the same flags as "revi review", except for the diff source flags.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cmd.Flags().Set(snapshotSource, "true"); err != nil {
+			return err
+		}
+		return runReview(cmd, args)
+	},
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/source"
+	"github.com/spf13/cobra"
+)
+
+// addSourceFlags adds a flag for every registered diff source to cmd.
+// Sources are mutually exclusive; with none set the staged changes are used.
+func addSourceFlags(cmd *cobra.Command) {
+	var names []string
+	for _, kind := range source.Kinds() {
+		for _, name := range kind.FlagNames() {
+			usage := kind.Usage
+			if name != kind.Name {
+				usage = "Alias for --" + kind.Name
+			}
+			if kind.Arg == "" {
+				cmd.Flags().Bool(name, false, usage)
+			} else {
+				cmd.Flags().String(name, "", usage)
+			}
+			names = append(names, name)
+		}
+	}
+	cmd.MarkFlagsMutuallyExclusive(names...)
+}
+
+// selectSource returns the diff source chosen by cmd's source flags,
+// or the staged changes if none is set
+func selectSource(cmd *cobra.Command, repo *git.Repository) (source.Source, source.Kind, error) {
+	for _, kind := range source.Kinds() {
+		for _, name := range kind.FlagNames() {
+			flag := cmd.Flags().Lookup(name)
+			if flag == nil || !flag.Changed {
+				continue
+			}
+			if kind.Arg == "" {
+				if set, _ := cmd.Flags().GetBool(name); !set {
+					continue
+				}
+			} else if flag.Value.String() == "" {
+				return nil, source.Kind{}, withCode(CodeInvalidInput, fmt.Errorf("--%s requires a value", name))
+			}
+			return kind.New(repo, flag.Value.String()), kind, nil
+		}
+	}
+
+	kind, ok := source.Lookup(source.Default)
+	if !ok {
+		return nil, source.Kind{}, fmt.Errorf("no %s diff source registered", source.Default)
+	}
+	return kind.New(repo, ""), kind, nil
+}
+
+// sourceDiff returns the diff produced by src, parsed, marking an empty diff
+// with CodeNoChanges
+func sourceDiff(src source.Source) (*diff.Diff, error) {
+	text, err := src.Diff()
+	if errors.Is(err, source.ErrNoChanges) {
+		return nil, withCode(CodeNoChanges, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return diff.NewDiff(text), nil
+}
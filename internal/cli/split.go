@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+	"github.com/spf13/cobra"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/commit"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+)
+
+func init() {
+	splitCmd.Flags().StringP("message", "m", "", "Context explaining why these changes were made")
+	splitCmd.Flags().BoolP("yes", "y", false, "Commit every group without asking for confirmation")
+	splitCmd.Flags().Bool("no-verify", false, "Skip the pre-commit and commit-msg hooks")
+	splitCmd.Flags().Bool("fast", false, "Generate each message in a single AI call, truncating large diffs instead of summarizing them")
+}
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the staged changes into several commits",
+	Long: `Ask the AI how the staged changes divide into logically separate commits,
+then commit each group of files in turn with its own generated message.
+
+Each commit is confirmed before it is made: answer y to commit the group, n to
+skip it, or q to stop. Files are committed as they are staged, so changes left
+unstaged stay in the working tree, and the changes of groups not committed are
+staged again at the end.`,
+	RunE: runSplit,
+}
+
+// splitChoice is the answer to the question of whether to commit a group
+type splitChoice int
+
+const (
+	splitSkip splitChoice = iota
+	splitCommit
+	splitQuit
+)
+
+func runSplit(cmd *cobra.Command, args []string) (err error) {
+	ctx, cancel := workflowContext(cmd)
+	defer cancel()
+	cfg := config.Get()
+
+	guidance, err := promptGuidance(cfg)
+	if err != nil {
+		return err
+	}
+	aiClient, err := ai.NewClient(cfg.AI.Model)
+	if err != nil {
+		return fmt.Errorf("failed to initialize AI client: %w", err)
+	}
+	aiClient.SetGuidance(guidance)
+	aiClient.SetRetryPolicy(retryPolicy(cfg))
+	aiClient.SetRedactSecrets(cfg.AI.RedactSecrets)
+	aiClient.SetMaxDiffSize(cfg.AI.MaxDiffChars)
+	setTranscript(aiClient)
+
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	repo.SetContentFilter(contentFilter(cfg))
+	repo.SetSyntaxContext(cfg.Review.SyntaxContext)
+
+	release, err := acquireRepoLock(cmd, repo)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	staged, err := repo.SaveStaged()
+	if err != nil {
+		return err
+	}
+	text, err := repo.GetStagedDiff()
+	if err != nil {
+		return err
+	}
+	d := diff.NewDiff(text)
+
+	fmt.Println("Grouping the staged changes...")
+	var groups []ai.CommitGroup
+	err = aiClient.RunWithClient(ctx, func(client claudecode.Client) error {
+		var groupErr error
+		groups, groupErr = aiClient.SuggestCommitGroups(ctx, client, d)
+		return groupErr
+	})
+	if err != nil && timedOut(ctx) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		return withCode(CodeTimedOut, fmt.Errorf("grouping the changes timed out after %s", timeout))
+	}
+	if err != nil {
+		return err
+	}
+	groups = commit.NormalizeGroups(groups, d.Paths())
+	printSplitPlan(os.Stdout, groups)
+
+	// Whatever happens, the changes not committed end up staged as before
+	defer func() {
+		if restoreErr := repo.RestoreStaged(staged); restoreErr != nil && err == nil {
+			err = fmt.Errorf("failed to stage the changes not committed again: %w", restoreErr)
+		}
+	}()
+
+	userContext, _ := cmd.Flags().GetString("message")
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	repo.SetSkipHooks(noVerify)
+	in := bufio.NewReader(os.Stdin)
+	for i, g := range groups {
+		fmt.Printf("\nCommit %d of %d: %s\n", i+1, len(groups), g.Reason)
+		group := d.Filter(func(f *diff.File) bool { return slices.Contains(g.Files, f.Path) })
+		message, err := generateCommitMessage(ctx, aiClient, group, userContext, config.IsFastCommitEnabled(cmd))
+		if err != nil && timedOut(ctx) {
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			return withCode(CodeTimedOut, fmt.Errorf("commit message generation timed out after %s", timeout))
+		}
+		if err != nil {
+			return err
+		}
+		message = addTrailers(repo, message)
+		fmt.Println()
+		fmt.Println("  " + strings.ReplaceAll(message, "\n", "\n  "))
+		fmt.Println()
+
+		choice := splitCommit
+		if config.IsAutoConfirmEnabled(cmd) {
+			debugLog("Auto-confirm enabled, committing group %d", i+1)
+		} else if isCI(cmd) {
+			fmt.Println("Running in CI; commits not created. Pass --yes to commit without confirmation.")
+			return nil
+		} else {
+			choice = askSplitCommit(in, os.Stdout)
+		}
+		if choice == splitQuit {
+			fmt.Println("Stopped; the remaining changes are still staged.")
+			return nil
+		}
+		if choice == splitSkip {
+			fmt.Println("Skipped; these changes stay staged.")
+			continue
+		}
+
+		if err := repo.StageOnly(staged, splitGroupPaths(d, g)); err != nil {
+			return err
+		}
+		hash, err := repo.Commit(message)
+		if err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
+		}
+		fmt.Printf("Created commit: %s\n", shortHash(hash))
+		linkReviewedCommit(repo, group, hash)
+	}
+	return nil
+}
+
+// printSplitPlan lists the commits the staged changes will be split into
+func printSplitPlan(out io.Writer, groups []ai.CommitGroup) {
+	fmt.Fprintf(out, "\nThe staged changes will be split into %d commit(s):\n", len(groups))
+	for i, g := range groups {
+		fmt.Fprintf(out, "\n  %d. %s\n", i+1, g.Reason)
+		for _, f := range g.Files {
+			fmt.Fprintf(out, "     %s\n", f)
+		}
+	}
+}
+
+// askSplitCommit asks whether to commit a group, skip it or stop splitting.
+// Anything but y, yes, q or quit skips the group.
+func askSplitCommit(in *bufio.Reader, out io.Writer) splitChoice {
+	fmt.Fprint(out, "Commit these changes? [y/N/q] ")
+	response, _ := in.ReadString('\n')
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "y", "yes":
+		return splitCommit
+	case "q", "quit":
+		return splitQuit
+	default:
+		return splitSkip
+	}
+}
+
+// splitGroupPaths returns the paths to stage to commit the files of g: their
+// paths, and the old paths of those renamed, so that the old file is removed
+// in the same commit
+func splitGroupPaths(d *diff.Diff, g ai.CommitGroup) []string {
+	var paths []string
+	for _, path := range g.Files {
+		paths = append(paths, path)
+		if f := d.File(path); f != nil && f.OldPath() != path {
+			paths = append(paths, f.OldPath())
+		}
+	}
+	return paths
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/tui"
+	"github.com/buker/revi/internal/tui/views"
+)
+
+var stageCmd = &cobra.Command{
+	Use:   "stage",
+	Short: "Choose which hunks of the changed files are staged",
+	Long: `List the hunks of the changes to tracked files, staged or not, and choose
+which are staged, to review and commit part of a file's changes.
+
+Move between hunks with the arrow keys, press space to stage or unstage the
+selected hunk, and y to write the choice to the index; Esc leaves the index
+as it was. Staged changes that the working tree no longer has are replaced
+by the hunks chosen.`,
+	RunE: runStage,
+}
+
+func runStage(cmd *cobra.Command, args []string) error {
+	if isCI(cmd) {
+		return withCode(CodeInvalidInput, fmt.Errorf("revi stage is interactive and cannot run in CI"))
+	}
+	repo, err := git.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	release, err := acquireRepoLock(cmd, repo)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	hunks, err := repo.GetHunks()
+	if err != nil {
+		return err
+	}
+	if len(hunks) == 0 {
+		fmt.Println("No changes to tracked files.")
+		return nil
+	}
+
+	chosen, ok, err := tui.PickHunks(hunkItems(hunks))
+	if err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+	if !ok {
+		fmt.Println("Index unchanged.")
+		return nil
+	}
+	staged := 0
+	for i := range hunks {
+		hunks[i].Staged = chosen[i].Staged
+		if hunks[i].Staged {
+			staged++
+		}
+	}
+	if err := repo.StageHunks(hunks); err != nil {
+		return err
+	}
+	fmt.Printf("Staged %d of %d hunk(s).\n", staged, len(hunks))
+	return nil
+}
+
+// hunkItems returns the hunks as the hunk staging view shows them
+func hunkItems(hunks []git.Hunk) []views.HunkItem {
+	items := make([]views.HunkItem, len(hunks))
+	for i, h := range hunks {
+		item := views.HunkItem{Path: h.Path, Header: h.Header(), Staged: h.Staged}
+		for _, line := range h.Removed {
+			item.Lines = append(item.Lines, "-"+line)
+		}
+		for _, line := range h.Added {
+			item.Lines = append(item.Lines, "+"+line)
+		}
+		items[i] = item
+	}
+	return items
+}
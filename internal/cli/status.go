@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/review"
+)
+
+// statusFile is where the outcome of the last review is written, relative to
+// the git directory
+const statusFile = "revi/status"
+
+// Outcomes of a review, as written to the status file and the OSC sequence
+const (
+	statusClean   = "clean"   // No issues found
+	statusIssues  = "issues"  // Issues found, none of which block
+	statusBlocked = "blocked" // High-severity issues block the commit
+)
+
+// reviewOutcome returns the outcome of a review that produced results
+func reviewOutcome(results []*review.Result, blocked bool) string {
+	switch {
+	case blocked:
+		return statusBlocked
+	case review.Summarize(results).IssuesFound > 0:
+		return statusIssues
+	default:
+		return statusClean
+	}
+}
+
+// reportReviewStatus makes the outcome of a finished review available to
+// shell prompts and terminal multiplexers, as the ui settings ask: in the
+// status file, with the terminal bell and with an OSC sequence. Failures are
+// only logged, since the review itself succeeded.
+func reportReviewStatus(repo *git.Repository, results []*review.Result, blocked bool) {
+	cfg := config.Get()
+	outcome := reviewOutcome(results, blocked)
+	if cfg.UI.StatusFile {
+		if gitDir, err := repo.GitDir(); err != nil {
+			debugLog("failed to write review status: %v", err)
+		} else if err := writeReviewStatus(filepath.Join(gitDir, statusFile), outcome, review.Summarize(results), time.Now()); err != nil {
+			debugLog("failed to write review status: %v", err)
+		}
+	}
+	if isTerminal(os.Stderr) {
+		notifyTerminal(os.Stderr, cfg.UI.Bell, cfg.UI.OSC, outcome)
+	}
+}
+
+// writeReviewStatus writes the status file at path: the outcome on the first
+// line, so "head -n1" is enough for a prompt, then the issue counts and the
+// time of the review as key=value lines
+func writeReviewStatus(path, outcome string, summary review.Summary, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	content := fmt.Sprintf("%s\nhigh=%d\nmedium=%d\nlow=%d\ntimed_out=%d\ntime=%s\n",
+		outcome, summary.HighSeverity, summary.MediumSeverity, summary.LowSeverity,
+		summary.TimedOutReviews, now.UTC().Format(time.RFC3339))
+	// Write and rename so a prompt never reads a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// notifyTerminal rings the bell if bell is set and sends osc, with {status}
+// replaced by outcome, as an OSC sequence. osc is the part between ESC ] and
+// the terminator, e.g. "2;revi: {status}" to set the window title.
+func notifyTerminal(w io.Writer, bell bool, osc, outcome string) {
+	if osc != "" {
+		fmt.Fprintf(w, "\x1b]%s\x07", strings.ReplaceAll(osc, "{status}", outcome))
+	}
+	if bell {
+		fmt.Fprint(w, "\a")
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	claudecode "github.com/rokrokss/claude-code-sdk-go"
+)
+
+// withPromotedSuggestions wraps run so that every suggestion in its result is
+// turned into an issue with a fix attempt. Suggestions that cannot be promoted
+// are kept as they are.
+func withPromotedSuggestions(aiClient *ai.Client, client claudecode.Client, diff *diff.Diff, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		if err != nil || result == nil {
+			return result, err
+		}
+		for _, suggestion := range append([]string(nil), result.Suggestions...) {
+			issue, err := aiClient.PromoteSuggestion(ctx, client, mode, diff, suggestion)
+			if err != nil {
+				debugLog("Could not promote suggestion %q: %v", suggestion, err)
+				if errors.Is(err, review.ErrAuthRequired) || ctx.Err() != nil {
+					break
+				}
+				continue
+			}
+			result.PromoteSuggestion(suggestion, *issue)
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/history"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/suppress"
+	"github.com/buker/revi/internal/triage"
+	"github.com/buker/revi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// issueFilter loads the repository's .reviignore rules, its baseline, its
+// triage decisions, the revi:ignore annotations in diff and the earlier run
+// given with --baseline. Returns nil if --no-ignore is set.
+func issueFilter(cmd *cobra.Command, repo *git.Repository, diff *diff.Diff) (*suppress.Filter, error) {
+	if noIgnore, _ := cmd.Flags().GetBool("no-ignore"); noIgnore {
+		return nil, nil
+	}
+	previous, err := knownIssues(cmd, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []suppress.Rule
+	var known *baseline.Baseline
+	var decisions *triage.Triage
+	if root, err := repo.Root(); err == nil {
+		rules, err = suppress.Load(filepath.Join(root, suppress.FileName))
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, err)
+		}
+		known, err = baseline.Load(filepath.Join(root, baseline.FileName))
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, err)
+		}
+		decisions, err = triage.Load(filepath.Join(root, triage.FileName))
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, err)
+		}
+	}
+	filter := suppress.New(rules, diff)
+	filter.SetBaseline(known)
+	filter.SetTriage(decisions)
+	filter.SetKnown(previous)
+	return filter, nil
+}
+
+// knownIssues loads the earlier run named by --baseline: a file holding a
+// baseline or JSON review output, "last" for the most recent review in the
+// history, or the ID of one. Returns nil if --baseline is not set.
+func knownIssues(cmd *cobra.Command, repo *git.Repository) (*baseline.Baseline, error) {
+	from, _ := cmd.Flags().GetString("baseline")
+	if from == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(from); err == nil {
+		known, err := baseline.Read(from)
+		if err != nil {
+			return nil, withCode(CodeInvalidInput, err)
+		}
+		return known, nil
+	}
+
+	store, err := historyStore(repo)
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	if from != "last" {
+		args = []string{from}
+	}
+	entry, err := baselineEntry(store, args)
+	if errors.Is(err, history.ErrNotFound) {
+		return nil, withCode(CodeInvalidInput, fmt.Errorf("--baseline %s is neither a file nor a review in the history", from))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return baseline.New(entry.Results), nil
+}
+
+// issueTriager returns the function the TUI saves triage decisions with. Each
+// decision is added to the repository's triage file as it is made.
+func issueTriager(repo *git.Repository) tui.IssueTriager {
+	return func(mode review.Mode, issue review.Issue, decision triage.Decision) error {
+		root, err := repo.Root()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(root, triage.FileName)
+		decisions, err := triage.Load(path)
+		if err != nil {
+			return err
+		}
+		decisions.Record(mode, issue, decision)
+		return decisions.Write(path)
+	}
+}
+
+// withSuppression wraps run so that suppressed issues are removed from its
+// results. run is returned unchanged if filter is nil.
+func withSuppression(filter *suppress.Filter, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if filter == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		filter.Apply(result)
+		return result, err
+	}
+}
+
+// withKnownIssues wraps run so that the issues an earlier run given with
+// --baseline reported are removed from its results. It goes around the
+// history's recording, which keeps them. run is returned unchanged if filter
+// is nil.
+func withKnownIssues(filter *suppress.Filter, run func(ctx context.Context, mode review.Mode) (*review.Result, error)) func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+	if filter == nil {
+		return run
+	}
+	return func(ctx context.Context, mode review.Mode) (*review.Result, error) {
+		result, err := run(ctx, mode)
+		return filter.DropKnown(result), err
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/config"
+	"github.com/buker/revi/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	transcriptListCmd.Flags().IntP("limit", "n", 20, "Number of most recent runs to list (0 lists all)")
+
+	transcriptCmd.AddCommand(transcriptListCmd)
+	transcriptCmd.AddCommand(transcriptShowCmd)
+}
+
+var transcriptCmd = &cobra.Command{
+	Use:   "transcript",
+	Short: "Browse the prompts and responses recorded for audits",
+	Long: `Browse the transcripts recorded in ai.transcript_dir.
+
+With ai.transcript_dir set, every run that calls the AI records each prompt
+it sends and each raw response, with timestamps, in a file named after the
+run's ID. Secrets recognized by the local scanner are redacted first.`,
+}
+
+var transcriptListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent runs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := transcriptDir()
+		if err != nil {
+			return err
+		}
+		ids, err := transcript.List(dir)
+		if err != nil {
+			return err
+		}
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && len(ids) > limit {
+			ids = ids[len(ids)-limit:]
+		}
+
+		runs := make([]transcript.Run, 0, len(ids))
+		for _, id := range ids {
+			run, err := transcript.Read(dir, id)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+		}
+		if isJSONOutput(cmd) {
+			return writeJSON(os.Stdout, runs)
+		}
+		if len(runs) == 0 {
+			fmt.Println("No transcripts recorded yet.")
+			return nil
+		}
+		return writeTranscriptList(os.Stdout, runs)
+	},
+}
+
+var transcriptShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show the prompts and responses of a run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := transcriptDir()
+		if err != nil {
+			return err
+		}
+		run, err := transcript.Read(dir, args[0])
+		if err != nil {
+			return withCode(CodeInvalidInput, err)
+		}
+		if isJSONOutput(cmd) {
+			return writeJSON(os.Stdout, run)
+		}
+		writeTranscript(os.Stdout, run)
+		return nil
+	},
+}
+
+// transcriptDir returns ai.transcript_dir, relative to the repository root
+// when it is a relative path and revi runs in a repository
+func transcriptDir() (string, error) {
+	dir := config.Get().AI.TranscriptDir
+	if dir == "" {
+		return "", withCode(CodeInvalidInput, fmt.Errorf("ai.transcript_dir is not set; set it to record transcripts"))
+	}
+	if root := currentRepoRoot(); root != "" && !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir, nil
+}
+
+// runTranscript is the transcript of this run, opened by the first AI client
+// that needs it
+var runTranscript struct {
+	once sync.Once
+	log  *transcript.Log
+}
+
+// setTranscript makes client record its prompts and responses in the
+// transcript of this run if ai.transcript_dir is set. If the transcript
+// cannot be started, the run goes on without one.
+func setTranscript(client *ai.Client) {
+	runTranscript.once.Do(func() {
+		dir, err := transcriptDir()
+		if err != nil {
+			return
+		}
+		log, err := transcript.Open(dir, strings.Join(append([]string{"revi"}, os.Args[1:]...), " "))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: not recording a transcript: %v\n", err)
+			return
+		}
+		debugLog("Recording transcript %s", log.ID())
+		runTranscript.log = log
+	})
+	client.SetTranscript(runTranscript.log)
+}
+
+// closeTranscript warns if recording the transcript of this run failed
+func closeTranscript() {
+	if err := runTranscript.log.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: transcript %s is incomplete: %v\n", runTranscript.log.ID(), err)
+	}
+}
+
+// writeTranscriptList writes one line per run, newest first
+func writeTranscriptList(w io.Writer, runs []transcript.Run) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tDATE\tPROMPTS\tCOMMAND")
+	for i := len(runs) - 1; i >= 0; i-- {
+		r := runs[i]
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n",
+			r.ID, r.Start().Local().Format("2006-01-02 15:04"), r.Prompts(), r.Command())
+	}
+	return tw.Flush()
+}
+
+// writeTranscript writes the entries of run in order, each under a heading
+// with its time, kind, mode and model
+func writeTranscript(w io.Writer, run transcript.Run) {
+	fmt.Fprintf(w, "Run %s\n", run.ID)
+	fmt.Fprintf(w, "Command: %s\n", run.Command())
+	for _, e := range run.Entries {
+		if e.Kind == transcript.KindStart {
+			continue
+		}
+		var about []string
+		for _, s := range []string{e.Mode, e.Model} {
+			if s != "" {
+				about = append(about, s)
+			}
+		}
+		heading := fmt.Sprintf("%s %s", e.Time.Local().Format("15:04:05"), e.Kind)
+		if len(about) > 0 {
+			heading += " (" + strings.Join(about, ", ") + ")"
+		}
+		if e.Redacted > 0 {
+			heading += fmt.Sprintf(", %d secret(s) redacted", e.Redacted)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, heading)
+		fmt.Fprintln(w, strings.Repeat("-", len(heading)))
+		fmt.Fprintln(w, strings.TrimRight(e.Text, "\n"))
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	rtdebug "runtime/debug"
+	"time"
+
+	"github.com/buker/revi/internal/update"
+	"github.com/spf13/cobra"
+)
+
+// updateCheckTimeout bounds the GitHub request made by --check-update
+const updateCheckTimeout = 5 * time.Second
+
+func init() {
+	versionCmd.Flags().Bool("json", false, "Print build metadata as JSON (same as --output json)")
+	versionCmd.Flags().Bool("check-update", false, "Check GitHub releases for a newer version")
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version number",
+	Long: `Print the version of revi and the build it came from.
+
+Use --json for machine-readable output and --check-update to compare the
+version with the latest GitHub release.`,
+	Run: runVersion,
+}
+
+// versionInfo is the build metadata printed by the version command
+type versionInfo struct {
+	Version     string         `json:"version"`
+	Commit      string         `json:"commit,omitempty"`
+	Date        string         `json:"date,omitempty"`
+	GoVersion   string         `json:"go_version"`
+	Platform    string         `json:"platform"`
+	Update      *update.Status `json:"update,omitempty"`
+	UpdateError string         `json:"update_error,omitempty"`
+}
+
+// buildInfo returns the build metadata, falling back to the module and VCS
+// information embedded by the go tool for binaries built without -ldflags
+// (e.g. go install)
+func buildInfo() versionInfo {
+	info := versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	bi, ok := rtdebug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, setting := range bi.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.Date == "":
+			info.Date = setting.Value
+		}
+	}
+	return info
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	info := buildInfo()
+
+	if check, _ := cmd.Flags().GetBool("check-update"); check {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		status, err := update.Check(ctx, http.DefaultClient, update.ReleasesURL, info.Version)
+		if err != nil {
+			info.UpdateError = err.Error()
+		} else {
+			info.Update = status
+		}
+	}
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if err := writeVersion(os.Stdout, info, asJSON || isJSONOutput(cmd)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// writeVersion prints the build metadata as text or as a JSON object
+func writeVersion(w io.Writer, info versionInfo, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			return fmt.Errorf("failed to write JSON output: %w", err)
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "revi version %s\n", info.Version)
+	if info.Commit != "" {
+		_, _ = fmt.Fprintf(w, "  commit:   %s\n", info.Commit)
+	}
+	if info.Date != "" {
+		_, _ = fmt.Fprintf(w, "  built:    %s\n", info.Date)
+	}
+	_, _ = fmt.Fprintf(w, "  go:       %s\n", info.GoVersion)
+	_, _ = fmt.Fprintf(w, "  platform: %s\n", info.Platform)
+
+	switch {
+	case info.UpdateError != "":
+		_, _ = fmt.Fprintf(w, "\nUpdate check failed: %s\n", info.UpdateError)
+	case info.Update != nil && info.Update.Available:
+		_, _ = fmt.Fprintf(w, "\nA newer version is available: %s\n%s\n", info.Update.Latest, info.Update.URL)
+	case info.Update != nil:
+		_, _ = fmt.Fprintln(w, "\nrevi is up to date.")
+	}
+	return nil
+}
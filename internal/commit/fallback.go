@@ -0,0 +1,171 @@
+package commit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// FallbackNote marks commit messages generated without AI
+const FallbackNote = "Generated without AI because the AI backend was unavailable."
+
+// maxFallbackFiles is the number of files listed in a fallback message's body
+const maxFallbackFiles = 20
+
+// fileChange is the diffstat of one file in a diff
+type fileChange struct {
+	path             string
+	added, removed   int
+	created, deleted bool
+}
+
+// FallbackMessage returns a conventional commit message for diff built from
+// its file list and diffstat alone, for when the AI backend is unavailable,
+// e.g. "chore: update 3 files in internal/tui". userContext, if set, opens
+// the body, and the body ends with FallbackNote.
+func FallbackMessage(d *diff.Diff, userContext string) *ai.CommitMessage {
+	var changes []fileChange
+	for _, f := range d.Files {
+		c := fileChange{path: f.Path}
+		c.created = f.Status() == diff.FileAdded
+		c.deleted = f.Status() == diff.FileDeleted
+		c.added, c.removed = f.Stat()
+		changes = append(changes, c)
+	}
+
+	var body []string
+	if userContext != "" {
+		body = append(body, strings.TrimSpace(userContext))
+	}
+	if stat := diffstat(changes); stat != "" {
+		body = append(body, stat)
+	}
+	body = append(body, FallbackNote)
+
+	return &ai.CommitMessage{
+		Type:    fallbackType(changes),
+		Subject: fallbackSubject(changes),
+		Body:    strings.Join(body, "\n\n"),
+	}
+}
+
+// fallbackType returns docs or test if every file is documentation or tests,
+// and chore otherwise
+func fallbackType(changes []fileChange) string {
+	if len(changes) == 0 {
+		return "chore"
+	}
+	docs, tests := true, true
+	for _, c := range changes {
+		docs = docs && isDocFile(c.path)
+		tests = tests && isTestFile(c.path)
+	}
+	switch {
+	case docs:
+		return "docs"
+	case tests:
+		return "test"
+	}
+	return "chore"
+}
+
+// fallbackSubject describes what happened to which files, in at most 50
+// characters as ValidateMessage requires
+func fallbackSubject(changes []fileChange) string {
+	if len(changes) == 0 {
+		return "update files"
+	}
+
+	verb := "update"
+	created, deleted := true, true
+	for _, c := range changes {
+		created = created && c.created
+		deleted = deleted && c.deleted
+	}
+	switch {
+	case created:
+		verb = "add"
+	case deleted:
+		verb = "remove"
+	}
+
+	var candidates []string
+	if len(changes) == 1 {
+		candidates = []string{verb + " " + changes[0].path, verb + " " + path.Base(changes[0].path)}
+	} else {
+		paths := make([]string, len(changes))
+		for i, c := range changes {
+			paths[i] = c.path
+		}
+		files := fmt.Sprintf("%s %d files", verb, len(changes))
+		if dir := commonDir(paths); dir != "" {
+			candidates = append(candidates, files+" in "+dir)
+		}
+		candidates = append(candidates, files)
+	}
+	for _, subject := range candidates {
+		if len(subject) <= 50 {
+			return subject
+		}
+	}
+	last := candidates[len(candidates)-1]
+	return last[:47] + "..."
+}
+
+// diffstat lists the lines added and removed in each file, followed by totals
+func diffstat(changes []fileChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	var added, removed int
+	for i, c := range changes {
+		added += c.added
+		removed += c.removed
+		if i < maxFallbackFiles {
+			fmt.Fprintf(&b, "- %s (+%d -%d)\n", c.path, c.added, c.removed)
+		}
+	}
+	if len(changes) > maxFallbackFiles {
+		fmt.Fprintf(&b, "- and %d more\n", len(changes)-maxFallbackFiles)
+	}
+	fmt.Fprintf(&b, "\n%d file(s) changed, %d insertion(s), %d deletion(s)", len(changes), added, removed)
+	return b.String()
+}
+
+// commonDir returns the deepest directory containing every path, or "" if
+// they only share the repository root
+func commonDir(paths []string) string {
+	dir := path.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for dir != "." && !strings.HasPrefix(p, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// isDocFile reports whether p is documentation
+func isDocFile(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown", ".rst", ".adoc", ".txt":
+		return true
+	}
+	return strings.HasPrefix(p, "docs/") || strings.Contains(p, "/docs/")
+}
+
+// isTestFile reports whether p holds tests
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") ||
+		strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") ||
+		strings.Contains(p, "/test/") || strings.Contains(p, "/tests/")
+}
@@ -0,0 +1,119 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// modifiedFile returns a diff changing one line of path
+func modifiedFile(path string) string {
+	return fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -1 +1 @@\n-old\n+new\n", path, path, path, path)
+}
+
+// createdFile returns a diff adding path with lines lines
+func createdFile(path string, lines int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\nnew file mode 100644\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", path, path, path, lines)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&b, "+line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestFallbackMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "files in one directory",
+			diff: modifiedFile("internal/tui/model.go") + modifiedFile("internal/tui/program.go") + modifiedFile("internal/tui/views/progress.go"),
+			want: "chore: update 3 files in internal/tui",
+		},
+		{
+			name: "files across the repository",
+			diff: modifiedFile("go.mod") + modifiedFile("internal/cli/root.go"),
+			want: "chore: update 2 files",
+		},
+		{
+			name: "single new file",
+			diff: createdFile("internal/commit/fallback.go", 3),
+			want: "chore: add internal/commit/fallback.go",
+		},
+		{
+			name: "documentation only",
+			diff: modifiedFile("README.md") + modifiedFile("docs/usage.txt"),
+			want: "docs: update 2 files",
+		},
+		{
+			name: "tests only",
+			diff: createdFile("internal/ai/estimate_test.go", 2) + createdFile("internal/ai/chunk_test.go", 2),
+			want: "test: add 2 files in internal/ai",
+		},
+		{
+			name: "long path",
+			diff: modifiedFile("internal/some/very/deeply/nested/directory/tree/file.go"),
+			want: "chore: update file.go",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := FallbackMessage(diff.NewDiff(tt.diff), "")
+			subject := strings.SplitN(msg.String(), "\n", 2)[0]
+			if subject != tt.want {
+				t.Errorf("subject = %q, want %q", subject, tt.want)
+			}
+			if err := ValidateMessage(msg); err != nil {
+				t.Errorf("ValidateMessage() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFallbackMessage_Body(t *testing.T) {
+	text := createdFile("a.go", 3) + modifiedFile("b.go")
+	msg := FallbackMessage(diff.NewDiff(text), "Needed for the release")
+
+	for _, want := range []string{"Needed for the release", "- a.go (+3 -0)", "- b.go (+1 -1)", "2 file(s) changed, 4 insertion(s), 1 deletion(s)"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, msg.Body)
+		}
+	}
+	if !strings.HasSuffix(msg.Body, FallbackNote) {
+		t.Errorf("body should end with FallbackNote:\n%s", msg.Body)
+	}
+	if !strings.HasPrefix(msg.Body, "Needed for the release") {
+		t.Errorf("body should open with the user's context:\n%s", msg.Body)
+	}
+}
+
+func TestFallbackMessage_CountsFilesWithoutHunkHeader(t *testing.T) {
+	// Added and deleted files as older versions of the git package wrote them
+	text := "diff --git a/a.go b/a.go\nnew file mode 100644\n--- /dev/null\n+++ b/a.go\n+package a\n+\n+var x = 1\n" +
+		"diff --git a/b.go b/b.go\ndeleted file mode 100644\n--- a/b.go\n+++ /dev/null\n-package b\n"
+	msg := FallbackMessage(diff.NewDiff(text), "")
+
+	for _, want := range []string{"- a.go (+3 -0)", "- b.go (+0 -1)"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, msg.Body)
+		}
+	}
+}
+
+func TestFallbackMessage_ListsAtMostMaxFiles(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < maxFallbackFiles+5; i++ {
+		text.WriteString(modifiedFile(fmt.Sprintf("pkg/file%d.go", i)))
+	}
+	msg := FallbackMessage(diff.NewDiff(text.String()), "")
+	if got := strings.Count(msg.Body, "\n- pkg/"); got != maxFallbackFiles-1 {
+		t.Errorf("listed %d files after the first, want %d", got, maxFallbackFiles-1)
+	}
+	if !strings.Contains(msg.Body, "- and 5 more") {
+		t.Errorf("body should count the unlisted files:\n%s", msg.Body)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	claudecode "github.com/rokrokss/claude-code-sdk-go"
 
 	"github.com/buker/revi/internal/ai"
+	"github.com/buker/revi/internal/git/diff"
 )
 
 // Generator handles AI-powered commit message generation using the Claude Code SDK.
@@ -27,10 +28,31 @@ func NewGenerator(wrapper *ai.ClientWrapper) *Generator {
 // If userContext is provided, it will be included to explain why the change was made.
 // Returns a structured CommitMessage with type, optional scope, subject, and body.
 // Requires a connected SDK client obtained via RunWithClient().
-func (g *Generator) Generate(ctx context.Context, client claudecode.Client, diff string, userContext string) (*ai.CommitMessage, error) {
+func (g *Generator) Generate(ctx context.Context, client claudecode.Client, diff *diff.Diff, userContext string) (*ai.CommitMessage, error) {
 	return g.wrapper.GenerateCommitMessage(ctx, client, diff, userContext)
 }
 
+// Regenerate creates a commit message for the diff again, revising message,
+// a message generated earlier, as the user's feedback asks (e.g. "mention the
+// migration" or "use scope api"). The feedback is appended to userContext.
+func (g *Generator) Regenerate(ctx context.Context, client claudecode.Client, diff *diff.Diff, userContext, message, feedback string) (*ai.CommitMessage, error) {
+	return g.wrapper.GenerateCommitMessage(ctx, client, diff, FeedbackContext(userContext, message, feedback))
+}
+
+// FeedbackContext appends feedback on a generated commit message to the
+// context explaining why the change was made, so the message is generated
+// again with both.
+func FeedbackContext(userContext, message, feedback string) string {
+	var b strings.Builder
+	if userContext != "" {
+		b.WriteString(userContext)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "A previous commit message for this diff was:\n%s\n\n", message)
+	fmt.Fprintf(&b, "Revise it as the user asks: %s", feedback)
+	return b.String()
+}
+
 // FormatMessage formats a CommitMessage as a string suitable for git commit.
 func FormatMessage(msg *ai.CommitMessage) string {
 	return msg.String()
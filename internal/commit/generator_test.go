@@ -117,3 +117,19 @@ func TestTypeDescription(t *testing.T) {
 		t.Fatal("expected empty description for unknown type")
 	}
 }
+
+func TestFeedbackContext(t *testing.T) {
+	got := FeedbackContext("ticket PROJ-1", "feat: add users table", "mention the migration")
+	for _, want := range []string{"ticket PROJ-1", "feat: add users table", "mention the migration"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FeedbackContext() = %q, want it to contain %q", got, want)
+		}
+	}
+	if !strings.HasPrefix(got, "ticket PROJ-1\n\n") {
+		t.Errorf("FeedbackContext() = %q, want the user context first", got)
+	}
+
+	if got := FeedbackContext("", "fix: typo", "use scope docs"); strings.HasPrefix(got, "\n") {
+		t.Errorf("FeedbackContext() without context = %q, want no leading blank line", got)
+	}
+}
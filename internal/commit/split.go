@@ -0,0 +1,40 @@
+package commit
+
+import (
+	"slices"
+
+	"github.com/buker/revi/internal/ai"
+)
+
+// NormalizeGroups makes the commit groups suggested for a diff cover each of
+// its files, paths, exactly once. Files the diff does not change are dropped,
+// a file in several groups stays in the first, and files in no group are put
+// in a last group of their own. Groups left empty are removed.
+func NormalizeGroups(groups []ai.CommitGroup, paths []string) []ai.CommitGroup {
+	seen := make(map[string]bool)
+	var normalized []ai.CommitGroup
+	for _, g := range groups {
+		var files []string
+		for _, f := range g.Files {
+			if seen[f] || !slices.Contains(paths, f) {
+				continue
+			}
+			seen[f] = true
+			files = append(files, f)
+		}
+		if len(files) > 0 {
+			normalized = append(normalized, ai.CommitGroup{Files: files, Reason: g.Reason})
+		}
+	}
+
+	var rest []string
+	for _, p := range paths {
+		if !seen[p] {
+			rest = append(rest, p)
+		}
+	}
+	if len(rest) > 0 {
+		normalized = append(normalized, ai.CommitGroup{Files: rest, Reason: "remaining changes"})
+	}
+	return normalized
+}
@@ -0,0 +1,29 @@
+package commit
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/buker/revi/internal/ai"
+)
+
+func TestNormalizeGroups(t *testing.T) {
+	paths := []string{"a.go", "b.go", "c.go", "d.go"}
+	groups := []ai.CommitGroup{
+		{Files: []string{"a.go", "missing.go"}, Reason: "first"},
+		{Files: []string{"missing.go"}, Reason: "only unknown files"},
+		{Files: []string{"b.go", "a.go"}, Reason: "second"},
+	}
+	want := []ai.CommitGroup{
+		{Files: []string{"a.go"}, Reason: "first"},
+		{Files: []string{"b.go"}, Reason: "second"},
+		{Files: []string{"c.go", "d.go"}, Reason: "remaining changes"},
+	}
+	if got := NormalizeGroups(groups, paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeGroups() = %+v, want %+v", got, want)
+	}
+
+	if got := NormalizeGroups(nil, []string{"a.go"}); len(got) != 1 || got[0].Files[0] != "a.go" {
+		t.Errorf("NormalizeGroups(nil) = %+v, want one group of every file", got)
+	}
+}
@@ -0,0 +1,68 @@
+package commit
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// trailerLine matches a git trailer, such as "Reviewed-by: Jane Doe <jane@example.com>"
+var trailerLine = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S`)
+
+// IsTrailer reports whether line is a git trailer: a token, a colon and a value
+func IsTrailer(line string) bool {
+	return trailerLine.MatchString(line)
+}
+
+// IssueIDs returns the issue IDs pattern finds in branch, such as PROJ-123 in
+// feature/PROJ-123-login, in order and without repeats
+func IssueIDs(branch string, pattern *regexp.Regexp) []string {
+	if pattern == nil {
+		return nil
+	}
+	var ids []string
+	for _, id := range pattern.FindAllString(branch, -1) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// AddTrailers appends trailers to message. They join the trailer block the
+// body already ends with, if any, or start one; trailers the message already
+// has are not added again.
+func AddTrailers(message string, trailers []string) string {
+	lines := strings.Split(message, "\n")
+
+	var added []string
+	for _, trailer := range trailers {
+		trailer = strings.TrimSpace(trailer)
+		has := func(line string) bool { return strings.EqualFold(strings.TrimSpace(line), trailer) }
+		if trailer == "" || slices.ContainsFunc(lines, has) || slices.ContainsFunc(added, has) {
+			continue
+		}
+		added = append(added, trailer)
+	}
+	if len(added) == 0 {
+		return message
+	}
+	message = strings.TrimRight(message, "\n")
+
+	// The subject alone is not a trailer block, even when it looks like one
+	separator := "\n\n"
+	if i := strings.LastIndex(message, "\n\n"); i >= 0 && endsWithTrailers(message[i+2:]) {
+		separator = "\n"
+	}
+	return message + separator + strings.Join(added, "\n")
+}
+
+// endsWithTrailers reports whether every line of paragraph is a trailer
+func endsWithTrailers(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !IsTrailer(line) {
+			return false
+		}
+	}
+	return strings.TrimSpace(paragraph) != ""
+}
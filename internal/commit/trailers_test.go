@@ -0,0 +1,74 @@
+package commit
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestIssueIDs(t *testing.T) {
+	pattern := regexp.MustCompile(`[A-Z][A-Z0-9]+-[0-9]+`)
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"feature/PROJ-123-login", []string{"PROJ-123"}},
+		{"PROJ-1-and-OPS-22-PROJ-1", []string{"PROJ-1", "OPS-22"}},
+		{"fix-login", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := IssueIDs(tt.branch, pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("IssueIDs(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+	if got := IssueIDs("PROJ-123", nil); got != nil {
+		t.Errorf("IssueIDs() without a pattern = %v, want nil", got)
+	}
+}
+
+func TestAddTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		trailers []string
+		want     string
+	}{
+		{
+			name:     "subject only",
+			message:  "feat: add login",
+			trailers: []string{"Refs: PROJ-123"},
+			want:     "feat: add login\n\nRefs: PROJ-123",
+		},
+		{
+			name:     "after body",
+			message:  "fix(api): handle nil user\n\nThe handler crashed on logout.\n",
+			trailers: []string{"Refs: PROJ-123", "Reviewed-by: Jane Doe <jane@example.com>"},
+			want:     "fix(api): handle nil user\n\nThe handler crashed on logout.\n\nRefs: PROJ-123\nReviewed-by: Jane Doe <jane@example.com>",
+		},
+		{
+			name:     "joins existing block",
+			message:  "fix: handle nil user\n\nBody.\n\nSigned-off-by: Jane Doe <jane@example.com>",
+			trailers: []string{"Refs: PROJ-123"},
+			want:     "fix: handle nil user\n\nBody.\n\nSigned-off-by: Jane Doe <jane@example.com>\nRefs: PROJ-123",
+		},
+		{
+			name:     "skips present and repeated",
+			message:  "fix: handle nil user\n\nrefs: PROJ-123",
+			trailers: []string{"Refs: PROJ-123", "", "Refs: OPS-1", "Refs: OPS-1"},
+			want:     "fix: handle nil user\n\nrefs: PROJ-123\nRefs: OPS-1",
+		},
+		{
+			name:    "none",
+			message: "fix: handle nil user\n",
+			want:    "fix: handle nil user\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddTrailers(tt.message, tt.trailers); got != tt.want {
+				t.Errorf("AddTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
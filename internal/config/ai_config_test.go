@@ -22,6 +22,15 @@ func TestAIConfig_DefaultModel(t *testing.T) {
 	}
 }
 
+func TestAIConfig_RedactsSecretsByDefault(t *testing.T) {
+	resetForTest(t)
+	Init()
+
+	if !Get().AI.RedactSecrets {
+		t.Fatal("expected ai.redact_secrets to default to true")
+	}
+}
+
 func TestAIConfig_ModelOverrideFromEnv(t *testing.T) {
 	resetForTest(t)
 	t.Setenv("REVI_AI_MODEL", "claude-sonnet-4-20250514")
@@ -4,9 +4,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,16 +18,60 @@ import (
 // Config holds all application configuration values.
 // It is populated from config files, environment variables, and command-line flags.
 type Config struct {
-	Review ReviewConfig `mapstructure:"review"` // Review behavior settings
-	Commit CommitConfig `mapstructure:"commit"` // Commit generation settings
-	AI     AIConfig     `mapstructure:"ai"`     // AI provider settings
+	Review  ReviewConfig  `mapstructure:"review"`  // Review behavior settings
+	Diff    DiffConfig    `mapstructure:"diff"`    // Settings for the diff sent to the AI
+	Commit  CommitConfig  `mapstructure:"commit"`  // Commit generation settings
+	Fix     FixConfig     `mapstructure:"fix"`     // Fix application settings
+	Lint    LintConfig    `mapstructure:"lint"`    // Linters run on the changed files before reviewing
+	Report  ReportConfig  `mapstructure:"report"`  // Report output settings
+	UI      UIConfig      `mapstructure:"ui"`      // Terminal UI settings
+	History HistoryConfig `mapstructure:"history"` // Review history settings
+	Forge   ForgeConfig   `mapstructure:"forge"`   // Code hosting service settings
+	CI      CIConfig      `mapstructure:"ci"`      // Settings for revi review --ci
+	AI      AIConfig      `mapstructure:"ai"`      // AI provider settings
+	Prompts PromptsConfig `mapstructure:"prompts"` // Project-specific guidance added to the AI prompts
+	// Review settings for branches matching a pattern, e.g. "release/*"
+	Branches map[string]BranchRules `mapstructure:"branches"`
 }
 
 // ReviewConfig holds configuration for code review behavior.
 type ReviewConfig struct {
-	Enabled bool        `mapstructure:"enabled"` // Whether to run code review
-	Block   bool        `mapstructure:"block"`   // Whether to block commits on high-severity issues
-	Modes   ReviewModes `mapstructure:"modes"`   // Individual mode toggles
+	Enabled          bool              `mapstructure:"enabled"`           // Whether to run code review
+	Block            bool              `mapstructure:"block"`             // Whether to block commits on issues of BlockThreshold or above
+	BlockThreshold   string            `mapstructure:"block_threshold"`   // Lowest severity that blocks: high, medium or low
+	IgnoreWhitespace bool              `mapstructure:"ignore_whitespace"` // Skip whitespace-only, reformat-only and moved hunks
+	Modes            ReviewModes       `mapstructure:"modes"`             // Individual mode toggles
+	CrossCheck       CrossCheckConfig  `mapstructure:"cross_check"`       // Second-model comparison settings
+	Sampling         float64           `mapstructure:"sampling"`          // Fraction of low-risk hunks to review (1 reviews everything)
+	CriticalPaths    []string          `mapstructure:"critical_paths"`    // Path patterns that are always fully reviewed when sampling
+	Include          []string          `mapstructure:"include"`           // Path patterns of the files reviewed (empty reviews every file)
+	Exclude          []string          `mapstructure:"exclude"`           // Path patterns of files left out of reviews
+	SeverityMap      map[string]string `mapstructure:"severity_map"`      // Extra severity names mapped onto high/medium/low
+	MaxSuggestions   int               `mapstructure:"max_suggestions"`   // Suggestions kept per review mode (0 keeps all)
+	MinConfidence    float64           `mapstructure:"min_confidence"`    // Confidence, from 0 to 1, below which reported issues are dropped (0 keeps all)
+	Concurrency      int               `mapstructure:"concurrency"`       // Reviews in flight at once (0 runs every mode at once)
+	Pacing           time.Duration     `mapstructure:"pacing"`            // Minimum delay between starting reviews
+	ConfirmCost      float64           `mapstructure:"confirm_cost"`      // Estimated USD cost above which reviews wait for confirmation (0 never asks)
+	TimeoutSeconds   int               `mapstructure:"timeout_seconds"`   // Longest one review mode may run (0 for no limit)
+	SmartSkip        bool              `mapstructure:"smart_skip"`        // Review doc-only and test-only diffs with the relevant modes only
+	SyntaxContext    int               `mapstructure:"syntax_context"`    // Lines a hunk may grow by to show its whole function or block (0 keeps three lines)
+	Offline          bool              `mapstructure:"offline"`           // Review with the rule checks, secrets scanner and linters only, without the AI
+}
+
+// BranchRules overrides review settings on the branches matching a pattern.
+// Unset rules keep the review settings.
+type BranchRules struct {
+	Block          *bool  `mapstructure:"block"`           // Whether issues block
+	BlockThreshold string `mapstructure:"block_threshold"` // Lowest severity that blocks
+}
+
+// DiffConfig selects the staged files whose content is left out of the diff
+// sent to the AI. The files are still listed, so their changes are noticed.
+type DiffConfig struct {
+	OmitBinary    bool     `mapstructure:"omit_binary"`    // Leave out binary files
+	OmitLockfiles bool     `mapstructure:"omit_lockfiles"` // Leave out lockfiles such as go.sum and package-lock.json
+	OmitGenerated bool     `mapstructure:"omit_generated"` // Leave out files marked as generated
+	OmitPaths     []string `mapstructure:"omit_paths"`     // Leave out files matching these patterns
 }
 
 // ReviewModes holds on/off settings for each review mode.
@@ -36,24 +83,154 @@ type ReviewModes struct {
 	Errors      bool `mapstructure:"errors"`      // Check error handling
 	Testing     bool `mapstructure:"testing"`     // Check test coverage
 	Docs        bool `mapstructure:"docs"`        // Check documentation
+	Secrets     bool `mapstructure:"secrets"`     // Check for committed credentials
+}
+
+// CrossCheckConfig holds settings for running selected modes with a second model
+// and comparing the findings. Cross-checking is disabled when Model is empty.
+type CrossCheckConfig struct {
+	Model string   `mapstructure:"model"` // Second AI model to compare against
+	Modes []string `mapstructure:"modes"` // Review modes to cross-check
 }
 
 // CommitConfig holds configuration for commit message generation.
 type CommitConfig struct {
-	Enabled bool `mapstructure:"enabled"` // Whether to generate commit messages
+	Enabled      bool     `mapstructure:"enabled"`       // Whether to generate commit messages
+	AutoConfirm  bool     `mapstructure:"auto_confirm"`  // Commit without prompting when no blocking issues were found
+	SummaryModel string   `mapstructure:"summary_model"` // Model summarizing each file of diffs too large to send whole
+	Fallback     bool     `mapstructure:"fallback"`      // Build a message from the file list when the AI backend fails
+	Fast         bool     `mapstructure:"fast"`          // Only generate the message, in a single AI call, without reviewing
+	Trailers     []string `mapstructure:"trailers"`      // Added to every message, e.g. "Reviewed-by: Jane Doe <jane@example.com>"
+	IssueTrailer string   `mapstructure:"issue_trailer"` // Trailer naming each issue ID found in the branch name, e.g. "Refs" (empty adds none)
+	IssuePattern string   `mapstructure:"issue_pattern"` // Regular expression matching issue IDs in branch names
+}
+
+// FixConfig holds configuration for previewing and applying suggested fixes.
+type FixConfig struct {
+	PreviewContext int               `mapstructure:"preview_context"` // Unchanged lines shown around a fix in previews
+	AutoApply      map[string]string `mapstructure:"auto_apply"`      // Highest severity applied without asking, per mode
+	Verify         string            `mapstructure:"verify"`          // Command run after each fix; the fix is rolled back if it fails
+	VerifyTimeout  time.Duration     `mapstructure:"verify_timeout"`  // Longest the verify command may run
+}
+
+// LintConfig holds the linters run on the changed files before reviewing.
+// Their findings are reported as issues and given to the AI as context.
+type LintConfig struct {
+	Linters []string      `mapstructure:"linters"` // Commands run with the system shell, e.g. "go vet {dirs}"
+	Timeout time.Duration `mapstructure:"timeout"` // Longest each linter may run
+}
+
+// ReportConfig holds configuration for review reports.
+type ReportConfig struct {
+	Links LinksConfig `mapstructure:"links"` // Permalinks from issues to the repository host
+	Blame bool        `mapstructure:"blame"` // Show the last author of the lines around each issue
+}
+
+// LinksConfig holds settings for linking issues to their file and line on the
+// repository hosting provider. Provider "auto" detects the provider from the
+// origin remote; "none" disables links.
+type LinksConfig struct {
+	Provider string `mapstructure:"provider"` // auto, github, gitlab, bitbucket or none
+	Template string `mapstructure:"template"` // Custom link template, overrides the provider's
+	RepoURL  string `mapstructure:"repo_url"` // Repository web URL, defaults to the origin remote
+}
+
+// UIConfig holds configuration for the terminal UI.
+type UIConfig struct {
+	Inline         bool              `mapstructure:"inline"`          // Render in the scrollback instead of the alternate screen
+	SeverityLabels map[string]string `mapstructure:"severity_labels"` // Label shown for each severity level instead of its name
+	ScreenReader   bool              `mapstructure:"screen_reader"`   // Plain, linear text output without the TUI or decorations
+	StreamLines    int               `mapstructure:"stream_lines"`    // Lines of model output shown for the selected review, 0 to hide
+	StatusFile     bool              `mapstructure:"status_file"`     // Write the outcome of the last review to .git/revi/status
+	Bell           bool              `mapstructure:"bell"`            // Ring the terminal bell when a review finishes
+	OSC            string            `mapstructure:"osc"`             // OSC sequence sent when a review finishes, with {status} expanded
+}
+
+// HistoryConfig holds configuration for the log of past reviews.
+type HistoryConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`     // Whether to record review runs
+	MaxEntries int           `mapstructure:"max_entries"` // Runs kept before the oldest are dropped (0 keeps all)
+	MaxAge     time.Duration `mapstructure:"max_age"`     // Runs older than this are dropped (0 keeps all)
+	MaxSizeMB  int           `mapstructure:"max_size_mb"` // Size the history file is kept under (0 for no limit)
+}
+
+// ForgeConfig holds configuration for the code hosting service merge requests
+// are reviewed on. The access token is read from the environment.
+type ForgeConfig struct {
+	Provider string `mapstructure:"provider"` // gitlab, or auto to detect it from the origin remote
+	URL      string `mapstructure:"url"`      // Base URL of a host served below a path, e.g. https://example.com/gitlab
+}
+
+// CIConfig holds configuration for revi review --ci.
+type CIConfig struct {
+	ExitCodes CIExitCodes `mapstructure:"exit_codes"` // Exit status for each severity found
+}
+
+// CIExitCodes holds the exit status revi review --ci uses when the most severe
+// issue found has each severity. The highest status of the severities found wins.
+type CIExitCodes struct {
+	High   int `mapstructure:"high"`
+	Medium int `mapstructure:"medium"`
+	Low    int `mapstructure:"low"`
 }
 
 // AIConfig holds configuration for the AI provider integration.
 // The model can be overridden via REVI_AI_MODEL environment variable or --model flag.
 type AIConfig struct {
-	Model string `mapstructure:"model"` // AI model identifier (e.g., claude-opus-4-5-20251101)
+	Model         string            `mapstructure:"model"`          // AI model identifier (e.g., claude-opus-4-5-20251101)
+	Models        map[string]string `mapstructure:"models"`         // Model reviewing a mode instead of Model, per mode
+	Retry         RetryConfig       `mapstructure:"retry"`          // How failed AI calls are retried
+	TranscriptDir string            `mapstructure:"transcript_dir"` // Directory recording every prompt and response of each run (empty records nothing)
+	RedactSecrets bool              `mapstructure:"redact_secrets"` // Replace secrets in diffs before they are sent to the AI
+	MaxDiffChars  int               `mapstructure:"max_diff_chars"` // Most of a diff sent in one prompt (0 uses the default)
+}
+
+// RetryConfig holds how often and for how long failed AI calls are retried.
+// CI runs usually want a tighter budget than interactive ones.
+type RetryConfig struct {
+	RateLimit int           `mapstructure:"rate_limit"` // Retries after a rate limit
+	Network   int           `mapstructure:"network"`    // Retries after a network or connection error
+	Process   int           `mapstructure:"process"`    // Retries after the Claude Code CLI subprocess fails
+	Backoff   time.Duration `mapstructure:"backoff"`    // First rate-limit backoff, doubled on each retry
+	Delay     time.Duration `mapstructure:"delay"`      // Wait before retrying network and subprocess failures
+	Deadline  time.Duration `mapstructure:"deadline"`   // Longest one call may spend retrying (0 for no limit)
+}
+
+// PromptsConfig holds project-specific guidance added to the AI prompts, after
+// any found in .revi/prompts/ at the repository root.
+type PromptsConfig struct {
+	Review string            `mapstructure:"review"` // Added to every review prompt
+	Modes  map[string]string `mapstructure:"modes"`  // Added to the review prompts of one mode
+	Commit string            `mapstructure:"commit"` // Added to the commit message prompt
 }
 
 var (
 	cfg        Config
 	configFile string
+	// explicitFile is the file set with SetConfigFile, read instead of
+	// searching for .revi.yaml
+	explicitFile string
+	// loadErr is why explicitFile could not be loaded, or the problems
+	// ValidateFile found in the config file loaded
+	loadErr error
+	// branchRules are the branches rules ApplyBranchRules applied
+	branchRules BranchRules
 )
 
+// SetConfigFile makes Init read the config file at path instead of searching
+// the current and home directories for .revi.yaml. A missing or invalid file
+// is then reported by LoadError rather than ignored. Empty restores the search.
+func SetConfigFile(path string) {
+	explicitFile = path
+}
+
+// LoadError returns why the config file set with SetConfigFile could not be
+// loaded by Init, or an error wrapping ErrInvalidConfig listing the problems
+// in the config file Init loaded. It returns nil if the file loaded cleanly.
+func LoadError() error {
+	return loadErr
+}
+
 // Init initializes the configuration system by setting defaults,
 // loading config files from current and home directories, and
 // enabling environment variable overrides with the REVI_ prefix.
@@ -67,23 +244,116 @@ func setDefaults() {
 	// Review defaults
 	viper.SetDefault("review.enabled", true)
 	viper.SetDefault("review.block", true)
+	viper.SetDefault("review.block_threshold", "high")
+	viper.SetDefault("review.ignore_whitespace", false)
 	viper.SetDefault("review.modes.security", true)
 	viper.SetDefault("review.modes.performance", true)
 	viper.SetDefault("review.modes.style", true)
 	viper.SetDefault("review.modes.errors", true)
 	viper.SetDefault("review.modes.testing", true)
 	viper.SetDefault("review.modes.docs", true)
+	viper.SetDefault("review.modes.secrets", true)
+	viper.SetDefault("review.cross_check.model", "")
+	viper.SetDefault("review.cross_check.modes", []string{"security"})
+	viper.SetDefault("review.sampling", 1.0)
+	viper.SetDefault("review.critical_paths", []string{})
+	viper.SetDefault("review.include", []string{})
+	viper.SetDefault("review.exclude", []string{})
+	viper.SetDefault("review.max_suggestions", 5)
+	viper.SetDefault("review.min_confidence", 0.0)
+	viper.SetDefault("review.concurrency", 0)
+	viper.SetDefault("review.pacing", "0s")
+	viper.SetDefault("review.confirm_cost", 2.0)
+	viper.SetDefault("review.timeout_seconds", 0)
+	viper.SetDefault("review.smart_skip", true)
+	viper.SetDefault("review.syntax_context", 40)
+	viper.SetDefault("review.offline", false)
+
+	// Diff defaults
+	viper.SetDefault("diff.omit_binary", true)
+	viper.SetDefault("diff.omit_lockfiles", true)
+	viper.SetDefault("diff.omit_generated", true)
+	viper.SetDefault("diff.omit_paths", []string{})
 
 	// Commit defaults
 	viper.SetDefault("commit.enabled", true)
+	viper.SetDefault("commit.auto_confirm", false)
+	viper.SetDefault("commit.summary_model", "claude-haiku-4-5-20251001")
+	viper.SetDefault("commit.fallback", true)
+	viper.SetDefault("commit.fast", false)
+	viper.SetDefault("commit.trailers", []string{})
+	viper.SetDefault("commit.issue_trailer", "")
+	viper.SetDefault("commit.issue_pattern", "[A-Z][A-Z0-9]+-[0-9]+")
+
+	// Fix defaults
+	viper.SetDefault("fix.preview_context", 3)
+	viper.SetDefault("fix.verify", "")
+	viper.SetDefault("fix.verify_timeout", 5*time.Minute)
+
+	// Lint defaults
+	viper.SetDefault("lint.linters", []string{})
+	viper.SetDefault("lint.timeout", 2*time.Minute)
+
+	// Report defaults
+	viper.SetDefault("report.links.provider", "auto")
+	viper.SetDefault("report.links.template", "")
+	viper.SetDefault("report.links.repo_url", "")
+	viper.SetDefault("report.blame", false)
+
+	// UI defaults
+	viper.SetDefault("ui.inline", false)
+	viper.SetDefault("ui.screen_reader", false)
+	viper.SetDefault("ui.stream_lines", 6)
+	viper.SetDefault("ui.status_file", true)
+	viper.SetDefault("ui.bell", false)
+	viper.SetDefault("ui.osc", "")
+
+	// History defaults
+	viper.SetDefault("history.enabled", true)
+	viper.SetDefault("history.max_entries", 500)
+	viper.SetDefault("history.max_age", "0s")
+	viper.SetDefault("history.max_size_mb", 10)
+
+	// Forge defaults
+	viper.SetDefault("forge.provider", "auto")
+	viper.SetDefault("forge.url", "")
+
+	// CI defaults
+	viper.SetDefault("ci.exit_codes.high", 2)
+	viper.SetDefault("ci.exit_codes.medium", 1)
+	viper.SetDefault("ci.exit_codes.low", 0)
 
 	// AI defaults - uses Claude Opus 4.5 as the default model
 	viper.SetDefault("ai.model", "claude-opus-4-5-20251101")
+	viper.SetDefault("ai.retry.rate_limit", 3)
+	viper.SetDefault("ai.retry.network", 1)
+	viper.SetDefault("ai.retry.process", 1)
+	viper.SetDefault("ai.retry.backoff", "1s")
+	viper.SetDefault("ai.retry.delay", "2s")
+	viper.SetDefault("ai.retry.deadline", "0s")
+	viper.SetDefault("ai.transcript_dir", "")
+	viper.SetDefault("ai.redact_secrets", true)
+	viper.SetDefault("ai.max_diff_chars", 100000)
+
+	// Prompt defaults
+	viper.SetDefault("prompts.review", "")
+	viper.SetDefault("prompts.commit", "")
+
+	// Branch defaults
+	viper.SetDefault("branches", map[string]any{})
 }
 
 func loadConfigFile() {
-	viper.SetConfigName(".revi")
 	viper.SetConfigType("yaml")
+	loadErr = nil
+	if explicitFile != "" {
+		if loadErr = loadExplicitFile(explicitFile); loadErr == nil {
+			loadErr = validateLoadedFile()
+		}
+		return
+	}
+
+	viper.SetConfigName(".revi")
 
 	// Add config paths in priority order
 	// 1. Current directory (project config)
@@ -95,15 +365,76 @@ func loadConfigFile() {
 
 	if err := viper.ReadInConfig(); err == nil {
 		configFile = viper.ConfigFileUsed()
+		loadErr = validateLoadedFile()
 	}
 }
 
+// validateLoadedFile returns the problems ValidateFile finds in the config
+// file Init loaded as an error
+func validateLoadedFile() error {
+	problems, err := ValidateFile(configFile)
+	if err != nil {
+		return err
+	}
+	return ProblemsError(configFile, problems)
+}
+
+// loadExplicitFile reads the config file at path, which must exist and hold
+// valid settings
+func loadExplicitFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := viper.Unmarshal(&Config{}); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	configFile = path
+	return nil
+}
+
 func loadEnvVars() {
 	viper.SetEnvPrefix("REVI")
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 }
 
+// ApplyBranchRules selects the branches rules whose pattern matches branch,
+// returned by AppliedBranchRules, so a release branch can block on
+// medium-severity issues while experiments are not blocked at all. It returns
+// the pattern applied, or "" if none matches.
+func ApplyBranchRules(branch string) string {
+	pattern, rules, _ := Get().BranchRulesFor(branch)
+	branchRules = rules
+	return pattern
+}
+
+// AppliedBranchRules returns the branches rules selected by ApplyBranchRules,
+// which are empty if no pattern matched the branch.
+func AppliedBranchRules() BranchRules {
+	return branchRules
+}
+
+// BranchRulesFor returns the branches rules for branch and their pattern, and
+// false if no pattern matches it. Patterns use path.Match syntax, so "*" does
+// not match "/"; of several matching patterns the longest wins.
+func (c *Config) BranchRulesFor(branch string) (string, BranchRules, bool) {
+	best := ""
+	found := false
+	for pattern := range c.Branches {
+		if matched, _ := path.Match(pattern, branch); !matched || branch == "" {
+			continue
+		}
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	return best, c.Branches[best], found
+}
+
 // BindFlags binds cobra command-line flags to viper configuration values.
 // This enables flags like --review, --block, and --model to override config file settings.
 func BindFlags(cmd *cobra.Command) {
@@ -118,6 +449,7 @@ func BindFlags(cmd *cobra.Command) {
 	_ = viper.BindPFlag("review.modes.errors", cmd.PersistentFlags().Lookup("errors"))
 	_ = viper.BindPFlag("review.modes.testing", cmd.PersistentFlags().Lookup("testing"))
 	_ = viper.BindPFlag("review.modes.docs", cmd.PersistentFlags().Lookup("docs"))
+	_ = viper.BindPFlag("review.modes.secrets", cmd.PersistentFlags().Lookup("secrets"))
 
 	// AI model flag
 	_ = viper.BindPFlag("ai.model", cmd.PersistentFlags().Lookup("model"))
@@ -128,6 +460,10 @@ func BindFlags(cmd *cobra.Command) {
 func Get() *Config {
 	// Error is ignored as defaults are always valid
 	_ = viper.Unmarshal(&cfg)
+	// Branch patterns such as release/1.* would be split at the dots, which
+	// viper separates keys with, so their map is decoded as it was read
+	cfg.Branches = nil
+	_ = viper.UnmarshalKey("branches", &cfg.Branches)
 	return &cfg
 }
 
@@ -163,6 +499,27 @@ func IsBlockEnabled(cmd *cobra.Command) bool {
 	return viper.GetBool("review.block")
 }
 
+// IsAutoConfirmEnabled checks if the commit confirmation prompt should be skipped,
+// considering both the --yes flag and the commit.auto_confirm config setting.
+func IsAutoConfirmEnabled(cmd *cobra.Command) bool {
+	yes, _ := cmd.Flags().GetBool("yes")
+	if yes {
+		return true
+	}
+	return viper.GetBool("commit.auto_confirm")
+}
+
+// IsFastCommitEnabled checks if the commit message should be generated in a
+// single AI call without a review, considering both the --fast flag and the
+// commit.fast config setting. An explicit --fast=false overrides the setting.
+func IsFastCommitEnabled(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("fast") {
+		fast, _ := cmd.Flags().GetBool("fast")
+		return fast
+	}
+	return viper.GetBool("commit.fast")
+}
+
 // GetEnabledModes returns the list of review modes that should be run.
 // It respects the --all flag, individual --no-<mode> flags, and config settings.
 func GetEnabledModes(cmd *cobra.Command) []string {
@@ -189,6 +546,7 @@ func GetEnabledModes(cmd *cobra.Command) []string {
 	checkMode("errors", cfg.Review.Modes.Errors, "no-errors")
 	checkMode("testing", cfg.Review.Modes.Testing, "no-testing")
 	checkMode("docs", cfg.Review.Modes.Docs, "no-docs")
+	checkMode("secrets", cfg.Review.Modes.Secrets, "no-secrets")
 
 	return modes
 }
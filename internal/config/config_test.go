@@ -1,7 +1,10 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,6 +15,9 @@ func resetForTest(t *testing.T) {
 	viper.Reset()
 	cfg = Config{}
 	configFile = ""
+	explicitFile = ""
+	loadErr = nil
+	branchRules = BranchRules{}
 	// Prevent accidentally reading a real user config from HOME.
 	t.Setenv("HOME", t.TempDir())
 }
@@ -25,6 +31,7 @@ func newCmdForEnabledModesTest() *cobra.Command {
 	cmd.Flags().Bool("no-errors", false, "")
 	cmd.Flags().Bool("no-testing", false, "")
 	cmd.Flags().Bool("no-docs", false, "")
+	cmd.Flags().Bool("no-secrets", false, "")
 	return cmd
 }
 
@@ -39,12 +46,87 @@ func TestInit_SetsDefaults(t *testing.T) {
 	if !c.Review.Block {
 		t.Fatal("expected review.block default to be true")
 	}
+	if !c.Review.SmartSkip {
+		t.Fatal("expected review.smart_skip default to be true")
+	}
 	if !c.Commit.Enabled {
 		t.Fatal("expected commit.enabled default to be true")
 	}
+	if c.Commit.AutoConfirm {
+		t.Fatal("expected commit.auto_confirm default to be false")
+	}
+	if c.Commit.SummaryModel != "claude-haiku-4-5-20251001" {
+		t.Fatalf("expected commit.summary_model default %q, got %q", "claude-haiku-4-5-20251001", c.Commit.SummaryModel)
+	}
+	if !c.Commit.Fallback {
+		t.Fatal("expected commit.fallback default to be true")
+	}
+	if c.Commit.Fast {
+		t.Fatal("expected commit.fast default to be false")
+	}
+	if c.Review.CrossCheck.Model != "" {
+		t.Fatalf("expected cross-checking to be disabled by default, got model %q", c.Review.CrossCheck.Model)
+	}
+	if len(c.Review.CrossCheck.Modes) != 1 || c.Review.CrossCheck.Modes[0] != "security" {
+		t.Fatalf("expected review.cross_check.modes default [security], got %v", c.Review.CrossCheck.Modes)
+	}
+	if c.Review.Sampling != 1 {
+		t.Fatalf("expected review.sampling default 1, got %v", c.Review.Sampling)
+	}
+	if c.Review.MaxSuggestions != 5 {
+		t.Fatalf("expected review.max_suggestions default 5, got %d", c.Review.MaxSuggestions)
+	}
+	if c.Review.ConfirmCost != 2.0 {
+		t.Fatalf("expected review.confirm_cost default 2.0, got %v", c.Review.ConfirmCost)
+	}
+	if !c.Diff.OmitBinary || !c.Diff.OmitLockfiles || !c.Diff.OmitGenerated || len(c.Diff.OmitPaths) != 0 {
+		t.Fatalf("expected binary, lock and generated files to be omitted from diffs by default, got %+v", c.Diff)
+	}
+	if c.Review.TimeoutSeconds != 0 {
+		t.Fatalf("expected review modes to have no time limit by default, got %d", c.Review.TimeoutSeconds)
+	}
+	if c.Review.Concurrency != 0 || c.Review.Pacing != 0 {
+		t.Fatalf("expected reviews to be unlimited by default, got concurrency %d pacing %v", c.Review.Concurrency, c.Review.Pacing)
+	}
+	if c.Fix.PreviewContext != 3 {
+		t.Fatalf("expected fix.preview_context default 3, got %d", c.Fix.PreviewContext)
+	}
+	if c.Fix.Verify != "" || c.Fix.VerifyTimeout != 5*time.Minute {
+		t.Fatalf("expected no fix.verify command with a 5m timeout by default, got %q %v", c.Fix.Verify, c.Fix.VerifyTimeout)
+	}
+	if c.Report.Blame {
+		t.Fatal("expected report.blame default to be false")
+	}
+	if c.UI.Inline {
+		t.Fatal("expected ui.inline default to be false")
+	}
+	if c.UI.ScreenReader || len(c.UI.SeverityLabels) != 0 {
+		t.Fatal("expected no screen reader mode or severity labels by default")
+	}
+	if c.UI.StreamLines != 6 {
+		t.Fatalf("expected ui.stream_lines default 6, got %d", c.UI.StreamLines)
+	}
+	if !c.UI.StatusFile || c.UI.Bell || c.UI.OSC != "" {
+		t.Fatalf("expected ui.status_file on without bell or OSC by default, got %v, %v and %q", c.UI.StatusFile, c.UI.Bell, c.UI.OSC)
+	}
+	if codes := c.CI.ExitCodes; codes.High != 2 || codes.Medium != 1 || codes.Low != 0 {
+		t.Fatalf("expected ci.exit_codes 2/1/0 by default, got %+v", codes)
+	}
+	if c.Forge.Provider != "auto" || c.Forge.URL != "" {
+		t.Fatalf("expected forge.provider auto without a URL by default, got %q and %q", c.Forge.Provider, c.Forge.URL)
+	}
+	if !c.History.Enabled || c.History.MaxEntries != 500 {
+		t.Fatalf("expected history enabled with 500 entries by default, got %v and %d", c.History.Enabled, c.History.MaxEntries)
+	}
+	if c.History.MaxAge != 0 || c.History.MaxSizeMB != 10 {
+		t.Fatalf("expected no history age limit and 10 MB by default, got %v and %d", c.History.MaxAge, c.History.MaxSizeMB)
+	}
 	if c.AI.Model != "claude-opus-4-5-20251101" {
 		t.Fatalf("expected ai.model default %q, got %q", "claude-opus-4-5-20251101", c.AI.Model)
 	}
+	if c.Prompts.Review != "" || c.Prompts.Commit != "" || len(c.Prompts.Modes) != 0 {
+		t.Fatalf("expected no prompt guidance by default, got %+v", c.Prompts)
+	}
 
 	if GetConfigPath() != "" {
 		t.Fatalf("expected no config file to be loaded in tests, got %q", GetConfigPath())
@@ -67,6 +149,57 @@ func TestInit_EnvOverrides(t *testing.T) {
 	}
 }
 
+func TestInit_ExplicitConfigFile(t *testing.T) {
+	resetForTest(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(".revi.yaml", []byte("ai:\n  model: claude-sonnet-4-5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ci.yaml")
+	if err := os.WriteFile(path, []byte("ai:\n  model: claude-haiku-4-5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	SetConfigFile(path)
+	Init()
+	if err := LoadError(); err != nil {
+		t.Fatalf("LoadError() = %v", err)
+	}
+	if got := Get().AI.Model; got != "claude-haiku-4-5" {
+		t.Errorf("ai.model = %q, want the explicit file's claude-haiku-4-5", got)
+	}
+	if GetConfigPath() != path {
+		t.Errorf("GetConfigPath() = %q, want %q", GetConfigPath(), path)
+	}
+}
+
+func TestInit_ExplicitConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"missing": "",
+		"syntax":  "ai: [model\n",
+		"types":   "review:\n  block: maybe\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			resetForTest(t)
+			path := filepath.Join(dir, name+".yaml")
+			if content != "" {
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			SetConfigFile(path)
+			Init()
+			if LoadError() == nil {
+				t.Fatal("expected an error instead of falling back to the defaults")
+			}
+		})
+	}
+}
+
 func TestGetEnabledModes_All(t *testing.T) {
 	resetForTest(t)
 	Init()
@@ -75,8 +208,8 @@ func TestGetEnabledModes_All(t *testing.T) {
 	_ = cmd.Flags().Set("all", "true")
 
 	modes := GetEnabledModes(cmd)
-	if len(modes) != 6 {
-		t.Fatalf("expected 6 modes when --all is set, got %d (%v)", len(modes), modes)
+	if len(modes) != 7 {
+		t.Fatalf("expected 7 modes when --all is set, got %d (%v)", len(modes), modes)
 	}
 }
 
@@ -112,3 +245,94 @@ func TestGetDefaultConfigPath_UsesHome(t *testing.T) {
 		t.Fatalf("expected %q, got %q", home+"/.revi.yaml", p)
 	}
 }
+
+func TestIsAutoConfirmEnabled(t *testing.T) {
+	resetForTest(t)
+	Init()
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("yes", false, "")
+
+	if IsAutoConfirmEnabled(cmd) {
+		t.Fatal("expected auto-confirm to be disabled by default")
+	}
+
+	viper.Set("commit.auto_confirm", true)
+	if !IsAutoConfirmEnabled(cmd) {
+		t.Fatal("expected commit.auto_confirm=true to enable auto-confirm")
+	}
+
+	viper.Set("commit.auto_confirm", false)
+	_ = cmd.Flags().Set("yes", "true")
+	if !IsAutoConfirmEnabled(cmd) {
+		t.Fatal("expected --yes to enable auto-confirm")
+	}
+}
+
+func TestIsFastCommitEnabled(t *testing.T) {
+	resetForTest(t)
+	Init()
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().Bool("fast", false, "")
+
+	if IsFastCommitEnabled(cmd) {
+		t.Fatal("expected fast commits to be disabled by default")
+	}
+
+	viper.Set("commit.fast", true)
+	if !IsFastCommitEnabled(cmd) {
+		t.Fatal("expected commit.fast=true to enable fast commits")
+	}
+
+	_ = cmd.Flags().Set("fast", "false")
+	if IsFastCommitEnabled(cmd) {
+		t.Fatal("expected --fast=false to override commit.fast")
+	}
+}
+
+func TestApplyBranchRules(t *testing.T) {
+	resetForTest(t)
+	t.Chdir(t.TempDir())
+	content := `branches:
+  "release/*":
+    block_threshold: medium
+  "release/1.*":
+    block_threshold: low
+  "spike/*":
+    block: false
+`
+	if err := os.WriteFile(".revi.yaml", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	Init()
+	if err := LoadError(); err != nil {
+		t.Fatalf("LoadError() = %v", err)
+	}
+
+	tests := []struct {
+		branch    string
+		pattern   string
+		threshold string
+		unblocked bool
+	}{
+		{"release/2.0", "release/*", "medium", false},
+		{"release/1.2", "release/1.*", "low", false},
+		{"spike/cache", "spike/*", "", true},
+		{"release/1.2/hotfix", "", "", false},
+		{"main", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		if pattern := ApplyBranchRules(tt.branch); pattern != tt.pattern {
+			t.Errorf("ApplyBranchRules(%q) = %q, want %q", tt.branch, pattern, tt.pattern)
+		}
+		rules := AppliedBranchRules()
+		if rules.BlockThreshold != tt.threshold {
+			t.Errorf("%s: block_threshold = %q, want %q", tt.branch, rules.BlockThreshold, tt.threshold)
+		}
+		if unblocked := rules.Block != nil && !*rules.Block; unblocked != tt.unblocked {
+			t.Errorf("%s: block: false applied = %v, want %v", tt.branch, unblocked, tt.unblocked)
+		}
+	}
+}
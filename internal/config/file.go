@@ -0,0 +1,402 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	// ErrConfigExists is returned by WriteSkeleton when the file already exists.
+	ErrConfigExists = errors.New("config file already exists")
+	// ErrUnknownKey is returned by SetValue for keys that are not settings.
+	ErrUnknownKey = errors.New("unknown config key")
+	// ErrInvalidValue is returned by SetValue for values of the wrong type.
+	ErrInvalidValue = errors.New("invalid config value")
+)
+
+// ProjectConfigPath is the project config file, read from the current directory
+const ProjectConfigPath = ".revi.yaml"
+
+// skeleton is the config file written by revi config init. Every setting is
+// present with its default value, so TestSkeleton_MatchesDefaults fails when
+// a setting is added without updating it.
+const skeleton = `# revi configuration
+#
+# revi reads ./.revi.yaml, then ~/.revi.yaml. Every setting below has its
+# default value; environment variables override them with the REVI_ prefix,
+# e.g. REVI_AI_MODEL or REVI_REVIEW_BLOCK. Change a value from the command
+# line with: revi config set review.block false
+
+review:
+  enabled: true
+  block: true  # Block commit on high-severity issues
+  block_threshold: high  # Lowest severity that blocks: high, medium or low
+  ignore_whitespace: false  # Skip whitespace-only, reformat-only and moved hunks
+  modes:
+    security: true
+    performance: true
+    style: true
+    errors: true
+    testing: true
+    docs: true
+    secrets: true  # Also scanned locally for known token formats and high-entropy values
+  cross_check:
+    model: ""  # Second model to compare findings against (empty disables)
+    modes: [security]  # Modes to run with both models
+  sampling: 1  # Fraction of low-risk hunks to review, e.g. 0.25 (1 reviews everything)
+  critical_paths: []  # Always fully reviewed when sampling, e.g. ["auth/**", "*.sql"]
+  include: []  # Review only files matching these patterns, e.g. ["internal/api/**"] (empty reviews all)
+  exclude: []  # Leave files matching these patterns out of reviews, e.g. ["internal/api/client/**"]
+  severity_map: {}  # Extra severity names mapped onto high/medium/low, e.g. p0: high
+  max_suggestions: 5  # Suggestions kept per review mode (0 keeps all)
+  min_confidence: 0  # Drop issues the model is less sure of than this, from 0 to 1, e.g. 0.6 (0 keeps all)
+  concurrency: 0  # Review modes running at once, e.g. 2 to avoid rate limits (0 runs all at once)
+  pacing: 0s  # Minimum delay between starting reviews, e.g. 2s
+  confirm_cost: 2  # Ask before reviews estimated to cost more than this many USD (0 never asks)
+  timeout_seconds: 0  # Longest one review mode may run before it is reported as timed out (0 for no limit)
+  smart_skip: true  # Review doc-only diffs with the docs mode and test-only diffs with testing and errors
+  syntax_context: 40  # Lines a hunk may grow by to show its whole function or block (0 keeps three lines)
+  offline: false  # Review without the AI: only the rule checks, secrets scanner and linters run
+
+diff:  # Files listed without their content in the diff sent to the AI
+  omit_binary: true
+  omit_lockfiles: true  # go.sum, package-lock.json, yarn.lock, Cargo.lock, ...
+  omit_generated: true  # Files marked "Code generated ... DO NOT EDIT." or "@generated"
+  omit_paths: []  # Extra path patterns, e.g. ["vendor/**", "*.pb.go"]
+
+commit:
+  enabled: true
+  auto_confirm: false  # Skip confirmation when no blocking issues were found
+  summary_model: "claude-haiku-4-5-20251001"  # Summarizes each file of diffs too large to send whole
+  fallback: true  # Build a message from the file list, marked as generated without AI, when the AI backend fails
+  fast: false  # Only generate the message: one AI call, and the pre-commit hook skips its review
+  trailers: []  # Added to every message, e.g. ["Reviewed-by: Jane Doe <jane@example.com>"]
+  issue_trailer: ""  # Trailer naming each issue ID found in the branch name, e.g. "Refs" (empty adds none)
+  issue_pattern: "[A-Z][A-Z0-9]+-[0-9]+"  # Issue IDs looked for in the branch name, such as PROJ-123
+
+fix:
+  preview_context: 3  # Unchanged lines shown around each fix preview
+  auto_apply: {}  # With --fix, apply fixes up to this severity per mode without asking, e.g. style: low
+  verify: ""  # Command run after each fix, e.g. "go build ./..."; failing fixes are rolled back
+  verify_timeout: 5m  # Longest the verify command may run before the fix is rolled back
+
+lint:
+  linters: []  # Run on the changed files before reviewing, e.g. ["go vet {dirs}", "staticcheck {dirs}"]; {files} and {dirs} list them
+  timeout: 2m  # Longest each linter may run
+
+report:
+  links:
+    provider: auto  # github, gitlab, bitbucket, or none to disable links
+    repo_url: ""  # Repository web URL (defaults to the origin remote)
+    template: ""  # Custom link format, e.g. "{repo}/blob/{commit}/{path}#L{line}"
+  blame: false  # Show who last changed the lines around each issue
+
+ui:
+  inline: false  # Render the TUI in the scrollback with a compact layout
+  screen_reader: false  # Plain, linear text output without the TUI
+  stream_lines: 6  # Lines of model output shown for the selected review while it runs; 0 hides them
+  status_file: true  # Write the outcome of the last review to .git/revi/status for shell prompts
+  bell: false  # Ring the terminal bell when a review finishes
+  osc: ""  # OSC sequence sent to the terminal when a review finishes; {status} becomes clean, issues or blocked
+  severity_labels: {}  # Text shown for each severity instead of HIGH/MEDIUM/LOW, e.g. high: "[!!] HIGH"
+
+forge:
+  provider: auto  # gitlab, or auto to detect it from the origin remote
+  url: ""  # Base URL when GitLab is served below a path, e.g. https://example.com/gitlab
+
+ci:
+  exit_codes:  # Exit status of revi review --ci for the most severe issue found (0 to 125)
+    high: 2
+    medium: 1
+    low: 0
+
+history:
+  enabled: true  # Record review runs for revi history
+  max_entries: 500  # Runs kept before the oldest are dropped (0 keeps all)
+  max_age: 0s  # Runs older than this are dropped, e.g. 2160h for 90 days (0s keeps all)
+  max_size_mb: 10  # Size the history file is kept under by dropping the oldest runs (0 for no limit)
+
+ai:
+  model: "claude-opus-4-5-20251101"  # AI model to use
+  models: {}  # Model reviewing a mode instead of ai.model, e.g. style: haiku, security: opus
+  retry:
+    rate_limit: 3  # Retries after a rate limit (--rate-limit-retries)
+    network: 1  # Retries after a network or connection error
+    process: 1  # Retries after the Claude Code CLI subprocess fails
+    backoff: 1s  # First rate-limit backoff, doubled on each retry (--retry-backoff)
+    delay: 2s  # Wait before retrying network and subprocess failures
+    deadline: 0s  # Longest one AI call may spend retrying (--retry-deadline, 0 for no limit)
+  transcript_dir: ""  # Record every prompt and response, secrets redacted, for audits, e.g. .revi/transcripts (empty records nothing)
+  redact_secrets: true  # Replace API keys, passwords, private keys and .env values in diffs before they are sent to the AI
+  max_diff_chars: 100000  # Most of a diff sent in one prompt; larger diffs are reviewed in chunks, and elsewhere lose vendored code, test fixtures and data files first
+
+prompts:  # Project guidance added to the AI prompts, after any in .revi/prompts/
+  review: ""  # Added to every review prompt, e.g. "We use sqlc; don't flag raw SQL in *.sql.go"
+  modes: {}  # Added to the review prompts of one mode, e.g. security: "Inputs are validated by the gateway"
+  commit: ""  # Added to the commit message prompt, e.g. "Use the Jira key as the scope"
+
+branches: {}  # Review settings for matching branches, e.g. "release/*": {block_threshold: medium}, "spike/*": {block: false}
+`
+
+// Skeleton returns the commented config file written by revi config init
+func Skeleton() string {
+	return skeleton
+}
+
+// WriteSkeleton writes Skeleton to path. Returns an error wrapping
+// ErrConfigExists if the file exists, unless force is set.
+func WriteSkeleton(path string, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s: %w", path, ErrConfigExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.WriteString(skeleton); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// Keys returns every setting's key, sorted. Keys of settings holding a map,
+// such as review.severity_map, end in ".<name>".
+func Keys() []string {
+	var keys []string
+	var walk func(prefix string, t reflect.Type)
+	walk = func(prefix string, t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + field.Tag.Get("mapstructure")
+			switch field.Type.Kind() {
+			case reflect.Struct:
+				walk(key+".", field.Type)
+			case reflect.Map:
+				keys = append(keys, key+".<name>")
+			default:
+				keys = append(keys, key)
+			}
+		}
+	}
+	walk("", reflect.TypeOf(Config{}))
+	sort.Strings(keys)
+	return keys
+}
+
+// SetValue sets key to value in the config file at path, creating the file if
+// needed and keeping the rest of it, comments included. value is parsed as
+// the setting's type; lists are comma-separated. Entries of map settings are
+// set one at a time, e.g. review.severity_map.p0.
+func SetValue(path, key, value string) error {
+	t, err := keyType(key)
+	if err != nil {
+		return err
+	}
+	node, err := valueNode(t, key, value)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s does not hold a YAML mapping", path)
+	}
+	setNode(doc.Content[0], strings.Split(key, "."), node)
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, separateSections(b.Bytes()), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// separateSections puts a blank line before each top-level key after the
+// first, as in Skeleton, since the YAML encoder drops blank lines
+func separateSections(data []byte) []byte {
+	lines := strings.SplitAfter(string(data), "\n")
+	var b strings.Builder
+	seenKey := false
+	for i, line := range lines {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '#' && line[0] != '\n' && line[0] != '-'
+		if topLevel && seenKey && i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			b.WriteString("\n")
+		}
+		seenKey = seenKey || topLevel
+		b.WriteString(line)
+	}
+	return []byte(b.String())
+}
+
+// keyType returns the type of the Config field key names, or of the entries
+// of the map it names an entry of
+func keyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(t, part)
+			if !ok {
+				return nil, fmt.Errorf("%w %q", ErrUnknownKey, key)
+			}
+			t = field.Type
+		case reflect.Map:
+			// Entries holding sections are followed by the key within them
+			if part == "" || (i != len(parts)-1) != (t.Elem().Kind() == reflect.Struct) {
+				return nil, fmt.Errorf("%w %q", ErrUnknownKey, key)
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownKey, key)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return nil, fmt.Errorf("%w %q: it is a section, set one of its keys", ErrUnknownKey, key)
+	case reflect.Map:
+		return nil, fmt.Errorf("%w %q: set one entry at a time, as %s.<name>", ErrUnknownKey, key, key)
+	}
+	return t, nil
+}
+
+// fieldByTag returns the field of struct type t with the given mapstructure tag
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Tag.Get("mapstructure") == tag {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// valueNode parses value as type t and returns it as a YAML node
+func valueNode(t reflect.Type, key, value string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode}
+	invalid := func(want string) error {
+		return fmt.Errorf("%w for %s: %q is not %s", ErrInvalidValue, key, value, want)
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, invalid("a duration such as 30s or 5m")
+		}
+		node.Value = value
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalid("true or false")
+		}
+		node.Value = strconv.FormatBool(b)
+	case t.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, invalid("a whole number")
+		}
+		node.Value = strconv.Itoa(n)
+	case t.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, invalid("a number")
+		}
+		node.Value = strconv.FormatFloat(f, 'g', -1, 64)
+	case t.Kind() == reflect.String:
+		node.SetString(value)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		node = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				child := &yaml.Node{}
+				child.SetString(item)
+				node.Content = append(node.Content, child)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w for %s: unsupported setting type %s", ErrInvalidValue, key, t)
+	}
+	return node, nil
+}
+
+// setNode sets the value at path below mapping m to value, adding the keys
+// that are missing. A replaced value's comment is kept.
+func setNode(m *yaml.Node, path []string, value *yaml.Node) {
+	for i, part := range path {
+		idx := -1
+		for j := 0; j+1 < len(m.Content); j += 2 {
+			if m.Content[j].Value == part {
+				idx = j + 1
+				break
+			}
+		}
+
+		if i == len(path)-1 {
+			if idx < 0 {
+				m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, value)
+				return
+			}
+			old := m.Content[idx]
+			value.LineComment = old.LineComment
+			value.HeadComment = old.HeadComment
+			value.FootComment = old.FootComment
+			m.Content[idx] = value
+			return
+		}
+
+		if idx < 0 {
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, &yaml.Node{Kind: yaml.MappingNode})
+			idx = len(m.Content) - 1
+		}
+		child := m.Content[idx]
+		if child.Kind != yaml.MappingNode || child.Style&yaml.FlowStyle != 0 {
+			// Entries added to an empty {} are written as a block, so its
+			// comment moves to the key to stay on the same line
+			keyNode := m.Content[idx-1]
+			if keyNode.LineComment == "" {
+				keyNode.LineComment = child.LineComment
+			}
+			content := child.Content
+			if child.Kind != yaml.MappingNode {
+				content = nil
+			}
+			child = &yaml.Node{Kind: yaml.MappingNode, Content: content}
+			m.Content[idx] = child
+		}
+		m = child
+	}
+}
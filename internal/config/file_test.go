@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// readConfigFile loads path alone, without defaults, and returns the result
+func readConfigFile(t *testing.T, path string) *Config {
+	t.Helper()
+	viper.Reset()
+	cfg = Config{}
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return Get()
+}
+
+func TestSkeleton_MatchesDefaults(t *testing.T) {
+	resetForTest(t)
+	setDefaults()
+	want := fmt.Sprintf("%+v", *Get())
+
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := WriteSkeleton(path, false); err != nil {
+		t.Fatalf("WriteSkeleton() error = %v", err)
+	}
+	if got := fmt.Sprintf("%+v", *readConfigFile(t, path)); got != want {
+		t.Errorf("skeleton settings differ from the defaults:\n got  %s\n want %s", got, want)
+	}
+	for _, key := range Keys() {
+		if key, ok := strings.CutSuffix(key, ".<name>"); ok {
+			if !viper.IsSet(key) {
+				t.Errorf("skeleton is missing %s", key)
+			}
+			continue
+		}
+		if !viper.IsSet(key) {
+			t.Errorf("skeleton is missing %s", key)
+		}
+	}
+}
+
+func TestWriteSkeleton_KeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := os.WriteFile(path, []byte("ai:\n  model: mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteSkeleton(path, false); !errors.Is(err, ErrConfigExists) {
+		t.Fatalf("WriteSkeleton() error = %v, want ErrConfigExists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ai:\n  model: mine\n" {
+		t.Errorf("existing file was changed to %q", data)
+	}
+
+	if err := WriteSkeleton(path, true); err != nil {
+		t.Fatalf("WriteSkeleton(force) error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != Skeleton() {
+		t.Error("WriteSkeleton(force) should overwrite the file")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := strings.Join(Keys(), " ")
+	for _, want := range []string{"review.block", "review.modes.security", "review.severity_map.<name>", "fix.verify_timeout", "ai.model"} {
+		if !strings.Contains(keys, want) {
+			t.Errorf("Keys() = %s, missing %s", keys, want)
+		}
+	}
+}
+
+func TestSetValue_KeepsCommentsAndOtherSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := WriteSkeleton(path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kv := range [][2]string{
+		{"review.block", "false"},
+		{"review.sampling", "0.25"},
+		{"review.cross_check.modes", "security, errors"},
+		{"review.severity_map.p0", "high"},
+		{"fix.verify_timeout", "90s"},
+		{"ai.model", "claude-sonnet-4-5"},
+	} {
+		if err := SetValue(path, kv[0], kv[1]); err != nil {
+			t.Fatalf("SetValue(%s, %s) error = %v", kv[0], kv[1], err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"# revi configuration", "# Block commit on high-severity issues", "# Extra severity names"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("comment %q was lost:\n%s", comment, data)
+		}
+	}
+
+	c := readConfigFile(t, path)
+	if c.Review.Block {
+		t.Error("review.block should be false")
+	}
+	if c.Review.Sampling != 0.25 {
+		t.Errorf("review.sampling = %v, want 0.25", c.Review.Sampling)
+	}
+	if got := strings.Join(c.Review.CrossCheck.Modes, ","); got != "security,errors" {
+		t.Errorf("review.cross_check.modes = %s, want security,errors", got)
+	}
+	if c.Review.SeverityMap["p0"] != "high" {
+		t.Errorf("review.severity_map = %v, want p0: high", c.Review.SeverityMap)
+	}
+	if c.Fix.VerifyTimeout.Seconds() != 90 {
+		t.Errorf("fix.verify_timeout = %s, want 90s", c.Fix.VerifyTimeout)
+	}
+	if c.AI.Model != "claude-sonnet-4-5" {
+		t.Errorf("ai.model = %q, want claude-sonnet-4-5", c.AI.Model)
+	}
+	if !c.Review.Enabled || c.History.MaxEntries != 500 {
+		t.Error("other settings should keep their values")
+	}
+}
+
+func TestSetValue_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := SetValue(path, "ui.stream_lines", "3"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if c := readConfigFile(t, path); c.UI.StreamLines != 3 {
+		t.Errorf("ui.stream_lines = %d, want 3", c.UI.StreamLines)
+	}
+}
+
+func TestSetValue_SetsBranchRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := SetValue(path, "branches.spike/*.block", "false"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	c := readConfigFile(t, path)
+	if rules, ok := c.Branches["spike/*"]; !ok || rules.Block == nil || *rules.Block {
+		t.Errorf("branches = %+v, want spike/* with block false", c.Branches)
+	}
+}
+
+func TestSetValue_QuotesStringsThatLookLikeOtherTypes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := SetValue(path, "fix.verify", "true"); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if c := readConfigFile(t, path); c.Fix.Verify != "true" {
+		t.Errorf("fix.verify = %q, want the string true", c.Fix.Verify)
+	}
+}
+
+func TestSetValue_RejectsInvalidInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	tests := []struct {
+		key, value string
+		want       error
+	}{
+		{"review.nope", "1", ErrUnknownKey},
+		{"review", "1", ErrUnknownKey},
+		{"review.severity_map", "high", ErrUnknownKey},
+		{"review.block.more", "1", ErrUnknownKey},
+		{"review.block", "maybe", ErrInvalidValue},
+		{"review.max_suggestions", "1.5", ErrInvalidValue},
+		{"review.pacing", "soon", ErrInvalidValue},
+		{"branches.main", "false", ErrUnknownKey},
+		{"branches.main.nope", "1", ErrUnknownKey},
+		{"branches.main.block", "maybe", ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := SetValue(path, tt.key, tt.value); !errors.Is(err, tt.want) {
+				t.Errorf("SetValue(%s, %s) error = %v, want %v", tt.key, tt.value, err, tt.want)
+			}
+		})
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("rejected values should not create the file")
+	}
+}
@@ -0,0 +1,428 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrInvalidConfig is wrapped by the error LoadError returns when the config
+// file has settings Validate or ValidateFile find fault with.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// modelAliases are the model names the Claude CLI accepts besides full model IDs
+var modelAliases = []string{"opus", "sonnet", "haiku"}
+
+// severities are the severity levels settings can name
+var severities = []string{"high", "medium", "low"}
+
+// trailerLine matches a git trailer, such as "Refs: PROJ-123", and
+// trailerToken the name before its colon
+var (
+	trailerLine  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S`)
+	trailerToken = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+)
+
+// Problem is a setting that is unknown or has a value revi cannot use.
+type Problem struct {
+	Key     string // Dotted key of the setting
+	Message string // What is wrong with it
+}
+
+// String returns the problem as "key: message".
+func (p Problem) String() string {
+	return p.Key + ": " + p.Message
+}
+
+// ProblemsError returns an error wrapping ErrInvalidConfig that lists
+// problems found in the config file at path, or nil if there are none.
+func ProblemsError(path string, problems []Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	lines := make([]string, len(problems))
+	for i, p := range problems {
+		lines[i] = "  " + p.String()
+	}
+	return fmt.Errorf("%w in %s:\n%s", ErrInvalidConfig, path, strings.Join(lines, "\n"))
+}
+
+// ValidateFile checks the config file at path: every key must be a setting,
+// with a value of the setting's type, and the values must pass Validate.
+func ValidateFile(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		return nil, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s does not hold a YAML mapping", path)
+	}
+
+	var problems []Problem
+	checkNode(doc.Content[0], "", reflect.TypeOf(Config{}), &problems)
+	if len(problems) > 0 {
+		// Values of the wrong type cannot be decoded to validate the rest
+		return problems, nil
+	}
+
+	var c Config
+	if err := doc.Decode(&rawConfig{&c}); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return Validate(&c, fileKeys(doc.Content[0], "")), nil
+}
+
+// rawConfig decodes a config file into a Config through its mapstructure tags
+type rawConfig struct{ c *Config }
+
+// UnmarshalYAML decodes a config file mapping into the Config
+func (r *rawConfig) UnmarshalYAML(node *yaml.Node) error {
+	return decodeInto(node, reflect.ValueOf(r.c).Elem())
+}
+
+// decodeInto decodes node into v, matching the keys of mappings to the
+// mapstructure tags of struct fields. Durations are parsed from strings.
+func decodeInto(node *yaml.Node, v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			for j := 0; j < v.NumField(); j++ {
+				if v.Type().Field(j).Tag.Get("mapstructure") == node.Content[i].Value {
+					if err := decodeInto(node.Content[i+1], v.Field(j)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.Struct:
+		v.Set(reflect.MakeMap(v.Type()))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			entry := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeInto(node.Content[i+1], entry); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(node.Content[i].Value), entry)
+		}
+		return nil
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(node.Value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	default:
+		return node.Decode(v.Addr().Interface())
+	}
+}
+
+// checkNode reports the keys of mapping m that are not settings of struct
+// type t, and the values that do not parse as their setting's type
+func checkNode(m *yaml.Node, prefix string, t reflect.Type, problems *[]Problem) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		name, value := m.Content[i].Value, m.Content[i+1]
+		key := prefix + name
+		field, ok := fieldByTag(t, name)
+		if !ok {
+			message := "unknown key"
+			if suggestion := closestKey(key); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %s?", suggestion)
+			}
+			*problems = append(*problems, Problem{Key: key, Message: message})
+			continue
+		}
+		checkValue(value, key, field.Type, problems)
+	}
+}
+
+// checkValue reports value if it does not parse as type t
+func checkValue(value *yaml.Node, key string, t reflect.Type, problems *[]Problem) {
+	switch {
+	case t.Kind() == reflect.Struct:
+		if value.Kind != yaml.MappingNode {
+			*problems = append(*problems, Problem{Key: key, Message: "is a section and needs keys below it"})
+			return
+		}
+		checkNode(value, key+".", t, problems)
+	case t.Kind() == reflect.Map:
+		if value.Kind != yaml.MappingNode {
+			*problems = append(*problems, Problem{Key: key, Message: "needs a mapping of names to values"})
+			return
+		}
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			checkValue(value.Content[i+1], key+"."+value.Content[i].Value, t.Elem(), problems)
+		}
+	case t.Kind() == reflect.Slice:
+		if value.Kind != yaml.SequenceNode {
+			*problems = append(*problems, Problem{Key: key, Message: "needs a list, e.g. [a, b]"})
+			return
+		}
+		for _, item := range value.Content {
+			checkValue(item, key, t.Elem(), problems)
+		}
+	case value.Kind != yaml.ScalarNode:
+		*problems = append(*problems, Problem{Key: key, Message: "needs a single value"})
+	default:
+		if _, err := valueNode(t, key, value.Value); err != nil {
+			_, message, _ := strings.Cut(err.Error(), ": ")
+			*problems = append(*problems, Problem{Key: key, Message: message})
+		}
+	}
+}
+
+// fileKeys returns the keys set in mapping m, so Validate only reports
+// settings the file sets
+func fileKeys(m *yaml.Node, prefix string) map[string]bool {
+	keys := make(map[string]bool)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key := prefix + m.Content[i].Value
+		keys[key] = true
+		if m.Content[i+1].Kind == yaml.MappingNode {
+			for k := range fileKeys(m.Content[i+1], key+".") {
+				keys[k] = true
+			}
+		}
+	}
+	return keys
+}
+
+// Validate checks the values of c that revi would reject or misread: model
+// names, severities, review modes, and numbers and durations out of range.
+// With set, only the keys in it are checked; nil checks every setting.
+func Validate(c *Config, set map[string]bool) []Problem {
+	var problems []Problem
+	report := func(key, format string, args ...any) {
+		if set == nil || set[key] {
+			problems = append(problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	for key, model := range map[string]string{
+		"ai.model":                 c.AI.Model,
+		"commit.summary_model":     c.Commit.SummaryModel,
+		"review.cross_check.model": c.Review.CrossCheck.Model,
+	} {
+		if model != "" && !isModelName(model) {
+			report(key, "%q is not a Claude model, e.g. claude-sonnet-4-5 or %s", model, strings.Join(modelAliases, ", "))
+		}
+	}
+	if c.AI.Model == "" {
+		report("ai.model", "must not be empty")
+	}
+
+	for mode, model := range c.AI.Models {
+		switch {
+		case !isModeName(mode):
+			report("ai.models."+mode, "unknown review mode %q, expected one of %s", mode, strings.Join(modeNames(), ", "))
+		case !isModelName(model):
+			report("ai.models."+mode, "%q is not a Claude model, e.g. claude-sonnet-4-5 or %s", model, strings.Join(modelAliases, ", "))
+		}
+	}
+	for _, mode := range c.Review.CrossCheck.Modes {
+		if !isModeName(mode) {
+			report("review.cross_check.modes", "unknown review mode %q, expected one of %s", mode, strings.Join(modeNames(), ", "))
+		}
+	}
+	for name, severity := range c.Review.SeverityMap {
+		if !isSeverity(severity) {
+			report("review.severity_map."+name, "severity %q must be high, medium or low", severity)
+		}
+	}
+	for mode, severity := range c.Fix.AutoApply {
+		switch {
+		case !isModeName(mode):
+			report("fix.auto_apply."+mode, "unknown review mode %q, expected one of %s", mode, strings.Join(modeNames(), ", "))
+		case !isSeverity(severity):
+			report("fix.auto_apply."+mode, "severity %q must be high, medium or low", severity)
+		}
+	}
+	for severity := range c.UI.SeverityLabels {
+		if !isSeverity(severity) {
+			report("ui.severity_labels."+severity, "%q is not a severity; labels are set for high, medium and low", severity)
+		}
+	}
+
+	for _, trailer := range c.Commit.Trailers {
+		if !trailerLine.MatchString(trailer) {
+			report("commit.trailers", "%q is not a trailer such as \"Reviewed-by: Jane Doe <jane@example.com>\"", trailer)
+		}
+	}
+	if c.Commit.IssueTrailer != "" && !trailerToken.MatchString(c.Commit.IssueTrailer) {
+		report("commit.issue_trailer", "%q is not a trailer name such as Refs", c.Commit.IssueTrailer)
+	}
+	if _, err := regexp.Compile(c.Commit.IssuePattern); err != nil {
+		report("commit.issue_pattern", "%q is not a valid regular expression: %v", c.Commit.IssuePattern, err)
+	}
+
+	if c.Review.BlockThreshold != "" && !isSeverity(c.Review.BlockThreshold) {
+		report("review.block_threshold", "severity %q must be high, medium or low", c.Review.BlockThreshold)
+	}
+	for pattern, rules := range c.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			report("branches."+pattern, "%q is not a valid branch pattern", pattern)
+		}
+		if rules.BlockThreshold != "" && !isSeverity(rules.BlockThreshold) {
+			report("branches."+pattern+".block_threshold", "severity %q must be high, medium or low", rules.BlockThreshold)
+		}
+	}
+
+	for key, patterns := range map[string][]string{
+		"review.include": c.Review.Include,
+		"review.exclude": c.Review.Exclude,
+	} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				report(key, "%q is not a valid path pattern", pattern)
+			}
+		}
+	}
+
+	if c.Review.Sampling < 0 || c.Review.Sampling > 1 {
+		report("review.sampling", "%v must be between 0 and 1", c.Review.Sampling)
+	}
+	if c.Review.MinConfidence < 0 || c.Review.MinConfidence > 1 {
+		report("review.min_confidence", "%v must be between 0 and 1", c.Review.MinConfidence)
+	}
+	if c.Review.ConfirmCost < 0 {
+		report("review.confirm_cost", "%v must not be negative (0 never asks)", c.Review.ConfirmCost)
+	}
+	for key, n := range map[string]int{
+		"review.max_suggestions": c.Review.MaxSuggestions,
+		"review.concurrency":     c.Review.Concurrency,
+		"review.timeout_seconds": c.Review.TimeoutSeconds,
+		"review.syntax_context":  c.Review.SyntaxContext,
+		"fix.preview_context":    c.Fix.PreviewContext,
+		"ui.stream_lines":        c.UI.StreamLines,
+		"history.max_entries":    c.History.MaxEntries,
+		"history.max_size_mb":    c.History.MaxSizeMB,
+		"ai.retry.rate_limit":    c.AI.Retry.RateLimit,
+		"ai.retry.network":       c.AI.Retry.Network,
+		"ai.retry.process":       c.AI.Retry.Process,
+		"ai.max_diff_chars":      c.AI.MaxDiffChars,
+	} {
+		if n < 0 {
+			report(key, "%d must not be negative", n)
+		}
+	}
+	for key, d := range map[string]time.Duration{
+		"review.pacing":     c.Review.Pacing,
+		"history.max_age":   c.History.MaxAge,
+		"ai.retry.backoff":  c.AI.Retry.Backoff,
+		"ai.retry.delay":    c.AI.Retry.Delay,
+		"ai.retry.deadline": c.AI.Retry.Deadline,
+	} {
+		if d < 0 {
+			report(key, "%s must not be negative", d)
+		}
+	}
+	if c.Fix.VerifyTimeout <= 0 {
+		report("fix.verify_timeout", "%s must be positive, e.g. 5m", c.Fix.VerifyTimeout)
+	}
+	if c.Lint.Timeout <= 0 {
+		report("lint.timeout", "%s must be positive, e.g. 2m", c.Lint.Timeout)
+	}
+	for key, status := range map[string]int{
+		"ci.exit_codes.high":   c.CI.ExitCodes.High,
+		"ci.exit_codes.medium": c.CI.ExitCodes.Medium,
+		"ci.exit_codes.low":    c.CI.ExitCodes.Low,
+	} {
+		if status < 0 || status > 125 {
+			report(key, "%d must be between 0 and 125", status)
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems
+}
+
+// isModelName reports whether model names a Claude model or is an alias of one
+func isModelName(model string) bool {
+	for _, alias := range modelAliases {
+		if model == alias {
+			return true
+		}
+	}
+	return strings.HasPrefix(model, "claude-")
+}
+
+// modeNames returns the review modes, as named in review.modes
+func modeNames() []string {
+	t := reflect.TypeOf(ReviewModes{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = t.Field(i).Tag.Get("mapstructure")
+	}
+	return names
+}
+
+// isModeName reports whether name is a review mode
+func isModeName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, mode := range modeNames() {
+		if name == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeverity reports whether s is a severity level
+func isSeverity(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, severity := range severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey returns the setting closest in spelling to key, if one is close
+// enough to be a likely typo of it
+func closestKey(key string) string {
+	best, bestDist := "", 3
+	for _, k := range Keys() {
+		k = strings.TrimSuffix(k, ".<name>")
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validateContent writes content to a config file and validates it
+func validateContent(t *testing.T, content string) []Problem {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".revi.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := ValidateFile(path)
+	if err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+	return problems
+}
+
+func TestValidateFile_SkeletonIsValid(t *testing.T) {
+	if problems := validateContent(t, Skeleton()); len(problems) > 0 {
+		t.Errorf("ValidateFile(skeleton) = %v, want no problems", problems)
+	}
+}
+
+func TestValidateFile_ReportsUnknownKeys(t *testing.T) {
+	problems := validateContent(t, "review:\n  bloc: true\n  severity_map:\n    p0: high\nnope: 1\n")
+	if len(problems) != 2 {
+		t.Fatalf("ValidateFile() = %v, want 2 problems", problems)
+	}
+	if problems[0].Key != "review.bloc" || !strings.Contains(problems[0].Message, "did you mean review.block?") {
+		t.Errorf("problems[0] = %v, want review.bloc with a suggestion of review.block", problems[0])
+	}
+	if problems[1].Key != "nope" || strings.Contains(problems[1].Message, "did you mean") {
+		t.Errorf("problems[1] = %v, want nope without a suggestion", problems[1])
+	}
+}
+
+func TestValidateFile_ReportsInvalidValues(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		key     string
+	}{
+		"type":          {"review:\n  block: maybe\n", "review.block"},
+		"section":       {"review: true\n", "review"},
+		"list":          {"review:\n  critical_paths: src/\n", "review.critical_paths"},
+		"model":         {"ai:\n  model: gpt-4\n", "ai.model"},
+		"empty model":   {"ai:\n  model: \"\"\n", "ai.model"},
+		"severity":      {"review:\n  severity_map:\n    p0: urgent\n", "review.severity_map.p0"},
+		"auto apply":    {"fix:\n  auto_apply:\n    secrity: high\n", "fix.auto_apply.secrity"},
+		"cross check":   {"review:\n  cross_check:\n    modes: [security, speed]\n", "review.cross_check.modes"},
+		"sampling":      {"review:\n  sampling: 1.5\n", "review.sampling"},
+		"confidence":    {"review:\n  min_confidence: 60\n", "review.min_confidence"},
+		"trailer":       {"commit:\n  trailers: [Reviewed by Jane]\n", "commit.trailers"},
+		"trailer name":  {"commit:\n  issue_trailer: \"Refs:\"\n", "commit.issue_trailer"},
+		"issue pattern": {"commit:\n  issue_pattern: \"[A-Z+\"\n", "commit.issue_pattern"},
+		"negative":      {"review:\n  concurrency: -1\n", "review.concurrency"},
+		"timeout":       {"fix:\n  verify_timeout: 0s\n", "fix.verify_timeout"},
+		"lint timeout":  {"lint:\n  timeout: -1m\n", "lint.timeout"},
+		"bad duration":  {"review:\n  pacing: soon\n", "review.pacing"},
+		"exit code":     {"ci:\n  exit_codes:\n    high: 200\n", "ci.exit_codes.high"},
+		"severity name": {"ui:\n  severity_labels:\n    critical: \"!!\"\n", "ui.severity_labels.critical"},
+		"retries":       {"ai:\n  retry:\n    network: -1\n", "ai.retry.network"},
+		"diff size":     {"ai:\n  max_diff_chars: -1\n", "ai.max_diff_chars"},
+		"retry backoff": {"ai:\n  retry:\n    backoff: -1s\n", "ai.retry.backoff"},
+		"mode model":    {"ai:\n  models:\n    style: gpt-4\n", "ai.models.style"},
+		"model mode":    {"ai:\n  models:\n    speed: haiku\n", "ai.models.speed"},
+		"threshold":     {"review:\n  block_threshold: critical\n", "review.block_threshold"},
+		"branch rule":   {"branches:\n  \"release/*\":\n    block_threshold: urgent\n", "branches.release/*.block_threshold"},
+		"branch key":    {"branches:\n  main:\n    blok: true\n", "branches.main.blok"},
+		"branch glob":   {"branches:\n  \"release/[\":\n    block: false\n", "branches.release/["},
+		"path glob":     {"review:\n  exclude: [\"gen/[\"]\n", "review.exclude"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			problems := validateContent(t, tt.content)
+			if len(problems) != 1 || problems[0].Key != tt.key {
+				t.Errorf("ValidateFile() = %v, want one problem with %s", problems, tt.key)
+			}
+		})
+	}
+}
+
+func TestValidateFile_AcceptsValidValues(t *testing.T) {
+	content := `ai:
+  model: sonnet
+  models:
+    style: haiku
+    security: claude-opus-4-5
+review:
+  cross_check:
+    model: claude-opus-4-5
+    modes: [security, errors]
+  severity_map:
+    p0: High
+  pacing: 2s
+  include: ["internal/api/**", "*.go"]
+  exclude: [internal/api/client/**]
+fix:
+  auto_apply:
+    style: low
+  verify_timeout: 30s
+lint:
+  linters: ["go vet {dirs}", "staticcheck {dirs}"]
+  timeout: 1m
+branches:
+  "release/1.*":
+    block_threshold: medium
+  "spike/*":
+    block: false
+`
+	if problems := validateContent(t, content); len(problems) > 0 {
+		t.Errorf("ValidateFile() = %v, want no problems", problems)
+	}
+}
+
+func TestValidate_ChecksEverySettingWithoutKeys(t *testing.T) {
+	c := Config{}
+	c.AI.Model = "claude-sonnet-4-5"
+	c.Fix.VerifyTimeout = time.Minute
+	c.Lint.Timeout = time.Minute
+	if problems := Validate(&c, nil); len(problems) > 0 {
+		t.Errorf("Validate() = %v, want no problems", problems)
+	}
+
+	c.Review.Sampling = -1
+	c.Fix.VerifyTimeout = 0
+	problems := Validate(&c, nil)
+	if len(problems) != 2 || problems[0].Key != "fix.verify_timeout" || problems[1].Key != "review.sampling" {
+		t.Errorf("Validate() = %v, want fix.verify_timeout and review.sampling", problems)
+	}
+	if problems := Validate(&c, map[string]bool{"review.sampling": true}); len(problems) != 1 {
+		t.Errorf("Validate(review.sampling) = %v, want only review.sampling", problems)
+	}
+}
+
+func TestInit_ReportsInvalidConfigFile(t *testing.T) {
+	resetForTest(t)
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".revi.yaml", []byte("review:\n  bloc: false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	Init()
+	err := LoadError()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("LoadError() = %v, want ErrInvalidConfig", err)
+	}
+	if !strings.Contains(err.Error(), "review.bloc: unknown key, did you mean review.block?") {
+		t.Errorf("LoadError() = %v, want the unknown key listed", err)
+	}
+}
@@ -15,58 +15,144 @@ import (
 
 // Applier handles applying fixes to files within a root directory.
 type Applier struct {
-	root string
+	root    string
+	journal *Journal
+	verify  VerifyFunc
+	offsets *lineOffsets
 }
 
 // NewApplier creates a new Applier that only modifies files within root.
 func NewApplier(root string) *Applier {
-	return &Applier{root: root}
+	return &Applier{root: root, offsets: newLineOffsets()}
+}
+
+// SetJournal sets a journal that records every change made by Apply.
+// A nil journal disables recording.
+func (a *Applier) SetJournal(journal *Journal) {
+	a.journal = journal
+}
+
+// SetVerifier sets a function run after every fix is written. If it fails,
+// the fix is rolled back and Apply returns an error wrapping ErrFixRejected.
+// A nil verifier disables verification.
+func (a *Applier) SetVerifier(verify VerifyFunc) {
+	a.verify = verify
 }
 
 // Apply applies a fix to the file specified in the fix.
+// The fix's line numbers refer to the file as it was reviewed; they are moved
+// past the lines added or removed by fixes this applier applied earlier, and
+// a fix overlapping one of them fails with ErrFixOverlaps.
 // Returns an error if the fix cannot be applied.
 func (a *Applier) Apply(fix *review.Fix) error {
 	if !fix.Available {
 		return fmt.Errorf("fix not available: %s", fix.Reason)
 	}
 
-	// Validate the file is within root
-	absPath, err := filepath.Abs(fix.FilePath)
+	absPath, err := a.resolvePath(fix.FilePath)
 	if err != nil {
-		return fmt.Errorf("invalid file path: %w", err)
+		return err
 	}
-
-	absRoot, err := filepath.Abs(a.root)
+	target, err := a.offsets.adjust(absPath, fix)
 	if err != nil {
-		return fmt.Errorf("invalid root path: %w", err)
-	}
-
-	if !strings.HasPrefix(absPath, absRoot+string(filepath.Separator)) && absPath != absRoot {
-		return fmt.Errorf("file %s is outside root directory %s", fix.FilePath, a.root)
+		return err
 	}
 
 	// Read the file
-	content, err := os.ReadFile(fix.FilePath)
+	content, err := os.ReadFile(absPath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Get file permissions to preserve them
-	info, err := os.Stat(fix.FilePath)
+	info, err := os.Stat(absPath)
 	if err != nil {
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 	perm := info.Mode().Perm()
 
+	newContent, err := replaceLines(string(content), target)
+	if err != nil {
+		return err
+	}
+
+	// Write back with preserved permissions
+	if err := os.WriteFile(absPath, []byte(newContent), perm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if a.verify != nil {
+		if err := a.verify(); err != nil {
+			if restoreErr := os.WriteFile(absPath, content, perm); restoreErr != nil {
+				return fmt.Errorf("fix failed verification (%v) and could not be rolled back: %w", err, restoreErr)
+			}
+			return fmt.Errorf("%w: %v", ErrFixRejected, err)
+		}
+	}
+
+	a.offsets.record(absPath, fix)
+	if a.journal != nil {
+		a.journal.Record(JournalEntry{
+			Path:   absPath,
+			Before: string(content),
+			After:  newContent,
+			Fix:    fix,
+		})
+	}
+
+	return nil
+}
+
+// Undo reverts the applied fix with the given ID, or the most recent one if id
+// is empty, using the content recorded in the applier's journal.
+func (a *Applier) Undo(id string) (JournalEntry, error) {
+	if a.journal == nil {
+		return JournalEntry{}, fmt.Errorf("undo requires a fix journal")
+	}
+	entry, err := a.journal.Undo(id)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	a.offsets.forget(entry.Path)
+	return entry, nil
+}
+
+// resolvePath returns the absolute path of a fix target after checking
+// that it lies within the applier's root directory. The path is first
+// normalized with review.RepoPath, then taken relative to the root.
+func (a *Applier) resolvePath(path string) (string, error) {
+	absRoot, err := filepath.Abs(a.root)
+	if err != nil {
+		return "", fmt.Errorf("invalid root path: %w", err)
+	}
+
+	path = filepath.FromSlash(review.RepoPath(path, absRoot))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(absRoot, path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid file path: %w", err)
+	}
+
+	if !strings.HasPrefix(absPath, absRoot+string(filepath.Separator)) && absPath != absRoot {
+		return "", fmt.Errorf("file %s is outside root directory %s", path, a.root)
+	}
+
+	return absPath, nil
+}
+
+// replaceLines returns content with the fix's line range replaced by its code.
+func replaceLines(content string, fix *review.Fix) (string, error) {
 	// Split into lines
-	lines := strings.Split(string(content), "\n")
+	lines := strings.Split(content, "\n")
 
 	// Validate line range
 	if fix.StartLine < 1 {
-		return fmt.Errorf("start line must be >= 1, got %d", fix.StartLine)
+		return "", fmt.Errorf("start line must be >= 1, got %d", fix.StartLine)
 	}
 	if fix.EndLine < fix.StartLine {
-		return fmt.Errorf("end line (%d) must be >= start line (%d)", fix.EndLine, fix.StartLine)
+		return "", fmt.Errorf("end line (%d) must be >= start line (%d)", fix.EndLine, fix.StartLine)
 	}
 	// Account for potential trailing newline creating extra empty line
 	maxLine := len(lines)
@@ -74,7 +160,7 @@ func (a *Applier) Apply(fix *review.Fix) error {
 		maxLine = len(lines) - 1
 	}
 	if fix.EndLine > maxLine {
-		return fmt.Errorf("end line (%d) exceeds file length (%d)", fix.EndLine, maxLine)
+		return "", fmt.Errorf("end line (%d) exceeds file length (%d)", fix.EndLine, maxLine)
 	}
 
 	// Replace lines (convert to 0-indexed)
@@ -87,26 +173,107 @@ func (a *Applier) Apply(fix *review.Fix) error {
 	newLines = append(newLines, fix.Code)
 	newLines = append(newLines, lines[endIdx+1:]...)
 
-	// Write back with preserved permissions
-	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(fix.FilePath, []byte(newContent), perm); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
+	return strings.Join(newLines, "\n"), nil
+}
 
-	return nil
+// PreviewHunk is a preview of a fix with unchanged lines around the replaced range.
+type PreviewHunk struct {
+	// StartLine is the 1-based line number of the first line shown
+	StartLine int
+	// Leading holds the unchanged lines before the replaced range
+	Leading []string
+	// Removed holds the lines being replaced
+	Removed []string
+	// Added holds the replacement lines
+	Added []string
+	// Trailing holds the unchanged lines after the replaced range
+	Trailing []string
+}
+
+// String renders the preview as a unified diff hunk.
+func (h *PreviewHunk) String() string {
+	var b strings.Builder
+	oldCount := len(h.Leading) + len(h.Removed) + len(h.Trailing)
+	newCount := len(h.Leading) + len(h.Added) + len(h.Trailing)
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.StartLine, oldCount, h.StartLine, newCount)
+	for _, line := range h.Leading {
+		b.WriteString(" " + line + "\n")
+	}
+	for _, line := range h.Removed {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range h.Added {
+		b.WriteString("+" + line + "\n")
+	}
+	for _, line := range h.Trailing {
+		b.WriteString(" " + line + "\n")
+	}
+	return b.String()
 }
 
-// Preview returns the original and replacement content for the fix.
-// The contextLines parameter is reserved for future use to show surrounding
-// context; currently it returns only the lines being replaced.
+// Preview returns the original and replacement content for the fix, each
+// surrounded by up to contextLines unchanged lines on both sides.
 func (a *Applier) Preview(fix *review.Fix, contextLines int) (before, after string, err error) {
+	hunk, err := a.PreviewHunk(fix, contextLines)
+	if err != nil {
+		return "", "", err
+	}
+
+	var beforeLines, afterLines []string
+	beforeLines = append(beforeLines, hunk.Leading...)
+	beforeLines = append(beforeLines, hunk.Removed...)
+	beforeLines = append(beforeLines, hunk.Trailing...)
+	afterLines = append(afterLines, hunk.Leading...)
+	afterLines = append(afterLines, hunk.Added...)
+	afterLines = append(afterLines, hunk.Trailing...)
+
+	return strings.Join(beforeLines, "\n"), strings.Join(afterLines, "\n"), nil
+}
+
+// PreviewHunk returns a preview of the fix including up to contextLines
+// unchanged lines before and after the replaced range.
+func (a *Applier) PreviewHunk(fix *review.Fix, contextLines int) (*PreviewHunk, error) {
 	if !fix.Available {
-		return "", "", fmt.Errorf("fix not available: %s", fix.Reason)
+		return nil, fmt.Errorf("fix not available: %s", fix.Reason)
+	}
+	if contextLines < 0 {
+		contextLines = 0
 	}
 
-	file, err := os.Open(fix.FilePath)
+	lines, fix, err := a.targetLines(fix)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to open file: %w", err)
+		return nil, err
+	}
+
+	startIdx := fix.StartLine - 1
+	endIdx := fix.EndLine - 1
+	firstIdx := max(startIdx-contextLines, 0)
+	lastIdx := min(endIdx+contextLines, len(lines)-1)
+
+	return &PreviewHunk{
+		StartLine: firstIdx + 1,
+		Leading:   lines[firstIdx:startIdx],
+		Removed:   lines[startIdx : endIdx+1],
+		Added:     strings.Split(fix.Code, "\n"),
+		Trailing:  lines[endIdx+1 : lastIdx+1],
+	}, nil
+}
+
+// targetLines returns the lines of the file a fix targets and the fix with its
+// line numbers moved past the fixes applied earlier, after checking that its
+// range lies within the file
+func (a *Applier) targetLines(fix *review.Fix) ([]string, *review.Fix, error) {
+	absPath, err := a.resolvePath(fix.FilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	fix, err = a.offsets.adjust(absPath, fix)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(absPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	// Close error ignored for read-only file - any significant I/O errors would
 	// have been caught during the read operations above
@@ -118,24 +285,11 @@ func (a *Applier) Preview(fix *review.Fix, contextLines int) (before, after stri
 		lines = append(lines, scanner.Text())
 	}
 	if err := scanner.Err(); err != nil {
-		return "", "", fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	if fix.StartLine < 1 || fix.EndLine > len(lines) {
-		return "", "", fmt.Errorf("invalid line range")
+	if fix.StartLine < 1 || fix.EndLine < fix.StartLine || fix.EndLine > len(lines) {
+		return nil, nil, fmt.Errorf("invalid line range")
 	}
-
-	startIdx := fix.StartLine - 1
-	endIdx := fix.EndLine - 1
-
-	// Get lines being replaced
-	var beforeLines []string
-	for i := startIdx; i <= endIdx; i++ {
-		beforeLines = append(beforeLines, lines[i])
-	}
-
-	before = strings.Join(beforeLines, "\n")
-	after = fix.Code
-
-	return before, after, nil
+	return lines, fix, nil
 }
@@ -214,20 +214,58 @@ func main() {
 	}
 
 	applier := NewApplier(tmpDir)
-	// Note: contextLines parameter is currently reserved for future use.
-	// Preview returns only the lines being replaced, not surrounding context.
 	before, after, err := applier.Preview(fix, 2)
 	if err != nil {
 		t.Fatalf("Preview failed: %v", err)
 	}
 
-	// Verify only the target line is returned (no context lines)
-	if before != "\told code here" {
+	// Two unchanged lines are included on each side of the replaced line
+	if before != "\nfunc main() {\n\told code here\n\treturn\n}" {
 		t.Errorf("unexpected before: %q", before)
 	}
-	if after != "new code here" {
+	if after != "\nfunc main() {\nnew code here\n\treturn\n}" {
 		t.Errorf("unexpected after: %q", after)
 	}
+
+	// Without context only the replaced lines are returned
+	before, after, err = applier.Preview(fix, 0)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if before != "\told code here" {
+		t.Errorf("unexpected before without context: %q", before)
+	}
+	if after != "new code here" {
+		t.Errorf("unexpected after without context: %q", after)
+	}
+}
+
+func TestApplier_PreviewHunk_ClampsContextAtFileEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "test.go")
+
+	if err := os.WriteFile(filePath, []byte("line1\nline2\nline3\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	fix := &review.Fix{
+		Available: true,
+		Code:      "LINE2",
+		FilePath:  filePath,
+		StartLine: 2,
+		EndLine:   2,
+	}
+
+	applier := NewApplier(tmpDir)
+	hunk, err := applier.PreviewHunk(fix, 5)
+	if err != nil {
+		t.Fatalf("PreviewHunk failed: %v", err)
+	}
+
+	want := "@@ -1,3 +1,3 @@\n line1\n-line2\n+LINE2\n line3\n"
+	if got := hunk.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
 }
 
 func TestApplier_Preview_UnavailableFix(t *testing.T) {
@@ -391,3 +429,64 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		t.Errorf("unexpected content:\ngot:\n%s\nwant:\n%s", string(content), expected)
 	}
 }
+
+func TestApplier_Apply_RelativePathWithSpacesAndUnicode(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "docs"), 0755); err != nil {
+		t.Fatalf("failed to create docs dir: %v", err)
+	}
+	filePath := filepath.Join(tmpDir, "docs", "Design Überblick.md")
+	if err := os.WriteFile(filePath, []byte("# Entwurf\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	// Paths reported by the model are relative to the repository root and
+	// may be quoted
+	applier := NewApplier(tmpDir)
+	fix := &review.Fix{
+		Available: true,
+		Code:      "# Design",
+		FilePath:  `"docs/Design \303\234berblick.md"`,
+		StartLine: 1,
+		EndLine:   1,
+	}
+	hunk, err := applier.PreviewHunk(fix, 0)
+	if err != nil {
+		t.Fatalf("PreviewHunk() error = %v", err)
+	}
+	if len(hunk.Removed) != 1 || hunk.Removed[0] != "# Entwurf" {
+		t.Errorf("PreviewHunk() removed = %v, want the file's first line", hunk.Removed)
+	}
+	if err := applier.Apply(fix); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(filePath)
+	if string(content) != "# Design\n" {
+		t.Errorf("content = %q, want the fix applied", string(content))
+	}
+}
+
+func TestApplier_Apply_DiffPrefixedAndAbsolutePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "pkg"), 0755); err != nil {
+		t.Fatalf("failed to create pkg dir: %v", err)
+	}
+	filePath := filepath.Join(tmpDir, "pkg", "main.go")
+
+	// Models sometimes keep the b/ of the diff or report absolute paths
+	for _, path := range []string{"b/pkg/main.go", "./pkg/main.go", filePath} {
+		t.Run(path, func(t *testing.T) {
+			if err := os.WriteFile(filePath, []byte("old\n"), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+			fix := &review.Fix{Available: true, Code: "new", FilePath: path, StartLine: 1, EndLine: 1}
+			if err := NewApplier(tmpDir).Apply(fix); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if content, _ := os.ReadFile(filePath); string(content) != "new\n" {
+				t.Errorf("content = %q, want the fix applied", string(content))
+			}
+		})
+	}
+}
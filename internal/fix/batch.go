@@ -0,0 +1,62 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// ApplyAll applies every available fix without prompting and writes one line
+// per fix and a summary of applied and failed fixes to w. Fixes to the same
+// file are applied from the bottom up so the line numbers of the remaining
+// fixes stay valid. Failed fixes are counted in both Skipped and Failed.
+// Severities are shown with labels, or as upper-case level names if labels is nil.
+func ApplyAll(w io.Writer, issues []review.Issue, labels review.SeverityLabels, applyFn ApplyFunc) Stats {
+	var stats Stats
+
+	var fixable []review.Issue
+	for _, issue := range issues {
+		if issue.Fix == nil || !issue.Fix.Available {
+			stats.Unfixable++
+			continue
+		}
+		fixable = append(fixable, issue)
+	}
+	sort.SliceStable(fixable, func(i, k int) bool {
+		a, b := fixable[i].Fix, fixable[k].Fix
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine > b.StartLine
+	})
+
+	// Write errors are intentionally ignored - if output fails, continue processing
+	for _, issue := range fixable {
+		if err := applyFn(issue.Fix); err != nil {
+			_, _ = fmt.Fprintf(w, "  ✗ %s:%d: %v\n", issue.Fix.FilePath, issue.Fix.StartLine, err)
+			if errors.Is(err, ErrFixRejected) {
+				stats.Rejected++
+			}
+			stats.Skipped++
+			stats.Failed++
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  ✓ %s:%d: [%s] %s\n", issue.Fix.FilePath, issue.Fix.StartLine,
+			labels.Label(issue.Severity), issue.Description)
+		stats.Applied++
+	}
+
+	_, _ = fmt.Fprintf(w, "\nApplied %d fix(es), %d failed", stats.Applied, stats.Failed)
+	if stats.Rejected > 0 {
+		_, _ = fmt.Fprintf(w, " (%d rejected by verification)", stats.Rejected)
+	}
+	if stats.Unfixable > 0 {
+		_, _ = fmt.Fprintf(w, ", %d unfixable", stats.Unfixable)
+	}
+	_, _ = fmt.Fprintln(w)
+
+	return stats
+}
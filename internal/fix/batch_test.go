@@ -0,0 +1,67 @@
+package fix
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestApplyAll_AppliesBottomUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	// The first fix adds a line, which would shift the second one if it were
+	// applied first
+	issues := []review.Issue{
+		{Severity: "low", Description: "split a", Fix: &review.Fix{Available: true, Code: "a1\na2", FilePath: filePath, StartLine: 1, EndLine: 1}},
+		{Severity: "low", Description: "rename c", Fix: &review.Fix{Available: true, Code: "C", FilePath: filePath, StartLine: 3, EndLine: 3}},
+		{Severity: "medium", Description: "needs judgment", Fix: &review.Fix{Available: false, Reason: "business logic"}},
+	}
+
+	var out bytes.Buffer
+	stats := ApplyAll(&out, issues, nil, NewApplier(tmpDir).Apply)
+
+	if stats.Applied != 2 || stats.Failed != 0 || stats.Unfixable != 1 {
+		t.Errorf("stats = %+v, want 2 applied, 0 failed, 1 unfixable", stats)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "a1\na2\nb\nC\n" {
+		t.Errorf("content = %q, want both fixes applied", string(content))
+	}
+	if !strings.Contains(out.String(), "Applied 2 fix(es), 0 failed, 1 unfixable") {
+		t.Errorf("missing summary in output:\n%s", out.String())
+	}
+}
+
+func TestApplyAll_ReportsFailures(t *testing.T) {
+	issues := []review.Issue{
+		{Severity: "low", Description: "ok", Fix: &review.Fix{Available: true, FilePath: "a.go", StartLine: 1, EndLine: 1}},
+		{Severity: "low", Description: "broken", Fix: &review.Fix{Available: true, FilePath: "b.go", StartLine: 1, EndLine: 1}},
+	}
+
+	var out bytes.Buffer
+	stats := ApplyAll(&out, issues, nil, func(f *review.Fix) error {
+		if f.FilePath == "b.go" {
+			return errors.New("end line exceeds file length")
+		}
+		return nil
+	})
+
+	if stats.Applied != 1 || stats.Failed != 1 || stats.Skipped != 1 {
+		t.Errorf("stats = %+v, want 1 applied and 1 failed", stats)
+	}
+	if !strings.Contains(out.String(), "b.go:1: end line exceeds file length") {
+		t.Errorf("output should name the failed fix and why:\n%s", out.String())
+	}
+}
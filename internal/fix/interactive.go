@@ -2,10 +2,14 @@ package fix
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
 )
 
@@ -13,8 +17,14 @@ import (
 type Stats struct {
 	// Applied is the count of fixes that were successfully applied to files
 	Applied int
+	// AutoApplied is the part of Applied that was applied without asking
+	AutoApplied int
 	// Skipped is the count of fixes that were skipped by user choice or application failure
 	Skipped int
+	// Failed is the part of Skipped whose application failed
+	Failed int
+	// Rejected is the part of Failed rolled back because verification failed
+	Rejected int
 	// Unfixable is the count of issues that cannot be automatically fixed
 	Unfixable int
 }
@@ -23,14 +33,29 @@ type Stats struct {
 // It should return an error if the fix cannot be applied.
 type ApplyFunc func(*review.Fix) error
 
+// AutoApplyFunc reports whether the fix for an issue is applied without asking.
+type AutoApplyFunc func(review.Issue) bool
+
+// PreviewFunc renders a preview of a fix, typically as a unified diff hunk.
+type PreviewFunc func(*review.Fix) (string, error)
+
+// SplitFunc divides a fix into hunks that can be accepted one by one.
+type SplitFunc func(*review.Fix) (*SplitFix, error)
+
 // InteractiveFixer drives the interactive fix approval loop.
 // It presents each issue to the user, shows the suggested fix if available,
 // and prompts for approval before applying changes. Users can approve (y),
-// skip (n), or skip all remaining issues (s).
+// skip (n), skip all remaining issues (s), or pick the hunks of a fix that
+// changes several places (h).
 type InteractiveFixer struct {
-	reader  *bufio.Reader
-	writer  io.Writer
-	applyFn ApplyFunc
+	reader    *bufio.Reader
+	writer    io.Writer
+	applyFn   ApplyFunc
+	previewFn PreviewFunc
+	splitFn   SplitFunc
+	autoFn    AutoApplyFunc
+	labels    review.SeverityLabels
+	plain     bool
 }
 
 // NewInteractiveFixer creates a new InteractiveFixer.
@@ -42,6 +67,36 @@ func NewInteractiveFixer(reader io.Reader, writer io.Writer, applyFn ApplyFunc)
 	}
 }
 
+// SetPreviewer sets a function that renders each fix before the user is asked
+// to apply it. Without a previewer only the replacement code is shown.
+func (f *InteractiveFixer) SetPreviewer(previewFn PreviewFunc) {
+	f.previewFn = previewFn
+}
+
+// SetSplitter sets a function dividing fixes into hunks. With one, fixes
+// that change several places can be applied hunk by hunk.
+func (f *InteractiveFixer) SetSplitter(splitFn SplitFunc) {
+	f.splitFn = splitFn
+}
+
+// SetAutoApply sets a function selecting the fixes that are applied without
+// prompting. They are still listed and counted in the summary.
+func (f *InteractiveFixer) SetAutoApply(autoFn AutoApplyFunc) {
+	f.autoFn = autoFn
+}
+
+// SetSeverityLabels sets the labels shown for issue severities. Without labels
+// severities are shown as upper-case level names.
+func (f *InteractiveFixer) SetSeverityLabels(labels review.SeverityLabels) {
+	f.labels = labels
+}
+
+// SetPlain leaves out decorative output such as horizontal rules, for screen
+// readers.
+func (f *InteractiveFixer) SetPlain(plain bool) {
+	f.plain = plain
+}
+
 // Run processes all issues and prompts for user approval on each fix.
 func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 	var stats Stats
@@ -51,21 +106,26 @@ func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 	}
 
 	// Write errors are intentionally ignored - if output fails, continue processing
-	_, _ = fmt.Fprintln(f.writer, strings.Repeat("-", 40))
-	_, _ = fmt.Fprintln(f.writer, "FIX ISSUES")
-	_, _ = fmt.Fprintln(f.writer, strings.Repeat("-", 40))
+	if f.plain {
+		_, _ = fmt.Fprintln(f.writer, "Fix issues")
+	} else {
+		_, _ = fmt.Fprintln(f.writer, strings.Repeat("-", 40))
+		_, _ = fmt.Fprintln(f.writer, "FIX ISSUES")
+		_, _ = fmt.Fprintln(f.writer, strings.Repeat("-", 40))
+	}
 
 	skipAll := false
 
 	for i, issue := range issues {
-		if skipAll {
+		auto := f.autoFn != nil && f.autoFn(issue)
+		if skipAll && !auto {
 			stats.Skipped++
 			continue
 		}
 
 		// Write errors are intentionally ignored - if output fails, continue processing
 		_, _ = fmt.Fprintf(f.writer, "\nIssue %d/%d: [%s] %s",
-			i+1, len(issues), strings.ToUpper(issue.Severity), issue.Description)
+			i+1, len(issues), f.labels.Label(issue.Severity), issue.Description)
 		if issue.Location != "" {
 			_, _ = fmt.Fprintf(f.writer, " (%s)", issue.Location)
 		}
@@ -81,15 +141,26 @@ func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 		// Show the fix
 		f.showFix(issue.Fix)
 
+		// Fixes selected by the auto-apply policy skip the prompt
+		if auto {
+			if err := f.applyFn(issue.Fix); err != nil {
+				f.reportFailure(err, &stats)
+			} else {
+				_, _ = fmt.Fprintln(f.writer, "  ✓ Applied automatically")
+				stats.Applied++
+				stats.AutoApplied++
+			}
+			continue
+		}
+
 		// Prompt for approval
-		response := f.prompt()
+		split := f.split(issue.Fix)
+		response := f.prompt(split != nil)
 
 		switch response {
 		case "y", "yes", "":
 			if err := f.applyFn(issue.Fix); err != nil {
-				// Write errors are intentionally ignored - if output fails, continue processing
-				_, _ = fmt.Fprintf(f.writer, "  ✗ Failed: %v\n", err)
-				stats.Skipped++
+				f.reportFailure(err, &stats)
 			} else {
 				_, _ = fmt.Fprintln(f.writer, "  ✓ Applied")
 				stats.Applied++
@@ -101,6 +172,23 @@ func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 			_, _ = fmt.Fprintln(f.writer, "  - Skipping remaining issues")
 			skipAll = true
 			stats.Skipped++
+		case "h", "hunks":
+			if split == nil {
+				_, _ = fmt.Fprintln(f.writer, "  - Skipped (invalid input)")
+				stats.Skipped++
+				continue
+			}
+			accepted, count := f.pickHunks(split)
+			selected := split.Select(accepted)
+			if selected == nil {
+				_, _ = fmt.Fprintln(f.writer, "  - Skipped (no hunks accepted)")
+				stats.Skipped++
+			} else if err := f.applyFn(selected); err != nil {
+				f.reportFailure(err, &stats)
+			} else {
+				_, _ = fmt.Fprintf(f.writer, "  ✓ Applied %d of %d hunks\n", count, len(split.Hunks))
+				stats.Applied++
+			}
 		default:
 			_, _ = fmt.Fprintln(f.writer, "  - Skipped (invalid input)")
 			stats.Skipped++
@@ -109,7 +197,14 @@ func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 
 	// Print summary - write errors are intentionally ignored
 	_, _ = fmt.Fprintln(f.writer)
-	_, _ = fmt.Fprintf(f.writer, "Applied %d fix(es), skipped %d", stats.Applied, stats.Skipped)
+	_, _ = fmt.Fprintf(f.writer, "Applied %d fix(es)", stats.Applied)
+	if stats.AutoApplied > 0 {
+		_, _ = fmt.Fprintf(f.writer, " (%d automatically)", stats.AutoApplied)
+	}
+	_, _ = fmt.Fprintf(f.writer, ", skipped %d", stats.Skipped)
+	if stats.Rejected > 0 {
+		_, _ = fmt.Fprintf(f.writer, ", %d rejected", stats.Rejected)
+	}
 	if stats.Unfixable > 0 {
 		_, _ = fmt.Fprintf(f.writer, ", %d unfixable", stats.Unfixable)
 	}
@@ -118,10 +213,27 @@ func (f *InteractiveFixer) Run(issues []review.Issue) Stats {
 	return stats
 }
 
+// reportFailure prints why a fix could not be applied and counts it in stats
+func (f *InteractiveFixer) reportFailure(err error, stats *Stats) {
+	// Write errors are intentionally ignored - if output fails, continue processing
+	if errors.Is(err, ErrFixRejected) {
+		_, _ = fmt.Fprintf(f.writer, "  ✗ Rolled back: %v\n", err)
+		stats.Rejected++
+	} else {
+		_, _ = fmt.Fprintf(f.writer, "  ✗ Failed: %v\n", err)
+	}
+	stats.Skipped++
+	stats.Failed++
+}
+
 func (f *InteractiveFixer) showFix(fix *review.Fix) {
 	// Show the suggested code change
 	// Write errors are intentionally ignored - if output fails, continue processing
-	if fix.Code != "" {
+	if preview, ok := f.preview(fix); ok {
+		for _, line := range numberLines(preview) {
+			_, _ = fmt.Fprintf(f.writer, "  %s\n", line)
+		}
+	} else if fix.Code != "" {
 		_, _ = fmt.Fprintf(f.writer, "  After:  %s\n", strings.TrimSpace(fix.Code))
 	}
 	if fix.Explanation != "" {
@@ -129,6 +241,44 @@ func (f *InteractiveFixer) showFix(fix *review.Fix) {
 	}
 }
 
+// preview renders the fix with the previewer, if one is set and succeeds
+func (f *InteractiveFixer) preview(fix *review.Fix) (string, bool) {
+	if f.previewFn == nil {
+		return "", false
+	}
+	preview, err := f.previewFn(fix)
+	if err != nil || preview == "" {
+		return "", false
+	}
+	return preview, true
+}
+
+// numberLines splits a unified diff hunk into lines, putting the number of
+// each line in the old and the new version of the file in front of it. Text
+// that is not a hunk is split unchanged.
+func numberLines(hunk string) []string {
+	lines := strings.Split(strings.TrimRight(hunk, "\n"), "\n")
+	h := diff.ParseHunk(hunk)
+	if h == nil {
+		return lines
+	}
+	before, after := h.LineNumbers()
+	width := len(strconv.Itoa(max(slices.Max(append(before, 0)), slices.Max(append(after, 0)))))
+	out := []string{lines[0]}
+	for i, line := range h.Lines {
+		out = append(out, fmt.Sprintf("%*s %*s  %s", width, lineNumber(before[i]), width, lineNumber(after[i]), line))
+	}
+	return out
+}
+
+// lineNumber returns n as text, or nothing for 0
+func lineNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
 func (f *InteractiveFixer) handleUnfixable(fix *review.Fix) {
 	// Write errors are intentionally ignored - if output fails, continue processing
 	_, _ = fmt.Fprintln(f.writer, "  ⚠ Cannot auto-fix")
@@ -149,12 +299,66 @@ func (f *InteractiveFixer) handleUnfixable(fix *review.Fix) {
 	_, _ = f.reader.ReadString('\n')
 }
 
-func (f *InteractiveFixer) prompt() string {
+// split divides the fix into hunks with the splitter, returning nil unless it
+// is set, succeeds and finds more than one hunk
+func (f *InteractiveFixer) split(fix *review.Fix) *SplitFix {
+	if f.splitFn == nil {
+		return nil
+	}
+	split, err := f.splitFn(fix)
+	if err != nil || len(split.Hunks) < 2 {
+		return nil
+	}
+	return split
+}
+
+// pickHunks asks about each hunk of split and returns which were accepted
+// and how many
+func (f *InteractiveFixer) pickHunks(split *SplitFix) ([]bool, int) {
+	accepted := make([]bool, len(split.Hunks))
+	count := 0
+	for i, h := range split.Hunks {
+		// Write errors are intentionally ignored - if output fails, continue to read input
+		_, _ = fmt.Fprintf(f.writer, "\n  Hunk %d/%d:\n", i+1, len(split.Hunks))
+		for _, line := range numberLines(h.String()) {
+			_, _ = fmt.Fprintf(f.writer, "  %s\n", line)
+		}
+		_, _ = fmt.Fprint(f.writer, "  Apply this hunk? [y]es / [n]o: ")
+		input, err := f.reader.ReadString('\n')
+		if err != nil && input == "" {
+			break // Leave the remaining hunks out rather than guess
+		}
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "y", "yes", "":
+			accepted[i] = true
+			count++
+		}
+	}
+	return accepted, count
+}
+
+func (f *InteractiveFixer) prompt(hunks bool) string {
+	question := "\nApply this fix? [y]es / [n]o / [s]kip remaining: "
+	if hunks {
+		question = "\nApply this fix? [y]es / [n]o / [h]unks / [s]kip remaining: "
+	}
 	// Write error is intentionally ignored - if output fails, continue to read input
-	_, _ = fmt.Fprint(f.writer, "\nApply this fix? [y]es / [n]o / [s]kip remaining: ")
+	_, _ = fmt.Fprint(f.writer, question)
 	input, err := f.reader.ReadString('\n')
 	if err != nil {
 		return "n" // Treat read errors as skip to avoid unintended changes
 	}
 	return strings.ToLower(strings.TrimSpace(input))
 }
+
+// Ask prints a question and returns the trimmed line the user typed.
+// Returns an empty string if input cannot be read.
+func (f *InteractiveFixer) Ask(question string) string {
+	// Write error is intentionally ignored - if output fails, continue to read input
+	_, _ = fmt.Fprint(f.writer, question)
+	input, err := f.reader.ReadString('\n')
+	if err != nil && input == "" {
+		return ""
+	}
+	return strings.TrimSpace(input)
+}
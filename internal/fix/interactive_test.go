@@ -585,3 +585,201 @@ func TestInteractiveFixer_ApplyFnErrorInMixedIssues(t *testing.T) {
 		t.Error("expected output to contain error message")
 	}
 }
+
+func TestInteractiveFixer_ShowsPreviewWithContext(t *testing.T) {
+	issues := []review.Issue{
+		{
+			Severity:    "medium",
+			Description: "Unchecked error",
+			Fix: &review.Fix{
+				Available: true,
+				Code:      "if err := f(); err != nil {",
+				FilePath:  "main.go",
+				StartLine: 10,
+				EndLine:   10,
+			},
+		},
+	}
+	input := bytes.NewBufferString("n\n")
+	output := &bytes.Buffer{}
+
+	fixer := NewInteractiveFixer(input, output, func(*review.Fix) error { return nil })
+	fixer.SetPreviewer(func(fix *review.Fix) (string, error) {
+		return "@@ -9,3 +9,3 @@\n func run() {\n-\tf()\n+" + fix.Code + "\n }\n", nil
+	})
+	fixer.Run(issues)
+
+	outStr := output.String()
+	if !strings.Contains(outStr, "  @@ -9,3 +9,3 @@") {
+		t.Errorf("expected output to contain the preview hunk, got:\n%s", outStr)
+	}
+	if !strings.Contains(outStr, "   func run() {") {
+		t.Error("expected output to contain context lines")
+	}
+	if strings.Contains(outStr, "After:") {
+		t.Error("expected preview to replace the plain replacement code")
+	}
+}
+
+func TestInteractiveFixer_PreviewErrorFallsBackToCode(t *testing.T) {
+	issues := []review.Issue{
+		{
+			Severity:    "low",
+			Description: "Typo",
+			Fix: &review.Fix{
+				Available: true,
+				Code:      "fixed",
+				FilePath:  "main.go",
+				StartLine: 1,
+				EndLine:   1,
+			},
+		},
+	}
+	input := bytes.NewBufferString("n\n")
+	output := &bytes.Buffer{}
+
+	fixer := NewInteractiveFixer(input, output, func(*review.Fix) error { return nil })
+	fixer.SetPreviewer(func(*review.Fix) (string, error) {
+		return "", fmt.Errorf("file not found")
+	})
+	fixer.Run(issues)
+
+	if !strings.Contains(output.String(), "After:  fixed") {
+		t.Errorf("expected fallback to the replacement code, got:\n%s", output.String())
+	}
+}
+
+func TestInteractiveFixer_AutoApply(t *testing.T) {
+	issues := []review.Issue{
+		{
+			Severity:    "low",
+			Description: "Trailing whitespace",
+			Fix:         &review.Fix{Available: true, Code: "trimmed", FilePath: "a.go", StartLine: 1, EndLine: 1},
+		},
+		{
+			Severity:    "high",
+			Description: "SQL injection",
+			Fix:         &review.Fix{Available: true, Code: "safe", FilePath: "b.go", StartLine: 2, EndLine: 2},
+		},
+	}
+	// Only the high-severity fix is prompted for; the user declines it
+	input := bytes.NewBufferString("n\n")
+	output := &bytes.Buffer{}
+
+	var applied []string
+	fixer := NewInteractiveFixer(input, output, func(f *review.Fix) error {
+		applied = append(applied, f.FilePath)
+		return nil
+	})
+	fixer.SetAutoApply(func(issue review.Issue) bool { return issue.Severity == "low" })
+	stats := fixer.Run(issues)
+
+	if len(applied) != 1 || applied[0] != "a.go" {
+		t.Errorf("applied = %v, want [a.go]", applied)
+	}
+	if stats.Applied != 1 || stats.AutoApplied != 1 || stats.Skipped != 1 {
+		t.Errorf("stats = %+v, want 1 applied (1 automatically), 1 skipped", stats)
+	}
+
+	outStr := output.String()
+	if strings.Count(outStr, "Apply this fix?") != 1 {
+		t.Errorf("expected a single prompt, got:\n%s", outStr)
+	}
+	if !strings.Contains(outStr, "Applied automatically") {
+		t.Error("expected the auto-applied fix to be reported")
+	}
+	if !strings.Contains(outStr, "Applied 1 fix(es) (1 automatically), skipped 1") {
+		t.Errorf("expected summary to count auto-applied fixes, got:\n%s", outStr)
+	}
+}
+
+func TestInteractiveFixer_PlainWithSeverityLabels(t *testing.T) {
+	issues := []review.Issue{{Severity: "high", Description: "SQL injection", Fix: &review.Fix{Available: true, Code: "fixed"}}}
+	input := bytes.NewBufferString("n\n")
+	output := &bytes.Buffer{}
+
+	fixer := NewInteractiveFixer(input, output, func(*review.Fix) error { return nil })
+	fixer.SetSeverityLabels(review.SeverityLabels{"high": "high severity"})
+	fixer.SetPlain(true)
+	fixer.Run(issues)
+
+	if !strings.Contains(output.String(), "[high severity] SQL injection") {
+		t.Errorf("expected the configured severity label, got:\n%s", output.String())
+	}
+	if strings.Contains(output.String(), "----") {
+		t.Errorf("plain output should not contain rules, got:\n%s", output.String())
+	}
+}
+
+func TestInteractiveFixer_PicksHunks(t *testing.T) {
+	applier, _, fix := twoHunkFix(t)
+	var applied *review.Fix
+	input := bytes.NewBufferString("h\nn\ny\n")
+	output := &bytes.Buffer{}
+
+	fixer := NewInteractiveFixer(input, output, func(f *review.Fix) error {
+		applied = f
+		return nil
+	})
+	fixer.SetSplitter(applier.Split)
+	stats := fixer.Run([]review.Issue{{Severity: "low", Description: "naming", Fix: fix}})
+
+	if stats.Applied != 1 {
+		t.Errorf("expected 1 applied, got %d", stats.Applied)
+	}
+	if applied == nil || strings.Contains(applied.Code, "fixed3") || !strings.Contains(applied.Code, "renamed7") {
+		t.Errorf("applied fix = %+v, want only the second hunk", applied)
+	}
+	out := output.String()
+	for _, want := range []string{"[h]unks", "Hunk 1/2:", "-line3", "+fixed3", "Hunk 2/2:", "Applied 1 of 2 hunks"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestInteractiveFixer_NoHunksAccepted(t *testing.T) {
+	applier, _, fix := twoHunkFix(t)
+	applyCalled := false
+	fixer := NewInteractiveFixer(bytes.NewBufferString("h\nn\nn\n"), &bytes.Buffer{}, func(f *review.Fix) error {
+		applyCalled = true
+		return nil
+	})
+	fixer.SetSplitter(applier.Split)
+	stats := fixer.Run([]review.Issue{{Severity: "low", Description: "naming", Fix: fix}})
+
+	if applyCalled || stats.Skipped != 1 {
+		t.Errorf("rejecting every hunk should skip the fix, got applied=%v stats=%+v", applyCalled, stats)
+	}
+}
+
+func TestInteractiveFixer_OffersHunksOnlyForSeveralChanges(t *testing.T) {
+	applier, _, fix := twoHunkFix(t)
+	fix.Code = "line2\nfixed3\nline4\nline5\nline6\nline7\nline8"
+	output := &bytes.Buffer{}
+	fixer := NewInteractiveFixer(bytes.NewBufferString("n\n"), output, func(*review.Fix) error { return nil })
+	fixer.SetSplitter(applier.Split)
+	fixer.Run([]review.Issue{{Severity: "low", Description: "naming", Fix: fix}})
+
+	if strings.Contains(output.String(), "[h]unks") {
+		t.Error("a fix with a single hunk should not offer to pick hunks")
+	}
+}
+
+func TestNumberLines(t *testing.T) {
+	got := numberLines("@@ -9,3 +9,3 @@\n func run() {\n-\tf()\n+\tg()\n }\n")
+	want := []string{
+		"@@ -9,3 +9,3 @@",
+		" 9  9   func run() {",
+		"10     -\tf()",
+		"   10  +\tg()",
+		"11 11   }",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("numberLines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if got := numberLines("plain text\n"); len(got) != 1 || got[0] != "plain text" {
+		t.Errorf("numberLines() of text that is not a hunk = %q", got)
+	}
+}
@@ -0,0 +1,310 @@
+package fix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	godiffpatch "github.com/sourcegraph/go-diff-patch"
+)
+
+// JournalFile is the name of the file, in the git directory, holding the
+// journal of the last fix session so its fixes can be undone later.
+const JournalFile = "revi-fixes.json"
+
+var (
+	// ErrFixNotFound is returned by Undo when no applied fix has the given ID.
+	ErrFixNotFound = errors.New("no applied fix with that ID")
+	// ErrFileChanged is returned by Undo when the file was modified after the
+	// fix was applied, so restoring it would discard those changes.
+	ErrFileChanged = errors.New("file was modified after the fix was applied")
+)
+
+// JournalEntry records a single file change made by an applied fix.
+type JournalEntry struct {
+	// ID identifies the fix within the journal
+	ID string `json:"id"`
+	// Path is the absolute path of the modified file
+	Path string `json:"path"`
+	// Before is the file content prior to applying the fix
+	Before string `json:"before"`
+	// After is the file content after applying the fix
+	After string `json:"after"`
+	// Fix is the fix that produced the change
+	Fix *review.Fix `json:"fix,omitempty"`
+}
+
+// Journal is an ordered record of the changes made during a fix session.
+// Undone fixes are removed from it. It is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+	lastID  int
+}
+
+// NewJournal creates an empty Journal.
+func NewJournal() *Journal {
+	return &Journal{}
+}
+
+// LoadJournal reads a journal saved with Save. A missing file yields an empty journal.
+func LoadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewJournal(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fix journal: %w", err)
+	}
+
+	j := NewJournal()
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse fix journal: %w", err)
+	}
+	for _, e := range j.entries {
+		if n, err := strconv.Atoi(e.ID); err == nil && n > j.lastID {
+			j.lastID = n
+		}
+	}
+	return j, nil
+}
+
+// Save writes the journal to path as JSON.
+func (j *Journal) Save(path string) error {
+	entries := j.Entries()
+	if entries == nil {
+		entries = []JournalEntry{}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode fix journal: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fix journal: %w", err)
+	}
+	return nil
+}
+
+// Record appends an entry to the journal, assigning it the next ID if it has none.
+func (j *Journal) Record(entry JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if entry.ID == "" {
+		j.lastID++
+		entry.ID = strconv.Itoa(j.lastID)
+	}
+	j.entries = append(j.entries, entry)
+}
+
+// Lookup returns the ID of the most recent entry recorded for fix.
+func (j *Journal) Lookup(fix *review.Fix) (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if j.entries[i].Fix == fix {
+			return j.entries[i].ID, true
+		}
+	}
+	return "", false
+}
+
+// Undo restores the file changed by the fix with the given ID to its content
+// before the fix and removes the fix from the journal. An empty id undoes the
+// most recent fix. Fixes to the same file are undone last-applied first, and
+// a file changed outside the journal since the fix is left untouched.
+func (j *Journal) Undo(id string) (JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	idx := len(j.entries) - 1
+	if id != "" {
+		for idx >= 0 && j.entries[idx].ID != id {
+			idx--
+		}
+	}
+	if idx < 0 {
+		return JournalEntry{}, ErrFixNotFound
+	}
+	entry := j.entries[idx]
+
+	for _, later := range j.entries[idx+1:] {
+		if later.Path == entry.Path {
+			return JournalEntry{}, fmt.Errorf("fix %s changed %s after fix %s; undo it first", later.ID, entry.Path, entry.ID)
+		}
+	}
+
+	info, err := os.Stat(entry.Path)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	current, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if string(current) != entry.After {
+		return JournalEntry{}, fmt.Errorf("%s: %w", entry.Path, ErrFileChanged)
+	}
+	if err := os.WriteFile(entry.Path, []byte(entry.Before), info.Mode().Perm()); err != nil {
+		return JournalEntry{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	j.entries = append(j.entries[:idx], j.entries[idx+1:]...)
+	return entry, nil
+}
+
+// Entries returns a copy of the recorded entries in the order they were applied.
+func (j *Journal) Entries() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]JournalEntry(nil), j.entries...)
+}
+
+// Len returns the number of recorded entries.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// Paths returns the paths of the modified files, each once, in the order they
+// were first changed.
+func (j *Journal) Paths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, e := range j.Entries() {
+		if !seen[e.Path] {
+			seen[e.Path] = true
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// WritePatch writes all journaled changes as a single unified diff.
+// Multiple fixes to the same file are combined into one file diff spanning the
+// content before the first fix and after the last one. Paths in the patch are
+// relative to root so the result can be applied with `git apply`.
+func (j *Journal) WritePatch(w io.Writer, root string) error {
+	entries := j.Entries()
+
+	before := make(map[string]string)
+	after := make(map[string]string)
+	for _, e := range entries {
+		if _, seen := before[e.Path]; !seen {
+			before[e.Path] = e.Before
+		}
+		after[e.Path] = e.After
+	}
+
+	return writeFilePatches(w, root, before, after)
+}
+
+// SuggestedPatch builds a unified diff of the given fixes without modifying any files.
+// It is intended for exporting fixes that were not applied. Fixes that are unavailable,
+// outside the root, or that cannot be applied cleanly are skipped and reported in the
+// returned error list. Line numbers are adjusted for the fixes already applied.
+func (a *Applier) SuggestedPatch(w io.Writer, fixes []*review.Fix) []error {
+	var errs []error
+
+	// Group fixes by file so each file gets a single diff
+	byPath := make(map[string][]*review.Fix)
+	for _, f := range fixes {
+		if f == nil || !f.Available {
+			continue
+		}
+		absPath, err := a.resolvePath(f.FilePath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// Move the fix past the lines changed by applied fixes
+		adjusted, err := a.offsets.adjust(absPath, f)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.FilePath, err))
+			continue
+		}
+		byPath[absPath] = append(byPath[absPath], adjusted)
+	}
+
+	before := make(map[string]string)
+	after := make(map[string]string)
+	for path, pathFixes := range byPath {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read file: %w", err))
+			continue
+		}
+		content := string(data)
+
+		// Apply bottom-up so earlier line numbers stay valid
+		sort.SliceStable(pathFixes, func(i, k int) bool {
+			return pathFixes[i].StartLine > pathFixes[k].StartLine
+		})
+
+		updated := content
+		for _, f := range pathFixes {
+			next, err := replaceLines(updated, f)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.FilePath, err))
+				continue
+			}
+			updated = next
+		}
+
+		before[path] = content
+		after[path] = updated
+	}
+
+	if err := writeFilePatches(w, a.root, before, after); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// writeFilePatches writes a git-style diff for every path whose content changed,
+// in sorted path order for deterministic output.
+func writeFilePatches(w io.Writer, root string, before, after map[string]string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("invalid root path: %w", err)
+	}
+
+	paths := make([]string, 0, len(after))
+	for path := range after {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if before[path] == after[path] {
+			continue
+		}
+
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil {
+			return fmt.Errorf("failed to make %s relative to %s: %w", path, root, err)
+		}
+		rel = filepath.ToSlash(rel)
+
+		patch := diff.QuoteFileLines(godiffpatch.GeneratePatch(rel, before[path], after[path]), rel)
+		if !strings.HasPrefix(patch, "diff --git ") {
+			if _, err := fmt.Fprintln(w, diff.GitHeader(rel)); err != nil {
+				return fmt.Errorf("failed to write patch: %w", err)
+			}
+		}
+		if _, err := io.WriteString(w, patch); err != nil {
+			return fmt.Errorf("failed to write patch: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package fix
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestJournal_WritePatch_CombinesFixesPerFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	original := "line1\nline2\nline3\nline4\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	journal := NewJournal()
+	applier := NewApplier(tmpDir)
+	applier.SetJournal(journal)
+
+	fixes := []*review.Fix{
+		{Available: true, Code: "LINE1", FilePath: filePath, StartLine: 1, EndLine: 1},
+		{Available: true, Code: "LINE4", FilePath: filePath, StartLine: 4, EndLine: 4},
+	}
+	for _, f := range fixes {
+		if err := applier.Apply(f); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+
+	if journal.Len() != 2 {
+		t.Fatalf("journal.Len() = %d, want 2", journal.Len())
+	}
+
+	var b strings.Builder
+	if err := journal.WritePatch(&b, tmpDir); err != nil {
+		t.Fatalf("WritePatch failed: %v", err)
+	}
+	patch := b.String()
+
+	if strings.Count(patch, "diff --git a/main.go b/main.go") != 1 {
+		t.Errorf("expected a single file header for main.go, got:\n%s", patch)
+	}
+	for _, want := range []string{"-line1", "+LINE1", "-line4", "+LINE4"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+}
+
+func TestJournal_Paths(t *testing.T) {
+	journal := NewJournal()
+	journal.Record(JournalEntry{Path: "/repo/b.go"})
+	journal.Record(JournalEntry{Path: "/repo/a.go"})
+	journal.Record(JournalEntry{Path: "/repo/b.go"})
+
+	paths := journal.Paths()
+	if len(paths) != 2 || paths[0] != "/repo/b.go" || paths[1] != "/repo/a.go" {
+		t.Errorf("Paths() = %v, want [/repo/b.go /repo/a.go]", paths)
+	}
+}
+
+func TestJournal_WritePatch_Empty(t *testing.T) {
+	var b strings.Builder
+	if err := NewJournal().WritePatch(&b, t.TempDir()); err != nil {
+		t.Fatalf("WritePatch failed: %v", err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected empty patch, got:\n%s", b.String())
+	}
+}
+
+func TestApplier_SuggestedPatch_DoesNotModifyFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	original := "a\nb\nc\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	applier := NewApplier(tmpDir)
+	fixes := []*review.Fix{
+		{Available: true, Code: "A", FilePath: filePath, StartLine: 1, EndLine: 1},
+		{Available: true, Code: "C", FilePath: filePath, StartLine: 3, EndLine: 3},
+		{Available: false, Reason: "needs human judgment"},
+	}
+
+	var b strings.Builder
+	if errs := applier.SuggestedPatch(&b, fixes); len(errs) != 0 {
+		t.Fatalf("SuggestedPatch returned errors: %v", errs)
+	}
+
+	patch := b.String()
+	for _, want := range []string{"+A", "+C", "-a", "-c"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("SuggestedPatch modified the file: %q", string(content))
+	}
+}
+
+func TestApplier_SuggestedPatch_ReportsInvalidFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("a\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	applier := NewApplier(tmpDir)
+	fixes := []*review.Fix{
+		{Available: true, Code: "x", FilePath: filePath, StartLine: 5, EndLine: 6},
+	}
+
+	var b strings.Builder
+	errs := applier.SuggestedPatch(&b, fixes)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for out-of-range fix, got %v", errs)
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no patch output, got:\n%s", b.String())
+	}
+}
+
+func TestApplier_Undo(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	original := "line1\nline2\nline3\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	journal := NewJournal()
+	applier := NewApplier(tmpDir)
+	applier.SetJournal(journal)
+
+	first := &review.Fix{Available: true, Code: "LINE1", FilePath: filePath, StartLine: 1, EndLine: 1}
+	second := &review.Fix{Available: true, Code: "LINE3", FilePath: filePath, StartLine: 3, EndLine: 3}
+	for _, f := range []*review.Fix{first, second} {
+		if err := applier.Apply(f); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+	}
+
+	firstID, ok := journal.Lookup(first)
+	if !ok || firstID != "1" {
+		t.Fatalf("Lookup(first) = %q, %v, want 1, true", firstID, ok)
+	}
+
+	// The later fix to the same file has to be undone first
+	if _, err := applier.Undo(firstID); err == nil {
+		t.Fatal("expected an error undoing a fix followed by another fix to the same file")
+	}
+
+	entry, err := applier.Undo("")
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if entry.Fix != second {
+		t.Errorf("Undo(\"\") undid fix %s, want the most recent one", entry.ID)
+	}
+	if _, err := applier.Undo(firstID); err != nil {
+		t.Fatalf("Undo(%s) failed: %v", firstID, err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != original {
+		t.Errorf("content after undo = %q, want %q", string(content), original)
+	}
+	if journal.Len() != 0 {
+		t.Errorf("journal.Len() = %d after undoing every fix, want 0", journal.Len())
+	}
+	if _, err := applier.Undo(firstID); !errors.Is(err, ErrFixNotFound) {
+		t.Errorf("Undo of an undone fix: got %v, want ErrFixNotFound", err)
+	}
+}
+
+func TestApplier_Undo_FileChanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	journal := NewJournal()
+	applier := NewApplier(tmpDir)
+	applier.SetJournal(journal)
+	if err := applier.Apply(&review.Fix{Available: true, Code: "A", FilePath: filePath, StartLine: 1, EndLine: 1}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte("edited\n"), 0644); err != nil {
+		t.Fatalf("failed to edit file: %v", err)
+	}
+
+	if _, err := applier.Undo(""); !errors.Is(err, ErrFileChanged) {
+		t.Fatalf("Undo: got %v, want ErrFileChanged", err)
+	}
+	content, _ := os.ReadFile(filePath)
+	if string(content) != "edited\n" {
+		t.Errorf("Undo overwrote a modified file: %q", string(content))
+	}
+	if journal.Len() != 1 {
+		t.Errorf("journal.Len() = %d after a failed undo, want 1", journal.Len())
+	}
+}
+
+func TestJournal_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFile)
+
+	journal := NewJournal()
+	journal.Record(JournalEntry{Path: "/repo/a.go", Before: "a", After: "A"})
+	journal.Record(JournalEntry{Path: "/repo/b.go", Before: "b", After: "B"})
+	if err := journal.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("LoadJournal failed: %v", err)
+	}
+	entries := loaded.Entries()
+	if len(entries) != 2 || entries[0].ID != "1" || entries[1].ID != "2" || entries[1].After != "B" {
+		t.Fatalf("loaded entries = %+v", entries)
+	}
+
+	// New entries continue the ID sequence
+	loaded.Record(JournalEntry{Path: "/repo/c.go"})
+	if got := loaded.Entries()[2].ID; got != "3" {
+		t.Errorf("next ID = %q, want 3", got)
+	}
+}
+
+func TestLoadJournal_Missing(t *testing.T) {
+	journal, err := LoadJournal(filepath.Join(t.TempDir(), JournalFile))
+	if err != nil {
+		t.Fatalf("LoadJournal failed: %v", err)
+	}
+	if journal.Len() != 0 {
+		t.Errorf("journal.Len() = %d, want 0", journal.Len())
+	}
+}
@@ -0,0 +1,80 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// ErrFixOverlaps is returned when a fix changes lines that an applied fix
+// already replaced, so its line numbers no longer describe the file.
+var ErrFixOverlaps = errors.New("fix overlaps an applied fix")
+
+// lineEdit is a fix applied to a file, in the line numbers of the file as it
+// was reviewed
+type lineEdit struct {
+	start, end int // replaced range
+	delta      int // lines added by the replacement, negative if it removed lines
+}
+
+// lineOffsets tracks the fixes applied to each file, so that fixes whose line
+// numbers refer to the reviewed file can be moved to where those lines are now.
+// It is safe for concurrent use.
+type lineOffsets struct {
+	mu    sync.Mutex
+	edits map[string][]lineEdit // by absolute path, in the order applied
+}
+
+// newLineOffsets creates a tracker with no applied fixes
+func newLineOffsets() *lineOffsets {
+	return &lineOffsets{edits: make(map[string][]lineEdit)}
+}
+
+// adjust returns a copy of fix with its line range moved past the lines added
+// or removed by the fixes already applied to path. Returns an error wrapping
+// ErrFixOverlaps if the range overlaps one of them.
+func (o *lineOffsets) adjust(path string, fix *review.Fix) (*review.Fix, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	shift := 0
+	for _, e := range o.edits[path] {
+		switch {
+		case fix.EndLine < e.start:
+			// Above the applied fix, so unaffected
+		case fix.StartLine > e.end:
+			shift += e.delta
+		default:
+			return nil, fmt.Errorf("lines %d-%d: %w at lines %d-%d", fix.StartLine, fix.EndLine, ErrFixOverlaps, e.start, e.end)
+		}
+	}
+
+	adjusted := *fix
+	adjusted.StartLine += shift
+	adjusted.EndLine += shift
+	return &adjusted, nil
+}
+
+// record notes that fix, in the line numbers of the reviewed file, was
+// applied to path
+func (o *lineOffsets) record(path string, fix *review.Fix) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	replaced := fix.EndLine - fix.StartLine + 1
+	added := strings.Count(fix.Code, "\n") + 1
+	o.edits[path] = append(o.edits[path], lineEdit{start: fix.StartLine, end: fix.EndLine, delta: added - replaced})
+}
+
+// forget drops the most recently applied fix to path, after it was undone
+func (o *lineOffsets) forget(path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if edits := o.edits[path]; len(edits) > 0 {
+		o.edits[path] = edits[:len(edits)-1]
+	}
+}
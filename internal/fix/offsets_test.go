@@ -0,0 +1,110 @@
+package fix
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// writeNumberedFile writes a file whose lines are "line 1" to "line n"
+func writeNumberedFile(t *testing.T, dir string, n int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		b.WriteString("line " + strconv.Itoa(i) + "\n")
+	}
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return path
+}
+
+func TestApplier_Apply_AdjustsLinesForEarlierFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := writeNumberedFile(t, tmpDir, 6)
+	applier := NewApplier(tmpDir)
+
+	// Top-down: the first fix grows line 2 into three lines and the second
+	// shrinks lines 4-5 into one, so the third must move down by one line
+	fixes := []*review.Fix{
+		{Available: true, Code: "two a\ntwo b\ntwo c", FilePath: path, StartLine: 2, EndLine: 2},
+		{Available: true, Code: "four and five", FilePath: path, StartLine: 4, EndLine: 5},
+		{Available: true, Code: "six", FilePath: path, StartLine: 6, EndLine: 6},
+	}
+	for _, f := range fixes {
+		if err := applier.Apply(f); err != nil {
+			t.Fatalf("Apply(lines %d-%d) error = %v", f.StartLine, f.EndLine, err)
+		}
+	}
+
+	content, _ := os.ReadFile(path)
+	want := "line 1\ntwo a\ntwo b\ntwo c\nline 3\nfour and five\nsix\n"
+	if string(content) != want {
+		t.Errorf("content = %q, want %q", string(content), want)
+	}
+	if fixes[2].StartLine != 6 {
+		t.Error("Apply should not modify the fix's line numbers")
+	}
+}
+
+func TestApplier_Apply_RejectsOverlappingFix(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := writeNumberedFile(t, tmpDir, 4)
+	applier := NewApplier(tmpDir)
+
+	if err := applier.Apply(&review.Fix{Available: true, Code: "x\ny", FilePath: path, StartLine: 2, EndLine: 3}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	err := applier.Apply(&review.Fix{Available: true, Code: "z", FilePath: path, StartLine: 3, EndLine: 4})
+	if !errors.Is(err, ErrFixOverlaps) {
+		t.Errorf("Apply() error = %v, want ErrFixOverlaps", err)
+	}
+}
+
+func TestApplier_Undo_ForgetsLineOffsets(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := writeNumberedFile(t, tmpDir, 3)
+	applier := NewApplier(tmpDir)
+	applier.SetJournal(NewJournal())
+
+	if err := applier.Apply(&review.Fix{Available: true, Code: "a\nb\nc", FilePath: path, StartLine: 1, EndLine: 1}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if _, err := applier.Undo(""); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if err := applier.Apply(&review.Fix{Available: true, Code: "three", FilePath: path, StartLine: 3, EndLine: 3}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "line 1\nline 2\nthree\n" {
+		t.Errorf("content = %q, want the undone fix not to shift later fixes", string(content))
+	}
+}
+
+func TestApplier_SuggestedPatch_AdjustsForAppliedFixes(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := writeNumberedFile(t, tmpDir, 3)
+	applier := NewApplier(tmpDir)
+
+	if err := applier.Apply(&review.Fix{Available: true, Code: "one a\none b", FilePath: path, StartLine: 1, EndLine: 1}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	errs := applier.SuggestedPatch(&out, []*review.Fix{{Available: true, Code: "three", FilePath: path, StartLine: 3, EndLine: 3}})
+	if len(errs) != 0 {
+		t.Fatalf("SuggestedPatch() errors = %v", errs)
+	}
+	if !strings.Contains(out.String(), "-line 3\n+three") {
+		t.Errorf("patch should replace line 3 of the reviewed file, got:\n%s", out.String())
+	}
+}
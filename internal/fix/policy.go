@@ -0,0 +1,66 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// Policy selects fixes that are applied without asking. It maps a review mode
+// to the highest severity whose fixes are applied automatically, so
+// {style: low} applies low-severity style fixes and prompts for everything else.
+type Policy map[review.Mode]string
+
+// NewPolicy builds a Policy from fix.auto_apply, which maps mode names to
+// severities.
+func NewPolicy(rules map[string]string) (Policy, error) {
+	policy := make(Policy, len(rules))
+	for name, severity := range rules {
+		mode := review.Mode(strings.ToLower(strings.TrimSpace(name)))
+		if review.GetModeInfo(mode).Name == "" {
+			return nil, fmt.Errorf("unknown review mode %q", name)
+		}
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if severityRank(severity) == 0 {
+			return nil, fmt.Errorf("severity %q for %s must be high, medium or low", severity, mode)
+		}
+		policy[mode] = severity
+	}
+	return policy, nil
+}
+
+// Allows reports whether the fix for issue, found by mode, is applied without
+// asking. Issues whose severity was not recognized are always left to the user.
+func (p Policy) Allows(mode review.Mode, issue review.Issue) bool {
+	limit, ok := p[mode]
+	if !ok || issue.SeverityUnknown || issue.Fix == nil || !issue.Fix.Available {
+		return false
+	}
+	rank := severityRank(issue.Severity)
+	return rank > 0 && rank <= severityRank(limit)
+}
+
+// String describes the policy as "mode<=severity" pairs in mode order.
+func (p Policy) String() string {
+	var parts []string
+	for _, mode := range review.AllModes() {
+		if severity, ok := p[mode]; ok {
+			parts = append(parts, fmt.Sprintf("%s<=%s", mode, severity))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// severityRank orders severities from low (1) to high (3), 0 if unknown
+func severityRank(severity string) int {
+	switch severity {
+	case review.SeverityLow:
+		return 1
+	case review.SeverityMedium:
+		return 2
+	case review.SeverityHigh:
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package fix
+
+import (
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy(map[string]string{"Style": "LOW", "docs": "medium"})
+	if err != nil {
+		t.Fatalf("NewPolicy() failed: %v", err)
+	}
+	if policy[review.ModeStyle] != review.SeverityLow || policy[review.ModeDocs] != review.SeverityMedium {
+		t.Errorf("NewPolicy() = %v", policy)
+	}
+	if got := policy.String(); got != "style<=low, docs<=medium" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	tests := []map[string]string{
+		{"formatting": "low"},
+		{"style": "trivial"},
+	}
+	for _, rules := range tests {
+		if _, err := NewPolicy(rules); err == nil {
+			t.Errorf("NewPolicy(%v) should fail", rules)
+		}
+	}
+}
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := Policy{review.ModeStyle: review.SeverityLow, review.ModeDocs: review.SeverityMedium}
+	fixable := &review.Fix{Available: true, Code: "x"}
+
+	tests := []struct {
+		name  string
+		mode  review.Mode
+		issue review.Issue
+		want  bool
+	}{
+		{"low style", review.ModeStyle, review.Issue{Severity: "low", Fix: fixable}, true},
+		{"medium style", review.ModeStyle, review.Issue{Severity: "medium", Fix: fixable}, false},
+		{"low docs", review.ModeDocs, review.Issue{Severity: "low", Fix: fixable}, true},
+		{"medium docs", review.ModeDocs, review.Issue{Severity: "medium", Fix: fixable}, true},
+		{"mode not in policy", review.ModeSecurity, review.Issue{Severity: "low", Fix: fixable}, false},
+		{"unknown severity", review.ModeStyle, review.Issue{Severity: "low", SeverityUnknown: true, Fix: fixable}, false},
+		{"no fix", review.ModeStyle, review.Issue{Severity: "low"}, false},
+		{"unavailable fix", review.ModeStyle, review.Issue{Severity: "low", Fix: &review.Fix{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allows(tt.mode, tt.issue); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
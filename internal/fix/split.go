@@ -0,0 +1,128 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Hunk is one run of changed lines within the range a fix replaces
+type Hunk struct {
+	// StartLine is the 1-based line of the file where the hunk starts
+	StartLine int
+	// Removed holds the lines of the file the hunk replaces
+	Removed []string
+	// Added holds the lines put in their place
+	Added []string
+	// offset is the index of the hunk's first line within the fix's range
+	offset int
+}
+
+// String renders the hunk as a unified diff hunk without context.
+func (h Hunk) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.StartLine, len(h.Removed), h.StartLine, len(h.Added))
+	for _, line := range h.Removed {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range h.Added {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
+
+// SplitFix is a fix divided into hunks that can be accepted one by one, for
+// fixes that bundle an unrelated change with the actual correction.
+type SplitFix struct {
+	// Hunks holds the fix's changes in file order
+	Hunks []Hunk
+	fix   *review.Fix
+	// original holds the lines of the file in the fix's range
+	original []string
+}
+
+// Split divides a fix into hunks by comparing its code with the lines it
+// replaces. Unchanged lines inside the range separate the hunks.
+func (a *Applier) Split(fix *review.Fix) (*SplitFix, error) {
+	if !fix.Available {
+		return nil, fmt.Errorf("fix not available: %s", fix.Reason)
+	}
+	lines, target, err := a.targetLines(fix)
+	if err != nil {
+		return nil, err
+	}
+	original := lines[target.StartLine-1 : target.EndLine]
+	return &SplitFix{
+		Hunks:    diffHunks(target.StartLine, original, strings.Split(fix.Code, "\n")),
+		fix:      fix,
+		original: original,
+	}, nil
+}
+
+// Select returns the fix with only the accepted hunks, where accepted[i]
+// tells whether Hunks[i] is kept, or nil if none is. The result replaces the
+// same lines as the original fix, so it is applied like any other fix.
+func (s *SplitFix) Select(accepted []bool) *review.Fix {
+	var lines []string
+	pos, kept := 0, 0
+	for i, h := range s.Hunks {
+		lines = append(lines, s.original[pos:h.offset]...)
+		if i < len(accepted) && accepted[i] {
+			lines = append(lines, h.Added...)
+			kept++
+		} else {
+			lines = append(lines, h.Removed...)
+		}
+		pos = h.offset + len(h.Removed)
+	}
+	lines = append(lines, s.original[pos:]...)
+
+	switch kept {
+	case 0:
+		return nil
+	case len(s.Hunks):
+		return s.fix
+	}
+	selected := *s.fix
+	selected.Code = strings.Join(lines, "\n")
+	return &selected
+}
+
+// diffHunks compares the lines of a range starting at line start with their
+// replacement and returns each run of changed lines as a hunk
+func diffHunks(start int, before, after []string) []Hunk {
+	var hunks []Hunk
+	inHunk := false
+	pos := 0
+	for _, d := range gitdiff.Do(joinLines(before), joinLines(after)) {
+		lines := strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n")
+		if d.Type == diffmatchpatch.DiffEqual {
+			pos += len(lines)
+			inHunk = false
+			continue
+		}
+		if !inHunk {
+			hunks = append(hunks, Hunk{StartLine: start + pos, offset: pos})
+			inHunk = true
+		}
+		h := &hunks[len(hunks)-1]
+		if d.Type == diffmatchpatch.DiffDelete {
+			h.Removed = append(h.Removed, lines...)
+			pos += len(lines)
+		} else {
+			h.Added = append(h.Added, lines...)
+		}
+	}
+	return hunks
+}
+
+// joinLines joins lines into text that ends in a newline, as line diffs expect
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
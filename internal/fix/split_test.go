@@ -0,0 +1,102 @@
+package fix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// twoHunkFix writes a ten-line file and returns an applier for its directory,
+// the file's path and a fix of lines 2-8 that changes lines 3 and 7
+func twoHunkFix(t *testing.T) (*Applier, string, *review.Fix) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, fmt.Sprintf("line%d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return NewApplier(dir), path, &review.Fix{
+		Available: true,
+		FilePath:  "main.go",
+		StartLine: 2,
+		EndLine:   8,
+		Code:      "line2\nfixed3\nline4\nline5\nline6\nrenamed7\nextra7\nline8",
+	}
+}
+
+func TestApplier_Split(t *testing.T) {
+	applier, _, fix := twoHunkFix(t)
+
+	split, err := applier.Split(fix)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(split.Hunks) != 2 {
+		t.Fatalf("Split() = %d hunks, want 2", len(split.Hunks))
+	}
+	first, second := split.Hunks[0], split.Hunks[1]
+	if first.StartLine != 3 || strings.Join(first.Removed, ",") != "line3" || strings.Join(first.Added, ",") != "fixed3" {
+		t.Errorf("first hunk = %+v", first)
+	}
+	if second.StartLine != 7 || strings.Join(second.Removed, ",") != "line7" || strings.Join(second.Added, ",") != "renamed7,extra7" {
+		t.Errorf("second hunk = %+v", second)
+	}
+	if got := second.String(); got != "@@ -7,1 +7,2 @@\n-line7\n+renamed7\n+extra7\n" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSplitFix_Select(t *testing.T) {
+	applier, path, fix := twoHunkFix(t)
+	split, err := applier.Split(fix)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	if split.Select([]bool{false, false}) != nil {
+		t.Error("Select() with no accepted hunks should return nil")
+	}
+	if split.Select([]bool{true, true}) != fix {
+		t.Error("Select() with every hunk accepted should return the fix itself")
+	}
+
+	selected := split.Select([]bool{true, false})
+	if selected.StartLine != 2 || selected.EndLine != 8 {
+		t.Errorf("selected range = %d-%d, want the fix's 2-8", selected.StartLine, selected.EndLine)
+	}
+	if fix.Code == selected.Code {
+		t.Error("Select() should not change the original fix")
+	}
+	if err := applier.Apply(selected); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	want := "line1\nline2\nfixed3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\n"
+	if string(content) != want {
+		t.Errorf("content = %q, want only the first hunk applied", content)
+	}
+}
+
+func TestApplier_Split_AfterEarlierFix(t *testing.T) {
+	applier, _, fix := twoHunkFix(t)
+	// Replace line 1 with two lines, moving the fix's range down by one
+	if err := applier.Apply(&review.Fix{Available: true, FilePath: "main.go", StartLine: 1, EndLine: 1, Code: "a\nb"}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	split, err := applier.Split(fix)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(split.Hunks) != 2 || split.Hunks[0].StartLine != 4 || split.Hunks[1].StartLine != 8 {
+		t.Errorf("hunks = %+v, want them at lines 4 and 8", split.Hunks)
+	}
+}
@@ -0,0 +1,73 @@
+package fix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ErrFixRejected is returned by Apply when the verification command fails
+// after a fix is written. The fix has been rolled back when it is returned.
+var ErrFixRejected = errors.New("fix rejected")
+
+// verifyOutputLines is the number of trailing output lines of a failed
+// verification command included in its error
+const verifyOutputLines = 10
+
+// VerifyFunc checks the working tree after a fix is applied and returns an
+// error if the fix should be rolled back.
+type VerifyFunc func() error
+
+// CommandVerifier returns a VerifyFunc that runs command with the system shell
+// in dir, such as "go build ./..." or "go test ./...". The command fails if it
+// exits with a non-zero status or runs longer than timeout; a timeout of zero
+// or less means no limit.
+func CommandVerifier(dir, command string, timeout time.Duration) VerifyFunc {
+	return func() error {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		cmd := exec.CommandContext(ctx, shell, flag, command)
+		cmd.Dir = dir
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		// Processes started by the shell may hold the output open after it is
+		// killed, so stop waiting for them shortly after
+		cmd.WaitDelay = time.Second
+
+		err := cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%q timed out after %s", command, timeout)
+		}
+		if err != nil {
+			if tail := lastLines(output.String(), verifyOutputLines); tail != "" {
+				return fmt.Errorf("%q failed: %w\n%s", command, err, tail)
+			}
+			return fmt.Errorf("%q failed: %w", command, err)
+		}
+		return nil
+	}
+}
+
+// lastLines returns the last n lines of s, ignoring trailing newlines
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
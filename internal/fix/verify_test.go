@@ -0,0 +1,93 @@
+package fix
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestApplier_Apply_RollsBackRejectedFix(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	original := "package main\n\nfunc main() {}\n"
+	if err := os.WriteFile(filePath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	journal := NewJournal()
+	applier := NewApplier(tmpDir)
+	applier.SetJournal(journal)
+	var verified string
+	applier.SetVerifier(func() error {
+		content, _ := os.ReadFile(filePath)
+		verified = string(content)
+		return errors.New("build failed")
+	})
+
+	err := applier.Apply(&review.Fix{Available: true, Code: "func main() {", FilePath: filePath, StartLine: 3, EndLine: 3})
+	if !errors.Is(err, ErrFixRejected) {
+		t.Fatalf("Apply() error = %v, want ErrFixRejected", err)
+	}
+	if !strings.Contains(verified, "func main() {\n") || strings.Contains(verified, "{}") {
+		t.Errorf("verifier should run with the fix applied, saw %q", verified)
+	}
+	content, _ := os.ReadFile(filePath)
+	if string(content) != original {
+		t.Errorf("rejected fix should be rolled back, got %q", string(content))
+	}
+	if journal.Len() != 0 {
+		t.Error("rejected fix should not be recorded in the journal")
+	}
+}
+
+func TestApplier_Apply_KeepsVerifiedFix(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	applier := NewApplier(tmpDir)
+	applier.SetVerifier(func() error { return nil })
+
+	if err := applier.Apply(&review.Fix{Available: true, Code: "new", FilePath: filePath, StartLine: 1, EndLine: 1}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	content, _ := os.ReadFile(filePath)
+	if string(content) != "new\n" {
+		t.Errorf("content = %q, want verified fix kept", string(content))
+	}
+}
+
+func TestCommandVerifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("verification commands in this test use sh syntax")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0644); err != nil {
+		t.Fatalf("failed to write marker: %v", err)
+	}
+
+	if err := CommandVerifier(dir, "test -f marker", 0)(); err != nil {
+		t.Errorf("passing command should verify, got %v", err)
+	}
+
+	err := CommandVerifier(dir, "echo compiling; echo 'main.go:3: syntax error' >&2; exit 2", 0)()
+	if err == nil {
+		t.Fatal("failing command should not verify")
+	}
+	if !strings.Contains(err.Error(), "main.go:3: syntax error") {
+		t.Errorf("error should include the command's output, got %v", err)
+	}
+
+	err = CommandVerifier(dir, "sleep 5", 50*time.Millisecond)()
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow command should time out, got %v", err)
+	}
+}
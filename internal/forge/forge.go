@@ -0,0 +1,109 @@
+// Package forge connects revi to code hosting services, so that merge requests
+// can be fetched for review and the findings posted back as comments. Each
+// host implements Forge; GitLab, including self-hosted instances, is supported.
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/buker/revi/internal/permalink"
+)
+
+// ErrLineNotInDiff is returned by Comment when the host rejects a comment on a
+// line that is not part of the merge request's diff.
+var ErrLineNotInDiff = errors.New("line is not part of the merge request diff")
+
+// MergeRequest describes a merge (or pull) request on the host.
+type MergeRequest struct {
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	BaseSHA      string `json:"base_sha"`  // Merge base the diff is computed against
+	StartSHA     string `json:"start_sha"` // Target branch commit when the diff was computed
+	HeadSHA      string `json:"head_sha"`  // Latest commit of the source branch
+}
+
+// Comment is a comment to post on a merge request.
+type Comment struct {
+	Path string // File the comment is attached to; empty for a general comment
+	Line int    // Line in the new version of the file
+	Body string // Markdown text
+}
+
+// Forge is a code hosting service.
+type Forge interface {
+	// Name returns the provider name, e.g. "gitlab"
+	Name() string
+	// MergeRequest fetches the merge request with the given number
+	MergeRequest(ctx context.Context, number int) (*MergeRequest, error)
+	// Diff returns the merge request's changes as a unified diff
+	Diff(ctx context.Context, mr *MergeRequest) (string, error)
+	// Comment posts c on the merge request. Line comments outside the diff
+	// fail with ErrLineNotInDiff.
+	Comment(ctx context.Context, mr *MergeRequest, c Comment) error
+}
+
+// Options selects and configures a forge.
+type Options struct {
+	// Provider is the host type; empty or "auto" detects it from RepoURL
+	Provider string
+	// RepoURL is the web URL of the repository, e.g. https://gitlab.example.com/group/project
+	RepoURL string
+	// BaseURL is the root URL of the host when it is served below a path,
+	// e.g. https://example.com/gitlab; defaults to the scheme and host of RepoURL
+	BaseURL string
+	// Token authenticates API requests
+	Token string
+	// Client makes the HTTP requests; defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// New returns the forge for the repository described by opts.
+func New(opts Options) (Forge, error) {
+	provider := opts.Provider
+	if provider == "" || provider == "auto" {
+		provider = permalink.DetectProvider(opts.RepoURL)
+	}
+
+	switch provider {
+	case permalink.GitLab:
+		base, project, err := splitRepoURL(opts.RepoURL, opts.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Token == "" {
+			return nil, fmt.Errorf("no GitLab token; set %s to a token with the api scope", GitLabTokenEnv)
+		}
+		return NewGitLab(base, project, opts.Token, opts.Client), nil
+	case "":
+		return nil, fmt.Errorf("cannot detect the forge of %q; set forge.provider", opts.RepoURL)
+	default:
+		return nil, fmt.Errorf("forge %q is not supported, expected %s", provider, permalink.GitLab)
+	}
+}
+
+// splitRepoURL splits a repository URL into the host's base URL and the
+// project path below it
+func splitRepoURL(repoURL, baseURL string) (string, string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid repository URL %q", repoURL)
+	}
+	if baseURL == "" {
+		baseURL = u.Scheme + "://" + u.Host
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	project, ok := strings.CutPrefix(strings.TrimSuffix(repoURL, "/"), baseURL+"/")
+	if !ok || project == "" {
+		return "", "", fmt.Errorf("repository URL %q is not below %q", repoURL, baseURL)
+	}
+	return baseURL, project, nil
+}
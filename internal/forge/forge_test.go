@@ -0,0 +1,61 @@
+package forge
+
+import (
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Tests for New
+// =============================================================================
+
+func TestNew_DetectsGitLab(t *testing.T) {
+	f, err := New(Options{RepoURL: "https://gitlab.com/group/sub/project", Token: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	gl, ok := f.(*GitLab)
+	if !ok {
+		t.Fatalf("New() = %T, want *GitLab", f)
+	}
+	if gl.baseURL != "https://gitlab.com" || gl.project != "group/sub/project" {
+		t.Errorf("base %q project %q, want https://gitlab.com and group/sub/project", gl.baseURL, gl.project)
+	}
+}
+
+func TestNew_SelfHostedBelowPath(t *testing.T) {
+	f, err := New(Options{
+		Provider: "gitlab",
+		RepoURL:  "https://git.example.com/gitlab/team/app",
+		BaseURL:  "https://git.example.com/gitlab/",
+		Token:    "secret",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	gl := f.(*GitLab)
+	if gl.baseURL != "https://git.example.com/gitlab" || gl.project != "team/app" {
+		t.Errorf("base %q project %q, want https://git.example.com/gitlab and team/app", gl.baseURL, gl.project)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"unknown host", Options{RepoURL: "https://git.example.com/team/app", Token: "t"}, "cannot detect"},
+		{"unsupported provider", Options{Provider: "bitbucket", RepoURL: "https://bitbucket.org/team/app", Token: "t"}, "not supported"},
+		{"missing token", Options{RepoURL: "https://gitlab.com/team/app"}, GitLabTokenEnv},
+		{"repository outside base", Options{Provider: "gitlab", RepoURL: "https://gitlab.com/team/app", BaseURL: "https://other.example.com", Token: "t"}, "not below"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
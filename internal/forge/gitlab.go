@@ -0,0 +1,242 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/permalink"
+)
+
+// GitLabTokenEnv is the environment variable holding the GitLab access token.
+const GitLabTokenEnv = "GITLAB_TOKEN"
+
+// GitLab talks to the REST API (v4) of gitlab.com or a self-hosted instance.
+type GitLab struct {
+	baseURL string
+	project string
+	token   string
+	client  *http.Client
+}
+
+// NewGitLab returns a GitLab forge for project (e.g. "group/project") on the
+// instance at baseURL. A nil client uses http.DefaultClient.
+func NewGitLab(baseURL, project, token string, client *http.Client) *GitLab {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GitLab{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		project: project,
+		token:   token,
+		client:  client,
+	}
+}
+
+// Name returns "gitlab".
+func (g *GitLab) Name() string {
+	return permalink.GitLab
+}
+
+// gitlabMergeRequest is the subset of the merge request API response we use
+type gitlabMergeRequest struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	WebURL       string `json:"web_url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	DiffRefs     struct {
+		BaseSHA  string `json:"base_sha"`
+		StartSHA string `json:"start_sha"`
+		HeadSHA  string `json:"head_sha"`
+	} `json:"diff_refs"`
+}
+
+// MergeRequest fetches the merge request with the given IID.
+func (g *GitLab) MergeRequest(ctx context.Context, number int) (*MergeRequest, error) {
+	var mr gitlabMergeRequest
+	if _, err := g.do(ctx, http.MethodGet, g.mrPath(number), nil, &mr); err != nil {
+		return nil, fmt.Errorf("failed to fetch merge request !%d: %w", number, err)
+	}
+	return &MergeRequest{
+		Number:       mr.IID,
+		Title:        mr.Title,
+		URL:          mr.WebURL,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		BaseSHA:      mr.DiffRefs.BaseSHA,
+		StartSHA:     mr.DiffRefs.StartSHA,
+		HeadSHA:      mr.DiffRefs.HeadSHA,
+	}, nil
+}
+
+// gitlabDiff is one file of the merge request diffs API response
+type gitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// Diff returns the merge request's changes, fetching every page of files.
+func (g *GitLab) Diff(ctx context.Context, mr *MergeRequest) (string, error) {
+	var b strings.Builder
+	for page := "1"; page != ""; {
+		var files []gitlabDiff
+		header, err := g.do(ctx, http.MethodGet, g.mrPath(mr.Number)+"/diffs?per_page=100&page="+page, nil, &files)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch diff of merge request !%d: %w", mr.Number, err)
+		}
+		for _, f := range files {
+			writeFileDiff(&b, f)
+		}
+		page = header.Get("X-Next-Page")
+	}
+	return b.String(), nil
+}
+
+// writeFileDiff writes f as a git-style file diff
+func writeFileDiff(b *strings.Builder, f gitlabDiff) {
+	oldName, newName := diff.QuotePath("a/"+f.OldPath), diff.QuotePath("b/"+f.NewPath)
+	fmt.Fprintf(b, "diff --git %s %s\n", oldName, newName)
+	switch {
+	case f.NewFile:
+		b.WriteString("new file mode 100644\n")
+		oldName = "/dev/null"
+	case f.DeletedFile:
+		b.WriteString("deleted file mode 100644\n")
+		newName = "/dev/null"
+	}
+	if f.Diff == "" {
+		// Binary or collapsed files have no hunks
+		return
+	}
+	fmt.Fprintf(b, "--- %s\n+++ %s\n", oldName, newName)
+	b.WriteString(f.Diff)
+	if !strings.HasSuffix(f.Diff, "\n") {
+		b.WriteString("\n")
+	}
+}
+
+// Comment posts c as a note, or as a discussion on a line of the diff if c has
+// a path and line.
+func (g *GitLab) Comment(ctx context.Context, mr *MergeRequest, c Comment) error {
+	if c.Path == "" || c.Line <= 0 {
+		body := map[string]string{"body": c.Body}
+		if _, err := g.do(ctx, http.MethodPost, g.mrPath(mr.Number)+"/notes", body, nil); err != nil {
+			return fmt.Errorf("failed to comment on merge request !%d: %w", mr.Number, err)
+		}
+		return nil
+	}
+
+	body := map[string]any{
+		"body": c.Body,
+		"position": map[string]any{
+			"position_type": "text",
+			"base_sha":      mr.BaseSHA,
+			"start_sha":     mr.StartSHA,
+			"head_sha":      mr.HeadSHA,
+			"old_path":      c.Path,
+			"new_path":      c.Path,
+			"new_line":      c.Line,
+		},
+	}
+	_, err := g.do(ctx, http.MethodPost, g.mrPath(mr.Number)+"/discussions", body, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusBadRequest {
+		// GitLab rejects positions outside the diff with a validation error
+		return fmt.Errorf("%w: %s:%d (%s)", ErrLineNotInDiff, c.Path, c.Line, apiErr.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to comment on %s:%d: %w", c.Path, c.Line, err)
+	}
+	return nil
+}
+
+// APIError is a request the host answered with an error status.
+type APIError struct {
+	Status  int    // HTTP status code
+	Message string // Error message from the response body, if any
+}
+
+// Error returns the status and message.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// mrPath returns the API path of merge request number
+func (g *GitLab) mrPath(number int) string {
+	return "/projects/" + url.PathEscape(g.project) + "/merge_requests/" + strconv.Itoa(number)
+}
+
+// do sends a request to the API, encoding body as JSON and decoding the
+// response into out if they are not nil. Returns the response headers.
+func (g *GitLab) do(ctx context.Context, method, path string, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+"/api/v4"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", g.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &APIError{Status: resp.StatusCode, Message: errorMessage(resp.Body)}
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// errorMessage extracts the message of a GitLab error response body
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	var parsed struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &parsed) != nil {
+		return strings.TrimSpace(string(data))
+	}
+	switch {
+	case parsed.Message != nil:
+		if s, ok := parsed.Message.(string); ok {
+			return s
+		}
+		encoded, _ := json.Marshal(parsed.Message)
+		return string(encoded)
+	default:
+		return parsed.Error
+	}
+}
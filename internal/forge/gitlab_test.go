@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestGitLab returns a GitLab forge for group/app talking to handler
+func newTestGitLab(t *testing.T, handler http.HandlerFunc) *GitLab {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewGitLab(server.URL, "group/app", "secret", server.Client())
+}
+
+// =============================================================================
+// Tests for MergeRequest and Diff
+// =============================================================================
+
+func TestGitLab_MergeRequest(t *testing.T) {
+	gl := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fapp/merge_requests/7" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Error("expected the token in the PRIVATE-TOKEN header")
+		}
+		_, _ = w.Write([]byte(`{"iid":7,"title":"Add cache","web_url":"https://gitlab/mr/7",
+			"diff_refs":{"base_sha":"b","start_sha":"s","head_sha":"h"}}`))
+	})
+
+	mr, err := gl.MergeRequest(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("MergeRequest() error = %v", err)
+	}
+	if mr.Number != 7 || mr.Title != "Add cache" || mr.HeadSHA != "h" || mr.BaseSHA != "b" || mr.StartSHA != "s" {
+		t.Errorf("MergeRequest() = %+v", mr)
+	}
+}
+
+func TestGitLab_MergeRequest_APIError(t *testing.T) {
+	gl := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Not found"}`))
+	})
+
+	_, err := gl.MergeRequest(context.Background(), 7)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Message != "404 Not found" {
+		t.Errorf("MergeRequest() error = %v, want a 404 APIError", err)
+	}
+}
+
+func TestGitLab_Diff_FollowsPages(t *testing.T) {
+	gl := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`[{"old_path":"a.go","new_path":"a.go","diff":"@@ -1 +1 @@\n-old\n+new\n"}]`))
+		case "2":
+			_, _ = w.Write([]byte(`[{"old_path":"b.go","new_path":"b.go","new_file":true,"diff":"@@ -0,0 +1 @@\n+added"}]`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	diff, err := gl.Diff(context.Background(), &MergeRequest{Number: 7})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-old\n+new\n",
+		"diff --git a/b.go b/b.go\nnew file mode 100644\n--- /dev/null\n+++ b/b.go\n@@ -0,0 +1 @@\n+added\n",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff should contain %q, got:\n%s", want, diff)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for Comment
+// =============================================================================
+
+func TestGitLab_Comment(t *testing.T) {
+	var paths []string
+	var bodies []map[string]any
+	gl := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mr := &MergeRequest{Number: 7, BaseSHA: "b", StartSHA: "s", HeadSHA: "h"}
+
+	if err := gl.Comment(context.Background(), mr, Comment{Body: "summary"}); err != nil {
+		t.Fatalf("Comment(general) error = %v", err)
+	}
+	if err := gl.Comment(context.Background(), mr, Comment{Path: "a.go", Line: 3, Body: "issue"}); err != nil {
+		t.Fatalf("Comment(line) error = %v", err)
+	}
+
+	if !strings.HasSuffix(paths[0], "/merge_requests/7/notes") || bodies[0]["body"] != "summary" {
+		t.Errorf("general comment posted to %s with %v", paths[0], bodies[0])
+	}
+	if !strings.HasSuffix(paths[1], "/merge_requests/7/discussions") {
+		t.Errorf("line comment posted to %s, want discussions", paths[1])
+	}
+	position, _ := bodies[1]["position"].(map[string]any)
+	if position["new_path"] != "a.go" || position["new_line"] != float64(3) || position["head_sha"] != "h" {
+		t.Errorf("line comment position = %v", position)
+	}
+}
+
+func TestGitLab_Comment_LineNotInDiff(t *testing.T) {
+	gl := newTestGitLab(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":{"line_code":["can't be blank"]}}`))
+	})
+
+	err := gl.Comment(context.Background(), &MergeRequest{Number: 7}, Comment{Path: "a.go", Line: 99, Body: "x"})
+	if !errors.Is(err, ErrLineNotInDiff) {
+		t.Errorf("Comment() error = %v, want ErrLineNotInDiff", err)
+	}
+}
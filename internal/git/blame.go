@@ -0,0 +1,72 @@
+package git
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// LineAuthor describes the commit that last changed a line.
+type LineAuthor struct {
+	Name   string    // Author name
+	Email  string    // Author email
+	Commit string    // Full SHA of the commit
+	When   time.Time // When the commit was authored
+}
+
+// String returns the author as "Name <email>".
+func (a LineAuthor) String() string {
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// Blame returns who last changed each line of path at HEAD, indexed from 0.
+// Authors are given their canonical identity from the repository's mailmap.
+func (r *Repository) Blame(path string) ([]LineAuthor, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head commit: %w", err)
+	}
+
+	result, err := git.Blame(commit, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w", path, err)
+	}
+	mailmap := r.Mailmap()
+	authors := make([]LineAuthor, len(result.Lines))
+	for i, line := range result.Lines {
+		name, email := mailmap.Resolve(line.AuthorName, line.Author)
+		authors[i] = LineAuthor{
+			Name:   name,
+			Email:  email,
+			Commit: line.Hash.String(),
+			When:   line.Date,
+		}
+	}
+	return authors, nil
+}
+
+// LastAuthor returns the most recent author among lines start to end (1-based,
+// inclusive) of a file blamed with Blame. The range is clamped to the file;
+// returns false if it lies entirely outside it.
+func LastAuthor(lines []LineAuthor, start, end int) (LineAuthor, bool) {
+	start = max(start, 1)
+	end = min(end, len(lines))
+	if start > end {
+		return LineAuthor{}, false
+	}
+
+	var last LineAuthor
+	found := false
+	for _, author := range lines[start-1 : end] {
+		if !found || author.When.After(last.When) {
+			last = author
+			found = true
+		}
+	}
+	return last, found
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// =============================================================================
+// Tests for Blame and LastAuthor
+// =============================================================================
+
+func TestBlame(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	// A second author changes the last line
+	first := commitFile(t, repo, dir, "code.go", "one\ntwo\n")
+	if err := os.WriteFile(filepath.Join(dir, "code.go"), []byte("one\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := repo.repo.Worktree()
+	if _, err := worktree.Add("code.go"); err != nil {
+		t.Fatal(err)
+	}
+	second, err := worktree.Commit("Change line two", &git.CommitOptions{
+		Author: &object.Signature{Name: "Other Dev", Email: "other@example.com", When: time.Now().Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := repo.Blame("code.go")
+	if err != nil {
+		t.Fatalf("Blame() failed: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Blame() returned %d lines, want 2", len(lines))
+	}
+	if lines[0].Commit != first || lines[0].Name != "Test Author" {
+		t.Errorf("line 1 blamed on %+v, want the first commit by Test Author", lines[0])
+	}
+	if lines[1].Commit != second.String() || lines[1].String() != "Other Dev <other@example.com>" {
+		t.Errorf("line 2 blamed on %+v, want the second commit by Other Dev", lines[1])
+	}
+
+	if _, err := repo.Blame("missing.go"); err == nil {
+		t.Error("Blame() should fail for a file that is not in HEAD")
+	}
+}
+
+func TestLastAuthor(t *testing.T) {
+	now := time.Now()
+	lines := []LineAuthor{
+		{Name: "old", When: now.Add(-time.Hour)},
+		{Name: "new", When: now},
+		{Name: "mid", When: now.Add(-time.Minute)},
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       string
+		found      bool
+	}{
+		{"whole file", 1, 3, "new", true},
+		{"single line", 3, 3, "mid", true},
+		{"clamped", -5, 1, "old", true},
+		{"past the end", 3, 10, "mid", true},
+		{"outside", 4, 6, "", false},
+		{"empty range", 3, 2, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := LastAuthor(lines, tt.start, tt.end)
+			if found != tt.found || got.Name != tt.want {
+				t.Errorf("LastAuthor(%d, %d) = %q, %v; want %q, %v", tt.start, tt.end, got.Name, found, tt.want, tt.found)
+			}
+		})
+	}
+}
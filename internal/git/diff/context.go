@@ -0,0 +1,296 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// closingPrefixes start the lines that close a block in common languages
+var closingPrefixes = []string{"}", ")", "]", "end", "fi", "done", "esac", "</"}
+
+// commentPrefixes start comment lines kept with the declaration below them
+var commentPrefixes = []string{"//", "#", "/*", "*", "--", ";", "@"}
+
+// span is a range of hunks of a file diff and the lines of the new version
+// of the file they are widened to, 1-based and inclusive
+type span struct {
+	hunks       []*Hunk
+	first, last int
+}
+
+// ExpandContext widens the hunks of patch, the diff of a single file whose
+// new version is content, so that the model reviewing them sees whole
+// functions and blocks instead of three lines around each change. A hunk is
+// extended up to the header of the outermost block enclosing its changes
+// within maxLines lines, with the comments above it, and down to the line
+// closing that block if it is within maxLines lines too. Hunks that come to
+// overlap are merged.
+//
+// Blocks are found from indentation and closing brackets alone, which covers
+// most languages without parsing them. patch is returned unchanged if maxLines
+// is not positive or the file has no hunks.
+func ExpandContext(patch, content string, maxLines int) string {
+	files := Parse(strings.TrimSuffix(patch, "\n"))
+	if maxLines <= 0 || len(files) != 1 || len(files[0].Hunks) == 0 {
+		return patch
+	}
+	file := files[0]
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	var spans []*span
+	for _, h := range file.Hunks {
+		first, last := expandHunk(h, lines, maxLines)
+		if n := len(spans); n > 0 && first <= spans[n-1].last+1 {
+			prev := spans[n-1]
+			prev.hunks = append(prev.hunks, h)
+			prev.last = max(prev.last, last)
+			continue
+		}
+		spans = append(spans, &span{hunks: []*Hunk{h}, first: first, last: last})
+	}
+
+	hunks := make([]*Hunk, 0, len(spans))
+	for _, s := range spans {
+		hunks = append(hunks, s.hunk(lines))
+	}
+	file.Hunks = hunks
+	if !strings.HasSuffix(patch, "\n") {
+		return strings.TrimSuffix(file.String(), "\n")
+	}
+	return file.String()
+}
+
+// hunk returns the hunks of s joined into one spanning s.first to s.last,
+// with the lines between and around them as context. The only hunk is
+// returned unchanged if it needs no extra context.
+func (s *span) hunk(lines []string) *Hunk {
+	head := s.hunks[0]
+	if len(s.hunks) == 1 {
+		first, last := newRange(head)
+		if s.first == first && s.last == last {
+			return head
+		}
+	}
+
+	var body []string
+	next := s.first
+	for _, h := range s.hunks {
+		first, last := newRange(h)
+		body = append(body, contextLines(lines, next, first-1)...)
+		body = append(body, h.Lines...)
+		next = last + 1
+	}
+	body = append(body, contextLines(lines, next, s.last)...)
+
+	oldFirst, _ := oldRange(head)
+	newFirst, _ := newRange(head)
+	oldStart := oldFirst - (newFirst - s.first)
+	var oldCount, newCount int
+	for _, line := range body {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			newCount++
+		case strings.HasPrefix(line, "-"):
+			oldCount++
+		case strings.HasPrefix(line, `\`):
+		default:
+			oldCount++
+			newCount++
+		}
+	}
+	return &Hunk{
+		Header: fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", oldStart, oldCount, s.first, newCount, sectionHeading(head.Header)),
+		Lines:  body,
+	}
+}
+
+// contextLines returns lines first to last (1-based, inclusive) as unchanged
+// diff lines
+func contextLines(lines []string, first, last int) []string {
+	var out []string
+	for n := max(first, 1); n <= last && n <= len(lines); n++ {
+		out = append(out, " "+lines[n-1])
+	}
+	return out
+}
+
+// sectionHeading returns the text git puts after the closing "@@" of a hunk
+// header, usually the enclosing function, with its leading space
+func sectionHeading(header string) string {
+	rest := strings.TrimPrefix(header, "@@")
+	if idx := strings.Index(rest, "@@"); idx >= 0 {
+		return rest[idx+2:]
+	}
+	return ""
+}
+
+// newRange returns the lines of the new version of the file h covers. A hunk
+// with none covers the empty range following the line its header names.
+func newRange(h *Hunk) (first, last int) {
+	return hunkRange(h.NewStart(), h.Lines, '-')
+}
+
+// oldRange returns the lines of the old version of the file h covers
+func oldRange(h *Hunk) (first, last int) {
+	return hunkRange(h.OldStart(), h.Lines, '+')
+}
+
+// hunkRange returns the range of the lines of body, starting at start, that
+// do not begin with other, the marker of lines only in the other version
+func hunkRange(start int, body []string, other byte) (first, last int) {
+	count := 0
+	for _, line := range body {
+		if line == "" || (line[0] != other && line[0] != '\\') {
+			count++
+		}
+	}
+	if count == 0 {
+		return start + 1, start
+	}
+	return start, start + count - 1
+}
+
+// expandHunk returns the lines of the new version of the file h should cover
+// to show the blocks enclosing its changes
+func expandHunk(h *Hunk, lines []string, maxLines int) (first, last int) {
+	first, last = newRange(h)
+	_, after := h.LineNumbers()
+
+	// Where the changes sit in the new version, and how deeply they are indented
+	changeFirst, changeLast, indent := 0, 0, -1
+	pos := first
+	for i, line := range h.Lines {
+		if after[i] > 0 {
+			pos = after[i] + 1
+		}
+		if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+			continue
+		}
+		at := pos
+		if after[i] > 0 {
+			at = after[i]
+		}
+		if changeFirst == 0 {
+			changeFirst = at
+		}
+		changeLast = at
+		if text := line[1:]; strings.TrimSpace(text) != "" {
+			if n := indentOf(text); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+	if changeFirst == 0 || indent < 0 {
+		return first, last
+	}
+
+	start, depth := blockStart(lines, changeFirst, indent, first-maxLines)
+	if start > 0 && start < first {
+		first = start
+	}
+	if end := blockEnd(lines, changeLast, depth, last+maxLines); end > last {
+		last = end
+	}
+	return first, last
+}
+
+// blockStart returns the header of the outermost block enclosing line at,
+// whose changes are indented by indent, looking no higher than limit, and the
+// indentation of that header. Comments directly above the header are
+// included. Returns 0 and indent if no enclosing header is within limit.
+func blockStart(lines []string, at, indent, limit int) (start, depth int) {
+	depth = indent
+	for n := min(at, len(lines)+1) - 1; n >= max(limit, 1) && depth > 0; n-- {
+		text := lines[n-1]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if i := indentOf(text); i < depth && !isClosing(text) {
+			start, depth = n, i
+		}
+	}
+	if start == 0 {
+		return 0, indent
+	}
+	for start > max(limit, 1) {
+		above := lines[start-2]
+		if strings.TrimSpace(above) == "" || indentOf(above) != depth || !isComment(above) {
+			break
+		}
+		start--
+	}
+	return start, depth
+}
+
+// blockEnd returns the line closing the block indented by depth that contains
+// line at, looking no lower than limit. Returns 0 if the block does not end
+// within limit.
+func blockEnd(lines []string, at, depth, limit int) int {
+	for n := at + 1; n <= min(limit, len(lines)); n++ {
+		text := lines[n-1]
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		switch i := indentOf(text); {
+		case i == depth && isClosing(text):
+			return n
+		case i <= depth:
+			// A statement outside the block: it ended on the last non-blank
+			// line above
+			for n > at+1 && strings.TrimSpace(lines[n-2]) == "" {
+				n--
+			}
+			return n - 1
+		}
+	}
+	return 0
+}
+
+// indentOf returns the width of the leading whitespace of line, counting a
+// tab as four spaces
+func indentOf(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// isClosing reports whether line closes a block. Keywords such as "end" must
+// be whole words.
+func isClosing(line string) bool {
+	text := strings.TrimSpace(line)
+	for _, prefix := range closingPrefixes {
+		rest, ok := strings.CutPrefix(text, prefix)
+		if !ok {
+			continue
+		}
+		if !isWordByte(prefix[0]) || rest == "" || !isWordByte(rest[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWordByte reports whether c can be part of an identifier
+func isWordByte(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isComment reports whether line is a comment or an annotation
+func isComment(line string) bool {
+	text := strings.TrimSpace(line)
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
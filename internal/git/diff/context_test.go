@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const goContent = `package main
+
+// add sums a and b
+func add(a, b int) int {
+	x := a
+	y := b
+	z := 0
+	w := 1
+	sum := x + y
+	_ = z
+	_ = w
+	q := 2
+	_ = q
+	return sum
+}
+
+func other() {}
+`
+
+const goPatch = `diff --git a/add.go b/add.go
+--- a/add.go
++++ b/add.go
+@@ -6,7 +6,7 @@ func add(a, b int) int {
+ 	y := b
+ 	z := 0
+ 	w := 1
+-	sum := x - y
++	sum := x + y
+ 	_ = z
+ 	_ = w
+ 	q := 2
+`
+
+func TestExpandContext_WholeFunction(t *testing.T) {
+	got := ExpandContext(goPatch, goContent, 20)
+	want := `diff --git a/add.go b/add.go
+--- a/add.go
++++ b/add.go
+@@ -3,13 +3,13 @@ func add(a, b int) int {
+ // add sums a and b
+ func add(a, b int) int {
+ 	x := a
+ 	y := b
+ 	z := 0
+ 	w := 1
+-	sum := x - y
++	sum := x + y
+ 	_ = z
+ 	_ = w
+ 	q := 2
+ 	_ = q
+ 	return sum
+ }
+`
+	if got != want {
+		t.Errorf("ExpandContext() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExpandContext_LimitsGrowth(t *testing.T) {
+	if got := ExpandContext(goPatch, goContent, 1); got != goPatch {
+		t.Errorf("ExpandContext(1) =\n%s\nwant the patch unchanged", got)
+	}
+	if got := ExpandContext(goPatch, goContent, 0); got != goPatch {
+		t.Errorf("ExpandContext(0) =\n%s\nwant the patch unchanged", got)
+	}
+
+	// The function starts within two lines, but its end is further away
+	got := ExpandContext(goPatch, goContent, 2)
+	if !strings.Contains(got, "@@ -4,9 +4,9 @@") || !strings.HasSuffix(got, " \tq := 2\n") {
+		t.Errorf("ExpandContext(2) =\n%s\nwant it to start at the function only", got)
+	}
+}
+
+func TestExpandContext_MergesHunksInOneBlock(t *testing.T) {
+	content := `import os
+
+def run(path):
+    if os.path.exists(path):
+        a = 1
+        b = 2
+        c = 3
+        d = 4
+        e = 5
+        f = 6
+        g = 7
+        h = 8
+    return path
+
+def other():
+    pass
+`
+	patch := `diff --git a/run.py b/run.py
+--- a/run.py
++++ b/run.py
+@@ -4,3 +4,3 @@
+     if os.path.exists(path):
+-        a = 0
++        a = 1
+         b = 2
+@@ -11,3 +11,3 @@
+         g = 7
+-        h = 0
++        h = 8
+     return path
+`
+	got := ExpandContext(patch, content, 20)
+	want := `diff --git a/run.py b/run.py
+--- a/run.py
++++ b/run.py
+@@ -3,11 +3,11 @@
+ def run(path):
+     if os.path.exists(path):
+-        a = 0
++        a = 1
+         b = 2
+         c = 3
+         d = 4
+         e = 5
+         f = 6
+         g = 7
+-        h = 0
++        h = 8
+     return path
+`
+	if got != want {
+		t.Errorf("ExpandContext() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIsClosing(t *testing.T) {
+	for line, want := range map[string]bool{
+		"}":                 true,
+		"\t} else {":        true,
+		"  end":             true,
+		"end;":              true,
+		"endpoint := url":   false,
+		"done":              true,
+		"doneCh <- true":    false,
+		"return x":          false,
+		"</div>":            true,
+		") // close params": true,
+	} {
+		if got := isClosing(line); got != want {
+			t.Errorf("isClosing(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
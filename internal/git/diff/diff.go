@@ -0,0 +1,226 @@
+// Package diff parses unified diffs produced by the git package into a Diff of
+// files, hunks and lines, renders them back to text, and provides analysis
+// helpers that operate on that structure.
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// File is the portion of a unified diff that describes a single file.
+type File struct {
+	// Path is the file path taken from the "diff --git" header (b/ side)
+	Path string
+	// Header holds the lines preceding the first hunk (diff --git, mode, ---/+++)
+	Header []string
+	// Hunks holds the file's hunks in order
+	Hunks []*Hunk
+}
+
+// Hunk is a single "@@" section of a file diff.
+type Hunk struct {
+	// Header is the "@@ -a,b +c,d @@" line
+	Header string
+	// Lines holds the hunk body, each line keeping its ' ', '+' or '-' prefix
+	Lines []string
+}
+
+// Parse splits a unified diff into files and hunks.
+// Text before the first "diff --git" header is ignored. Lines of an added or
+// deleted file that follow its ---/+++ lines without a "@@" header, as older
+// versions of the git package wrote them, are parsed as a hunk covering the
+// whole file.
+func Parse(text string) []*File {
+	var files []*File
+	var cur *File
+	var hunk *Hunk
+
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			cur = &File{Path: pathFromHeader(line), Header: []string{line}}
+			files = append(files, cur)
+			hunk = nil
+		case cur == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			hunk = &Hunk{Header: line}
+			cur.Hunks = append(cur.Hunks, hunk)
+		case hunk == nil && isContentLine(line) && strings.HasPrefix(cur.Header[len(cur.Header)-1], "+++ "):
+			hunk = &Hunk{Lines: []string{line}}
+			cur.Hunks = append(cur.Hunks, hunk)
+		case hunk == nil:
+			cur.Header = append(cur.Header, line)
+		default:
+			hunk.Lines = append(hunk.Lines, line)
+		}
+	}
+
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			if h.Header == "" {
+				h.Header = wholeFileHeader(h.Lines)
+			}
+		}
+	}
+	return files
+}
+
+// isContentLine reports whether line is a line of a hunk body
+func isContentLine(line string) bool {
+	return line != "" && strings.ContainsRune("+- \\", rune(line[0]))
+}
+
+// wholeFileHeader returns the "@@" header of a hunk covering a whole file
+// with lines as its body
+func wholeFileHeader(lines []string) string {
+	var old, new int
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			new++
+		case strings.HasPrefix(line, "-"):
+			old++
+		case strings.HasPrefix(line, " "):
+			old++
+			new++
+		}
+	}
+	return HunkHeader(min(old, 1), old, min(new, 1), new)
+}
+
+// HunkHeader returns the "@@ -a,b +c,d @@" header of a hunk starting at line
+// oldStart of the old version and newStart of the new one, and spanning
+// oldLines and newLines lines of them
+func HunkHeader(oldStart, oldLines, newStart, newLines int) string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldLines, newStart, newLines)
+}
+
+// String reassembles the file diff into unified diff text.
+func (f *File) String() string {
+	var b strings.Builder
+	for _, line := range f.Header {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for _, h := range f.Hunks {
+		b.WriteString(h.String())
+	}
+	return b.String()
+}
+
+// String reassembles the hunk into unified diff text.
+func (h *Hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header)
+	b.WriteString("\n")
+	for _, line := range h.Lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ParseHunk parses a single hunk, starting with its "@@" header, as produced
+// by Hunk.String. Returns nil if text does not start with a hunk header.
+func ParseHunk(text string) *Hunk {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if !strings.HasPrefix(lines[0], "@@") {
+		return nil
+	}
+	return &Hunk{Header: lines[0], Lines: lines[1:]}
+}
+
+// NewStart returns the first line of the hunk in the new version of the file,
+// taken from the "+c,d" part of the header. Returns 0 if the header is malformed.
+func (h *Hunk) NewStart() int {
+	return h.headerStart(" +")
+}
+
+// OldStart returns the first line of the hunk in the old version of the file,
+// taken from the "-a,b" part of the header. Returns 0 if the header is malformed.
+func (h *Hunk) OldStart() int {
+	return h.headerStart(" -")
+}
+
+// LineNumbers returns the number of each line of the hunk in the old version
+// of the file, before, and in the new one, after. A number is 0 where the line
+// is not in that version: for added lines in before, and for removed lines in
+// after.
+func (h *Hunk) LineNumbers() (before, after []int) {
+	o, n := h.OldStart(), h.NewStart()
+	before = make([]int, len(h.Lines))
+	after = make([]int, len(h.Lines))
+	for i, line := range h.Lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			after[i] = n
+			n++
+		case strings.HasPrefix(line, "-"):
+			before[i] = o
+			o++
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" belongs to neither version
+		default:
+			before[i], after[i] = o, n
+			o++
+			n++
+		}
+	}
+	return before, after
+}
+
+// headerStart returns the start line following marker in the hunk header
+func (h *Hunk) headerStart(marker string) int {
+	_, rest, ok := strings.Cut(h.Header, marker)
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		end = len(rest)
+	}
+	start, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return start
+}
+
+// Removed returns the hunk's removed lines without their '-' prefix.
+func (h *Hunk) Removed() []string {
+	return h.linesWithPrefix('-')
+}
+
+// Added returns the hunk's added lines without their '+' prefix.
+func (h *Hunk) Added() []string {
+	return h.linesWithPrefix('+')
+}
+
+// linesWithPrefix returns the hunk lines starting with prefix, with the prefix stripped
+func (h *Hunk) linesWithPrefix(prefix byte) []string {
+	var out []string
+	for _, line := range h.Lines {
+		if len(line) > 0 && line[0] == prefix {
+			out = append(out, line[1:])
+		}
+	}
+	return out
+}
+
+// pathFromHeader extracts the b/ path from a "diff --git a/x b/x" line,
+// decoding it if git quoted it
+func pathFromHeader(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if strings.HasSuffix(rest, `"`) {
+		if idx := strings.LastIndex(rest, ` "b/`); idx >= 0 {
+			return strings.TrimPrefix(UnquotePath(rest[idx+1:]), "b/")
+		}
+	}
+	if idx := strings.LastIndex(rest, " b/"); idx >= 0 {
+		return rest[idx+3:]
+	}
+	return strings.TrimPrefix(UnquotePath(rest), "a/")
+}
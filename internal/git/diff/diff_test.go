@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"testing"
+)
+
+const twoFileDiff = `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ package a
+-var x = 1
++var x = 2
+diff --git a/b.go b/b.go
+new file mode 100644
+--- /dev/null
++++ b/b.go
+@@ -0,0 +1,1 @@
++package b
+`
+
+func TestParse_SplitsFilesAndHunks(t *testing.T) {
+	files := Parse(twoFileDiff)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+
+	if files[0].Path != "a.go" {
+		t.Errorf("files[0].Path = %q, want %q", files[0].Path, "a.go")
+	}
+	if len(files[0].Hunks) != 1 {
+		t.Fatalf("expected 1 hunk in a.go, got %d", len(files[0].Hunks))
+	}
+	h := files[0].Hunks[0]
+	if got := h.Removed(); len(got) != 1 || got[0] != "var x = 1" {
+		t.Errorf("Removed() = %q", got)
+	}
+	if got := h.Added(); len(got) != 1 || got[0] != "var x = 2" {
+		t.Errorf("Added() = %q", got)
+	}
+
+	if files[1].Path != "b.go" {
+		t.Errorf("files[1].Path = %q, want %q", files[1].Path, "b.go")
+	}
+	if len(files[1].Hunks) != 1 || files[1].Hunks[0].Added()[0] != "package b" {
+		t.Errorf("expected the content of b.go in a hunk, got %q", files[1].String())
+	}
+}
+
+func TestParse_FileWithoutHunkHeader(t *testing.T) {
+	// Older versions of the git package wrote added and deleted files
+	// without a "@@" header
+	text := "diff --git a/b.go b/b.go\nnew file mode 100644\n--- /dev/null\n+++ b/b.go\n+package b\n+\n+var y = 1\n" +
+		"diff --git a/c.go b/c.go\ndeleted file mode 100644\n--- a/c.go\n+++ /dev/null\n-package c"
+	files := Parse(text)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+
+	added := files[0]
+	if len(added.Header) != 4 || len(added.Hunks) != 1 {
+		t.Fatalf("b.go: header %q, %d hunks, want the content in one hunk", added.Header, len(added.Hunks))
+	}
+	if h := added.Hunks[0]; h.Header != "@@ -0,0 +1,3 @@" || len(h.Added()) != 3 {
+		t.Errorf("b.go hunk = %q", h.String())
+	}
+	if h := files[1].Hunks[0]; h.Header != "@@ -1,1 +0,0 @@" || h.Removed()[0] != "package c" {
+		t.Errorf("c.go hunk = %q", h.String())
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	var out string
+	for _, f := range Parse(twoFileDiff) {
+		out += f.String()
+	}
+	// The trailing newline of the input produces one empty body line
+	if out != twoFileDiff+"\n" {
+		t.Errorf("round trip mismatch:\ngot:\n%q\nwant:\n%q", out, twoFileDiff+"\n")
+	}
+}
+
+func TestHunk_LineNumbers(t *testing.T) {
+	h := ParseHunk("@@ -9,4 +9,4 @@ func run() {\n ctx := ctx\n-\tf()\n-\tg()\n+\th()\n }\n\\ No newline at end of file\n")
+	if h == nil {
+		t.Fatal("ParseHunk() = nil")
+	}
+	before, after := h.LineNumbers()
+	wantBefore := []int{9, 10, 11, 0, 12, 0}
+	wantAfter := []int{9, 0, 0, 10, 11, 0}
+	for i := range h.Lines {
+		if before[i] != wantBefore[i] || after[i] != wantAfter[i] {
+			t.Errorf("line %d %q numbered %d/%d, want %d/%d", i, h.Lines[i], before[i], after[i], wantBefore[i], wantAfter[i])
+		}
+	}
+
+	if ParseHunk("+only added\n") != nil {
+		t.Error("ParseHunk() without a header should return nil")
+	}
+}
+
+func TestHunk_NewStart(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"@@ -1,2 +1,2 @@", 1},
+		{"@@ -10,3 +12,5 @@ func main() {", 12},
+		{"@@ -3 +4 @@", 4},
+		{"@@ malformed @@", 0},
+	}
+	for _, tt := range tests {
+		if got := (&Hunk{Header: tt.header}).NewStart(); got != tt.want {
+			t.Errorf("NewStart(%q) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
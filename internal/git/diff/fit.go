@@ -0,0 +1,117 @@
+package diff
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+)
+
+// OmittedPrefix starts the line written in place of the content of a file
+// left out of a diff
+const OmittedPrefix = "# content omitted from review: "
+
+// OmittedNote is the line written in place of the content of a file left out
+// of a diff
+func OmittedNote(reason string) string {
+	return OmittedPrefix + reason + "\n"
+}
+
+// Values of a file's content, lowest first: what Fit leaves out first
+const (
+	valueVendored = iota
+	valueFixture
+	valueData
+	valueSource
+)
+
+// vendoredDirs hold third-party code checked into a repository
+var vendoredDirs = []string{"vendor", "node_modules", "third_party", "bower_components"}
+
+// fixtureDirs hold test fixtures and snapshots
+var fixtureDirs = []string{"testdata", "fixtures", "__fixtures__", "__snapshots__"}
+
+// dataExts are the extensions of data files
+var dataExts = []string{".csv", ".tsv", ".json", ".jsonl", ".ndjson", ".xml", ".sql", ".svg", ".geojson"}
+
+// contentValue returns how much the content of the file at p is worth to a
+// reader of the diff, and what it is if that is less than source code
+func contentValue(p string) (int, string) {
+	dirs := strings.Split(path.Dir(p), "/")
+	base := path.Base(p)
+	switch {
+	case containsAny(dirs, vendoredDirs) || strings.HasSuffix(base, ".min.js") || strings.HasSuffix(base, ".min.css"):
+		return valueVendored, "vendored code"
+	case containsAny(dirs, fixtureDirs) || path.Ext(base) == ".snap" || path.Ext(base) == ".golden":
+		return valueFixture, "test fixture"
+	case slices.Contains(dataExts, strings.ToLower(path.Ext(base))):
+		return valueData, "data file"
+	}
+	return valueSource, ""
+}
+
+// containsAny reports whether any of names is one of want
+func containsAny(names, want []string) bool {
+	return slices.ContainsFunc(names, func(name string) bool { return slices.Contains(want, name) })
+}
+
+// Fit makes a diff fit in limit bytes of text by leaving out the content of
+// its least valuable files first: vendored code, then test fixtures, then data
+// files, then the rest, the largest first within each. A file left out keeps
+// its header and a note saying why, as the git package writes for the files
+// its content filter omits, so the diff still lists every file. Files are
+// left out only while the diff is over limit and another file still has its
+// content, so the result can still be over limit; callers truncate what is
+// left. d is not changed; a diff within limit is returned as it is.
+func Fit(d *Diff, limit int) *Diff {
+	size := len(d.String())
+	if size <= limit {
+		return d
+	}
+
+	type candidate struct {
+		index  int
+		value  int
+		reason string
+		size   int
+	}
+	var candidates []candidate
+	for i, f := range d.Files {
+		if len(f.Hunks) == 0 {
+			continue
+		}
+		value, reason := contentValue(f.Path)
+		candidates = append(candidates, candidate{i, value, reason, len(f.String())})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(a.value, b.value), cmp.Compare(b.size, a.size))
+	})
+
+	fitted := &Diff{Preamble: d.Preamble, Files: slices.Clone(d.Files)}
+	for _, c := range candidates[:max(len(candidates)-1, 0)] {
+		if size <= limit {
+			break
+		}
+		reason := "over the diff size limit"
+		if c.reason != "" {
+			reason = c.reason + ", " + reason
+		}
+		omitted := omitContent(d.Files[c.index], reason)
+		fitted.Files[c.index] = omitted
+		size -= c.size - len(omitted.String())
+	}
+	return fitted
+}
+
+// omitContent returns a copy of f whose hunks, and the ---/+++ lines naming
+// the sides they apply to, are replaced with the note that its content was
+// left out for reason
+func omitContent(f *File, reason string) *File {
+	var header []string
+	for _, line := range f.Header {
+		if !strings.HasPrefix(line, "--- ") && !strings.HasPrefix(line, "+++ ") {
+			header = append(header, line)
+		}
+	}
+	return &File{Path: f.Path, Header: append(header, strings.TrimSuffix(OmittedNote(reason), "\n"))}
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// fileDiff returns the diff of a new file of n lines of text, as the git
+// package writes it
+func fileDiff(path string, n int, text string) string {
+	out := "diff --git a/" + path + " b/" + path + "\nnew file mode 100644\n--- /dev/null\n+++ b/" + path + "\n"
+	out += HunkHeader(0, 0, 1, n) + "\n"
+	return out + strings.Repeat("+"+text+"\n", n)
+}
+
+func TestFit_LeavesOutLowestValueFirst(t *testing.T) {
+	text := fileDiff("main.go", 10, "func main() {}") +
+		fileDiff("vendor/lib/lib.go", 50, "package lib") +
+		fileDiff("testdata/users.json", 40, `{"name": "a"}`) +
+		fileDiff("auth/login.go", 10, "return nil")
+
+	// Leaving out the vendored code is enough
+	d := Fit(NewDiff(text), len(text)-500)
+	got := d.String()
+	if len(d.Files) != 4 {
+		t.Fatalf("Fit() kept %d files, want all 4 listed:\n%s", len(d.Files), got)
+	}
+	if f := d.File("vendor/lib/lib.go"); len(f.Hunks) != 0 || !strings.Contains(f.String(), OmittedPrefix+"vendored code, over the diff size limit") {
+		t.Errorf("vendored file = %q, want its content left out", f.String())
+	}
+	for _, p := range []string{"main.go", "testdata/users.json", "auth/login.go"} {
+		if len(d.File(p).Hunks) != 1 {
+			t.Errorf("%s lost its content:\n%s", p, got)
+		}
+	}
+
+	// The fixture goes next, and the source files at the end stay
+	d = Fit(NewDiff(text), len(fileDiff("main.go", 10, "func main() {}"))*2+300)
+	got = d.String()
+	if len(d.File("testdata/users.json").Hunks) != 0 || len(d.File("auth/login.go").Hunks) != 1 {
+		t.Errorf("Fit() should leave out the fixture before source files:\n%s", got)
+	}
+}
+
+func TestFit_AddedVendoredFile(t *testing.T) {
+	text := fileDiff("main.go", 10, "func main() {}") + fileDiff("vendor/x.go", 50, "package x")
+
+	// Older versions of the git package wrote added files without a hunk header
+	hunkless := strings.Replace(text, HunkHeader(0, 0, 1, 50)+"\n", "", 1)
+	for _, shape := range []string{text, hunkless} {
+		d := Fit(NewDiff(shape), len(fileDiff("main.go", 10, "func main() {}"))+200)
+		f := d.File("vendor/x.go")
+		if len(f.Hunks) != 0 || !strings.Contains(f.String(), "new file mode 100644\n"+OmittedPrefix+"vendored code") {
+			t.Errorf("vendored file = %q, want its content left out in\n%s", f.String(), shape)
+		}
+		if len(d.File("main.go").Hunks) != 1 {
+			t.Errorf("main.go lost its content:\n%s", d)
+		}
+	}
+}
+
+func TestFit_KeepsOneFile(t *testing.T) {
+	text := fileDiff("a.go", 100, "x := 1") + fileDiff("b.go", 10, "y := 2")
+	got := Fit(NewDiff(text), 10)
+	if len(got.File("a.go").Hunks) != 0 || len(got.File("b.go").Hunks) != 1 {
+		t.Errorf("Fit() should leave out the larger file and keep the last one:\n%s", got)
+	}
+}
+
+func TestFit_Unchanged(t *testing.T) {
+	text := fileDiff("main.go", 3, "x")
+	d := NewDiff(text)
+	if got := Fit(d, len(text)); got != d {
+		t.Errorf("Fit() changed a diff within the limit:\n%s", got)
+	}
+	if got := Fit(NewDiff("not a diff"), 3).String(); got != "not a diff" {
+		t.Errorf("Fit() = %q, want text that is not a diff as it is", got)
+	}
+
+	// The diff given is left as it was
+	big := NewDiff(fileDiff("a.go", 100, "x := 1") + fileDiff("b.go", 10, "y := 2"))
+	Fit(big, 10)
+	if len(big.File("a.go").Hunks) != 1 {
+		t.Error("Fit() changed the diff it was given")
+	}
+}
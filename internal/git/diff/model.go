@@ -0,0 +1,206 @@
+package diff
+
+import "strings"
+
+// Diff is a unified diff parsed into files, hunks and lines, so that
+// consumers needing paths, hunks or line numbers share one parse instead of
+// each splitting the text again. String renders it back to unified diff text.
+// A Diff flows through review, chunking and truncation in place of the text.
+type Diff struct {
+	// Preamble is the text before the first file, such as the message of a
+	// patch written by git format-patch, or all of the text if it has no
+	// "diff --git" header
+	Preamble string
+	// Files holds the file diffs in the order they appear
+	Files []*File
+}
+
+// FileStatus describes what a file diff does to the file
+type FileStatus string
+
+const (
+	FileModified FileStatus = "modified"
+	FileAdded    FileStatus = "added"
+	FileDeleted  FileStatus = "deleted"
+	FileRenamed  FileStatus = "renamed"
+)
+
+// LineKind is the kind of a line in a hunk
+type LineKind int
+
+const (
+	// LineContext is a line unchanged between the two versions
+	LineContext LineKind = iota
+	// LineAdded is a line only in the new version
+	LineAdded
+	// LineRemoved is a line only in the old version
+	LineRemoved
+	// LineNoNewline is the "\ No newline at end of file" marker
+	LineNoNewline
+)
+
+// Line is a line of a hunk with its position in both versions of the file
+type Line struct {
+	Kind LineKind
+	// Text is the line without its ' ', '+' or '-' prefix
+	Text string
+	// Old and New are the line's numbers in the old and new versions of the
+	// file, 0 where it is not in that version
+	Old, New int
+}
+
+// NewDiff parses text, a unified diff produced by the git package. Text
+// before the first "diff --git" header is kept as the preamble, so String
+// renders text as it was given, except that the files always end in a newline.
+func NewDiff(text string) *Diff {
+	start := 0
+	if !strings.HasPrefix(text, "diff --git ") {
+		start = strings.Index(text, "\ndiff --git ")
+		if start < 0 {
+			return &Diff{Preamble: text}
+		}
+		start++
+	}
+	return &Diff{Preamble: text[:start], Files: Parse(strings.TrimSuffix(text[start:], "\n"))}
+}
+
+// String renders the diff as unified diff text
+func (d *Diff) String() string {
+	var b strings.Builder
+	b.WriteString(d.Preamble)
+	for _, f := range d.Files {
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// IsEmpty reports whether the diff has no files and no other text
+func (d *Diff) IsEmpty() bool {
+	return len(d.Files) == 0 && strings.TrimSpace(d.Preamble) == ""
+}
+
+// Paths returns the path of each file in the diff
+func (d *Diff) Paths() []string {
+	paths := make([]string, len(d.Files))
+	for i, f := range d.Files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// File returns the diff of the file at path, or nil if the diff does not
+// change it
+func (d *Diff) File(path string) *File {
+	for _, f := range d.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	return nil
+}
+
+// Filter returns a diff of the files keep reports true for, with d's
+// preamble. The files are shared with d.
+func (d *Diff) Filter(keep func(f *File) bool) *Diff {
+	filtered := &Diff{Preamble: d.Preamble}
+	for _, f := range d.Files {
+		if keep(f) {
+			filtered.Files = append(filtered.Files, f)
+		}
+	}
+	return filtered
+}
+
+// Scope returns a diff of the files matching include, or of every file if it
+// is empty, that do not match exclude. Patterns are those of MatchesAny, and
+// a renamed file matches by either of its paths.
+func (d *Diff) Scope(include, exclude []string) *Diff {
+	matches := func(f *File, patterns []string) bool {
+		return MatchesAny(f.Path, patterns) || MatchesAny(f.OldPath(), patterns)
+	}
+	return d.Filter(func(f *File) bool {
+		return (len(include) == 0 || matches(f, include)) && !matches(f, exclude)
+	})
+}
+
+// Stat returns the number of lines added and removed across the diff
+func (d *Diff) Stat() (added, removed int) {
+	for _, f := range d.Files {
+		a, r := f.Stat()
+		added += a
+		removed += r
+	}
+	return added, removed
+}
+
+// withHunks returns a copy of the file diff with hunks in place of its own
+func (f *File) withHunks(hunks []*Hunk) *File {
+	return &File{Path: f.Path, Header: f.Header, Hunks: hunks}
+}
+
+// Status returns what the file diff does to the file, from its header
+func (f *File) Status() FileStatus {
+	for _, line := range f.Header {
+		switch {
+		case strings.HasPrefix(line, "new file mode"):
+			return FileAdded
+		case strings.HasPrefix(line, "deleted file mode"):
+			return FileDeleted
+		case strings.HasPrefix(line, "rename from "):
+			return FileRenamed
+		}
+	}
+	return FileModified
+}
+
+// OldPath returns the path of the file before the change, which differs from
+// Path for renames
+func (f *File) OldPath() string {
+	for _, line := range f.Header {
+		if from, ok := strings.CutPrefix(line, "rename from "); ok {
+			return UnquotePath(from)
+		}
+	}
+	return f.Path
+}
+
+// IsBinary reports whether git described the file as binary instead of
+// diffing its lines
+func (f *File) IsBinary() bool {
+	for _, line := range f.Header {
+		if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+			return true
+		}
+	}
+	return false
+}
+
+// Stat returns the number of lines added to and removed from the file
+func (f *File) Stat() (added, removed int) {
+	for _, h := range f.Hunks {
+		added += len(h.Added())
+		removed += len(h.Removed())
+	}
+	return added, removed
+}
+
+// Body returns the lines of the hunk with their kind and line numbers
+func (h *Hunk) Body() []Line {
+	before, after := h.LineNumbers()
+	lines := make([]Line, len(h.Lines))
+	for i, text := range h.Lines {
+		line := Line{Old: before[i], New: after[i]}
+		switch {
+		case strings.HasPrefix(text, "+"):
+			line.Kind, line.Text = LineAdded, text[1:]
+		case strings.HasPrefix(text, "-"):
+			line.Kind, line.Text = LineRemoved, text[1:]
+		case strings.HasPrefix(text, `\`):
+			line.Kind, line.Text = LineNoNewline, text
+		case text != "":
+			line.Text = text[1:]
+		}
+		lines[i] = line
+	}
+	return lines
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+const renameDiff = `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+--- a/old.go
++++ b/new.go
+@@ -3,3 +3,3 @@ func f() {
+ 	a := 1
+-	b := 2
++	b := 3
+\ No newline at end of file
+diff --git a/logo.png b/logo.png
+deleted file mode 100644
+Binary files a/logo.png and /dev/null differ
+`
+
+func TestNewDiff_RoundTrips(t *testing.T) {
+	for _, text := range []string{twoFileDiff, renameDiff} {
+		if got := NewDiff(text).String(); got != text {
+			t.Errorf("NewDiff().String() =\n%s\nwant\n%s", got, text)
+		}
+	}
+	if d := NewDiff("not a diff"); len(d.Files) != 0 || d.String() != "not a diff" {
+		t.Errorf("NewDiff(text) = %+v, want no files and the text kept", d)
+	}
+
+	// The message of a patch written by git format-patch is kept
+	patch := "From 1234 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] Add b\n---\n" + twoFileDiff + "\n"
+	d := NewDiff(patch)
+	if got := d.String(); got != patch || len(d.Files) != 2 {
+		t.Errorf("NewDiff(patch) has %d files and renders\n%s\nwant\n%s", len(d.Files), got, patch)
+	}
+}
+
+func TestDiff_Files(t *testing.T) {
+	d := NewDiff(twoFileDiff + renameDiff)
+	if got := d.Paths(); !slices.Equal(got, []string{"a.go", "b.go", "new.go", "logo.png"}) {
+		t.Errorf("Paths() = %q", got)
+	}
+
+	tests := []struct {
+		path    string
+		status  FileStatus
+		oldPath string
+		binary  bool
+	}{
+		{"a.go", FileModified, "a.go", false},
+		{"b.go", FileAdded, "b.go", false},
+		{"new.go", FileRenamed, "old.go", false},
+		{"logo.png", FileDeleted, "logo.png", true},
+	}
+	for _, tt := range tests {
+		f := d.File(tt.path)
+		if f == nil {
+			t.Fatalf("File(%q) = nil", tt.path)
+		}
+		if f.Status() != tt.status || f.OldPath() != tt.oldPath || f.IsBinary() != tt.binary {
+			t.Errorf("File(%q): status %s, old path %q, binary %v, want %s, %q, %v",
+				tt.path, f.Status(), f.OldPath(), f.IsBinary(), tt.status, tt.oldPath, tt.binary)
+		}
+	}
+	if d.File("missing.go") != nil {
+		t.Error("File() of a path not in the diff is not nil")
+	}
+
+	if added, removed := d.Stat(); added != 3 || removed != 2 {
+		t.Errorf("Stat() = %d, %d, want 3, 2", added, removed)
+	}
+	if got := d.Filter(func(f *File) bool { return f.Status() != FileDeleted }).Paths(); len(got) != 3 {
+		t.Errorf("Filter() paths = %q, want the three files not deleted", got)
+	}
+}
+
+func TestDiff_Scope(t *testing.T) {
+	d := NewDiff(twoFileDiff + renameDiff)
+	tests := []struct {
+		name             string
+		include, exclude []string
+		want             []string
+	}{
+		{"everything", nil, nil, []string{"a.go", "b.go", "new.go", "logo.png"}},
+		{"include", []string{"*.go"}, nil, []string{"a.go", "b.go", "new.go"}},
+		{"exclude", nil, []string{"b.go", "*.png"}, []string{"a.go", "new.go"}},
+		{"both", []string{"*.go"}, []string{"a.go"}, []string{"b.go", "new.go"}},
+		{"old path of a rename", []string{"old.go"}, nil, []string{"new.go"}},
+		{"nothing matches", []string{"docs/**"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Scope(tt.include, tt.exclude).Paths(); !slices.Equal(got, tt.want) {
+				t.Errorf("Scope(%q, %q) = %q, want %q", tt.include, tt.exclude, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunk_Body(t *testing.T) {
+	h := NewDiff(renameDiff).Files[0].Hunks[0]
+	want := []Line{
+		{Kind: LineContext, Text: "\ta := 1", Old: 3, New: 3},
+		{Kind: LineRemoved, Text: "\tb := 2", Old: 4},
+		{Kind: LineAdded, Text: "\tb := 3", New: 4},
+		{Kind: LineNoNewline, Text: `\ No newline at end of file`},
+	}
+	if got := h.Body(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Body() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HunkKind classifies what a hunk changes.
+type HunkKind string
+
+const (
+	HunkSubstantive HunkKind = "substantive" // Changes code or content
+	HunkWhitespace  HunkKind = "whitespace"  // Only whitespace within lines changed
+	HunkReformat    HunkKind = "reformat"    // Lines were rewrapped/reformatted (e.g. gofmt) without changing tokens
+	HunkMove        HunkKind = "move"        // Lines were moved verbatim from or to elsewhere in the diff
+)
+
+// NoiseReport summarizes the hunks removed by FilterNoise.
+type NoiseReport struct {
+	Whitespace int // Number of whitespace-only hunks
+	Reformat   int // Number of reformat-only hunks
+	Move       int // Number of pure-move hunks
+	Kept       int // Number of substantive hunks kept
+}
+
+// Filtered returns the total number of hunks that were dropped.
+func (r NoiseReport) Filtered() int {
+	return r.Whitespace + r.Reformat + r.Move
+}
+
+// ClassifyHunks returns the kind of every hunk in files, keyed by hunk.
+// Moves are detected across the whole diff, so a block removed from one file
+// and added to another is classified as a move on both sides.
+func ClassifyHunks(files []*File) map[*Hunk]HunkKind {
+	removedLines := make(map[string]int)
+	addedLines := make(map[string]int)
+	for _, f := range files {
+		// Whole-file additions and deletions may carry their content without a hunk header
+		for _, line := range f.Header {
+			switch {
+			case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			case strings.HasPrefix(line, "+"):
+				if key := strings.TrimSpace(line[1:]); key != "" {
+					addedLines[key]++
+				}
+			case strings.HasPrefix(line, "-"):
+				if key := strings.TrimSpace(line[1:]); key != "" {
+					removedLines[key]++
+				}
+			}
+		}
+		for _, h := range f.Hunks {
+			for _, line := range h.Removed() {
+				if key := strings.TrimSpace(line); key != "" {
+					removedLines[key]++
+				}
+			}
+			for _, line := range h.Added() {
+				if key := strings.TrimSpace(line); key != "" {
+					addedLines[key]++
+				}
+			}
+		}
+	}
+
+	kinds := make(map[*Hunk]HunkKind)
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			kinds[h] = classifyHunk(h, removedLines, addedLines)
+		}
+	}
+	return kinds
+}
+
+// classifyHunk determines the kind of a single hunk
+func classifyHunk(h *Hunk, removedLines, addedLines map[string]int) HunkKind {
+	removed := h.Removed()
+	added := h.Added()
+	if len(removed) == 0 && len(added) == 0 {
+		return HunkSubstantive
+	}
+
+	if len(removed) == len(added) {
+		sameLines := true
+		for i := range removed {
+			if stripSpace(removed[i]) != stripSpace(added[i]) {
+				sameLines = false
+				break
+			}
+		}
+		if sameLines {
+			return HunkWhitespace
+		}
+	}
+
+	if stripSpace(strings.Join(removed, "")) == stripSpace(strings.Join(added, "")) {
+		return HunkReformat
+	}
+
+	// A pure move only removes lines that are added elsewhere, or vice versa
+	if len(removed) > 0 && len(added) == 0 && allIn(removed, addedLines) {
+		return HunkMove
+	}
+	if len(added) > 0 && len(removed) == 0 && allIn(added, removedLines) {
+		return HunkMove
+	}
+
+	return HunkSubstantive
+}
+
+// allIn reports whether every non-blank line appears in set
+func allIn(lines []string, set map[string]int) bool {
+	nonBlank := 0
+	for _, line := range lines {
+		key := strings.TrimSpace(line)
+		if key == "" {
+			continue
+		}
+		nonBlank++
+		if set[key] == 0 {
+			return false
+		}
+	}
+	return nonBlank > 0
+}
+
+// stripSpace removes all whitespace from s
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// FilterNoise removes whitespace-only, reformat-only and pure-move hunks from a
+// diff. Files left without hunks are dropped, except files whose header
+// records a mode change, creation, deletion or rename without hunks to begin with.
+// Returns the filtered diff and a report of what was removed; d is not changed.
+func FilterNoise(d *Diff) (*Diff, NoiseReport) {
+	kinds := ClassifyHunks(d.Files)
+
+	var report NoiseReport
+	filtered := &Diff{Preamble: d.Preamble}
+	for _, f := range d.Files {
+		hadHunks := len(f.Hunks) > 0
+		var kept []*Hunk
+		for _, h := range f.Hunks {
+			switch kinds[h] {
+			case HunkWhitespace:
+				report.Whitespace++
+			case HunkReformat:
+				report.Reformat++
+			case HunkMove:
+				report.Move++
+			default:
+				report.Kept++
+				kept = append(kept, h)
+			}
+		}
+		if hadHunks && len(kept) == 0 {
+			continue
+		}
+		filtered.Files = append(filtered.Files, f.withHunks(kept))
+	}
+
+	return filtered, report
+}
@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterNoise_DropsWhitespaceOnlyHunks(t *testing.T) {
+	input := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ func a() {
+-  return 1
++	return 1
+`
+	filtered, report := FilterNoise(NewDiff(input))
+	out := filtered.String()
+	if report.Whitespace != 1 || report.Kept != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if strings.TrimSpace(out) != "" {
+		t.Errorf("expected empty diff, got:\n%s", out)
+	}
+}
+
+func TestFilterNoise_DropsReformatHunks(t *testing.T) {
+	input := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,1 @@
+-call(a,
+-	b,
+-	c)
++call(a, b, c)
+`
+	_, report := FilterNoise(NewDiff(input))
+	if report.Reformat != 1 {
+		t.Fatalf("expected 1 reformat hunk, got %+v", report)
+	}
+}
+
+func TestFilterNoise_DropsMovedBlocks(t *testing.T) {
+	input := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,1 @@
+ package a
+-func helper() int {
+-	return 42
+-}
+diff --git a/b.go b/b.go
+--- a/b.go
++++ b/b.go
+@@ -1,1 +1,4 @@
+ package b
++func helper() int {
++	return 42
++}
+`
+	filtered, report := FilterNoise(NewDiff(input))
+	out := filtered.String()
+	if report.Move != 2 {
+		t.Fatalf("expected both sides of the move to be detected, got %+v", report)
+	}
+	if strings.Contains(out, "helper") {
+		t.Errorf("moved code should be filtered out, got:\n%s", out)
+	}
+}
+
+func TestFilterNoise_KeepsSubstantiveChanges(t *testing.T) {
+	input := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ func a() {
+-  return 1
++	return 1
+@@ -10,1 +10,1 @@
+-	x := compute(1)
++	x := compute(2)
+`
+	filtered, report := FilterNoise(NewDiff(input))
+	out := filtered.String()
+	if report.Whitespace != 1 || report.Kept != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !strings.Contains(out, "compute(2)") {
+		t.Errorf("substantive hunk missing from output:\n%s", out)
+	}
+	if strings.Contains(out, "return 1") {
+		t.Errorf("whitespace hunk should be removed:\n%s", out)
+	}
+	if !strings.HasPrefix(out, "diff --git a/a.go b/a.go") {
+		t.Errorf("file header should be preserved:\n%s", out)
+	}
+}
+
+func TestFilterNoise_KeepsFilesWithoutHunks(t *testing.T) {
+	input := `diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
++package main
+`
+	filtered, report := FilterNoise(NewDiff(input))
+	out := filtered.String()
+	if report.Filtered() != 0 {
+		t.Fatalf("nothing should be filtered, got %+v", report)
+	}
+	if !strings.Contains(out, "+package main") {
+		t.Errorf("new file content should be kept:\n%s", out)
+	}
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QuotePath returns a file name as git writes it in diff headers: unchanged,
+// or in double quotes with C-style escapes if it contains a double quote, a
+// backslash or a control character. Spaces and non-ASCII characters are kept
+// as they are, as with core.quotePath=false.
+func QuotePath(name string) string {
+	if !needsQuoting(name) {
+		return name
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				b.WriteString(`\` + strconv.FormatInt(int64(c)+0o1000, 8)[1:])
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// UnquotePath returns the file name written by git as name. Quoted names,
+// including the octal escapes git uses for non-ASCII bytes by default, are
+// decoded, and the tab git appends to names containing spaces in "---" and
+// "+++" lines is removed. Other names are returned unchanged.
+func UnquotePath(name string) string {
+	name = strings.TrimSuffix(name, "\t")
+	if len(name) < 2 || name[0] != '"' || name[len(name)-1] != '"' {
+		return name
+	}
+	s := name[1 : len(name)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := s[i]; e {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '0', '1', '2', '3':
+			if i+2 < len(s) {
+				if v, err := strconv.ParseUint(s[i:i+3], 8, 8); err == nil {
+					b.WriteByte(byte(v))
+					i += 2
+					continue
+				}
+			}
+			b.WriteByte('\\')
+			b.WriteByte(e)
+		default:
+			b.WriteByte(e)
+		}
+	}
+	return b.String()
+}
+
+// GitHeader returns the "diff --git" line for a file, without a newline
+func GitHeader(path string) string {
+	return "diff --git " + QuotePath("a/"+path) + " " + QuotePath("b/"+path)
+}
+
+// QuoteFileLines quotes the file names in the "---" and "+++" lines of a patch
+// for path generated without quoting, such as by go-diff-patch
+func QuoteFileLines(patch, path string) string {
+	if !needsQuoting(path) {
+		return patch
+	}
+	patch = strings.Replace(patch, "--- a/"+path+"\n", "--- "+QuotePath("a/"+path)+"\n", 1)
+	return strings.Replace(patch, "+++ b/"+path+"\n", "+++ "+QuotePath("b/"+path)+"\n", 1)
+}
+
+// needsQuoting reports whether git quotes name in diff headers
+func needsQuoting(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c == '"' || c == '\\' || c < 0x20 || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package diff
+
+import "testing"
+
+func TestQuotePath(t *testing.T) {
+	tests := map[string]string{
+		"a/docs/Design Überblick.md": "a/docs/Design Überblick.md",
+		`a/say "hi".txt`:             `"a/say \"hi\".txt"`,
+		"a/tab\there":                `"a/tab\there"`,
+		"a/back\\slash":              `"a/back\\slash"`,
+		"a/bell\x01":                 `"a/bell\001"`,
+	}
+	for name, want := range tests {
+		if got := QuotePath(name); got != want {
+			t.Errorf("QuotePath(%q) = %q, want %q", name, got, want)
+		}
+		if got := UnquotePath(QuotePath(name)); got != name {
+			t.Errorf("UnquotePath(QuotePath(%q)) = %q, want the original name", name, got)
+		}
+	}
+}
+
+func TestUnquotePath_GitEscapes(t *testing.T) {
+	tests := map[string]string{
+		`"b/docs/Design \303\234berblick.md"`: "b/docs/Design Überblick.md",
+		"b/docs/Design Überblick.md\t":        "b/docs/Design Überblick.md",
+		"b/plain.go":                          "b/plain.go",
+		`"`:                                   `"`,
+	}
+	for name, want := range tests {
+		if got := UnquotePath(name); got != want {
+			t.Errorf("UnquotePath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParse_PathsWithSpacesAndQuotes(t *testing.T) {
+	text := "diff --git a/docs/Design Überblick.md b/docs/Design Überblick.md\n" +
+		"@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git \"a/docs/Design \\303\\234berblick.md\" \"b/docs/Design \\303\\234berblick.md\"\n" +
+		"@@ -1 +1 @@\n-a\n+b\n"
+
+	files := Parse(text)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	for _, f := range files {
+		if f.Path != "docs/Design Überblick.md" {
+			t.Errorf("Path = %q, want docs/Design Überblick.md", f.Path)
+		}
+	}
+	if got := GitHeader("docs/Design Überblick.md"); got != "diff --git a/docs/Design Überblick.md b/docs/Design Überblick.md" {
+		t.Errorf("GitHeader() = %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package diff
+
+import (
+	"math/rand/v2"
+	"path"
+	"strings"
+)
+
+// SampledFile records how many of a file's hunks were kept by Sample.
+type SampledFile struct {
+	Path     string `json:"path"`
+	Hunks    int    `json:"hunks"`    // Hunks in the file
+	Reviewed int    `json:"reviewed"` // Hunks kept for review
+	Critical bool   `json:"critical"` // Whether the file matched a critical path
+}
+
+// SampleReport records the sampling decision made by Sample.
+type SampleReport struct {
+	Rate     float64       `json:"rate"`     // Fraction of low-risk hunks kept
+	Hunks    int           `json:"hunks"`    // Hunks in the diff
+	Reviewed int           `json:"reviewed"` // Hunks kept for review, including critical ones
+	Critical int           `json:"critical"` // Hunks kept because they are in critical paths
+	Files    []SampledFile `json:"files"`    // Per-file decisions
+}
+
+// Skipped returns the number of hunks that were left out of the review.
+func (r SampleReport) Skipped() int {
+	return r.Hunks - r.Reviewed
+}
+
+// Sample keeps every hunk in files matching one of the critical path patterns
+// and a random fraction rate of the remaining hunks, using rnd to decide. At
+// least one hunk is kept when the diff has any. Files without hunks (binary
+// files, renames) are always kept. A rate of 1 or more (or below 0) disables
+// sampling and keeps everything; a rate of 0 keeps only critical paths. d is
+// not changed.
+func Sample(d *Diff, rate float64, critical []string, rnd *rand.Rand) (*Diff, SampleReport) {
+	report := SampleReport{Rate: rate}
+	files := d.Files
+
+	sampling := rate >= 0 && rate < 1
+	kept := make(map[*Hunk]bool)
+	var candidates []*Hunk
+	for _, f := range files {
+		isCritical := MatchesAny(f.Path, critical)
+		for _, h := range f.Hunks {
+			report.Hunks++
+			switch {
+			case !sampling:
+				kept[h] = true
+			case isCritical:
+				kept[h] = true
+				report.Critical++
+			default:
+				candidates = append(candidates, h)
+				kept[h] = rnd.Float64() < rate
+			}
+		}
+	}
+
+	// Always review something: keep one low-risk hunk if the dice kept none
+	if report.Hunks > 0 && report.Critical == 0 && len(candidates) > 0 {
+		keptAny := false
+		for _, h := range candidates {
+			keptAny = keptAny || kept[h]
+		}
+		if !keptAny {
+			kept[candidates[rnd.IntN(len(candidates))]] = true
+		}
+	}
+
+	sampled := &Diff{Preamble: d.Preamble}
+	for _, f := range files {
+		entry := SampledFile{Path: f.Path, Hunks: len(f.Hunks), Critical: sampling && MatchesAny(f.Path, critical)}
+		var hunks []*Hunk
+		for _, h := range f.Hunks {
+			if kept[h] {
+				hunks = append(hunks, h)
+			}
+		}
+		entry.Reviewed = len(hunks)
+		report.Reviewed += len(hunks)
+		report.Files = append(report.Files, entry)
+
+		if entry.Hunks > 0 && len(hunks) == 0 {
+			continue
+		}
+		sampled.Files = append(sampled.Files, f.withHunks(hunks))
+	}
+
+	if !sampling {
+		return d, report
+	}
+	return sampled, report
+}
+
+// MatchesAny reports whether the file path p matches one of the patterns.
+// Patterns use path.Match syntax against the whole path; a pattern without a
+// slash also matches the file name, and a pattern ending in "/**" matches
+// everything below that directory.
+func MatchesAny(p string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if strings.HasPrefix(p, dir+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(p)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
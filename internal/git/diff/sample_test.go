@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// sampleInput returns a diff with the given number of one-line hunks per file
+func sampleInput(files map[string]int) string {
+	var b strings.Builder
+	for _, path := range []string{"auth/login.go", "docs/readme.md", "ui/button.go"} {
+		n, ok := files[path]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", path, path, path, path)
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "@@ -%d,1 +%d,1 @@\n-old %d\n+new %d\n", i*10+1, i*10+1, i, i)
+		}
+	}
+	return b.String()
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+func TestSample_KeepsCriticalPaths(t *testing.T) {
+	input := sampleInput(map[string]int{"auth/login.go": 3, "docs/readme.md": 20, "ui/button.go": 20})
+
+	sampled, report := Sample(NewDiff(input), 0.25, []string{"auth/**"}, newRand())
+	out := sampled.String()
+	if report.Hunks != 43 {
+		t.Fatalf("report.Hunks = %d, want 43", report.Hunks)
+	}
+	if report.Critical != 3 {
+		t.Errorf("report.Critical = %d, want 3", report.Critical)
+	}
+	if strings.Count(out, "diff --git a/auth/login.go") != 1 || strings.Count(out, "+new ") < 3 {
+		t.Errorf("critical file should be fully kept, got:\n%s", out)
+	}
+	if report.Reviewed <= report.Critical || report.Reviewed >= report.Hunks {
+		t.Errorf("expected a partial sample of low-risk hunks, got %+v", report)
+	}
+	if report.Skipped() != report.Hunks-report.Reviewed {
+		t.Errorf("Skipped() = %d", report.Skipped())
+	}
+
+	for _, f := range report.Files {
+		if f.Path == "auth/login.go" && (!f.Critical || f.Reviewed != 3) {
+			t.Errorf("auth/login.go entry = %+v, want critical and fully reviewed", f)
+		}
+	}
+}
+
+func TestSample_RateOneKeepsEverything(t *testing.T) {
+	input := sampleInput(map[string]int{"docs/readme.md": 5, "ui/button.go": 5})
+
+	sampled, report := Sample(NewDiff(input), 1, nil, newRand())
+	out := sampled.String()
+	if out != input {
+		t.Errorf("Sample() with rate 1 should not change the diff")
+	}
+	if report.Reviewed != 10 || report.Skipped() != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestSample_RateZeroKeepsOneHunk(t *testing.T) {
+	input := sampleInput(map[string]int{"docs/readme.md": 5, "ui/button.go": 5})
+
+	sampled, report := Sample(NewDiff(input), 0, nil, newRand())
+	out := sampled.String()
+	if report.Reviewed != 1 {
+		t.Errorf("report.Reviewed = %d, want 1", report.Reviewed)
+	}
+	if strings.Count(out, "@@ ") != 1 {
+		t.Errorf("expected a single hunk, got:\n%s", out)
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		want     bool
+	}{
+		{"auth/login.go", []string{"auth/**"}, true},
+		{"internal/auth/login.go", []string{"auth/**"}, false},
+		{"db/migrations/001.sql", []string{"*.sql"}, true},
+		{"api/handler.go", []string{"api/*.go"}, true},
+		{"api/v1/handler.go", []string{"api/*.go"}, false},
+		{"ui/button.go", []string{"auth/**", "*.sql"}, false},
+		{"ui/button.go", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesAny(tt.path, tt.patterns); got != tt.want {
+			t.Errorf("MatchesAny(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// ContentFilter selects the files whose content the diffs of a Repository
+// leave out. Such files are still listed, with a note saying why their
+// content was omitted, so reviewers and commit messages know they changed.
+type ContentFilter struct {
+	Binary    bool     // Omit files that look binary
+	Lockfiles bool     // Omit dependency lockfiles such as go.sum and package-lock.json
+	Generated bool     // Omit files marked as generated, e.g. "Code generated ... DO NOT EDIT."
+	Paths     []string // Omit files matching these patterns, as for diff.MatchesAny
+}
+
+// lockfileNames are the file names of the lockfiles written by common
+// package managers
+var lockfileNames = map[string]bool{
+	"go.sum":              true,
+	"go.work.sum":         true,
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"Cargo.lock":          true,
+	"Gemfile.lock":        true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"uv.lock":             true,
+	"composer.lock":       true,
+	"mix.lock":            true,
+	"flake.lock":          true,
+}
+
+// generatedPattern matches the markers tools put at the top of generated
+// files: Go's "Code generated ... DO NOT EDIT." and the "@generated" tag
+var generatedPattern = regexp.MustCompile(`Code generated .* DO NOT EDIT\.|@generated\b`)
+
+// Header lines searched for a generated-code marker, and bytes searched for
+// the NUL that marks a binary file (as git does)
+const (
+	generatedMarkerLines = 20
+	binarySniffBytes     = 8000
+)
+
+// omitReason returns why the content of the file at p should be left out of
+// the diff, or "" if it should be kept. contents are the file's versions
+// on either side of the change; deleted and added files have only one.
+func (f ContentFilter) omitReason(p string, contents ...string) string {
+	switch {
+	case f.Lockfiles && lockfileNames[path.Base(p)]:
+		return "lockfile"
+	case len(f.Paths) > 0 && diff.MatchesAny(p, f.Paths):
+		return "path excluded by a pattern"
+	}
+	for _, content := range contents {
+		switch {
+		case f.Binary && isBinary(content):
+			return "binary file"
+		case f.Generated && isGenerated(content):
+			return "generated file"
+		}
+	}
+	return ""
+}
+
+// isBinary returns true if content has a NUL byte near its start
+func isBinary(content string) bool {
+	if len(content) > binarySniffBytes {
+		content = content[:binarySniffBytes]
+	}
+	return strings.IndexByte(content, 0) >= 0
+}
+
+// isGenerated returns true if one of the first lines of content carries a
+// generated-code marker
+func isGenerated(content string) bool {
+	lines := strings.SplitN(content, "\n", generatedMarkerLines+1)
+	if len(lines) > generatedMarkerLines {
+		lines = lines[:generatedMarkerLines]
+	}
+	for _, line := range lines {
+		if generatedPattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// OmittedReason returns why the content of a file was left out of a diff
+// produced by GetStagedDiff, found in the header lines of the file's part of
+// the diff, or "" if its content is there.
+func OmittedReason(header []string) string {
+	for _, line := range header {
+		if reason, ok := strings.CutPrefix(line, diff.OmittedPrefix); ok {
+			return reason
+		}
+	}
+	return ""
+}
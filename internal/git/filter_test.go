@@ -0,0 +1,196 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestContentFilter_OmitReason(t *testing.T) {
+	filter := ContentFilter{Binary: true, Lockfiles: true, Generated: true, Paths: []string{"vendor/**"}}
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"main.go", "package main\n", ""},
+		{"go.sum", "github.com/x/y v1.0.0 h1:abc=\n", "lockfile"},
+		{"web/package-lock.json", "{}\n", "lockfile"},
+		{"logo.png", "\x89PNG\r\n\x1a\n\x00\x00", "binary file"},
+		{"api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api\n", "generated file"},
+		{"schema.ts", "/**\n * @generated\n */\n", "generated file"},
+		{"vendor/github.com/x/y/y.go", "package y\n", "path excluded by a pattern"},
+		{"gen.go", "package main\n\n// Code generated by hand, then edited\n", ""},
+	}
+	for _, tt := range tests {
+		if got := filter.omitReason(tt.path, tt.content); got != tt.want {
+			t.Errorf("omitReason(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got := (ContentFilter{}).omitReason("go.sum", "\x00"); got != "" {
+		t.Errorf("zero ContentFilter omitted go.sum as %q, want its content kept", got)
+	}
+}
+
+func TestGetStagedDiff_OmitsFilteredContent(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	repo.SetContentFilter(ContentFilter{Binary: true, Lockfiles: true, Generated: true})
+
+	files := map[string]string{
+		"main.go":  "package main\n",
+		"go.sum":   "github.com/x/y v1.0.0 h1:abc=\n",
+		"logo.png": "\x89PNG\r\n\x1a\n\x00\x00",
+		"initial.txt": "// Code generated by stringer. DO NOT EDIT.\n" +
+			"initial content\n",
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to stage %s: %v", name, err)
+		}
+	}
+
+	diff, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	for _, want := range []string{
+		"+package main",
+		"diff --git a/go.sum b/go.sum\nnew file mode 100644\n# content omitted from review: lockfile",
+		"diff --git a/logo.png b/logo.png\nnew file mode 100644\n# content omitted from review: binary file",
+		"diff --git a/initial.txt b/initial.txt\n# content omitted from review: generated file",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	for _, omitted := range []string{"h1:abc=", "PNG", "stringer"} {
+		if strings.Contains(diff, omitted) {
+			t.Errorf("diff should not contain the omitted content %q", omitted)
+		}
+	}
+}
+
+func TestGetWorkingTreeDiff_OmitsFilteredContent(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	repo.SetContentFilter(ContentFilter{Lockfiles: true, Generated: true})
+
+	files := map[string]string{
+		"go.sum": "github.com/x/y v1.0.0 h1:abc=\n",
+		"initial.txt": "// Code generated by stringer. DO NOT EDIT.\n" +
+			"initial content\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	diff, err := repo.GetWorkingTreeDiff()
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiff() failed: %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/go.sum b/go.sum\nnew file mode 100644\n# content omitted from review: lockfile",
+		"diff --git a/initial.txt b/initial.txt\n# content omitted from review: generated file",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	for _, omitted := range []string{"h1:abc=", "stringer"} {
+		if strings.Contains(diff, omitted) {
+			t.Errorf("diff should not contain the omitted content %q", omitted)
+		}
+	}
+
+	// Deleted files are filtered too
+	if err := os.Remove(filepath.Join(tmpDir, "initial.txt")); err != nil {
+		t.Fatalf("failed to delete initial.txt: %v", err)
+	}
+	repo.SetContentFilter(ContentFilter{Paths: []string{"initial.txt"}})
+	diff, err = repo.GetWorkingTreeDiff()
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiff() failed: %v", err)
+	}
+	want := "diff --git a/initial.txt b/initial.txt\ndeleted file mode 100644\n# content omitted from review: path excluded by a pattern"
+	if !strings.Contains(diff, want) || strings.Contains(diff, "-initial content") {
+		t.Errorf("diff should list the deleted file without its content:\n%s", diff)
+	}
+}
+
+func TestGetRangeDiff_OmitsFilteredContent(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	repo.SetContentFilter(ContentFilter{Lockfiles: true, Generated: true})
+
+	lockCommit := commitFile(t, repo, tmpDir, "go.sum", "github.com/x/y v1.0.0 h1:abc=\n")
+	commitFile(t, repo, tmpDir, "initial.txt", "// Code generated by stringer. DO NOT EDIT.\ninitial content\n")
+	commitFile(t, repo, tmpDir, "main.go", "package main\n")
+
+	diff, err := repo.GetRangeDiff("HEAD~3..HEAD")
+	if err != nil {
+		t.Fatalf("GetRangeDiff() failed: %v", err)
+	}
+	for _, want := range []string{
+		"+package main",
+		"diff --git a/go.sum b/go.sum\nnew file mode 100644\n# content omitted from review: lockfile",
+		"diff --git a/initial.txt b/initial.txt\n# content omitted from review: generated file",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("range diff missing %q:\n%s", want, diff)
+		}
+	}
+	for _, omitted := range []string{"h1:abc=", "stringer"} {
+		if strings.Contains(diff, omitted) {
+			t.Errorf("range diff should not contain the omitted content %q", omitted)
+		}
+	}
+
+	// Single commits and deleted files are filtered too
+	if diff, err := repo.GetCommitDiff(lockCommit); err != nil || strings.Contains(diff, "h1:abc=") || !strings.Contains(diff, "lockfile") {
+		t.Errorf("GetCommitDiff() = %q, %v, want the lockfile listed without its content", diff, err)
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Remove("go.sum"); err != nil {
+		t.Fatalf("failed to remove go.sum: %v", err)
+	}
+	if _, err := worktree.Commit("Remove go.sum", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	diff, err = repo.GetCommitDiff("HEAD")
+	want := "diff --git a/go.sum b/go.sum\ndeleted file mode 100644\n# content omitted from review: lockfile"
+	if err != nil || !strings.Contains(diff, want) || strings.Contains(diff, "h1:abc=") {
+		t.Errorf("GetCommitDiff() = %q, %v, want the deleted lockfile without its content", diff, err)
+	}
+}
+
+func TestOmittedReason(t *testing.T) {
+	header := []string{"diff --git a/go.sum b/go.sum", "new file mode 100644", strings.TrimSuffix(diff.OmittedNote("lockfile"), "\n")}
+	if got := OmittedReason(header); got != "lockfile" {
+		t.Errorf("OmittedReason() = %q, want %q", got, "lockfile")
+	}
+	if got := OmittedReason([]string{"diff --git a/main.go b/main.go", "--- a/main.go", "+++ b/main.go"}); got != "" {
+		t.Errorf("OmittedReason() = %q for a file with content", got)
+	}
+}
@@ -7,14 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"path/filepath"
 	"sort"
 	"strings"
-	"time"
+	"sync"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 	godiffpatch "github.com/sourcegraph/go-diff-patch"
 )
 
@@ -22,6 +25,10 @@ import (
 var (
 	// ErrNoStagedChanges is returned when attempting to get a diff but no files are staged.
 	ErrNoStagedChanges = errors.New("no staged changes found")
+	// ErrNoWorkingTreeChanges is returned when the working tree matches the index.
+	ErrNoWorkingTreeChanges = errors.New("no unstaged changes found")
+	// ErrNoChangesBetween is returned when two revisions have identical trees.
+	ErrNoChangesBetween = errors.New("no changes between the given revisions")
 	// ErrNotAGitRepo is returned when the path is not a valid git repository.
 	ErrNotAGitRepo = errors.New("not a git repository")
 )
@@ -30,6 +37,15 @@ var (
 // for reading staged changes and creating commits.
 type Repository struct {
 	repo *git.Repository
+	// contentFilter selects the files whose content GetStagedDiff omits
+	contentFilter ContentFilter
+	// skipHooks leaves out the pre-commit and commit-msg hooks when committing
+	skipHooks bool
+	// syntaxContext is how many lines hunks may grow by to show whole blocks
+	syntaxContext int
+	// mailmap is loaded by Mailmap on first use
+	mailmap     *Mailmap
+	mailmapOnce sync.Once
 }
 
 // Open opens the git repository at the given path.
@@ -51,9 +67,98 @@ func OpenCurrent() (*Repository, error) {
 	return Open(".")
 }
 
+// SetContentFilter sets the files whose content the diffs leave out, so that
+// binary, lock and generated files do not use up the diff sent to the AI. By
+// default the content of every file is kept.
+func (r *Repository) SetContentFilter(f ContentFilter) {
+	r.contentFilter = f
+}
+
+// SetSyntaxContext lets the hunks of the diffs generated from file contents
+// (staged, working tree, snapshot, commit and range changes) grow by up to
+// lines lines on each side to show the whole function or block around each change, as with
+// diff.ExpandContext. By default, and with lines 0, hunks have three lines of
+// context.
+func (r *Repository) SetSyntaxContext(lines int) {
+	r.syntaxContext = lines
+}
+
+// addedFilePatch returns the diff of a file added at path with content. If
+// the content filter selects the file, the diff has a note instead of the
+// content.
+func (r *Repository) addedFilePatch(path, content string) string {
+	var b strings.Builder
+	b.WriteString(diff.GitHeader(path) + "\n")
+	b.WriteString("new file mode 100644\n")
+	if reason := r.contentFilter.omitReason(path, content); reason != "" {
+		b.WriteString(diff.OmittedNote(reason))
+		return b.String()
+	}
+	if content != "" {
+		b.WriteString("--- /dev/null\n+++ " + diff.QuotePath("b/"+path) + "\n")
+		b.WriteString(wholeFileHunk('+', content))
+	}
+	return b.String()
+}
+
+// deletedFilePatch returns the diff of the file at path deleted with content.
+// If the content filter selects the file, the diff has a note instead of the
+// content.
+func (r *Repository) deletedFilePatch(path, content string) string {
+	var b strings.Builder
+	b.WriteString(diff.GitHeader(path) + "\n")
+	b.WriteString("deleted file mode 100644\n")
+	if reason := r.contentFilter.omitReason(path, content); reason != "" {
+		b.WriteString(diff.OmittedNote(reason))
+		return b.String()
+	}
+	if content != "" {
+		b.WriteString("--- " + diff.QuotePath("a/"+path) + "\n+++ /dev/null\n")
+		b.WriteString(wholeFileHunk('-', content))
+	}
+	return b.String()
+}
+
+// wholeFileHunk returns the hunk adding (prefix '+') or removing (prefix '-')
+// every line of content, as git writes it for added and deleted files
+func wholeFileHunk(prefix byte, content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var b strings.Builder
+	if prefix == '+' {
+		b.WriteString(diff.HunkHeader(0, 0, 1, len(lines)) + "\n")
+	} else {
+		b.WriteString(diff.HunkHeader(1, len(lines), 0, 0) + "\n")
+	}
+	for _, line := range lines {
+		b.WriteString(string(prefix) + line + "\n")
+	}
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\\ No newline at end of file\n")
+	}
+	return b.String()
+}
+
+// filePatch returns the diff of a modified file at path, with its git-style
+// header and with hunks widened as set with SetSyntaxContext. If the content
+// filter selects the file, the diff has a note instead of the content.
+func (r *Repository) filePatch(path, oldContent, newContent string) string {
+	if reason := r.contentFilter.omitReason(path, oldContent, newContent); reason != "" {
+		return diff.GitHeader(path) + "\n" + diff.OmittedNote(reason)
+	}
+	patch := diff.QuoteFileLines(godiffpatch.GeneratePatch(path, oldContent, newContent), path)
+	// go-diff-patch omits the git-style header; our tests and downstream
+	// tooling expect it
+	if !strings.HasPrefix(patch, "diff --git ") {
+		patch = diff.GitHeader(path) + "\n" + patch
+	}
+	return diff.ExpandContext(patch, newContent, r.syntaxContext)
+}
+
 // GetStagedDiff returns a unified diff of all staged changes.
 // Returns ErrNoStagedChanges if no files are staged.
 // For new repositories without commits, returns the content of staged files as additions.
+// Files selected by the content filter are listed with a note instead of their content.
+// A deleted file and an added file with mostly the same lines are shown as a rename.
 func (r *Repository) GetStagedDiff() (string, error) {
 	worktree, err := r.repo.Worktree()
 	if err != nil {
@@ -119,60 +224,225 @@ func (r *Repository) GetStagedDiff() (string, error) {
 	}
 	sort.Strings(stagedPaths) // deterministic output (useful for tests)
 
+	// The status reports renames as a deletion and an addition
+	renames, err := r.findRenames(stagedPaths, status, headTree, indexHashByPath)
+	if err != nil {
+		return "", err
+	}
+
 	for _, path := range stagedPaths {
+		if rn := renames[path]; rn != nil {
+			// Written once, in the place of the new path
+			if path == rn.to {
+				r.writeRename(&diffBuilder, rn)
+				diffBuilder.WriteString("\n")
+			}
+			continue
+		}
 		fileStatus := status.File(path)
 
 		switch fileStatus.Staging {
 		case git.Added:
-			diffBuilder.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", path, path))
-			diffBuilder.WriteString("new file mode 100644\n")
 			hash, ok := indexHashByPath[path]
 			if !ok {
 				return "", fmt.Errorf("failed to get index entry for added file %s", path)
 			}
-			content, err := r.getIndexFileContent(hash)
-			if err != nil {
-				return "", fmt.Errorf("failed to get content for added file %s: %w", path, err)
+			if err := r.writeIndexChange(&diffBuilder, path, nil, hash); err != nil {
+				return "", err
 			}
-			diffBuilder.WriteString(fmt.Sprintf("--- /dev/null\n+++ b/%s\n", path))
-			for _, line := range strings.Split(content, "\n") {
-				diffBuilder.WriteString("+" + line + "\n")
+		case git.Deleted:
+			if err := r.writeIndexChange(&diffBuilder, path, headTree, plumbing.ZeroHash); err != nil {
+				return "", err
+			}
+		case git.Modified:
+			hash, ok := indexHashByPath[path]
+			if !ok {
+				return "", fmt.Errorf("failed to get index entry for modified file %s", path)
+			}
+			if err := r.writeIndexChange(&diffBuilder, path, headTree, hash); err != nil {
+				return "", err
+			}
+		default:
+			// Best-effort: ignore uncommon staged statuses (conflicts),
+			// rather than failing the entire diff generation.
+			continue
+		}
+		diffBuilder.WriteString("\n")
+	}
+
+	return diffBuilder.String(), nil
+}
+
+// GetAmendDiff returns a unified diff of the changes amending HEAD would
+// commit: those HEAD made to its parent, with any staged since. A root commit
+// is compared with an empty tree. Merge commits cannot be amended. Returns
+// ErrNoChangesBetween if the index matches the parent.
+func (r *Repository) GetAmendDiff() (string, error) {
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if head.NumParents() > 1 {
+		return "", fmt.Errorf("cannot amend merge commit %s", head.Hash.String()[:8])
+	}
+	base := &object.Tree{}
+	if head.NumParents() == 1 {
+		parent, err := head.Parent(0)
+		if err != nil {
+			return "", fmt.Errorf("failed to get parent of HEAD: %w", err)
+		}
+		if base, err = parent.Tree(); err != nil {
+			return "", fmt.Errorf("failed to get tree for %s: %w", parent.Hash, err)
+		}
+	}
+
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to get index: %w", err)
+	}
+	hashes := make(map[string]plumbing.Hash)
+	if err := base.Files().ForEach(func(f *object.File) error {
+		hashes[f.Name] = f.Hash
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to list files of HEAD's parent: %w", err)
+	}
+	indexHashByPath := make(map[string]plumbing.Hash, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		indexHashByPath[entry.Name] = entry.Hash
+		if _, ok := hashes[entry.Name]; !ok {
+			hashes[entry.Name] = plumbing.ZeroHash
+		}
+	}
+
+	paths := make([]string, 0, len(hashes))
+	for path := range hashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		old, hash := hashes[path], indexHashByPath[path]
+		if old == hash {
+			continue
+		}
+		from := base
+		if old.IsZero() {
+			from = nil
+		}
+		if err := r.writeIndexChange(&b, path, from, hash); err != nil {
+			return "", err
+		}
+		b.WriteString("\n")
+	}
+	if b.Len() == 0 {
+		return "", ErrNoChangesBetween
+	}
+	return b.String(), nil
+}
+
+// writeIndexChange writes the diff of the file at path from its version in
+// base to the staged blob hash. The file is added if base is nil and deleted
+// if hash is zero.
+func (r *Repository) writeIndexChange(b *strings.Builder, path string, base *object.Tree, hash plumbing.Hash) error {
+	switch {
+	case base == nil:
+		content, err := r.getIndexFileContent(hash)
+		if err != nil {
+			return fmt.Errorf("failed to get content for added file %s: %w", path, err)
+		}
+		b.WriteString(r.addedFilePatch(path, content))
+	case hash.IsZero():
+		content, err := r.getTreeFileContent(base, path)
+		if err != nil {
+			return fmt.Errorf("failed to get content for deleted file %s: %w", path, err)
+		}
+		b.WriteString(r.deletedFilePatch(path, content))
+	default:
+		oldContent, err := r.getTreeFileContent(base, path)
+		if err != nil {
+			return fmt.Errorf("failed to get old content for modified file %s: %w", path, err)
+		}
+		newContent, err := r.getIndexFileContent(hash)
+		if err != nil {
+			return fmt.Errorf("failed to get new content for modified file %s: %w", path, err)
+		}
+		b.WriteString(r.filePatch(path, oldContent, newContent))
+	}
+	return nil
+}
+
+// GetWorkingTreeDiff returns a unified diff of changes in the working tree that
+// have not been staged, including untracked files (which are not ignored).
+// Returns ErrNoWorkingTreeChanges if the working tree matches the index.
+func (r *Repository) GetWorkingTreeDiff() (string, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var changedPaths []string
+	for path, s := range status {
+		if s.Worktree != git.Unmodified {
+			changedPaths = append(changedPaths, path)
+		}
+	}
+	if len(changedPaths) == 0 {
+		return "", ErrNoWorkingTreeChanges
+	}
+	sort.Strings(changedPaths) // deterministic output (useful for tests)
+
+	// The index holds the content the working tree is compared against
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to get index: %w", err)
+	}
+	indexHashByPath := make(map[string]plumbing.Hash, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		indexHashByPath[entry.Name] = entry.Hash
+	}
+
+	var diffBuilder strings.Builder
+	for _, path := range changedPaths {
+		switch status.File(path).Worktree {
+		case git.Untracked:
+			content, err := r.getWorktreeFileContent(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to get content for untracked file %s: %w", path, err)
 			}
+			diffBuilder.WriteString(r.addedFilePatch(path, content))
 		case git.Deleted:
-			diffBuilder.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", path, path))
-			diffBuilder.WriteString("deleted file mode 100644\n")
-			content, err := r.getTreeFileContent(headTree, path)
+			hash, ok := indexHashByPath[path]
+			if !ok {
+				return "", fmt.Errorf("failed to get index entry for deleted file %s", path)
+			}
+			content, err := r.getIndexFileContent(hash)
 			if err != nil {
 				return "", fmt.Errorf("failed to get content for deleted file %s: %w", path, err)
 			}
-			diffBuilder.WriteString(fmt.Sprintf("--- a/%s\n+++ /dev/null\n", path))
-			for _, line := range strings.Split(content, "\n") {
-				diffBuilder.WriteString("-" + line + "\n")
-			}
+			diffBuilder.WriteString(r.deletedFilePatch(path, content))
 		case git.Modified:
 			hash, ok := indexHashByPath[path]
 			if !ok {
 				return "", fmt.Errorf("failed to get index entry for modified file %s", path)
 			}
-			oldContent, err := r.getTreeFileContent(headTree, path)
+			oldContent, err := r.getIndexFileContent(hash)
 			if err != nil {
 				return "", fmt.Errorf("failed to get old content for modified file %s: %w", path, err)
 			}
-			newContent, err := r.getIndexFileContent(hash)
+			newContent, err := r.getWorktreeFileContent(path)
 			if err != nil {
 				return "", fmt.Errorf("failed to get new content for modified file %s: %w", path, err)
 			}
-			// Use go-diff-patch library for proper unified diff generation.
-			patch := godiffpatch.GeneratePatch(path, oldContent, newContent)
-			// Some patch generators omit the git-style header; our tests and downstream
-			// tooling expect it.
-			if !strings.HasPrefix(patch, "diff --git ") {
-				diffBuilder.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", path, path))
-			}
-			diffBuilder.WriteString(patch)
+			diffBuilder.WriteString(r.filePatch(path, oldContent, newContent))
 		default:
-			// Best-effort: ignore uncommon staged statuses (renames/conflicts),
-			// rather than failing the entire diff generation.
+			// Best-effort: ignore uncommon worktree statuses (renames/conflicts)
 			continue
 		}
 		diffBuilder.WriteString("\n")
@@ -181,6 +451,180 @@ func (r *Repository) GetStagedDiff() (string, error) {
 	return diffBuilder.String(), nil
 }
 
+// GetDiffBetween returns a unified diff of the changes from refA to refB.
+// Refs may be anything git understands as a revision (branch, tag, SHA, HEAD~2).
+// Returns ErrNoChangesBetween if both revisions have the same content.
+func (r *Repository) GetDiffBetween(refA, refB string) (string, error) {
+	commitA, err := r.resolveCommit(refA)
+	if err != nil {
+		return "", err
+	}
+	commitB, err := r.resolveCommit(refB)
+	if err != nil {
+		return "", err
+	}
+	return r.diffCommits(commitA, commitB)
+}
+
+// GetRangeDiff returns a unified diff for a revision range.
+// "A..B" compares A with B, "A...B" compares the merge base of A and B with B
+// (the changes made on B since it diverged from A), and an empty side means HEAD.
+func (r *Repository) GetRangeDiff(spec string) (string, error) {
+	if a, b, ok := strings.Cut(spec, "..."); ok {
+		commitA, err := r.resolveCommit(orHead(a))
+		if err != nil {
+			return "", err
+		}
+		commitB, err := r.resolveCommit(orHead(b))
+		if err != nil {
+			return "", err
+		}
+		bases, err := commitA.MergeBase(commitB)
+		if err != nil {
+			return "", fmt.Errorf("failed to find merge base of %s and %s: %w", orHead(a), orHead(b), err)
+		}
+		if len(bases) == 0 {
+			return "", fmt.Errorf("%s and %s have no common ancestor", orHead(a), orHead(b))
+		}
+		return r.diffCommits(bases[0], commitB)
+	}
+
+	a, b, ok := strings.Cut(spec, "..")
+	if !ok {
+		return "", fmt.Errorf("invalid range %q, expected A..B or A...B", spec)
+	}
+	return r.GetDiffBetween(orHead(a), orHead(b))
+}
+
+// GetCommitDiff returns a unified diff of the changes introduced by a single commit,
+// compared with its first parent. A root commit is compared with an empty tree.
+func (r *Repository) GetCommitDiff(ref string) (string, error) {
+	commit, err := r.resolveCommit(ref)
+	if err != nil {
+		return "", err
+	}
+	if commit.NumParents() == 0 {
+		tree, err := commit.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree for %s: %w", ref, err)
+		}
+		return r.diffTrees(&object.Tree{}, tree)
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parent of %s: %w", ref, err)
+	}
+	return r.diffCommits(parent, commit)
+}
+
+// resolveCommit resolves a revision string to a commit
+func (r *Repository) resolveCommit(ref string) (*object.Commit, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", ref, err)
+	}
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// diffCommits returns a unified diff between the trees of two commits
+func (r *Repository) diffCommits(a, b *object.Commit) (string, error) {
+	treeA, err := a.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree for %s: %w", a.Hash, err)
+	}
+	treeB, err := b.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree for %s: %w", b.Hash, err)
+	}
+	return r.diffTrees(treeA, treeB)
+}
+
+// diffTrees returns a unified diff between two trees. Each file is written as
+// in the staged diff, so the content filter and the syntax context apply.
+func (r *Repository) diffTrees(a, b *object.Tree) (string, error) {
+	changes, err := object.DiffTree(a, b)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %w", err)
+	}
+	if len(changes) == 0 {
+		return "", ErrNoChangesBetween
+	}
+
+	var diffBuilder strings.Builder
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return "", fmt.Errorf("failed to get files of change %s: %w", change, err)
+		}
+		switch {
+		case from == nil && to == nil:
+			// Submodules have no content to show
+			continue
+		case from == nil:
+			content, err := to.Contents()
+			if err != nil {
+				return "", fmt.Errorf("failed to get content for added file %s: %w", to.Name, err)
+			}
+			diffBuilder.WriteString(r.addedFilePatch(to.Name, content))
+		case to == nil:
+			content, err := from.Contents()
+			if err != nil {
+				return "", fmt.Errorf("failed to get content for deleted file %s: %w", from.Name, err)
+			}
+			diffBuilder.WriteString(r.deletedFilePatch(from.Name, content))
+		default:
+			oldContent, err := from.Contents()
+			if err != nil {
+				return "", fmt.Errorf("failed to get old content for modified file %s: %w", from.Name, err)
+			}
+			newContent, err := to.Contents()
+			if err != nil {
+				return "", fmt.Errorf("failed to get new content for modified file %s: %w", to.Name, err)
+			}
+			diffBuilder.WriteString(r.filePatch(to.Name, oldContent, newContent))
+		}
+		diffBuilder.WriteString("\n")
+	}
+	return diffBuilder.String(), nil
+}
+
+// orHead returns ref, or HEAD if ref is empty
+func orHead(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return ref
+}
+
+// getWorktreeFileContent reads a file from the working tree by repository-relative path
+func (r *Repository) getWorktreeFileContent(path string) (content string, err error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := worktree.Filesystem.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
 // getStagedFilesContent gets content of all staged files when there's no HEAD
 func (r *Repository) getStagedFilesContent(status git.Status) (string, error) {
 	idx, err := r.repo.Storer.Index()
@@ -195,16 +639,11 @@ func (r *Repository) getStagedFilesContent(status git.Status) (string, error) {
 			continue
 		}
 
-		diffBuilder.WriteString(fmt.Sprintf("diff --git a/%s b/%s\n", entry.Name, entry.Name))
-		diffBuilder.WriteString("new file mode 100644\n")
-
 		content, err := r.getIndexFileContent(entry.Hash)
-		if err == nil {
-			diffBuilder.WriteString(fmt.Sprintf("+++ b/%s\n", entry.Name))
-			for _, line := range strings.Split(content, "\n") {
-				diffBuilder.WriteString("+" + line + "\n")
-			}
+		if err != nil {
+			return "", fmt.Errorf("failed to get content for added file %s: %w", entry.Name, err)
 		}
+		diffBuilder.WriteString(r.addedFilePatch(entry.Name, content))
 		diffBuilder.WriteString("\n")
 	}
 
@@ -269,6 +708,9 @@ func (r *Repository) GetStagedFiles() ([]string, error) {
 }
 
 // Commit creates a new commit with the given message from staged changes.
+// The author and committer are taken from the git config and environment as
+// git does, and the commit is signed when commit.gpgsign is set. The
+// pre-commit, commit-msg and post-commit hooks run as for "git commit".
 // Returns the commit hash as a hex string on success.
 func (r *Repository) Commit(message string) (string, error) {
 	worktree, err := r.repo.Worktree()
@@ -276,55 +718,116 @@ func (r *Repository) Commit(message string) (string, error) {
 		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	author := r.getAuthorSignature()
+	cfg := r.loadGitConfig()
+	committer := cfg.signature("committer")
+	signer, err := cfg.signer(committer)
+	if err != nil {
+		return "", err
+	}
+
+	message, err = r.runCommitHooks(message)
+	if err != nil {
+		return "", err
+	}
 
 	hash, err := worktree.Commit(message, &git.CommitOptions{
-		Author: author,
+		Author:    cfg.signature("author"),
+		Committer: committer,
+		Signer:    signer,
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create commit: %w", err)
 	}
+	r.runPostCommitHook()
 
 	return hash.String(), nil
 }
 
-// getAuthorSignature returns an author signature for commits.
-// It tries to read from git config first, then falls back to environment
-// variables (GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL), and finally uses defaults.
-func (r *Repository) getAuthorSignature() *object.Signature {
-	name := ""
-	email := ""
+// StageFiles adds the given files to the index. Paths may be absolute or
+// relative to the repository root.
+func (r *Repository) StageFiles(paths []string) error {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	root := worktree.Filesystem.Root()
 
-	// Try to get from git config
-	cfg, err := r.repo.Config()
-	if err == nil && cfg.User.Name != "" {
-		name = cfg.User.Name
+	for _, path := range paths {
+		if filepath.IsAbs(path) {
+			rel, err := filepath.Rel(root, path)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				return fmt.Errorf("%s is outside the repository", path)
+			}
+			path = rel
+		}
+		if _, err := worktree.Add(filepath.ToSlash(path)); err != nil {
+			return fmt.Errorf("failed to stage %s: %w", path, err)
+		}
 	}
-	if err == nil && cfg.User.Email != "" {
-		email = cfg.User.Email
+	return nil
+}
+
+// HeadSubject returns the first line of the HEAD commit's message.
+func (r *Repository) HeadSubject() (string, error) {
+	commit, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return "", err
 	}
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	return subject, nil
+}
 
-	// Fall back to environment variables
-	if name == "" {
-		name = os.Getenv("GIT_AUTHOR_NAME")
+// AmendHead replaces the HEAD commit with one whose tree is the current index,
+// keeping the original message and author. Hooks run as for Commit. Merge
+// commits cannot be amended. Returns the hash of the new commit.
+func (r *Repository) AmendHead() (string, error) {
+	return r.AmendHeadMessage("")
+}
+
+// AmendHeadMessage is AmendHead with message replacing the original message,
+// unless it is empty.
+func (r *Repository) AmendHeadMessage(message string) (string, error) {
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+	if message == "" {
+		message = head.Message
 	}
-	if email == "" {
-		email = os.Getenv("GIT_AUTHOR_EMAIL")
+	if head.NumParents() > 1 {
+		return "", fmt.Errorf("cannot amend merge commit %s", head.Hash.String()[:8])
 	}
 
-	// Fall back to defaults
-	if name == "" {
-		name = "revi"
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
-	if email == "" {
-		email = "revi@localhost"
+
+	cfg := r.loadGitConfig()
+	committer := cfg.signature("committer")
+	signer, err := cfg.signer(committer)
+	if err != nil {
+		return "", err
 	}
 
-	return &object.Signature{
-		Name:  name,
-		Email: email,
-		When:  time.Now(),
+	message, err = r.runCommitHooks(message)
+	if err != nil {
+		return "", err
+	}
+
+	author := head.Author
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author:    &author,
+		Committer: committer,
+		Signer:    signer,
+		Amend:     true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to amend commit: %w", err)
 	}
+	r.runPostCommitHook()
+
+	return hash.String(), nil
 }
 
 // Root returns the absolute path to the repository root directory.
@@ -338,6 +841,86 @@ func (r *Repository) Root() (string, error) {
 	return worktree.Filesystem.Root(), nil
 }
 
+// GitDir returns the absolute path to the repository's git directory
+// (usually the .git folder inside the root), where revi keeps its lock file.
+func (r *Repository) GitDir() (string, error) {
+	if storage, ok := r.repo.Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root(), nil
+	}
+
+	root, err := r.Root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ".git"), nil
+}
+
+// HooksDir returns the directory git runs hooks from: core.hooksPath if set
+// in any config file git reads (relative paths are resolved against the
+// worktree root), otherwise the hooks directory inside the git directory.
+func (r *Repository) HooksDir() (string, error) {
+	if hooksPath := expandHome(r.loadGitConfig().get("core", "", "hooksPath")); hooksPath != "" {
+		if filepath.IsAbs(hooksPath) {
+			return hooksPath, nil
+		}
+		root, err := r.Root()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, hooksPath), nil
+	}
+
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "hooks"), nil
+}
+
+// RemoteURL returns the first URL configured for the named remote.
+func (r *Repository) RemoteURL(name string) (string, error) {
+	remote, err := r.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", name, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+	return urls[0], nil
+}
+
+// HeadCommit returns the full SHA of the commit HEAD points to.
+func (r *Repository) HeadCommit() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// CurrentBranch returns the short name of the branch HEAD is on, such as
+// release/1.2, or "" if HEAD is detached. The branch need not have commits yet.
+func (r *Repository) CurrentBranch() (string, error) {
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Type() != plumbing.SymbolicReference || !head.Target().IsBranch() {
+		return "", nil
+	}
+	return head.Target().Short(), nil
+}
+
+// CommitHash returns the full SHA of the commit ref resolves to.
+func (r *Repository) CommitHash(ref string) (string, error) {
+	commit, err := r.resolveCommit(ref)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
 // HasStagedChanges returns true if there are any staged changes in the repository.
 // This is useful for validating before attempting to create a commit.
 func (r *Repository) HasStagedChanges() (bool, error) {
@@ -359,3 +942,19 @@ func (r *Repository) HasStagedChanges() (bool, error) {
 
 	return false, nil
 }
+
+// FetchPullRequest fetches the head of pull request number from remote into
+// refs/remotes/<remote>/pr/<number> and returns that ref name.
+// This follows the refs/pull/<n>/head convention used by GitHub.
+func (r *Repository) FetchPullRequest(remote string, number int) (string, error) {
+	ref := fmt.Sprintf("refs/remotes/%s/pr/%d", remote, number)
+	refSpec := config.RefSpec(fmt.Sprintf("+refs/pull/%d/head:%s", number, ref))
+	err := r.repo.Fetch(&git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{refSpec},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "", fmt.Errorf("failed to fetch pull request %d from %s: %w", number, remote, err)
+	}
+	return ref, nil
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +9,8 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -256,6 +259,11 @@ func TestGetStagedDiff_UnifiedDiffFormat_NewFile(t *testing.T) {
 		t.Error("diff should indicate new file mode")
 	}
 
+	// The content is one hunk, as git writes it
+	if !strings.Contains(diff, "+++ b/new_file.go\n@@ -0,0 +1,5 @@\n") {
+		t.Errorf("diff should have a hunk header for the new file:\n%s", diff)
+	}
+
 	// All lines should be additions (start with +)
 	if !strings.Contains(diff, "+package main") {
 		t.Error("diff should show package line as addition")
@@ -266,6 +274,34 @@ func TestGetStagedDiff_UnifiedDiffFormat_NewFile(t *testing.T) {
 	}
 }
 
+func TestGetStagedDiff_UnifiedDiffFormat_PathWithSpacesAndUnicode(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	name := "docs/Design Überblick.md"
+	if err := os.MkdirAll(filepath.Join(tmpDir, "docs"), 0755); err != nil {
+		t.Fatalf("failed to create docs dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("# Design\n"), 0644); err != nil {
+		t.Fatalf("failed to write new file: %v", err)
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to stage new file: %v", err)
+	}
+
+	diff, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "diff --git a/"+name+" b/"+name+"\n") || !strings.Contains(diff, "+++ b/"+name+"\n") {
+		t.Errorf("diff should name the file unquoted, got:\n%s", diff)
+	}
+}
+
 func TestGetStagedDiff_UnifiedDiffFormat_ModifiedFile(t *testing.T) {
 	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
 	defer cleanup()
@@ -315,11 +351,10 @@ func TestGetStagedDiff_UnifiedDiffFormat_ModifiedFile(t *testing.T) {
 	}
 }
 
-// TestGetStagedDiff_UnifiedDiffFormat_DeletedFile documents the behavior for deleted files.
-// KNOWN LIMITATION: The current implementation iterates over idx.Entries which does not
-// include deleted files. Deleted files appear in git.Status with Staging==Deleted but
-// are not in the index entries, so GetStagedDiff returns ErrNoStagedChanges for
-// deletion-only changes. This test verifies and documents this behavior.
+// TestGetStagedDiff_UnifiedDiffFormat_DeletedFile checks that a deletion-only
+// stage produces a diff. Deleted files appear in git.Status with
+// Staging==Deleted but not in the index entries, so GetStagedDiff iterates over
+// the status rather than the index.
 func TestGetStagedDiff_UnifiedDiffFormat_DeletedFile(t *testing.T) {
 	repo, _, cleanup := setupTestRepoWithCommit(t)
 	defer cleanup()
@@ -357,8 +392,8 @@ func TestGetStagedDiff_UnifiedDiffFormat_DeletedFile(t *testing.T) {
 	if !strings.Contains(diff, "--- a/initial.txt") {
 		t.Error("diff should contain --- marker for deleted file")
 	}
-	if !strings.Contains(diff, "+++ /dev/null") {
-		t.Error("diff should contain +++ /dev/null marker for deleted file")
+	if !strings.Contains(diff, "+++ /dev/null\n@@ -1,1 +0,0 @@\n") {
+		t.Errorf("diff should contain +++ /dev/null and a hunk header for deleted file:\n%s", diff)
 	}
 	if !strings.Contains(diff, "-initial content") {
 		t.Error("diff should show deleted content as removal")
@@ -508,6 +543,47 @@ func TestGetStagedDiff_UnifiedDiffFormat_ContextLines(t *testing.T) {
 	}
 }
 
+func TestGetStagedDiff_SyntaxContext(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	body := []string{"package main", "", "func run() {"}
+	for i := 1; i <= 12; i++ {
+		body = append(body, fmt.Sprintf("\tstep(%d)", i))
+	}
+	body = append(body, "}", "", "func other() {}")
+	commitFile(t, repo, tmpDir, "run.go", strings.Join(body, "\n")+"\n")
+
+	body[9] = "\tstep(-7)"
+	if err := os.WriteFile(filepath.Join(tmpDir, "run.go"), []byte(strings.Join(body, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := repo.repo.Worktree()
+	if _, err := worktree.Add("run.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	narrow, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	if strings.Contains(narrow, " func run() {") {
+		t.Errorf("diff without syntax context shows the function header:\n%s", narrow)
+	}
+
+	repo.SetSyntaxContext(20)
+	wide, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	if !strings.Contains(wide, "@@ -3,14 +3,14 @@\n func run() {\n") || !strings.Contains(wide, "\tstep(12)\n }\n") {
+		t.Errorf("diff with syntax context should show the whole function:\n%s", wide)
+	}
+	if strings.Contains(wide, "other") {
+		t.Errorf("diff with syntax context should stop at the end of the function:\n%s", wide)
+	}
+}
+
 // =============================================================================
 // Tests for edge cases and error handling
 // =============================================================================
@@ -694,3 +770,505 @@ func TestCommit_CreatesCommit(t *testing.T) {
 		t.Error("expected no staged changes after commit")
 	}
 }
+
+// =============================================================================
+// Tests for Repository.GetWorkingTreeDiff()
+// =============================================================================
+
+func TestGetWorkingTreeDiff_ModifiedAndUntrackedFiles(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	// Modify the committed file without staging it
+	if err := os.WriteFile(filepath.Join(tmpDir, "initial.txt"), []byte("changed content\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	// Add a new file without staging it
+	if err := os.WriteFile(filepath.Join(tmpDir, "new.txt"), []byte("brand new\n"), 0644); err != nil {
+		t.Fatalf("failed to write new file: %v", err)
+	}
+
+	diff, err := repo.GetWorkingTreeDiff()
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiff() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"diff --git a/initial.txt b/initial.txt",
+		"-initial content",
+		"+changed content",
+		"diff --git a/new.txt b/new.txt",
+		"new file mode 100644",
+		"+brand new",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff should contain %q, got:\n%s", want, diff)
+		}
+	}
+}
+
+func TestGetWorkingTreeDiff_ComparesAgainstIndex(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	filePath := filepath.Join(tmpDir, "initial.txt")
+	if err := os.WriteFile(filePath, []byte("staged content\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add("initial.txt"); err != nil {
+		t.Fatalf("failed to stage file: %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte("unstaged content\n"), 0644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+
+	diff, err := repo.GetWorkingTreeDiff()
+	if err != nil {
+		t.Fatalf("GetWorkingTreeDiff() failed: %v", err)
+	}
+
+	// Only the unstaged edit is shown, relative to the staged content
+	if !strings.Contains(diff, "-staged content") || !strings.Contains(diff, "+unstaged content") {
+		t.Errorf("diff should compare the working tree against the index, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "initial content") {
+		t.Error("diff should not include committed content that is already replaced in the index")
+	}
+}
+
+func TestGetWorkingTreeDiff_NoChanges(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	_, err := repo.GetWorkingTreeDiff()
+	if err != ErrNoWorkingTreeChanges {
+		t.Errorf("expected ErrNoWorkingTreeChanges, got: %v", err)
+	}
+}
+
+// =============================================================================
+// Tests for Repository.GitDir()
+// =============================================================================
+
+func TestRepository_GitDir(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		t.Fatalf("GitDir() failed: %v", err)
+	}
+
+	want, _ := filepath.EvalSymlinks(filepath.Join(tmpDir, ".git"))
+	got, _ := filepath.EvalSymlinks(gitDir)
+	if got != want {
+		t.Errorf("GitDir() = %q, want %q", gitDir, filepath.Join(tmpDir, ".git"))
+	}
+}
+
+func TestRepository_HooksDir(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	dir, err := repo.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() failed: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(filepath.Join(tmpDir, ".git"))
+	if got, _ := filepath.EvalSymlinks(filepath.Dir(dir)); got != want || filepath.Base(dir) != "hooks" {
+		t.Errorf("HooksDir() = %q, want .git/hooks", dir)
+	}
+
+	// core.hooksPath is resolved against the worktree root
+	cfg, err := repo.repo.Config()
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	cfg.Raw.Section("core").SetOption("hooksPath", ".githooks")
+	if err := repo.repo.SetConfig(cfg); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	dir, err = repo.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() failed: %v", err)
+	}
+	if filepath.Base(dir) != ".githooks" || !filepath.IsAbs(dir) {
+		t.Errorf("HooksDir() = %q, want <root>/.githooks", dir)
+	}
+}
+
+// =============================================================================
+// Tests for GetDiffBetween, GetRangeDiff and GetCommitDiff
+// =============================================================================
+
+// commitFile writes content to name, stages it and commits it, returning the commit hash
+func commitFile(t *testing.T, repo *Repository, dir, name, content string) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to stage %s: %v", name, err)
+	}
+	hash, err := worktree.Commit("Update "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit %s: %v", name, err)
+	}
+	return hash.String()
+}
+
+func TestGetDiffBetween_ShowsChangesBetweenCommits(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	commitFile(t, repo, tmpDir, "initial.txt", "changed content\n")
+	commitFile(t, repo, tmpDir, "feature.txt", "feature work\n")
+
+	diff, err := repo.GetDiffBetween("HEAD~2", "HEAD")
+	if err != nil {
+		t.Fatalf("GetDiffBetween() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"diff --git a/initial.txt b/initial.txt",
+		"-initial content",
+		"+changed content",
+		"diff --git a/feature.txt b/feature.txt",
+		"+feature work",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff should contain %q, got:\n%s", want, diff)
+		}
+	}
+}
+
+func TestGetDiffBetween_NoChanges(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	if _, err := repo.GetDiffBetween("HEAD", "HEAD"); err != ErrNoChangesBetween {
+		t.Errorf("expected ErrNoChangesBetween, got: %v", err)
+	}
+}
+
+func TestGetDiffBetween_UnknownRevision(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	if _, err := repo.GetDiffBetween("does-not-exist", "HEAD"); err == nil {
+		t.Error("expected error for unknown revision")
+	}
+}
+
+func TestGetRangeDiff(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	commitFile(t, repo, tmpDir, "feature.txt", "feature work\n")
+
+	tests := []struct {
+		spec string
+		ok   bool
+	}{
+		{"HEAD~1..HEAD", true},
+		{"HEAD~1..", true},
+		{"HEAD~1...HEAD", true},
+		{"HEAD~1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			diff, err := repo.GetRangeDiff(tt.spec)
+			if !tt.ok {
+				if err == nil {
+					t.Error("expected error for invalid range")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRangeDiff(%q) failed: %v", tt.spec, err)
+			}
+			if !strings.Contains(diff, "+feature work") {
+				t.Errorf("diff should contain the feature change, got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetCommitDiff(t *testing.T) {
+	repo, tmpDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	sha := commitFile(t, repo, tmpDir, "feature.txt", "feature work\n")
+	commitFile(t, repo, tmpDir, "later.txt", "later work\n")
+
+	diff, err := repo.GetCommitDiff(sha)
+	if err != nil {
+		t.Fatalf("GetCommitDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "+feature work") {
+		t.Errorf("diff should contain the commit's change, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "later work") {
+		t.Error("diff should not contain changes from later commits")
+	}
+
+	// The root commit is compared with an empty tree
+	root, err := repo.GetCommitDiff("HEAD~2")
+	if err != nil {
+		t.Fatalf("GetCommitDiff(root) failed: %v", err)
+	}
+	if !strings.Contains(root, "+initial content") {
+		t.Errorf("root commit diff should add its files, got:\n%s", root)
+	}
+}
+
+func TestFetchPullRequest(t *testing.T) {
+	upstream, upstreamDir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	cloneDir := t.TempDir()
+	clone, err := git.PlainClone(cloneDir, false, &git.CloneOptions{URL: upstreamDir})
+	if err != nil {
+		t.Fatalf("failed to clone: %v", err)
+	}
+
+	// Commit upstream after cloning and publish it as a pull request head
+	sha := commitFile(t, upstream, upstreamDir, "feature.txt", "pull request work\n")
+	head := plumbing.NewHashReference("refs/pull/7/head", plumbing.NewHash(sha))
+	if err := upstream.repo.Storer.SetReference(head); err != nil {
+		t.Fatalf("failed to create pull request ref: %v", err)
+	}
+
+	repo := &Repository{repo: clone}
+	ref, err := repo.FetchPullRequest("origin", 7)
+	if err != nil {
+		t.Fatalf("FetchPullRequest() failed: %v", err)
+	}
+	if ref != "refs/remotes/origin/pr/7" {
+		t.Errorf("ref = %q, want refs/remotes/origin/pr/7", ref)
+	}
+
+	diff, err := repo.GetRangeDiff("HEAD..." + ref)
+	if err != nil {
+		t.Fatalf("GetRangeDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "+pull request work") {
+		t.Errorf("diff should contain the pull request change, got:\n%s", diff)
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	repo, _, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if _, err := repo.RemoteURL("origin"); err == nil {
+		t.Error("expected an error for a missing remote")
+	}
+
+	_, err := repo.repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:buker/revi.git"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create remote: %v", err)
+	}
+	url, err := repo.RemoteURL("origin")
+	if err != nil {
+		t.Fatalf("RemoteURL() failed: %v", err)
+	}
+	if url != "git@github.com:buker/revi.git" {
+		t.Errorf("RemoteURL() = %q, want git@github.com:buker/revi.git", url)
+	}
+}
+
+func TestHeadCommit(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	sha := commitFile(t, repo, dir, "next.txt", "next\n")
+	head, err := repo.HeadCommit()
+	if err != nil {
+		t.Fatalf("HeadCommit() failed: %v", err)
+	}
+	if head != sha {
+		t.Errorf("HeadCommit() = %q, want %q", head, sha)
+	}
+}
+
+func TestCurrentBranch(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	sha := commitFile(t, repo, dir, "next.txt", "next\n")
+	branch := plumbing.NewBranchReferenceName("release/1.2")
+	if err := repo.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.CurrentBranch(); err != nil || got != "release/1.2" {
+		t.Errorf("CurrentBranch() = %q, %v, want release/1.2", got, err)
+	}
+
+	if err := repo.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, plumbing.NewHash(sha))); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.CurrentBranch(); err != nil || got != "" {
+		t.Errorf("CurrentBranch() = %q, %v, want no branch with a detached HEAD", got, err)
+	}
+}
+
+func TestCommitHash(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	sha := commitFile(t, repo, dir, "next.txt", "next\n")
+	commitFile(t, repo, dir, "later.txt", "later\n")
+
+	got, err := repo.CommitHash("HEAD~1")
+	if err != nil {
+		t.Fatalf("CommitHash() failed: %v", err)
+	}
+	if got != sha {
+		t.Errorf("CommitHash(HEAD~1) = %q, want %q", got, sha)
+	}
+	if _, err := repo.CommitHash("no-such-ref"); err == nil {
+		t.Error("CommitHash() should fail for an unknown revision")
+	}
+}
+
+func TestStageFiles(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(dir, "initial.txt"), []byte("fixed content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.StageFiles([]string{filepath.Join(dir, "initial.txt")}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+
+	files, err := repo.GetStagedFiles()
+	if err != nil {
+		t.Fatalf("GetStagedFiles() failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != "initial.txt" {
+		t.Errorf("staged files = %v, want [initial.txt]", files)
+	}
+
+	if err := repo.StageFiles([]string{"/elsewhere/file.txt"}); err == nil {
+		t.Error("expected an error for a path outside the repository")
+	}
+}
+
+func TestAmendHead(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	original := commitFile(t, repo, dir, "feature.txt", "feature\n")
+	subject, err := repo.HeadSubject()
+	if err != nil {
+		t.Fatalf("HeadSubject() failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature, fixed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StageFiles([]string{"feature.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+
+	amended, err := repo.AmendHead()
+	if err != nil {
+		t.Fatalf("AmendHead() failed: %v", err)
+	}
+	if amended == original {
+		t.Fatal("AmendHead() should create a new commit")
+	}
+
+	commit, err := repo.resolveCommit("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, _ := strings.Cut(commit.Message, "\n"); got != subject {
+		t.Errorf("amended subject = %q, want %q", got, subject)
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.Hash.String() == original {
+		t.Error("amended commit should replace the original, not follow it")
+	}
+	if staged, _ := repo.HasStagedChanges(); staged {
+		t.Error("no changes should remain staged after amending")
+	}
+}
+
+func TestGetAmendDiff(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	commitFile(t, repo, dir, "feature.txt", "feature\n")
+	diff, err := repo.GetAmendDiff()
+	if err != nil {
+		t.Fatalf("GetAmendDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "+feature") || strings.Contains(diff, "initial content") {
+		t.Errorf("diff should hold only the last commit's changes, got:\n%s", diff)
+	}
+
+	// Changes staged since are included, and compared with HEAD's parent
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature, fixed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "initial.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StageFiles([]string{"feature.txt", "initial.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+	diff, err = repo.GetAmendDiff()
+	if err != nil {
+		t.Fatalf("GetAmendDiff() failed: %v", err)
+	}
+	for _, want := range []string{"new file mode", "+feature, fixed", "deleted file mode", "-initial content"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff should contain %q, got:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "-feature\n") {
+		t.Errorf("diff should not show the version of HEAD, got:\n%s", diff)
+	}
+}
+
+func TestAmendHeadMessage(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	commitFile(t, repo, dir, "feature.txt", "feature\n")
+
+	if _, err := repo.AmendHeadMessage("feat: add feature"); err != nil {
+		t.Fatalf("AmendHeadMessage() failed: %v", err)
+	}
+	if subject, _ := repo.HeadSubject(); subject != "feat: add feature" {
+		t.Errorf("amended subject = %q, want the new message", subject)
+	}
+	if _, err := repo.GetAmendDiff(); err != nil {
+		t.Errorf("GetAmendDiff() after amending failed: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxIncludeDepth is how deeply config files may include each other, as in git
+const maxIncludeDepth = 10
+
+// gitConfig is the git configuration of a repository as the git CLI sees it:
+// the config files that apply, in order of precedence. go-git only reads the
+// repository's own file and ignores includes, so commits made through it
+// would miss identities and settings kept in the global config or in files
+// included conditionally, e.g. a work identity for repositories under ~/work.
+type gitConfig []*config.Config
+
+// loadGitConfig reads the repository's config file, the global files
+// (~/.gitconfig and $XDG_CONFIG_HOME/git/config, or $GIT_CONFIG_GLOBAL) and
+// the system file (/etc/gitconfig or $GIT_CONFIG_SYSTEM, unless
+// $GIT_CONFIG_NOSYSTEM is set), following include.path and includeIf.*.path
+// settings. Files that are missing or cannot be parsed are left out.
+func (r *Repository) loadGitConfig() gitConfig {
+	loader := configLoader{}
+	if gitDir, err := r.GitDir(); err == nil {
+		loader.gitDir = gitDir
+	}
+	if head, err := r.repo.Head(); err == nil && head.Name().IsBranch() {
+		loader.branch = head.Name().Short()
+	}
+
+	var cfg gitConfig
+	if loader.gitDir != "" {
+		cfg = append(cfg, loader.read(filepath.Join(loader.gitDir, "config"), 0)...)
+	}
+	for _, path := range globalConfigPaths() {
+		cfg = append(cfg, loader.read(path, 0)...)
+	}
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		cfg = append(cfg, loader.read(firstNonEmpty(os.Getenv("GIT_CONFIG_SYSTEM"), "/etc/gitconfig"), 0)...)
+	}
+	return cfg
+}
+
+// globalConfigPaths returns the global config files, highest precedence first
+func globalConfigPaths() []string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return []string{path}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	xdg := firstNonEmpty(os.Getenv("XDG_CONFIG_HOME"), filepath.Join(home, ".config"))
+	return []string{filepath.Join(home, ".gitconfig"), filepath.Join(xdg, "git", "config")}
+}
+
+// configLoader reads config files and the files they include
+type configLoader struct {
+	gitDir string // Matched by includeIf "gitdir:" conditions
+	branch string // Matched by includeIf "onbranch:" conditions
+}
+
+// read parses the config file at path and returns it after the files it
+// includes, which take precedence over it
+func (l configLoader) read(path string, depth int) []*config.Config {
+	if depth > maxIncludeDepth {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	cfg := config.New()
+	if err := config.NewDecoder(f).Decode(cfg); err != nil {
+		return nil
+	}
+
+	var includes []string
+	for _, s := range cfg.Sections {
+		switch {
+		case s.IsName("include"):
+			includes = append(includes, s.Options.GetAll("path")...)
+		case s.IsName("includeIf"):
+			for _, sub := range s.Subsections {
+				if l.matches(sub.Name, filepath.Dir(path)) {
+					includes = append(includes, sub.Options.GetAll("path")...)
+				}
+			}
+		}
+	}
+
+	// Later includes override earlier ones
+	var files []*config.Config
+	for i := len(includes) - 1; i >= 0; i-- {
+		include := expandHome(includes[i])
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		files = append(files, l.read(include, depth+1)...)
+	}
+	return append(files, cfg)
+}
+
+// matches reports whether an includeIf condition holds. dir is the directory
+// of the file holding the condition. Conditions other than gitdir, gitdir/i
+// and onbranch never hold.
+func (l configLoader) matches(condition, dir string) bool {
+	kind, pattern, ok := strings.Cut(condition, ":")
+	if !ok {
+		return false
+	}
+	switch kind {
+	case "gitdir", "gitdir/i":
+		if l.gitDir == "" {
+			return false
+		}
+		if rest, ok := strings.CutPrefix(pattern, "./"); ok {
+			pattern = filepath.ToSlash(dir) + "/" + rest
+		}
+		if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				pattern = filepath.ToSlash(home) + "/" + rest
+			}
+		}
+		if !strings.HasPrefix(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		gitDir := filepath.ToSlash(l.gitDir)
+		if real, err := filepath.EvalSymlinks(l.gitDir); err == nil {
+			real = filepath.ToSlash(real)
+			return wildmatch(pattern, gitDir, kind == "gitdir/i") || wildmatch(pattern, real, kind == "gitdir/i")
+		}
+		return wildmatch(pattern, gitDir, kind == "gitdir/i")
+	case "onbranch":
+		if l.branch == "" {
+			return false
+		}
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return wildmatch(pattern, l.branch, false)
+	}
+	return false
+}
+
+// wildmatch reports whether name matches the glob pattern as git matches
+// paths: "*" and "?" do not match "/", while "**" matches across directories
+func wildmatch(pattern, name string, foldCase bool) bool {
+	var b strings.Builder
+	if foldCase {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	return err == nil && re.MatchString(name)
+}
+
+// get returns the value of key in section, or in section's subsection if
+// one is given, from the config file that sets it with the highest precedence
+func (c gitConfig) get(section, subsection, key string) string {
+	for _, cfg := range c {
+		if !cfg.HasSection(section) {
+			continue
+		}
+		s := cfg.Section(section)
+		if subsection == "" {
+			if s.HasOption(key) {
+				return s.Option(key)
+			}
+			continue
+		}
+		if s.HasSubsection(subsection) && s.Subsection(subsection).HasOption(key) {
+			return s.Subsection(subsection).Option(key)
+		}
+	}
+	return ""
+}
+
+// getBool returns the value of a boolean key, reading it as git does
+func (c gitConfig) getBool(section, subsection, key string) bool {
+	switch strings.ToLower(c.get(section, subsection, key)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+// signature returns the identity git would record for role ("author" or
+// "committer"): the GIT_<ROLE>_NAME and GIT_<ROLE>_EMAIL environment
+// variables, then the <role>.name and <role>.email settings, then user.name
+// and user.email, and finally revi's own defaults.
+func (c gitConfig) signature(role string) *object.Signature {
+	env := "GIT_" + strings.ToUpper(role) + "_"
+	name := firstNonEmpty(os.Getenv(env+"NAME"), c.get(role, "", "name"), c.get("user", "", "name"), "revi")
+	email := firstNonEmpty(os.Getenv(env+"EMAIL"), c.get(role, "", "email"), c.get("user", "", "email"), "revi@localhost")
+	return &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// expandHome replaces a leading "~/" in path with the home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
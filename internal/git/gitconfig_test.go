@@ -0,0 +1,131 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolateGitConfig keeps the user's global git config and identity out of
+// the test. Returns the temporary home directory.
+func isolateGitConfig(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(v, "")
+	}
+	return home
+}
+
+// appendGitConfig adds lines to the repository's .git/config
+func appendGitConfig(t *testing.T, dir, lines string) {
+	t.Helper()
+	f, err := os.OpenFile(filepath.Join(dir, ".git", "config"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open git config: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(lines); err != nil {
+		t.Fatalf("failed to write git config: %v", err)
+	}
+}
+
+// writeFile writes content to path, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoadGitConfig_GlobalAndLocal(t *testing.T) {
+	home := isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[user]\n\tname = Global Name\n\temail = global@example.com\n")
+	writeFile(t, filepath.Join(home, ".config", "git", "config"), "[user]\n\tname = XDG Name\n[core]\n\thooksPath = ~/hooks\n")
+	appendGitConfig(t, dir, "[user]\n\temail = local@example.com\n")
+
+	cfg := repo.loadGitConfig()
+	sig := cfg.signature("author")
+	if sig.Name != "Global Name" || sig.Email != "local@example.com" {
+		t.Errorf("signature = %s <%s>, want Global Name <local@example.com>", sig.Name, sig.Email)
+	}
+
+	hooksDir, err := repo.HooksDir()
+	if err != nil {
+		t.Fatalf("HooksDir() failed: %v", err)
+	}
+	if want := filepath.Join(home, "hooks"); hooksDir != want {
+		t.Errorf("HooksDir() = %q, want the global core.hooksPath %q", hooksDir, want)
+	}
+}
+
+func TestLoadGitConfig_Includes(t *testing.T) {
+	home := isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	writeFile(t, filepath.Join(home, "base.inc"), "[user]\n\tname = Included Name\n\temail = personal@example.com\n")
+	writeFile(t, filepath.Join(home, "work.inc"), "[user]\n\temail = work@example.com\n")
+	writeFile(t, filepath.Join(home, "other.inc"), "[user]\n\temail = other@example.com\n")
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[include]\n\tpath = base.inc\n"+
+		"[includeIf \"gitdir:"+filepath.ToSlash(dir)+"/\"]\n\tpath = ~/work.inc\n"+
+		"[includeIf \"gitdir:/nowhere/\"]\n\tpath = other.inc\n")
+
+	sig := repo.loadGitConfig().signature("author")
+	if sig.Name != "Included Name" || sig.Email != "work@example.com" {
+		t.Errorf("signature = %s <%s>, want Included Name <work@example.com>", sig.Name, sig.Email)
+	}
+}
+
+func TestLoadGitConfig_IncludeCycle(t *testing.T) {
+	home := isolateGitConfig(t)
+	repo, _, cleanup := setupTestRepo(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[include]\n\tpath = ~/.gitconfig\n[user]\n\tname = Loop\n")
+
+	if got := repo.loadGitConfig().get("user", "", "name"); got != "Loop" {
+		t.Errorf("user.name = %q, want Loop", got)
+	}
+}
+
+func TestConfigLoader_Matches(t *testing.T) {
+	home := isolateGitConfig(t)
+	l := configLoader{gitDir: "/home/jo/work/api/.git", branch: "feature/login"}
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{"gitdir:/home/jo/work/", true},
+		{"gitdir:/home/jo/personal/", false},
+		{"gitdir:work/api/.git", true},
+		{"gitdir:api/", true},
+		{"gitdir:/HOME/jo/work/", false},
+		{"gitdir/i:/HOME/jo/work/", true},
+		{"gitdir:/home/jo/*/api/.git", true},
+		{"gitdir:/home/*/api/.git", false},
+		{"onbranch:feature/", true},
+		{"onbranch:feature/login", true},
+		{"onbranch:main", false},
+		{"hasconfig:remote.*.url:https://example.com/**", false},
+		{"nonsense", false},
+	}
+	for _, tt := range tests {
+		if got := l.matches(tt.condition, home); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+
+	l.gitDir = filepath.Join(home, "src", "app", ".git")
+	if !l.matches("gitdir:~/src/", home) || !l.matches("gitdir:./src/", home) {
+		t.Error("gitdir patterns starting with ~/ or ./ should be expanded")
+	}
+}
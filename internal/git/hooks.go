@@ -0,0 +1,112 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/buker/revi/internal/hook"
+)
+
+// Hooks run around the commits revi creates, as "git commit" runs them
+const (
+	preCommitHook  = "pre-commit"
+	commitMsgHook  = "commit-msg"
+	postCommitHook = "post-commit"
+)
+
+// commitMsgFile is the file in the git directory that holds the message
+// given to the commit-msg hook
+const commitMsgFile = "COMMIT_EDITMSG"
+
+// SetSkipHooks makes Commit and AmendHead skip the pre-commit and commit-msg
+// hooks, as "git commit --no-verify" does.
+func (r *Repository) SetSkipHooks(skip bool) {
+	r.skipHooks = skip
+}
+
+// runCommitHooks runs the pre-commit hook, then the commit-msg hook on
+// message, unless hooks are skipped. Returns the message as the commit-msg
+// hook left it. A failing hook aborts the commit.
+func (r *Repository) runCommitHooks(message string) (string, error) {
+	if r.skipHooks {
+		return message, nil
+	}
+	if path, ok := r.hookPath(preCommitHook); ok {
+		if err := r.runHook(path); err != nil {
+			return "", fmt.Errorf("%s hook failed: %w", preCommitHook, err)
+		}
+	}
+
+	path, ok := r.hookPath(commitMsgHook)
+	if !ok {
+		return message, nil
+	}
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return "", err
+	}
+	msgFile := filepath.Join(gitDir, commitMsgFile)
+	if err := os.WriteFile(msgFile, []byte(message), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", commitMsgFile, err)
+	}
+	if err := r.runHook(path, msgFile); err != nil {
+		return "", fmt.Errorf("%s hook failed: %w", commitMsgHook, err)
+	}
+	edited, err := os.ReadFile(msgFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", commitMsgFile, err)
+	}
+	return string(edited), nil
+}
+
+// runPostCommitHook runs the post-commit hook. As with git, its failure does
+// not affect the commit that was made, and --no-verify does not skip it.
+func (r *Repository) runPostCommitHook() {
+	if path, ok := r.hookPath(postCommitHook); ok {
+		_ = r.runHook(path)
+	}
+}
+
+// hookPath returns the path of the named hook if git would run it: it exists
+// and is executable. Hooks installed by revi are left out, since the commits
+// revi creates were already reviewed and given a generated message.
+func (r *Repository) hookPath(name string) (string, bool) {
+	dir, err := r.HooksDir()
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return "", false
+	}
+	if ours, err := hook.IsRevi(path); err != nil || ours {
+		return "", false
+	}
+	return path, true
+}
+
+// runHook runs the hook at path with args from the worktree root, with its
+// output sent to stderr as git does
+func (r *Repository) runHook(path string, args ...string) error {
+	root, err := r.Root()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if gitDir, err := r.GitDir(); err == nil {
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(gitDir, "index"))
+	}
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("exited with status %d", exitErr.ExitCode())
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/hook"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// writeHook installs an executable hook script in the repository at dir
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	path := filepath.Join(dir, ".git", "hooks", name)
+	writeFile(t, path, "#!/bin/sh\n"+script)
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatalf("failed to make %s executable: %v", name, err)
+	}
+}
+
+// stageChange stages a new file in the repository at dir
+func stageChange(t *testing.T, repo *Repository, dir string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "change.txt"), "change\n")
+	if err := repo.StageFiles([]string{"change.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+}
+
+func TestCommit_PreCommitHookAborts(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	writeHook(t, dir, "pre-commit", "exit 1\n")
+	stageChange(t, repo, dir)
+
+	before, _ := repo.CommitHash("HEAD")
+	_, err := repo.Commit("Add change")
+	if err == nil || !strings.Contains(err.Error(), "pre-commit hook failed") {
+		t.Fatalf("Commit() error = %v, want a pre-commit hook failure", err)
+	}
+	if after, _ := repo.CommitHash("HEAD"); after != before {
+		t.Error("a failing pre-commit hook should leave HEAD unchanged")
+	}
+
+	repo.SetSkipHooks(true)
+	if _, err := repo.Commit("Add change"); err != nil {
+		t.Errorf("Commit() with hooks skipped failed: %v", err)
+	}
+}
+
+func TestCommit_CommitMsgHookEditsMessage(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	writeHook(t, dir, "commit-msg", "printf '\\nRefs: #42\\n' >> \"$1\"\n")
+	writeHook(t, dir, "post-commit", "touch post-commit-ran\nexit 1\n")
+	stageChange(t, repo, dir)
+
+	hash, err := repo.Commit("feat: add change")
+	if err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	commit, err := repo.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if commit.Message != "feat: add change\nRefs: #42\n" {
+		t.Errorf("message = %q, want the hook's trailer appended", commit.Message)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post-commit-ran")); err != nil {
+		t.Error("post-commit hook should run from the worktree root")
+	}
+}
+
+func TestCommit_SkipsReviHooks(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	// revi's own hook would review the changes again
+	writeHook(t, dir, "pre-commit", hook.Marker+"\nexit 1\n")
+	// Hooks that are not executable are not run, as with git
+	writeFile(t, filepath.Join(dir, ".git", "hooks", "commit-msg"), "#!/bin/sh\nexit 1\n")
+	stageChange(t, repo, dir)
+
+	if _, err := repo.Commit("Add change"); err != nil {
+		t.Errorf("Commit() failed: %v", err)
+	}
+}
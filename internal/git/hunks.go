@@ -0,0 +1,288 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Hunk is a run of changed lines of a tracked file, between its version in
+// HEAD and the one in the working tree, that can be staged on its own.
+type Hunk struct {
+	// Path is the repository-relative path of the file
+	Path string
+	// OldStart and NewStart are the 1-based lines where the hunk starts in
+	// HEAD's version and the working tree's; for a hunk only adding lines,
+	// OldStart is the line they are added after
+	OldStart, NewStart int
+	// Removed holds the lines of HEAD's version the hunk replaces, and Added
+	// the lines put in their place
+	Removed, Added []string
+	// Staged tells whether the index has the hunk's change. Setting it and
+	// calling StageHunks stages or unstages the hunk.
+	Staged bool
+
+	// offset is the index of the hunk's first line in HEAD's version, and
+	// removed and added the hunk's lines as they are in the file
+	offset         int
+	removed, added string
+	file           *hunkFile
+}
+
+// hunkFile is what StageHunks needs to know of the file a hunk belongs to
+type hunkFile struct {
+	base    string // Content in HEAD, empty if it is not in HEAD
+	inHead  bool
+	deleted bool // Removed from the working tree
+	mode    filemode.FileMode
+}
+
+// Header returns the hunk's "@@ -a,b +c,d @@" line.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, len(h.Removed), h.NewStart, len(h.Added))
+}
+
+// GetHunks returns the hunks of the changes to tracked files, staged or not,
+// sorted by path and position. Untracked and binary files are left out.
+// A hunk counts as staged when the index has exactly its change; staged
+// changes the working tree no longer has are not listed, and StageHunks
+// replaces them.
+func (r *Repository) GetHunks() ([]Hunk, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	head, err := r.headFiles()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index: %w", err)
+	}
+
+	var paths []string
+	for path, s := range status {
+		if s.Worktree == git.Untracked || (s.Staging == git.Unmodified && s.Worktree == git.Unmodified) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var hunks []Hunk
+	for _, path := range paths {
+		file := &hunkFile{mode: filemode.Regular}
+		if f, ok := head[path]; ok {
+			if file.base, err = f.Contents(); err != nil {
+				return nil, fmt.Errorf("failed to read %s from HEAD: %w", path, err)
+			}
+			file.inHead, file.mode = true, f.Mode
+		}
+		staged := ""
+		if entry, err := idx.Entry(path); err == nil {
+			if staged, err = r.getIndexFileContent(entry.Hash); err != nil {
+				return nil, fmt.Errorf("failed to read %s from the index: %w", path, err)
+			}
+			file.mode = entry.Mode
+		}
+		work, err := r.getWorktreeFileContent(path)
+		if os.IsNotExist(err) {
+			file.deleted = true
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if isBinary(file.base) || isBinary(staged) || isBinary(work) {
+			continue
+		}
+
+		stagedHunks := lineHunks(file.base, staged)
+		for _, h := range lineHunks(file.base, work) {
+			h.Path, h.file = path, file
+			h.Staged = slices.ContainsFunc(stagedHunks, func(s Hunk) bool {
+				return s.offset == h.offset && s.removed == h.removed && s.added == h.added
+			})
+			hunks = append(hunks, h)
+		}
+	}
+	return hunks, nil
+}
+
+// StageHunks rewrites the index entries of the files of hunks, as returned by
+// GetHunks, so that they hold HEAD's version with the changes of the hunks
+// marked Staged. A new file none of whose hunks are staged is unstaged, and a
+// deleted file all of whose hunks are staged is staged as deleted.
+func (r *Repository) StageHunks(hunks []Hunk) error {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to get index: %w", err)
+	}
+
+	byFile := make(map[*hunkFile][]Hunk)
+	var files []*hunkFile
+	paths := make(map[*hunkFile]string)
+	for _, h := range hunks {
+		if h.file == nil {
+			return fmt.Errorf("hunk of %s was not returned by GetHunks", h.Path)
+		}
+		if _, ok := byFile[h.file]; !ok {
+			files = append(files, h.file)
+			paths[h.file] = h.Path
+		}
+		byFile[h.file] = append(byFile[h.file], h)
+	}
+
+	for _, file := range files {
+		path := paths[file]
+		var selected []Hunk
+		for _, h := range byFile[file] {
+			if h.Staged {
+				selected = append(selected, h)
+			}
+		}
+		if (file.deleted && len(selected) == len(byFile[file])) || (!file.inHead && len(selected) == 0) {
+			if _, err := idx.Remove(path); err != nil && err != index.ErrEntryNotFound {
+				return fmt.Errorf("failed to unstage %s: %w", path, err)
+			}
+			continue
+		}
+
+		content := applyHunks(file.base, selected)
+		hash, err := r.storeBlob(content)
+		if err != nil {
+			return fmt.Errorf("failed to store %s: %w", path, err)
+		}
+		entry, err := idx.Entry(path)
+		if err != nil {
+			entry = idx.Add(path)
+		}
+		entry.Hash, entry.Mode, entry.Size = hash, file.mode, uint32(len(content))
+		// The stat data describes the file the old entry was staged from. As
+		// with git apply --cached, it is cleared so that git hashes the file
+		// again rather than trust it matches the new blob of the same size.
+		entry.CreatedAt, entry.ModifiedAt = time.Time{}, time.Time{}
+		entry.Dev, entry.Inode, entry.UID, entry.GID = 0, 0, 0, 0
+	}
+
+	// The cached trees would describe the old entries
+	idx.Cache = nil
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		if idx.Entries[i].Name != idx.Entries[j].Name {
+			return idx.Entries[i].Name < idx.Entries[j].Name
+		}
+		return idx.Entries[i].Stage < idx.Entries[j].Stage
+	})
+	if err := r.repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// lineHunks compares two versions of a file line by line and returns each run
+// of changed lines as a hunk without context
+func lineHunks(before, after string) []Hunk {
+	var hunks []Hunk
+	inHunk := false
+	oldPos, newPos := 0, 0
+	for _, d := range gitdiff.Do(before, after) {
+		n := countLines(d.Text)
+		if d.Type == diffmatchpatch.DiffEqual {
+			oldPos += n
+			newPos += n
+			inHunk = false
+			continue
+		}
+		if !inHunk {
+			hunks = append(hunks, Hunk{offset: oldPos, OldStart: oldPos, NewStart: newPos})
+			inHunk = true
+		}
+		h := &hunks[len(hunks)-1]
+		if d.Type == diffmatchpatch.DiffDelete {
+			h.removed += d.Text
+			h.Removed = append(h.Removed, splitLines(d.Text)...)
+			oldPos += n
+		} else {
+			h.added += d.Text
+			h.Added = append(h.Added, splitLines(d.Text)...)
+			newPos += n
+		}
+	}
+	// Hunks replacing lines start at their first line; those only adding
+	// lines start at the line before them, as in unified diffs
+	for i := range hunks {
+		if len(hunks[i].Removed) > 0 {
+			hunks[i].OldStart++
+		}
+		if len(hunks[i].Added) > 0 {
+			hunks[i].NewStart++
+		}
+	}
+	return hunks
+}
+
+// applyHunks returns base with the changes of hunks, which must come from
+// the same comparison with base, made
+func applyHunks(base string, hunks []Hunk) string {
+	lines := strings.SplitAfter(base, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var b strings.Builder
+	pos := 0
+	for _, h := range hunks {
+		b.WriteString(strings.Join(lines[pos:h.offset], ""))
+		b.WriteString(h.added)
+		pos = h.offset + countLines(h.removed)
+	}
+	b.WriteString(strings.Join(lines[pos:], ""))
+	return b.String()
+}
+
+// countLines returns the number of lines of text, counting a last line
+// without a newline
+func countLines(text string) int {
+	n := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
+
+// splitLines splits text into lines without their newlines
+func splitLines(text string) []string {
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// storeBlob writes content to the object database as a blob and returns its
+// hash
+func (r *Repository) storeBlob(content string) (plumbing.Hash, error) {
+	obj := r.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.repo.Storer.SetEncodedObject(obj)
+}
@@ -0,0 +1,164 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stagedContent returns the content of path in the index, or "" if the index
+// has no entry for it
+func stagedContent(t *testing.T, repo *Repository, path string) (string, bool) {
+	t.Helper()
+	idx, err := repo.repo.Storer.Index()
+	if err != nil {
+		t.Fatalf("failed to get index: %v", err)
+	}
+	entry, err := idx.Entry(path)
+	if err != nil {
+		return "", false
+	}
+	content, err := repo.getIndexFileContent(entry.Hash)
+	if err != nil {
+		t.Fatalf("failed to read %s from the index: %v", path, err)
+	}
+	return content, true
+}
+
+func TestLineHunks(t *testing.T) {
+	hunks := lineHunks("a\nb\nc\nd\n", "a\nB\nc\nd\ne\n")
+	if len(hunks) != 2 {
+		t.Fatalf("lineHunks() = %+v, want 2 hunks", hunks)
+	}
+	if got := hunks[0].Header(); got != "@@ -2,1 +2,1 @@" {
+		t.Errorf("first hunk header = %q", got)
+	}
+	if got := hunks[1].Header(); got != "@@ -4,0 +5,1 @@" || !slices.Equal(hunks[1].Added, []string{"e"}) {
+		t.Errorf("second hunk = %s %q", got, hunks[1].Added)
+	}
+
+	base := "a\nb\nc\nd\n"
+	if got := applyHunks(base, hunks[1:]); got != "a\nb\nc\nd\ne\n" {
+		t.Errorf("applyHunks(second) = %q", got)
+	}
+	if got := applyHunks(base, hunks); got != "a\nB\nc\nd\ne\n" {
+		t.Errorf("applyHunks(all) = %q", got)
+	}
+	if got := applyHunks("x", lineHunks("x", "x\ny")); got != "x\ny" {
+		t.Errorf("applyHunks() without a final newline = %q", got)
+	}
+}
+
+func TestStageHunks_StagesChosenHunks(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	commitFile(t, repo, dir, "main.go", "one\ntwo\nthree\nfour\nfive\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "ONE\ntwo\nthree\nfour\nFIVE\n")
+
+	hunks, err := repo.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks() failed: %v", err)
+	}
+	if len(hunks) != 2 || hunks[0].Staged || hunks[1].Staged {
+		t.Fatalf("GetHunks() = %+v, want 2 unstaged hunks", hunks)
+	}
+
+	hunks[1].Staged = true
+	if err := repo.StageHunks(hunks); err != nil {
+		t.Fatalf("StageHunks() failed: %v", err)
+	}
+	if content, _ := stagedContent(t, repo, "main.go"); content != "one\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("staged content = %q, want only the second hunk", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "main.go")); !strings.HasPrefix(string(content), "ONE") {
+		t.Errorf("working tree changed: %q", content)
+	}
+
+	hunks, err = repo.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks() failed: %v", err)
+	}
+	if len(hunks) != 2 || hunks[0].Staged || !hunks[1].Staged {
+		t.Errorf("GetHunks() after staging = %+v, want only the second staged", hunks)
+	}
+	staged, err := repo.GetStagedDiff()
+	if err != nil || !strings.Contains(staged, "+FIVE") || strings.Contains(staged, "+ONE") {
+		t.Errorf("GetStagedDiff() = %q, %v, want the second hunk only", staged, err)
+	}
+}
+
+func TestStageHunks_NewAndDeletedFiles(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(dir, "new.go"), "package main\n")
+	if err := repo.StageFiles([]string{"new.go"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "initial.txt")); err != nil {
+		t.Fatalf("failed to remove initial.txt: %v", err)
+	}
+
+	hunks, err := repo.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks() failed: %v", err)
+	}
+	var paths []string
+	for i, h := range hunks {
+		paths = append(paths, h.Path)
+		// Unstage the new file and stage the deletion
+		hunks[i].Staged = h.Path == "initial.txt"
+	}
+	if !slices.Equal(paths, []string{"initial.txt", "new.go"}) {
+		t.Fatalf("GetHunks() paths = %q", paths)
+	}
+	if err := repo.StageHunks(hunks); err != nil {
+		t.Fatalf("StageHunks() failed: %v", err)
+	}
+	if _, ok := stagedContent(t, repo, "new.go"); ok {
+		t.Error("new.go should no longer be staged")
+	}
+	if _, ok := stagedContent(t, repo, "initial.txt"); ok {
+		t.Error("initial.txt should be staged as deleted")
+	}
+}
+
+func TestStageHunks_GitSeesUnstagedChange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	commitFile(t, repo, dir, "main.go", "one\ntwo\n")
+	writeFile(t, filepath.Join(dir, "main.go"), "ONE\ntwo\n")
+	// A file older than the index is one git trusts the stat data of
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "main.go"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", dir, "add", "main.go").CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v\n%s", err, out)
+	}
+
+	// Unstaging the change leaves an entry of the same size as the file, so
+	// git finds the change only if the entry's stat data no longer matches
+	hunks, err := repo.GetHunks()
+	if err != nil || len(hunks) != 1 {
+		t.Fatalf("GetHunks() = %+v, %v, want 1 hunk", hunks, err)
+	}
+	hunks[0].Staged = false
+	if err := repo.StageHunks(hunks); err != nil {
+		t.Fatalf("StageHunks() failed: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "diff").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git diff failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "-one\n+ONE\n") {
+		t.Errorf("git diff = %q, want the unstaged change", out)
+	}
+}
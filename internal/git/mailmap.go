@@ -0,0 +1,202 @@
+package git
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Mailmap maps the names and emails authors used in commits to their
+// canonical ones, as git's .mailmap does, so that a contributor who committed
+// from several machines or addresses is reported the same way everywhere.
+type Mailmap struct {
+	entries []mailmapEntry
+}
+
+// mailmapEntry is one line of a mailmap file
+type mailmapEntry struct {
+	name, email             string // Canonical identity; either may be empty
+	commitName, commitEmail string // Identity it replaces; commitName may be empty
+}
+
+// ParseMailmap reads entries in git's mailmap format:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+//
+// Comments start with "#". Lines that do not match a form are skipped.
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	m := &Mailmap{}
+	if err := m.read(r); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// read adds the entries in r to m, later entries taking precedence
+func (m *Mailmap) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if entry, ok := parseMailmapLine(line); ok {
+			m.entries = append(m.entries, entry)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseMailmapLine parses one line of a mailmap file
+func parseMailmapLine(line string) (mailmapEntry, bool) {
+	var names, emails []string
+	for len(emails) < 2 {
+		open := strings.IndexByte(line, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(line[open:], '>')
+		if end < 0 {
+			break
+		}
+		names = append(names, strings.TrimSpace(line[:open]))
+		emails = append(emails, strings.TrimSpace(line[open+1:open+end]))
+		line = line[open+end+1:]
+	}
+
+	switch len(emails) {
+	case 1:
+		if names[0] == "" {
+			return mailmapEntry{}, false
+		}
+		return mailmapEntry{name: names[0], commitEmail: emails[0]}, true
+	case 2:
+		return mailmapEntry{name: names[0], email: emails[0], commitName: names[1], commitEmail: emails[1]}, true
+	}
+	return mailmapEntry{}, false
+}
+
+// Resolve returns the canonical name and email for an identity used in a
+// commit. Emails and names are matched case-insensitively, and entries naming
+// the commit's author take precedence over those matching the email alone,
+// which combine as in git. The identity is returned unchanged if no entry
+// matches; a nil Mailmap has no entries.
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+	var byEmail, byName mailmapEntry
+	named := false
+	for _, entry := range m.entries {
+		if !strings.EqualFold(entry.commitEmail, email) {
+			continue
+		}
+		target := &byEmail
+		if entry.commitName != "" {
+			if !strings.EqualFold(entry.commitName, name) {
+				continue
+			}
+			target, named = &byName, true
+		}
+		target.name = firstNonEmpty(entry.name, target.name)
+		target.email = firstNonEmpty(entry.email, target.email)
+	}
+	if named {
+		byEmail = byName
+	}
+	return firstNonEmpty(byEmail.name, name), firstNonEmpty(byEmail.email, email)
+}
+
+// ResolveIdentity returns ident, "Name <email>" as in a Co-authored-by
+// trailer, with its name and email resolved as by Resolve. Anything else is
+// returned unchanged.
+func (m *Mailmap) ResolveIdentity(ident string) string {
+	open := strings.LastIndexByte(ident, '<')
+	if open < 0 || !strings.HasSuffix(ident, ">") {
+		return ident
+	}
+	name, email := m.Resolve(strings.TrimSpace(ident[:open]), ident[open+1:len(ident)-1])
+	if name == "" {
+		return "<" + email + ">"
+	}
+	return name + " <" + email + ">"
+}
+
+// Mailmap returns the repository's mailmap, loaded on first use from the
+// sources git reads: the .mailmap file at the root of the worktree, the blob
+// named by mailmap.blob (HEAD:.mailmap in bare repositories) and the file
+// named by mailmap.file, each taking precedence over the ones before it.
+// Sources that are missing or cannot be read are left out.
+func (r *Repository) Mailmap() *Mailmap {
+	r.mailmapOnce.Do(func() {
+		r.mailmap = r.loadMailmap()
+	})
+	return r.mailmap
+}
+
+// loadMailmap reads the mailmap sources of the repository
+func (r *Repository) loadMailmap() *Mailmap {
+	m := &Mailmap{}
+	cfg := r.loadGitConfig()
+
+	blob := cfg.get("mailmap", "", "blob")
+	if root, err := r.Root(); err == nil {
+		m.readFile(filepath.Join(root, ".mailmap"))
+	} else if blob == "" {
+		blob = "HEAD:.mailmap"
+	}
+	if blob != "" {
+		r.readMailmapBlob(m, blob)
+	}
+	if path := cfg.get("mailmap", "", "file"); path != "" {
+		m.readFile(expandHome(path))
+	}
+	return m
+}
+
+// readFile adds the entries of the mailmap file at path, if it exists
+func (m *Mailmap) readFile(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_ = m.read(f)
+}
+
+// readMailmapBlob adds the entries of the mailmap blob named by spec, either
+// "<revision>:<path>" or a blob hash, if it exists
+func (r *Repository) readMailmapBlob(m *Mailmap, spec string) {
+	var reader io.ReadCloser
+	if rev, path, ok := strings.Cut(spec, ":"); ok {
+		hash, err := r.repo.ResolveRevision(plumbing.Revision(firstNonEmpty(rev, "HEAD")))
+		if err != nil {
+			return
+		}
+		commit, err := r.repo.CommitObject(*hash)
+		if err != nil {
+			return
+		}
+		file, err := commit.File(path)
+		if err != nil {
+			return
+		}
+		if reader, err = file.Reader(); err != nil {
+			return
+		}
+	} else {
+		blob, err := r.repo.BlobObject(plumbing.NewHash(spec))
+		if err != nil {
+			return
+		}
+		if reader, err = blob.Reader(); err != nil {
+			return
+		}
+	}
+	defer reader.Close()
+	_ = m.read(reader)
+}
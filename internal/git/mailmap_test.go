@@ -0,0 +1,94 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	m, err := ParseMailmap(strings.NewReader(`# Canonical identities
+Jane Doe <jane@example.com>
+<jane@example.com> <jane@laptop.local>
+Jane Doe <jane@laptop.local>
+Joe Smith <joe@example.com> <shared@example.com> # trailing comment
+Build Bot <bot@example.com> CI <shared@example.com>
+not an entry
+<no-name@example.com>
+`))
+	if err != nil {
+		t.Fatalf("ParseMailmap() error = %v", err)
+	}
+
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		{"jane", "jane@example.com", "Jane Doe", "jane@example.com"},
+		{"jd", "JANE@Laptop.local", "Jane Doe", "jane@example.com"},
+		{"Joe", "shared@example.com", "Joe Smith", "joe@example.com"},
+		{"ci", "shared@example.com", "Build Bot", "bot@example.com"},
+		{"Someone", "no-name@example.com", "Someone", "no-name@example.com"},
+		{"Other", "other@example.com", "Other", "other@example.com"},
+	}
+	for _, tt := range tests {
+		name, email := m.Resolve(tt.name, tt.email)
+		if name != tt.wantName || email != tt.wantEmail {
+			t.Errorf("Resolve(%q, %q) = %q, %q, want %q, %q", tt.name, tt.email, name, email, tt.wantName, tt.wantEmail)
+		}
+	}
+
+	var none *Mailmap
+	if name, email := none.Resolve("a", "b"); name != "a" || email != "b" {
+		t.Errorf("nil Resolve() = %q, %q, want the identity unchanged", name, email)
+	}
+}
+
+func TestMailmap_ResolveIdentity(t *testing.T) {
+	m, err := ParseMailmap(strings.NewReader("Jane Doe <jane@example.com> <jane@laptop.local>\n<bot@example.com> <ci@example.com>\n"))
+	if err != nil {
+		t.Fatalf("ParseMailmap() error = %v", err)
+	}
+	tests := map[string]string{
+		"jd <jane@laptop.local>":    "Jane Doe <jane@example.com>",
+		"<ci@example.com>":          "<bot@example.com>",
+		"CI Bot <ci@example.com>":   "CI Bot <bot@example.com>",
+		"Other <other@example.com>": "Other <other@example.com>",
+		"PROJ-123":                  "PROJ-123",
+	}
+	for ident, want := range tests {
+		if got := m.ResolveIdentity(ident); got != want {
+			t.Errorf("ResolveIdentity(%q) = %q, want %q", ident, got, want)
+		}
+	}
+}
+
+func TestRepository_Mailmap(t *testing.T) {
+	home := isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	writeFile(t, filepath.Join(dir, ".mailmap"), "Worktree Name <test@example.com>\n")
+	writeFile(t, filepath.Join(home, "mailmap"), "Config Name <canonical@example.com> <test@example.com>\n")
+	appendGitConfig(t, dir, "[mailmap]\n\tfile = ~/mailmap\n")
+
+	lines, err := repo.Blame("initial.txt")
+	if err != nil {
+		t.Fatalf("Blame() failed: %v", err)
+	}
+	if got := lines[0].String(); got != "Config Name <canonical@example.com>" {
+		t.Errorf("Blame() author = %q, want mailmap.file to override .mailmap", got)
+	}
+}
+
+func TestRepository_MailmapBlob(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	commitFile(t, repo, dir, "authors.map", "<canonical@example.com> <test@example.com>\n")
+	appendGitConfig(t, dir, "[mailmap]\n\tblob = HEAD:authors.map\n")
+
+	name, email := repo.Mailmap().Resolve("Test Author", "test@example.com")
+	if name != "Test Author" || email != "canonical@example.com" {
+		t.Errorf("Resolve() = %q, %q, want the email from mailmap.blob", name, email)
+	}
+}
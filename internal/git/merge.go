@@ -0,0 +1,371 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	godiffpatch "github.com/sourcegraph/go-diff-patch"
+)
+
+var (
+	// ErrNotAMerge is returned when a commit reviewed as a merge has fewer than two parents.
+	ErrNotAMerge = errors.New("commit is not a merge")
+	// ErrNoMergeInProgress is returned when there is no MERGE_HEAD to review a resolution against.
+	ErrNoMergeInProgress = errors.New("no merge in progress")
+	// ErrNoResolutionChanges is returned when a merge only took lines from its parents.
+	ErrNoResolutionChanges = errors.New("no conflict resolution changes found")
+)
+
+// mergeVersion is the content of a file on one side of a merge
+type mergeVersion struct {
+	content string
+	exists  bool
+	binary  bool
+}
+
+// GetMergeDiff returns the conflict-resolution hunks of a merge commit: the
+// parts of its diff against the first parent containing lines that are in none
+// of its parents, or removing lines that all of them kept. Hunks that only
+// take one side's version of the code are left out, as in git's combined diff.
+// Binary files are skipped.
+// Returns ErrNotAMerge for commits with fewer than two parents and
+// ErrNoResolutionChanges if the merge introduced nothing of its own.
+func (r *Repository) GetMergeDiff(ref string) (string, error) {
+	commit, err := r.resolveCommit(ref)
+	if err != nil {
+		return "", err
+	}
+	if commit.NumParents() < 2 {
+		return "", fmt.Errorf("%s: %w", ref, ErrNotAMerge)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree for %s: %w", ref, err)
+	}
+
+	var parentTrees []*object.Tree
+	err = commit.Parents().ForEach(func(parent *object.Commit) error {
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to get tree for %s: %w", parent.Hash, err)
+		}
+		parentTrees = append(parentTrees, parentTree)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// A file can only hold resolution changes if it differs from every parent
+	paths, err := changedInAll(parentTrees, tree)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, path := range paths {
+		result, err := treeVersion(tree, path)
+		if err != nil {
+			return "", err
+		}
+		if err := writeResolutionDiff(&b, path, result, parentTrees); err != nil {
+			return "", err
+		}
+	}
+	if b.Len() == 0 {
+		return "", ErrNoResolutionChanges
+	}
+	return b.String(), nil
+}
+
+// GetMergeResolutionDiff returns the conflict-resolution hunks of the merge in
+// progress, comparing the working tree with HEAD and every MERGE_HEAD the same
+// way GetMergeDiff compares a merge commit with its parents. Untracked files
+// are not part of the merge and are left out.
+// Returns ErrNoMergeInProgress without a MERGE_HEAD and ErrNoResolutionChanges
+// if the working tree only takes lines from the merged commits.
+func (r *Repository) GetMergeResolutionDiff() (string, error) {
+	heads, err := r.mergeHeads()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := r.resolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+	var parentTrees []*object.Tree
+	for _, commit := range append([]*object.Commit{head}, heads...) {
+		tree, err := commit.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree for %s: %w", commit.Hash, err)
+		}
+		parentTrees = append(parentTrees, tree)
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	// Files matching HEAD cannot hold resolution changes
+	var paths []string
+	for path, s := range status {
+		if s.Worktree == git.Untracked || (s.Worktree == git.Unmodified && s.Staging == git.Unmodified) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths) // deterministic output (useful for tests)
+
+	var b strings.Builder
+	for _, path := range paths {
+		var result mergeVersion
+		content, err := r.getWorktreeFileContent(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return "", fmt.Errorf("failed to get content for %s: %w", path, err)
+		default:
+			result = mergeVersion{content: content, exists: true, binary: strings.Contains(content, "\x00")}
+		}
+		if err := writeResolutionDiff(&b, path, result, parentTrees); err != nil {
+			return "", err
+		}
+	}
+	if b.Len() == 0 {
+		return "", ErrNoResolutionChanges
+	}
+	return b.String(), nil
+}
+
+// mergeHeads returns the commits listed in MERGE_HEAD
+func (r *Repository) mergeHeads() ([]*object.Commit, error) {
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "MERGE_HEAD"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoMergeInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MERGE_HEAD: %w", err)
+	}
+
+	var heads []*object.Commit
+	for _, line := range strings.Fields(string(data)) {
+		commit, err := r.repo.CommitObject(plumbing.NewHash(line))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get merged commit %s: %w", line, err)
+		}
+		heads = append(heads, commit)
+	}
+	if len(heads) == 0 {
+		return nil, ErrNoMergeInProgress
+	}
+	return heads, nil
+}
+
+// changedInAll returns the sorted paths whose content in tree differs from
+// their content in every one of parents
+func changedInAll(parents []*object.Tree, tree *object.Tree) ([]string, error) {
+	counts := make(map[string]int)
+	for _, parent := range parents {
+		changes, err := object.DiffTree(parent, tree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff trees: %w", err)
+		}
+		for _, change := range changes {
+			path := change.To.Name
+			if path == "" {
+				path = change.From.Name
+			}
+			counts[path]++
+		}
+	}
+
+	var paths []string
+	for path, n := range counts {
+		if n == len(parents) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// treeVersion returns the content of path in tree
+func treeVersion(tree *object.Tree, path string) (mergeVersion, error) {
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return mergeVersion{}, nil
+	}
+	if err != nil {
+		return mergeVersion{}, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if binary, err := file.IsBinary(); err != nil || binary {
+		return mergeVersion{exists: true, binary: true}, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return mergeVersion{}, fmt.Errorf("failed to get content for %s: %w", path, err)
+	}
+	return mergeVersion{content: content, exists: true}, nil
+}
+
+// writeResolutionDiff writes the hunks of path's diff from the first parent
+// to result that contain resolution changes, if there are any
+func writeResolutionDiff(b *strings.Builder, path string, result mergeVersion, parentTrees []*object.Tree) error {
+	if result.binary {
+		return nil
+	}
+	parents := make([]mergeVersion, len(parentTrees))
+	for i, tree := range parentTrees {
+		version, err := treeVersion(tree, path)
+		if err != nil {
+			return err
+		}
+		if version.binary {
+			return nil
+		}
+		parents[i] = version
+	}
+
+	if file := resolutionDiff(path, result, parents); file != nil {
+		b.WriteString(file.String())
+		b.WriteString("\n")
+	}
+	return nil
+}
+
+// resolutionDiff returns the diff of path from the first parent to result,
+// keeping only the hunks with resolution changes, or nil if it has none
+func resolutionDiff(path string, result mergeVersion, parents []mergeVersion) *diff.File {
+	for _, parent := range parents {
+		if parent.exists == result.exists && parent.content == result.content {
+			return nil
+		}
+	}
+
+	patch := diff.QuoteFileLines(godiffpatch.GeneratePatch(path, parents[0].content, result.content), path)
+	if !strings.HasPrefix(patch, "diff --git ") {
+		patch = diff.GitHeader(path) + "\n" + patch
+	}
+	files := diff.Parse(patch)
+	if len(files) == 0 {
+		return nil
+	}
+	file := files[0]
+
+	added, removed := resolutionLines(result.content, parents)
+	var hunks []*diff.Hunk
+	for _, h := range file.Hunks {
+		if hunkHasResolution(h, added, removed) {
+			hunks = append(hunks, h)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil
+	}
+	file.Hunks = hunks
+
+	switch {
+	case !parents[0].exists:
+		file.Header = []string{diff.GitHeader(path), "new file mode 100644", "--- /dev/null", "+++ " + diff.QuotePath("b/"+path)}
+	case !result.exists:
+		file.Header = []string{diff.GitHeader(path), "deleted file mode 100644", "--- " + diff.QuotePath("a/"+path), "+++ /dev/null"}
+	}
+	return file
+}
+
+// resolutionLines compares result with every parent. added reports, for each
+// line of result, whether it is in none of the parents; removed reports, for
+// each position before a line of result (or at its end), whether every parent
+// has lines there that result dropped.
+func resolutionLines(result string, parents []mergeVersion) (added, removed []bool) {
+	result = withTrailingNewline(result)
+	n := strings.Count(result, "\n")
+	added = make([]bool, n)
+	removed = make([]bool, n+1)
+	for i := range added {
+		added[i] = true
+	}
+	for i := range removed {
+		removed[i] = true
+	}
+
+	for _, parent := range parents {
+		parentAdded := make([]bool, n)
+		parentRemoved := make([]bool, n+1)
+		line := 0
+		for _, d := range gitdiff.Do(withTrailingNewline(parent.content), result) {
+			count := strings.Count(d.Text, "\n")
+			switch d.Type {
+			case diffmatchpatch.DiffInsert:
+				for i := line; i < line+count && i < n; i++ {
+					parentAdded[i] = true
+				}
+				line += count
+			case diffmatchpatch.DiffDelete:
+				parentRemoved[min(line, n)] = true
+			default:
+				line += count
+			}
+		}
+		for i := range added {
+			added[i] = added[i] && parentAdded[i]
+		}
+		for i := range removed {
+			removed[i] = removed[i] && parentRemoved[i]
+		}
+	}
+	return added, removed
+}
+
+// hunkHasResolution reports whether a hunk of the diff to result adds a line
+// marked in added or removes lines at a position marked in removed
+func hunkHasResolution(h *diff.Hunk, added, removed []bool) bool {
+	line := max(h.NewStart()-1, 0)
+	for _, l := range h.Lines {
+		if l == "" {
+			continue
+		}
+		switch l[0] {
+		case '+':
+			if line < len(added) && added[line] {
+				return true
+			}
+			line++
+		case '-':
+			if line < len(removed) && removed[line] {
+				return true
+			}
+		case ' ':
+			line++
+		}
+	}
+	return false
+}
+
+// withTrailingNewline returns s ending in a newline, so that every line of a
+// line diff is counted by its newline
+func withTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,152 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitWithParents writes content to name, stages it and commits it with the
+// given parents, returning the commit hash
+func commitWithParents(t *testing.T, repo *Repository, dir, name, content string, parents ...string) string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to stage %s: %v", name, err)
+	}
+	var hashes []plumbing.Hash
+	for _, p := range parents {
+		hashes = append(hashes, plumbing.NewHash(p))
+	}
+	hash, err := worktree.Commit("Update "+name, &git.CommitOptions{
+		Author:  &object.Signature{Name: "Test Author", Email: "test@example.com", When: time.Now()},
+		Parents: hashes,
+	})
+	if err != nil {
+		t.Fatalf("failed to commit %s: %v", name, err)
+	}
+	return hash.String()
+}
+
+// mergeLines returns twenty numbered lines with the given lines replaced
+func mergeLines(replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= 20; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line + "\n")
+		} else {
+			b.WriteString("line " + strings.Repeat("x", i) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// setupMerge commits a base version of file.txt and two branches changing
+// line 2 (ours, left as HEAD) and line 18 (theirs)
+func setupMerge(t *testing.T) (repo *Repository, dir, ours, theirs string) {
+	t.Helper()
+
+	repo, dir, _ = setupTestRepoWithCommit(t)
+	base := commitFile(t, repo, dir, "file.txt", mergeLines(nil))
+	theirs = commitWithParents(t, repo, dir, "file.txt", mergeLines(map[int]string{18: "theirs"}), base)
+	ours = commitWithParents(t, repo, dir, "file.txt", mergeLines(map[int]string{2: "ours"}), base)
+	return repo, dir, ours, theirs
+}
+
+// =============================================================================
+// Tests for GetMergeDiff and GetMergeResolutionDiff
+// =============================================================================
+
+func TestGetMergeDiff_KeepsOnlyResolutionHunks(t *testing.T) {
+	repo, dir, ours, theirs := setupMerge(t)
+	merged := mergeLines(map[int]string{2: "ours", 10: "resolved", 18: "theirs"})
+	sha := commitWithParents(t, repo, dir, "file.txt", merged, ours, theirs)
+
+	diff, err := repo.GetMergeDiff(sha)
+	if err != nil {
+		t.Fatalf("GetMergeDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "diff --git a/file.txt b/file.txt") || !strings.Contains(diff, "+resolved") {
+		t.Errorf("diff should contain the resolution, got:\n%s", diff)
+	}
+	if strings.Contains(diff, "theirs") {
+		t.Errorf("diff should not contain lines taken from a parent, got:\n%s", diff)
+	}
+}
+
+func TestGetMergeDiff_CleanMerge(t *testing.T) {
+	repo, dir, ours, theirs := setupMerge(t)
+	sha := commitWithParents(t, repo, dir, "file.txt", mergeLines(map[int]string{2: "ours", 18: "theirs"}), ours, theirs)
+
+	if _, err := repo.GetMergeDiff(sha); !errors.Is(err, ErrNoResolutionChanges) {
+		t.Errorf("expected ErrNoResolutionChanges, got: %v", err)
+	}
+}
+
+func TestGetMergeDiff_RemovedLines(t *testing.T) {
+	repo, dir, ours, theirs := setupMerge(t)
+	merged := strings.Replace(mergeLines(map[int]string{2: "ours", 18: "theirs"}), "line "+strings.Repeat("x", 10)+"\n", "", 1)
+	sha := commitWithParents(t, repo, dir, "file.txt", merged, ours, theirs)
+
+	diff, err := repo.GetMergeDiff(sha)
+	if err != nil {
+		t.Fatalf("GetMergeDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "-line "+strings.Repeat("x", 10)+"\n") {
+		t.Errorf("diff should contain the removed line, got:\n%s", diff)
+	}
+}
+
+func TestGetMergeDiff_NotAMerge(t *testing.T) {
+	repo, _, ours, _ := setupMerge(t)
+
+	if _, err := repo.GetMergeDiff(ours); !errors.Is(err, ErrNotAMerge) {
+		t.Errorf("expected ErrNotAMerge, got: %v", err)
+	}
+}
+
+func TestGetMergeResolutionDiff(t *testing.T) {
+	repo, dir, _, theirs := setupMerge(t)
+	gitDir, err := repo.GitDir()
+	if err != nil {
+		t.Fatalf("GitDir() failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "MERGE_HEAD"), []byte(theirs+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write MERGE_HEAD: %v", err)
+	}
+	merged := mergeLines(map[int]string{2: "ours", 10: "resolved", 18: "theirs"})
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(merged), 0644); err != nil {
+		t.Fatalf("failed to write file.txt: %v", err)
+	}
+
+	diff, err := repo.GetMergeResolutionDiff()
+	if err != nil {
+		t.Fatalf("GetMergeResolutionDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "+resolved") || strings.Contains(diff, "theirs") {
+		t.Errorf("diff should contain only the resolution, got:\n%s", diff)
+	}
+}
+
+func TestGetMergeResolutionDiff_NoMergeInProgress(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	if _, err := repo.GetMergeResolutionDiff(); !errors.Is(err, ErrNoMergeInProgress) {
+		t.Errorf("expected ErrNoMergeInProgress, got: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// renameThreshold is the similarity, in percent, from which a deleted and an
+// added file are shown as a rename, as with git's default
+const renameThreshold = 50
+
+// stagedRename is a file staged as deleted paired with one staged as added
+// whose content is similar enough to show them as a rename
+type stagedRename struct {
+	from, to   string
+	oldContent string
+	newContent string
+	similarity int // Percentage of lines the two versions share
+}
+
+// findRenames pairs the files among paths staged as deleted with those staged
+// as added, since the status reports a staged rename as both. Each deleted
+// file is paired with the most similar added file not paired yet. Returns the
+// renames keyed by both their old and new path.
+func (r *Repository) findRenames(paths []string, status git.Status, headTree *object.Tree, indexHashByPath map[string]plumbing.Hash) (map[string]*stagedRename, error) {
+	var deleted, added []string
+	for _, path := range paths {
+		switch status.File(path).Staging {
+		case git.Deleted:
+			deleted = append(deleted, path)
+		case git.Added:
+			added = append(added, path)
+		}
+	}
+	renames := make(map[string]*stagedRename)
+	if len(deleted) == 0 || len(added) == 0 {
+		return renames, nil
+	}
+
+	addedContent := make(map[string]string, len(added))
+	for _, path := range added {
+		hash, ok := indexHashByPath[path]
+		if !ok {
+			return nil, fmt.Errorf("failed to get index entry for added file %s", path)
+		}
+		content, err := r.getIndexFileContent(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get content for added file %s: %w", path, err)
+		}
+		addedContent[path] = content
+	}
+
+	for _, from := range deleted {
+		oldContent, err := r.getTreeFileContent(headTree, from)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get content for deleted file %s: %w", from, err)
+		}
+		var best *stagedRename
+		for _, to := range added {
+			if renames[to] != nil {
+				continue
+			}
+			score := similarity(oldContent, addedContent[to])
+			if score >= renameThreshold && (best == nil || score > best.similarity) {
+				best = &stagedRename{from: from, to: to, oldContent: oldContent, newContent: addedContent[to], similarity: score}
+			}
+		}
+		if best != nil {
+			renames[best.from] = best
+			renames[best.to] = best
+		}
+	}
+	return renames, nil
+}
+
+// similarity returns the percentage of lines a and b have in common
+func similarity(a, b string) int {
+	if a == b {
+		return 100
+	}
+	// The empty string after a final newline is not a line
+	aLines := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	bLines := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	counts := make(map[string]int, len(aLines))
+	for _, line := range aLines {
+		counts[line]++
+	}
+	common := 0
+	for _, line := range bLines {
+		if counts[line] > 0 {
+			counts[line]--
+			common++
+		}
+	}
+	// Identical files are the only ones scoring 100
+	return min(common*200/(len(aLines)+len(bLines)), 99)
+}
+
+// writeRename writes the diff of a staged rename as git does: the rename
+// header, followed by the changes made to the content if there are any
+func (r *Repository) writeRename(b *strings.Builder, rn *stagedRename) {
+	b.WriteString("diff --git " + diff.QuotePath("a/"+rn.from) + " " + diff.QuotePath("b/"+rn.to) + "\n")
+	fmt.Fprintf(b, "similarity index %d%%\n", rn.similarity)
+	b.WriteString("rename from " + diff.QuotePath(rn.from) + "\n")
+	b.WriteString("rename to " + diff.QuotePath(rn.to) + "\n")
+	if rn.oldContent == rn.newContent {
+		return
+	}
+	if reason := r.contentFilter.omitReason(rn.to, rn.oldContent, rn.newContent); reason != "" {
+		b.WriteString(diff.OmittedNote(reason))
+		return
+	}
+
+	// The patch names the file by its new path on both sides
+	patch := r.filePatch(rn.to, rn.oldContent, rn.newContent)
+	if idx := strings.Index(patch, "\n@@"); idx >= 0 {
+		b.WriteString("--- " + diff.QuotePath("a/"+rn.from) + "\n")
+		b.WriteString("+++ " + diff.QuotePath("b/"+rn.to) + "\n")
+		b.WriteString(patch[idx+1:])
+	}
+}
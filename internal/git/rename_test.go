@@ -0,0 +1,106 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stageRename moves initial.txt of a repository made by
+// setupTestRepoWithCommit to newName with content and stages both sides
+func stageRename(t *testing.T, repo *Repository, dir, newName, content string) {
+	t.Helper()
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Remove("initial.txt"); err != nil {
+		t.Fatalf("failed to remove initial.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, newName), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", newName, err)
+	}
+	if _, err := worktree.Add(newName); err != nil {
+		t.Fatalf("failed to stage %s: %v", newName, err)
+	}
+}
+
+func TestGetStagedDiff_PureRename(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	stageRename(t, repo, dir, "renamed.txt", "initial content\n")
+
+	diff, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	want := "diff --git a/initial.txt b/renamed.txt\n" +
+		"similarity index 100%\n" +
+		"rename from initial.txt\n" +
+		"rename to renamed.txt\n\n"
+	if diff != want {
+		t.Errorf("diff = %q, want %q", diff, want)
+	}
+}
+
+func TestGetStagedDiff_RenameWithChanges(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	// Replace the commit's file with a longer one so a one-line edit keeps
+	// most of it
+	lines := "one\ntwo\nthree\nfour\nfive\n"
+	commitFile(t, repo, dir, "initial.txt", lines)
+	stageRename(t, repo, dir, "numbers.txt", strings.Replace(lines, "three", "THREE", 1))
+
+	diff, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	for _, want := range []string{
+		"diff --git a/initial.txt b/numbers.txt\n",
+		"rename from initial.txt\nrename to numbers.txt\n--- a/initial.txt\n+++ b/numbers.txt\n@@",
+		"-three\n+THREE\n",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "deleted file mode") || strings.Contains(diff, "new file mode") {
+		t.Errorf("rename should not be shown as a deletion and an addition:\n%s", diff)
+	}
+}
+
+func TestGetStagedDiff_UnrelatedAddAndDeleteAreNotARename(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	stageRename(t, repo, dir, "other.txt", "something else\nentirely\n")
+
+	diff, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	if strings.Contains(diff, "rename from") {
+		t.Errorf("unrelated files should not be paired as a rename:\n%s", diff)
+	}
+	if !strings.Contains(diff, "deleted file mode") || !strings.Contains(diff, "new file mode") {
+		t.Errorf("expected a deletion and an addition:\n%s", diff)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"a\nb\n", "a\nb\n", 100},
+		{"a\nb\nc\n", "a\nb\nd\n", 66},
+		{"a\n", "b\n", 0},
+		{"a\nb\n", "a\nb", 99},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarity(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
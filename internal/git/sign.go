@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// signer returns the signer for commits when commit.gpgsign is set, or nil if
+// commits are not signed. Like git, it runs gpg.program (gpg) for the openpgp
+// format, gpg.x509.program (gpgsm) for x509 and gpg.ssh.program (ssh-keygen)
+// for ssh, with the key in user.signingkey. OpenPGP signing falls back to the
+// committer's identity when no key is set.
+func (c gitConfig) signer(committer *object.Signature) (git.Signer, error) {
+	if !c.getBool("commit", "", "gpgsign") {
+		return nil, nil
+	}
+	key := c.get("user", "", "signingkey")
+
+	switch format := c.get("gpg", "", "format"); format {
+	case "", "openpgp":
+		if key == "" {
+			key = committer.Name + " <" + committer.Email + ">"
+		}
+		return &gpgSigner{program: firstNonEmpty(c.get("gpg", "", "program"), c.get("gpg", "openpgp", "program"), "gpg"), key: key}, nil
+	case "x509":
+		if key == "" {
+			key = committer.Name + " <" + committer.Email + ">"
+		}
+		return &gpgSigner{program: firstNonEmpty(c.get("gpg", "x509", "program"), "gpgsm"), key: key}, nil
+	case "ssh":
+		if key == "" {
+			return nil, fmt.Errorf("commit.gpgsign is set but user.signingkey is not; set it to the SSH key to sign with")
+		}
+		return &sshSigner{program: firstNonEmpty(c.get("gpg", "ssh", "program"), "ssh-keygen"), key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format %q (expected openpgp, x509 or ssh)", format)
+	}
+}
+
+// gpgSigner signs commits with gpg, or with gpgsm for x509 certificates,
+// which take the same arguments
+type gpgSigner struct {
+	program string // Program to run
+	key     string // Key ID or user ID to sign with
+}
+
+// Sign returns an armored detached signature of message
+func (s *gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.program, "--status-fd=2", "-bsau", s.key)
+	cmd.Stdin = message
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	// gpg reports success on its status output, which it can omit even when
+	// it exits cleanly
+	if err != nil || !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return nil, fmt.Errorf("%s failed to sign the commit: %s", s.program, commandError(err, stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// sshSigner signs commits with ssh-keygen
+type sshSigner struct {
+	program string // Program to run
+	key     string // Path to a key, or a public key ("ssh-..." or "key::...") held by ssh-agent
+}
+
+// Sign returns the SSH signature of message
+func (s *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "revi-sign-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory for signing: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// ssh-keygen signs a file, writing the signature next to it
+	buffer := filepath.Join(dir, "commit")
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit to sign: %w", err)
+	}
+	if err := os.WriteFile(buffer, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write commit to sign: %w", err)
+	}
+
+	args := []string{"-Y", "sign", "-n", "git", "-f"}
+	if literal, ok := literalSSHKey(s.key); ok {
+		// A public key names a private key held by ssh-agent
+		keyFile := filepath.Join(dir, "key.pub")
+		if err := os.WriteFile(keyFile, []byte(literal+"\n"), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+		args = append(args, keyFile, "-U")
+	} else {
+		args = append(args, expandHome(s.key))
+	}
+	args = append(args, buffer)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.program, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed to sign the commit: %s", s.program, commandError(err, stderr.String()))
+	}
+	signature, err := os.ReadFile(buffer + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH signature: %w", err)
+	}
+	return signature, nil
+}
+
+// literalSSHKey returns the public key in user.signingkey if it holds one
+// rather than a path
+func literalSSHKey(key string) (string, bool) {
+	if literal, ok := strings.CutPrefix(key, "key::"); ok {
+		return literal, true
+	}
+	if strings.HasPrefix(key, "ssh-") {
+		return key, true
+	}
+	return "", false
+}
+
+// commandError describes why an external command failed, preferring what it
+// wrote to stderr
+func commandError(err error, stderr string) string {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return msg
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "no signature was created"
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// stageAndCommit stages a change and commits it with Commit, returning the
+// hash of the new commit
+func stageAndCommit(t *testing.T, repo *Repository, dir string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "change.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatalf("failed to write change.txt: %v", err)
+	}
+	if err := repo.StageFiles([]string{"change.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+	hash, err := repo.Commit("Add change")
+	if err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	return hash
+}
+
+func TestCommit_IdentityFromGitConfig(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	appendGitConfig(t, dir, "[user]\n\tname = Jo Dev\n\temail = jo@example.com\n[committer]\n\temail = ci@example.com\n")
+	t.Setenv("GIT_AUTHOR_NAME", "Env Author")
+
+	hash := stageAndCommit(t, repo, dir)
+	commit, err := repo.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if commit.Author.Name != "Env Author" || commit.Author.Email != "jo@example.com" {
+		t.Errorf("author = %s <%s>, want Env Author <jo@example.com>", commit.Author.Name, commit.Author.Email)
+	}
+	if commit.Committer.Name != "Jo Dev" || commit.Committer.Email != "ci@example.com" {
+		t.Errorf("committer = %s <%s>, want Jo Dev <ci@example.com>", commit.Committer.Name, commit.Committer.Email)
+	}
+	if commit.PGPSignature != "" {
+		t.Error("commit should not be signed without commit.gpgsign")
+	}
+}
+
+func TestCommit_SignsWithGPGProgram(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	// A stand-in for gpg that records its arguments and reports success
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	program := filepath.Join(bin, "fake-gpg")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat > /dev/null\n" +
+		"echo '[GNUPG:] SIG_CREATED D 1 8 00 0 ABCD' >&2\n" +
+		"printf -- '-----BEGIN PGP SIGNATURE-----\\nfake\\n-----END PGP SIGNATURE-----\\n'\n"
+	if err := os.WriteFile(program, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+	appendGitConfig(t, dir, "[commit]\n\tgpgsign = true\n[user]\n\tsigningkey = ABCD\n[gpg]\n\tprogram = "+program+"\n")
+
+	hash := stageAndCommit(t, repo, dir)
+	commit, err := repo.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if !strings.Contains(commit.PGPSignature, "fake") {
+		t.Errorf("PGPSignature = %q, want the fake signature", commit.PGPSignature)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("fake gpg was not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(args)); got != "--status-fd=2 -bsau ABCD" {
+		t.Errorf("gpg arguments = %q, want %q", got, "--status-fd=2 -bsau ABCD")
+	}
+}
+
+func TestCommit_SigningFailureIsReported(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	appendGitConfig(t, dir, "[commit]\n\tgpgsign = true\n[gpg]\n\tprogram = false\n")
+
+	if err := os.WriteFile(filepath.Join(dir, "change.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatalf("failed to write change.txt: %v", err)
+	}
+	if err := repo.StageFiles([]string{"change.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+	if _, err := repo.Commit("Add change"); err == nil || !strings.Contains(err.Error(), "failed to sign") {
+		t.Errorf("Commit() error = %v, want a signing failure", err)
+	}
+}
+
+func TestCommit_SignsWithSSHKey(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("failed to generate SSH key: %v: %s", err, out)
+	}
+	appendGitConfig(t, dir, "[commit]\n\tgpgsign = true\n[gpg]\n\tformat = ssh\n[user]\n\tsigningkey = "+key+"\n")
+
+	hash := stageAndCommit(t, repo, dir)
+	commit, err := repo.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if !strings.HasPrefix(commit.PGPSignature, "-----BEGIN SSH SIGNATURE-----") {
+		t.Errorf("PGPSignature = %q, want an SSH signature", commit.PGPSignature)
+	}
+}
+
+func TestGitConfigSigner(t *testing.T) {
+	isolateGitConfig(t)
+	repo, dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	appendGitConfig(t, dir, "[commit]\n\tgpgsign = yes\n[gpg]\n\tformat = ssh\n")
+
+	cfg := repo.loadGitConfig()
+	if _, err := cfg.signer(cfg.signature("committer")); err == nil || !strings.Contains(err.Error(), "user.signingkey") {
+		t.Errorf("signer() error = %v, want one asking for user.signingkey", err)
+	}
+}
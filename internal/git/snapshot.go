@@ -0,0 +1,233 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// SnapshotFile is the name of the file in the git directory that records the
+// last snapshot of the working tree.
+const SnapshotFile = "revi-snapshot.json"
+
+var (
+	// ErrNoSnapshot is returned when no snapshot of the working tree was taken.
+	ErrNoSnapshot = errors.New("no working tree snapshot found")
+	// ErrNoSnapshotChanges is returned when the working tree matches the snapshot.
+	ErrNoSnapshotChanges = errors.New("no changes since the snapshot")
+)
+
+// Snapshot records the working tree files that differed from the index when it
+// was taken. Their content is stored as blobs in the object database; files
+// not listed matched the index.
+type Snapshot struct {
+	Taken time.Time         `json:"taken"`
+	Files map[string]string `json:"files"` // Blob hash by path, empty for files missing from the working tree
+}
+
+// TakeSnapshot records the current state of the working tree, replacing any
+// earlier snapshot. Ignored files are not recorded.
+func (r *Repository) TakeSnapshot() (*Snapshot, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	snap := &Snapshot{Taken: time.Now(), Files: make(map[string]string)}
+	for path, s := range status {
+		switch s.Worktree {
+		case git.Unmodified:
+			continue
+		case git.Deleted:
+			snap.Files[path] = ""
+		default:
+			hash, err := r.storeWorktreeFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to snapshot %s: %w", path, err)
+			}
+			snap.Files[path] = hash.String()
+		}
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	path, err := r.snapshotPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// LoadSnapshot returns the snapshot taken last, or ErrNoSnapshot.
+func (r *Repository) LoadSnapshot() (*Snapshot, error) {
+	path, err := r.snapshotPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// GetSnapshotDiff returns a unified diff of the changes made to the working
+// tree since the last snapshot. Files that were clean when the snapshot was
+// taken are compared with the index, so staging them in between hides the
+// staged part of their changes.
+// Returns ErrNoSnapshot without a snapshot and ErrNoSnapshotChanges if nothing changed.
+func (r *Repository) GetSnapshotDiff() (string, error) {
+	snap, err := r.LoadSnapshot()
+	if err != nil {
+		return "", err
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("failed to get index: %w", err)
+	}
+	indexHashByPath := make(map[string]plumbing.Hash, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		indexHashByPath[entry.Name] = entry.Hash
+	}
+
+	// Files dirty now or at the snapshot may have changed in between
+	paths := make(map[string]bool)
+	for path, s := range status {
+		if s.Worktree != git.Unmodified {
+			paths[path] = true
+		}
+	}
+	for path := range snap.Files {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted) // deterministic output (useful for tests)
+
+	var diffBuilder strings.Builder
+	for _, path := range sorted {
+		// Content when the snapshot was taken
+		var before plumbing.Hash
+		if hash, ok := snap.Files[path]; ok {
+			before = plumbing.NewHash(hash)
+		} else {
+			before = indexHashByPath[path]
+		}
+
+		// Content now; clean files are left out of the status and match the index
+		var after plumbing.Hash
+		s, listed := status[path]
+		switch {
+		case !listed || s.Worktree == git.Unmodified:
+			after = indexHashByPath[path]
+		case s.Worktree == git.Deleted:
+		default:
+			if after, err = r.hashWorktreeFile(path); err != nil {
+				return "", fmt.Errorf("failed to hash %s: %w", path, err)
+			}
+		}
+
+		if before == after {
+			continue
+		}
+		if err := r.writeSnapshotFileDiff(&diffBuilder, path, before, after); err != nil {
+			return "", err
+		}
+	}
+
+	if diffBuilder.Len() == 0 {
+		return "", ErrNoSnapshotChanges
+	}
+	return diffBuilder.String(), nil
+}
+
+// writeSnapshotFileDiff writes the diff of path between the blobs before and
+// after, where a zero hash means the file does not exist
+func (r *Repository) writeSnapshotFileDiff(b *strings.Builder, path string, before, after plumbing.Hash) error {
+	var oldContent, newContent string
+	var err error
+	if !before.IsZero() {
+		if oldContent, err = r.getIndexFileContent(before); err != nil {
+			return fmt.Errorf("failed to get snapshot content for %s: %w", path, err)
+		}
+	}
+	if !after.IsZero() {
+		if newContent, err = r.getWorktreeFileContent(path); err != nil {
+			return fmt.Errorf("failed to get content for %s: %w", path, err)
+		}
+	}
+
+	switch {
+	case before.IsZero():
+		b.WriteString(r.addedFilePatch(path, newContent))
+	case after.IsZero():
+		b.WriteString(r.deletedFilePatch(path, oldContent))
+	default:
+		b.WriteString(r.filePatch(path, oldContent, newContent))
+	}
+	b.WriteString("\n")
+	return nil
+}
+
+// storeWorktreeFile writes the content of a working tree file to the object
+// database as a blob and returns its hash
+func (r *Repository) storeWorktreeFile(path string) (plumbing.Hash, error) {
+	content, err := r.getWorktreeFileContent(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return r.storeBlob(content)
+}
+
+// hashWorktreeFile returns the blob hash of a working tree file's content
+func (r *Repository) hashWorktreeFile(path string) (plumbing.Hash, error) {
+	content, err := r.getWorktreeFileContent(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, []byte(content)), nil
+}
+
+// snapshotPath returns the path of the snapshot file in the git directory
+func (r *Repository) snapshotPath() (string, error) {
+	gitDir, err := r.GitDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	return filepath.Join(gitDir, SnapshotFile), nil
+}
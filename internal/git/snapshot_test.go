@@ -0,0 +1,89 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorktreeFile writes content to name in the working tree
+func writeWorktreeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+// =============================================================================
+// Tests for TakeSnapshot and GetSnapshotDiff
+// =============================================================================
+
+func TestGetSnapshotDiff_NoSnapshot(t *testing.T) {
+	repo, _, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	if _, err := repo.GetSnapshotDiff(); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("GetSnapshotDiff() error = %v, want ErrNoSnapshot", err)
+	}
+}
+
+func TestGetSnapshotDiff_OnlyChangesSinceSnapshot(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	// Edits made before the snapshot are not part of the diff
+	writeWorktreeFile(t, dir, "initial.txt", "initial content\nhand edit\n")
+	writeWorktreeFile(t, dir, "gen/old.go", "package gen\n")
+	if _, err := repo.TakeSnapshot(); err != nil {
+		t.Fatalf("TakeSnapshot() failed: %v", err)
+	}
+	if _, err := repo.GetSnapshotDiff(); !errors.Is(err, ErrNoSnapshotChanges) {
+		t.Fatalf("GetSnapshotDiff() right after the snapshot error = %v, want ErrNoSnapshotChanges", err)
+	}
+
+	// The "build" rewrites a dirty file, adds a file and removes another
+	writeWorktreeFile(t, dir, "initial.txt", "initial content\nhand edit\ngenerated\n")
+	writeWorktreeFile(t, dir, "gen/new.go", "package gen\n\nconst X = 1\n")
+	if err := os.Remove(filepath.Join(dir, "gen/old.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := repo.GetSnapshotDiff()
+	if err != nil {
+		t.Fatalf("GetSnapshotDiff() failed: %v", err)
+	}
+	for _, want := range []string{"+generated", "+++ b/gen/new.go", "+const X = 1", "--- a/gen/old.go", "-package gen"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff should contain %q, got:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "+hand edit") {
+		t.Errorf("diff should not contain edits made before the snapshot, got:\n%s", diff)
+	}
+}
+
+func TestGetSnapshotDiff_RevertedFile(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+
+	writeWorktreeFile(t, dir, "initial.txt", "dirty\n")
+	if _, err := repo.TakeSnapshot(); err != nil {
+		t.Fatalf("TakeSnapshot() failed: %v", err)
+	}
+
+	// A formatter restoring the committed content is a change too
+	writeWorktreeFile(t, dir, "initial.txt", "initial content\n")
+	diff, err := repo.GetSnapshotDiff()
+	if err != nil {
+		t.Fatalf("GetSnapshotDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "-dirty") || !strings.Contains(diff, "+initial content") {
+		t.Errorf("diff should restore the committed content, got:\n%s", diff)
+	}
+}
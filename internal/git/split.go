@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// StagedChanges is a saved copy of the index, so that its staged changes can
+// be committed a few files at a time with StageOnly.
+type StagedChanges struct {
+	index *index.Index
+	paths []string
+}
+
+// Paths returns the sorted paths of the files with staged changes.
+func (s *StagedChanges) Paths() []string {
+	return s.paths
+}
+
+// SaveStaged saves the staged changes of the index. Files with unresolved
+// conflicts are left out. Returns ErrNoStagedChanges if nothing is staged.
+func (r *Repository) SaveStaged() (*StagedChanges, error) {
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index: %w", err)
+	}
+	head, err := r.headFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	inIndex := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		// Unconflicted entries are at stage 0; go-git's index.Merged is not
+		if entry.Stage != 0 {
+			continue
+		}
+		inIndex[entry.Name] = true
+		if f, ok := head[entry.Name]; !ok || f.Hash != entry.Hash || f.Mode != entry.Mode {
+			paths = append(paths, entry.Name)
+		}
+	}
+	for path := range head {
+		if !inIndex[path] {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoStagedChanges
+	}
+	sort.Strings(paths)
+	return &StagedChanges{index: idx, paths: paths}, nil
+}
+
+// StageOnly rewrites the index so that, of the saved staged changes, only
+// those to paths are staged; the other files are staged as they are in HEAD.
+// Committing then commits the changes to paths alone. Files committed since
+// the changes were saved are in HEAD as staged, so they stay unchanged.
+func (r *Repository) StageOnly(staged *StagedChanges, paths []string) error {
+	head, err := r.headFiles()
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		keep[path] = true
+	}
+	unstage := make(map[string]bool)
+	for _, path := range staged.paths {
+		if !keep[path] {
+			unstage[path] = true
+		}
+	}
+
+	// The cached trees would describe the saved index, so they are dropped
+	idx := &index.Index{Version: staged.index.Version, ResolveUndo: staged.index.ResolveUndo}
+	for _, entry := range staged.index.Entries {
+		if unstage[entry.Name] {
+			// Already committed as staged, so the saved entry still applies
+			if f, ok := head[entry.Name]; !ok || f.Hash != entry.Hash || f.Mode != entry.Mode {
+				continue
+			}
+			delete(unstage, entry.Name)
+		}
+		e := *entry
+		idx.Entries = append(idx.Entries, &e)
+	}
+	for path := range unstage {
+		if f, ok := head[path]; ok {
+			idx.Entries = append(idx.Entries, &index.Entry{Name: path, Hash: f.Hash, Mode: f.Mode})
+		}
+	}
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		if idx.Entries[i].Name != idx.Entries[j].Name {
+			return idx.Entries[i].Name < idx.Entries[j].Name
+		}
+		return idx.Entries[i].Stage < idx.Entries[j].Stage
+	})
+
+	if err := r.repo.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// RestoreStaged stages again every saved change not yet committed, as it was
+// staged when saved.
+func (r *Repository) RestoreStaged(staged *StagedChanges) error {
+	return r.StageOnly(staged, staged.paths)
+}
+
+// headFiles returns the files of the HEAD commit by path, or none if the
+// repository has no commits yet
+func (r *Repository) headFiles() (map[string]*object.File, error) {
+	files := make(map[string]*object.File)
+	head, err := r.repo.Head()
+	if err != nil {
+		// No commits yet
+		return files, nil
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head tree: %w", err)
+	}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = f
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of HEAD: %w", err)
+	}
+	return files, nil
+}
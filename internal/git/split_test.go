@@ -0,0 +1,90 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stageSplitChanges stages a new file and a change to initial.txt, then
+// changes initial.txt again without staging it
+func stageSplitChanges(t *testing.T, repo *Repository, dir string) {
+	t.Helper()
+	writeFile(t, filepath.Join(dir, "feature.txt"), "feature\n")
+	writeFile(t, filepath.Join(dir, "initial.txt"), "staged content\n")
+	if err := repo.StageFiles([]string{"feature.txt", "initial.txt"}); err != nil {
+		t.Fatalf("StageFiles() failed: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "initial.txt"), "unstaged content\n")
+}
+
+func TestStageOnly_CommitsGroupsAsStaged(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	stageSplitChanges(t, repo, dir)
+
+	staged, err := repo.SaveStaged()
+	if err != nil {
+		t.Fatalf("SaveStaged() failed: %v", err)
+	}
+	if !slices.Equal(staged.Paths(), []string{"feature.txt", "initial.txt"}) {
+		t.Fatalf("Paths() = %q", staged.Paths())
+	}
+
+	for _, group := range [][]string{{"feature.txt"}, {"initial.txt"}} {
+		if err := repo.StageOnly(staged, group); err != nil {
+			t.Fatalf("StageOnly(%q) failed: %v", group, err)
+		}
+		files, err := repo.GetStagedFiles()
+		if err != nil {
+			t.Fatalf("GetStagedFiles() failed: %v", err)
+		}
+		if !slices.Equal(files, group) {
+			t.Errorf("staged files = %q, want %q", files, group)
+		}
+		if _, err := repo.Commit("Commit " + group[0]); err != nil {
+			t.Fatalf("Commit() failed: %v", err)
+		}
+	}
+
+	diff, err := repo.GetCommitDiff("HEAD")
+	if err != nil {
+		t.Fatalf("GetCommitDiff() failed: %v", err)
+	}
+	if !strings.Contains(diff, "+staged content") || strings.Contains(diff, "unstaged content") {
+		t.Errorf("last commit should have the staged content of initial.txt, got:\n%s", diff)
+	}
+	if _, err := repo.SaveStaged(); !errors.Is(err, ErrNoStagedChanges) {
+		t.Errorf("SaveStaged() after committing every group = %v, want ErrNoStagedChanges", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "initial.txt")); string(content) != "unstaged content\n" {
+		t.Errorf("working tree changed: %q", content)
+	}
+}
+
+func TestRestoreStaged(t *testing.T) {
+	repo, dir, cleanup := setupTestRepoWithCommit(t)
+	defer cleanup()
+	stageSplitChanges(t, repo, dir)
+
+	staged, err := repo.SaveStaged()
+	if err != nil {
+		t.Fatalf("SaveStaged() failed: %v", err)
+	}
+	before, err := repo.GetStagedDiff()
+	if err != nil {
+		t.Fatalf("GetStagedDiff() failed: %v", err)
+	}
+	if err := repo.StageOnly(staged, []string{"feature.txt"}); err != nil {
+		t.Fatalf("StageOnly() failed: %v", err)
+	}
+	if err := repo.RestoreStaged(staged); err != nil {
+		t.Fatalf("RestoreStaged() failed: %v", err)
+	}
+	if after, _ := repo.GetStagedDiff(); after != before {
+		t.Errorf("staged diff after restoring =\n%s\nwant\n%s", after, before)
+	}
+}
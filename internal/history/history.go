@@ -0,0 +1,256 @@
+// Package history keeps a log of past review runs in the repository's git
+// directory, so findings can be looked up again after the changes were
+// committed. Each run is stored as one JSON object per line.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// FileName is the name of the history file created in the repository's git directory.
+const FileName = "revi-history.jsonl"
+
+// DefaultMaxEntries is how many runs are kept unless SetMaxEntries is called.
+const DefaultMaxEntries = 500
+
+// ErrNotFound is returned by Get when no entry matches the ID.
+var ErrNotFound = errors.New("review not found in history")
+
+// Entry is a recorded review run.
+type Entry struct {
+	ID           string           `json:"id"`
+	Time         time.Time        `json:"time"`
+	Source       string           `json:"source"`           // Description of the reviewed diff, e.g. "staged changes"
+	DiffHash     string           `json:"diff_hash"`        // See HashDiff
+	Modes        []review.Mode    `json:"modes"`            // Modes that were reviewed
+	Results      []*review.Result `json:"results"`          // Results in the order reviews finished
+	FixesApplied int              `json:"fixes_applied"`    // Fixes applied with --fix
+	Commit       string           `json:"commit,omitempty"` // Commit containing the reviewed changes, if known
+}
+
+// Issues returns the number of issues found across all results.
+func (e Entry) Issues() int {
+	n := 0
+	for _, r := range e.Results {
+		if r != nil {
+			n += len(r.Issues)
+		}
+	}
+	return n
+}
+
+// HashDiff returns the hex SHA-256 of diff, which identifies the reviewed changes.
+func HashDiff(diff string) string {
+	sum := sha256.Sum256([]byte(diff))
+	return hex.EncodeToString(sum[:])
+}
+
+// Store reads and writes the history file.
+type Store struct {
+	path       string
+	maxEntries int
+	maxAge     time.Duration
+	maxSize    int64
+}
+
+// Open returns the store for the history file in dir. The file is created on
+// the first Add.
+func Open(dir string) *Store {
+	return &Store{path: filepath.Join(dir, FileName), maxEntries: DefaultMaxEntries}
+}
+
+// SetMaxEntries sets how many runs are kept; older runs are dropped on Add.
+// Zero or less keeps every run.
+func (s *Store) SetMaxEntries(n int) {
+	s.maxEntries = n
+}
+
+// SetMaxAge sets how long runs are kept; older runs are dropped on Add and
+// Prune. Zero or less keeps runs of any age.
+func (s *Store) SetMaxAge(d time.Duration) {
+	s.maxAge = d
+}
+
+// SetMaxSize sets the size in bytes the history file is kept under by dropping
+// the oldest runs on Add and Prune. The newest run is always kept. Zero or less
+// sets no limit.
+func (s *Store) SetMaxSize(n int64) {
+	s.maxSize = n
+}
+
+// Add records e, filling in its ID and time if they are empty, and drops the
+// runs the retention limits no longer allow.
+func (s *Store) Add(e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = newID(e.Time, e.DiffHash)
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, *e)
+	kept, err := s.retain(entries, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.write(kept)
+}
+
+// Prune drops the runs the retention limits no longer allow, for when the
+// limits were lowered or runs aged out since the last Add. Returns how many
+// runs were dropped; the file is left untouched if none were.
+func (s *Store) Prune() (int, error) {
+	entries, err := s.List()
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	kept, err := s.retain(entries, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if len(kept) == len(entries) {
+		return 0, nil
+	}
+	return len(entries) - len(kept), s.write(kept)
+}
+
+// retain returns the newest of entries, oldest first, that fit the retention
+// limits at now
+func (s *Store) retain(entries []Entry, now time.Time) ([]Entry, error) {
+	if s.maxEntries > 0 && len(entries) > s.maxEntries {
+		entries = entries[len(entries)-s.maxEntries:]
+	}
+	if s.maxAge > 0 {
+		cutoff := now.Add(-s.maxAge)
+		first := 0
+		for first < len(entries) && entries[first].Time.Before(cutoff) {
+			first++
+		}
+		entries = entries[first:]
+	}
+	if s.maxSize > 0 && len(entries) > 1 {
+		// Count back from the newest run until the limit is reached
+		var size int64
+		first := len(entries)
+		for first > 0 {
+			line, err := json.Marshal(entries[first-1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode review history: %w", err)
+			}
+			size += int64(len(line)) + 1
+			if size > s.maxSize && first < len(entries) {
+				break
+			}
+			first--
+		}
+		entries = entries[first:]
+	}
+	return entries, nil
+}
+
+// List returns all recorded runs, oldest first. Lines that cannot be parsed
+// are skipped.
+func (s *Store) List() ([]Entry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review history: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.ID == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Get returns the run whose ID starts with id.
+func (s *Store) Get(id string) (Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var matches []Entry
+	for _, e := range entries {
+		if id != "" && strings.HasPrefix(e.ID, id) {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	case 1:
+		return matches[0], nil
+	default:
+		return Entry{}, fmt.Errorf("review ID %s is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+// LinkCommit records commit on the newest run of the diff with diffHash that is
+// not linked to a commit yet. Returns false if there is no such run.
+func (s *Store) LinkCommit(diffHash, commit string) (bool, error) {
+	entries, err := s.List()
+	if err != nil {
+		return false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].DiffHash == diffHash && entries[i].Commit == "" {
+			entries[i].Commit = commit
+			return true, s.write(entries)
+		}
+	}
+	return false, nil
+}
+
+// write replaces the history file with entries, going through a temporary file
+// so a failed write does not lose the existing history
+func (s *Store) write(entries []Entry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode review history: %w", err)
+		}
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write review history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write review history: %w", err)
+	}
+	return nil
+}
+
+// newID derives a short ID from the time of the run and the reviewed diff
+func newID(t time.Time, diffHash string) string {
+	sum := sha256.Sum256([]byte(t.Format(time.RFC3339Nano) + diffHash))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// addEntry records an entry for diff at the given minute and returns it
+func addEntry(t *testing.T, s *Store, diff string, minute int) Entry {
+	t.Helper()
+	e := Entry{
+		Time:     time.Date(2026, 1, 2, 3, minute, 0, 0, time.UTC),
+		Source:   "staged changes",
+		DiffHash: HashDiff(diff),
+		Modes:    []review.Mode{review.ModeSecurity},
+		Results: []*review.Result{{
+			Mode:   review.ModeSecurity,
+			Status: review.StatusIssues,
+			Issues: []review.Issue{{Severity: "high", Description: "SQL injection"}},
+		}},
+	}
+	if err := s.Add(&e); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+	return e
+}
+
+// =============================================================================
+// Tests for Add and List
+// =============================================================================
+
+func TestStore_AddAndList(t *testing.T) {
+	s := Open(t.TempDir())
+
+	entries, err := s.List()
+	if err != nil || entries != nil {
+		t.Fatalf("List() on a missing file = %v, %v; want nil, nil", entries, err)
+	}
+
+	first := addEntry(t, s, "diff one", 1)
+	second := addEntry(t, s, "diff two", 2)
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("expected distinct IDs, got %q and %q", first.ID, second.ID)
+	}
+
+	entries, err = s.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != first.ID || entries[1].ID != second.ID {
+		t.Fatalf("List() = %+v, want the two entries oldest first", entries)
+	}
+	if got := entries[0].Issues(); got != 1 {
+		t.Errorf("Issues() = %d, want 1", got)
+	}
+	if entries[0].Results[0].Issues[0].Description != "SQL injection" {
+		t.Errorf("results were not stored: %+v", entries[0].Results)
+	}
+}
+
+func TestStore_AddTrimsOldEntries(t *testing.T) {
+	s := Open(t.TempDir())
+	s.SetMaxEntries(2)
+
+	addEntry(t, s, "a", 1)
+	b := addEntry(t, s, "b", 2)
+	c := addEntry(t, s, "c", 3)
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != b.ID || entries[1].ID != c.ID {
+		t.Errorf("List() = %+v, want only the two newest entries", entries)
+	}
+}
+
+func TestStore_PruneByAge(t *testing.T) {
+	s := Open(t.TempDir())
+	addEntry(t, s, "old", 1)
+	recent := Entry{Time: time.Now().Add(-time.Hour), DiffHash: HashDiff("recent")}
+	if err := s.Add(&recent); err != nil {
+		t.Fatalf("Add() failed: %v", err)
+	}
+
+	if pruned, err := s.Prune(); err != nil || pruned != 0 {
+		t.Fatalf("Prune() without limits = %d, %v; want nothing pruned", pruned, err)
+	}
+	s.SetMaxAge(24 * time.Hour)
+	pruned, err := s.Prune()
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	entries, _ := s.List()
+	if pruned != 1 || len(entries) != 1 || entries[0].ID != recent.ID {
+		t.Errorf("Prune() = %d leaving %+v, want only the recent entry", pruned, entries)
+	}
+}
+
+func TestStore_PruneBySize(t *testing.T) {
+	dir := t.TempDir()
+	s := Open(dir)
+	addEntry(t, s, "a", 1)
+	addEntry(t, s, "b", 2)
+	c := addEntry(t, s, "c", 3)
+
+	info, err := os.Stat(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("failed to stat history: %v", err)
+	}
+	// Room for a bit more than one entry keeps only the newest
+	s.SetMaxSize(info.Size()/3 + 10)
+	if pruned, err := s.Prune(); err != nil || pruned != 2 {
+		t.Fatalf("Prune() = %d, %v; want 2 pruned", pruned, err)
+	}
+	entries, _ := s.List()
+	if len(entries) != 1 || entries[0].ID != c.ID {
+		t.Errorf("List() = %+v, want only the newest entry", entries)
+	}
+
+	// The newest run is kept even when it alone exceeds the limit
+	s.SetMaxSize(1)
+	if pruned, _ := s.Prune(); pruned != 0 {
+		t.Errorf("Prune() dropped the newest entry")
+	}
+}
+
+func TestStore_ListSkipsCorruptLines(t *testing.T) {
+	dir := t.TempDir()
+	s := Open(dir)
+	e := addEntry(t, s, "a", 1)
+
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("{not json\n")
+	_ = f.Close()
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != e.ID {
+		t.Errorf("List() = %+v, want only the valid entry", entries)
+	}
+}
+
+// =============================================================================
+// Tests for Get and LinkCommit
+// =============================================================================
+
+func TestStore_Get(t *testing.T) {
+	s := Open(t.TempDir())
+	e := addEntry(t, s, "a", 1)
+
+	got, err := s.Get(e.ID[:4])
+	if err != nil {
+		t.Fatalf("Get() by prefix failed: %v", err)
+	}
+	if got.ID != e.ID {
+		t.Errorf("Get() = %s, want %s", got.ID, e.ID)
+	}
+
+	if _, err := s.Get("zzzz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(unknown) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Get(""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(\"\") error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_LinkCommit(t *testing.T) {
+	s := Open(t.TempDir())
+	older := addEntry(t, s, "same diff", 1)
+	newer := addEntry(t, s, "same diff", 2)
+	addEntry(t, s, "other diff", 3)
+
+	linked, err := s.LinkCommit(HashDiff("same diff"), "abc123")
+	if err != nil || !linked {
+		t.Fatalf("LinkCommit() = %v, %v; want true, nil", linked, err)
+	}
+	if got, _ := s.Get(newer.ID); got.Commit != "abc123" {
+		t.Errorf("newest matching entry commit = %q, want abc123", got.Commit)
+	}
+	if got, _ := s.Get(older.ID); got.Commit != "" {
+		t.Errorf("older entry should not be linked, got %q", got.Commit)
+	}
+
+	if linked, _ := s.LinkCommit(HashDiff("unknown"), "def456"); linked {
+		t.Error("LinkCommit() should report false when no entry matches")
+	}
+}
@@ -0,0 +1,127 @@
+// Package hook installs and removes the git hooks that run revi from
+// "git commit": a pre-commit hook that reviews the staged changes and a
+// prepare-commit-msg hook that fills in a generated commit message.
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hook names managed by revi.
+const (
+	PreCommit        = "pre-commit"
+	PrepareCommitMsg = "prepare-commit-msg"
+)
+
+// Marker identifies hook scripts written by revi. Hooks without it are never
+// overwritten or removed unless forced.
+const Marker = "# Installed by revi"
+
+// ErrForeignHook is returned when a hook exists that was not installed by revi.
+var ErrForeignHook = errors.New("hook exists and was not installed by revi")
+
+// Names returns the hooks revi installs.
+func Names() []string {
+	return []string{PreCommit, PrepareCommitMsg}
+}
+
+// Script returns the shell script for the named hook. The script hands its
+// arguments to "revi hook run <name>".
+func Script(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s. Remove with: revi hook uninstall
+exec revi hook run %s "$@"
+`, Marker, name)
+}
+
+// IsRevi reports whether the hook script at path was installed by revi.
+func IsRevi(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(data), Marker), nil
+}
+
+// Install writes revi's hooks into dir, creating it if needed, and returns the
+// paths written. Existing revi hooks are replaced. Other existing hooks cause an
+// ErrForeignHook error unless force is set, in which case they are renamed with
+// a .bak suffix first. No hook is written if any would fail this check.
+func Install(dir string, force bool) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	// Check every hook before writing any, so a refusal leaves dir unchanged
+	var foreign []string
+	for _, name := range Names() {
+		path := filepath.Join(dir, name)
+		ours, err := IsRevi(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s hook: %w", name, err)
+		}
+		if !ours {
+			foreign = append(foreign, path)
+		}
+	}
+	if len(foreign) > 0 && !force {
+		return nil, fmt.Errorf("%w: %s (use --force to back it up and replace it)", ErrForeignHook, strings.Join(foreign, ", "))
+	}
+	for _, path := range foreign {
+		if err := os.Rename(path, path+".bak"); err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+
+	var written []string
+	for _, name := range Names() {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(Script(name)), 0755); err != nil {
+			return written, fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0755); err != nil {
+			return written, fmt.Errorf("failed to make %s hook executable: %w", name, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// Uninstall removes revi's hooks from dir and returns the paths removed.
+// Hooks not installed by revi are left alone.
+func Uninstall(dir string) ([]string, error) {
+	var removed []string
+	for _, name := range Names() {
+		path := filepath.Join(dir, name)
+		ours, err := IsRevi(path)
+		if errors.Is(err, os.ErrNotExist) || (err == nil && !ours) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to read %s hook: %w", name, err)
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s hook: %w", name, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// PrepareMessage returns the content of a commit message file after adding
+// message in front of the file's existing content (git's comment template).
+func PrepareMessage(existing, message string) string {
+	message = strings.TrimRight(message, "\n")
+	if strings.TrimSpace(existing) == "" {
+		return message + "\n"
+	}
+	return message + "\n\n" + existing
+}
@@ -0,0 +1,106 @@
+package hook
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Tests for Install and Uninstall
+// =============================================================================
+
+func TestInstall_WritesExecutableHooks(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+
+	written, err := Install(dir, false)
+	if err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	if len(written) != len(Names()) {
+		t.Fatalf("Install() wrote %d hooks, want %d", len(written), len(Names()))
+	}
+
+	for _, name := range Names() {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if !strings.Contains(string(data), "revi hook run "+name) {
+			t.Errorf("%s hook should run revi, got:\n%s", name, data)
+		}
+		info, _ := os.Stat(path)
+		if info.Mode().Perm()&0100 == 0 {
+			t.Errorf("%s hook is not executable: %v", name, info.Mode())
+		}
+	}
+
+	// Reinstalling replaces revi's own hooks
+	if _, err := Install(dir, false); err != nil {
+		t.Errorf("reinstall failed: %v", err)
+	}
+}
+
+func TestInstall_RefusesForeignHook(t *testing.T) {
+	dir := t.TempDir()
+	foreign := filepath.Join(dir, PreCommit)
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	if _, err := Install(dir, false); !errors.Is(err, ErrForeignHook) {
+		t.Fatalf("expected ErrForeignHook, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, PrepareCommitMsg)); !os.IsNotExist(err) {
+		t.Error("no hook should be written when installation is refused")
+	}
+
+	if _, err := Install(dir, true); err != nil {
+		t.Fatalf("Install(force) failed: %v", err)
+	}
+	backup, err := os.ReadFile(foreign + ".bak")
+	if err != nil || !strings.Contains(string(backup), "make lint") {
+		t.Errorf("expected the foreign hook to be backed up, got %q (%v)", backup, err)
+	}
+}
+
+func TestUninstall_RemovesOnlyReviHooks(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Install(dir, false); err != nil {
+		t.Fatalf("Install() failed: %v", err)
+	}
+	foreign := filepath.Join(dir, PreCommit)
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	removed, err := Uninstall(dir)
+	if err != nil {
+		t.Fatalf("Uninstall() failed: %v", err)
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != PrepareCommitMsg {
+		t.Errorf("Uninstall() removed %v, want only %s", removed, PrepareCommitMsg)
+	}
+	if _, err := os.Stat(foreign); err != nil {
+		t.Errorf("foreign hook should be kept: %v", err)
+	}
+}
+
+// =============================================================================
+// Tests for PrepareMessage
+// =============================================================================
+
+func TestPrepareMessage(t *testing.T) {
+	if got := PrepareMessage("", "feat: add hooks\n"); got != "feat: add hooks\n" {
+		t.Errorf("PrepareMessage(empty) = %q", got)
+	}
+
+	template := "# Please enter the commit message\n"
+	got := PrepareMessage(template, "feat: add hooks")
+	if got != "feat: add hooks\n\n"+template {
+		t.Errorf("PrepareMessage(template) = %q", got)
+	}
+}
@@ -0,0 +1,209 @@
+// Package lint runs the project's linters, such as go vet or staticcheck, on
+// the files a change touches and parses what they report, so that reviews
+// build on diagnostics a tool has verified.
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// outputLines is the number of trailing output lines of a failed linter
+// included in its error
+const outputLines = 5
+
+// Finding is a diagnostic reported by a linter
+type Finding struct {
+	Linter  string // Name of the linter, e.g. "go vet"
+	Path    string // Slash-separated path relative to the repository root
+	Line    int
+	Column  int // 0 if the linter reported none
+	Message string
+}
+
+// Issue returns the finding as a low-severity review issue
+func (f Finding) Issue() review.Issue {
+	return review.Issue{
+		Severity:    review.SeverityLow,
+		Description: fmt.Sprintf("%s (%s)", f.Message, f.Linter),
+		Location:    fmt.Sprintf("%s:%d", f.Path, f.Line),
+	}
+}
+
+// Issues returns findings as review issues
+func Issues(findings []Finding) []review.Issue {
+	issues := make([]review.Issue, len(findings))
+	for i, f := range findings {
+		issues[i] = f.Issue()
+	}
+	return issues
+}
+
+// Name returns the name of the linter command runs: its words up to the
+// first argument, such as "go vet" for "go vet {dirs}"
+func Name(command string) string {
+	var words []string
+	for _, word := range strings.Fields(command) {
+		if strings.ContainsAny(word[:1], "-./{'\"$") {
+			break
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return strings.TrimSpace(command)
+	}
+	return strings.Join(words, " ")
+}
+
+// Expand returns command with {files} replaced by files and {dirs} by their
+// directories, as ./dir, so that package-based linters such as go vet can be
+// given the packages that changed. Paths are quoted for the system shell.
+func Expand(command string, files []string) string {
+	var quoted, dirs []string
+	for _, file := range files {
+		quoted = append(quoted, quote(file))
+		dir := "./" + path.Dir(file)
+		if dir == "./." {
+			dir = "."
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for i, dir := range dirs {
+		dirs[i] = quote(dir)
+	}
+	command = strings.ReplaceAll(command, "{files}", strings.Join(quoted, " "))
+	return strings.ReplaceAll(command, "{dirs}", strings.Join(dirs, " "))
+}
+
+// shellSafe matches the paths that need no quoting
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./@+-]+$`)
+
+// quote returns s quoted for the system shell if it needs to be
+func quote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + s + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// diagnostic matches the file:line[:column]: message lines that go vet,
+// staticcheck and most other linters print
+var diagnostic = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?:\s*(.+)$`)
+
+// Parse returns the diagnostics linter printed in output. Paths are made
+// relative to root, where the linter ran.
+func Parse(linter, root, output string) []Finding {
+	var findings []Finding
+	for _, line := range strings.Split(output, "\n") {
+		m := diagnostic.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		p := m[1]
+		if filepath.IsAbs(p) {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				continue
+			}
+			p = rel
+		}
+		p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+		lineNo, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		findings = append(findings, Finding{Linter: linter, Path: p, Line: lineNo, Column: column, Message: m[4]})
+	}
+	return findings
+}
+
+// Run runs each of linters with the system shell in root, expanded for files,
+// and returns what they report on files, ordered by path and line. Linters
+// usually fail when they report something; one that fails without reporting
+// anything, or runs longer than timeout, is returned as an error instead.
+// A timeout of zero or less means no limit.
+func Run(ctx context.Context, root string, linters, files []string, timeout time.Duration) ([]Finding, []error) {
+	changed := make(map[string]bool, len(files))
+	for _, file := range files {
+		changed[file] = true
+	}
+
+	var findings []Finding
+	var errs []error
+	for _, command := range linters {
+		name := Name(command)
+		output, err := run(ctx, root, Expand(command, files), timeout)
+		reported := Parse(name, root, output)
+		if err != nil && len(reported) == 0 {
+			if tail := lastLines(output, outputLines); tail != "" {
+				err = fmt.Errorf("%w\n%s", err, tail)
+			}
+			errs = append(errs, fmt.Errorf("linter %q failed: %w", name, err))
+			continue
+		}
+		for _, f := range reported {
+			if changed[f.Path] {
+				findings = append(findings, f)
+			}
+		}
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	return findings, errs
+}
+
+// run runs command with the system shell in dir and returns its combined output
+func run(ctx context.Context, dir, command string, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Processes started by the shell may hold the output open after it is
+	// killed, so stop waiting for them shortly after
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output.String(), fmt.Errorf("timed out after %s", timeout)
+	}
+	return output.String(), err
+}
+
+// lastLines returns the last n lines of s, ignoring trailing newlines
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,99 @@
+package lint
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestName(t *testing.T) {
+	tests := map[string]string{
+		"go vet {dirs}":                   "go vet",
+		"staticcheck ./...":               "staticcheck",
+		"golangci-lint run --fast {dirs}": "golangci-lint run",
+		"./scripts/lint.sh":               "./scripts/lint.sh",
+	}
+	for command, want := range tests {
+		if got := Name(command); got != want {
+			t.Errorf("Name(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	files := []string{"main.go", "internal/cli/review.go", "internal/cli/lint.go", "docs/my notes.md"}
+
+	if got, want := Expand("go vet {dirs}", files), "go vet . ./internal/cli ./docs"; got != want {
+		t.Errorf("Expand({dirs}) = %q, want %q", got, want)
+	}
+	want := "eslint main.go internal/cli/review.go internal/cli/lint.go 'docs/my notes.md'"
+	if runtime.GOOS == "windows" {
+		want = `eslint main.go internal/cli/review.go internal/cli/lint.go "docs/my notes.md"`
+	}
+	if got := Expand("eslint {files}", files); got != want {
+		t.Errorf("Expand({files}) = %q, want %q", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	root := t.TempDir()
+	output := strings.Join([]string{
+		"# github.com/buker/revi/internal/cli",
+		"internal/cli/review.go:12:2: unreachable code",
+		"./main.go:3: result of fmt.Sprintf call not used",
+		filepath.Join(root, "internal", "lint", "lint.go") + ":40:9: should omit nil check (S1031)",
+		"exit status 1",
+	}, "\n")
+
+	got := Parse("go vet", root, output)
+	want := []Finding{
+		{Linter: "go vet", Path: "internal/cli/review.go", Line: 12, Column: 2, Message: "unreachable code"},
+		{Linter: "go vet", Path: "main.go", Line: 3, Message: "result of fmt.Sprintf call not used"},
+		{Linter: "go vet", Path: "internal/lint/lint.go", Line: 40, Column: 9, Message: "should omit nil check (S1031)"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFinding_Issue(t *testing.T) {
+	issue := Finding{Linter: "staticcheck", Path: "main.go", Line: 7, Column: 2, Message: "unused variable x"}.Issue()
+	if issue.Severity != review.SeverityLow || issue.Location != "main.go:7" || issue.Description != "unused variable x (staticcheck)" {
+		t.Errorf("Issue() = %+v", issue)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("linters in this test use sh syntax")
+	}
+	dir := t.TempDir()
+	linters := []string{
+		"echo 'b.go:9:1: second'; echo 'a.go:2:5: first'; echo 'other.go:1:1: not changed'; exit 1",
+		"echo 'config file not found'; exit 3",
+		"echo files: {files}",
+	}
+
+	findings, errs := Run(context.Background(), dir, linters, []string{"a.go", "b.go"}, 0)
+	if len(findings) != 2 || findings[0].Path != "a.go" || findings[1].Path != "b.go" || findings[0].Linter != "echo" {
+		t.Errorf("Run() findings = %+v, want those on a.go and b.go in order", findings)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "config file not found") {
+		t.Errorf("Run() errors = %v, want the failure of the second linter with its output", errs)
+	}
+
+	_, errs = Run(context.Background(), dir, []string{"sleep 5"}, []string{"a.go"}, 50*time.Millisecond)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "timed out") {
+		t.Errorf("Run() errors = %v, want a timeout", errs)
+	}
+}
@@ -0,0 +1,176 @@
+// Package lock provides a per-repository lock file that prevents concurrent
+// revi runs from applying fixes or creating commits at the same time.
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the name of the lock file created in the repository's git directory.
+const FileName = "revi.lock"
+
+// StaleAfter is how long a lock may be held before it is considered abandoned,
+// even if the process that created it appears to be running.
+const StaleAfter = 2 * time.Hour
+
+// ErrLocked is returned when another revi run holds the lock.
+var ErrLocked = errors.New("another revi run is in progress")
+
+// Info describes the process holding a lock.
+type Info struct {
+	PID     int       `json:"pid"`     // Process ID of the holder
+	Host    string    `json:"host"`    // Hostname of the holder
+	Started time.Time `json:"started"` // When the lock was acquired
+}
+
+// LockedError reports which process holds the lock. It matches ErrLocked with errors.Is.
+type LockedError struct {
+	Path   string // Path of the lock file
+	Holder Info   // Process holding the lock
+}
+
+// Error returns a message explaining who holds the lock and how to remove it.
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s (pid %d on %s since %s); if it is not running, remove %s or use --force-unlock",
+		ErrLocked, e.Holder.PID, e.Holder.Host, e.Holder.Started.Format(time.Kitchen), e.Path)
+}
+
+// Is reports whether target is ErrLocked.
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// Lock is a held lock file. Call Release when done.
+type Lock struct {
+	path string
+}
+
+// Acquire creates the lock file in dir. If the file already exists and its
+// holder is no longer running (or has held it longer than StaleAfter), the stale
+// lock is replaced. Otherwise a *LockedError is returned.
+func Acquire(dir string) (*Lock, error) {
+	path := filepath.Join(dir, FileName)
+
+	// Retry once after removing a stale lock
+	for attempt := 0; attempt < 2; attempt++ {
+		err := create(path)
+		if err == nil {
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		holder, stale := readHolder(path)
+		if !stale {
+			return nil, &LockedError{Path: path, Holder: holder}
+		}
+		if err := removeStale(path); err != nil {
+			return nil, err
+		}
+	}
+
+	holder, _ := readHolder(path)
+	return nil, &LockedError{Path: path, Holder: holder}
+}
+
+// removeStale removes the stale lock file at path. Runs that found the same
+// stale lock race to remove it, and a plain remove could delete the lock the
+// winner has created since. So the lock is first moved aside under a name of
+// its own, which only one run can do, and is checked again there: if it turns
+// out to be a live lock, it is put back unless another run has taken the lock
+// in the meantime. It is not an error if the lock is already gone.
+func removeStale(path string) error {
+	aside := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove stale lock file: %w", err)
+	}
+
+	if _, stale := readHolder(aside); !stale {
+		// Link fails if the path exists, so a newer lock is never replaced
+		_ = os.Link(aside, path)
+	}
+	if err := os.Remove(aside); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale lock file: %w", err)
+	}
+	return nil
+}
+
+// ForceUnlock removes the lock file in dir regardless of who holds it.
+// It is not an error if no lock exists.
+func ForceUnlock(dir string) error {
+	err := os.Remove(filepath.Join(dir, FileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	err := os.Remove(l.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// create atomically creates the lock file and records the current process in it
+func create(path string) (err error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	host, _ := os.Hostname()
+	return json.NewEncoder(file).Encode(Info{
+		PID:     os.Getpid(),
+		Host:    host,
+		Started: time.Now(),
+	})
+}
+
+// readHolder reads the lock file and reports whether the lock is stale
+func readHolder(path string) (Info, bool) {
+	var holder Info
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Removed in the meantime; let the caller retry
+		return holder, errors.Is(err, os.ErrNotExist)
+	}
+
+	if err := json.Unmarshal(data, &holder); err != nil {
+		// The holder may still be writing the file, so only treat an unreadable
+		// lock as stale once it has been around for a while
+		info, statErr := os.Stat(path)
+		return holder, statErr == nil && time.Since(info.ModTime()) > time.Minute
+	}
+
+	return holder, isStale(holder)
+}
+
+// isStale reports whether a lock holder has exited or held the lock too long
+func isStale(holder Info) bool {
+	if time.Since(holder.Started) > StaleAfter {
+		return true
+	}
+	host, _ := os.Hostname()
+	if holder.Host != host {
+		// Cannot check processes on other machines (e.g. shared network drives)
+		return false
+	}
+	return !processAlive(holder.PID)
+}
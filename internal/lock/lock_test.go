@@ -0,0 +1,167 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeHolder writes a lock file for the given holder
+func writeHolder(t *testing.T, dir string, holder Info) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatalf("failed to marshal holder: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), data, 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+}
+
+// =============================================================================
+// Tests for Acquire and Release
+// =============================================================================
+
+func TestAcquire_CreatesAndReleasesLock(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("expected lock file to exist: %v", err)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("expected lock file to be removed")
+	}
+}
+
+func TestAcquire_FailsWhileHeld(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	defer func() { _ = l.Release() }()
+
+	_, err = Acquire(dir)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) || lockedErr.Holder.PID != os.Getpid() {
+		t.Errorf("expected LockedError naming this process, got %v", err)
+	}
+}
+
+func TestAcquire_ReplacesLockOfExitedProcess(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	// PIDs are positive, so -1 never refers to a running process
+	writeHolder(t, dir, Info{PID: -1, Host: host, Started: time.Now()})
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("expected stale lock to be replaced, got %v", err)
+	}
+	defer func() { _ = l.Release() }()
+}
+
+func TestAcquire_LeavesNoStaleLockBehind(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	writeHolder(t, dir, Info{PID: -1, Host: host, Started: time.Now()})
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("expected stale lock to be replaced, got %v", err)
+	}
+	defer func() { _ = l.Release() }()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != FileName {
+		t.Errorf("dir holds %v, want only %s", entries, FileName)
+	}
+}
+
+func TestRemoveStale_PutsBackLiveLock(t *testing.T) {
+	// Another run replaced the stale lock between reading and removing it
+	dir := t.TempDir()
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	defer func() { _ = l.Release() }()
+
+	if err := removeStale(filepath.Join(dir, FileName)); err != nil {
+		t.Fatalf("removeStale() failed: %v", err)
+	}
+	if _, err := Acquire(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected the live lock to be kept, got %v", err)
+	}
+}
+
+func TestRemoveStale_NoLock(t *testing.T) {
+	if err := removeStale(filepath.Join(t.TempDir(), FileName)); err != nil {
+		t.Errorf("removeStale() = %v, want nil when the lock is already gone", err)
+	}
+}
+
+func TestAcquire_ReplacesExpiredLock(t *testing.T) {
+	dir := t.TempDir()
+	writeHolder(t, dir, Info{PID: os.Getpid(), Host: "other-host", Started: time.Now().Add(-StaleAfter - time.Minute)})
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("expected expired lock to be replaced, got %v", err)
+	}
+	defer func() { _ = l.Release() }()
+}
+
+func TestAcquire_KeepsLockFromOtherHost(t *testing.T) {
+	dir := t.TempDir()
+	writeHolder(t, dir, Info{PID: -1, Host: "other-host", Started: time.Now()})
+
+	if _, err := Acquire(dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked for a recent lock from another host, got %v", err)
+	}
+}
+
+// =============================================================================
+// Tests for ForceUnlock
+// =============================================================================
+
+func TestForceUnlock_RemovesHeldLock(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Acquire(dir); err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	if err := ForceUnlock(dir); err != nil {
+		t.Fatalf("ForceUnlock() failed: %v", err)
+	}
+
+	l, err := Acquire(dir)
+	if err != nil {
+		t.Fatalf("expected Acquire() to succeed after ForceUnlock, got %v", err)
+	}
+	defer func() { _ = l.Release() }()
+}
+
+func TestForceUnlock_NoLock(t *testing.T) {
+	if err := ForceUnlock(t.TempDir()); err != nil {
+		t.Errorf("ForceUnlock() without a lock should succeed, got %v", err)
+	}
+}
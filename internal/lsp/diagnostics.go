@@ -0,0 +1,129 @@
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// diagnosticSource names revi as the source of its diagnostics
+const diagnosticSource = "revi"
+
+// maxTitleLength is the length past which code action titles are cut short
+const maxTitleLength = 60
+
+// finding is an issue found in a file, with the diagnostic showing it
+type finding struct {
+	issue      review.Issue
+	diagnostic Diagnostic
+}
+
+// findings returns the issues of results by the URI of their file, resolving
+// paths against root. Issues without a file are left out, since editors can
+// only show diagnostics on documents.
+func findings(root string, results []*review.Result) map[string][]finding {
+	byURI := make(map[string][]finding)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, issue := range r.Issues {
+			file, line := issue.FileLine()
+			if file == "" {
+				continue
+			}
+			uri := PathToURI(resolve(root, file))
+			byURI[uri] = append(byURI[uri], finding{issue: issue, diagnostic: issueDiagnostic(r.Mode, issue, line)})
+		}
+	}
+	return byURI
+}
+
+// issueDiagnostic returns the diagnostic showing issue on its line. Issues
+// without a line are shown on the first.
+func issueDiagnostic(mode review.Mode, issue review.Issue, line int) Diagnostic {
+	start := max(line-1, 0)
+	return Diagnostic{
+		Range:    Range{Start: Position{Line: start}, End: Position{Line: start + 1}},
+		Severity: diagnosticSeverity(issue),
+		Code:     string(mode),
+		Source:   diagnosticSource,
+		Message:  issue.Description,
+	}
+}
+
+// diagnosticSeverity maps an issue's severity to a diagnostic's. Issues
+// recorded in the baseline are hints, as they do not block.
+func diagnosticSeverity(issue review.Issue) int {
+	if issue.Baseline {
+		return SeverityHint
+	}
+	switch issue.Severity {
+	case review.SeverityHigh:
+		return SeverityError
+	case review.SeverityMedium:
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+// fixAction returns the quick fix applying the fix of f, replacing the lines
+// it covers. ok is false if the issue has no fix that can be applied.
+func fixAction(root string, f finding) (action CodeAction, ok bool) {
+	fix := f.issue.Fix
+	if fix == nil || !fix.Available || fix.StartLine < 1 || fix.EndLine < fix.StartLine {
+		return CodeAction{}, false
+	}
+	path := fix.FilePath
+	if path == "" {
+		path, _ = f.issue.FileLine()
+	}
+	if path == "" {
+		return CodeAction{}, false
+	}
+
+	code := fix.Code
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	edit := TextEdit{
+		Range:   Range{Start: Position{Line: fix.StartLine - 1}, End: Position{Line: fix.EndLine}},
+		NewText: code,
+	}
+	return CodeAction{
+		Title:       "Apply revi fix: " + shorten(f.issue.Description),
+		Kind:        "quickfix",
+		Diagnostics: []Diagnostic{f.diagnostic},
+		IsPreferred: true,
+		Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{PathToURI(resolve(root, path)): {edit}}},
+	}, true
+}
+
+// overlaps returns true if the diagnostic's lines meet those of r
+func overlaps(d Diagnostic, r Range) bool {
+	last := r.End.Line
+	if r.End.Character == 0 && r.End.Line > r.Start.Line {
+		last--
+	}
+	return d.Range.Start.Line <= last && r.Start.Line < d.Range.End.Line
+}
+
+// resolve returns path joined to root unless it is absolute
+func resolve(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// shorten returns the first line of s, cut to maxTitleLength runes
+func shorten(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(s); len(r) > maxTitleLength {
+		return fmt.Sprintf("%s...", string(r[:maxTitleLength-3]))
+	}
+	return s
+}
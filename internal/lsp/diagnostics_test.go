@@ -0,0 +1,110 @@
+package lsp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestPathToURI_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dir with space", "main.go")
+	uri := PathToURI(path)
+	if got := URIToPath(uri); got != path {
+		t.Errorf("URIToPath(%q) = %q, want %q", uri, got, path)
+	}
+	if URIToPath("untitled:Untitled-1") != "" {
+		t.Error("URIToPath() should return \"\" for URIs other than files")
+	}
+}
+
+func TestFindings_GroupsIssuesByFile(t *testing.T) {
+	root := t.TempDir()
+	results := []*review.Result{
+		{Mode: review.ModeSecurity, Issues: []review.Issue{
+			{Severity: review.SeverityHigh, Description: "SQL injection", Location: "db/query.go:12"},
+			{Severity: review.SeverityLow, Description: "No location"},
+		}},
+		{Mode: review.ModeStyle, Issues: []review.Issue{
+			{Severity: review.SeverityMedium, Description: "Unclear name", Location: "db/query.go"},
+			{Severity: review.SeverityHigh, Description: "Known issue", Location: "main.go:3", Baseline: true},
+		}},
+		nil,
+	}
+
+	got := findings(root, results)
+	if len(got) != 2 {
+		t.Fatalf("findings() has %d files, want 2", len(got))
+	}
+	query := got[PathToURI(filepath.Join(root, "db", "query.go"))]
+	if len(query) != 2 {
+		t.Fatalf("db/query.go has %d findings, want 2", len(query))
+	}
+	d := query[0].diagnostic
+	if d.Range.Start.Line != 11 || d.Range.End.Line != 12 || d.Severity != SeverityError || d.Code != "security" || d.Source != "revi" || d.Message != "SQL injection" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+	if d := query[1].diagnostic; d.Range.Start.Line != 0 || d.Severity != SeverityWarning {
+		t.Errorf("issue without a line: diagnostic = %+v, want a warning on the first line", d)
+	}
+	if d := got[PathToURI(filepath.Join(root, "main.go"))][0].diagnostic; d.Severity != SeverityHint {
+		t.Errorf("baseline issue severity = %d, want a hint", d.Severity)
+	}
+}
+
+func TestFixAction(t *testing.T) {
+	root := t.TempDir()
+	issue := review.Issue{
+		Severity:    review.SeverityHigh,
+		Description: "SQL injection",
+		Location:    "db/query.go:12",
+		Fix:         &review.Fix{Available: true, Code: "db.Query(q, id)", StartLine: 12, EndLine: 13},
+	}
+	f := finding{issue: issue, diagnostic: issueDiagnostic(review.ModeSecurity, issue, 12)}
+
+	action, ok := fixAction(root, f)
+	if !ok {
+		t.Fatal("fixAction() should return an action for an available fix")
+	}
+	if action.Kind != "quickfix" || action.Title != "Apply revi fix: SQL injection" || !action.IsPreferred {
+		t.Errorf("action = %+v", action)
+	}
+	edits := action.Edit.Changes[PathToURI(filepath.Join(root, "db", "query.go"))]
+	want := TextEdit{Range: Range{Start: Position{Line: 11}, End: Position{Line: 13}}, NewText: "db.Query(q, id)\n"}
+	if len(edits) != 1 || edits[0] != want {
+		t.Errorf("edits = %+v, want %+v", edits, want)
+	}
+
+	f.issue.Fix = &review.Fix{Available: false, Reason: "needs a schema change"}
+	if _, ok := fixAction(root, f); ok {
+		t.Error("fixAction() should not return an action for an unavailable fix")
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	d := Diagnostic{Range: Range{Start: Position{Line: 4}, End: Position{Line: 5}}}
+	tests := []struct {
+		r    Range
+		want bool
+	}{
+		{Range{Start: Position{Line: 4, Character: 3}, End: Position{Line: 4, Character: 3}}, true},
+		{Range{Start: Position{Line: 2}, End: Position{Line: 8}}, true},
+		{Range{Start: Position{Line: 3}, End: Position{Line: 4}}, false},
+		{Range{Start: Position{Line: 5}, End: Position{Line: 6}}, false},
+	}
+	for _, tt := range tests {
+		if got := overlaps(d, tt.r); got != tt.want {
+			t.Errorf("overlaps(%+v) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestShorten(t *testing.T) {
+	if got := shorten("first line\nsecond"); got != "first line" {
+		t.Errorf("shorten() = %q, want the first line", got)
+	}
+	long := "a very long description of an issue that goes on and on past the limit"
+	if got := []rune(shorten(long)); len(got) != maxTitleLength || string(got[len(got)-3:]) != "..." {
+		t.Errorf("shorten() = %q, want %d runes ending with ...", string(got), maxTitleLength)
+	}
+}
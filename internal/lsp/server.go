@@ -0,0 +1,280 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends exit
+// without asking the server to shut down first.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+// Reviewer reviews the repository at root and returns the results. Issue
+// locations are relative to root.
+type Reviewer func(ctx context.Context, root string) ([]*review.Result, error)
+
+// Server is a language server publishing the issues of revi's reviews as
+// diagnostics. A review runs when the client is initialized, each time a
+// document is saved, and when the revi.review command is executed; saves
+// made while a review runs start one more review once it is done.
+type Server struct {
+	review      Reviewer
+	resolveRoot func(dir string) (string, error)
+	version     string
+
+	conn     *conn
+	root     string
+	shutdown bool
+
+	mu        sync.Mutex // Guards the fields below, shared with the review goroutine
+	findings  map[string][]finding
+	edited    map[string]bool // Documents changed since the last review
+	reviewing bool
+	pending   bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server running reviews with review in root, unless the
+// client names another workspace root
+func NewServer(review Reviewer, root string) *Server {
+	return &Server{
+		review:      review,
+		resolveRoot: func(dir string) (string, error) { return dir, nil },
+		root:        root,
+		findings:    make(map[string][]finding),
+		edited:      make(map[string]bool),
+	}
+}
+
+// SetRootResolver sets the function mapping the workspace root the client
+// names to the root reviews run in, such as the top of its repository
+func (s *Server) SetRootResolver(resolve func(dir string) (string, error)) {
+	s.resolveRoot = resolve
+}
+
+// SetVersion sets the version reported to the client
+func (s *Server) SetVersion(version string) {
+	s.version = version
+}
+
+// Serve reads requests from in and writes responses and notifications to out
+// until the client sends exit, in closes or ctx is cancelled. Reviews still
+// running are cancelled before it returns.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+	s.conn = newConn(in, out)
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		body, err := s.conn.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.conn.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(ctx, &req); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a request or acts on a notification. Only failures to write
+// to the client are returned.
+func (s *Server) handle(ctx context.Context, req *request) error {
+	if s.shutdown && !req.isNotification() {
+		return s.conn.replyError(req.ID, codeInvalidRequest, "server is shut down")
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params initializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.conn.replyError(req.ID, codeInvalidParams, err.Error())
+		}
+		dir := URIToPath(params.RootURI)
+		if dir == "" {
+			dir = params.RootPath
+		}
+		if dir != "" {
+			root, err := s.resolveRoot(dir)
+			if err != nil {
+				return s.conn.replyError(req.ID, codeInvalidParams, err.Error())
+			}
+			s.root = root
+		}
+		return s.conn.reply(req.ID, s.initializeResult())
+
+	case "initialized":
+		s.startReview(ctx)
+
+	case "shutdown":
+		s.shutdown = true
+		return s.conn.reply(req.ID, nil)
+
+	case "textDocument/didSave":
+		s.startReview(ctx)
+
+	case "textDocument/didChange":
+		var params textDocumentParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.mu.Lock()
+			s.edited[params.TextDocument.URI] = true
+			s.mu.Unlock()
+		}
+
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.conn.replyError(req.ID, codeInvalidParams, err.Error())
+		}
+		return s.conn.reply(req.ID, s.codeActions(params.TextDocument.URI, params.Range))
+
+	case "workspace/executeCommand":
+		var params executeCommandParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.conn.replyError(req.ID, codeInvalidParams, err.Error())
+		}
+		if params.Command != reviewCommand {
+			return s.conn.replyError(req.ID, codeInvalidParams, "unknown command "+params.Command)
+		}
+		s.startReview(ctx)
+		return s.conn.reply(req.ID, nil)
+
+	default:
+		// Other notifications, such as didOpen and $/cancelRequest, need nothing
+		if !req.isNotification() {
+			return s.conn.replyError(req.ID, codeMethodNotFound, "method not supported: "+req.Method)
+		}
+	}
+	return nil
+}
+
+// initializeResult returns the server's capabilities: documents are followed
+// to review them on save and to know which were edited since, and fixes are
+// offered as quick fixes
+func (s *Server) initializeResult() any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync": map[string]any{
+				"openClose": true,
+				"change":    2, // Incremental; only the fact that a document changed is used
+				"save":      map[string]any{"includeText": false},
+			},
+			"codeActionProvider":     map[string]any{"codeActionKinds": []string{"quickfix"}},
+			"executeCommandProvider": map[string]any{"commands": []string{reviewCommand}},
+		},
+		"serverInfo": map[string]any{"name": "revi", "version": s.version},
+	}
+}
+
+// codeActions returns the fixes of the issues found in the document at uri on
+// the lines of r. Documents edited since the review get none, since the lines
+// the fixes replace may have moved.
+func (s *Server) codeActions(uri string, r Range) []CodeAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := []CodeAction{}
+	if s.edited[uri] {
+		return actions
+	}
+	for _, f := range s.findings[uri] {
+		if !overlaps(f.diagnostic, r) {
+			continue
+		}
+		if action, ok := fixAction(s.root, f); ok {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// startReview runs a review in the background, or has the running one
+// followed by another
+func (s *Server) startReview(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reviewing {
+		s.pending = true
+		return
+	}
+	s.reviewing = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.runReview(ctx)
+
+			s.mu.Lock()
+			if !s.pending || ctx.Err() != nil {
+				s.reviewing = false
+				s.mu.Unlock()
+				return
+			}
+			s.pending = false
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// runReview reviews the repository and publishes the issues found, clearing
+// the diagnostics of documents that no longer have any. Failures are shown to
+// the user and leave the previous diagnostics in place.
+func (s *Server) runReview(ctx context.Context) {
+	_ = s.conn.notify("window/logMessage", messageParams{Type: messageInfo, Message: "revi: reviewing " + s.root})
+	results, err := s.review(ctx, s.root)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		_ = s.conn.notify("window/showMessage", messageParams{Type: messageError, Message: "revi review failed: " + err.Error()})
+		return
+	}
+
+	found := findings(s.root, results)
+	s.mu.Lock()
+	previous := s.findings
+	s.findings = found
+	s.edited = make(map[string]bool)
+	s.mu.Unlock()
+
+	for uri := range previous {
+		if _, ok := found[uri]; !ok {
+			_ = s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}})
+		}
+	}
+	for uri, fs := range found {
+		diagnostics := make([]Diagnostic, len(fs))
+		for i, f := range fs {
+			diagnostics[i] = f.diagnostic
+		}
+		_ = s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	}
+	summary := review.Summarize(results)
+	_ = s.conn.notify("window/logMessage", messageParams{Type: messageInfo, Message: fmt.Sprintf("revi: %d issue(s) found", summary.IssuesFound)})
+}
@@ -0,0 +1,286 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// testClient drives a Server over pipes as an editor would
+type testClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *textproto.Reader
+	nextID int
+	done   chan error
+}
+
+func startServer(t *testing.T, s *Server) *testClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &testClient{t: t, in: inW, out: textproto.NewReader(bufio.NewReader(outR)), done: make(chan error, 1)}
+	go func() {
+		err := s.Serve(context.Background(), inR, outW)
+		_ = outW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() {
+		_ = inW.Close()
+		// Drain what is left so the server is not blocked writing
+		go func() { _, _ = io.Copy(io.Discard, outR) }()
+		<-c.done
+	})
+	return c
+}
+
+func (c *testClient) send(msg map[string]any) {
+	c.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	body, _ := json.Marshal(msg)
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatalf("failed to send: %v", err)
+	}
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+func (c *testClient) request(method string, params any) int {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": method, "params": params})
+	return c.nextID
+}
+
+// message is a message the server sent
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+func (c *testClient) read() message {
+	c.t.Helper()
+	header, err := c.out.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatalf("failed to read header: %v", err)
+	}
+	length, _ := strconv.Atoi(header.Get("Content-Length"))
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.out.R, body); err != nil {
+		c.t.Fatalf("failed to read body: %v", err)
+	}
+	var m message
+	if err := json.Unmarshal(body, &m); err != nil {
+		c.t.Fatalf("invalid message %s: %v", body, err)
+	}
+	return m
+}
+
+// response reads messages until the response to id, skipping notifications
+func (c *testClient) response(id int) message {
+	c.t.Helper()
+	for {
+		m := c.read()
+		if m.ID != nil && *m.ID == id {
+			return m
+		}
+	}
+}
+
+// diagnostics reads messages until n publishDiagnostics notifications are
+// read and returns them by URI
+func (c *testClient) diagnostics(n int) map[string][]Diagnostic {
+	c.t.Helper()
+	published := make(map[string][]Diagnostic)
+	for len(published) < n {
+		m := c.read()
+		if m.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var p PublishDiagnosticsParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			c.t.Fatalf("invalid diagnostics: %v", err)
+		}
+		published[p.URI] = p.Diagnostics
+	}
+	return published
+}
+
+func (c *testClient) initialize(root string) {
+	c.t.Helper()
+	id := c.request("initialize", map[string]any{"rootUri": PathToURI(root)})
+	if m := c.response(id); m.Error != nil {
+		c.t.Fatalf("initialize failed: %s", m.Error.Message)
+	}
+	c.notify("initialized", map[string]any{})
+}
+
+func TestServer_InitializeReportsCapabilities(t *testing.T) {
+	s := NewServer(func(ctx context.Context, root string) ([]*review.Result, error) { return nil, nil }, "")
+	s.SetVersion("1.2.3")
+	c := startServer(t, s)
+
+	id := c.request("initialize", map[string]any{"rootUri": nil})
+	var result struct {
+		Capabilities struct {
+			CodeActionProvider     any `json:"codeActionProvider"`
+			ExecuteCommandProvider struct {
+				Commands []string `json:"commands"`
+			} `json:"executeCommandProvider"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name, Version string
+		} `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(c.response(id).Result, &result); err != nil {
+		t.Fatalf("invalid initialize result: %v", err)
+	}
+	if result.Capabilities.CodeActionProvider == nil || len(result.Capabilities.ExecuteCommandProvider.Commands) != 1 {
+		t.Errorf("capabilities = %+v", result.Capabilities)
+	}
+	if result.ServerInfo.Name != "revi" || result.ServerInfo.Version != "1.2.3" {
+		t.Errorf("serverInfo = %+v", result.ServerInfo)
+	}
+}
+
+func TestServer_PublishesDiagnosticsAndFixes(t *testing.T) {
+	root := t.TempDir()
+	reviews := make(chan []*review.Result, 2)
+	reviews <- []*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{
+		{Severity: review.SeverityHigh, Description: "SQL injection", Location: "db.go:3",
+			Fix: &review.Fix{Available: true, Code: "safe()", StartLine: 3, EndLine: 3}},
+		{Severity: review.SeverityLow, Description: "Long function", Location: "main.go:10"},
+	}}}
+	var reviewedIn string
+	s := NewServer(func(ctx context.Context, dir string) ([]*review.Result, error) {
+		reviewedIn = dir
+		return <-reviews, nil
+	}, "")
+	c := startServer(t, s)
+	c.initialize(root)
+
+	dbURI, mainURI := PathToURI(filepath.Join(root, "db.go")), PathToURI(filepath.Join(root, "main.go"))
+	published := c.diagnostics(2)
+	if reviewedIn != root {
+		t.Errorf("reviewed in %q, want the workspace root %q", reviewedIn, root)
+	}
+	if d := published[dbURI]; len(d) != 1 || d[0].Message != "SQL injection" || d[0].Range.Start.Line != 2 {
+		t.Errorf("diagnostics of db.go = %+v", d)
+	}
+
+	id := c.request("textDocument/codeAction", map[string]any{
+		"textDocument": map[string]any{"uri": dbURI},
+		"range":        Range{Start: Position{Line: 2, Character: 4}, End: Position{Line: 2, Character: 4}},
+		"context":      map[string]any{"diagnostics": []any{}},
+	})
+	var actions []CodeAction
+	if err := json.Unmarshal(c.response(id).Result, &actions); err != nil {
+		t.Fatalf("invalid code actions: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Edit.Changes[dbURI][0].NewText != "safe()\n" {
+		t.Errorf("code actions = %+v, want the fix", actions)
+	}
+
+	// Edited documents get no fixes, since their lines may have moved
+	c.notify("textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": dbURI, "version": 2}})
+	id = c.request("textDocument/codeAction", map[string]any{
+		"textDocument": map[string]any{"uri": dbURI},
+		"range":        Range{Start: Position{Line: 2}, End: Position{Line: 3}},
+	})
+	if got := string(c.response(id).Result); got != "[]" {
+		t.Errorf("code actions after an edit = %s, want []", got)
+	}
+
+	// Saving reviews again, clearing files without issues
+	reviews <- []*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{
+		{Severity: review.SeverityMedium, Description: "Still risky", Location: "db.go:3"},
+	}}}
+	c.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": dbURI}})
+	published = c.diagnostics(2)
+	if d := published[mainURI]; d == nil || len(d) != 0 {
+		t.Errorf("diagnostics of main.go = %+v, want them cleared", d)
+	}
+	if d := published[dbURI]; len(d) != 1 || d[0].Severity != SeverityWarning {
+		t.Errorf("diagnostics of db.go = %+v", d)
+	}
+}
+
+func TestServer_ShowsReviewFailures(t *testing.T) {
+	s := NewServer(func(ctx context.Context, root string) ([]*review.Result, error) {
+		return nil, errors.New("claude CLI not found")
+	}, t.TempDir())
+	c := startServer(t, s)
+	c.notify("initialized", map[string]any{})
+
+	for {
+		m := c.read()
+		if m.Method != "window/showMessage" {
+			continue
+		}
+		var p messageParams
+		_ = json.Unmarshal(m.Params, &p)
+		if p.Type != messageError || p.Message != "revi review failed: claude CLI not found" {
+			t.Errorf("showMessage = %+v", p)
+		}
+		return
+	}
+}
+
+func TestServer_UnknownMethodAndCommand(t *testing.T) {
+	c := startServer(t, NewServer(func(ctx context.Context, root string) ([]*review.Result, error) { return nil, nil }, ""))
+
+	id := c.request("textDocument/hover", map[string]any{})
+	if m := c.response(id); m.Error == nil || m.Error.Code != codeMethodNotFound {
+		t.Errorf("hover response = %+v, want method not found", m)
+	}
+	id = c.request("workspace/executeCommand", map[string]any{"command": "other.command"})
+	if m := c.response(id); m.Error == nil || m.Error.Code != codeInvalidParams {
+		t.Errorf("executeCommand response = %+v, want invalid params", m)
+	}
+}
+
+func TestServer_ShutdownAndExit(t *testing.T) {
+	c := startServer(t, NewServer(func(ctx context.Context, root string) ([]*review.Result, error) { return nil, nil }, ""))
+
+	id := c.request("shutdown", nil)
+	if m := c.response(id); m.Error != nil || string(m.Result) != "null" {
+		t.Errorf("shutdown response = %+v, want a null result", m)
+	}
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			t.Errorf("Serve() = %v, want nil after shutdown", err)
+		}
+		c.done <- err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not return after exit")
+	}
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c := startServer(t, NewServer(func(ctx context.Context, root string) ([]*review.Result, error) { return nil, nil }, ""))
+
+	c.notify("exit", nil)
+	err := <-c.done
+	c.done <- err
+	if !errors.Is(err, ErrExitWithoutShutdown) {
+		t.Errorf("Serve() = %v, want ErrExitWithoutShutdown", err)
+	}
+}
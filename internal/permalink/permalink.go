@@ -0,0 +1,114 @@
+// Package permalink builds links to files and lines on a repository hosting
+// provider (GitHub, GitLab, Bitbucket) so that reports can point readers
+// straight at the code an issue refers to.
+package permalink
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Provider names.
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+)
+
+// Templates holds the link template for each provider. Templates may use the
+// placeholders {repo}, {commit}, {path} and {line}; the part from '#' on is
+// dropped when an issue has no line number.
+var Templates = map[string]string{
+	GitHub:    "{repo}/blob/{commit}/{path}#L{line}",
+	GitLab:    "{repo}/-/blob/{commit}/{path}#L{line}",
+	Bitbucket: "{repo}/src/{commit}/{path}#lines-{line}",
+}
+
+// RepoURL converts a git remote URL (https, ssh:// or scp-like git@host:path)
+// into the https URL of the repository's web page. Returns "" if the remote
+// cannot be converted, e.g. a local path.
+func RepoURL(remote string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), "/")
+	remote = strings.TrimSuffix(remote, ".git")
+
+	// scp-like syntax: git@github.com:owner/repo
+	if !strings.Contains(remote, "://") {
+		userHost, path, ok := strings.Cut(remote, ":")
+		if !ok || path == "" || strings.HasPrefix(path, "/") {
+			return ""
+		}
+		_, host, found := strings.Cut(userHost, "@")
+		if !found {
+			host = userHost
+		}
+		return "https://" + host + "/" + path
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "http", "https", "ssh", "git":
+	default:
+		return ""
+	}
+	return "https://" + u.Hostname() + u.Path
+}
+
+// DetectProvider guesses the hosting provider from a repository URL.
+// Returns "" if the host is not recognized.
+func DetectProvider(repoURL string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, provider := range []string{GitHub, GitLab, Bitbucket} {
+		if strings.Contains(host, provider) {
+			return provider
+		}
+	}
+	return ""
+}
+
+// Builder creates links for one repository at one commit.
+type Builder struct {
+	template string
+	repo     string
+	commit   string
+}
+
+// New returns a Builder that fills template with the repository URL and commit.
+func New(template, repoURL, commit string) *Builder {
+	return &Builder{
+		template: template,
+		repo:     strings.TrimSuffix(repoURL, "/"),
+		commit:   commit,
+	}
+}
+
+// Link returns the URL of line in the file at path. With line 0 the link points
+// to the file as a whole.
+func (b *Builder) Link(path string, line int) string {
+	template := b.template
+	if line <= 0 {
+		template, _, _ = strings.Cut(template, "#")
+	}
+	return strings.NewReplacer(
+		"{repo}", b.repo,
+		"{commit}", b.commit,
+		"{path}", escapePath(strings.TrimPrefix(path, "./")),
+		"{line}", strconv.Itoa(line),
+	).Replace(template)
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a URL
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
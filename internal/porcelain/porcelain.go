@@ -0,0 +1,131 @@
+// Package porcelain implements the line protocol revi speaks with --porcelain,
+// so that other programs, such as git frontends, can run revi as a child
+// process, follow its progress and answer its prompts.
+//
+// Each line revi writes to stdout is a record: a kind followed by its fields,
+// separated by tabs. Backslashes, tabs, newlines and carriage returns within
+// fields are escaped as \\, \t, \n and \r. The first record is always
+// "revi-porcelain" with the protocol version; records and fields may be added
+// within a version, so readers ignore kinds they do not know and fields past
+// the ones they expect. A "prompt" record waits for one line on stdin with
+// the answer; an empty line or the end of input picks the default answer.
+package porcelain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Version is the version of the protocol, incremented on incompatible changes.
+const Version = 1
+
+// Record kinds and their fields, in order.
+const (
+	KindVersion  = "revi-porcelain" // version
+	KindModes    = "modes"          // mode...; the review modes about to run
+	KindProgress = "progress"       // mode, status; a review changed status
+	KindIssue    = "issue"          // mode, severity, file, line, fix ("yes" or "no"), fingerprint, description
+	KindPrompt   = "prompt"         // id, answers ("y/n"; the first is the default), question
+	KindFix      = "fix"            // fingerprint, outcome ("applied", "skipped" or "failed"), detail
+	KindMessage  = "message"        // commit message
+	KindCommit   = "commit"         // hash of the commit created
+	KindResult   = "result"         // outcome, high, medium, low, timed out
+	KindNote     = "note"           // text meant for the user
+	KindError    = "error"          // code, message
+)
+
+// Writer writes records to the parent process. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	out io.Writer
+	in  *bufio.Reader
+}
+
+// NewWriter returns a Writer writing records to out and reading answers to
+// prompts from in, after writing the version record.
+func NewWriter(out io.Writer, in io.Reader) *Writer {
+	w := &Writer{out: out, in: bufio.NewReader(in)}
+	w.Write(KindVersion, fmt.Sprint(Version))
+	return w
+}
+
+// Write writes a record of kind with fields. Write errors are ignored: the
+// parent process has gone away, and revi carries on as it would unattended.
+func (w *Writer) Write(kind string, fields ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = io.WriteString(w.out, FormatLine(kind, fields...))
+}
+
+// Ask writes a prompt record and returns the answer read from the parent,
+// lower-cased, or the first of answers if it gave none.
+func (w *Writer) Ask(id string, answers []string, question string) string {
+	w.Write(KindPrompt, id, strings.Join(answers, "/"), question)
+	line, err := w.in.ReadString('\n')
+	if err != nil && line == "" {
+		return answers[0]
+	}
+	if answer := strings.ToLower(strings.TrimSpace(line)); answer != "" {
+		return answer
+	}
+	return answers[0]
+}
+
+// Confirm asks a yes-or-no question that defaults to no and reports whether
+// the parent answered yes.
+func (w *Writer) Confirm(id, question string) bool {
+	answer := w.Ask(id, []string{"n", "y"}, question)
+	return answer == "y" || answer == "yes"
+}
+
+// FormatLine returns the record of kind with fields as a line.
+func FormatLine(kind string, fields ...string) string {
+	var b strings.Builder
+	b.WriteString(kind)
+	for _, f := range fields {
+		b.WriteByte('\t')
+		b.WriteString(escaper.Replace(f))
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// ParseLine splits a record line into its kind and unescaped fields.
+func ParseLine(line string) (kind string, fields []string) {
+	parts := strings.Split(strings.TrimRight(line, "\r\n"), "\t")
+	for _, p := range parts[1:] {
+		fields = append(fields, unescape(p))
+	}
+	return parts[0], fields
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// unescape reverses the escaping of a field
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package porcelain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormatLine_RoundTrips(t *testing.T) {
+	fields := []string{"security", "line one\nline\ttwo", `C:\path`, ""}
+	line := FormatLine(KindIssue, fields...)
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("FormatLine() = %q, want a single line", line)
+	}
+
+	kind, got := ParseLine(line)
+	if kind != KindIssue {
+		t.Errorf("kind = %q, want %q", kind, KindIssue)
+	}
+	if strings.Join(got, "|") != strings.Join(fields, "|") || len(got) != len(fields) {
+		t.Errorf("fields = %q, want %q", got, fields)
+	}
+}
+
+func TestNewWriter_WritesVersionFirst(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, strings.NewReader(""))
+	w.Write(KindNote, "hello")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "revi-porcelain\t1" || lines[1] != "note\thello" {
+		t.Errorf("output = %q", lines)
+	}
+}
+
+func TestWriter_Ask(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, strings.NewReader("Y\n\nn\n"))
+
+	if !w.Confirm("commit", "Proceed with commit?") {
+		t.Error("Confirm() = false for the answer \"Y\"")
+	}
+	if w.Confirm("stage", "Stage?") {
+		t.Error("Confirm() = true for an empty answer, want the default no")
+	}
+	if got := w.Ask("fix:abc", []string{"y", "n"}, "Apply?"); got != "n" {
+		t.Errorf("Ask() = %q, want \"n\"", got)
+	}
+	// The parent went away: the default answer
+	if got := w.Ask("cost", []string{"n", "y"}, "Start?"); got != "n" {
+		t.Errorf("Ask() at end of input = %q, want \"n\"", got)
+	}
+
+	if !strings.Contains(out.String(), "prompt\tcommit\tn/y\tProceed with commit?\n") {
+		t.Errorf("output has no prompt record for commit:\n%s", out.String())
+	}
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// RepoResults are the results of reviewing one repository of a batch
+type RepoResults struct {
+	Repo    string           // Path of the repository as given
+	Results []*review.Result // Its review results
+	Note    string           // Why it has no results, e.g. "no changes" or an error
+}
+
+// WriteBatchMarkdown writes the results of reviewing several repositories to
+// w: a table with the issues found in each repository, then a section per
+// repository with its review modes as WriteMarkdown writes them.
+func WriteBatchMarkdown(w io.Writer, repos []RepoResults, labels review.SeverityLabels) error {
+	bw := bufio.NewWriter(w)
+
+	var all []*review.Result
+	for _, repo := range repos {
+		all = append(all, repo.Results...)
+	}
+	total := review.Summarize(all)
+
+	fmt.Fprintf(bw, "# Code review: %d repositories\n\n", len(repos))
+	fmt.Fprintf(bw, "%d issue(s) found in %d review(s).\n\n", total.IssuesFound, total.TotalReviews)
+	fmt.Fprintf(bw, "| Repository | %s | %s | %s | Notes |\n",
+		cell(labels.Label(review.SeverityHigh)), cell(labels.Label(review.SeverityMedium)), cell(labels.Label(review.SeverityLow)))
+	fmt.Fprintln(bw, "| --- | ---: | ---: | ---: | --- |")
+	for _, repo := range repos {
+		summary := review.Summarize(repo.Results)
+		fmt.Fprintf(bw, "| %s | %d | %d | %d | %s |\n", code(repo.Repo),
+			summary.HighSeverity, summary.MediumSeverity, summary.LowSeverity, cell(repo.Note))
+	}
+
+	for _, repo := range repos {
+		fmt.Fprintf(bw, "\n## %s\n", code(repo.Repo))
+		if repo.Note != "" {
+			fmt.Fprintf(bw, "\n%s\n", cell(repo.Note))
+		}
+		for _, r := range repo.Results {
+			if r != nil {
+				writeResult(bw, r, labels, 3)
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,179 @@
+// Package report renders review results as documents that can be shared
+// outside the terminal, such as in pull request descriptions or chat.
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// WriteMarkdown writes results to w as a Markdown report: a severity table for
+// the whole run, then a section per review mode with its issues in a table,
+// its fixes in code blocks and its suggestions. source describes what was
+// reviewed, e.g. "staged changes". Severities are shown with labels.
+func WriteMarkdown(w io.Writer, source string, results []*review.Result, labels review.SeverityLabels) error {
+	bw := bufio.NewWriter(w)
+	summary := review.Summarize(results)
+
+	fmt.Fprintf(bw, "# Code review: %s\n\n", source)
+	fmt.Fprintf(bw, "%d issue(s) found in %d review(s).\n\n", summary.IssuesFound, summary.TotalReviews)
+	fmt.Fprintln(bw, "| Severity | Issues |")
+	fmt.Fprintln(bw, "| --- | ---: |")
+	fmt.Fprintf(bw, "| %s | %d |\n", cell(labels.Label(review.SeverityHigh)), summary.HighSeverity)
+	fmt.Fprintf(bw, "| %s | %d |\n", cell(labels.Label(review.SeverityMedium)), summary.MediumSeverity)
+	fmt.Fprintf(bw, "| %s | %d |\n", cell(labels.Label(review.SeverityLow)), summary.LowSeverity)
+	var notes []string
+	if summary.FailedReviews > 0 {
+		notes = append(notes, fmt.Sprintf("%d review(s) failed", summary.FailedReviews))
+	}
+	if summary.TimedOutReviews > 0 {
+		notes = append(notes, fmt.Sprintf("%d review(s) timed out (partial review)", summary.TimedOutReviews))
+	}
+	if summary.Suppressed > 0 {
+		notes = append(notes, fmt.Sprintf("%d issue(s) suppressed", summary.Suppressed))
+	}
+	if summary.Merged > 0 {
+		notes = append(notes, fmt.Sprintf("%d duplicate report(s) merged", summary.Merged))
+	}
+	if summary.Unconfident > 0 {
+		notes = append(notes, fmt.Sprintf("%d low-confidence issue(s) dropped", summary.Unconfident))
+	}
+	if summary.Known > 0 {
+		notes = append(notes, fmt.Sprintf("%d issue(s) already reported by an earlier run", summary.Known))
+	}
+	if summary.BaselineHigh > 0 {
+		notes = append(notes, fmt.Sprintf("%d high-severity issue(s) recorded in the baseline do not block", summary.BaselineHigh))
+	}
+	if len(notes) > 0 {
+		fmt.Fprintf(bw, "\n_%s._\n", strings.Join(notes, ", "))
+	}
+
+	for _, r := range results {
+		if r != nil {
+			writeResult(bw, r, labels, 2)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeResult writes the section for one review mode, under a heading of the
+// given level
+func writeResult(w io.Writer, r *review.Result, labels review.SeverityLabels, level int) {
+	heading := strings.Repeat("#", level)
+	fmt.Fprintf(w, "\n%s %s\n\n", heading, review.GetModeInfo(r.Mode).Name)
+
+	switch {
+	case r.Status == review.StatusFailed:
+		fmt.Fprintf(w, "**Failed:** %s\n", oneLine(r.Error))
+		return
+	case r.Status == review.StatusTimedOut:
+		fmt.Fprintln(w, "**Timed out** before finishing (partial review).")
+		return
+	case r.Status == review.StatusSkipped:
+		fmt.Fprintln(w, "Skipped.")
+		return
+	}
+
+	if r.Summary != "" {
+		fmt.Fprintf(w, "%s\n\n", r.Summary)
+	}
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(w, "No issues found.")
+	} else {
+		fmt.Fprintln(w, "| Severity | Location | Issue |")
+		fmt.Fprintln(w, "| --- | --- | --- |")
+		for _, issue := range r.Issues {
+			description := cell(issue.Description)
+			if issue.Baseline {
+				description += " _(baseline)_"
+			}
+			if also := issue.AlsoReportedByNames(); also != "" {
+				description += " _(also " + cell(also) + ")_"
+			}
+			if issue.Speculative() {
+				description += " _(speculative)_"
+			}
+			fmt.Fprintf(w, "| %s | %s | %s |\n", cell(labels.Label(issue.Severity)), location(issue), description)
+		}
+	}
+
+	var fixes []*review.Fix
+	for i := range r.Issues {
+		if f := r.Issues[i].Fix; f != nil && f.Available && f.Code != "" {
+			fixes = append(fixes, f)
+		}
+	}
+	if len(fixes) > 0 {
+		fmt.Fprintf(w, "\n%s# Suggested fixes\n", heading)
+		for _, f := range fixes {
+			writeFix(w, f)
+		}
+	}
+
+	if len(r.Suggestions) > 0 {
+		fmt.Fprintf(w, "\n%s# Suggestions\n", heading)
+		fmt.Fprintln(w)
+		for _, s := range r.Suggestions {
+			fmt.Fprintf(w, "- %s\n", oneLine(s))
+		}
+	}
+}
+
+// writeFix writes a fix's location and explanation followed by its code
+func writeFix(w io.Writer, f *review.Fix) {
+	lines := fmt.Sprintf("line %d", f.StartLine)
+	if f.EndLine > f.StartLine {
+		lines = fmt.Sprintf("lines %d-%d", f.StartLine, f.EndLine)
+	}
+	path := review.RepoPath(f.FilePath, "")
+	fmt.Fprintf(w, "\n%s, %s", code(path), lines)
+	if f.Explanation != "" {
+		fmt.Fprintf(w, ": %s", oneLine(f.Explanation))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	fence := "```"
+	for strings.Contains(f.Code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n", fence, language(path), strings.TrimRight(f.Code, "\n"), fence)
+}
+
+// location renders an issue's location as code, linked to its permalink if it has one
+func location(issue review.Issue) string {
+	loc := review.CleanLocation(issue.Location, "")
+	if loc == "" {
+		return ""
+	}
+	if issue.URL != "" {
+		return fmt.Sprintf("[%s](%s)", code(loc), issue.URL)
+	}
+	return code(loc)
+}
+
+// language returns the code block info string for a file, taken from its extension
+func language(path string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// code renders s as inline code, escaping the pipes that would end a table cell
+func code(s string) string {
+	return "`" + strings.ReplaceAll(strings.ReplaceAll(oneLine(s), "`", "'"), "|", `\|`) + "`"
+}
+
+// cell escapes s for use in a table cell
+func cell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
+
+// oneLine joins the lines of s with spaces, since tables and list items
+// cannot span lines
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
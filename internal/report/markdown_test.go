@@ -0,0 +1,140 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func sampleResults() []*review.Result {
+	return []*review.Result{
+		{
+			Mode:    review.ModeSecurity,
+			Status:  review.StatusIssues,
+			Summary: "One injection risk.",
+			Issues: []review.Issue{
+				{
+					Severity:    review.SeverityHigh,
+					Description: "User input | reaches the shell",
+					Location:    "cmd/run.go:12",
+					URL:         "https://example.com/blob/abc/cmd/run.go#L12",
+					Fix: &review.Fix{
+						Available:   true,
+						Code:        "exec.Command(\"ls\", dir)",
+						FilePath:    "cmd/run.go",
+						StartLine:   12,
+						EndLine:     13,
+						Explanation: "Pass arguments\nwithout a shell",
+					},
+				},
+			},
+			Suggestions: []string{"Validate dir"},
+		},
+		{Mode: review.ModeStyle, Status: review.StatusNoIssues},
+		{Mode: review.ModePerformance, Status: review.StatusFailed, Error: "rate limited"},
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, "staged changes", sampleResults(), nil); err != nil {
+		t.Fatalf("WriteMarkdown() failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Code review: staged changes\n",
+		"| HIGH | 1 |",
+		"_1 review(s) failed._",
+		"## Security\n\nOne injection risk.\n",
+		"| HIGH | [`cmd/run.go:12`](https://example.com/blob/abc/cmd/run.go#L12) | User input \\| reaches the shell |",
+		"### Suggested fixes",
+		"`cmd/run.go`, lines 12-13: Pass arguments without a shell",
+		"```go\nexec.Command(\"ls\", dir)\n```",
+		"### Suggestions\n\n- Validate dir",
+		"## Style\n\nNo issues found.",
+		"## Performance\n\n**Failed:** rate limited",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report should contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteMarkdown_UsesSeverityLabels(t *testing.T) {
+	labels, err := review.NewSeverityLabels(map[string]string{"high": "Blocker"})
+	if err != nil {
+		t.Fatalf("NewSeverityLabels() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, "staged changes", sampleResults(), labels); err != nil {
+		t.Fatalf("WriteMarkdown() failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| Blocker | [`cmd/run.go:12`]") {
+		t.Errorf("report should use the severity labels, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteFix_LengthensFenceAroundBackticks(t *testing.T) {
+	var buf bytes.Buffer
+	writeFix(&buf, &review.Fix{Available: true, Code: "s := ```", FilePath: "README.md", StartLine: 3, EndLine: 3})
+
+	if !strings.Contains(buf.String(), "````md\ns := ```\n````") {
+		t.Errorf("fence should be longer than the code's backticks, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "`README.md`, line 3") {
+		t.Errorf("single-line fixes should name one line, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdown_CleansPaths(t *testing.T) {
+	results := []*review.Result{{
+		Mode:   review.ModeStyle,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{{
+			Severity:    review.SeverityLow,
+			Description: "Unclear name",
+			Location:    "`./docs/My File.md`:4",
+			Fix:         &review.Fix{Available: true, Code: "x", FilePath: "\"./docs/My File.md\"", StartLine: 4, EndLine: 4},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, "staged changes", results, nil); err != nil {
+		t.Fatalf("WriteMarkdown() failed: %v", err)
+	}
+	for _, want := range []string{"| LOW | `docs/My File.md:4` |", "`docs/My File.md`, line 4", "```md\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWriteBatchMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	repos := []RepoResults{
+		{Repo: "services/api", Results: sampleResults()},
+		{Repo: "services/web", Note: "No changes"},
+	}
+	if err := WriteBatchMarkdown(&buf, repos, nil); err != nil {
+		t.Fatalf("WriteBatchMarkdown() error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Code review: 2 repositories",
+		"| `services/api` | 1 | 0 | 0 |  |",
+		"| `services/web` | 0 | 0 | 0 | No changes |",
+		"## `services/api`",
+		"### Security",
+		"#### Suggested fixes",
+		"## `services/web`\n\nNo changes",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package review
+
+import "fmt"
+
+// SpeculativeConfidence is the confidence below which an issue is flagged as
+// speculative, so it can be told apart from the issues its reviewer is sure of
+const SpeculativeConfidence = 0.5
+
+// Speculative reports whether the reviewer reported the issue with a
+// confidence below SpeculativeConfidence
+func (i Issue) Speculative() bool {
+	return i.Confidence != nil && *i.Confidence < SpeculativeConfidence
+}
+
+// ConfidenceLabel returns the issue's confidence as a percentage, e.g. "40%",
+// or "" if none was reported
+func (i Issue) ConfidenceLabel() string {
+	if i.Confidence == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", *i.Confidence*100)
+}
+
+// NormalizeConfidence brings the confidences reported in r into the range 0
+// to 1. Models sometimes report them as percentages, so values up to 100 are
+// read as one; anything else out of range is clamped.
+func NormalizeConfidence(r *Result) {
+	for i := range r.Issues {
+		c := r.Issues[i].Confidence
+		if c == nil {
+			continue
+		}
+		v := *c
+		switch {
+		case v > 1 && v <= 100:
+			v /= 100
+		case v > 1:
+			v = 1
+		case v < 0:
+			v = 0
+		}
+		r.Issues[i].Confidence = &v
+	}
+}
+
+// DropUnconfident removes the issues reported with a confidence below min
+// and records how many were removed in Unconfident. Issues reported without a
+// confidence, such as those of the local secrets scanner and linters, are
+// kept. A result left without issues is marked as having none.
+func (r *Result) DropUnconfident(min float64) {
+	if r == nil || min <= 0 || len(r.Issues) == 0 {
+		return
+	}
+	kept := r.Issues[:0]
+	for _, issue := range r.Issues {
+		if issue.Confidence != nil && *issue.Confidence < min {
+			r.Unconfident++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	r.Issues = kept
+	if len(kept) == 0 && r.Status == StatusIssues {
+		r.Status = StatusNoIssues
+	}
+}
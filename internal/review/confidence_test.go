@@ -0,0 +1,75 @@
+package review
+
+import "testing"
+
+func confidence(v float64) *float64 {
+	return &v
+}
+
+func TestNormalizeConfidence(t *testing.T) {
+	r := &Result{Issues: []Issue{
+		{Confidence: confidence(0.7)},
+		{Confidence: confidence(85)},
+		{Confidence: confidence(250)},
+		{Confidence: confidence(-1)},
+		{},
+	}}
+	NormalizeConfidence(r)
+
+	want := []float64{0.7, 0.85, 1, 0}
+	for i, w := range want {
+		if got := *r.Issues[i].Confidence; got != w {
+			t.Errorf("issue %d confidence = %v, want %v", i, got, w)
+		}
+	}
+	if r.Issues[4].Confidence != nil {
+		t.Errorf("issue without a confidence = %v, want nil", *r.Issues[4].Confidence)
+	}
+}
+
+func TestResult_DropUnconfident(t *testing.T) {
+	r := &Result{Status: StatusIssues, Issues: []Issue{
+		{Description: "sure", Confidence: confidence(0.9)},
+		{Description: "guess", Confidence: confidence(0.3)},
+		{Description: "scanner"},
+	}}
+	r.DropUnconfident(0.6)
+	if len(r.Issues) != 2 || r.Issues[0].Description != "sure" || r.Issues[1].Description != "scanner" {
+		t.Errorf("Issues = %+v, want the confident issue and the one without a confidence", r.Issues)
+	}
+	if r.Unconfident != 1 {
+		t.Errorf("Unconfident = %d, want 1", r.Unconfident)
+	}
+
+	only := &Result{Status: StatusIssues, Issues: []Issue{{Confidence: confidence(0.2)}}}
+	only.DropUnconfident(0.5)
+	if len(only.Issues) != 0 || only.Status != StatusNoIssues {
+		t.Errorf("result = %+v, want no issues left", only)
+	}
+
+	kept := &Result{Status: StatusIssues, Issues: []Issue{{Confidence: confidence(0.2)}}}
+	kept.DropUnconfident(0)
+	if len(kept.Issues) != 1 || kept.Unconfident != 0 {
+		t.Errorf("result = %+v, want every issue kept without a minimum", kept)
+	}
+}
+
+func TestIssue_Speculative(t *testing.T) {
+	tests := []struct {
+		issue     Issue
+		want      bool
+		wantLabel string
+	}{
+		{Issue{Confidence: confidence(0.3)}, true, "30%"},
+		{Issue{Confidence: confidence(0.5)}, false, "50%"},
+		{Issue{}, false, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.issue.Speculative(); got != tt.want {
+			t.Errorf("Speculative() = %v, want %v", got, tt.want)
+		}
+		if got := tt.issue.ConfidenceLabel(); got != tt.wantLabel {
+			t.Errorf("ConfidenceLabel() = %q, want %q", got, tt.wantLabel)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package review
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Agreement values recorded on issues produced by a cross-checked review.
+const (
+	AgreementBoth          = "both"           // Reported by both models
+	AgreementPrimaryOnly   = "primary_only"   // Reported only by the primary model
+	AgreementSecondaryOnly = "secondary_only" // Reported only by the secondary model
+)
+
+// crossCheckLineTolerance is how far apart two issue locations in the same file
+// may be while still being considered the same finding.
+const crossCheckLineTolerance = 3
+
+// CrossCheck merges the results of the same mode run with two different models.
+// Issues reported by both are kept once (using the primary model's wording) and
+// marked AgreementBoth; the rest are marked with the model that reported them.
+// If either review failed, the other result is returned unchanged.
+func CrossCheck(primary, secondary *Result) *Result {
+	if secondary == nil || secondary.Status == StatusFailed {
+		return primary
+	}
+	if primary == nil || primary.Status == StatusFailed {
+		return secondary
+	}
+
+	merged := *primary
+	merged.Issues = nil
+	merged.Suggestions = append([]string(nil), primary.Suggestions...)
+
+	matched := make([]bool, len(secondary.Issues))
+	for _, p := range primary.Issues {
+		issue := p
+		issue.Agreement = AgreementPrimaryOnly
+		for j, s := range secondary.Issues {
+			if !matched[j] && sameFinding(p, s) {
+				matched[j] = true
+				issue.Agreement = AgreementBoth
+				break
+			}
+		}
+		merged.Issues = append(merged.Issues, issue)
+	}
+	for j, s := range secondary.Issues {
+		if matched[j] {
+			continue
+		}
+		issue := s
+		issue.Agreement = AgreementSecondaryOnly
+		merged.Issues = append(merged.Issues, issue)
+	}
+
+	if len(merged.Issues) > 0 {
+		merged.Status = StatusIssues
+	} else {
+		merged.Status = StatusNoIssues
+	}
+	return &merged
+}
+
+// sameFinding reports whether two issues likely describe the same problem.
+// Issues with locations match when they point to the same file within a few lines;
+// otherwise their descriptions must share most of their words.
+func sameFinding(a, b Issue) bool {
+	fileA, lineA := splitLocation(a.Location)
+	fileB, lineB := splitLocation(b.Location)
+	if fileA != "" && fileB != "" {
+		if fileA != fileB {
+			return false
+		}
+		if lineA == 0 || lineB == 0 {
+			return wordOverlap(a.Description, b.Description) >= 0.5
+		}
+		diff := lineA - lineB
+		if diff < 0 {
+			diff = -diff
+		}
+		return diff <= crossCheckLineTolerance
+	}
+	return wordOverlap(a.Description, b.Description) >= 0.5
+}
+
+// splitLocation splits a "file:line" location into its parts.
+// The line is 0 if it is missing or not a number.
+func splitLocation(location string) (string, int) {
+	location = CleanLocation(location, "")
+	if location == "" {
+		return "", 0
+	}
+	idx := strings.LastIndex(location, ":")
+	if idx < 0 {
+		return location, 0
+	}
+	// Accept ranges such as "file.go:10-12" by using the first line
+	linePart := location[idx+1:]
+	if dash := strings.Index(linePart, "-"); dash >= 0 {
+		linePart = linePart[:dash]
+	}
+	line, err := strconv.Atoi(linePart)
+	if err != nil {
+		return location, 0
+	}
+	return location[:idx], line
+}
+
+// wordOverlap returns the Jaccard similarity of the lowercase word sets of a and b
+func wordOverlap(a, b string) float64 {
+	setA := wordSet(a)
+	setB := wordSet(b)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range setA {
+		if setB[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+// wordSet returns the set of lowercase words in s, ignoring punctuation
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		set[w] = true
+	}
+	return set
+}
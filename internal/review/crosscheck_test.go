@@ -0,0 +1,87 @@
+package review
+
+import "testing"
+
+func TestCrossCheck_MarksAgreement(t *testing.T) {
+	primary := &Result{
+		Mode:   ModeSecurity,
+		Status: StatusIssues,
+		Issues: []Issue{
+			{Severity: "high", Description: "SQL injection in query", Location: "db.go:42"},
+			{Severity: "low", Description: "Weak random source", Location: "token.go:10"},
+		},
+	}
+	secondary := &Result{
+		Mode:   ModeSecurity,
+		Status: StatusIssues,
+		Issues: []Issue{
+			{Severity: "high", Description: "Unsanitized input concatenated into SQL", Location: "db.go:43"},
+			{Severity: "medium", Description: "Secret logged to stdout", Location: "main.go:5"},
+		},
+	}
+
+	merged := CrossCheck(primary, secondary)
+	if len(merged.Issues) != 3 {
+		t.Fatalf("expected 3 merged issues, got %d: %+v", len(merged.Issues), merged.Issues)
+	}
+
+	want := map[string]string{
+		"db.go:42":    AgreementBoth,
+		"token.go:10": AgreementPrimaryOnly,
+		"main.go:5":   AgreementSecondaryOnly,
+	}
+	for _, issue := range merged.Issues {
+		if got := issue.Agreement; got != want[issue.Location] {
+			t.Errorf("issue at %s: Agreement = %q, want %q", issue.Location, got, want[issue.Location])
+		}
+	}
+	if merged.Status != StatusIssues {
+		t.Errorf("Status = %q, want %q", merged.Status, StatusIssues)
+	}
+
+	// Inputs must not be modified
+	if primary.Issues[0].Agreement != "" {
+		t.Error("CrossCheck should not modify the primary result")
+	}
+}
+
+func TestCrossCheck_MatchesByDescriptionWithoutLocation(t *testing.T) {
+	primary := &Result{Status: StatusIssues, Issues: []Issue{{Severity: "high", Description: "Hardcoded API key in config"}}}
+	secondary := &Result{Status: StatusIssues, Issues: []Issue{{Severity: "high", Description: "API key hardcoded in config"}}}
+
+	merged := CrossCheck(primary, secondary)
+	if len(merged.Issues) != 1 || merged.Issues[0].Agreement != AgreementBoth {
+		t.Fatalf("expected a single agreed issue, got %+v", merged.Issues)
+	}
+}
+
+func TestCrossCheck_FailedSideFallsBack(t *testing.T) {
+	primary := &Result{Mode: ModeSecurity, Status: StatusNoIssues}
+	secondary := &Result{Mode: ModeSecurity, Status: StatusFailed, Error: "boom"}
+
+	if got := CrossCheck(primary, secondary); got != primary {
+		t.Error("expected primary result when secondary failed")
+	}
+	if got := CrossCheck(secondary, primary); got != primary {
+		t.Error("expected secondary result when primary failed")
+	}
+}
+
+func TestSplitLocation(t *testing.T) {
+	tests := []struct {
+		in   string
+		file string
+		line int
+	}{
+		{"main.go:12", "main.go", 12},
+		{"main.go:12-14", "main.go", 12},
+		{"main.go", "main.go", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		file, line := splitLocation(tt.in)
+		if file != tt.file || line != tt.line {
+			t.Errorf("splitLocation(%q) = (%q, %d), want (%q, %d)", tt.in, file, line, tt.file, tt.line)
+		}
+	}
+}
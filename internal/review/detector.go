@@ -7,6 +7,8 @@ package review
 import (
 	"context"
 	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
 )
 
 // Detector defines the interface for detecting which review modes are relevant
@@ -16,24 +18,24 @@ type Detector interface {
 	// Detect analyzes the given diff and returns the relevant review modes,
 	// a reasoning string explaining why those modes were selected, and any error.
 	// If detection fails, implementations should return a sensible default (e.g., all modes).
-	Detect(ctx context.Context, diff string) ([]Mode, string, error)
+	Detect(ctx context.Context, diff *diff.Diff) ([]Mode, string, error)
 }
 
 // ClaudeDetector uses Claude AI to intelligently detect relevant review modes
 // based on the semantic content of the code diff. It provides more accurate
 // detection than heuristic approaches by understanding code context.
 type ClaudeDetector struct {
-	detectFunc func(ctx context.Context, diff string) (*DetectionResult, error)
+	detectFunc func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error)
 }
 
 // NewClaudeDetector creates a new ClaudeDetector with the provided detection function.
 // The detectFunc should call Claude to analyze the diff and return detected modes.
-func NewClaudeDetector(detectFunc func(ctx context.Context, diff string) (*DetectionResult, error)) *ClaudeDetector {
+func NewClaudeDetector(detectFunc func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error)) *ClaudeDetector {
 	return &ClaudeDetector{detectFunc: detectFunc}
 }
 
 // Detect analyzes the diff and returns relevant review modes
-func (d *ClaudeDetector) Detect(ctx context.Context, diff string) ([]Mode, string, error) {
+func (d *ClaudeDetector) Detect(ctx context.Context, diff *diff.Diff) ([]Mode, string, error) {
 	result, err := d.detectFunc(ctx, diff)
 	if err != nil {
 		// On detection failure, fall back to all modes
@@ -58,7 +60,7 @@ func (d *ClaudeDetector) Detect(ctx context.Context, diff string) ([]Mode, strin
 // isValidMode checks if a mode string is valid
 func isValidMode(mode Mode) bool {
 	switch mode {
-	case ModeSecurity, ModePerformance, ModeStyle, ModeErrors, ModeTesting, ModeDocs:
+	case ModeSecurity, ModePerformance, ModeStyle, ModeErrors, ModeTesting, ModeDocs, ModeSecrets:
 		return true
 	}
 	return false
@@ -75,8 +77,9 @@ func NewHeuristicDetector() *HeuristicDetector {
 }
 
 // Detect uses heuristics to determine relevant review modes
-func (d *HeuristicDetector) Detect(ctx context.Context, diff string) ([]Mode, string, error) {
-	diffLower := strings.ToLower(diff)
+func (d *HeuristicDetector) Detect(ctx context.Context, diff *diff.Diff) ([]Mode, string, error) {
+	text := diff.String()
+	diffLower := strings.ToLower(text)
 	var modes []Mode
 	var reasons []string
 
@@ -130,8 +133,14 @@ func (d *HeuristicDetector) Detect(ctx context.Context, diff string) ([]Mode, st
 		reasons = append(reasons, "documentation changes detected")
 	}
 
+	// Secrets indicators
+	if len(ScanSecrets(diff)) > 0 {
+		modes = append(modes, ModeSecrets)
+		reasons = append(reasons, "possible secrets detected")
+	}
+
 	// Always include style review for non-trivial changes
-	if len(diff) > 100 {
+	if len(text) > 100 {
 		modes = append(modes, ModeStyle)
 		reasons = append(reasons, "code style review")
 	}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"reflect"
 	"testing"
+
+	"github.com/buker/revi/internal/git/diff"
 )
 
 func TestClaudeDetector_FiltersInvalidModes(t *testing.T) {
-	d := NewClaudeDetector(func(ctx context.Context, diff string) (*DetectionResult, error) {
+	d := NewClaudeDetector(func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error) {
 		return &DetectionResult{Modes: []Mode{ModeSecurity, Mode("bogus"), ModeStyle}, Reasoning: "ok"}, nil
 	})
 
-	modes, reasoning, err := d.Detect(context.Background(), "diff")
+	modes, reasoning, err := d.Detect(context.Background(), diff.NewDiff("diff"))
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -26,11 +28,11 @@ func TestClaudeDetector_FiltersInvalidModes(t *testing.T) {
 }
 
 func TestClaudeDetector_NoValidModesFallsBackToAll(t *testing.T) {
-	d := NewClaudeDetector(func(ctx context.Context, diff string) (*DetectionResult, error) {
+	d := NewClaudeDetector(func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error) {
 		return &DetectionResult{Modes: []Mode{Mode("bogus")}, Reasoning: "ignored"}, nil
 	})
 
-	modes, reasoning, err := d.Detect(context.Background(), "diff")
+	modes, reasoning, err := d.Detect(context.Background(), diff.NewDiff("diff"))
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -43,11 +45,11 @@ func TestClaudeDetector_NoValidModesFallsBackToAll(t *testing.T) {
 }
 
 func TestClaudeDetector_ErrorFallsBackToAll(t *testing.T) {
-	d := NewClaudeDetector(func(ctx context.Context, diff string) (*DetectionResult, error) {
+	d := NewClaudeDetector(func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error) {
 		return nil, context.Canceled
 	})
 
-	modes, reasoning, err := d.Detect(context.Background(), "diff")
+	modes, reasoning, err := d.Detect(context.Background(), diff.NewDiff("diff"))
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -61,8 +63,8 @@ func TestClaudeDetector_ErrorFallsBackToAll(t *testing.T) {
 
 func TestHeuristicDetector_DetectsSecurityKeyword(t *testing.T) {
 	d := NewHeuristicDetector()
-	diff := "password = input\nquery := \"select * from users\"\n" // should trigger security
-	modes, _, err := d.Detect(context.Background(), diff)
+	text := "password = input\nquery := \"select * from users\"\n" // should trigger security
+	modes, _, err := d.Detect(context.Background(), diff.NewDiff(text))
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -123,3 +125,40 @@ func sameModeSet(a, b []Mode) bool {
 	}
 	return true
 }
+
+func TestSmartSkipDetector_NarrowsDocAndTestDiffs(t *testing.T) {
+	asked := false
+	d := NewSmartSkipDetector(NewClaudeDetector(func(ctx context.Context, diff *diff.Diff) (*DetectionResult, error) {
+		asked = true
+		return &DetectionResult{Modes: []Mode{ModeSecurity}, Reasoning: "ok"}, nil
+	}))
+	fileDiff := func(paths ...string) string {
+		var out string
+		for _, p := range paths {
+			out += "diff --git a/" + p + " b/" + p + "\n--- a/" + p + "\n+++ b/" + p + "\n@@ -1 +1 @@\n-a\n+b\n"
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		diff  string
+		want  []Mode
+		asked bool
+	}{
+		{"docs", fileDiff("README.md", "docs/setup.html"), []Mode{ModeDocs}, false},
+		{"tests", fileDiff("internal/cli/cli_test.go", "web/app.spec.ts"), []Mode{ModeTesting, ModeErrors}, false},
+		{"docs and tests", fileDiff("README.md", "main_test.go"), []Mode{ModeSecurity}, true},
+		{"code", fileDiff("main.go"), []Mode{ModeSecurity}, true},
+	}
+	for _, tt := range tests {
+		asked = false
+		modes, _, err := d.Detect(context.Background(), diff.NewDiff(tt.diff))
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(modes, tt.want) || asked != tt.asked {
+			t.Errorf("%s: modes = %v (detector asked: %v), want %v (asked: %v)", tt.name, modes, asked, tt.want, tt.asked)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package review
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GoModule describes the Go module at the root of a repository, so that
+// suggested fixes can use its import paths and language version
+type GoModule struct {
+	Path      string   // Module path from the module directive
+	GoVersion string   // Version from the go directive, empty if there is none
+	Requires  []string // Module paths of the required modules, direct or indirect
+
+	root string // Directory holding go.mod
+}
+
+// LoadGoModule reads the go.mod file at root. It returns nil without an error
+// if root is not a Go module.
+func LoadGoModule(root string) (*GoModule, error) {
+	file, err := os.Open(filepath.Join(root, "go.mod"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	mod := &GoModule{root: root}
+	inRequire := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+			} else {
+				mod.Requires = append(mod.Requires, unquoteModPath(fields[0]))
+			}
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			if len(fields) > 1 {
+				mod.Path = unquoteModPath(fields[1])
+			}
+		case "go":
+			if len(fields) > 1 {
+				mod.GoVersion = fields[1]
+			}
+		case "require":
+			switch {
+			case len(fields) > 1 && fields[1] == "(":
+				inRequire = true
+			case len(fields) > 1:
+				mod.Requires = append(mod.Requires, unquoteModPath(fields[1]))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	if mod.Path == "" {
+		return nil, errors.New("go.mod has no module directive")
+	}
+	return mod, nil
+}
+
+// unquoteModPath returns a module path from go.mod, which may be quoted
+func unquoteModPath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// importSpecPattern matches a line of an import block: an optional name
+// followed by a quoted import path
+var importSpecPattern = regexp.MustCompile(`^(?:import\s+)?(?:[\p{L}_.][\p{L}\p{N}_]*\s+)?"([^"\s]+)"\s*(?:;\s*)?$`)
+
+// UnknownImports returns the packages imported by code, a Go snippet, that
+// are neither in the standard library, in this module nor in a module it
+// requires. The snippet is a whole file, an import declaration or the lines
+// of an import block; import paths are only looked for in the import
+// declarations, or in every line if all of them are import specs.
+func (m *GoModule) UnknownImports(code string) []string {
+	var unknown []string
+	seen := make(map[string]bool)
+	for _, path := range snippetImports(code) {
+		if seen[path] || m.knownImport(path) {
+			continue
+		}
+		seen[path] = true
+		unknown = append(unknown, path)
+	}
+	return unknown
+}
+
+// snippetImports returns the import paths in code as described for
+// UnknownImports
+func snippetImports(code string) []string {
+	var all, declared []string
+	allSpecs, inBlock := true, false
+	for _, line := range strings.Split(code, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case line == "import (":
+			inBlock = true
+			allSpecs = false
+			continue
+		}
+		match := importSpecPattern.FindStringSubmatch(line)
+		if match == nil {
+			allSpecs = false
+			continue
+		}
+		all = append(all, match[1])
+		if inBlock || strings.HasPrefix(line, "import") {
+			declared = append(declared, match[1])
+		}
+	}
+	if allSpecs {
+		return all
+	}
+	return declared
+}
+
+// knownImport returns true if path can be imported from this module
+func (m *GoModule) knownImport(path string) bool {
+	if rest, ok := cutModulePath(path, m.Path); ok {
+		info, err := os.Stat(filepath.Join(m.root, filepath.FromSlash(rest)))
+		return err == nil && info.IsDir()
+	}
+	// Standard library paths have no dot in their first element
+	first, _, _ := strings.Cut(path, "/")
+	if !strings.Contains(first, ".") {
+		return true
+	}
+	for _, req := range m.Requires {
+		if _, ok := cutModulePath(path, req); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cutModulePath returns path relative to the module modPath, or false if
+// path is not a package of that module
+func cutModulePath(path, modPath string) (string, bool) {
+	if path == modPath {
+		return "", true
+	}
+	return strings.CutPrefix(path, modPath+"/")
+}
+
+// ValidateFixes marks the fixes of r's issues that import packages unknown to
+// this module as unavailable, since models sometimes invent import paths.
+// Only fixes to .go files are checked.
+func (m *GoModule) ValidateFixes(r *Result) {
+	if m == nil || r == nil {
+		return
+	}
+	for i := range r.Issues {
+		f := r.Issues[i].Fix
+		if f == nil || !f.Available || filepath.Ext(f.FilePath) != ".go" {
+			continue
+		}
+		if unknown := m.UnknownImports(f.Code); len(unknown) > 0 {
+			f.Available = false
+			f.Code = ""
+			f.Reason = fmt.Sprintf("the suggested fix imports %s, which is not part of %s or its dependencies",
+				strings.Join(unknown, ", "), m.Path)
+		}
+	}
+}
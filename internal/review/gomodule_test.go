@@ -0,0 +1,129 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module github.com/acme/shop // the shop
+
+go 1.21
+
+require github.com/spf13/cobra v1.10.2
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/sync v0.8.0 // indirect
+)
+`
+
+// newTestGoModule writes testGoMod and an internal/cart package to a
+// temporary directory and loads it
+func newTestGoModule(t *testing.T) *GoModule {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(testGoMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "internal", "cart"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadGoModule(root)
+	if err != nil {
+		t.Fatalf("LoadGoModule() error: %v", err)
+	}
+	return m
+}
+
+func TestLoadGoModule(t *testing.T) {
+	m := newTestGoModule(t)
+	if m.Path != "github.com/acme/shop" || m.GoVersion != "1.21" {
+		t.Errorf("module = %s go %s, want github.com/acme/shop go 1.21", m.Path, m.GoVersion)
+	}
+	want := []string{"github.com/spf13/cobra", "github.com/google/uuid", "golang.org/x/sync"}
+	if !reflect.DeepEqual(m.Requires, want) {
+		t.Errorf("Requires = %v, want %v", m.Requires, want)
+	}
+}
+
+func TestLoadGoModule_NotAModule(t *testing.T) {
+	m, err := LoadGoModule(t.TempDir())
+	if m != nil || err != nil {
+		t.Errorf("LoadGoModule() = %v, %v, want nil, nil", m, err)
+	}
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("go 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGoModule(root); err == nil {
+		t.Error("expected an error for a go.mod without a module directive")
+	}
+}
+
+func TestGoModule_UnknownImports(t *testing.T) {
+	m := newTestGoModule(t)
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "known imports",
+			code: "import (\n\t\"fmt\"\n\tcobra \"github.com/spf13/cobra\"\n\t\"github.com/acme/shop/internal/cart\"\n)",
+		},
+		{
+			name: "invented packages",
+			code: "import (\n\t\"github.com/acme/shop/internal/payments\"\n\t\"github.com/pkg/errors\"\n)\n\nfunc f() {}",
+			want: []string{"github.com/acme/shop/internal/payments", "github.com/pkg/errors"},
+		},
+		{
+			name: "single import declaration",
+			code: "import \"github.com/pkg/errors\"",
+			want: []string{"github.com/pkg/errors"},
+		},
+		{
+			name: "lines of an import block",
+			code: "\t\"strings\"\n\t\"github.com/pkg/errors\" // wrapping",
+			want: []string{"github.com/pkg/errors"},
+		},
+		{
+			name: "strings in code are not imports",
+			code: "if err != nil {\n\treturn fmt.Errorf(\"open: %w\", err)\n}\nname := \"github.com/pkg/errors\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.UnknownImports(tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownImports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoModule_ValidateFixes(t *testing.T) {
+	m := newTestGoModule(t)
+	r := &Result{Issues: []Issue{
+		{Fix: &Fix{Available: true, FilePath: "main.go", Code: "import \"github.com/pkg/errors\""}},
+		{Fix: &Fix{Available: true, FilePath: "main.go", Code: "import \"fmt\""}},
+		{Fix: &Fix{Available: true, FilePath: "script.py", Code: "import \"github.com/pkg/errors\""}},
+	}}
+	m.ValidateFixes(r)
+
+	invented := r.Issues[0].Fix
+	if invented.Available || invented.Code != "" || !strings.Contains(invented.Reason, "github.com/pkg/errors") {
+		t.Errorf("fix with an invented import = %+v, want it unavailable with the import in its reason", invented)
+	}
+	if !r.Issues[1].Fix.Available {
+		t.Error("fix importing the standard library should stay available")
+	}
+	if !r.Issues[2].Fix.Available {
+		t.Error("fixes to non-Go files should not be checked")
+	}
+
+	var none *GoModule
+	none.ValidateFixes(r)
+}
@@ -0,0 +1,78 @@
+package review
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter bounds how many reviews run at once and spaces out their starts, so
+// running many modes does not trip the AI provider's rate limits. A nil
+// Limiter does not limit anything.
+type Limiter struct {
+	slots    chan struct{} // nil when concurrency is unlimited
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next review
+}
+
+// NewLimiter returns a Limiter allowing at most concurrency reviews in flight,
+// each starting at least interval after the previous one. Zero or less for
+// either disables that limit; if both are disabled NewLimiter returns nil.
+func NewLimiter(concurrency int, interval time.Duration) *Limiter {
+	if concurrency <= 0 && interval <= 0 {
+		return nil
+	}
+	l := &Limiter{interval: max(interval, 0)}
+	if concurrency > 0 {
+		l.slots = make(chan struct{}, concurrency)
+	}
+	return l
+}
+
+// Acquire waits until a review may start and returns a function that must be
+// called when it finishes. Returns the context's error if ctx is done first.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = sync.OnceFunc(func() { <-l.slots })
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if wait := l.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// reserve claims the next start time and returns how long to wait for it
+func (l *Limiter) reserve() time.Duration {
+	if l.interval <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	start := now
+	if l.next.After(now) {
+		start = l.next
+	}
+	l.next = start.Add(l.interval)
+	return start.Sub(now)
+}
@@ -0,0 +1,99 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewLimiter_Unlimited(t *testing.T) {
+	if l := NewLimiter(0, 0); l != nil {
+		t.Fatalf("NewLimiter(0, 0) = %v, want nil", l)
+	}
+
+	var l *Limiter
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() on nil limiter error = %v", err)
+	}
+	release()
+}
+
+func TestLimiter_BoundsConcurrency(t *testing.T) {
+	l := NewLimiter(2, 0)
+
+	var inFlight, peak atomic.Int32
+	done := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			release, err := l.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire() error = %v", err)
+				return
+			}
+			defer release()
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	for i := 0; i < 6; i++ {
+		<-done
+	}
+
+	if peak.Load() != 2 {
+		t.Errorf("peak reviews in flight = %d, want 2", peak.Load())
+	}
+}
+
+func TestLimiter_PacesStarts(t *testing.T) {
+	l := NewLimiter(0, 20*time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("three paced starts took %v, want at least 40ms", elapsed)
+	}
+}
+
+func TestLimiter_AcquireCancelled(t *testing.T) {
+	l := NewLimiter(1, 0)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() with a full limiter error = %v, want deadline exceeded", err)
+	}
+
+	// Releasing twice must not free a slot held by someone else
+	release()
+	release()
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel2()
+	if _, err := l.Acquire(ctx2); err == nil {
+		t.Error("expected the single slot to be taken")
+	}
+}
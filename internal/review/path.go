@@ -0,0 +1,99 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// CleanPath returns a file path reported by a model without the quotes or
+// backticks put around paths with spaces, decoding paths quoted by git with
+// C-style escapes.
+func CleanPath(path string) string {
+	path = strings.TrimSpace(path)
+	if len(path) >= 2 {
+		switch first, last := path[0], path[len(path)-1]; {
+		case first == '"' && last == '"':
+			return diff.UnquotePath(path)
+		case first == last && (first == '\'' || first == '`'):
+			return path[1 : len(path)-1]
+		}
+	}
+	return path
+}
+
+// RepoPath returns a file path reported by a model relative to the repository
+// at root: cleaned as by CleanPath and without a leading "./". With a root,
+// absolute paths inside it are made relative and the "a/" and "b/" prefixes
+// of diff file names are removed unless the prefixed path exists. Without a
+// root only the cleaning is done, so paths already relative are kept as they are.
+func RepoPath(path, root string) string {
+	path = CleanPath(path)
+	for strings.HasPrefix(path, "./") {
+		path = path[2:]
+	}
+	if root == "" || path == "" {
+		return path
+	}
+
+	if filepath.IsAbs(path) {
+		roots := []string{root}
+		if resolved, err := filepath.EvalSymlinks(root); err == nil && resolved != root {
+			roots = append(roots, resolved)
+		}
+		for _, r := range roots {
+			rel, err := filepath.Rel(r, path)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return path
+	}
+
+	if rest, ok := cutDiffPrefix(path); ok {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			return rest
+		}
+	}
+	return path
+}
+
+// cutDiffPrefix returns path without the "a/" or "b/" git puts before the
+// file names of a diff
+func cutDiffPrefix(path string) (string, bool) {
+	for _, prefix := range []string{"a/", "b/"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok && rest != "" {
+			return rest, true
+		}
+	}
+	return path, false
+}
+
+// NormalizePaths rewrites the locations and fix file paths of r's issues to
+// plain paths relative to the repository at root, as RepoPath does, so that
+// fixes, suppressions, links and reports find the files. root may be empty.
+func NormalizePaths(r *Result, root string) {
+	if r == nil {
+		return
+	}
+	for i := range r.Issues {
+		issue := &r.Issues[i]
+		issue.Location = CleanLocation(issue.Location, root)
+		if issue.Fix != nil && issue.Fix.FilePath != "" {
+			issue.Fix.FilePath = RepoPath(issue.Fix.FilePath, root)
+		}
+	}
+}
+
+// CleanLocation normalizes the file part of a "file:line" location, which
+// may be quoted as a whole or on its own, with RepoPath
+func CleanLocation(location, root string) string {
+	location = CleanPath(location)
+	idx := strings.LastIndex(location, ":")
+	if idx < 0 {
+		return RepoPath(location, root)
+	}
+	return RepoPath(location[:idx], root) + location[idx:]
+}
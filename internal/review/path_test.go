@@ -0,0 +1,95 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := map[string]string{
+		"docs/Design Überblick.md":          "docs/Design Überblick.md",
+		`"docs/Design Überblick.md"`:        "docs/Design Überblick.md",
+		"`docs/Design Überblick.md`":        "docs/Design Überblick.md",
+		"'docs/Design Überblick.md'":        "docs/Design Überblick.md",
+		`"docs/Design \303\234berblick.md"`: "docs/Design Überblick.md",
+		"  main.go ":                        "main.go",
+	}
+	for path, want := range tests {
+		if got := CleanPath(path); got != want {
+			t.Errorf("CleanPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestNormalizePaths(t *testing.T) {
+	r := &Result{Issues: []Issue{
+		{Location: `"docs/Design Überblick.md":12`, Fix: &Fix{FilePath: "`docs/Design Überblick.md`"}},
+		{Location: `"docs/My File.md:3-5"`},
+		{Location: "main.go:7"},
+	}}
+
+	NormalizePaths(r, "")
+
+	want := []string{"docs/Design Überblick.md:12", "docs/My File.md:3-5", "main.go:7"}
+	for i, w := range want {
+		if r.Issues[i].Location != w {
+			t.Errorf("Issues[%d].Location = %q, want %q", i, r.Issues[i].Location, w)
+		}
+	}
+	if r.Issues[0].Fix.FilePath != "docs/Design Überblick.md" {
+		t.Errorf("Fix.FilePath = %q, want the unquoted path", r.Issues[0].Fix.FilePath)
+	}
+	if file, line := (Issue{Location: `"docs/My File.md":3`}).FileLine(); file != "docs/My File.md" || line != 3 {
+		t.Errorf("FileLine() = %q, %d, want the unquoted file and line 3", file, line)
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	root := t.TempDir()
+	// A real top-level directory named like a diff prefix
+	if err := os.MkdirAll(filepath.Join(root, "a"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "kept.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, root, want string
+	}{
+		{"pkg/main.go", root, "pkg/main.go"},
+		{"./pkg/main.go", root, "pkg/main.go"},
+		{"a/pkg/main.go", root, "pkg/main.go"},
+		{"b/pkg/main.go", root, "pkg/main.go"},
+		{"`b/docs/My File.md`", root, "docs/My File.md"},
+		{"a/kept.go", root, "a/kept.go"},
+		{filepath.Join(root, "pkg", "main.go"), root, "pkg/main.go"},
+		{"/etc/passwd", root, "/etc/passwd"},
+		{"b/pkg/main.go", "", "b/pkg/main.go"},
+		{"./pkg/main.go", "", "pkg/main.go"},
+		{"", root, ""},
+	}
+	for _, tt := range tests {
+		if got := RepoPath(tt.path, tt.root); got != tt.want {
+			t.Errorf("RepoPath(%q, %q) = %q, want %q", tt.path, tt.root, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePaths_RelativeToRoot(t *testing.T) {
+	root := t.TempDir()
+	r := &Result{Issues: []Issue{
+		{Location: filepath.Join(root, "main.go") + ":7", Fix: &Fix{FilePath: "b/main.go"}},
+		{Location: "a/util.go:3-5"},
+	}}
+
+	NormalizePaths(r, root)
+
+	if r.Issues[0].Location != "main.go:7" || r.Issues[0].Fix.FilePath != "main.go" {
+		t.Errorf("Issues[0] = %q, %q, want main.go:7 and main.go", r.Issues[0].Location, r.Issues[0].Fix.FilePath)
+	}
+	if r.Issues[1].Location != "util.go:3-5" {
+		t.Errorf("Issues[1].Location = %q, want util.go:3-5", r.Issues[1].Location)
+	}
+}
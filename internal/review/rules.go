@@ -0,0 +1,119 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// rule is a deterministic check of the lines a diff adds, run instead of the
+// AI by offline reviews
+type rule struct {
+	mode        Mode
+	severity    string
+	pattern     *regexp.Regexp
+	description string
+}
+
+// rules are the offline checks of each mode. They look for patterns that are
+// almost always worth a second look, so they find far less than an AI review
+// but rarely report what is fine. A line is reported once per mode, for the
+// first rule it matches.
+var rules = []rule{
+	{ModeSecurity, SeverityHigh, regexp.MustCompile(`InsecureSkipVerify:\s*true`),
+		"TLS certificate verification is disabled"},
+	{ModeSecurity, SeverityMedium, regexp.MustCompile(`(?i)"\s*(?:select|insert|update|delete)\b[^"]*"\s*\+`),
+		"SQL query built by concatenating strings; use query parameters"},
+	{ModeSecurity, SeverityMedium, regexp.MustCompile(`(?i)Sprintf\(\s*"\s*(?:select|insert|update|delete)\b`),
+		"SQL query built with Sprintf; use query parameters"},
+	{ModeSecurity, SeverityMedium, regexp.MustCompile(`exec\.Command(?:Context)?\([^)]*"(?:sh|bash)"\s*,\s*"-c"`),
+		"Command run through a shell, which interprets anything interpolated into it"},
+	{ModeSecurity, SeverityMedium, regexp.MustCompile(`\b(?:md5|sha1)\.(?:New|Sum)\b`),
+		"MD5 and SHA-1 are broken for passwords and signatures"},
+	{ModeSecurity, SeverityMedium, regexp.MustCompile(`(?:^|[^\w.])eval\s*\(`),
+		"Dynamic code evaluated with eval"},
+	{ModePerformance, SeverityLow, regexp.MustCompile(`(?i)\bselect\s+\*\s+from\b`),
+		"SELECT * fetches every column, including ones not used"},
+	{ModeErrors, SeverityMedium, regexp.MustCompile(`\bcatch\s*(?:\([^)]*\))?\s*\{\s*\}`),
+		"Empty catch block swallows the exception"},
+	{ModeErrors, SeverityMedium, regexp.MustCompile(`^\s*except(?:\s+\w+(?:\s+as\s+\w+)?)?\s*:\s*pass\s*$`),
+		"Exception silenced with pass"},
+	{ModeErrors, SeverityMedium, regexp.MustCompile(`if err != nil \{\s*\}`),
+		"Error checked but not handled"},
+	{ModeStyle, SeverityLow, regexp.MustCompile(`^\s*debugger;?\s*$`),
+		"debugger statement left in"},
+	{ModeStyle, SeverityLow, regexp.MustCompile(`\bconsole\.log\(`),
+		"console.log left in"},
+	{ModeStyle, SeverityLow, regexp.MustCompile(`\b(?:TODO|FIXME|XXX)\b`),
+		"TODO left in the change"},
+	{ModeTesting, SeverityMedium, regexp.MustCompile(`\b(?:it|describe|test)\.only\(`),
+		"Focused test keeps the rest of the suite from running"},
+	{ModeTesting, SeverityLow, regexp.MustCompile(`\bt\.Skip(?:f|Now)?\(`),
+		"Test skipped"},
+}
+
+// HasRules reports whether offline reviews of mode check anything
+func HasRules(mode Mode) bool {
+	if mode == ModeSecrets {
+		return true
+	}
+	for _, r := range rules {
+		if r.mode == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRules runs the offline checks of mode on the lines d adds and returns
+// an issue for each line one matches. The secrets mode reports what
+// ScanSecrets finds.
+func CheckRules(mode Mode, d *diff.Diff) []Issue {
+	if mode == ModeSecrets {
+		return ScanSecrets(d)
+	}
+	var issues []Issue
+	for _, f := range d.Files {
+		if f.IsBinary() {
+			continue
+		}
+		for _, h := range f.Hunks {
+			for _, line := range h.Body() {
+				if line.Kind != diff.LineAdded {
+					continue
+				}
+				for _, r := range rules {
+					if r.mode == mode && r.pattern.MatchString(line.Text) {
+						issues = append(issues, Issue{
+							Severity:    r.severity,
+							Description: r.description,
+							Location:    fmt.Sprintf("%s:%d", f.Path, line.New),
+						})
+						break
+					}
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// OfflineResult reviews d in mode with CheckRules instead of the AI. Modes
+// without offline checks are skipped.
+func OfflineResult(mode Mode, d *diff.Diff) *Result {
+	if !HasRules(mode) {
+		return &Result{Mode: mode, Status: StatusSkipped, Summary: "Not reviewed offline; this mode needs the AI"}
+	}
+	result := &Result{
+		Mode:    mode,
+		Status:  StatusNoIssues,
+		Summary: "The offline checks found nothing",
+		Issues:  CheckRules(mode, d),
+	}
+	if len(result.Issues) > 0 {
+		result.Status = StatusIssues
+		result.Summary = fmt.Sprintf("%d issue(s) found by the offline checks", len(result.Issues))
+	}
+	return result
+}
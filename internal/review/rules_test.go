@@ -0,0 +1,77 @@
+package review
+
+import (
+	"testing"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+func TestCheckRules(t *testing.T) {
+	text := addedLines("server.go",
+		`	tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}`,
+		`	rows, err := db.Query("SELECT name FROM users WHERE id = " + id)`,
+		`	// TODO: paginate`,
+		`	sum := md5.Sum(data)`,
+	)
+
+	security := CheckRules(ModeSecurity, diff.NewDiff(text))
+	if len(security) != 3 {
+		t.Fatalf("security issues = %+v, want 3", security)
+	}
+	if security[0].Severity != SeverityHigh || security[0].Location != "server.go:2" {
+		t.Errorf("first issue = %+v, want high at server.go:2", security[0])
+	}
+	if security[2].Location != "server.go:5" {
+		t.Errorf("third issue at %s, want server.go:5", security[2].Location)
+	}
+
+	if style := CheckRules(ModeStyle, diff.NewDiff(text)); len(style) != 1 || style[0].Location != "server.go:4" {
+		t.Errorf("style issues = %+v, want the TODO at server.go:4", style)
+	}
+	if docs := CheckRules(ModeDocs, diff.NewDiff(text)); len(docs) != 0 {
+		t.Errorf("docs issues = %+v, want none", docs)
+	}
+}
+
+func TestCheckRules_IgnoresRemovedLines(t *testing.T) {
+	text := "diff --git a/a.js b/a.js\n--- a/a.js\n+++ b/a.js\n@@ -1,2 +1,1 @@\n-  debugger;\n-  console.log(x)\n+  return x\n"
+	if issues := CheckRules(ModeStyle, diff.NewDiff(text)); len(issues) != 0 {
+		t.Errorf("issues = %+v, want none for removed lines", issues)
+	}
+}
+
+func TestCheckRules_AddedAndDeletedFiles(t *testing.T) {
+	text := createdFile("server.go", "package main", "", `	// TODO: paginate`, `	sum := md5.Sum(data)`) +
+		deletedFile("legacy.go", "package main", `	// FIXME: drop`, `	sum := md5.Sum(data)`)
+
+	for _, shape := range []string{text, withoutHunkHeaders(text)} {
+		d := diff.NewDiff(shape)
+		if security := CheckRules(ModeSecurity, d); len(security) != 1 || security[0].Location != "server.go:4" {
+			t.Errorf("security issues = %+v, want only the md5 at server.go:4 in\n%s", security, shape)
+		}
+		if style := CheckRules(ModeStyle, d); len(style) != 1 || style[0].Location != "server.go:3" {
+			t.Errorf("style issues = %+v, want only the TODO at server.go:3 in\n%s", style, shape)
+		}
+	}
+}
+
+func TestCheckRules_SecretsUsesScanner(t *testing.T) {
+	text := addedLines("config.go", `	key := "`+fakeAWSKey+`"`)
+	if issues := CheckRules(ModeSecrets, diff.NewDiff(text)); len(issues) != 1 {
+		t.Errorf("secrets issues = %+v, want the AWS key", issues)
+	}
+}
+
+func TestOfflineResult(t *testing.T) {
+	text := addedLines("app_test.js", `describe.only("login", () => {`)
+
+	if r := OfflineResult(ModeTesting, diff.NewDiff(text)); r.Status != StatusIssues || len(r.Issues) != 1 || r.Mode != ModeTesting {
+		t.Errorf("testing result = %+v, want one issue", r)
+	}
+	if r := OfflineResult(ModeSecurity, diff.NewDiff(text)); r.Status != StatusNoIssues {
+		t.Errorf("security result = %+v, want no issues", r)
+	}
+	if r := OfflineResult(ModeDocs, diff.NewDiff(text)); r.Status != StatusSkipped {
+		t.Errorf("docs result = %+v, want skipped since it has no offline checks", r)
+	}
+}
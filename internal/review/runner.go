@@ -2,14 +2,21 @@ package review
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
+
+	"github.com/buker/revi/internal/git/diff"
 )
 
 // ReviewFunc defines the signature for a function that executes a single code review.
 // It takes a context for cancellation, the review mode to run, and the code diff to analyze.
 // Returns the review result or an error if the review failed.
-type ReviewFunc func(ctx context.Context, mode Mode, diff string) (*Result, error)
+type ReviewFunc func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error)
 
 // StatusCallback is invoked when a review mode's execution status changes.
 // Used to update UI or logging as reviews transition between pending, running, and done states.
@@ -20,6 +27,8 @@ type StatusCallback func(mode Mode, status Status)
 type Runner struct {
 	reviewFunc     ReviewFunc
 	statusCallback StatusCallback
+	limiter        *Limiter
+	modeTimeout    time.Duration
 }
 
 // NewRunner creates a new Runner with the given review function and optional status callback.
@@ -32,10 +41,23 @@ func NewRunner(reviewFunc ReviewFunc, statusCallback StatusCallback) *Runner {
 	}
 }
 
+// SetLimiter bounds how many reviews run at once and paces their starts.
+// Queued reviews are reported as running only once they start.
+func (r *Runner) SetLimiter(l *Limiter) {
+	r.limiter = l
+}
+
+// SetModeTimeout limits how long each review may run once it has started, so
+// that one stuck mode does not hold up the others. Reviews cut short are
+// reported as timed out. Zero or less sets no limit.
+func (r *Runner) SetModeTimeout(d time.Duration) {
+	r.modeTimeout = d
+}
+
 // Run executes all specified review modes in parallel using goroutines.
 // It waits for all reviews to complete and returns results in the same order as modes.
 // Each review's status is reported via the statusCallback if configured.
-func (r *Runner) Run(ctx context.Context, modes []Mode, diff string) []*Result {
+func (r *Runner) Run(ctx context.Context, modes []Mode, diff *diff.Diff) []*Result {
 	results := make([]*Result, len(modes))
 	var wg sync.WaitGroup
 
@@ -44,13 +66,26 @@ func (r *Runner) Run(ctx context.Context, modes []Mode, diff string) []*Result {
 		go func(idx int, m Mode) {
 			defer wg.Done()
 
+			// Wait for a free slot when the number of reviews in flight is limited
+			release, err := r.limiter.Acquire(ctx)
+			if err != nil {
+				results[idx] = MarkTimedOut(ctx, &Result{Mode: m, Status: StatusFailed, Error: err.Error()})
+				if r.statusCallback != nil {
+					r.statusCallback(m, results[idx].Status)
+				}
+				return
+			}
+			defer release()
+
 			// Update status to running
 			if r.statusCallback != nil {
 				r.statusCallback(m, StatusRunning)
 			}
 
 			// Run the review
-			result, err := r.reviewFunc(ctx, m, diff)
+			reviewCtx, cancel := WithModeTimeout(ctx, r.modeTimeout)
+			defer cancel()
+			result, err := r.reviewFunc(reviewCtx, m, diff)
 			if err != nil {
 				result = &Result{
 					Mode:   m,
@@ -58,13 +93,14 @@ func (r *Runner) Run(ctx context.Context, modes []Mode, diff string) []*Result {
 					Error:  err.Error(),
 				}
 			}
+			result = MarkTimedOut(reviewCtx, result)
 
 			results[idx] = result
 
 			// Update status to done/failed
 			if r.statusCallback != nil {
-				if result.Status == StatusFailed {
-					r.statusCallback(m, StatusFailed)
+				if result.Status == StatusFailed || result.Status == StatusTimedOut {
+					r.statusCallback(m, result.Status)
 				} else {
 					r.statusCallback(m, StatusDone)
 				}
@@ -73,18 +109,113 @@ func (r *Runner) Run(ctx context.Context, modes []Mode, diff string) []*Result {
 	}
 
 	wg.Wait()
-	return results
+	return MergeDuplicates(results)
+}
+
+// WithModeTimeout returns a context for a single review that ends after d, or
+// a plain cancellable one if d is zero or less
+func WithModeTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// MarkTimedOut returns result with StatusTimedOut if it failed because ctx's
+// deadline passed, so a review cut short by a time limit is not mistaken for
+// one that failed on its own. Other results are returned unchanged.
+func MarkTimedOut(ctx context.Context, result *Result) *Result {
+	if result == nil || result.Status != StatusFailed || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result
+	}
+	result.Status = StatusTimedOut
+	result.Summary = "Timed out before the review finished"
+	return result
+}
+
+// MergeDuplicates merges the issues several modes report at the same location
+// with the same description, such as missing input validation reported by
+// both the security and errors reviews. Each is kept once, in the first mode
+// that reported it, with the others recorded in AlsoReportedBy, the highest
+// severity and confidence any of them gave, and a fix if any of them had one. Results with
+// issues are returned as copies, so results already handed to the TUI are not
+// modified while it shows them; a result left without issues reports none.
+func MergeDuplicates(results []*Result) []*Result {
+	type position struct{ result, issue int }
+	seen := make(map[string]position)
+	merged := slices.Clone(results)
+	for i, r := range merged {
+		if r == nil || len(r.Issues) == 0 {
+			continue
+		}
+		var kept []Issue
+		dropped := false
+		for _, issue := range r.Issues {
+			key := duplicateKey(issue)
+			at, ok := seen[key]
+			if key == "" || !ok || at.result == i {
+				if key != "" && !ok {
+					seen[key] = position{i, len(kept)}
+				}
+				kept = append(kept, issue)
+				continue
+			}
+			first := &merged[at.result].Issues[at.issue]
+			if !slices.Contains(first.AlsoReportedBy, r.Mode) {
+				first.AlsoReportedBy = append(first.AlsoReportedBy, r.Mode)
+			}
+			if !SeverityAtLeast(first.Severity, issue.Severity) {
+				first.Severity = issue.Severity
+			}
+			if issue.Confidence != nil && (first.Confidence == nil || *issue.Confidence > *first.Confidence) {
+				first.Confidence = issue.Confidence
+			}
+			if (first.Fix == nil || !first.Fix.Available) && issue.Fix != nil && issue.Fix.Available {
+				first.Fix = issue.Fix
+			}
+			dropped = true
+		}
+		copied := *r
+		copied.Issues = kept
+		if dropped && len(kept) == 0 && copied.Status == StatusIssues {
+			copied.Status = StatusNoIssues
+		}
+		merged[i] = &copied
+	}
+	return merged
+}
+
+// duplicateKey returns the key under which issues count as the same: their
+// file, line and description, ignoring case, punctuation and spacing. Issues
+// without a location have none, since nothing ties them to the same code.
+func duplicateKey(issue Issue) string {
+	file, line := issue.FileLine()
+	if file == "" {
+		return ""
+	}
+	words := strings.FieldsFunc(strings.ToLower(issue.Description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return fmt.Sprintf("%s:%d %s", file, line, strings.Join(words, " "))
 }
 
 // Summary aggregates statistics from a set of review results.
 // It counts total reviews, issues by severity level, and failed reviews.
 type Summary struct {
-	TotalReviews   int // Total number of reviews executed
-	IssuesFound    int // Total number of issues found across all reviews
-	HighSeverity   int // Count of high-severity issues
-	MediumSeverity int // Count of medium-severity issues
-	LowSeverity    int // Count of low-severity issues
-	FailedReviews  int // Number of reviews that failed to execute
+	TotalReviews    int `json:"total_reviews"`     // Total number of reviews executed
+	IssuesFound     int `json:"issues_found"`      // Total number of issues found across all reviews
+	HighSeverity    int `json:"high_severity"`     // Count of high-severity issues
+	MediumSeverity  int `json:"medium_severity"`   // Count of medium-severity issues
+	LowSeverity     int `json:"low_severity"`      // Count of low-severity issues
+	FailedReviews   int `json:"failed_reviews"`    // Number of reviews that failed to execute
+	SkippedReviews  int `json:"skipped_reviews"`   // Number of reviews that were cancelled by the user
+	TimedOutReviews int `json:"timed_out_reviews"` // Number of reviews cut short by the run's or their mode's time limit
+	UnknownSeverity int `json:"unknown_severity"`  // Issues whose reported severity was not recognized
+	Suppressed      int `json:"suppressed"`        // Issues ignored by .reviignore rules or revi:ignore annotations
+	BaselineHigh    int `json:"baseline_high"`     // High-severity issues recorded in the baseline, which do not block
+	Merged          int `json:"merged"`            // Reports of an issue another mode also reported, merged into it
+	Unconfident     int `json:"unconfident"`       // Issues dropped for a confidence below review.min_confidence
+	Known           int `json:"known"`             // Issues dropped by --baseline because an earlier run reported them
 }
 
 // Summarize creates a Summary by aggregating statistics from the given review results.
@@ -103,16 +234,36 @@ func Summarize(results []*Result) Summary {
 			continue
 		}
 
+		if r.Status == StatusSkipped {
+			summary.SkippedReviews++
+			continue
+		}
+
+		if r.Status == StatusTimedOut {
+			summary.TimedOutReviews++
+			continue
+		}
+
+		summary.Suppressed += r.Suppressed
+		summary.Unconfident += r.Unconfident
+		summary.Known += r.Known
 		for _, issue := range r.Issues {
 			summary.IssuesFound++
 			switch issue.Severity {
-			case "high":
+			case SeverityHigh:
 				summary.HighSeverity++
-			case "medium":
+				if issue.Baseline {
+					summary.BaselineHigh++
+				}
+			case SeverityMedium:
 				summary.MediumSeverity++
-			case "low":
+			case SeverityLow:
 				summary.LowSeverity++
 			}
+			if issue.SeverityUnknown {
+				summary.UnknownSeverity++
+			}
+			summary.Merged += len(issue.AlsoReportedBy)
 		}
 	}
 
@@ -120,15 +271,22 @@ func Summarize(results []*Result) Summary {
 }
 
 // ShouldBlock determines if a commit should be blocked based on review results.
-// Returns true if blockOnIssues is enabled and any result contains high-severity issues.
+// Returns true if blockOnIssues is enabled and any result contains high-severity
+// issues that are not recorded in the baseline.
 // This allows CI/CD pipelines to prevent commits that introduce critical problems.
 func ShouldBlock(results []*Result, blockOnIssues bool) bool {
+	return ShouldBlockAt(results, blockOnIssues, SeverityHigh)
+}
+
+// ShouldBlockAt is ShouldBlock with issues of threshold severity or above
+// blocking, e.g. medium on release branches. An empty threshold is high.
+func ShouldBlockAt(results []*Result, blockOnIssues bool, threshold string) bool {
 	if !blockOnIssues {
 		return false
 	}
 
 	for _, r := range results {
-		if r != nil && r.HasHighSeverityIssues() {
+		if r != nil && r.HasBlockingIssuesAt(threshold) {
 			return true
 		}
 	}
@@ -137,26 +295,39 @@ func ShouldBlock(results []*Result, blockOnIssues bool) bool {
 }
 
 // GetBlockReason returns a human-readable reason explaining why a commit was blocked.
-// It counts high-severity issues across all results and returns an appropriate message.
-// Returns an empty string if there are no high-severity issues.
+// It counts high-severity issues not recorded in the baseline across all results
+// and returns an appropriate message.
+// Returns an empty string if there are no such issues.
 func GetBlockReason(results []*Result) string {
-	var highIssues int
+	return GetBlockReasonAt(results, SeverityHigh)
+}
+
+// GetBlockReasonAt is GetBlockReason counting the issues of threshold
+// severity or above. An empty threshold is high.
+func GetBlockReasonAt(results []*Result, threshold string) string {
+	if threshold == "" {
+		threshold = SeverityHigh
+	}
+	var blocking int
 	for _, r := range results {
 		if r != nil {
 			for _, issue := range r.Issues {
-				if issue.Severity == "high" {
-					highIssues++
+				if SeverityAtLeast(issue.Severity, threshold) && !issue.Baseline {
+					blocking++
 				}
 			}
 		}
 	}
 
-	if highIssues > 0 {
-		if highIssues == 1 {
-			return "1 high-severity issue found"
-		}
-		return fmt.Sprintf("%d high-severity issues found", highIssues)
+	switch {
+	case blocking == 0:
+		return ""
+	case threshold != SeverityHigh && blocking == 1:
+		return fmt.Sprintf("1 issue of %s severity or higher found", threshold)
+	case threshold != SeverityHigh:
+		return fmt.Sprintf("%d issues of %s severity or higher found", blocking, threshold)
+	case blocking == 1:
+		return "1 high-severity issue found"
 	}
-
-	return ""
+	return fmt.Sprintf("%d high-severity issues found", blocking)
 }
@@ -2,9 +2,12 @@ package review
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/buker/revi/internal/git/diff"
 )
 
 func TestRunner_RunPreservesModeOrder(t *testing.T) {
@@ -17,14 +20,14 @@ func TestRunner_RunPreservesModeOrder(t *testing.T) {
 	}
 
 	runner := NewRunner(
-		func(ctx context.Context, mode Mode, diff string) (*Result, error) {
+		func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
 			time.Sleep(sleepByMode[mode])
 			return &Result{Mode: mode, Status: StatusNoIssues}, nil
 		},
 		nil,
 	)
 
-	results := runner.Run(context.Background(), modes, "diff")
+	results := runner.Run(context.Background(), modes, diff.NewDiff("diff"))
 	if len(results) != len(modes) {
 		t.Fatalf("expected %d results, got %d", len(modes), len(results))
 	}
@@ -50,7 +53,7 @@ func TestRunner_StatusCallbackTransitions(t *testing.T) {
 	}
 
 	runner := NewRunner(
-		func(ctx context.Context, mode Mode, diff string) (*Result, error) {
+		func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
 			if mode == ModeErrors {
 				return nil, context.Canceled
 			}
@@ -59,7 +62,7 @@ func TestRunner_StatusCallbackTransitions(t *testing.T) {
 		cb,
 	)
 
-	_ = runner.Run(context.Background(), modes, "diff")
+	_ = runner.Run(context.Background(), modes, diff.NewDiff("diff"))
 
 	mu.Lock()
 	defer mu.Unlock()
@@ -125,3 +128,247 @@ func TestSummarize_ShouldBlock_GetBlockReason(t *testing.T) {
 		t.Fatalf("expected block reason %q, got %q", "1 high-severity issue found", reason)
 	}
 }
+
+func TestShouldBlock_IgnoresBaselineIssues(t *testing.T) {
+	results := []*Result{{
+		Mode:   ModeSecurity,
+		Status: StatusIssues,
+		Issues: []Issue{{Severity: "high", Description: "old", Baseline: true}},
+	}}
+	if ShouldBlock(results, true) {
+		t.Fatal("high-severity issues in the baseline should not block")
+	}
+	if reason := GetBlockReason(results); reason != "" {
+		t.Fatalf("GetBlockReason() = %q, want none", reason)
+	}
+	if summary := Summarize(results); summary.HighSeverity != 1 || summary.BaselineHigh != 1 {
+		t.Fatalf("HighSeverity = %d, BaselineHigh = %d; want 1 and 1", summary.HighSeverity, summary.BaselineHigh)
+	}
+
+	results[0].Issues = append(results[0].Issues, Issue{Severity: "high", Description: "new"})
+	if !ShouldBlock(results, true) {
+		t.Fatal("a newly introduced high-severity issue should block")
+	}
+	if reason := GetBlockReason(results); reason != "1 high-severity issue found" {
+		t.Fatalf("GetBlockReason() = %q, want only the new issue counted", reason)
+	}
+}
+
+func TestShouldBlockAt_Threshold(t *testing.T) {
+	results := []*Result{{
+		Mode:   ModeStyle,
+		Status: StatusIssues,
+		Issues: []Issue{
+			{Severity: "medium", Description: "naming"},
+			{Severity: "medium", Description: "old", Baseline: true},
+			{Severity: "low", Description: "nit"},
+		},
+	}}
+
+	tests := []struct {
+		threshold string
+		block     bool
+		reason    string
+	}{
+		{"high", false, ""},
+		{"", false, ""},
+		{"medium", true, "1 issue of medium severity or higher found"},
+		{"low", true, "2 issues of low severity or higher found"},
+	}
+	for _, tt := range tests {
+		if got := ShouldBlockAt(results, true, tt.threshold); got != tt.block {
+			t.Errorf("ShouldBlockAt(%q) = %v, want %v", tt.threshold, got, tt.block)
+		}
+		if got := GetBlockReasonAt(results, tt.threshold); got != tt.reason {
+			t.Errorf("GetBlockReasonAt(%q) = %q, want %q", tt.threshold, got, tt.reason)
+		}
+	}
+	if ShouldBlockAt(results, false, SeverityLow) {
+		t.Error("ShouldBlockAt() should not block with blocking disabled")
+	}
+}
+
+func TestSummarize_CountsSkippedReviews(t *testing.T) {
+	results := []*Result{
+		{Mode: ModeSecurity, Status: StatusSkipped, Summary: "Skipped by user"},
+		{Mode: ModeStyle, Status: StatusNoIssues},
+	}
+
+	summary := Summarize(results)
+	if summary.SkippedReviews != 1 {
+		t.Fatalf("expected SkippedReviews 1, got %d", summary.SkippedReviews)
+	}
+	if summary.FailedReviews != 0 {
+		t.Fatalf("expected FailedReviews 0, got %d", summary.FailedReviews)
+	}
+}
+
+func TestRunner_MarksReviewsCutShortByDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	runner := NewRunner(func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
+		if mode == ModeStyle {
+			return &Result{Mode: mode, Status: StatusNoIssues}, nil
+		}
+		<-ctx.Done()
+		return &Result{Mode: mode, Status: StatusFailed, Error: "request timed out"}, nil
+	}, nil)
+
+	results := runner.Run(ctx, []Mode{ModeStyle, ModeSecurity}, diff.NewDiff("diff"))
+	if results[0].Status != StatusNoIssues {
+		t.Errorf("finished review status = %s, want %s", results[0].Status, StatusNoIssues)
+	}
+	if results[1].Status != StatusTimedOut {
+		t.Errorf("cut short review status = %s, want %s", results[1].Status, StatusTimedOut)
+	}
+
+	summary := Summarize(results)
+	if summary.TimedOutReviews != 1 || summary.FailedReviews != 0 {
+		t.Errorf("summary = %+v, want 1 timed out and 0 failed reviews", summary)
+	}
+}
+
+func TestRunner_ModeTimeoutCutsShortOnlyTheStuckMode(t *testing.T) {
+	runner := NewRunner(func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
+		if mode == ModeStyle {
+			return &Result{Mode: mode, Status: StatusNoIssues}, nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, nil)
+	runner.SetModeTimeout(20 * time.Millisecond)
+
+	results := runner.Run(context.Background(), []Mode{ModeStyle, ModeSecurity}, diff.NewDiff("diff"))
+	if results[0].Status != StatusNoIssues {
+		t.Errorf("finished review status = %s, want %s", results[0].Status, StatusNoIssues)
+	}
+	if results[1].Status != StatusTimedOut {
+		t.Errorf("stuck review status = %s, want %s", results[1].Status, StatusTimedOut)
+	}
+}
+
+func TestWithModeTimeout_NoLimit(t *testing.T) {
+	ctx, cancel := WithModeTimeout(context.Background(), 0)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("expected no deadline for a zero timeout")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("ctx.Err() = %v, want %v after cancel", ctx.Err(), context.Canceled)
+	}
+}
+
+func TestMarkTimedOut_KeepsOtherFailures(t *testing.T) {
+	result := &Result{Mode: ModeDocs, Status: StatusFailed, Error: "boom"}
+	if got := MarkTimedOut(context.Background(), result); got.Status != StatusFailed {
+		t.Errorf("status = %s, want %s without a deadline", got.Status, StatusFailed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := MarkTimedOut(ctx, result); got.Status != StatusFailed {
+		t.Errorf("status = %s, want %s after a cancellation", got.Status, StatusFailed)
+	}
+}
+
+func TestRunner_LimiterQueuesReviews(t *testing.T) {
+	modes := AllModes()
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	runner := NewRunner(
+		func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
+			return &Result{Mode: mode, Status: StatusNoIssues}, nil
+		},
+		func(mode Mode, status Status) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch status {
+			case StatusRunning:
+				running++
+				peak = max(peak, running)
+			case StatusDone, StatusFailed:
+				running--
+			}
+		},
+	)
+	runner.SetLimiter(NewLimiter(2, 0))
+
+	results := runner.Run(context.Background(), modes, diff.NewDiff("diff"))
+	for i, r := range results {
+		if r == nil || r.Status != StatusNoIssues {
+			t.Fatalf("result[%d] = %+v, want a completed review", i, r)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("peak reviews running = %d, want at most 2", peak)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	original := &Result{Mode: ModeSecurity, Status: StatusIssues, Issues: []Issue{
+		{Severity: SeverityMedium, Description: "Missing input validation.", Location: "api/handler.go:42", Confidence: confidence(0.4)},
+		{Severity: SeverityLow, Description: "Unbounded request body", Location: "api/handler.go:50"},
+	}}
+	results := []*Result{
+		original,
+		{Mode: ModeErrors, Status: StatusIssues, Issues: []Issue{
+			{Severity: SeverityHigh, Description: "missing   input validation", Location: "api/handler.go:42", Confidence: confidence(0.8),
+				Fix: &Fix{Available: true, Code: "if id == \"\" { return errBadID }"}},
+		}},
+		{Mode: ModeStyle, Status: StatusIssues, Issues: []Issue{
+			{Severity: SeverityLow, Description: "Missing input validation", Location: "api/handler.go:43"},
+			{Severity: SeverityLow, Description: "Unclear name", Location: ""},
+		}},
+		nil,
+	}
+
+	merged := MergeDuplicates(results)
+	if len(merged) != len(results) || merged[3] != nil {
+		t.Fatalf("MergeDuplicates() = %v, want one result per input", merged)
+	}
+
+	kept := merged[0].Issues[0]
+	if !slices.Equal(kept.AlsoReportedBy, []Mode{ModeErrors}) {
+		t.Errorf("AlsoReportedBy = %v, want [errors]", kept.AlsoReportedBy)
+	}
+	if kept.Severity != SeverityHigh {
+		t.Errorf("severity = %s, want the higher of the two", kept.Severity)
+	}
+	if kept.Confidence == nil || *kept.Confidence != 0.8 {
+		t.Errorf("confidence = %v, want the higher of the two", kept.Confidence)
+	}
+	if kept.Fix == nil || !kept.Fix.Available {
+		t.Errorf("fix = %+v, want the duplicate's fix", kept.Fix)
+	}
+	if len(merged[1].Issues) != 0 || merged[1].Status != StatusNoIssues {
+		t.Errorf("errors result = %+v, want its only issue merged away", merged[1])
+	}
+	if len(merged[2].Issues) != 2 {
+		t.Errorf("style issues = %+v, want issues on other lines or without a location kept", merged[2].Issues)
+	}
+
+	if original.Issues[0].AlsoReportedBy != nil || original.Issues[0].Severity != SeverityMedium {
+		t.Errorf("original issue = %+v, want it unchanged", original.Issues[0])
+	}
+	if summary := Summarize(merged); summary.IssuesFound != 4 || summary.Merged != 1 || summary.HighSeverity != 1 {
+		t.Errorf("Summarize() = %+v, want 4 issues with 1 merged", summary)
+	}
+}
+
+func TestRunner_MergesDuplicateIssues(t *testing.T) {
+	runner := NewRunner(func(ctx context.Context, mode Mode, diff *diff.Diff) (*Result, error) {
+		return &Result{Mode: mode, Status: StatusIssues, Issues: []Issue{
+			{Severity: SeverityMedium, Description: "Error ignored", Location: "main.go:7"},
+		}}, nil
+	}, nil)
+
+	results := runner.Run(context.Background(), []Mode{ModeErrors, ModeSecurity}, diff.NewDiff("diff"))
+	if len(results[0].Issues) != 1 || len(results[1].Issues) != 0 {
+		t.Fatalf("issues = %+v and %+v, want the duplicate merged into the first mode", results[0].Issues, results[1].Issues)
+	}
+	if !slices.Equal(results[0].Issues[0].AlsoReportedBy, []Mode{ModeSecurity}) {
+		t.Errorf("AlsoReportedBy = %v, want [security]", results[0].Issues[0].AlsoReportedBy)
+	}
+}
@@ -0,0 +1,335 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// secretRule is a kind of secret the scanner recognizes by its format
+type secretRule struct {
+	name     string
+	severity string
+	pattern  *regexp.Regexp
+}
+
+// secretRules are checked in order, so more specific formats come first
+var secretRules = []secretRule{
+	{"private key", SeverityHigh, privateKeyBegin},
+	{"AWS access key ID", SeverityHigh, regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", SeverityHigh, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"GitLab token", SeverityHigh, regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{"Slack token", SeverityHigh, regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Stripe secret key", SeverityHigh, regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}`)},
+	{"Google API key", SeverityHigh, regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Anthropic API key", SeverityHigh, regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"JSON Web Token", SeverityMedium, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// privateKeyBegin and privateKeyEnd match the lines starting and ending a
+// PEM private key
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+)
+
+// privateKeyBlock matches a whole PEM private key, from its BEGIN line to
+// its END line
+var privateKeyBlock = regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+
+// secretAssignment matches a quoted value assigned to a name suggesting a
+// credential, such as password = "..." or "apiKey": "..."
+var secretAssignment = regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api_?key|access_?key|private_?key|credentials?)[a-z0-9_]*["']?\s*(?::=|=|:)\s*["']([^"'\s]{12,})["']`)
+
+// secretLiteral matches quoted strings long enough to be keys or tokens
+var secretLiteral = regexp.MustCompile(`["']([A-Za-z0-9+/=_-]{32,})["']`)
+
+// Entropy thresholds, in bits per character. Assigned values only need to
+// look random; bare literals must look more random than hex digests, whose
+// characters carry at most 4 bits.
+const (
+	assignmentEntropy = 3.5
+	literalEntropy    = 4.5
+)
+
+// ScanSecrets looks for credentials in the lines a diff adds, by the formats
+// of well-known tokens and the entropy of quoted values, and returns one issue
+// per line they are found on. It is deterministic and does not call the AI,
+// so what it finds is reported even when a review misses it.
+func ScanSecrets(d *diff.Diff) []Issue {
+	var issues []Issue
+	for _, f := range d.Files {
+		if f.IsBinary() {
+			continue
+		}
+		for _, h := range f.Hunks {
+			for _, line := range h.Body() {
+				if line.Kind != diff.LineAdded {
+					continue
+				}
+				if issue, ok := scanSecretLine(line.Text); ok {
+					issue.Location = fmt.Sprintf("%s:%d", f.Path, line.New)
+					issues = append(issues, issue)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// scanSecretLine returns an issue for the first secret found in line
+func scanSecretLine(line string) (Issue, bool) {
+	for _, rule := range secretRules {
+		if value := rule.pattern.FindString(line); value != "" && !isPlaceholder(value) {
+			return secretIssue(rule.severity, "Possible "+rule.name, value), true
+		}
+	}
+	for _, m := range secretAssignment.FindAllStringSubmatch(line, -1) {
+		if value := m[1]; !isPlaceholder(value) && entropy(value) >= assignmentEntropy {
+			return secretIssue(SeverityMedium, "Possible hardcoded credential", value), true
+		}
+	}
+	for _, m := range secretLiteral.FindAllStringSubmatch(line, -1) {
+		if value := m[1]; !isPlaceholder(value) && entropy(value) >= literalEntropy {
+			return secretIssue(SeverityMedium, "High-entropy string that may be a secret", value), true
+		}
+	}
+	return Issue{}, false
+}
+
+// secretIssue returns the issue for a secret found. It gives the length of
+// the value and none of its characters, since the description goes into the
+// report and into the prompt of the secrets review.
+func secretIssue(severity, what, value string) Issue {
+	return Issue{
+		Severity:    severity,
+		Description: fmt.Sprintf("%s added (%d characters), found by the local secrets scanner", what, len(value)),
+		Fix: &Fix{
+			Available: false,
+			Reason:    "A committed secret must be revoked, since it stays in the repository's history",
+			Alternatives: []string{
+				"Revoke or rotate the secret",
+				"Read it from the environment or a secret manager instead",
+			},
+		},
+	}
+}
+
+// isPlaceholder reports whether value looks like an example or a reference to
+// a secret kept elsewhere rather than a secret
+func isPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, marker := range []string{"example", "placeholder", "changeme", "dummy", "xxxx", "${", "{{", "<", "os.getenv", "process.env"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var bits float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Redacted replaces the secrets removed by RedactSecrets
+const Redacted = "[REDACTED]"
+
+// RedactSecrets replaces what ScanSecrets would report as a secret anywhere in
+// text, not only in the lines a diff adds, with Redacted. Private keys are
+// replaced whole. Returns the redacted text and the number of secrets
+// replaced.
+func RedactSecrets(text string) (string, int) {
+	n := 0
+	text = redact(text, func(string) { n++ })
+	return text, n
+}
+
+// redact replaces the secrets in text with Redacted, calling found with the
+// kind of each one
+func redact(text string, found func(kind string)) string {
+	text = privateKeyBlock.ReplaceAllStringFunc(text, func(string) string {
+		found("private key")
+		return Redacted
+	})
+	for _, rule := range secretRules {
+		text = rule.pattern.ReplaceAllStringFunc(text, func(value string) string {
+			if isPlaceholder(value) {
+				return value
+			}
+			found(rule.name)
+			return Redacted
+		})
+	}
+	for _, r := range []struct {
+		pattern *regexp.Regexp
+		entropy float64
+		kind    string
+	}{{secretAssignment, assignmentEntropy, "hardcoded credential"}, {secretLiteral, literalEntropy, "high-entropy string"}} {
+		var b strings.Builder
+		last := 0
+		for _, m := range r.pattern.FindAllStringSubmatchIndex(text, -1) {
+			value := text[m[2]:m[3]]
+			if isPlaceholder(value) || entropy(value) < r.entropy {
+				continue
+			}
+			b.WriteString(text[last:m[2]])
+			b.WriteString(Redacted)
+			last = m[3]
+			found(r.kind)
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+	return text
+}
+
+// Redaction is a secret RedactDiff removed from a diff
+type Redaction struct {
+	Kind     string `json:"kind"`               // What it looked like, such as "AWS access key ID"
+	Location string `json:"location,omitempty"` // file:line, in the old version of the file for removed lines
+}
+
+// envAssignment matches a KEY=value line of a .env file
+var envAssignment = regexp.MustCompile(`^(\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_.]*\s*=\s*)(\S.*?)\s*$`)
+
+// RedactDiff replaces the secrets on every line of d, whether added, removed
+// or context, with Redacted, so the diff can be sent to the model without
+// them. Besides what RedactSecrets recognizes, it replaces the lines of
+// private keys split over several lines and every value of .env files. The
+// preamble, such as the message of a patch, is redacted with RedactSecrets,
+// without locations. Returns the redacted diff and what was replaced where;
+// d is not changed, and is returned as it is if nothing was found.
+func RedactDiff(d *diff.Diff) (*diff.Diff, []Redaction) {
+	var found []Redaction
+	redacted := &diff.Diff{Files: make([]*diff.File, len(d.Files))}
+	redacted.Preamble = redact(d.Preamble, func(kind string) { found = append(found, Redaction{Kind: kind}) })
+
+	for fi, f := range d.Files {
+		env := isEnvFile(f.Path)
+		redactedFile := &diff.File{Path: f.Path, Header: f.Header, Hunks: make([]*diff.Hunk, len(f.Hunks))}
+		redacted.Files[fi] = redactedFile
+		for hi, h := range f.Hunks {
+			lines := slices.Clone(h.Lines)
+			redactedFile.Hunks[hi] = &diff.Hunk{Header: h.Header, Lines: lines}
+			inKey := false
+			for i, line := range h.Body() {
+				if line.Kind == diff.LineNoNewline {
+					continue
+				}
+				n := line.New
+				if line.Kind == diff.LineRemoved {
+					n = line.Old
+				}
+				location := fmt.Sprintf("%s:%d", f.Path, n)
+
+				s := line.Text
+				switch m := envAssignment.FindStringSubmatch(s); {
+				case inKey:
+					// The key was reported at its BEGIN line
+					if privateKeyEnd.MatchString(s) {
+						inKey = false
+					} else {
+						s = Redacted
+					}
+				case env && m != nil && strings.Trim(m[2], `"'`) != "" && !isPlaceholder(m[2]):
+					s = m[1] + Redacted
+					found = append(found, Redaction{Kind: ".env value", Location: location})
+				default:
+					inKey = privateKeyBegin.MatchString(s) && !privateKeyEnd.MatchString(s)
+					s = redact(s, func(kind string) { found = append(found, Redaction{Kind: kind, Location: location}) })
+				}
+				if s != line.Text {
+					lines[i] = lines[i][:1] + s
+				}
+			}
+		}
+	}
+	if len(found) == 0 {
+		return d, nil
+	}
+	return redacted, found
+}
+
+// isEnvFile reports whether the file at p holds environment variables, such as
+// .env or .env.production, rather than an example of them
+func isEnvFile(p string) bool {
+	base := path.Base(p)
+	if base != ".env" && !strings.HasPrefix(base, ".env.") && !strings.HasSuffix(base, ".env") {
+		return false
+	}
+	for _, suffix := range []string{".sample", ".template", ".dist"} {
+		if strings.HasSuffix(base, suffix) {
+			return false
+		}
+	}
+	return !isPlaceholder(base)
+}
+
+// MergeSecrets adds the issues found by ScanSecrets to result, the result of
+// a secrets review, unless the review already reported an issue on their
+// line. A review that failed still reports them, keeping its error.
+func MergeSecrets(result *Result, found []Issue) {
+	reported := make(map[string]bool, len(result.Issues))
+	for _, issue := range result.Issues {
+		reported[issue.Location] = true
+	}
+	added := 0
+	for _, issue := range found {
+		if reported[issue.Location] {
+			continue
+		}
+		reported[issue.Location] = true
+		result.Issues = append(result.Issues, issue)
+		added++
+	}
+	if added == 0 {
+		return
+	}
+	switch result.Status {
+	case StatusFailed, StatusTimedOut:
+		result.Summary = fmt.Sprintf("The review did not finish; %d possible secret(s) found by the local scanner", added)
+	case StatusNoIssues:
+		result.Summary = fmt.Sprintf("%d possible secret(s) found by the local scanner", added)
+	}
+	result.Status = StatusIssues
+}
+
+// SecretsDetector adds the secrets mode to the modes the detector it wraps
+// returns whenever ScanSecrets finds something, so possible secrets are
+// always reviewed.
+type SecretsDetector struct {
+	next Detector
+}
+
+// NewSecretsDetector returns a SecretsDetector wrapping next
+func NewSecretsDetector(next Detector) *SecretsDetector {
+	return &SecretsDetector{next: next}
+}
+
+// Detect returns the modes next detects, with the secrets mode added if the
+// diff adds possible secrets
+func (d *SecretsDetector) Detect(ctx context.Context, diff *diff.Diff) ([]Mode, string, error) {
+	modes, reasoning, err := d.next.Detect(ctx, diff)
+	if err != nil || slices.Contains(modes, ModeSecrets) || len(ScanSecrets(diff)) == 0 {
+		return modes, reasoning, err
+	}
+	return append(modes, ModeSecrets), reasoning + "; possible secrets found by the local scanner", nil
+}
@@ -0,0 +1,171 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Canonical severity levels.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// DefaultSeverityMap maps severity names models commonly use instead of the
+// canonical levels. Keys are lowercase.
+var DefaultSeverityMap = map[string]string{
+	"critical":      SeverityHigh,
+	"blocker":       SeverityHigh,
+	"severe":        SeverityHigh,
+	"error":         SeverityHigh,
+	"major":         SeverityMedium,
+	"moderate":      SeverityMedium,
+	"warning":       SeverityMedium,
+	"warn":          SeverityMedium,
+	"minor":         SeverityLow,
+	"info":          SeverityLow,
+	"informational": SeverityLow,
+	"note":          SeverityLow,
+	"trivial":       SeverityLow,
+	"nit":           SeverityLow,
+	"suggestion":    SeverityLow,
+}
+
+// UnknownSeverity is the level given to issues whose severity is not recognized.
+const UnknownSeverity = SeverityMedium
+
+// SeverityNormalizer maps the severities reported by a model onto the
+// canonical high/medium/low levels.
+type SeverityNormalizer struct {
+	mapping map[string]string
+}
+
+// NewSeverityNormalizer returns a normalizer using DefaultSeverityMap with the
+// given overrides applied. Override keys are matched case-insensitively and
+// values must be canonical levels.
+func NewSeverityNormalizer(overrides map[string]string) (*SeverityNormalizer, error) {
+	mapping := make(map[string]string, len(DefaultSeverityMap)+len(overrides))
+	for k, v := range DefaultSeverityMap {
+		mapping[k] = v
+	}
+	for k, v := range overrides {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if !isCanonicalSeverity(v) {
+			return nil, fmt.Errorf("severity %q maps to %q, expected high, medium or low", k, v)
+		}
+		mapping[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return &SeverityNormalizer{mapping: mapping}, nil
+}
+
+// DefaultSeverityNormalizer returns a normalizer using DefaultSeverityMap.
+func DefaultSeverityNormalizer() *SeverityNormalizer {
+	n, _ := NewSeverityNormalizer(nil)
+	return n
+}
+
+// Normalize returns the canonical level for a reported severity and whether
+// it was recognized. Unrecognized severities return UnknownSeverity.
+func (n *SeverityNormalizer) Normalize(severity string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(severity))
+	if isCanonicalSeverity(key) {
+		return key, true
+	}
+	if level, ok := n.mapping[key]; ok {
+		return level, true
+	}
+	return UnknownSeverity, false
+}
+
+// NormalizeResult rewrites the severity of each issue in r to a canonical
+// level. The reported value is kept in RawSeverity when it differs, and issues
+// with unrecognized severities are flagged with SeverityUnknown.
+func (n *SeverityNormalizer) NormalizeResult(r *Result) {
+	if r == nil {
+		return
+	}
+	for i := range r.Issues {
+		issue := &r.Issues[i]
+		level, ok := n.Normalize(issue.Severity)
+		if level != issue.Severity {
+			issue.RawSeverity = issue.Severity
+		}
+		issue.Severity = level
+		issue.SeverityUnknown = !ok
+	}
+}
+
+// isCanonicalSeverity reports whether s is high, medium or low
+func isCanonicalSeverity(s string) bool {
+	return s == SeverityHigh || s == SeverityMedium || s == SeverityLow
+}
+
+// severityOrder lists the canonical levels from lowest to highest
+var severityOrder = []string{SeverityLow, SeverityMedium, SeverityHigh}
+
+// RaiseSeverity returns the level above severity, or severity itself if it is
+// already the highest or not a canonical level.
+func RaiseSeverity(severity string) string {
+	return shiftSeverity(severity, 1)
+}
+
+// LowerSeverity returns the level below severity, or severity itself if it is
+// already the lowest or not a canonical level.
+func LowerSeverity(severity string) string {
+	return shiftSeverity(severity, -1)
+}
+
+// SeverityAtLeast reports whether severity is threshold or above. Levels that
+// are not canonical are never at least another.
+func SeverityAtLeast(severity, threshold string) bool {
+	rank, limit := -1, -1
+	for i, level := range severityOrder {
+		if level == severity {
+			rank = i
+		}
+		if level == threshold {
+			limit = i
+		}
+	}
+	return rank >= 0 && limit >= 0 && rank >= limit
+}
+
+// shiftSeverity moves severity by steps levels, staying within the canonical ones
+func shiftSeverity(severity string, steps int) string {
+	for i, level := range severityOrder {
+		if level == severity {
+			return severityOrder[min(max(i+steps, 0), len(severityOrder)-1)]
+		}
+	}
+	return severity
+}
+
+// SeverityLabels maps canonical severity levels to the labels shown for them
+// in place of the upper-case level names.
+type SeverityLabels map[string]string
+
+// NewSeverityLabels returns labels for the given levels. Keys are matched
+// case-insensitively and must be canonical levels; empty labels are ignored.
+func NewSeverityLabels(labels map[string]string) (SeverityLabels, error) {
+	l := make(SeverityLabels, len(labels))
+	for k, v := range labels {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !isCanonicalSeverity(k) {
+			return nil, fmt.Errorf("label given for severity %q, expected high, medium or low", k)
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			l[k] = v
+		}
+	}
+	return l, nil
+}
+
+// Label returns the label for a severity level, or the level in upper case if
+// no label is set.
+func (l SeverityLabels) Label(severity string) string {
+	if label, ok := l[severity]; ok {
+		return label
+	}
+	return strings.ToUpper(severity)
+}
@@ -0,0 +1,119 @@
+package review
+
+import "testing"
+
+func TestSeverityNormalizer_Normalize(t *testing.T) {
+	n := DefaultSeverityNormalizer()
+
+	tests := []struct {
+		input string
+		want  string
+		known bool
+	}{
+		{"high", SeverityHigh, true},
+		{" Medium ", SeverityMedium, true},
+		{"CRITICAL", SeverityHigh, true},
+		{"warning", SeverityMedium, true},
+		{"info", SeverityLow, true},
+		{"catastrophic", UnknownSeverity, false},
+		{"", UnknownSeverity, false},
+	}
+
+	for _, tt := range tests {
+		got, known := n.Normalize(tt.input)
+		if got != tt.want || known != tt.known {
+			t.Errorf("Normalize(%q) = (%q, %v), want (%q, %v)", tt.input, got, known, tt.want, tt.known)
+		}
+	}
+}
+
+func TestNewSeverityNormalizer_Overrides(t *testing.T) {
+	n, err := NewSeverityNormalizer(map[string]string{"Warning": "high", "p0": "HIGH"})
+	if err != nil {
+		t.Fatalf("NewSeverityNormalizer() error = %v", err)
+	}
+	if got, _ := n.Normalize("warning"); got != SeverityHigh {
+		t.Errorf("Normalize(warning) = %q, want override %q", got, SeverityHigh)
+	}
+	if got, known := n.Normalize("P0"); got != SeverityHigh || !known {
+		t.Errorf("Normalize(P0) = (%q, %v), want (high, true)", got, known)
+	}
+	if got, _ := n.Normalize("critical"); got != SeverityHigh {
+		t.Errorf("defaults should still apply, Normalize(critical) = %q", got)
+	}
+}
+
+func TestNewSeverityNormalizer_RejectsInvalidLevel(t *testing.T) {
+	if _, err := NewSeverityNormalizer(map[string]string{"warning": "urgent"}); err == nil {
+		t.Error("expected error for a non-canonical target level")
+	}
+}
+
+func TestSeverityNormalizer_NormalizeResult(t *testing.T) {
+	r := &Result{Issues: []Issue{
+		{Severity: "high"},
+		{Severity: "Critical"},
+		{Severity: "whatever"},
+	}}
+
+	DefaultSeverityNormalizer().NormalizeResult(r)
+
+	if r.Issues[0].Severity != SeverityHigh || r.Issues[0].RawSeverity != "" {
+		t.Errorf("canonical severity should be unchanged, got %+v", r.Issues[0])
+	}
+	if r.Issues[1].Severity != SeverityHigh || r.Issues[1].RawSeverity != "Critical" || r.Issues[1].SeverityUnknown {
+		t.Errorf("critical should map to high and keep the raw value, got %+v", r.Issues[1])
+	}
+	if r.Issues[2].Severity != UnknownSeverity || !r.Issues[2].SeverityUnknown || r.Issues[2].RawSeverity != "whatever" {
+		t.Errorf("unknown severity should be flagged, got %+v", r.Issues[2])
+	}
+	if !r.HasHighSeverityIssues() {
+		t.Error("normalized critical issue should count as high severity")
+	}
+
+	summary := Summarize([]*Result{r})
+	if summary.HighSeverity != 2 || summary.MediumSeverity != 1 || summary.UnknownSeverity != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestSeverityLabels_Label(t *testing.T) {
+	labels, err := NewSeverityLabels(map[string]string{"High": "[!!] HIGH", "low": " "})
+	if err != nil {
+		t.Fatalf("NewSeverityLabels() error = %v", err)
+	}
+	if got := labels.Label(SeverityHigh); got != "[!!] HIGH" {
+		t.Errorf("Label(high) = %q, want the configured label", got)
+	}
+	if got := labels.Label(SeverityLow); got != "LOW" {
+		t.Errorf("Label(low) = %q, want the level name for an empty label", got)
+	}
+	if got := SeverityLabels(nil).Label(SeverityMedium); got != "MEDIUM" {
+		t.Errorf("nil labels Label(medium) = %q, want MEDIUM", got)
+	}
+}
+
+func TestNewSeverityLabels_RejectsUnknownLevel(t *testing.T) {
+	if _, err := NewSeverityLabels(map[string]string{"critical": "CRIT"}); err == nil {
+		t.Error("expected error for a label on a non-canonical level")
+	}
+}
+
+func TestRaiseLowerSeverity(t *testing.T) {
+	tests := []struct {
+		severity, raised, lowered string
+	}{
+		{SeverityLow, SeverityMedium, SeverityLow},
+		{SeverityMedium, SeverityHigh, SeverityLow},
+		{SeverityHigh, SeverityHigh, SeverityMedium},
+		{"critical", "critical", "critical"},
+	}
+	for _, tt := range tests {
+		if got := RaiseSeverity(tt.severity); got != tt.raised {
+			t.Errorf("RaiseSeverity(%q) = %q, want %q", tt.severity, got, tt.raised)
+		}
+		if got := LowerSeverity(tt.severity); got != tt.lowered {
+			t.Errorf("LowerSeverity(%q) = %q, want %q", tt.severity, got, tt.lowered)
+		}
+	}
+}
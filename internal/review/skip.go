@@ -0,0 +1,74 @@
+package review
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/buker/revi/internal/git/diff"
+)
+
+// SmartSkipDetector narrows the review of diffs that only touch documentation
+// to the docs mode, and of diffs that only touch tests to the testing and
+// errors modes, without asking the detector it wraps. Other diffs are passed
+// on to it.
+type SmartSkipDetector struct {
+	next Detector
+}
+
+// NewSmartSkipDetector returns a SmartSkipDetector passing the diffs it does
+// not narrow to next.
+func NewSmartSkipDetector(next Detector) *SmartSkipDetector {
+	return &SmartSkipDetector{next: next}
+}
+
+// Detect returns the narrowed modes for doc-only and test-only diffs, and the
+// modes next detects otherwise
+func (d *SmartSkipDetector) Detect(ctx context.Context, diff *diff.Diff) ([]Mode, string, error) {
+	if modes, reasoning, ok := NarrowModes(diff); ok {
+		return modes, reasoning, nil
+	}
+	return d.next.Detect(ctx, diff)
+}
+
+// NarrowModes returns the modes worth running on a diff whose files are all
+// documentation or all tests, with the reason, and false for any other diff.
+func NarrowModes(d *diff.Diff) ([]Mode, string, bool) {
+	paths := d.Paths()
+	if len(paths) == 0 {
+		return nil, "", false
+	}
+	docs, tests := true, true
+	for _, p := range paths {
+		docs = docs && isDocPath(p)
+		tests = tests && isTestPath(p)
+	}
+	switch {
+	case docs:
+		return []Mode{ModeDocs}, "Only documentation changed, running the docs review", true
+	case tests:
+		return []Mode{ModeTesting, ModeErrors}, "Only tests changed, running the testing and errors reviews", true
+	}
+	return nil, "", false
+}
+
+// isDocPath reports whether p is documentation, by the rules commit messages
+// built without AI use
+func isDocPath(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown", ".rst", ".adoc", ".txt":
+		return true
+	}
+	return strings.HasPrefix(p, "docs/") || strings.Contains(p, "/docs/")
+}
+
+// isTestPath reports whether p holds tests, by the rules commit messages
+// built without AI use
+func isTestPath(p string) bool {
+	base := path.Base(p)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasPrefix(base, "test_") ||
+		strings.HasPrefix(p, "test/") || strings.HasPrefix(p, "tests/") ||
+		strings.Contains(p, "/test/") || strings.Contains(p, "/tests/")
+}
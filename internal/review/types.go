@@ -3,6 +3,16 @@
 // result aggregation, and blocking logic for high-severity issues.
 package review
 
+import (
+	"errors"
+	"strings"
+)
+
+// ErrAuthRequired is returned by a review function when the AI backend rejects
+// the session's credentials, for example because the login expired mid-run.
+// Callers can pause the remaining work until the user logs in again.
+var ErrAuthRequired = errors.New("claude CLI authentication required, run 'claude login' to authenticate")
+
 // Mode represents a review mode type
 type Mode string
 
@@ -13,6 +23,10 @@ const (
 	ModeErrors      Mode = "errors"
 	ModeTesting     Mode = "testing"
 	ModeDocs        Mode = "docs"
+	ModeSecrets     Mode = "secrets"
+	// ModeLint reports the findings of the project's linters. It is not
+	// reviewed by the AI, so it is not one of AllModes.
+	ModeLint Mode = "lint"
 )
 
 // AllModes returns all available review modes
@@ -24,6 +38,7 @@ func AllModes() []Mode {
 		ModeErrors,
 		ModeTesting,
 		ModeDocs,
+		ModeSecrets,
 	}
 }
 
@@ -60,6 +75,14 @@ func GetModeInfo(mode Mode) ModeInfo {
 			Name:        "Documentation",
 			Description: "Missing comments, unclear names, outdated comments, API docs",
 		},
+		ModeSecrets: {
+			Name:        "Secrets",
+			Description: "Hardcoded credentials, API keys, tokens, private keys, connection strings",
+		},
+		ModeLint: {
+			Name:        "Linters",
+			Description: "Findings of the linters configured in lint.linters",
+		},
 	}
 	return info[mode]
 }
@@ -74,14 +97,41 @@ const (
 	StatusFailed   Status = "failed"
 	StatusIssues   Status = "issues_found"
 	StatusNoIssues Status = "no_issues"
+	StatusSkipped  Status = "skipped"
+	StatusTimedOut Status = "timed_out"
 )
 
 // Issue represents a single issue found during review
 type Issue struct {
-	Severity    string `json:"severity"` // high, medium, low
-	Description string `json:"description"`
-	Location    string `json:"location,omitempty"` // file:line if available
-	Fix         *Fix   `json:"fix,omitempty"`
+	Severity        string   `json:"severity"` // high, medium, low
+	Description     string   `json:"description"`
+	Location        string   `json:"location,omitempty"` // file:line if available
+	Fix             *Fix     `json:"fix,omitempty"`
+	Agreement       string   `json:"agreement,omitempty"`        // set by CrossCheck: both, primary_only, secondary_only
+	RawSeverity     string   `json:"raw_severity,omitempty"`     // severity as reported by the model, if normalized
+	SeverityUnknown bool     `json:"severity_unknown,omitempty"` // the reported severity was not recognized
+	URL             string   `json:"url,omitempty"`              // permalink to the location on the repository host
+	Author          string   `json:"author,omitempty"`           // last author of the lines around the location, from git blame
+	Baseline        bool     `json:"baseline,omitempty"`         // recorded in the repository's baseline, so it does not block
+	TriagedFrom     string   `json:"triaged_from,omitempty"`     // severity the reviewer gave before a triage decision changed it
+	AlsoReportedBy  []Mode   `json:"also_reported_by,omitempty"` // other modes that reported the same issue, set by MergeDuplicates
+	Confidence      *float64 `json:"confidence,omitempty"`       // how sure the reviewer is that the issue is real, from 0 to 1; nil if not reported
+}
+
+// FileLine returns the file and line of the issue's location.
+// The line is 0 if the location has no line number.
+func (i Issue) FileLine() (string, int) {
+	return splitLocation(i.Location)
+}
+
+// AlsoReportedByNames returns the display names of the other modes that
+// reported the issue, comma-separated, or "" if none did
+func (i Issue) AlsoReportedByNames() string {
+	names := make([]string, len(i.AlsoReportedBy))
+	for j, mode := range i.AlsoReportedBy {
+		names[j] = GetModeInfo(mode).Name
+	}
+	return strings.Join(names, ", ")
 }
 
 // Fix represents a suggested fix for an issue.
@@ -115,6 +165,10 @@ type Result struct {
 	Issues      []Issue  `json:"issues,omitempty"`
 	Suggestions []string `json:"suggestions,omitempty"`
 	Error       string   `json:"error,omitempty"`
+	Raw         string   `json:"raw,omitempty"`         // Response of a review whose result could not be parsed
+	Suppressed  int      `json:"suppressed,omitempty"`  // Issues removed by .reviignore rules or revi:ignore annotations
+	Unconfident int      `json:"unconfident,omitempty"` // Issues dropped for a confidence below review.min_confidence
+	Known       int      `json:"known,omitempty"`       // Issues dropped by --baseline because an earlier run reported them
 }
 
 // HasIssues returns true if the result contains issues
@@ -125,13 +179,50 @@ func (r *Result) HasIssues() bool {
 // HasHighSeverityIssues returns true if any issues are high severity
 func (r *Result) HasHighSeverityIssues() bool {
 	for _, issue := range r.Issues {
-		if issue.Severity == "high" {
+		if issue.Severity == SeverityHigh {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBlockingIssues returns true if any issues are high severity and not
+// recorded in the repository's baseline
+func (r *Result) HasBlockingIssues() bool {
+	return r.HasBlockingIssuesAt(SeverityHigh)
+}
+
+// HasBlockingIssuesAt returns true if any issues are of threshold severity or
+// above and not recorded in the repository's baseline. An empty threshold is high.
+func (r *Result) HasBlockingIssuesAt(threshold string) bool {
+	if threshold == "" {
+		threshold = SeverityHigh
+	}
+	for _, issue := range r.Issues {
+		if SeverityAtLeast(issue.Severity, threshold) && !issue.Baseline {
 			return true
 		}
 	}
 	return false
 }
 
+// PromoteSuggestion replaces suggestion with issue, making it a tracked issue
+// of the result. Returns false if the result has no such suggestion.
+func (r *Result) PromoteSuggestion(suggestion string, issue Issue) bool {
+	for i, s := range r.Suggestions {
+		if s != suggestion {
+			continue
+		}
+		r.Suggestions = append(r.Suggestions[:i:i], r.Suggestions[i+1:]...)
+		r.Issues = append(r.Issues, issue)
+		if r.Status == StatusNoIssues {
+			r.Status = StatusIssues
+		}
+		return true
+	}
+	return false
+}
+
 // DetectionResult represents the result of mode auto-detection
 type DetectionResult struct {
 	Modes     []Mode `json:"modes"`
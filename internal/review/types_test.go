@@ -221,11 +221,11 @@ func TestResult_HasHighSeverityIssues_OnlyHighIssue(t *testing.T) {
 // Tests for AllModes()
 // =============================================================================
 
-func TestAllModes_ReturnsAllSixModes(t *testing.T) {
+func TestAllModes_ReturnsAllSevenModes(t *testing.T) {
 	modes := AllModes()
 
-	if len(modes) != 6 {
-		t.Errorf("AllModes() returned %d modes, want 6", len(modes))
+	if len(modes) != 7 {
+		t.Errorf("AllModes() returned %d modes, want 7", len(modes))
 	}
 }
 
@@ -239,6 +239,7 @@ func TestAllModes_ContainsExpectedModes(t *testing.T) {
 		ModeErrors,
 		ModeTesting,
 		ModeDocs,
+		ModeSecrets,
 	}
 
 	modeSet := make(map[Mode]bool)
@@ -282,3 +283,42 @@ func TestGetModeInfo_AllModesHaveInfo(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Tests for Result.PromoteSuggestion()
+// =============================================================================
+
+func TestResult_PromoteSuggestion(t *testing.T) {
+	r := &Result{
+		Mode:        ModeErrors,
+		Status:      StatusNoIssues,
+		Suggestions: []string{"first", "second", "third"},
+	}
+
+	if !r.PromoteSuggestion("second", Issue{Severity: SeverityLow, Description: "promoted"}) {
+		t.Fatal("PromoteSuggestion() = false, want true")
+	}
+	if len(r.Suggestions) != 2 || r.Suggestions[0] != "first" || r.Suggestions[1] != "third" {
+		t.Errorf("Suggestions = %v, want [first third]", r.Suggestions)
+	}
+	if len(r.Issues) != 1 || r.Issues[0].Description != "promoted" {
+		t.Errorf("Issues = %v, want the promoted issue", r.Issues)
+	}
+	if r.Status != StatusIssues {
+		t.Errorf("Status = %s, want %s", r.Status, StatusIssues)
+	}
+
+	if r.PromoteSuggestion("missing", Issue{}) {
+		t.Error("PromoteSuggestion() of an unknown suggestion = true, want false")
+	}
+}
+
+func TestIssue_AlsoReportedByNames(t *testing.T) {
+	issue := Issue{AlsoReportedBy: []Mode{ModeErrors, ModeSecurity}}
+	if got := issue.AlsoReportedByNames(); got != "Error Handling, Security" {
+		t.Errorf("AlsoReportedByNames() = %q, want %q", got, "Error Handling, Security")
+	}
+	if got := (Issue{}).AlsoReportedByNames(); got != "" {
+		t.Errorf("AlsoReportedByNames() without other modes = %q, want empty", got)
+	}
+}
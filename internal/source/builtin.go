@@ -0,0 +1,345 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/buker/revi/internal/git"
+)
+
+// PullRequestRemote is the remote pull requests are fetched from.
+const PullRequestRemote = "origin"
+
+func init() {
+	Register(Kind{
+		Name:        Default,
+		Usage:       "Review staged changes (the default)",
+		Committable: true,
+		New: func(repo *git.Repository, _ string) Source {
+			return &staged{repo: repo}
+		},
+	})
+	Register(Kind{
+		Name:        "amend",
+		Usage:       "Use the changes of the last commit and any staged since, and amend it when committing",
+		Committable: true,
+		Amends:      true,
+		New: func(repo *git.Repository, _ string) Source {
+			return &amend{repo: repo}
+		},
+	})
+	Register(Kind{
+		Name:    "working-tree",
+		Aliases: []string{"unstaged"},
+		Usage:   "Review unstaged changes in the working tree instead of staged changes",
+		New: func(repo *git.Repository, _ string) Source {
+			return &workingTree{repo: repo}
+		},
+	})
+	Register(Kind{
+		Name:  "snapshot",
+		Usage: "Review working tree changes made since \"revi snapshot begin\"",
+		New: func(repo *git.Repository, _ string) Source {
+			return &snapshot{repo: repo}
+		},
+	})
+	Register(Kind{
+		Name:  "range",
+		Arg:   "RANGE",
+		Usage: "Review a revision range instead of staged changes (e.g. main..HEAD, main...feature)",
+		New: func(repo *git.Repository, spec string) Source {
+			return &revisionRange{repo: repo, spec: spec}
+		},
+	})
+	Register(Kind{
+		Name:  "commit",
+		Arg:   "REF",
+		Usage: "Review the changes introduced by a single commit",
+		New: func(repo *git.Repository, ref string) Source {
+			return &commit{repo: repo, ref: ref}
+		},
+	})
+	Register(Kind{
+		Name:  "merge",
+		Arg:   "REF",
+		Usage: "Review only the conflict-resolution hunks of a merge commit",
+		New: func(repo *git.Repository, ref string) Source {
+			return &merge{repo: repo, ref: ref}
+		},
+	})
+	Register(Kind{
+		Name:  "merge-resolution",
+		Usage: "Review only the conflict-resolution hunks of the merge in progress",
+		New: func(repo *git.Repository, _ string) Source {
+			return &mergeResolution{repo: repo}
+		},
+	})
+	Register(Kind{
+		Name:  "patch",
+		Arg:   "FILE",
+		Usage: "Review a unified diff read from a patch file (- for standard input)",
+		New: func(_ *git.Repository, path string) Source {
+			if path == "-" {
+				return NewReader("standard input", os.Stdin)
+			}
+			return &patchFile{path: path}
+		},
+	})
+	Register(Kind{
+		Name:  "stdin",
+		Usage: "Review a unified diff read from standard input",
+		New: func(_ *git.Repository, _ string) Source {
+			return NewReader("standard input", os.Stdin)
+		},
+	})
+	Register(Kind{
+		Name:  "pr",
+		Arg:   "NUMBER",
+		Usage: "Fetch a pull request from " + PullRequestRemote + " and review its changes against HEAD",
+		New: func(repo *git.Repository, number string) Source {
+			return &pullRequest{repo: repo, number: number}
+		},
+	})
+}
+
+// staged reviews the changes staged in the index
+type staged struct {
+	repo *git.Repository
+}
+
+func (s *staged) Describe() string { return "staged changes" }
+
+func (s *staged) Diff() (string, error) {
+	hasStagedChanges, err := s.repo.HasStagedChanges()
+	if err != nil {
+		return "", fmt.Errorf("failed to check staged changes: %w", err)
+	}
+	if !hasStagedChanges {
+		return "", noChanges("no staged changes found. Use 'git add' to stage files, or --working-tree to review unstaged changes")
+	}
+
+	diff, err := s.repo.GetStagedDiff()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	return diff, nil
+}
+
+// amend reviews what amending HEAD would commit: its changes and those
+// staged since
+type amend struct {
+	repo *git.Repository
+}
+
+func (s *amend) Describe() string { return "last commit and staged changes" }
+
+func (s *amend) Diff() (string, error) {
+	diff, err := s.repo.GetAmendDiff()
+	if errors.Is(err, git.ErrNoChangesBetween) {
+		return "", noChanges("amending the last commit would leave it empty")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for amending the last commit: %w", err)
+	}
+	return diff, nil
+}
+
+// workingTree reviews unstaged changes, including untracked files
+type workingTree struct {
+	repo *git.Repository
+}
+
+func (s *workingTree) Describe() string { return "working tree changes" }
+
+func (s *workingTree) Diff() (string, error) {
+	diff, err := s.repo.GetWorkingTreeDiff()
+	if errors.Is(err, git.ErrNoWorkingTreeChanges) {
+		return "", noChanges("no unstaged changes found in the working tree")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get working tree diff: %w", err)
+	}
+	return diff, nil
+}
+
+// snapshot reviews the working tree changes made since the last snapshot
+type snapshot struct {
+	repo *git.Repository
+}
+
+func (s *snapshot) Describe() string { return "changes since snapshot" }
+
+func (s *snapshot) Diff() (string, error) {
+	diff, err := s.repo.GetSnapshotDiff()
+	if errors.Is(err, git.ErrNoSnapshotChanges) {
+		return "", noChanges("no changes in the working tree since the snapshot")
+	}
+	if errors.Is(err, git.ErrNoSnapshot) {
+		return "", fmt.Errorf("%w; run \"revi snapshot begin\" first", err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get snapshot diff: %w", err)
+	}
+	return diff, nil
+}
+
+// revisionRange reviews an A..B or A...B revision range
+type revisionRange struct {
+	repo *git.Repository
+	spec string
+}
+
+func (s *revisionRange) Describe() string { return "range " + s.spec }
+
+func (s *revisionRange) Diff() (string, error) {
+	diff, err := s.repo.GetRangeDiff(s.spec)
+	if errors.Is(err, git.ErrNoChangesBetween) {
+		return "", noChanges("no changes found in range %s", s.spec)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for range %s: %w", s.spec, err)
+	}
+	return diff, nil
+}
+
+// commit reviews the changes introduced by a single commit
+type commit struct {
+	repo *git.Repository
+	ref  string
+}
+
+func (s *commit) Describe() string { return "commit " + s.ref }
+
+func (s *commit) Diff() (string, error) {
+	diff, err := s.repo.GetCommitDiff(s.ref)
+	if errors.Is(err, git.ErrNoChangesBetween) {
+		return "", noChanges("commit %s has no changes", s.ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for commit %s: %w", s.ref, err)
+	}
+	return diff, nil
+}
+
+// merge reviews the lines a merge commit has in none of its parents
+type merge struct {
+	repo *git.Repository
+	ref  string
+}
+
+func (s *merge) Describe() string { return "merge " + s.ref }
+
+func (s *merge) Diff() (string, error) {
+	diff, err := s.repo.GetMergeDiff(s.ref)
+	if errors.Is(err, git.ErrNoResolutionChanges) {
+		return "", noChanges("merge %s has no conflict resolution changes", s.ref)
+	}
+	if errors.Is(err, git.ErrNotAMerge) {
+		return "", fmt.Errorf("%w; use --commit to review it", err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for merge %s: %w", s.ref, err)
+	}
+	return diff, nil
+}
+
+// mergeResolution reviews the lines of the working tree that are in none of
+// the commits being merged
+type mergeResolution struct {
+	repo *git.Repository
+}
+
+func (s *mergeResolution) Describe() string { return "merge resolution" }
+
+func (s *mergeResolution) Diff() (string, error) {
+	diff, err := s.repo.GetMergeResolutionDiff()
+	if errors.Is(err, git.ErrNoResolutionChanges) {
+		return "", noChanges("no conflict resolution changes in the working tree")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge resolution diff: %w", err)
+	}
+	return diff, nil
+}
+
+// patchFile reviews a unified diff stored in a file
+type patchFile struct {
+	path string
+}
+
+func (s *patchFile) Describe() string { return "patch " + s.path }
+
+func (s *patchFile) Diff() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read patch file: %w", err)
+	}
+	return checkPatch(string(data), s.Describe())
+}
+
+// reader reviews a unified diff read from an io.Reader
+type reader struct {
+	name string
+	r    io.Reader
+}
+
+// NewReader returns a source that reads a unified diff from r.
+// name describes the reader in messages, e.g. "standard input".
+func NewReader(name string, r io.Reader) Source {
+	return &reader{name: name, r: r}
+}
+
+func (s *reader) Describe() string { return s.name }
+
+func (s *reader) Diff() (string, error) {
+	data, err := io.ReadAll(s.r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff from %s: %w", s.name, err)
+	}
+	return checkPatch(string(data), s.name)
+}
+
+// checkPatch rejects empty input and text that is not a unified diff
+func checkPatch(text, name string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", noChanges("no changes found in %s", name)
+	}
+	if !strings.Contains(text, "diff --git ") && !strings.Contains(text, "@@ ") {
+		return "", fmt.Errorf("%s does not contain a unified diff", name)
+	}
+	return text, nil
+}
+
+// pullRequest fetches a pull request and reviews its changes since it
+// diverged from HEAD
+type pullRequest struct {
+	repo   *git.Repository
+	number string
+}
+
+func (s *pullRequest) Describe() string { return "pull request #" + s.number }
+
+func (s *pullRequest) Diff() (string, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(s.number, "#"))
+	if err != nil || number <= 0 {
+		return "", fmt.Errorf("invalid pull request number %q", s.number)
+	}
+
+	ref, err := s.repo.FetchPullRequest(PullRequestRemote, number)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := s.repo.GetRangeDiff("HEAD..." + ref)
+	if errors.Is(err, git.ErrNoChangesBetween) {
+		return "", noChanges("pull request #%d has no changes compared with HEAD", number)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for pull request #%d: %w", number, err)
+	}
+	return diff, nil
+}
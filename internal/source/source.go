@@ -0,0 +1,101 @@
+// Package source abstracts where the diff under review comes from. Each kind of
+// source (staged changes, the working tree, changes since a working tree
+// snapshot, a revision range, a patch file, standard input, a pull request)
+// registers itself once, and commands select one through the flags generated
+// from the registry.
+package source
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/buker/revi/internal/git"
+)
+
+// Default is the name of the source used when no source flag is given.
+const Default = "staged"
+
+// ErrNoChanges is returned by Diff when the source has nothing to review.
+var ErrNoChanges = errors.New("no changes found")
+
+// Source produces the unified diff to review.
+type Source interface {
+	// Describe returns a short human-readable description, e.g. "range main..HEAD"
+	Describe() string
+	// Diff returns the unified diff, or an error matching ErrNoChanges if it is empty
+	Diff() (string, error)
+}
+
+// Kind describes a registered type of source and the flag that selects it.
+type Kind struct {
+	// Name is the source name and the name of the flag that selects it
+	Name string
+	// Aliases are additional flag names that select the source
+	Aliases []string
+	// Arg names the flag's value (e.g. "RANGE"); empty for boolean flags
+	Arg string
+	// Usage is the flag's help text
+	Usage string
+	// Committable reports whether the diff is the staged index, so committing
+	// after reviewing it commits exactly what was reviewed
+	Committable bool
+	// Amends reports whether committing replaces HEAD, whose changes the diff
+	// includes, instead of adding a commit
+	Amends bool
+	// New creates the source from the repository and the flag's value
+	New func(repo *git.Repository, arg string) Source
+}
+
+// FlagNames returns the kind's flag name followed by its aliases.
+func (k Kind) FlagNames() []string {
+	return append([]string{k.Name}, k.Aliases...)
+}
+
+var registry []Kind
+
+// Register adds a kind of source. It panics if the name is already registered,
+// since that is a programming error.
+func Register(k Kind) {
+	for _, existing := range registry {
+		if existing.Name == k.Name {
+			panic(fmt.Sprintf("source %q registered twice", k.Name))
+		}
+	}
+	registry = append(registry, k)
+}
+
+// Kinds returns all registered kinds in registration order.
+func Kinds() []Kind {
+	return append([]Kind(nil), registry...)
+}
+
+// Lookup returns the kind registered under name.
+func Lookup(name string) (Kind, bool) {
+	for _, k := range registry {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Kind{}, false
+}
+
+// NoChangesError carries a source-specific message for an empty diff.
+// It matches ErrNoChanges with errors.Is.
+type NoChangesError struct {
+	Message string
+}
+
+// Error returns the source-specific message.
+func (e *NoChangesError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is ErrNoChanges.
+func (e *NoChangesError) Is(target error) bool {
+	return target == ErrNoChanges
+}
+
+// noChanges returns a NoChangesError with a formatted message
+func noChanges(format string, args ...interface{}) error {
+	return &NoChangesError{Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,122 @@
+package source
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const samplePatch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,1 +1,1 @@
+-package old
++package main
+`
+
+// =============================================================================
+// Tests for the registry
+// =============================================================================
+
+func TestKinds_IncludeBuiltinSources(t *testing.T) {
+	for _, name := range []string{Default, "amend", "working-tree", "snapshot", "range", "commit", "merge", "merge-resolution", "patch", "stdin", "pr"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("expected %q source to be registered", name)
+		}
+	}
+}
+
+func TestKinds_OnlyStagedIsCommittable(t *testing.T) {
+	for _, kind := range Kinds() {
+		// Amending commits the index too, with the last commit's changes
+		if kind.Committable != (kind.Name == Default || kind.Amends) {
+			t.Errorf("%s: Committable = %v", kind.Name, kind.Committable)
+		}
+	}
+}
+
+func TestKind_FlagNames(t *testing.T) {
+	kind, _ := Lookup("working-tree")
+	names := kind.FlagNames()
+	if len(names) != 2 || names[0] != "working-tree" || names[1] != "unstaged" {
+		t.Errorf("FlagNames() = %v, want [working-tree unstaged]", names)
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Register to panic for a duplicate name")
+		}
+	}()
+	Register(Kind{Name: Default})
+}
+
+// =============================================================================
+// Tests for patch and reader sources
+// =============================================================================
+
+func TestNewReader_ReturnsPatch(t *testing.T) {
+	diff, err := NewReader("test input", strings.NewReader(samplePatch)).Diff()
+	if err != nil {
+		t.Fatalf("Diff() failed: %v", err)
+	}
+	if diff != samplePatch {
+		t.Errorf("Diff() = %q, want the input unchanged", diff)
+	}
+}
+
+func TestNewReader_EmptyInput(t *testing.T) {
+	_, err := NewReader("test input", strings.NewReader("\n")).Diff()
+	if !errors.Is(err, ErrNoChanges) {
+		t.Errorf("expected ErrNoChanges, got: %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "test input") {
+		t.Errorf("error should name the input, got: %v", err)
+	}
+}
+
+func TestNewReader_RejectsNonDiff(t *testing.T) {
+	_, err := NewReader("test input", strings.NewReader("hello world\n")).Diff()
+	if err == nil || errors.Is(err, ErrNoChanges) {
+		t.Errorf("expected an invalid diff error, got: %v", err)
+	}
+}
+
+func TestPatchSource_ReadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "change.patch")
+	if err := os.WriteFile(path, []byte(samplePatch), 0644); err != nil {
+		t.Fatalf("failed to write patch: %v", err)
+	}
+
+	kind, _ := Lookup("patch")
+	src := kind.New(nil, path)
+	if src.Describe() != "patch "+path {
+		t.Errorf("Describe() = %q", src.Describe())
+	}
+	diff, err := src.Diff()
+	if err != nil {
+		t.Fatalf("Diff() failed: %v", err)
+	}
+	if diff != samplePatch {
+		t.Errorf("Diff() = %q, want the file content", diff)
+	}
+}
+
+func TestPatchSource_MissingFile(t *testing.T) {
+	kind, _ := Lookup("patch")
+	if _, err := kind.New(nil, filepath.Join(t.TempDir(), "missing.patch")).Diff(); err == nil {
+		t.Error("expected error for a missing patch file")
+	}
+}
+
+func TestPullRequestSource_InvalidNumber(t *testing.T) {
+	kind, _ := Lookup("pr")
+	for _, number := range []string{"abc", "0", "-3"} {
+		if _, err := kind.New(nil, number).Diff(); err == nil {
+			t.Errorf("expected error for pull request number %q", number)
+		}
+	}
+}
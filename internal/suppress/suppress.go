@@ -0,0 +1,262 @@
+// Package suppress filters out review issues that a repository has chosen to
+// ignore, either with rules in a .reviignore file or with inline revi:ignore
+// annotations in the code. Suppressed issues are removed from results before
+// they are shown or considered for blocking, as are issues triaged as won't
+// fix; other triaged issues take their chosen severity. Issues recorded in the
+// repository's baseline are kept but marked so that they do not block, while
+// those an earlier run given with --baseline reported can be dropped.
+package suppress
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
+)
+
+// FileName is the name of the ignore file read from the repository root.
+const FileName = ".reviignore"
+
+// Annotation marks a line whose issues should be ignored, e.g.
+// "// revi:ignore security". It applies to its own line and the line below.
+const Annotation = "revi:ignore"
+
+// Rule is a single line of a .reviignore file:
+//
+//	PATH [MODES [PATTERN]]
+//
+// PATH is a glob matched against the issue's file ("dir/**" matches everything
+// below dir). MODES is a comma-separated list of review modes, or "*" for all.
+// PATTERN is matched case-insensitively anywhere in the issue description and
+// may contain "*" wildcards.
+type Rule struct {
+	Path    string
+	Modes   []review.Mode // Empty matches every mode
+	Pattern string        // Empty matches every description
+
+	pattern *regexp.Regexp
+}
+
+// Matches reports whether the rule suppresses issue found by mode.
+func (r Rule) Matches(mode review.Mode, issue review.Issue) bool {
+	file, _ := issue.FileLine()
+	if file == "" || !diff.MatchesAny(strings.TrimPrefix(file, "./"), []string{r.Path}) {
+		return false
+	}
+	if !matchesMode(r.Modes, mode) {
+		return false
+	}
+	return r.pattern == nil || r.pattern.MatchString(issue.Description)
+}
+
+// Parse reads rules from a .reviignore file. Blank lines and lines starting
+// with '#' are ignored.
+func Parse(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		rule := Rule{Path: fields[0]}
+		if len(fields) > 1 && fields[1] != "*" {
+			modes, err := parseModes(strings.Split(fields[1], ","))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", FileName, lineNo, err)
+			}
+			rule.Modes = modes
+		}
+		if len(fields) > 2 {
+			rule.Pattern = strings.Trim(strings.Join(fields[2:], " "), `"'`)
+			rule.pattern = compilePattern(rule.Pattern)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return rules, nil
+}
+
+// Load reads rules from the file at path. A missing file yields no rules.
+func Load(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", FileName, err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Filter decides which issues are suppressed by .reviignore rules and by
+// revi:ignore annotations found in the reviewed diff.
+type Filter struct {
+	rules    []Rule
+	inline   map[string]map[int][]review.Mode // file -> line -> modes (empty = all)
+	baseline *baseline.Baseline
+	triage   *triage.Triage
+	known    *baseline.Baseline
+}
+
+// New returns a Filter applying rules and the annotations in d.
+func New(rules []Rule, d *diff.Diff) *Filter {
+	return &Filter{rules: rules, inline: annotations(d)}
+}
+
+// SetBaseline makes Apply mark the issues recorded in b. A nil b marks none.
+func (f *Filter) SetBaseline(b *baseline.Baseline) {
+	f.baseline = b
+}
+
+// SetKnown makes DropKnown remove the issues recorded in b, those an earlier
+// run reported. A nil b removes none.
+func (f *Filter) SetKnown(b *baseline.Baseline) {
+	f.known = b
+}
+
+// DropKnown returns result without the issues set with SetKnown, as a copy.
+// Unlike Apply, it is used once the run has been recorded, so the history
+// keeps every issue for the next run to compare against.
+func (f *Filter) DropKnown(result *review.Result) *review.Result {
+	return f.known.Drop(result)
+}
+
+// SetTriage makes Apply follow the decisions recorded in t. A nil t records none.
+func (f *Filter) SetTriage(t *triage.Triage) {
+	f.triage = t
+}
+
+// Suppressed reports whether issue, found by mode, should be ignored.
+func (f *Filter) Suppressed(mode review.Mode, issue review.Issue) bool {
+	for _, rule := range f.rules {
+		if rule.Matches(mode, issue) {
+			return true
+		}
+	}
+
+	file, line := issue.FileLine()
+	if line == 0 {
+		return false
+	}
+	modes, ok := f.inline[strings.TrimPrefix(file, "./")][line]
+	return ok && matchesMode(modes, mode)
+}
+
+// Apply removes suppressed issues from result and records how many were
+// removed in result.Suppressed. A result left without issues is marked as
+// having none. Triage decisions are then applied, and remaining issues
+// recorded in the baseline are marked as such.
+func (f *Filter) Apply(result *review.Result) {
+	if result == nil || len(result.Issues) == 0 {
+		return
+	}
+	kept := result.Issues[:0]
+	for _, issue := range result.Issues {
+		if f.Suppressed(result.Mode, issue) {
+			result.Suppressed++
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	result.Issues = kept
+	if len(kept) == 0 && result.Status == review.StatusIssues {
+		result.Status = review.StatusNoIssues
+	}
+	f.triage.Apply(result)
+	f.baseline.Mark(result)
+}
+
+// annotations collects the revi:ignore annotations on added and context lines
+// of d, keyed by file and by each line they cover
+func annotations(d *diff.Diff) map[string]map[int][]review.Mode {
+	found := make(map[string]map[int][]review.Mode)
+	add := func(file string, line int, text string) {
+		_, rest, ok := strings.Cut(text, Annotation)
+		if !ok {
+			return
+		}
+		modes := leadingModes(strings.Fields(rest))
+		if found[file] == nil {
+			found[file] = make(map[int][]review.Mode)
+		}
+		found[file][line] = modes
+		found[file][line+1] = modes
+	}
+
+	for _, f := range d.Files {
+		for _, h := range f.Hunks {
+			for _, line := range h.Body() {
+				if line.New > 0 {
+					add(f.Path, line.New, line.Text)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// leadingModes returns the review modes at the start of words, stopping at the
+// first word that is not a mode so annotations can carry an explanation
+func leadingModes(words []string) []review.Mode {
+	var modes []review.Mode
+	for _, word := range words {
+		parsed, err := parseModes(strings.Split(strings.TrimSuffix(word, ","), ","))
+		if err != nil {
+			break
+		}
+		modes = append(modes, parsed...)
+	}
+	return modes
+}
+
+// parseModes validates mode names
+func parseModes(names []string) ([]review.Mode, error) {
+	var modes []review.Mode
+	for _, name := range names {
+		mode := review.Mode(strings.ToLower(strings.TrimSpace(name)))
+		if review.GetModeInfo(mode).Name == "" {
+			return nil, fmt.Errorf("unknown review mode %q", name)
+		}
+		modes = append(modes, mode)
+	}
+	return modes, nil
+}
+
+// matchesMode reports whether mode is in modes, treating empty modes as all
+func matchesMode(modes []review.Mode, mode review.Mode) bool {
+	if len(modes) == 0 {
+		return true
+	}
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePattern turns a description pattern with '*' wildcards into a
+// case-insensitive regular expression matching anywhere in the text
+func compilePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(parts, ".*"))
+}
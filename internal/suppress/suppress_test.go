@@ -0,0 +1,215 @@
+package suppress
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/git/diff"
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
+)
+
+// =============================================================================
+// Tests for Parse
+// =============================================================================
+
+func TestParse(t *testing.T) {
+	input := `# vendored code is not ours to fix
+vendor/**
+
+*.pb.go style,docs
+internal/legacy/** security "hardcoded*key"
+testdata/** * secret
+`
+	rules, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if len(rules) != 4 {
+		t.Fatalf("expected 4 rules, got %d", len(rules))
+	}
+
+	if rules[0].Path != "vendor/**" || rules[0].Modes != nil || rules[0].Pattern != "" {
+		t.Errorf("rules[0] = %+v", rules[0])
+	}
+	if len(rules[1].Modes) != 2 || rules[1].Modes[0] != review.ModeStyle || rules[1].Modes[1] != review.ModeDocs {
+		t.Errorf("rules[1].Modes = %v", rules[1].Modes)
+	}
+	if rules[2].Pattern != "hardcoded*key" {
+		t.Errorf("rules[2].Pattern = %q", rules[2].Pattern)
+	}
+	if rules[3].Modes != nil || rules[3].Pattern != "secret" {
+		t.Errorf("rules[3] = %+v", rules[3])
+	}
+}
+
+func TestParse_UnknownMode(t *testing.T) {
+	_, err := Parse(strings.NewReader("vendor/** securty\n"))
+	if err == nil || !strings.Contains(err.Error(), ".reviignore:1") {
+		t.Errorf("expected an error naming the line, got: %v", err)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	rules, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil || rules != nil {
+		t.Errorf("Load() = %v, %v; want no rules and no error", rules, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("vendor/**\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := Load(path)
+	if err != nil || len(rules) != 1 {
+		t.Errorf("Load() = %v, %v; want one rule", rules, err)
+	}
+}
+
+// =============================================================================
+// Tests for Filter
+// =============================================================================
+
+func TestFilter_Rules(t *testing.T) {
+	rules, err := Parse(strings.NewReader(`vendor/**
+*.pb.go style
+internal/legacy/** security hardcoded*key
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := New(rules, &diff.Diff{})
+
+	tests := []struct {
+		mode  review.Mode
+		issue review.Issue
+		want  bool
+	}{
+		{review.ModeSecurity, review.Issue{Location: "vendor/lib/x.go:3"}, true},
+		{review.ModeStyle, review.Issue{Location: "api/v1/api.pb.go:10"}, true},
+		{review.ModeErrors, review.Issue{Location: "api/v1/api.pb.go:10"}, false},
+		{review.ModeSecurity, review.Issue{Location: "internal/legacy/db.go:5", Description: "Hardcoded API key"}, true},
+		{review.ModeSecurity, review.Issue{Location: "internal/legacy/db.go:5", Description: "SQL injection"}, false},
+		{review.ModeSecurity, review.Issue{Location: "main.go:1"}, false},
+		{review.ModeSecurity, review.Issue{}, false},
+	}
+
+	for _, tt := range tests {
+		if got := f.Suppressed(tt.mode, tt.issue); got != tt.want {
+			t.Errorf("Suppressed(%s, %q %q) = %v, want %v", tt.mode, tt.issue.Location, tt.issue.Description, got, tt.want)
+		}
+	}
+}
+
+const annotatedDiff = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -10,3 +10,5 @@ func main() {
+ 	setup()
+-	old()
++	// revi:ignore security -- test fixture key
++	key := "abc123"
++	run(key) // revi:ignore
+ 	done()
+`
+
+func TestFilter_Annotations(t *testing.T) {
+	f := New(nil, diff.NewDiff(annotatedDiff))
+
+	tests := []struct {
+		mode     review.Mode
+		location string
+		want     bool
+	}{
+		{review.ModeSecurity, "main.go:11", true}, // the annotation line itself
+		{review.ModeSecurity, "main.go:12", true}, // the line below
+		{review.ModeStyle, "main.go:12", false},   // another mode
+		{review.ModeStyle, "main.go:13", true},    // annotation without modes
+		{review.ModeStyle, "main.go:14", true},    // line below it
+		{review.ModeSecurity, "main.go:10", false},
+		{review.ModeSecurity, "main.go", false},
+		{review.ModeSecurity, "other.go:12", false},
+	}
+
+	for _, tt := range tests {
+		if got := f.Suppressed(tt.mode, review.Issue{Location: tt.location}); got != tt.want {
+			t.Errorf("Suppressed(%s, %q) = %v, want %v", tt.mode, tt.location, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	rules, err := Parse(strings.NewReader("vendor/**\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &review.Result{
+		Mode:   review.ModeSecurity,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{
+			{Severity: "high", Location: "vendor/x.go:1"},
+			{Severity: "low", Location: "vendor/y.go:2"},
+		},
+	}
+
+	New(rules, &diff.Diff{}).Apply(result)
+	if len(result.Issues) != 0 {
+		t.Errorf("expected all issues suppressed, got %v", result.Issues)
+	}
+	if result.Suppressed != 2 {
+		t.Errorf("Suppressed = %d, want 2", result.Suppressed)
+	}
+	if result.Status != review.StatusNoIssues {
+		t.Errorf("Status = %s, want %s", result.Status, review.StatusNoIssues)
+	}
+	if review.ShouldBlock([]*review.Result{result}, true) {
+		t.Error("suppressed high-severity issues should not block")
+	}
+}
+
+func TestFilter_ApplyMarksBaseline(t *testing.T) {
+	known := review.Issue{Severity: "high", Description: "SQL injection", Location: "db.go:10"}
+	filter := New(nil, &diff.Diff{})
+	filter.SetBaseline(baseline.New([]*review.Result{{Mode: review.ModeSecurity, Issues: []review.Issue{known}}}))
+
+	result := &review.Result{
+		Mode:   review.ModeSecurity,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{
+			{Severity: "high", Description: "SQL injection", Location: "db.go:42"},
+			{Severity: "high", Description: "Command injection", Location: "db.go:50"},
+		},
+	}
+	filter.Apply(result)
+	if !result.Issues[0].Baseline || result.Issues[1].Baseline {
+		t.Errorf("Baseline = %v, %v; want only the recorded issue marked", result.Issues[0].Baseline, result.Issues[1].Baseline)
+	}
+	if len(result.Issues) != 2 {
+		t.Errorf("baseline issues should be kept, got %d issues", len(result.Issues))
+	}
+}
+
+func TestFilter_ApplyFollowsTriage(t *testing.T) {
+	decisions, err := triage.Load(filepath.Join(t.TempDir(), triage.FileName))
+	if err != nil {
+		t.Fatalf("triage.Load() failed: %v", err)
+	}
+	decisions.Record(review.ModeSecurity, review.Issue{Description: "Weak hash", Location: "h.go:1"}, triage.Decision{WontFix: true})
+	filter := New(nil, &diff.Diff{})
+	filter.SetTriage(decisions)
+
+	result := &review.Result{
+		Mode:   review.ModeSecurity,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{{Severity: "high", Description: "Weak hash", Location: "h.go:8"}},
+	}
+	filter.Apply(result)
+	if len(result.Issues) != 0 || result.Suppressed != 1 || result.Status != review.StatusNoIssues {
+		t.Errorf("result = %+v, want the won't-fix issue hidden", result)
+	}
+}
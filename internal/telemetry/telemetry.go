@@ -0,0 +1,112 @@
+// Package telemetry traces revi's pipeline stages with OpenTelemetry.
+// Tracing is off unless an OTLP endpoint is configured through the standard
+// OTEL_* environment variables; spans are then exported over OTLP/HTTP.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies revi's tracer
+const instrumentationName = "github.com/buker/revi"
+
+// Init installs a tracer provider exporting to the OTLP endpoint set in
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, unless
+// OTEL_SDK_DISABLED is true or OTEL_TRACES_EXPORTER is "none". Without an
+// endpoint spans are discarded. version is recorded as the service version.
+// The returned function flushes pending spans and must be called before exit.
+func Init(ctx context.Context, version string) (shutdown func(context.Context) error, err error) {
+	shutdown = func(context.Context) error { return nil }
+	if !enabled() {
+		return shutdown, nil
+	}
+	if protocol := protocol(); protocol != "http/protobuf" {
+		return shutdown, fmt.Errorf("unsupported OTLP protocol %q, only http/protobuf is supported", protocol)
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return shutdown, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("revi"), semconv.ServiceVersion(version)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return shutdown, fmt.Errorf("failed to build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: tracing: %v\n", err)
+	}))
+	return provider.Shutdown, nil
+}
+
+// enabled reports whether the environment asks for spans to be exported
+func enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "none":
+		return false
+	case "otlp":
+		return true
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// protocol returns the OTLP protocol requested for traces, http/protobuf by default
+func protocol() string {
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if value := os.Getenv(name); value != "" {
+			return strings.ToLower(value)
+		}
+	}
+	return "http/protobuf"
+}
+
+// ParentFromEnv returns ctx carrying the span context in the TRACEPARENT
+// environment variable, if set, so a CI job's trace can include revi's spans
+func ParentFromEnv(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{}
+	if parent := os.Getenv("TRACEPARENT"); parent != "" {
+		carrier.Set("traceparent", parent)
+	}
+	if state := os.Getenv("TRACESTATE"); state != "" {
+		carrier.Set("tracestate", state)
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
+
+// Start starts a span named name as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it as failed if err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
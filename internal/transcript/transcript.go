@@ -0,0 +1,230 @@
+// Package transcript keeps an audit log of what revi sends to the model and
+// what comes back. Each run writes one file of JSON lines to the transcript
+// directory, named after the run's ID, with every prompt and raw response
+// and the secrets the local scanner recognizes redacted.
+package transcript
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// Kinds of entries
+const (
+	KindStart    = "start"    // The run started; Text is its command line
+	KindPrompt   = "prompt"   // A prompt sent to the model
+	KindResponse = "response" // The model's raw response to the last prompt
+	KindError    = "error"    // The call failed; Text is the error
+)
+
+// ext is the extension of transcript files
+const ext = ".jsonl"
+
+// ErrNotFound is returned by Read when no run matches the ID.
+var ErrNotFound = errors.New("transcript not found")
+
+// Entry is a recorded event of a run
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Mode     string    `json:"mode,omitempty"`  // Review mode the call was for, if any
+	Model    string    `json:"model,omitempty"` // Model called
+	Text     string    `json:"text"`
+	Redacted int       `json:"redacted,omitempty"` // Secrets replaced in Text
+}
+
+// Run is the transcript of a run
+type Run struct {
+	ID      string
+	Entries []Entry
+}
+
+// Start returns the time of the run's first entry
+func (r Run) Start() time.Time {
+	if len(r.Entries) == 0 {
+		return time.Time{}
+	}
+	return r.Entries[0].Time
+}
+
+// Command returns the command line the run started with
+func (r Run) Command() string {
+	for _, e := range r.Entries {
+		if e.Kind == KindStart {
+			return e.Text
+		}
+	}
+	return ""
+}
+
+// Prompts returns the number of prompts the run sent
+func (r Run) Prompts() int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Kind == KindPrompt {
+			n++
+		}
+	}
+	return n
+}
+
+// Log appends the entries of one run to its transcript file. It is safe for
+// concurrent use. A nil Log records nothing.
+type Log struct {
+	mu   sync.Mutex
+	id   string
+	path string
+	err  error // First write error, reported by Close
+}
+
+// Open starts the transcript of a new run in dir, creating dir if needed, and
+// records its start with command.
+func Open(dir, command string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	now := time.Now()
+	l := &Log{id: newID(now)}
+	l.path = filepath.Join(dir, l.id+ext)
+	if err := l.write(Entry{Time: now, Kind: KindStart, Text: command}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ID returns the ID of the run
+func (l *Log) ID() string {
+	if l == nil {
+		return ""
+	}
+	return l.id
+}
+
+// Record appends an entry of kind for a call to model, redacting the secrets
+// in text. Write errors are kept for Close, so they never fail the call.
+func (l *Log) Record(kind, mode, model, text string) {
+	if l == nil {
+		return
+	}
+	text, n := review.RedactSecrets(text)
+	_ = l.write(Entry{Time: time.Now(), Kind: kind, Mode: mode, Model: model, Text: text, Redacted: n})
+}
+
+// Close returns the first error writing the transcript, if any
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// write appends e to the transcript file
+func (l *Log) write(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to write transcript: %w", err)
+		if l.err == nil {
+			l.err = err
+		}
+	}
+	return err
+}
+
+// List returns the IDs of the runs recorded in dir, oldest first
+func List(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript directory: %w", err)
+	}
+	var ids []string
+	for _, f := range files {
+		if id, ok := strings.CutSuffix(f.Name(), ext); ok && !f.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	// IDs start with the time of the run
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Read returns the transcript of the run in dir whose ID starts with id.
+// Lines that cannot be parsed are skipped.
+func Read(dir, id string) (Run, error) {
+	ids, err := List(dir)
+	if err != nil {
+		return Run{}, err
+	}
+	var matches []string
+	for _, candidate := range ids {
+		if id != "" && strings.HasPrefix(candidate, id) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Run{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	case 1:
+	default:
+		return Run{}, fmt.Errorf("run ID %s is ambiguous (%d matches)", id, len(matches))
+	}
+
+	f, err := os.Open(filepath.Join(dir, matches[0]+ext))
+	if err != nil {
+		return Run{}, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	run := Run{ID: matches[0]}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Kind == "" {
+			continue
+		}
+		run.Entries = append(run.Entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return Run{}, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return run, nil
+}
+
+// newID returns a run ID starting with the time of the run, so IDs sort in
+// the order runs started, followed by random characters to keep runs started
+// in the same second apart
+func newID(t time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return t.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
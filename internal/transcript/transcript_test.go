@@ -0,0 +1,119 @@
+package transcript
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLog_RecordsRun(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	log, err := Open(dir, "revi review --all")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	secret := "ghp_" + strings.Repeat("aB3dE6", 6)
+	log.Record(KindPrompt, "security", "claude-sonnet-4-5", "token := \""+secret+"\"")
+	log.Record(KindResponse, "security", "claude-sonnet-4-5", `{"summary": "ok"}`)
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	run, err := Read(dir, log.ID()[:10])
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if run.ID != log.ID() || len(run.Entries) != 3 {
+		t.Fatalf("run = %+v, want the start and two entries of %s", run, log.ID())
+	}
+	if run.Command() != "revi review --all" || run.Prompts() != 1 || run.Start().IsZero() {
+		t.Errorf("command = %q, prompts = %d, start = %v", run.Command(), run.Prompts(), run.Start())
+	}
+	prompt := run.Entries[1]
+	if strings.Contains(prompt.Text, secret) || prompt.Redacted != 1 {
+		t.Errorf("prompt = %+v, want the token redacted", prompt)
+	}
+	if prompt.Mode != "security" || prompt.Model != "claude-sonnet-4-5" {
+		t.Errorf("prompt = %+v, want its mode and model", prompt)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, log.ID()+ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("transcript permissions = %o, want 600", perm)
+	}
+}
+
+func TestLog_ConcurrentRecords(t *testing.T) {
+	dir := t.TempDir()
+	log, err := Open(dir, "revi review")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Record(KindPrompt, "", "haiku", strings.Repeat("x", 1000))
+		}()
+	}
+	wg.Wait()
+
+	run, err := Read(dir, log.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Prompts() != 20 {
+		t.Errorf("Prompts() = %d, want 20", run.Prompts())
+	}
+}
+
+func TestLog_Nil(t *testing.T) {
+	var log *Log
+	log.Record(KindPrompt, "", "", "text")
+	if log.ID() != "" || log.Close() != nil {
+		t.Error("nil Log should record nothing")
+	}
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20261018-120000-bbbbbb.jsonl", "20261017-090000-aaaaaa.jsonl", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "20261017-090000-aaaaaa" {
+		t.Errorf("List() = %v, want both runs, oldest first", ids)
+	}
+
+	if ids, err := List(filepath.Join(dir, "missing")); err != nil || ids != nil {
+		t.Errorf("List(missing) = %v, %v; want nothing", ids, err)
+	}
+}
+
+func TestRead_UnknownAndAmbiguousIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20261018-120000-aaaaaa.jsonl", "20261018-130000-bbbbbb.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Read(dir, "2025"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Read(2025) error = %v, want ErrNotFound", err)
+	}
+	if _, err := Read(dir, "20261018"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Read(20261018) error = %v, want ambiguous", err)
+	}
+}
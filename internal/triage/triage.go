@@ -0,0 +1,178 @@
+// Package triage records the decisions users make about review issues in the
+// TUI: a severity that differs from the one the reviewer gave, or that an
+// issue won't be fixed. Later reviews report triaged issues with the chosen
+// severity, which is also what blocking considers, and hide won't-fix issues.
+package triage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/buker/revi/internal/baseline"
+	"github.com/buker/revi/internal/review"
+)
+
+// FileName is the name of the triage file read from the repository root.
+const FileName = ".revi-triage.json"
+
+// Decision is what a user decided about an issue.
+type Decision struct {
+	Severity string // Severity to report the issue with; empty keeps the current one
+	WontFix  bool   // Hide the issue from later reviews
+}
+
+// Entry is the decision recorded for an issue. Issues are matched by
+// fingerprint, as in the baseline; the other fields tell readers of the file
+// what it stands for.
+type Entry struct {
+	Fingerprint string      `json:"fingerprint"`
+	Mode        review.Mode `json:"mode"`
+	File        string      `json:"file,omitempty"`
+	Description string      `json:"description"`
+	Severity    string      `json:"severity,omitempty"`
+	WontFix     bool        `json:"wont_fix,omitempty"`
+}
+
+// Triage is the set of decisions recorded for a repository.
+type Triage struct {
+	Issues []Entry `json:"issues"`
+
+	mu    sync.RWMutex
+	index map[string]int // fingerprint -> position in Issues
+}
+
+// Load reads the triage file at path. A missing file yields an empty triage.
+func Load(path string) (*Triage, error) {
+	t := &Triage{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.reindex()
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	t.reindex()
+	return t, nil
+}
+
+// reindex rebuilds the lookup from fingerprints to entries
+func (t *Triage) reindex() {
+	t.index = make(map[string]int, len(t.Issues))
+	for i, e := range t.Issues {
+		t.index[e.Fingerprint] = i
+	}
+}
+
+// Write saves the triage to the file at path.
+func (t *Triage) Write(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Issues == nil {
+		t.Issues = []Entry{}
+	}
+	// A stable order keeps changes to the file readable in review
+	sort.Slice(t.Issues, func(i, j int) bool {
+		a, c := t.Issues[i], t.Issues[j]
+		if a.File != c.File {
+			return a.File < c.File
+		}
+		if a.Mode != c.Mode {
+			return a.Mode < c.Mode
+		}
+		return a.Fingerprint < c.Fingerprint
+	})
+	t.reindex()
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode triage: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// Record applies decision to issue, found by mode, on top of any decision
+// recorded for it before.
+func (t *Triage) Record(mode review.Mode, issue review.Issue, decision Decision) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fp := baseline.Fingerprint(mode, issue)
+	i, ok := t.index[fp]
+	if !ok {
+		file, _ := issue.FileLine()
+		t.Issues = append(t.Issues, Entry{Fingerprint: fp, Mode: mode, File: file, Description: issue.Description})
+		i = len(t.Issues) - 1
+		t.index[fp] = i
+	}
+	if decision.Severity != "" {
+		t.Issues[i].Severity = decision.Severity
+	}
+	if decision.WontFix {
+		t.Issues[i].WontFix = true
+	}
+}
+
+// Lookup returns the decision recorded for issue, found by mode.
+func (t *Triage) Lookup(mode review.Mode, issue review.Issue) (Decision, bool) {
+	if t == nil {
+		return Decision{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i, ok := t.index[baseline.Fingerprint(mode, issue)]
+	if !ok {
+		return Decision{}, false
+	}
+	return Decision{Severity: t.Issues[i].Severity, WontFix: t.Issues[i].WontFix}, true
+}
+
+// Apply removes the won't-fix issues of result, counting them in
+// result.Suppressed, and gives the others their triaged severity. A result
+// left without issues is marked as having none.
+func (t *Triage) Apply(result *review.Result) {
+	if t == nil || result == nil || len(result.Issues) == 0 {
+		return
+	}
+	kept := result.Issues[:0]
+	for _, issue := range result.Issues {
+		decision, ok := t.Lookup(result.Mode, issue)
+		if ok && decision.WontFix {
+			result.Suppressed++
+			continue
+		}
+		if ok && decision.Severity != "" {
+			issue = Adjust(issue, decision.Severity)
+		}
+		kept = append(kept, issue)
+	}
+	result.Issues = kept
+	if len(kept) == 0 && result.Status == review.StatusIssues {
+		result.Status = review.StatusNoIssues
+	}
+}
+
+// Adjust returns issue with severity in place of the one the reviewer gave,
+// which is kept in TriagedFrom.
+func Adjust(issue review.Issue, severity string) review.Issue {
+	if issue.Severity == severity {
+		return issue
+	}
+	if issue.TriagedFrom == "" {
+		issue.TriagedFrom = issue.Severity
+	} else if issue.TriagedFrom == severity {
+		// Back to the reviewer's severity
+		issue.TriagedFrom = ""
+	}
+	issue.Severity = severity
+	return issue
+}
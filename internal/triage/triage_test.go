@@ -0,0 +1,88 @@
+package triage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+func TestLoad_MissingFileIsEmpty(t *testing.T) {
+	tr, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if _, ok := tr.Lookup(review.ModeStyle, review.Issue{Description: "x"}); ok {
+		t.Error("an empty triage should hold no decisions")
+	}
+}
+
+func TestRecord_WriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	tr, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	noisy := review.Issue{Severity: "high", Description: "Magic number 42", Location: "b.go:7"}
+	tr.Record(review.ModeStyle, noisy, Decision{Severity: "low"})
+	tr.Record(review.ModeStyle, noisy, Decision{WontFix: true})
+	tr.Record(review.ModeErrors, review.Issue{Severity: "low", Description: "Unchecked error", Location: "a.go:3"}, Decision{Severity: "high"})
+	if len(tr.Issues) != 2 {
+		t.Fatalf("Record() kept %d entries, want 2 (decisions on one issue merged)", len(tr.Issues))
+	}
+	if err := tr.Write(path); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if loaded.Issues[0].File != "a.go" {
+		t.Errorf("entries not sorted by file: %+v", loaded.Issues)
+	}
+	moved := review.Issue{Severity: "high", Description: "Magic number 17", Location: "b.go:30"}
+	if d, ok := loaded.Lookup(review.ModeStyle, moved); !ok || d.Severity != "low" || !d.WontFix {
+		t.Errorf("Lookup() = %+v, %v; want severity low and won't fix", d, ok)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tr, _ := Load(filepath.Join(t.TempDir(), FileName))
+	tr.Record(review.ModeSecurity, review.Issue{Description: "Weak hash", Location: "h.go:1"}, Decision{Severity: "medium"})
+	tr.Record(review.ModeSecurity, review.Issue{Description: "Debug endpoint", Location: "d.go:1"}, Decision{WontFix: true})
+
+	result := &review.Result{
+		Mode:   review.ModeSecurity,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{
+			{Severity: "high", Description: "Weak hash", Location: "h.go:9"},
+			{Severity: "high", Description: "Debug endpoint", Location: "d.go:4"},
+		},
+	}
+	tr.Apply(result)
+	if len(result.Issues) != 1 || result.Suppressed != 1 {
+		t.Fatalf("Apply() left %d issues, %d suppressed; want the won't-fix issue removed", len(result.Issues), result.Suppressed)
+	}
+	if got := result.Issues[0]; got.Severity != "medium" || got.TriagedFrom != "high" {
+		t.Errorf("issue severity = %q from %q, want medium from high", got.Severity, got.TriagedFrom)
+	}
+	if result.HasBlockingIssues() {
+		t.Error("a high-severity issue triaged down should not block")
+	}
+
+	var nilTriage *Triage
+	nilTriage.Apply(result)
+}
+
+func TestAdjust(t *testing.T) {
+	issue := Adjust(review.Issue{Severity: "high"}, "medium")
+	issue = Adjust(issue, "low")
+	if issue.Severity != "low" || issue.TriagedFrom != "high" {
+		t.Errorf("Adjust() = %q from %q, want low from high", issue.Severity, issue.TriagedFrom)
+	}
+	issue = Adjust(issue, "high")
+	if issue.Severity != "high" || issue.TriagedFrom != "" {
+		t.Errorf("Adjust() back to the reviewer's severity = %q from %q, want high with no triage", issue.Severity, issue.TriagedFrom)
+	}
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"github.com/buker/revi/internal/tui/views"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HunkPicker is a Bubble Tea model for choosing which hunks of the changed
+// files are staged. It quits once the choice is confirmed or cancelled.
+type HunkPicker struct {
+	view      *views.HunkStagingView
+	keys      KeyMap
+	confirmed bool
+}
+
+// NewHunkPicker creates a hunk picker starting from hunks as they are staged
+func NewHunkPicker(hunks []views.HunkItem) *HunkPicker {
+	return &HunkPicker{
+		view: views.NewHunkStagingView(hunks),
+		keys: DefaultKeyMap(),
+	}
+}
+
+// Init initializes the model
+func (p *HunkPicker) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages
+func (p *HunkPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.view.SetSize(msg.Width, msg.Height)
+		return p, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, p.keys.Confirm):
+			p.confirmed = true
+			return p, tea.Quit
+		case key.Matches(msg, p.keys.Escape), key.Matches(msg, p.keys.Cancel), key.Matches(msg, p.keys.Quit):
+			return p, tea.Quit
+		}
+		v, cmd := p.view.Update(msg)
+		p.view = v
+		return p, cmd
+	}
+	return p, nil
+}
+
+// View renders the model
+func (p *HunkPicker) View() string {
+	return p.view.View()
+}
+
+// Confirmed returns whether the user confirmed the choice of hunks
+func (p *HunkPicker) Confirmed() bool {
+	return p.confirmed
+}
+
+// Hunks returns the hunks with whether each was chosen to be staged
+func (p *HunkPicker) Hunks() []views.HunkItem {
+	return p.view.Hunks()
+}
+
+// PickHunks runs a hunk picker on the alternate screen and returns the hunks
+// with whether each was chosen to be staged. ok is false if the user
+// cancelled, in which case nothing should change.
+func PickHunks(hunks []views.HunkItem) (chosen []views.HunkItem, ok bool, err error) {
+	picker := NewHunkPicker(hunks)
+	if _, err := tea.NewProgram(picker, tea.WithAltScreen()).Run(); err != nil {
+		return nil, false, err
+	}
+	return picker.Hunks(), picker.Confirmed(), nil
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/buker/revi/internal/tui/tuitest"
+	"github.com/buker/revi/internal/tui/views"
+)
+
+// =============================================================================
+// Tests for HunkPicker
+// =============================================================================
+
+func TestHunkPicker_Scripted_ToggleAndConfirm(t *testing.T) {
+	d := tuitest.New(NewHunkPicker([]views.HunkItem{
+		{Path: "main.go", Header: "@@ -3,1 +3,1 @@", Lines: []string{"-old", "+new"}},
+		{Path: "main.go", Header: "@@ -9,0 +10,1 @@", Lines: []string{"+added"}, Staged: true},
+	}), 120, 40)
+	d.ExpectFrame(t, "1 of 2 hunk(s) staged", "[y] write to index")
+
+	d.Press(" ", "down", " ")
+	d.ExpectFrame(t, "1 of 2 hunk(s) staged", "[x] @@ -3,1 +3,1 @@", "[ ] @@ -9,0 +10,1 @@")
+	d.Press("y")
+	if !d.Quit() {
+		t.Fatal("y should quit the picker")
+	}
+	picker := d.Model().(*HunkPicker)
+	if !picker.Confirmed() {
+		t.Error("y should confirm the choice")
+	}
+	if hunks := picker.Hunks(); !hunks[0].Staged || hunks[1].Staged {
+		t.Errorf("Hunks() = %+v, want only the first staged", hunks)
+	}
+}
+
+func TestHunkPicker_Scripted_EscCancels(t *testing.T) {
+	d := tuitest.New(NewHunkPicker([]views.HunkItem{{Path: "main.go", Header: "@@ -1,1 +1,1 @@"}}), 120, 40)
+
+	d.Press(" ", "esc")
+	if !d.Quit() {
+		t.Fatal("esc should quit the picker")
+	}
+	if d.Model().(*HunkPicker).Confirmed() {
+		t.Error("esc should not confirm the choice")
+	}
+}
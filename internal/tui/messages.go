@@ -1,6 +1,9 @@
 package tui
 
-import "github.com/buker/revi/internal/review"
+import (
+	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
+)
 
 // MsgStreamContent is sent when streaming content is received from the AI.
 // This message allows the TUI to display progressive content updates
@@ -9,3 +12,59 @@ type MsgStreamContent struct {
 	Mode    review.Mode // The review mode this content belongs to (empty for detect/commit)
 	Content string      // The chunk of content received from the stream
 }
+
+// MsgAuthRequired is sent when a review mode is paused because the AI backend
+// rejected the session's credentials. The TUI asks the user to log in again
+// and resume.
+type MsgAuthRequired struct {
+	Mode review.Mode // The review mode that was paused
+}
+
+// MsgSuggestionPromoted is sent when promoting a review suggestion to an issue
+// has finished. Issue is nil and Error is set if the promotion failed.
+type MsgSuggestionPromoted struct {
+	Index      int           // Index of the suggestion in the issues table
+	Mode       review.Mode   // The review mode that made the suggestion
+	Suggestion string        // The suggestion text
+	Issue      *review.Issue // The issue the suggestion was promoted to
+	Error      string
+}
+
+// MsgCommitRegenerated is sent when the commit message has been regenerated
+// with the user's feedback. Error is set if it could not be, in which case
+// the message is unchanged.
+type MsgCommitRegenerated struct {
+	Message string
+	Error   string
+}
+
+// MsgIssueTriaged is sent when a triage decision about an issue has been
+// saved. Error is set if it could not be, in which case the issue is unchanged.
+type MsgIssueTriaged struct {
+	Index    int             // Index of the issue in the issues table
+	Mode     review.Mode     // The review mode that found the issue
+	Issue    review.Issue    // The issue as it was before the decision
+	Decision triage.Decision // The decision that was made
+	Error    string
+}
+
+// MsgIssuesTriaged is sent when triage decisions about several selected
+// issues have been saved
+type MsgIssuesTriaged struct {
+	Triaged []MsgIssueTriaged
+}
+
+// MsgIssuesExported is sent when selected issues have been exported. Path is
+// the file they were written to, or Error is set if they could not be.
+type MsgIssuesExported struct {
+	Count int
+	Path  string
+	Error string
+}
+
+// MsgCostEstimate is sent after mode detection with the estimated cost of the
+// reviews. When Confirm is set the reviews wait until the user starts them.
+type MsgCostEstimate struct {
+	Estimate string // Human-readable token and cost estimate
+	Confirm  bool   // Whether the estimate is above the confirmation threshold
+}
@@ -4,9 +4,15 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"sync"
 
+	"github.com/buker/revi/internal/fix"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
 	"github.com/buker/revi/internal/tui/views"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,6 +36,46 @@ const (
 // FixApplier is a function that applies a fix and returns an error if it fails
 type FixApplier func(*review.Fix) error
 
+// FixUndoer is a function that reverts an applied fix and returns an error if it fails
+type FixUndoer func(*review.Fix) error
+
+// FixPreviewer is a function that renders a fix as a unified diff hunk with context
+type FixPreviewer func(*review.Fix) (string, error)
+
+// FixSplitter is a function that divides a fix into hunks that can be
+// accepted one by one
+type FixSplitter func(*review.Fix) (*fix.SplitFix, error)
+
+// SuggestionPromoter is a function that turns a review suggestion into a tracked
+// issue with a fix attempt
+type SuggestionPromoter func(mode review.Mode, suggestion string) (*review.Issue, error)
+
+// IssueTriager is a function that saves a triage decision about an issue so
+// later reviews follow it
+type IssueTriager func(mode review.Mode, issue review.Issue, decision triage.Decision) error
+
+// IssueExporter is a function that writes issues, grouped by the review mode
+// that found them, to a file and returns its path
+type IssueExporter func(results []*review.Result) (string, error)
+
+// CommitRegenerator is a function that generates the commit message again,
+// revising message as feedback asks, e.g. "mention the migration"
+type CommitRegenerator func(message, feedback string) (string, error)
+
+// ModeCanceler is a function that cancels a single in-flight review mode
+type ModeCanceler func(review.Mode)
+
+// ModeAdder is a function that queues an additional review mode.
+// It returns false if the mode can no longer be added to the run.
+type ModeAdder func(review.Mode) bool
+
+// Resumer is a function that restarts reviews paused by an authentication failure
+type Resumer func()
+
+// CostConfirmer is a function that starts reviews held back because their
+// estimated cost needed confirmation
+type CostConfirmer func()
+
 // Model is the main Bubble Tea model that manages the TUI state and rendering.
 type Model struct {
 	state   State  // Current workflow phase
@@ -41,12 +87,31 @@ type Model struct {
 	results       []*review.Result // Collected review results
 	commitMessage string           // Generated commit message
 	confirmed     bool             // Whether user confirmed the commit
+	autoConfirm   bool             // Skip the confirm screen when no issues at the block threshold were found
+	commitOnly    bool             // The confirm screen was opened without a review; leaving it cancels
 	blocked       bool             // Whether commit was blocked
 	blockReason   string           // Reason for blocking
+	blockOnIssues bool             // Whether high-severity issues block, rechecked after triage
+
+	blockThreshold string // Lowest severity that blocks, high if empty
 
 	// Fix tracking
-	fixedIssues map[int]bool // Track which issues have been fixed (by index)
-	fixApplier  FixApplier   // Callback for applying fixes
+	fixedIssues map[int]bool       // Track which issues have been fixed (by index)
+	fixApplier  FixApplier         // Callback for applying fixes
+	fixUndoer   FixUndoer          // Callback for undoing applied fixes
+	fixPreview  FixPreviewer       // Callback for rendering fix previews with context
+	fixSplit    FixSplitter        // Callback for dividing fixes into hunks
+	fixHunks    *fix.SplitFix      // Hunks of the previewed fix, if it changes several places
+	promoter    SuggestionPromoter // Callback for promoting suggestions to issues
+	triager     IssueTriager       // Callback for saving triage decisions
+	exporter    IssueExporter      // Callback for exporting selected issues
+	regenerator CommitRegenerator  // Callback for regenerating the commit message with feedback
+
+	// Mode cancellation
+	modeCanceler ModeCanceler  // Callback for skipping a running review mode
+	modeAdder    ModeAdder     // Callback for queuing an additional review mode
+	resumer      Resumer       // Callback for resuming after re-authentication
+	costConfirm  CostConfirmer // Callback for starting reviews after confirming their cost
 
 	// View components
 	progressView *views.ProgressView
@@ -116,6 +181,23 @@ type MsgFixApplied struct {
 	IssueIndex int
 	Success    bool
 	Error      string
+	Rejected   bool        // The fix was rolled back because verification failed
+	Fix        *review.Fix // The fix applied instead of the issue's when only some of its hunks were accepted
+}
+
+// MsgFixUndone is sent when an applied fix has been undone
+type MsgFixUndone struct {
+	IssueIndex int
+	Success    bool
+	Error      string
+}
+
+// MsgAllFixesApplied is sent when every pending fix has been applied
+type MsgAllFixesApplied struct {
+	Applied  []int  // Indices of the issues whose fix was applied
+	Rejected []int  // Indices of the issues whose fix was rolled back by verification
+	Failed   int    // Number of fixes that could not be applied, including rejected ones
+	Error    string // Why the first failed fix could not be applied
 }
 
 // MsgQuit is sent to quit the application
@@ -147,6 +229,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case MsgModesDetected:
 		m.state = StateReviewing
 		m.progressView.SetModes(msg.Modes)
+		m.progressView.SetReasoning(msg.Reasoning)
 		return m, nil
 
 	case MsgReviewStarted:
@@ -156,6 +239,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case MsgReviewComplete:
 		if msg.Result != nil {
 			m.progressView.SetReviewComplete(msg.Result.Mode, msg.Result.Status, len(msg.Result.Issues))
+			if msg.Result.Status == review.StatusFailed {
+				m.progressView.SetReviewFailed(msg.Result.Mode, msg.Result.Error, msg.Result.Raw)
+			}
 		}
 		// Keep spinner ticking
 		pv, cmd := m.progressView.Update(msg)
@@ -163,6 +249,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 
+	case MsgCostEstimate:
+		m.progressView.SetCostEstimate(msg.Estimate, msg.Confirm)
+		return m, nil
+
+	case MsgAuthRequired:
+		m.progressView.SetReviewPaused(msg.Mode)
+		m.progressView.SetAuthRequired(true)
+		return m, nil
+
 	case MsgStreamContent:
 		// Handle streaming content updates during review
 		if m.state == StateReviewing || m.state == StateAnalyzing {
@@ -180,6 +275,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.blockReason = msg.Reason
 			m.issuesView.SetBlocked(true, msg.Reason)
 		}
+		if summary := review.Summarize(msg.Results); summary.TimedOutReviews > 0 {
+			m.issuesView.SetNotice(fmt.Sprintf("Partial review (timed out): %d of %d review(s) did not finish", summary.TimedOutReviews, summary.TotalReviews))
+		}
 		m.state = StateIssuesTable
 		return m, nil
 
@@ -189,6 +287,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mu.Unlock()
 		m.issuesView.SetCommitMessage(msg.Message)
 		m.commitView.SetCommitMessage(msg.Message)
+		if m.autoConfirm && !m.blocked && !review.ShouldBlockAt(m.results, true, m.blockThreshold) {
+			m.mu.Lock()
+			m.confirmed = true
+			m.mu.Unlock()
+			m.state = StateDone
+			return m, tea.Quit
+		}
 		return m, nil
 
 	case MsgError:
@@ -198,13 +303,112 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case MsgFixApplied:
 		if msg.Success {
+			if item := m.issuesView.IssueAt(msg.IssueIndex); item != nil && msg.Fix != nil {
+				// Undo looks the fix up by the one that was applied
+				item.Issue.Fix = msg.Fix
+			}
 			m.fixedIssues[msg.IssueIndex] = true
 			m.issuesView.MarkFixed(msg.IssueIndex)
+		} else if msg.Rejected {
+			m.issuesView.MarkRejected(msg.IssueIndex)
+			m.issuesView.SetNotice("Fix rejected: " + msg.Error)
 		}
 		// Return to issues table after fix
 		m.state = StateIssuesTable
 		return m, nil
 
+	case MsgAllFixesApplied:
+		for _, idx := range msg.Applied {
+			m.fixedIssues[idx] = true
+			m.issuesView.MarkFixed(idx)
+		}
+		for _, idx := range msg.Rejected {
+			m.issuesView.MarkRejected(idx)
+		}
+		notice := fmt.Sprintf("Applied %d fix(es), %d failed", len(msg.Applied), msg.Failed)
+		if msg.Error != "" {
+			notice += ": " + msg.Error
+		}
+		m.issuesView.SetNotice(notice)
+		return m, nil
+
+	case MsgFixUndone:
+		if msg.Success {
+			delete(m.fixedIssues, msg.IssueIndex)
+			m.issuesView.UnmarkFixed(msg.IssueIndex)
+			m.issuesView.SetNotice("")
+		} else {
+			m.issuesView.SetNotice("Could not undo fix: " + msg.Error)
+		}
+		return m, nil
+
+	case MsgSuggestionPromoted:
+		if msg.Issue == nil {
+			m.issuesView.SetPromoting(msg.Index, false)
+			m.issuesView.SetNotice("Could not promote suggestion: " + msg.Error)
+			return m, nil
+		}
+		m.issuesView.Promote(msg.Index, *msg.Issue)
+		m.issuesView.SetNotice("")
+		for _, r := range m.results {
+			if r != nil && r.Mode == msg.Mode && r.PromoteSuggestion(msg.Suggestion, *msg.Issue) {
+				break
+			}
+		}
+		return m, nil
+
+	case MsgIssueTriaged:
+		if msg.Error != "" {
+			m.issuesView.SetNotice("Could not save triage decision: " + msg.Error)
+			return m, nil
+		}
+		m.applyTriage(msg)
+		m.issuesView.SetNotice("")
+		return m, nil
+
+	case MsgIssuesTriaged:
+		failed := 0
+		notice := ""
+		for _, triaged := range msg.Triaged {
+			if triaged.Error != "" {
+				if failed == 0 {
+					notice = triaged.Error
+				}
+				failed++
+				continue
+			}
+			m.applyTriage(triaged)
+		}
+		if failed > 0 {
+			notice = fmt.Sprintf("Could not save %d triage decision(s): %s", failed, notice)
+		} else {
+			notice = fmt.Sprintf("Triaged %d issue(s)", len(msg.Triaged))
+		}
+		m.issuesView.SetNotice(notice)
+		return m, nil
+
+	case MsgIssuesExported:
+		if msg.Error != "" {
+			m.issuesView.SetNotice("Could not export issues: " + msg.Error)
+			return m, nil
+		}
+		m.issuesView.ClearSelection()
+		m.issuesView.SetNotice(fmt.Sprintf("Exported %d issue(s) to %s", msg.Count, msg.Path))
+		return m, nil
+
+	case MsgCommitRegenerated:
+		if msg.Error != "" {
+			m.commitView.SetRegenerateError(msg.Error)
+			return m, nil
+		}
+		m.mu.Lock()
+		m.commitMessage = msg.Message
+		m.mu.Unlock()
+		m.commitView.SetRegenerating(false)
+		m.issuesView.SetCommitMessage(msg.Message)
+		m.commitView.SetCommitMessage(msg.Message)
+		return m, nil
+
 	case MsgQuit:
 		return m, tea.Quit
 	}
@@ -216,11 +420,36 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	// Keep the search prompt's cursor blinking
+	if m.state == StateIssuesTable && m.issuesView.IsSearching() {
+		iv, cmd := m.issuesView.Update(msg)
+		m.issuesView = iv
+		cmds = append(cmds, cmd)
+	}
+
+	// Keep the feedback prompt's cursor blinking
+	if m.state == StateCommitConfirm && m.commitView.IsAskingFeedback() {
+		cv, cmd := m.commitView.Update(msg)
+		m.commitView = cv
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
 // handleKeyMsg handles keyboard input based on current state
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Keys typed into the issues search are text, but ctrl+c still quits
+	if m.state == StateIssuesTable && m.issuesView.IsSearching() && msg.Type != tea.KeyCtrlC {
+		iv, cmd := m.issuesView.Update(msg)
+		m.issuesView = iv
+		return m, cmd
+	}
+	// As are keys typed as feedback on the commit message
+	if m.state == StateCommitConfirm && m.commitView.IsAskingFeedback() && msg.Type != tea.KeyCtrlC {
+		return m.handleCommitConfirmKeys(msg)
+	}
+
 	// Global quit
 	if key.Matches(msg, m.keys.Quit) {
 		return m, tea.Quit
@@ -228,8 +457,7 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch m.state {
 	case StateReviewing:
-		// No interactive keys during review, just allow quit
-		return m, nil
+		return m.handleReviewingKeys(msg)
 
 	case StateIssuesTable:
 		return m.handleIssuesTableKeys(msg)
@@ -247,6 +475,71 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleReviewingKeys handles keys in the progress view while reviews run
+func (m *Model) handleReviewingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Reviews held back by their estimated cost only wait for enter or quit
+	if m.progressView.CostConfirmRequired() {
+		if key.Matches(msg, m.keys.Enter) && m.costConfirm != nil {
+			m.progressView.SetCostConfirmed()
+			m.costConfirm()
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.progressView.SelectPrev()
+
+	case key.Matches(msg, m.keys.Down):
+		m.progressView.SelectNext()
+
+	case key.Matches(msg, m.keys.Skip):
+		// Cancel only the selected mode; the others keep running
+		mode := m.progressView.SelectedMode()
+		if mode != "" && m.modeCanceler != nil && m.progressView.IsCancellable(mode) {
+			m.modeCanceler(mode)
+		}
+
+	case key.Matches(msg, m.keys.ToggleMode):
+		m.toggleMode(msg.String())
+
+	case key.Matches(msg, m.keys.Resume):
+		// Restart the paused reviews once the user has logged in again
+		if m.progressView.AuthRequired() && m.resumer != nil {
+			m.progressView.SetAuthRequired(false)
+			m.resumer()
+		}
+	}
+
+	return m, nil
+}
+
+// toggleMode adds or removes the mode bound to the given number key.
+// Modes can only be changed until the first review result comes in.
+func (m *Model) toggleMode(numKey string) {
+	if !m.progressView.CanChangeModes() || len(numKey) != 1 {
+		return
+	}
+	idx := int(numKey[0] - '1')
+	allModes := review.AllModes()
+	if idx < 0 || idx >= len(allModes) {
+		return
+	}
+	mode := allModes[idx]
+
+	if m.progressView.HasMode(mode) {
+		// Removing a mode that is already queued or running skips it
+		if m.modeCanceler != nil && m.progressView.IsCancellable(mode) {
+			m.modeCanceler(mode)
+		}
+		return
+	}
+
+	if m.modeAdder != nil && m.modeAdder(mode) {
+		m.progressView.AddMode(mode)
+	}
+}
+
 // handleIssuesTableKeys handles keys in the issues table view
 func (m *Model) handleIssuesTableKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
@@ -259,12 +552,58 @@ func (m *Model) handleIssuesTableKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Enter):
 		// Open issue detail modal
 		if item := m.issuesView.SelectedIssue(); item != nil {
-			m.detailModal.SetIssue(&item.Issue, item.Mode)
+			if item.Suggestion {
+				m.detailModal.SetSuggestion(item.Issue.Description, item.Mode)
+			} else {
+				m.detailModal.SetIssue(&item.Issue, item.Mode)
+			}
 			m.detailModal.SetSize(m.width, m.height)
 			m.state = StateIssueDetail
 		}
 		return m, nil
 
+	case key.Matches(msg, m.keys.Promote):
+		return m, m.promoteSelected()
+
+	case key.Matches(msg, m.keys.Undo):
+		return m, m.undoSelected()
+
+	case key.Matches(msg, m.keys.Raise):
+		return m, m.triageSelected(func(item *views.IssueItem) triage.Decision {
+			return triage.Decision{Severity: review.RaiseSeverity(item.Issue.Severity)}
+		})
+
+	case key.Matches(msg, m.keys.Lower):
+		return m, m.triageSelected(func(item *views.IssueItem) triage.Decision {
+			return triage.Decision{Severity: review.LowerSeverity(item.Issue.Severity)}
+		})
+
+	case key.Matches(msg, m.keys.WontFix):
+		return m, m.triageSelected(func(*views.IssueItem) triage.Decision {
+			return triage.Decision{WontFix: true}
+		})
+
+	case key.Matches(msg, m.keys.Select):
+		m.issuesView.ToggleSelected(m.issuesView.Cursor())
+		return m, nil
+
+	case key.Matches(msg, m.keys.SelectAll):
+		m.issuesView.ToggleSelectAll()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Export):
+		return m, m.exportSelected()
+
+	case key.Matches(msg, m.keys.Search):
+		return m, m.issuesView.StartSearch()
+
+	case key.Matches(msg, m.keys.Escape):
+		m.issuesView.SetFilter("")
+		return m, nil
+
+	case key.Matches(msg, m.keys.ApplyAll):
+		return m, m.applyAllFixes()
+
 	case key.Matches(msg, m.keys.Commit):
 		// Don't allow commit when blocked
 		if m.blocked {
@@ -287,11 +626,22 @@ func (m *Model) handleIssueDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state = StateIssuesTable
 		return m, nil
 
+	case key.Matches(msg, m.keys.Promote) && m.detailModal.IsSuggestion():
+		// Promote in the background and show progress in the table
+		m.state = StateIssuesTable
+		return m, m.promoteSelected()
+
 	case key.Matches(msg, m.keys.Apply):
 		// Open diff preview if fix available
 		if m.detailModal.HasFix() {
 			if item := m.issuesView.SelectedIssue(); item != nil && item.Issue.Fix != nil {
 				m.diffModal.SetFix(item.Issue.Fix)
+				if m.fixPreview != nil {
+					if preview, err := m.fixPreview(item.Issue.Fix); err == nil {
+						m.diffModal.SetPreview(preview)
+					}
+				}
+				m.setFixHunks(item.Issue.Fix)
 				m.diffModal.SetSize(m.width, m.height)
 				m.state = StateDiffPreview
 			}
@@ -306,14 +656,265 @@ func (m *Model) handleIssueDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// promoteSelected starts promoting the selected suggestion to an issue and
+// returns the command that reports the outcome, or nil if there is nothing to do
+func (m *Model) promoteSelected() tea.Cmd {
+	item := m.issuesView.SelectedIssue()
+	if item == nil || !item.Suggestion || item.Promoting || m.promoter == nil {
+		return nil
+	}
+	idx := m.issuesView.Cursor()
+	mode, suggestion := item.Mode, item.Issue.Description
+	m.issuesView.SetPromoting(idx, true)
+	m.issuesView.SetNotice("")
+
+	promoter := m.promoter
+	return func() tea.Msg {
+		issue, err := promoter(mode, suggestion)
+		if err != nil {
+			return MsgSuggestionPromoted{Index: idx, Mode: mode, Suggestion: suggestion, Error: err.Error()}
+		}
+		return MsgSuggestionPromoted{Index: idx, Mode: mode, Suggestion: suggestion, Issue: issue}
+	}
+}
+
+// triageSelected starts saving the decisions decide makes about the selected
+// issues, or the issue under the cursor if none are selected, and returns the
+// command that reports the outcome, or nil if there is nothing to do.
+// Suggestions, fixed issues and issues already triaged as won't fix are left
+// alone, as are decisions that would not change an issue's severity.
+func (m *Model) triageSelected(decide func(item *views.IssueItem) triage.Decision) tea.Cmd {
+	indices := m.issuesView.Selected()
+	if len(indices) == 0 {
+		indices = []int{m.issuesView.Cursor()}
+	}
+	var pending []MsgIssueTriaged
+	for _, idx := range indices {
+		item := m.issuesView.IssueAt(idx)
+		if item == nil || item.Suggestion || item.Fixed || item.WontFix {
+			continue
+		}
+		decision := decide(item)
+		if !decision.WontFix && decision.Severity == item.Issue.Severity {
+			continue
+		}
+		pending = append(pending, MsgIssueTriaged{Index: idx, Mode: item.Mode, Issue: item.Issue, Decision: decision})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	m.issuesView.ClearSelection()
+
+	// Decisions are saved one at a time since each rewrites the triage file
+	triager := m.triager
+	return func() tea.Msg {
+		for i := range pending {
+			if triager == nil {
+				continue
+			}
+			if err := triager(pending[i].Mode, pending[i].Issue, pending[i].Decision); err != nil {
+				pending[i].Error = err.Error()
+			}
+		}
+		if len(pending) == 1 {
+			return pending[0]
+		}
+		return MsgIssuesTriaged{Triaged: pending}
+	}
+}
+
+// exportSelected starts exporting the selected issues and returns the command
+// that reports the outcome, or nil if there is nothing to export
+func (m *Model) exportSelected() tea.Cmd {
+	indices := m.issuesView.Selected()
+	if len(indices) == 0 || m.exporter == nil {
+		return nil
+	}
+	// Group the issues by mode, keeping the order of the table
+	var results []*review.Result
+	byMode := make(map[review.Mode]*review.Result)
+	for _, idx := range indices {
+		item := m.issuesView.IssueAt(idx)
+		r, ok := byMode[item.Mode]
+		if !ok {
+			r = &review.Result{Mode: item.Mode, Status: review.StatusIssues}
+			byMode[item.Mode] = r
+			results = append(results, r)
+		}
+		r.Issues = append(r.Issues, item.Issue)
+	}
+
+	exporter := m.exporter
+	return func() tea.Msg {
+		path, err := exporter(results)
+		if err != nil {
+			return MsgIssuesExported{Count: len(indices), Error: err.Error()}
+		}
+		return MsgIssuesExported{Count: len(indices), Path: path}
+	}
+}
+
+// applyTriage updates the issues table and the results with a saved triage
+// decision, then checks again whether the commit is blocked. Issues triaged as
+// won't fix stay in the table but leave the results, as later reviews hide them.
+func (m *Model) applyTriage(msg MsgIssueTriaged) {
+	if msg.Decision.WontFix {
+		m.issuesView.MarkWontFix(msg.Index)
+	} else {
+		m.issuesView.SetSeverity(msg.Index, msg.Decision.Severity)
+	}
+
+	for _, r := range m.results {
+		if r == nil || r.Mode != msg.Mode {
+			continue
+		}
+		for i, issue := range r.Issues {
+			if issue.Description != msg.Issue.Description || issue.Location != msg.Issue.Location {
+				continue
+			}
+			if msg.Decision.WontFix {
+				r.Issues = append(r.Issues[:i], r.Issues[i+1:]...)
+				r.Suppressed++
+			} else {
+				r.Issues[i] = triage.Adjust(issue, msg.Decision.Severity)
+			}
+			break
+		}
+	}
+
+	blocked := review.ShouldBlockAt(m.results, m.blockOnIssues, m.blockThreshold)
+	m.mu.Lock()
+	m.blocked = blocked
+	m.mu.Unlock()
+	m.blockReason = review.GetBlockReasonAt(m.results, m.blockThreshold)
+	m.issuesView.SetBlocked(blocked, m.blockReason)
+}
+
+// undoSelected starts reverting the selected issue's applied fix and returns
+// the command that reports the outcome, or nil if there is nothing to undo
+func (m *Model) undoSelected() tea.Cmd {
+	item := m.issuesView.SelectedIssue()
+	if item == nil || !item.Fixed || item.Issue.Fix == nil || m.fixUndoer == nil {
+		return nil
+	}
+	idx := m.issuesView.Cursor()
+	fix := item.Issue.Fix
+
+	undoer := m.fixUndoer
+	return func() tea.Msg {
+		if err := undoer(fix); err != nil {
+			return MsgFixUndone{IssueIndex: idx, Success: false, Error: err.Error()}
+		}
+		return MsgFixUndone{IssueIndex: idx, Success: true}
+	}
+}
+
+// applyAllFixes starts applying every pending fix, or only those of the
+// selected issues if any are selected, and returns the command that reports
+// the outcome, or nil if there is nothing to apply. Fixes to the same file are
+// applied from the bottom up so the line numbers of the remaining fixes stay
+// valid.
+func (m *Model) applyAllFixes() tea.Cmd {
+	pending := m.issuesView.PendingFixes()
+	if selected := m.issuesView.Selected(); len(selected) > 0 {
+		pending = slices.DeleteFunc(pending, func(idx int) bool {
+			return !slices.Contains(selected, idx)
+		})
+	}
+	if len(pending) == 0 || m.fixApplier == nil {
+		return nil
+	}
+	m.issuesView.ClearSelection()
+	type pendingFix struct {
+		index int
+		fix   *review.Fix
+	}
+	fixes := make([]pendingFix, 0, len(pending))
+	for _, idx := range pending {
+		fixes = append(fixes, pendingFix{index: idx, fix: m.issuesView.IssueAt(idx).Issue.Fix})
+	}
+	sort.SliceStable(fixes, func(i, k int) bool {
+		a, b := fixes[i].fix, fixes[k].fix
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine > b.StartLine
+	})
+	m.issuesView.SetNotice(fmt.Sprintf("Applying %d fix(es)...", len(fixes)))
+
+	applier := m.fixApplier
+	return func() tea.Msg {
+		var result MsgAllFixesApplied
+		for _, f := range fixes {
+			if err := applier(f.fix); err != nil {
+				if result.Failed == 0 {
+					result.Error = err.Error()
+				}
+				if fixRejected(err) {
+					result.Rejected = append(result.Rejected, f.index)
+				}
+				result.Failed++
+				continue
+			}
+			result.Applied = append(result.Applied, f.index)
+		}
+		return result
+	}
+}
+
+// fixRejected reports whether err means a fix was rolled back by verification
+func fixRejected(err error) bool {
+	return errors.Is(err, fix.ErrFixRejected)
+}
+
+// setFixHunks divides f into hunks for the diff preview, so that a fix
+// changing several places can be applied in part
+func (m *Model) setFixHunks(f *review.Fix) {
+	m.fixHunks = nil
+	if m.fixSplit == nil {
+		return
+	}
+	split, err := m.fixSplit(f)
+	if err != nil || len(split.Hunks) < 2 {
+		return
+	}
+	m.fixHunks = split
+	hunks := make([]string, len(split.Hunks))
+	for i, h := range split.Hunks {
+		hunks[i] = h.String()
+	}
+	m.diffModal.SetHunks(hunks)
+}
+
 // handleDiffPreviewKeys handles keys in the diff preview modal
 func (m *Model) handleDiffPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picking := m.diffModal.Picking()
 	switch {
 	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Cancel):
 		// Close modal, return to issue detail
 		m.state = StateIssueDetail
 		return m, nil
 
+	case key.Matches(msg, m.keys.SideBySide):
+		m.diffModal.ToggleSideBySide()
+		return m, nil
+
+	case !picking && key.Matches(msg, m.keys.PickHunks) && m.diffModal.CanPickHunks():
+		m.diffModal.StartPicking()
+		return m, nil
+
+	case picking && key.Matches(msg, m.keys.Up):
+		m.diffModal.MoveHunk(-1)
+		return m, nil
+
+	case picking && key.Matches(msg, m.keys.Down):
+		m.diffModal.MoveHunk(1)
+		return m, nil
+
+	case picking && key.Matches(msg, m.keys.ToggleHunk):
+		m.diffModal.ToggleHunk()
+		return m, nil
+
 	case key.Matches(msg, m.keys.Confirm):
 		// Apply fix using the fix applier callback
 		fix := m.diffModal.GetFix()
@@ -325,6 +926,20 @@ func (m *Model) handleDiffPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Apply only the accepted hunks of a fix picked hunk by hunk
+		var partial *review.Fix
+		if picking && m.fixHunks != nil {
+			selected := m.fixHunks.Select(m.diffModal.Accepted())
+			if selected == nil {
+				m.issuesView.SetNotice("No hunks accepted, fix not applied")
+				m.state = StateIssuesTable
+				return m, nil
+			}
+			if selected != fix {
+				fix, partial = selected, selected
+			}
+		}
+
 		// Return a command that applies the fix asynchronously
 		return m, func() tea.Msg {
 			err := m.fixApplier(fix)
@@ -333,11 +948,13 @@ func (m *Model) handleDiffPreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					IssueIndex: issueIdx,
 					Success:    false,
 					Error:      err.Error(),
+					Rejected:   fixRejected(err),
 				}
 			}
 			return MsgFixApplied{
 				IssueIndex: issueIdx,
 				Success:    true,
+				Fix:        partial,
 			}
 		}
 
@@ -368,11 +985,36 @@ func (m *Model) handleCommitConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// If asking for feedback, handle the prompt
+	if m.commitView.IsAskingFeedback() {
+		switch {
+		case key.Matches(msg, m.keys.Escape):
+			m.commitView.CancelFeedback()
+			return m, nil
+		case key.Matches(msg, m.keys.Enter):
+			feedback := m.commitView.SubmitFeedback()
+			if feedback == "" {
+				return m, nil
+			}
+			return m, m.regenerateCommit(feedback)
+		default:
+			cv, cmd := m.commitView.Update(msg)
+			m.commitView = cv
+			return m, cmd
+		}
+	}
+
+	// Wait for the regenerated message before committing or editing
+	if m.commitView.IsRegenerating() {
+		if key.Matches(msg, m.keys.Escape) || key.Matches(msg, m.keys.Cancel) {
+			return m.leaveCommitConfirm()
+		}
+		return m, nil
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Escape), key.Matches(msg, m.keys.Cancel):
-		// Return to issues table
-		m.state = StateIssuesTable
-		return m, nil
+		return m.leaveCommitConfirm()
 
 	case key.Matches(msg, m.keys.Confirm):
 		// Confirm commit
@@ -386,11 +1028,51 @@ func (m *Model) handleCommitConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Edit):
 		// Enter edit mode
 		return m, m.commitView.StartEditing()
+
+	case key.Matches(msg, m.keys.Regenerate) && m.regenerator != nil:
+		// Ask for feedback to regenerate the message with
+		return m, m.commitView.StartFeedback()
 	}
 
 	return m, nil
 }
 
+// leaveCommitConfirm returns to the issues table, or cancels the commit if
+// the confirm screen was opened without a review
+func (m *Model) leaveCommitConfirm() (tea.Model, tea.Cmd) {
+	if m.commitOnly {
+		m.state = StateDone
+		return m, tea.Quit
+	}
+	m.state = StateIssuesTable
+	return m, nil
+}
+
+// showCommitConfirm opens the confirm screen for message without a review
+func (m *Model) showCommitConfirm(message string) {
+	m.mu.Lock()
+	m.commitMessage = message
+	m.mu.Unlock()
+	m.commitOnly = true
+	m.commitView.SetCommitMessage(message)
+	m.state = StateCommitConfirm
+}
+
+// regenerateCommit starts generating the commit message again with feedback
+// and returns the command that reports the new message
+func (m *Model) regenerateCommit(feedback string) tea.Cmd {
+	m.commitView.SetRegenerating(true)
+	regenerator := m.regenerator
+	message := m.commitView.GetCommitMessage()
+	return func() tea.Msg {
+		regenerated, err := regenerator(message, feedback)
+		if err != nil {
+			return MsgCommitRegenerated{Error: err.Error()}
+		}
+		return MsgCommitRegenerated{Message: regenerated}
+	}
+}
+
 // updateCommitSummary updates the commit view with current summary
 func (m *Model) updateCommitSummary() {
 	issuesFound := m.issuesView.IssueCount()
@@ -479,6 +1161,13 @@ func (m *Model) GetCommitMessage() string {
 	return m.commitMessage
 }
 
+// GetResults returns the review results, with triage decisions applied
+func (m *Model) GetResults() []*review.Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.results
+}
+
 // GetFixedIssues returns the set of fixed issue indices
 func (m *Model) GetFixedIssues() map[int]bool {
 	m.mu.RLock()
@@ -508,3 +1197,103 @@ func (m *Model) GetSelectedIssueIndex() int {
 func (m *Model) SetFixApplier(applier FixApplier) {
 	m.fixApplier = applier
 }
+
+// SetFixUndoer sets the callback function for undoing applied fixes
+func (m *Model) SetFixUndoer(undoer FixUndoer) {
+	m.fixUndoer = undoer
+}
+
+// SetAutoConfirm enables committing without the confirm screen when the review
+// found no issues at or above the block threshold
+func (m *Model) SetAutoConfirm(autoConfirm bool) {
+	m.autoConfirm = autoConfirm
+}
+
+// SetStreamLines sets how many lines of streamed output the progress view
+// shows for the selected review; 0 hides streamed output
+func (m *Model) SetStreamLines(n int) {
+	m.progressView.SetStreamLines(n)
+}
+
+// SetPayload sets the lines describing what is sent to the AI, shown in a
+// panel of the progress view; nil shows no panel
+func (m *Model) SetPayload(lines []string) {
+	m.progressView.SetPayload(lines)
+}
+
+// SetCompact switches every view to the compact layout used when the TUI
+// renders inline in the scrollback instead of on the alternate screen
+func (m *Model) SetCompact(compact bool) {
+	m.progressView.SetCompact(compact)
+	m.issuesView.SetCompact(compact)
+	m.detailModal.SetCompact(compact)
+	m.diffModal.SetCompact(compact)
+	m.commitView.SetCompact(compact)
+}
+
+// SetFixPreviewer sets the callback function for rendering fix previews
+func (m *Model) SetFixPreviewer(previewer FixPreviewer) {
+	m.fixPreview = previewer
+}
+
+// SetFixSplitter sets the callback function for dividing fixes into hunks,
+// which lets fixes that change several places be applied hunk by hunk
+func (m *Model) SetFixSplitter(splitter FixSplitter) {
+	m.fixSplit = splitter
+}
+
+// SetSuggestionPromoter sets the callback function for promoting suggestions to issues
+func (m *Model) SetSuggestionPromoter(promoter SuggestionPromoter) {
+	m.promoter = promoter
+}
+
+// SetIssueTriager sets the callback function for saving triage decisions
+func (m *Model) SetIssueTriager(triager IssueTriager) {
+	m.triager = triager
+}
+
+// SetIssueExporter sets the callback function for exporting selected issues
+func (m *Model) SetIssueExporter(exporter IssueExporter) {
+	m.exporter = exporter
+}
+
+// SetCommitRegenerator sets the callback function for regenerating the commit
+// message with feedback. The commit confirm view offers it only once set.
+func (m *Model) SetCommitRegenerator(regenerator CommitRegenerator) {
+	m.regenerator = regenerator
+	m.commitView.SetCanRegenerate(regenerator != nil)
+}
+
+// SetBlockOnIssues sets whether high-severity issues block the commit, which
+// is checked again when an issue is triaged
+func (m *Model) SetBlockOnIssues(block bool) {
+	m.blockOnIssues = block
+}
+
+// SetBlockThreshold sets the lowest severity whose issues block the commit,
+// high unless set
+func (m *Model) SetBlockThreshold(severity string) {
+	m.blockThreshold = severity
+}
+
+// SetModeCanceler sets the callback function for skipping a running review mode
+func (m *Model) SetModeCanceler(canceler ModeCanceler) {
+	m.modeCanceler = canceler
+}
+
+// SetModeAdder sets the callback function for queuing an additional review mode
+func (m *Model) SetModeAdder(adder ModeAdder) {
+	m.modeAdder = adder
+}
+
+// SetCostConfirmer sets the callback function for starting reviews held back
+// until their estimated cost is confirmed
+func (m *Model) SetCostConfirmer(confirm CostConfirmer) {
+	m.costConfirm = confirm
+}
+
+// SetResumer sets the callback function for resuming reviews paused by an
+// authentication failure
+func (m *Model) SetResumer(resumer Resumer) {
+	m.resumer = resumer
+}
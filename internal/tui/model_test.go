@@ -1,9 +1,17 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/buker/revi/internal/fix"
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
+	"github.com/buker/revi/internal/tui/tuitest"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -220,3 +228,967 @@ func TestModel_IsBlocked_ThreadSafe(t *testing.T) {
 		t.Error("IsBlocked() = false after setting blocked=true")
 	}
 }
+
+// =============================================================================
+// Tests for skipping a single mode while reviewing
+// =============================================================================
+
+func TestModel_SkipKey_CancelsSelectedRunningMode(t *testing.T) {
+	model := NewModel()
+	var cancelled []review.Mode
+	model.SetModeCanceler(func(mode review.Mode) {
+		cancelled = append(cancelled, mode)
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity, review.ModeStyle}})
+	model.Update(MsgReviewStarted{Mode: review.ModeSecurity})
+	model.Update(MsgReviewStarted{Mode: review.ModeStyle})
+
+	// Move to the second mode and skip it
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+	if len(cancelled) != 1 || cancelled[0] != review.ModeStyle {
+		t.Errorf("cancelled = %v, want [%s]", cancelled, review.ModeStyle)
+	}
+	if model.state != StateReviewing {
+		t.Errorf("state = %v, want StateReviewing after skipping a mode", model.state)
+	}
+}
+
+func TestModel_SkipKey_IgnoresCompletedMode(t *testing.T) {
+	model := NewModel()
+	called := false
+	model.SetModeCanceler(func(mode review.Mode) {
+		called = true
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+	model.Update(MsgReviewStarted{Mode: review.ModeSecurity})
+	model.Update(MsgReviewComplete{Result: &review.Result{Mode: review.ModeSecurity, Status: review.StatusNoIssues}})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+	if called {
+		t.Error("canceler should not be called for a completed mode")
+	}
+}
+
+func TestModel_SkippedResult_RendersInProgressView(t *testing.T) {
+	model := NewModel()
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+	model.Update(MsgReviewStarted{Mode: review.ModeSecurity})
+	model.Update(MsgReviewComplete{Result: &review.Result{Mode: review.ModeSecurity, Status: review.StatusSkipped}})
+
+	if !model.progressView.IsComplete() {
+		t.Error("skipped mode should count towards completion")
+	}
+	if !strings.Contains(model.View(), "Skipped") {
+		t.Error("View() should show the skipped status")
+	}
+}
+
+// =============================================================================
+// Tests for detection reasoning and mode toggling while reviewing
+// =============================================================================
+
+func TestModel_ModesDetected_ShowsReasoning(t *testing.T) {
+	model := NewModel()
+	model.Update(MsgModesDetected{
+		Modes:     []review.Mode{review.ModeSecurity},
+		Reasoning: "auth code changed",
+	})
+
+	if !strings.Contains(model.View(), "auth code changed") {
+		t.Error("View() should show the detection reasoning while reviewing")
+	}
+}
+
+func TestModel_ToggleMode_AddsMissingMode(t *testing.T) {
+	model := NewModel()
+	var added []review.Mode
+	model.SetModeAdder(func(mode review.Mode) bool {
+		added = append(added, mode)
+		return true
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+
+	// "2" maps to the second entry of review.AllModes()
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+
+	if len(added) != 1 || added[0] != review.ModePerformance {
+		t.Fatalf("added = %v, want [%s]", added, review.ModePerformance)
+	}
+	if !model.progressView.HasMode(review.ModePerformance) {
+		t.Error("added mode should be tracked by the progress view")
+	}
+}
+
+func TestProgram_AddMode_RejectsModesOfTheRun(t *testing.T) {
+	p := NewProgram()
+	p.queued = map[review.Mode]bool{review.ModeSecurity: true}
+	p.setAccepting(true)
+
+	if p.AddMode(review.ModeSecurity) {
+		t.Error("AddMode() should reject a mode the run already has, even a skipped one")
+	}
+	if !p.AddMode(review.ModeStyle) {
+		t.Fatal("AddMode() should queue a new mode")
+	}
+	if p.AddMode(review.ModeStyle) {
+		t.Error("AddMode() should not queue a mode twice")
+	}
+}
+
+func TestModel_ToggleMode_RemovesQueuedMode(t *testing.T) {
+	model := NewModel()
+	var cancelled []review.Mode
+	model.SetModeCanceler(func(mode review.Mode) {
+		cancelled = append(cancelled, mode)
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity, review.ModeStyle}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1'}})
+
+	if len(cancelled) != 1 || cancelled[0] != review.ModeSecurity {
+		t.Errorf("cancelled = %v, want [%s]", cancelled, review.ModeSecurity)
+	}
+}
+
+func TestModel_ToggleMode_LockedAfterFirstResult(t *testing.T) {
+	model := NewModel()
+	called := false
+	model.SetModeAdder(func(mode review.Mode) bool {
+		called = true
+		return true
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity, review.ModeStyle}})
+	model.Update(MsgReviewComplete{Result: &review.Result{Mode: review.ModeSecurity, Status: review.StatusNoIssues}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+
+	if called {
+		t.Error("modes should not be added after the first result has finished")
+	}
+}
+
+// =============================================================================
+// Tests for commit auto-confirm
+// =============================================================================
+
+func TestModel_AutoConfirm_SkipsConfirmScreenWithoutHighIssues(t *testing.T) {
+	model := NewModel()
+	model.SetAutoConfirm(true)
+
+	model.Update(MsgAllReviewsComplete{
+		Results: []*review.Result{
+			{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "nit"}}},
+		},
+	})
+	_, cmd := model.Update(MsgCommitGenerated{Message: "feat: add thing"})
+
+	if !model.IsConfirmed() {
+		t.Error("IsConfirmed() = false, want true with auto-confirm and no high-severity issues")
+	}
+	if model.state != StateDone {
+		t.Errorf("state = %v, want StateDone", model.state)
+	}
+	if cmd == nil {
+		t.Error("expected quit command after auto-confirm")
+	}
+}
+
+func TestModel_AutoConfirm_WaitsWhenHighIssuesFound(t *testing.T) {
+	model := NewModel()
+	model.SetAutoConfirm(true)
+
+	// High-severity issue with blocking disabled still requires manual confirmation
+	model.Update(MsgAllReviewsComplete{
+		Results: []*review.Result{
+			{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "high", Description: "bad"}}},
+		},
+	})
+	model.Update(MsgCommitGenerated{Message: "feat: add thing"})
+
+	if model.IsConfirmed() {
+		t.Error("IsConfirmed() = true, want false when high-severity issues exist")
+	}
+	if model.state != StateIssuesTable {
+		t.Errorf("state = %v, want StateIssuesTable", model.state)
+	}
+}
+
+func TestModel_AutoConfirm_WaitsForIssuesAtThreshold(t *testing.T) {
+	model := NewModel()
+	model.SetAutoConfirm(true)
+	model.SetBlockThreshold("medium")
+
+	model.Update(MsgAllReviewsComplete{
+		Results: []*review.Result{
+			{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "medium", Description: "nit"}}},
+		},
+	})
+	model.Update(MsgCommitGenerated{Message: "feat: add thing"})
+
+	if model.IsConfirmed() {
+		t.Error("IsConfirmed() = true, want false with a medium issue and the threshold at medium")
+	}
+}
+
+func TestModel_CommitOnly_EscapeCancels(t *testing.T) {
+	model := NewModel()
+	model.showCommitConfirm("feat: add thing")
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if model.state != StateDone || model.IsConfirmed() {
+		t.Errorf("state = %v, confirmed = %v; want the commit cancelled", model.state, model.IsConfirmed())
+	}
+	if cmd == nil {
+		t.Error("expected quit command after cancelling")
+	}
+}
+
+func TestProgram_RunCommitConfirm_AutoConfirm(t *testing.T) {
+	p := NewProgram()
+	p.SetAutoConfirm(true)
+
+	if err := p.RunCommitConfirm("feat: add thing"); err != nil {
+		t.Fatalf("RunCommitConfirm() error = %v", err)
+	}
+	if !p.IsConfirmed() || p.GetCommitMessage() != "feat: add thing" {
+		t.Errorf("confirmed = %v, message = %q; want the message accepted", p.IsConfirmed(), p.GetCommitMessage())
+	}
+}
+
+// =============================================================================
+// Tests for pausing on expired authentication
+// =============================================================================
+
+func TestModel_AuthRequired_ShowsLoginPrompt(t *testing.T) {
+	model := NewModel()
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity, review.ModeStyle}})
+	model.Update(MsgReviewStarted{Mode: review.ModeSecurity})
+	model.Update(MsgReviewComplete{Result: &review.Result{Mode: review.ModeSecurity, Status: review.StatusNoIssues}})
+	model.Update(MsgReviewStarted{Mode: review.ModeStyle})
+	model.Update(MsgAuthRequired{Mode: review.ModeStyle})
+
+	view := model.View()
+	if !strings.Contains(view, "claude login") {
+		t.Error("View() should tell the user to run claude login")
+	}
+	if !strings.Contains(view, "Pending") {
+		t.Error("paused mode should be shown as pending")
+	}
+	if model.progressView.IsComplete() {
+		t.Error("paused mode should not count towards completion")
+	}
+}
+
+func TestModel_ResumeKey_ResumesOnlyWhenPaused(t *testing.T) {
+	model := NewModel()
+	resumed := 0
+	model.SetResumer(func() {
+		resumed++
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+	model.Update(MsgReviewStarted{Mode: review.ModeSecurity})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if resumed != 0 {
+		t.Fatal("resume key should do nothing while reviews are not paused")
+	}
+
+	model.Update(MsgAuthRequired{Mode: review.ModeSecurity})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if resumed != 1 {
+		t.Fatalf("resumed = %d, want 1", resumed)
+	}
+	if strings.Contains(model.View(), "claude login") {
+		t.Error("login prompt should be hidden after resuming")
+	}
+}
+
+// =============================================================================
+// Tests for confirming the estimated cost
+// =============================================================================
+
+func TestModel_CostEstimate_ShownWithoutConfirmation(t *testing.T) {
+	model := NewModel()
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+	model.Update(MsgCostEstimate{Estimate: "~10K input + ~1.5K output tokens in 1 request(s), about $0.05"})
+
+	view := model.View()
+	if !strings.Contains(view, "about $0.05") {
+		t.Error("View() should show the cost estimate")
+	}
+	if strings.Contains(view, "start reviews") {
+		t.Error("View() should not ask for confirmation below the threshold")
+	}
+}
+
+func TestModel_CostConfirm_EnterStartsReviews(t *testing.T) {
+	model := NewModel()
+	confirmed := 0
+	model.SetCostConfirmer(func() {
+		confirmed++
+	})
+	skipped := 0
+	model.SetModeCanceler(func(review.Mode) {
+		skipped++
+	})
+
+	model.Update(MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}})
+	model.Update(MsgCostEstimate{Estimate: "about $12.00", Confirm: true})
+	if !strings.Contains(model.View(), "start reviews") {
+		t.Fatal("View() should ask to confirm the estimated cost")
+	}
+
+	// Other keys wait for the confirmation
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if skipped != 0 {
+		t.Error("skip key should do nothing while waiting for confirmation")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if confirmed != 1 {
+		t.Fatalf("confirmed = %d, want 1", confirmed)
+	}
+	if strings.Contains(model.View(), "start reviews") {
+		t.Error("confirmation prompt should be hidden after confirming")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if confirmed != 1 {
+		t.Errorf("confirmed = %d after a second enter, want 1", confirmed)
+	}
+}
+
+// =============================================================================
+// Tests for promoting suggestions to issues
+// =============================================================================
+
+func TestModel_PromoteKey_PromotesSelectedSuggestion(t *testing.T) {
+	model := NewModel()
+	model.SetSuggestionPromoter(func(mode review.Mode, suggestion string) (*review.Issue, error) {
+		return &review.Issue{Severity: "medium", Description: "promoted: " + suggestion, Location: "main.go:3"}, nil
+	})
+	result := &review.Result{
+		Mode:        review.ModeErrors,
+		Status:      review.StatusNoIssues,
+		Suggestions: []string{"check Close error"},
+	}
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{result}})
+
+	if model.issuesView.IssueCount() != 0 || model.issuesView.SuggestionCount() != 1 {
+		t.Fatalf("expected 0 issues and 1 suggestion, got %d and %d",
+			model.issuesView.IssueCount(), model.issuesView.SuggestionCount())
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if cmd == nil {
+		t.Fatal("promote key should return a command")
+	}
+	if !model.issuesView.SelectedIssue().Promoting {
+		t.Error("suggestion should be marked as promoting")
+	}
+
+	model.Update(cmd())
+	item := model.issuesView.SelectedIssue()
+	if item.Suggestion || item.Issue.Description != "promoted: check Close error" {
+		t.Errorf("selected item = %+v, want the promoted issue", item)
+	}
+	if len(result.Issues) != 1 || len(result.Suggestions) != 0 {
+		t.Errorf("result should hold the promoted issue, got issues %v suggestions %v", result.Issues, result.Suggestions)
+	}
+}
+
+func TestModel_PromoteKey_FailureKeepsSuggestion(t *testing.T) {
+	model := NewModel()
+	model.SetSuggestionPromoter(func(mode review.Mode, suggestion string) (*review.Issue, error) {
+		return nil, errors.New("model unavailable")
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusNoIssues, Suggestions: []string{"rename x"}},
+	}})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	model.Update(cmd())
+
+	item := model.issuesView.SelectedIssue()
+	if !item.Suggestion || item.Promoting {
+		t.Errorf("selected item = %+v, want an idle suggestion", item)
+	}
+	if !strings.Contains(model.View(), "model unavailable") {
+		t.Error("View() should show why the promotion failed")
+	}
+}
+
+func TestModel_PromoteKey_IgnoresIssues(t *testing.T) {
+	model := NewModel()
+	model.SetSuggestionPromoter(func(mode review.Mode, suggestion string) (*review.Issue, error) {
+		t.Error("promoter should not be called for an issue")
+		return nil, nil
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming"}}},
+	}})
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}}); cmd != nil {
+		t.Error("promote key should do nothing on an issue")
+	}
+}
+
+// =============================================================================
+// Tests for triaging issues
+// =============================================================================
+
+func TestModel_TriageKeys_LowerSeverityUnblocks(t *testing.T) {
+	model := NewModel()
+	model.SetBlockOnIssues(true)
+	var saved []triage.Decision
+	model.SetIssueTriager(func(mode review.Mode, issue review.Issue, decision triage.Decision) error {
+		if issue.Description != "Weak hash" {
+			t.Errorf("triager got issue %q, want the selected one", issue.Description)
+		}
+		saved = append(saved, decision)
+		return nil
+	})
+	result := &review.Result{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{
+		{Severity: "high", Description: "Weak hash", Location: "h.go:3"},
+	}}
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{result}, Blocked: true, Reason: "1 high-severity issue found"})
+	if !strings.Contains(model.View(), "[+/-] severity") {
+		t.Error("View() should offer to triage the selected issue")
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	if cmd == nil {
+		t.Fatal("lower key should return a command")
+	}
+	model.Update(cmd())
+
+	if len(saved) != 1 || saved[0].Severity != "medium" {
+		t.Errorf("saved decisions = %+v, want severity medium", saved)
+	}
+	if got := model.issuesView.SelectedIssue().Issue; got.Severity != "medium" || got.TriagedFrom != "high" {
+		t.Errorf("table issue = %q from %q, want medium from high", got.Severity, got.TriagedFrom)
+	}
+	if result.Issues[0].Severity != "medium" {
+		t.Errorf("result issue severity = %q, want medium", result.Issues[0].Severity)
+	}
+	if model.IsBlocked() {
+		t.Error("lowering the only high-severity issue should unblock the commit")
+	}
+
+	// Raising it again blocks once more
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	model.Update(cmd())
+	if !model.IsBlocked() {
+		t.Error("raising the issue back to high should block the commit")
+	}
+}
+
+func TestModel_TriageKeys_WontFixHidesFromResults(t *testing.T) {
+	model := NewModel()
+	model.SetBlockOnIssues(true)
+	result := &review.Result{Mode: review.ModeSecurity, Status: review.StatusIssues, Issues: []review.Issue{
+		{Severity: "high", Description: "Debug endpoint", Location: "d.go:1", Fix: &review.Fix{Available: true, FilePath: "d.go", StartLine: 1, EndLine: 1}},
+	}}
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{result}, Blocked: true, Reason: "1 high-severity issue found"})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	model.Update(cmd())
+
+	if !model.issuesView.SelectedIssue().WontFix {
+		t.Error("issue should be marked won't fix in the table")
+	}
+	if len(result.Issues) != 0 || result.Suppressed != 1 {
+		t.Errorf("result = %d issues, %d suppressed; want the issue hidden", len(result.Issues), result.Suppressed)
+	}
+	if model.IsBlocked() {
+		t.Error("a won't-fix issue should not block the commit")
+	}
+	if len(model.issuesView.PendingFixes()) != 0 {
+		t.Error("the fix of a won't-fix issue should not be pending")
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}}); cmd != nil {
+		t.Error("a won't-fix issue should not be triaged again")
+	}
+}
+
+func TestModel_TriageKeys_FailureKeepsIssue(t *testing.T) {
+	model := NewModel()
+	model.SetIssueTriager(func(mode review.Mode, issue review.Issue, decision triage.Decision) error {
+		return errors.New("permission denied")
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming"}}},
+	}})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	model.Update(cmd())
+
+	if got := model.issuesView.SelectedIssue().Issue.Severity; got != "low" {
+		t.Errorf("severity = %q, want it unchanged after a failed save", got)
+	}
+	if !strings.Contains(model.View(), "permission denied") {
+		t.Error("View() should show why the decision could not be saved")
+	}
+}
+
+func TestModel_BulkActions_ApplySelectedFixes(t *testing.T) {
+	model := NewModel()
+	var applied []string
+	model.SetFixApplier(func(f *review.Fix) error {
+		applied = append(applied, f.FilePath)
+		return nil
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{
+			{Severity: "low", Description: "one", Fix: &review.Fix{Available: true, FilePath: "a.go", StartLine: 1, EndLine: 1}},
+			{Severity: "low", Description: "two", Fix: &review.Fix{Available: true, FilePath: "b.go", StartLine: 1, EndLine: 1}},
+		}},
+	}})
+
+	model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if cmd == nil {
+		t.Fatal("apply key should return a command")
+	}
+	model.Update(cmd())
+
+	if len(applied) != 1 || applied[0] != "a.go" {
+		t.Errorf("applied fixes = %v, want only the selected issue's", applied)
+	}
+	if len(model.issuesView.Selected()) != 0 {
+		t.Error("selection should be cleared after the bulk action")
+	}
+}
+
+func TestModel_BulkActions_WontFixSelected(t *testing.T) {
+	model := NewModel()
+	var saved []string
+	model.SetIssueTriager(func(mode review.Mode, issue review.Issue, decision triage.Decision) error {
+		saved = append(saved, issue.Description)
+		return nil
+	})
+	result := &review.Result{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{
+		{Severity: "low", Description: "one"},
+		{Severity: "low", Description: "two"},
+		{Severity: "low", Description: "three"},
+	}}
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{result}})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	model.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	model.Update(cmd())
+
+	if len(saved) != 2 || saved[0] != "one" || saved[1] != "three" {
+		t.Errorf("saved decisions for %v, want one and three", saved)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Description != "two" {
+		t.Errorf("result issues = %+v, want only two left", result.Issues)
+	}
+	if !strings.Contains(model.View(), "Triaged 2 issue(s)") {
+		t.Error("View() should report the bulk triage")
+	}
+}
+
+func TestModel_BulkActions_ExportSelected(t *testing.T) {
+	model := NewModel()
+	var exported []*review.Result
+	model.SetIssueExporter(func(results []*review.Result) (string, error) {
+		exported = results
+		return "revi-issues.md", nil
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming"}}},
+		{Mode: review.ModeErrors, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "high", Description: "unchecked"}}},
+	}})
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}}); cmd != nil {
+		t.Error("export key should do nothing without a selection")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	model.Update(cmd())
+
+	if len(exported) != 2 || exported[0].Mode != review.ModeStyle || exported[1].Issues[0].Description != "unchecked" {
+		t.Errorf("exported = %+v, want one result per mode in table order", exported)
+	}
+	if !strings.Contains(model.View(), "Exported 2 issue(s) to revi-issues.md") {
+		t.Error("View() should say where the issues were exported")
+	}
+}
+
+// =============================================================================
+// Tests for undoing fixes
+// =============================================================================
+
+func TestModel_UndoKey_UndoesAppliedFix(t *testing.T) {
+	model := NewModel()
+	fix := &review.Fix{Available: true, Code: "x", FilePath: "main.go", StartLine: 1, EndLine: 1}
+	var undone *review.Fix
+	model.SetFixUndoer(func(f *review.Fix) error {
+		undone = f
+		return nil
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming", Fix: fix}}},
+	}})
+
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}}); cmd != nil {
+		t.Fatal("undo key should do nothing before the fix is applied")
+	}
+
+	model.Update(MsgFixApplied{IssueIndex: 0, Success: true})
+	if !strings.Contains(model.View(), "[u] undo fix") {
+		t.Error("View() should offer to undo the applied fix")
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if cmd == nil {
+		t.Fatal("undo key should return a command")
+	}
+	model.Update(cmd())
+
+	if undone != fix {
+		t.Error("undoer should be called with the selected issue's fix")
+	}
+	if model.issuesView.SelectedIssue().Fixed || len(model.GetFixedIssues()) != 0 {
+		t.Error("issue should no longer be marked as fixed")
+	}
+}
+
+func TestModel_UndoKey_FailureKeepsFix(t *testing.T) {
+	model := NewModel()
+	model.SetFixUndoer(func(f *review.Fix) error {
+		return errors.New("file was modified")
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming", Fix: &review.Fix{Available: true}}}},
+	}})
+	model.Update(MsgFixApplied{IssueIndex: 0, Success: true})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	model.Update(cmd())
+
+	if !model.issuesView.SelectedIssue().Fixed {
+		t.Error("issue should stay fixed when the undo fails")
+	}
+	if !strings.Contains(model.View(), "file was modified") {
+		t.Error("View() should show why the undo failed")
+	}
+}
+
+// =============================================================================
+// Tests for applying all fixes
+// =============================================================================
+
+func TestModel_ApplyAllKey_AppliesPendingFixesBottomUp(t *testing.T) {
+	model := NewModel()
+	var applied []int
+	model.SetFixApplier(func(f *review.Fix) error {
+		if f.FilePath == "broken.go" {
+			return errors.New("end line exceeds file length")
+		}
+		applied = append(applied, f.StartLine)
+		return nil
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{
+			{Severity: "low", Description: "top", Fix: &review.Fix{Available: true, FilePath: "main.go", StartLine: 3, EndLine: 3}},
+			{Severity: "low", Description: "bottom", Fix: &review.Fix{Available: true, FilePath: "main.go", StartLine: 20, EndLine: 20}},
+			{Severity: "low", Description: "stale", Fix: &review.Fix{Available: true, FilePath: "broken.go", StartLine: 1, EndLine: 1}},
+			{Severity: "low", Description: "manual", Fix: &review.Fix{Available: false}},
+		}},
+	}})
+	if !strings.Contains(model.View(), "[a] apply all fixes") {
+		t.Error("View() should offer to apply all fixes")
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if cmd == nil {
+		t.Fatal("apply all key should return a command")
+	}
+	model.Update(cmd())
+
+	if len(applied) != 2 || applied[0] != 20 || applied[1] != 3 {
+		t.Errorf("applied lines = %v, want [20 3]", applied)
+	}
+	fixed := model.GetFixedIssues()
+	if len(fixed) != 2 || !fixed[0] || !fixed[1] {
+		t.Errorf("fixed issues = %v, want issues 0 and 1", fixed)
+	}
+	if !strings.Contains(model.View(), "Applied 2 fix(es), 1 failed") {
+		t.Error("View() should summarize applied and failed fixes")
+	}
+
+	// Only the failed fix is left, and applying all again retries just that one
+	applied = nil
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model.Update(cmd())
+	if len(applied) != 0 {
+		t.Errorf("applied fixes should not be applied again, got %v", applied)
+	}
+}
+
+func TestModel_ApplyAllKey_MarksRejectedFixes(t *testing.T) {
+	model := NewModel()
+	model.SetFixApplier(func(f *review.Fix) error {
+		return fmt.Errorf("%w: \"go build ./...\" failed", fix.ErrFixRejected)
+	})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{
+			{Severity: "low", Description: "naming", Fix: &review.Fix{Available: true, FilePath: "main.go", StartLine: 1, EndLine: 1}},
+		}},
+	}})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model.Update(cmd())
+
+	if !model.issuesView.SelectedIssue().Rejected {
+		t.Error("issue should be marked as rejected")
+	}
+	view := model.View()
+	if !strings.Contains(view, "[REJECTED]") || strings.Contains(view, "[a] apply all fixes") {
+		t.Errorf("View() should show the rejected fix and not offer it again:\n%s", view)
+	}
+}
+
+// =============================================================================
+// Tests for picking the hunks of a fix
+// =============================================================================
+
+func TestModel_DiffPreview_AppliesAcceptedHunks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\nc\nd\ne\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	applier := fix.NewApplier(dir)
+	issueFix := &review.Fix{Available: true, FilePath: "main.go", StartLine: 1, EndLine: 5, Code: "A\nb\nc\nd\nE"}
+
+	model := NewModel()
+	var applied *review.Fix
+	model.SetFixApplier(func(f *review.Fix) error {
+		applied = f
+		return nil
+	})
+	model.SetFixSplitter(applier.Split)
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming", Fix: issueFix}}},
+	}})
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if !strings.Contains(model.View(), "[h] pick hunks") {
+		t.Fatalf("preview of a fix with two hunks should offer to pick them:\n%s", model.View())
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	if view := model.View(); !strings.Contains(view, "Hunk 1/2 [x] accepted") || !strings.Contains(view, "Hunk 2/2 [x] accepted") {
+		t.Fatalf("View() should list both hunks as accepted:\n%s", view)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(model.View(), "Hunk 1/2 [ ] rejected") {
+		t.Fatal("space should reject the selected hunk")
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("confirm should return a command applying the fix")
+	}
+	model.Update(cmd())
+
+	if applied == nil || applied.Code != "a\nb\nc\nd\nE" {
+		t.Fatalf("applied fix = %+v, want only the second hunk", applied)
+	}
+	if item := model.issuesView.SelectedIssue(); !item.Fixed || item.Issue.Fix != applied {
+		t.Error("issue should be fixed and keep the applied fix for undo")
+	}
+}
+
+func TestModel_DiffPreview_NoHunksAccepted(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	model := NewModel()
+	model.SetFixApplier(func(f *review.Fix) error {
+		t.Error("no fix should be applied")
+		return nil
+	})
+	model.SetFixSplitter(fix.NewApplier(dir).Split)
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	model.Update(MsgAllReviewsComplete{Results: []*review.Result{
+		{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: []review.Issue{{Severity: "low", Description: "naming",
+			Fix: &review.Fix{Available: true, FilePath: "main.go", StartLine: 1, EndLine: 3, Code: "A\nb\nC"}}}},
+	}})
+
+	for _, k := range []tea.KeyMsg{
+		{Type: tea.KeyEnter},
+		{Type: tea.KeyRunes, Runes: []rune{'a'}},
+		{Type: tea.KeyRunes, Runes: []rune{'h'}},
+		{Type: tea.KeySpace},
+		{Type: tea.KeyDown},
+		{Type: tea.KeySpace},
+	} {
+		model.Update(k)
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); cmd != nil {
+		t.Error("confirming without accepted hunks should not apply anything")
+	}
+	if model.state != StateIssuesTable || !strings.Contains(model.View(), "No hunks accepted") {
+		t.Errorf("should return to the table with a notice, state = %v", model.state)
+	}
+}
+
+// =============================================================================
+// Scripted interaction tests
+// =============================================================================
+
+func TestModel_Scripted_OpenAndCloseIssueDetail(t *testing.T) {
+	d := tuitest.New(NewModel(), 120, 40)
+	d.Send(
+		MsgModesDetected{Modes: []review.Mode{review.ModeSecurity}},
+		MsgReviewStarted{Mode: review.ModeSecurity},
+		MsgAllReviewsComplete{Results: []*review.Result{{
+			Mode:   review.ModeSecurity,
+			Status: review.StatusIssues,
+			Issues: []review.Issue{{Severity: "high", Description: "Hardcoded token", Location: "auth.go:12"}},
+		}}},
+	)
+	d.ExpectFrame(t, "Hardcoded token")
+
+	d.Press("enter")
+	if d.Model().(*Model).state != StateIssueDetail {
+		t.Fatalf("state = %v, want StateIssueDetail", d.Model().(*Model).state)
+	}
+	d.ExpectFrame(t, "auth.go:12")
+
+	d.Press("esc")
+	if d.Model().(*Model).state != StateIssuesTable {
+		t.Errorf("state = %v, want StateIssuesTable", d.Model().(*Model).state)
+	}
+
+	d.Press("q")
+	if !d.Quit() {
+		t.Error("q should quit")
+	}
+}
+
+func TestModel_Scripted_CompactDetailIsNotCentered(t *testing.T) {
+	model := NewModel()
+	model.SetCompact(true)
+	d := tuitest.New(model, 120, 60)
+	d.Send(MsgAllReviewsComplete{Results: []*review.Result{{
+		Mode:   review.ModeErrors,
+		Status: review.StatusIssues,
+		Issues: []review.Issue{{Severity: "medium", Description: "Unchecked error", Location: "main.go:4"}},
+	}}})
+
+	d.Press("enter")
+	frame := d.Frame()
+	if strings.HasPrefix(frame, "\n") || strings.HasPrefix(frame, " ") {
+		t.Errorf("compact detail should start at the top left, got:\n%s", frame)
+	}
+	d.ExpectFrame(t, "Unchecked error")
+}
+
+func TestModel_Scripted_SearchFiltersIssues(t *testing.T) {
+	d := tuitest.New(NewModel(), 120, 40)
+	d.Send(MsgAllReviewsComplete{Results: []*review.Result{
+		{
+			Mode:   review.ModeSecurity,
+			Status: review.StatusIssues,
+			Issues: []review.Issue{{Severity: "high", Description: "Hardcoded token", Location: "auth.go:12"}},
+		},
+		{
+			Mode:   review.ModeStyle,
+			Status: review.StatusIssues,
+			Issues: []review.Issue{{Severity: "low", Description: "Quirky naming", Location: "util/strings.go:3"}},
+		},
+	}})
+
+	d.Press("/").Type("sev:low quirky")
+	d.ExpectFrame(t, "Quirky naming", "/sev:low quirky")
+	d.RejectFrame(t, "Hardcoded token")
+	if d.Quit() {
+		t.Fatal("q typed into the search should not quit")
+	}
+
+	d.Press("enter")
+	d.ExpectFrame(t, "Filter: sev:low quirky (1 of 2 shown)")
+	if item := d.Model().(*Model).issuesView.SelectedIssue(); item == nil || item.Issue.Description != "Quirky naming" {
+		t.Errorf("cursor should be on the only shown issue, got %+v", item)
+	}
+
+	d.Press("esc")
+	d.ExpectFrame(t, "Hardcoded token", "Quirky naming")
+	d.RejectFrame(t, "Filter:")
+}
+
+func TestModel_Scripted_RegenerateCommitWithFeedback(t *testing.T) {
+	model := NewModel()
+	var gotMessage, gotFeedback string
+	model.SetCommitRegenerator(func(message, feedback string) (string, error) {
+		gotMessage, gotFeedback = message, feedback
+		return "feat(api): add users table\n\nAdds the migration creating it.", nil
+	})
+	d := tuitest.New(model, 120, 40)
+	d.Send(MsgAllReviewsComplete{Results: []*review.Result{{Mode: review.ModeSecurity, Status: review.StatusNoIssues}}})
+	d.Send(MsgCommitGenerated{Message: "feat: add users table"})
+
+	d.Press("c")
+	d.ExpectFrame(t, "[r] regenerate")
+	d.Press("r").Type("mention the migration, use scope api")
+	d.ExpectFrame(t, "Feedback: mention the migration, use scope api")
+	if d.Quit() {
+		t.Fatal("q typed as feedback should not quit")
+	}
+
+	d.Press("enter")
+	if gotMessage != "feat: add users table" || gotFeedback != "mention the migration, use scope api" {
+		t.Errorf("regenerator called with %q, %q", gotMessage, gotFeedback)
+	}
+	d.ExpectFrame(t, "feat(api): add users table")
+	if got := d.Model().(*Model).GetCommitMessage(); !strings.HasPrefix(got, "feat(api): add users table") {
+		t.Errorf("GetCommitMessage() = %q, want the regenerated message", got)
+	}
+}
+
+func TestModel_Scripted_RegenerateCommitFailureKeepsMessage(t *testing.T) {
+	model := NewModel()
+	model.SetCommitRegenerator(func(message, feedback string) (string, error) {
+		return "", errors.New("rate limited")
+	})
+	d := tuitest.New(model, 120, 40)
+	d.Send(MsgAllReviewsComplete{Results: []*review.Result{{Mode: review.ModeSecurity, Status: review.StatusNoIssues}}})
+	d.Send(MsgCommitGenerated{Message: "feat: add users table"})
+
+	d.Press("c", "r").Type("shorter")
+	d.Press("enter")
+	d.ExpectFrame(t, "feat: add users table", "Regenerating failed: rate limited")
+
+	// Esc closes the prompt without regenerating
+	d.Press("r").Type("never mind")
+	d.Press("esc")
+	d.RejectFrame(t, "Feedback:")
+	if d.Model().(*Model).state != StateCommitConfirm {
+		t.Errorf("state = %v, want StateCommitConfirm after cancelling feedback", d.Model().(*Model).state)
+	}
+}
+
+func TestModel_Scripted_NoRegenerateWithoutRegenerator(t *testing.T) {
+	d := tuitest.New(NewModel(), 120, 40)
+	d.Send(MsgAllReviewsComplete{Results: []*review.Result{{Mode: review.ModeSecurity, Status: review.StatusNoIssues}}})
+	d.Send(MsgCommitGenerated{Message: "feat: add users table"})
+
+	d.Press("c", "r")
+	d.RejectFrame(t, "[r] regenerate", "Feedback:")
+}
@@ -2,8 +2,13 @@ package tui
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/tui/shared"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -13,22 +18,81 @@ import (
 type Program struct {
 	program *tea.Program // Underlying Bubble Tea program
 	model   *Model       // Shared model for state access
+
+	// Per-mode cancellation for skipping a single slow review
+	cancelMu sync.Mutex
+	cancels  map[review.Mode]context.CancelFunc
+
+	// Modes queued from the TUI while reviews are running
+	addCh     chan review.Mode
+	accepting bool
+	// queued are the modes of the current run, including skipped ones, so
+	// none is queued twice
+	queued map[review.Mode]bool
+
+	// Bounds how many reviews run at once; nil runs every mode immediately
+	limiter *review.Limiter
+
+	// Longest each review may run once started; 0 sets no limit
+	modeTimeout time.Duration
+
+	// Coalesces streaming chunks before they reach the event loop
+	stream *StreamCoalescer
+
+	// Resume requests after an authentication pause, and TUI exit notification
+	resumeCh chan struct{}
+	quitCh   chan struct{}
+	quitOnce sync.Once
+
+	// Estimates the cost of the detected modes; confirmations arrive on confirmCh
+	costCheck CostCheck
+	confirmCh chan struct{}
 }
 
+// CostCheck estimates the cost of reviewing modes. It returns the estimate to
+// show and whether it is high enough that the user must confirm it before the
+// reviews start.
+type CostCheck func(modes []review.Mode) (estimate string, confirm bool)
+
 // NewProgram creates and initializes a new TUI Program ready to be started.
+// The TUI takes over the alternate screen while it runs.
 func NewProgram() *Program {
+	return newProgram(NewModel(), tea.WithAltScreen())
+}
+
+// NewInlineProgram creates a TUI Program that renders inline in the terminal
+// scrollback, using the compact layout, instead of on the alternate screen.
+func NewInlineProgram() *Program {
 	model := NewModel()
-	program := tea.NewProgram(model, tea.WithAltScreen())
-	return &Program{
-		program: program,
-		model:   model,
+	model.SetCompact(true)
+	return newProgram(model)
+}
+
+// newProgram wraps model in a Bubble Tea program started with opts
+func newProgram(model *Model, opts ...tea.ProgramOption) *Program {
+	program := tea.NewProgram(model, opts...)
+	p := &Program{
+		program:   program,
+		model:     model,
+		cancels:   make(map[review.Mode]context.CancelFunc),
+		addCh:     make(chan review.Mode, len(review.AllModes())),
+		resumeCh:  make(chan struct{}, 1),
+		quitCh:    make(chan struct{}),
+		confirmCh: make(chan struct{}, 1),
 	}
+	p.stream = NewStreamCoalescer(DefaultStreamFlushInterval, p.SetStreamContent)
+	model.SetModeCanceler(p.CancelMode)
+	model.SetModeAdder(p.AddMode)
+	model.SetResumer(p.Resume)
+	model.SetCostConfirmer(p.ConfirmCost)
+	return p
 }
 
 // Start runs the TUI program and blocks until it exits.
 // Returns an error if the program fails to initialize or encounters a fatal error.
 func (p *Program) Start() error {
 	_, err := p.program.Run()
+	p.quitOnce.Do(func() { close(p.quitCh) })
 	return err
 }
 
@@ -78,6 +142,74 @@ func (p *Program) SetStreamContent(mode review.Mode, content string) {
 	p.Send(MsgStreamContent{Mode: mode, Content: content})
 }
 
+// SetAutoConfirm enables committing without the confirm screen when the review
+// found no issues at or above the block threshold
+func (p *Program) SetAutoConfirm(autoConfirm bool) {
+	p.model.SetAutoConfirm(autoConfirm)
+}
+
+// CancelMode cancels the context of a single in-flight review mode.
+// The mode is reported as skipped while the remaining modes keep running.
+// It is a no-op if the mode is not currently running.
+func (p *Program) CancelMode(mode review.Mode) {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	if cancel, ok := p.cancels[mode]; ok {
+		cancel()
+	}
+}
+
+// AddMode queues an additional review mode to run alongside the detected ones.
+// Modes can only be added until the first review result comes in; returns false
+// if the run no longer accepts new modes or already has mode, even one that
+// was skipped.
+func (p *Program) AddMode(mode review.Mode) bool {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	if !p.accepting || p.queued[mode] {
+		return false
+	}
+	select {
+	case p.addCh <- mode:
+		p.queued[mode] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// Resume restarts the reviews paused because authentication expired.
+// It is safe to call from any goroutine; extra calls are ignored.
+func (p *Program) Resume() {
+	select {
+	case p.resumeCh <- struct{}{}:
+	default:
+	}
+}
+
+// ConfirmCost starts the reviews held back until their estimated cost was
+// confirmed. It is safe to call from any goroutine; extra calls are ignored.
+func (p *Program) ConfirmCost() {
+	select {
+	case p.confirmCh <- struct{}{}:
+	default:
+	}
+}
+
+// setAccepting toggles whether AddMode queues new modes
+func (p *Program) setAccepting(accepting bool) {
+	p.cancelMu.Lock()
+	p.accepting = accepting
+	p.cancelMu.Unlock()
+}
+
+// QueueStreamContent buffers streaming content for a mode and forwards it to the
+// TUI at most once per DefaultStreamFlushInterval. Use this instead of
+// SetStreamContent from high-frequency stream callbacks.
+func (p *Program) QueueStreamContent(mode review.Mode, content string) {
+	p.stream.Add(mode, content)
+}
+
 // Quit quits the TUI
 func (p *Program) Quit() {
 	p.Send(MsgQuit{})
@@ -93,6 +225,11 @@ func (p *Program) IsBlocked() bool {
 	return p.model.IsBlocked()
 }
 
+// GetResults returns the review results shown in the issues table
+func (p *Program) GetResults() []*review.Result {
+	return p.model.GetResults()
+}
+
 // GetCommitMessage returns the generated commit message
 func (p *Program) GetCommitMessage() string {
 	return p.model.GetCommitMessage()
@@ -118,6 +255,88 @@ func (p *Program) SetFixApplier(applier FixApplier) {
 	p.model.SetFixApplier(applier)
 }
 
+// SetFixUndoer sets the callback function for undoing applied fixes
+func (p *Program) SetFixUndoer(undoer FixUndoer) {
+	p.model.SetFixUndoer(undoer)
+}
+
+// SetFixPreviewer sets the function used to render fix previews with context
+func (p *Program) SetFixPreviewer(previewer FixPreviewer) {
+	p.model.SetFixPreviewer(previewer)
+}
+
+// SetFixSplitter sets the function used to divide fixes into hunks that can
+// be accepted one by one in the diff preview
+func (p *Program) SetFixSplitter(splitter FixSplitter) {
+	p.model.SetFixSplitter(splitter)
+}
+
+// SetStreamLines sets how many lines of streamed output are shown for the
+// selected review while reviews run; 0 hides streamed output
+func (p *Program) SetStreamLines(n int) {
+	p.model.SetStreamLines(n)
+}
+
+// SetPayload sets the lines describing what is sent to the AI, shown in a
+// panel of the progress view; nil shows no panel
+func (p *Program) SetPayload(lines []string) {
+	p.model.SetPayload(lines)
+}
+
+// SetSeverityLabels sets the labels shown for severities in place of their
+// names and abbreviations
+func (p *Program) SetSeverityLabels(labels review.SeverityLabels) {
+	shared.SetSeverityLabels(labels)
+}
+
+// SetLimiter bounds how many reviews run at once and paces their starts.
+// Queued modes stay pending in the progress view until they start.
+func (p *Program) SetLimiter(l *review.Limiter) {
+	p.limiter = l
+}
+
+// SetModeTimeout limits how long each review may run once it has started.
+// A review that runs longer is reported as timed out while the others carry
+// on. Zero or less sets no limit.
+func (p *Program) SetModeTimeout(d time.Duration) {
+	p.modeTimeout = d
+}
+
+// SetBlockThreshold sets the lowest severity whose issues block, e.g. medium
+// on release branches. It is high unless set.
+func (p *Program) SetBlockThreshold(severity string) {
+	p.model.SetBlockThreshold(severity)
+}
+
+// SetCostCheck sets the function estimating the cost of the detected modes.
+// The estimate is shown once modes are detected, and reviews it asks to confirm
+// wait until the user starts them.
+func (p *Program) SetCostCheck(check CostCheck) {
+	p.costCheck = check
+}
+
+// SetSuggestionPromoter sets the function used to promote suggestions to issues
+func (p *Program) SetSuggestionPromoter(promoter SuggestionPromoter) {
+	p.model.SetSuggestionPromoter(promoter)
+}
+
+// SetIssueTriager sets the function used to save triage decisions about issues
+func (p *Program) SetIssueTriager(triager IssueTriager) {
+	p.model.SetIssueTriager(triager)
+}
+
+// SetIssueExporter sets the function used to export the issues selected in
+// the issues table
+func (p *Program) SetIssueExporter(exporter IssueExporter) {
+	p.model.SetIssueExporter(exporter)
+}
+
+// SetCommitRegenerator sets the function used to regenerate the commit
+// message with the user's feedback from the commit confirm view
+func (p *Program) SetCommitRegenerator(regenerator CommitRegenerator) {
+	p.model.SetCommitRegenerator(regenerator)
+}
+
 // RunWithCallbacks orchestrates the complete review workflow with real-time TUI updates.
 // It starts the TUI in a background goroutine, then executes mode detection, parallel reviews,
 // and commit message generation, updating the TUI at each step. Returns when the TUI exits.
@@ -128,6 +347,8 @@ func (p *Program) RunWithCallbacks(
 	commitFunc func(ctx context.Context) (string, error),
 	blockOnIssues bool,
 ) error {
+	p.model.SetBlockOnIssues(blockOnIssues)
+
 	// Run TUI in background
 	errCh := make(chan error, 1)
 	go func() {
@@ -141,42 +362,16 @@ func (p *Program) RunWithCallbacks(
 		return <-errCh
 	}
 	p.SetModesDetected(modes, reasoning)
-
-	// Run reviews in parallel
-	results := make([]*review.Result, len(modes))
-	resultsCh := make(chan struct {
-		idx    int
-		result *review.Result
-	}, len(modes))
-
-	for i, mode := range modes {
-		go func(idx int, m review.Mode) {
-			p.SetReviewStarted(m)
-			result, err := reviewFunc(ctx, m)
-			if err != nil {
-				result = &review.Result{
-					Mode:   m,
-					Status: review.StatusFailed,
-					Error:  err.Error(),
-				}
-			}
-			p.SetReviewComplete(result)
-			resultsCh <- struct {
-				idx    int
-				result *review.Result
-			}{idx, result}
-		}(i, mode)
+	if !p.checkCost(ctx, modes) {
+		return <-errCh
 	}
 
-	// Collect results
-	for range modes {
-		r := <-resultsCh
-		results[r.idx] = r.result
-	}
+	// Run reviews in parallel
+	results := p.runReviews(ctx, modes, reviewFunc)
 
 	// Check if should block
-	blocked := review.ShouldBlock(results, blockOnIssues)
-	blockReason := review.GetBlockReason(results)
+	blocked := review.ShouldBlockAt(results, blockOnIssues, p.model.blockThreshold)
+	blockReason := review.GetBlockReasonAt(results, p.model.blockThreshold)
 	p.SetAllReviewsComplete(results, blocked, blockReason)
 
 	if blocked {
@@ -194,6 +389,23 @@ func (p *Program) RunWithCallbacks(
 	return <-errCh
 }
 
+// RunCommitConfirm shows message on the commit confirm screen, without a
+// review, and returns when the user commits or cancels. IsConfirmed and
+// GetCommitMessage then report the outcome and the message, as edited or
+// regenerated. With auto-confirm enabled the message is accepted without
+// showing the screen.
+func (p *Program) RunCommitConfirm(message string) error {
+	if p.model.autoConfirm {
+		p.model.mu.Lock()
+		p.model.commitMessage = message
+		p.model.confirmed = true
+		p.model.mu.Unlock()
+		return nil
+	}
+	p.model.showCommitConfirm(message)
+	return p.Start()
+}
+
 // RunReviewOnly orchestrates a review-only workflow without commit generation.
 // It starts the TUI in a background goroutine, then executes mode detection and parallel reviews,
 // updating the TUI at each step. Returns when the TUI exits.
@@ -203,6 +415,8 @@ func (p *Program) RunReviewOnly(
 	reviewFunc func(ctx context.Context, mode review.Mode) (*review.Result, error),
 	blockOnIssues bool,
 ) error {
+	p.model.SetBlockOnIssues(blockOnIssues)
+
 	// Run TUI in background
 	errCh := make(chan error, 1)
 	go func() {
@@ -216,42 +430,16 @@ func (p *Program) RunReviewOnly(
 		return <-errCh
 	}
 	p.SetModesDetected(modes, reasoning)
-
-	// Run reviews in parallel
-	results := make([]*review.Result, len(modes))
-	resultsCh := make(chan struct {
-		idx    int
-		result *review.Result
-	}, len(modes))
-
-	for i, mode := range modes {
-		go func(idx int, m review.Mode) {
-			p.SetReviewStarted(m)
-			result, err := reviewFunc(ctx, m)
-			if err != nil {
-				result = &review.Result{
-					Mode:   m,
-					Status: review.StatusFailed,
-					Error:  err.Error(),
-				}
-			}
-			p.SetReviewComplete(result)
-			resultsCh <- struct {
-				idx    int
-				result *review.Result
-			}{idx, result}
-		}(i, mode)
+	if !p.checkCost(ctx, modes) {
+		return <-errCh
 	}
 
-	// Collect results
-	for range modes {
-		r := <-resultsCh
-		results[r.idx] = r.result
-	}
+	// Run reviews in parallel
+	results := p.runReviews(ctx, modes, reviewFunc)
 
 	// Check if should block
-	blocked := review.ShouldBlock(results, blockOnIssues)
-	blockReason := review.GetBlockReason(results)
+	blocked := review.ShouldBlockAt(results, blockOnIssues, p.model.blockThreshold)
+	blockReason := review.GetBlockReasonAt(results, p.model.blockThreshold)
 	p.SetAllReviewsComplete(results, blocked, blockReason)
 
 	// For review-only, we don't generate commit message but still allow
@@ -260,3 +448,250 @@ func (p *Program) RunReviewOnly(
 
 	return <-errCh
 }
+
+// checkCost shows the estimated cost of reviewing modes and, if it needs
+// confirmation, waits until the user starts the reviews. Returns false if the
+// TUI exits or ctx ends first.
+func (p *Program) checkCost(ctx context.Context, modes []review.Mode) bool {
+	if p.costCheck == nil {
+		return true
+	}
+	estimate, confirm := p.costCheck(modes)
+	p.Send(MsgCostEstimate{Estimate: estimate, Confirm: confirm})
+	if !confirm {
+		return true
+	}
+
+	select {
+	case <-p.confirmCh:
+		return true
+	case <-p.quitCh:
+		return false
+	case <-ctx.Done():
+		p.SetError(fmt.Sprintf("reviews were not started: %v", ctx.Err()))
+		return false
+	}
+}
+
+// runReviews executes all modes in parallel, each under its own cancellable context,
+// and returns results in the same order as modes, followed by any modes queued via
+// AddMode. A mode cancelled via CancelMode is reported as skipped immediately,
+// without waiting for its review call to return.
+//
+// If a review fails with review.ErrAuthRequired, the remaining reviews are paused
+// and the TUI asks the user to log in again. Completed results are kept, and the
+// paused modes are restarted when Resume is called. If the TUI exits while paused,
+// the paused modes are reported as failed.
+func (p *Program) runReviews(
+	ctx context.Context,
+	modes []review.Mode,
+	reviewFunc func(ctx context.Context, mode review.Mode) (*review.Result, error),
+) []*review.Result {
+	type indexedResult struct {
+		idx        int
+		mode       review.Mode
+		result     *review.Result
+		authFailed bool
+	}
+	type outcome struct {
+		result     *review.Result
+		authFailed bool
+	}
+
+	results := make([]*review.Result, 0, len(modes))
+	resultsCh := make(chan indexedResult, len(review.AllModes()))
+
+	// runCtx is replaced after each pause so resumed reviews get a live context
+	var runCtx context.Context
+	var pause context.CancelFunc
+	newRun := func() {
+		runCtx, pause = context.WithCancel(ctx)
+	}
+	newRun()
+	defer func() { pause() }()
+
+	start := func(idx int, m review.Mode) {
+		genCtx := runCtx
+		modeCtx, cancel := context.WithCancel(genCtx)
+		p.cancelMu.Lock()
+		p.cancels[m] = cancel
+		p.cancelMu.Unlock()
+
+		go func() {
+			defer func() {
+				p.cancelMu.Lock()
+				delete(p.cancels, m)
+				p.cancelMu.Unlock()
+				cancel()
+			}()
+
+			// Run the review in its own goroutine so a skipped mode does not
+			// keep the whole run waiting on a call that ignores cancellation
+			doneCh := make(chan outcome, 1)
+			expiredCh := make(chan struct{})
+			go func() {
+				// A mode waiting for a free slot can still be skipped or paused;
+				// the cancellation is handled below
+				release, err := p.limiter.Acquire(modeCtx)
+				if err != nil {
+					return
+				}
+				defer release()
+
+				p.SetReviewStarted(m)
+				reviewCtx, cancelReview := review.WithModeTimeout(modeCtx, p.modeTimeout)
+				defer cancelReview()
+				// Report the mode's time limit running out even if the review
+				// call ignores it
+				stop := context.AfterFunc(reviewCtx, func() {
+					if errors.Is(reviewCtx.Err(), context.DeadlineExceeded) && modeCtx.Err() == nil {
+						close(expiredCh)
+					}
+				})
+				defer stop()
+
+				result, err := reviewFunc(reviewCtx, m)
+				if err != nil {
+					result = &review.Result{
+						Mode:   m,
+						Status: review.StatusFailed,
+						Error:  err.Error(),
+					}
+				}
+				doneCh <- outcome{review.MarkTimedOut(reviewCtx, result), errors.Is(err, review.ErrAuthRequired)}
+			}()
+
+			var o outcome
+			select {
+			case o = <-doneCh:
+			case <-expiredCh:
+				select {
+				case o = <-doneCh:
+				default:
+					o.result = &review.Result{
+						Mode:    m,
+						Status:  review.StatusTimedOut,
+						Summary: fmt.Sprintf("Timed out after %s", p.modeTimeout),
+					}
+				}
+			case <-modeCtx.Done():
+				switch {
+				case ctx.Err() != nil:
+					o.result = review.MarkTimedOut(ctx, &review.Result{
+						Mode:   m,
+						Status: review.StatusFailed,
+						Error:  ctx.Err().Error(),
+					})
+				case genCtx.Err() != nil:
+					// Paused because another mode hit an authentication error
+					o = outcome{authFailedResult(m), true}
+				default:
+					// Only a cancellation of this mode counts as a skip
+					o.result = &review.Result{
+						Mode:    m,
+						Status:  review.StatusSkipped,
+						Summary: "Skipped by user",
+					}
+				}
+			}
+
+			if o.authFailed {
+				p.Send(MsgAuthRequired{Mode: m})
+			} else {
+				p.SetReviewComplete(o.result)
+			}
+			resultsCh <- indexedResult{idx, m, o.result, o.authFailed}
+		}()
+	}
+
+	p.cancelMu.Lock()
+	p.queued = make(map[review.Mode]bool, len(review.AllModes()))
+	for _, mode := range modes {
+		p.queued[mode] = true
+	}
+	p.cancelMu.Unlock()
+	p.setAccepting(true)
+	for _, mode := range modes {
+		results = append(results, nil)
+		start(len(results)-1, mode)
+	}
+
+	// Collect results, starting queued modes as they arrive
+	var paused []indexedResult
+	pending := len(modes)
+	for pending > 0 || len(paused) > 0 {
+		// Give up on paused modes only once nothing else is running
+		var quitCh <-chan struct{}
+		var doneCh <-chan struct{}
+		if pending == 0 {
+			quitCh = p.quitCh
+			doneCh = ctx.Done()
+		}
+
+		select {
+		case r := <-resultsCh:
+			pending--
+			if r.authFailed {
+				// Stop the remaining reviews; they would fail the same way
+				pause()
+				paused = append(paused, r)
+				continue
+			}
+			results[r.idx] = r.result
+			if r.result.Status != review.StatusSkipped {
+				p.setAccepting(false)
+			}
+		case m := <-p.addCh:
+			results = append(results, nil)
+			if len(paused) > 0 {
+				// Hold new modes back until the user has logged in again
+				paused = append(paused, indexedResult{idx: len(results) - 1, mode: m, result: authFailedResult(m), authFailed: true})
+				continue
+			}
+			start(len(results)-1, m)
+			pending++
+		case <-p.resumeCh:
+			if len(paused) == 0 {
+				continue
+			}
+			newRun()
+			for _, r := range paused {
+				start(r.idx, r.mode)
+				pending++
+			}
+			paused = nil
+		case <-quitCh:
+			for _, r := range paused {
+				results[r.idx] = r.result
+			}
+			paused = nil
+		case <-doneCh:
+			for _, r := range paused {
+				results[r.idx] = r.result
+			}
+			paused = nil
+		}
+	}
+	p.setAccepting(false)
+	p.stream.Flush()
+
+	// Modes queued after the last result came in are reported as skipped
+	for {
+		select {
+		case m := <-p.addCh:
+			p.SetReviewComplete(&review.Result{Mode: m, Status: review.StatusSkipped})
+		default:
+			return review.MergeDuplicates(results)
+		}
+	}
+}
+
+// authFailedResult returns the result reported for a mode that could not run
+// because authentication expired
+func authFailedResult(mode review.Mode) *review.Result {
+	return &review.Result{
+		Mode:   mode,
+		Status: review.StatusFailed,
+		Error:  review.ErrAuthRequired.Error(),
+	}
+}
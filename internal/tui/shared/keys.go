@@ -1,6 +1,10 @@
 package shared
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 // KeyMap defines all keybindings for the TUI
 type KeyMap struct {
@@ -11,9 +15,26 @@ type KeyMap struct {
 	Quit         key.Binding
 	Commit       key.Binding
 	Apply        key.Binding
+	ApplyAll     key.Binding
 	Confirm      key.Binding
 	Cancel       key.Binding
 	Edit         key.Binding
+	Regenerate   key.Binding
+	Skip         key.Binding
+	ToggleMode   key.Binding
+	Resume       key.Binding
+	Promote      key.Binding
+	Undo         key.Binding
+	Raise        key.Binding
+	Lower        key.Binding
+	WontFix      key.Binding
+	Select       key.Binding
+	SelectAll    key.Binding
+	Export       key.Binding
+	Search       key.Binding
+	PickHunks    key.Binding
+	ToggleHunk   key.Binding
+	SideBySide   key.Binding
 	ScrollUp     key.Binding
 	ScrollDown   key.Binding
 	PageUp       key.Binding
@@ -55,6 +76,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("a"),
 			key.WithHelp("a", "preview fix"),
 		),
+		ApplyAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "apply all fixes"),
+		),
 		Confirm: key.NewBinding(
 			key.WithKeys("y"),
 			key.WithHelp("y", "confirm"),
@@ -67,6 +92,70 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit"),
 		),
+		Regenerate: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "regenerate"),
+		),
+		Skip: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "skip mode"),
+		),
+		ToggleMode: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7"),
+			key.WithHelp("1-7", "toggle mode"),
+		),
+		Resume: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "resume"),
+		),
+		Promote: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "promote suggestion"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo fix"),
+		),
+		Raise: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "raise severity"),
+		),
+		Lower: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "lower severity"),
+		),
+		WontFix: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "won't fix"),
+		),
+		Select: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "select"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "select all"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export selected"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		PickHunks: key.NewBinding(
+			key.WithKeys("h"),
+			key.WithHelp("h", "pick hunks"),
+		),
+		ToggleHunk: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "accept/reject hunk"),
+		),
+		SideBySide: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "side by side"),
+		),
 		ScrollUp: key.NewBinding(
 			key.WithKeys("up", "k"),
 			key.WithHelp("↑/k", "scroll up"),
@@ -120,17 +209,118 @@ func IssueDetailHelp(hasFix bool) string {
 	return " [Esc] close"
 }
 
+// PromoteHelp returns the help text appended to the issues table help when
+// there are suggestions that can be promoted to issues
+func PromoteHelp() string {
+	return "  [p] promote suggestion"
+}
+
+// UndoHelp returns the help text appended to the issues table help when the
+// selected issue's fix has been applied
+func UndoHelp() string {
+	return "  [u] undo fix"
+}
+
+// TriageHelp returns the help text appended to the issues table help when the
+// selected issue can be triaged
+func TriageHelp() string {
+	return "  [+/-] severity  [w] won't fix"
+}
+
+// SelectHelp returns the help text appended to the issues table help when
+// there are issues that can be selected for bulk actions
+func SelectHelp() string {
+	return "  [space] select  [A] all"
+}
+
+// BulkHelp returns the help text for the actions on the issues selected in
+// the issues table
+func BulkHelp(selected int) string {
+	return fmt.Sprintf(" %d selected: [a] apply fixes  [+/-] severity  [w] won't fix  [e] export  [A] clear", selected)
+}
+
+// StartSearchHelp returns the help text appended to the issues table help when
+// the table can be searched
+func StartSearchHelp() string {
+	return "  [/] search"
+}
+
+// ClearSearchHelp returns the help text appended to the issues table help
+// while a search filters the table
+func ClearSearchHelp() string {
+	return "  [/] edit search  [Esc] clear"
+}
+
+// SearchHelp returns help text for the issues table while a search is typed
+func SearchHelp() string {
+	return " [Enter] keep filter  [Esc] clear  mode:<mode> sev:<severity> file:<path>"
+}
+
+// ApplyAllHelp returns the help text appended to the issues table help when
+// some fixes have not been applied yet
+func ApplyAllHelp() string {
+	return "  [a] apply all fixes"
+}
+
+// SuggestionDetailHelp returns help text for the detail modal of a suggestion
+func SuggestionDetailHelp() string {
+	return " [p] promote to issue  [Esc] close"
+}
+
 // DiffPreviewHelp returns help text for the diff preview modal
 func DiffPreviewHelp() string {
 	return " [y] apply fix  [n/Esc] cancel"
 }
 
+// PickHunksHelp returns the help text appended to the diff preview help when
+// the fix changes several places that can be accepted one by one
+func PickHunksHelp() string {
+	return "  [h] pick hunks"
+}
+
+// SideBySideHelp returns the help text appended to the diff preview help when
+// the terminal is wide enough to switch layouts
+func SideBySideHelp(sideBySide bool) string {
+	if sideBySide {
+		return "  [s] unified"
+	}
+	return "  [s] side by side"
+}
+
+// HunkPickHelp returns help text for the diff preview while picking hunks
+func HunkPickHelp() string {
+	return " [↑/↓] hunk  [space] accept/reject  [y] apply accepted  [n/Esc] cancel"
+}
+
+// HunkStagingHelp returns help text for the hunk staging view
+func HunkStagingHelp() string {
+	return " [↑/↓] hunk  [space] stage/unstage  [y] write to index  [n/Esc] cancel"
+}
+
 // CommitConfirmHelp returns help text for the commit confirm view
 func CommitConfirmHelp() string {
 	return " [y] commit  [e] edit message  [n/Esc] cancel"
 }
 
+// CommitConfirmRegenerateHelp returns help text for the commit confirm view
+// when the message can be regenerated with feedback
+func CommitConfirmRegenerateHelp() string {
+	return " [y] commit  [e] edit message  [r] regenerate  [n/Esc] cancel"
+}
+
+// ProgressAuthHelp returns help text for the progress view while reviews are
+// paused waiting for the user to log in again
+func ProgressAuthHelp() string {
+	return " [r] resume  [q] quit"
+}
+
+// ProgressCostHelp returns help text for the progress view while reviews wait
+// for the user to confirm their estimated cost
+func ProgressCostHelp() string {
+	return " [enter] start reviews  [q] quit"
+}
+
 // ProgressHelp returns help text for the progress view
 func ProgressHelp() string {
-	return " [q] quit"
+	return " [↑/k] up  [↓/j] down  [x] skip mode  [1-7] toggle mode  [q] quit"
 }
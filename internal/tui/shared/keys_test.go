@@ -110,3 +110,23 @@ func TestCommitConfirmHelp_ContainsAllOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestCommitConfirmRegenerateHelp_ContainsRegenerate(t *testing.T) {
+	help := CommitConfirmRegenerateHelp()
+
+	for _, key := range []string{"commit", "edit", "[r] regenerate", "cancel"} {
+		if !strings.Contains(help, key) {
+			t.Errorf("CommitConfirmRegenerateHelp() should contain %q", key)
+		}
+	}
+}
+
+func TestHunkStagingHelp_ContainsAllOptions(t *testing.T) {
+	help := HunkStagingHelp()
+
+	for _, key := range []string{"[space] stage/unstage", "[y] write to index", "cancel"} {
+		if !strings.Contains(help, key) {
+			t.Errorf("HunkStagingHelp() should contain %q", key)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 // Package shared provides shared types, styles, and constants for the TUI package.
 package shared
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/buker/revi/internal/review"
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Color definitions for the TUI
 var (
@@ -85,6 +88,23 @@ var (
 	DiffHunkStyle = lipgloss.NewStyle().
 			Foreground(ColorAccent)
 
+	// Syntax highlighting styles for code in diffs
+	LineNumberStyle = lipgloss.NewStyle().
+			Foreground(ColorDimmed)
+
+	SyntaxKeywordStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF79C6"))
+
+	SyntaxStringStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F1FA8C"))
+
+	SyntaxNumberStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#BD93F9"))
+
+	SyntaxCommentStyle = lipgloss.NewStyle().
+				Foreground(ColorDimmed).
+				Italic(true)
+
 	// Help/Footer styles
 	HelpKeyStyle = lipgloss.NewStyle().
 			Foreground(ColorAccent)
@@ -115,11 +135,13 @@ const (
 	StatusIndicatorRunning = "◐"
 	StatusIndicatorDone    = "✓"
 	StatusIndicatorFailed  = "✗"
+	StatusIndicatorSkipped = "⊘"
 
 	FixAvailableIndicator   = "✓"
 	FixUnavailableIndicator = "✗"
 
 	SelectionChar = "▶"
+	CheckedChar   = "●"
 )
 
 // RenderDivider creates a horizontal divider of the specified width
@@ -151,8 +173,26 @@ func SeverityStyle(severity string) lipgloss.Style {
 	}
 }
 
-// SeverityAbbrev returns a 3-letter abbreviation for severity
+// severityLabels holds the labels set with SetSeverityLabels
+var severityLabels review.SeverityLabels
+
+// SetSeverityLabels sets the labels shown for severities in place of their
+// names and abbreviations. Nil restores the defaults.
+func SetSeverityLabels(labels review.SeverityLabels) {
+	severityLabels = labels
+}
+
+// SeverityLabel returns the label set for a severity, or its name in upper case
+func SeverityLabel(severity string) string {
+	return severityLabels.Label(severity)
+}
+
+// SeverityAbbrev returns the label set for a severity, or a 3-letter
+// abbreviation if none is set
 func SeverityAbbrev(severity string) string {
+	if label, ok := severityLabels[severity]; ok {
+		return label
+	}
 	switch severity {
 	case "high":
 		return "HIG"
@@ -162,3 +202,33 @@ func SeverityAbbrev(severity string) string {
 		return "LOW"
 	}
 }
+
+// AgreementBadge returns a short badge for a cross-checked issue's agreement,
+// or an empty string if the issue was not cross-checked
+func AgreementBadge(agreement string) string {
+	switch agreement {
+	case review.AgreementBoth:
+		return "[2/2]"
+	case review.AgreementPrimaryOnly:
+		return "[1st]"
+	case review.AgreementSecondaryOnly:
+		return "[2nd]"
+	default:
+		return ""
+	}
+}
+
+// AgreementDescription returns a human-readable description of a cross-checked
+// issue's agreement, or an empty string if the issue was not cross-checked
+func AgreementDescription(agreement string) string {
+	switch agreement {
+	case review.AgreementBoth:
+		return "Found by both models"
+	case review.AgreementPrimaryOnly:
+		return "Found only by the primary model"
+	case review.AgreementSecondaryOnly:
+		return "Found only by the cross-check model"
+	default:
+		return ""
+	}
+}
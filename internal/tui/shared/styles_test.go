@@ -0,0 +1,48 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// =============================================================================
+// Tests for AgreementBadge()
+// =============================================================================
+
+func TestAgreementBadge(t *testing.T) {
+	tests := map[string]string{
+		review.AgreementBoth:          "[2/2]",
+		review.AgreementPrimaryOnly:   "[1st]",
+		review.AgreementSecondaryOnly: "[2nd]",
+		"":                            "",
+	}
+	for agreement, want := range tests {
+		if got := AgreementBadge(agreement); got != want {
+			t.Errorf("AgreementBadge(%q) = %q, want %q", agreement, got, want)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for severity labels
+// =============================================================================
+
+func TestSetSeverityLabels(t *testing.T) {
+	t.Cleanup(func() { SetSeverityLabels(nil) })
+
+	if SeverityAbbrev("high") != "HIG" || SeverityLabel("high") != "HIGH" {
+		t.Errorf("default labels = %q/%q, want HIG/HIGH", SeverityAbbrev("high"), SeverityLabel("high"))
+	}
+
+	SetSeverityLabels(review.SeverityLabels{"high": "[!] high"})
+	if got := SeverityAbbrev("high"); got != "[!] high" {
+		t.Errorf("SeverityAbbrev(high) = %q, want the configured label", got)
+	}
+	if got := SeverityLabel("high"); got != "[!] high" {
+		t.Errorf("SeverityLabel(high) = %q, want the configured label", got)
+	}
+	if got := SeverityAbbrev("low"); got != "LOW" {
+		t.Errorf("SeverityAbbrev(low) = %q, want the default for unset levels", got)
+	}
+}
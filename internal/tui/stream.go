@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// DefaultStreamFlushInterval is the minimum time between stream updates sent
+// to the TUI for a single mode.
+const DefaultStreamFlushInterval = 50 * time.Millisecond
+
+// StreamCoalescer buffers streaming chunks per mode and forwards them in batches,
+// so rapid stream callbacks do not flood the Bubble Tea event loop.
+// Each mode is flushed at most once per interval; chunks arriving in between are
+// concatenated and delivered by a trailing flush. It is safe for concurrent use.
+type StreamCoalescer struct {
+	interval time.Duration
+	send     func(mode review.Mode, content string)
+
+	mu        sync.Mutex
+	pending   map[review.Mode]*strings.Builder
+	lastFlush map[review.Mode]time.Time
+	timers    map[review.Mode]*time.Timer
+}
+
+// NewStreamCoalescer creates a StreamCoalescer that delivers buffered content via send.
+func NewStreamCoalescer(interval time.Duration, send func(mode review.Mode, content string)) *StreamCoalescer {
+	return &StreamCoalescer{
+		interval:  interval,
+		send:      send,
+		pending:   make(map[review.Mode]*strings.Builder),
+		lastFlush: make(map[review.Mode]time.Time),
+		timers:    make(map[review.Mode]*time.Timer),
+	}
+}
+
+// Add buffers a chunk of content for a mode. The chunk is sent immediately if the
+// mode has not been flushed within the interval, otherwise a trailing flush is scheduled.
+func (c *StreamCoalescer) Add(mode review.Mode, content string) {
+	if content == "" {
+		return
+	}
+
+	c.mu.Lock()
+	buf, ok := c.pending[mode]
+	if !ok {
+		buf = &strings.Builder{}
+		c.pending[mode] = buf
+	}
+	buf.WriteString(content)
+
+	wait := c.interval - time.Since(c.lastFlush[mode])
+	if wait <= 0 {
+		out := c.takeLocked(mode)
+		c.mu.Unlock()
+		c.send(mode, out)
+		return
+	}
+
+	if _, scheduled := c.timers[mode]; !scheduled {
+		c.timers[mode] = time.AfterFunc(wait, func() {
+			c.flushMode(mode)
+		})
+	}
+	c.mu.Unlock()
+}
+
+// Flush immediately sends all buffered content and cancels scheduled flushes.
+func (c *StreamCoalescer) Flush() {
+	c.mu.Lock()
+	var modes []review.Mode
+	var contents []string
+	for mode := range c.pending {
+		if out := c.takeLocked(mode); out != "" {
+			modes = append(modes, mode)
+			contents = append(contents, out)
+		}
+	}
+	c.mu.Unlock()
+
+	for i, mode := range modes {
+		c.send(mode, contents[i])
+	}
+}
+
+// flushMode sends the buffered content for a single mode
+func (c *StreamCoalescer) flushMode(mode review.Mode) {
+	c.mu.Lock()
+	out := c.takeLocked(mode)
+	c.mu.Unlock()
+
+	if out != "" {
+		c.send(mode, out)
+	}
+}
+
+// takeLocked drains the buffer for a mode and records the flush time.
+// The caller must hold c.mu.
+func (c *StreamCoalescer) takeLocked(mode review.Mode) string {
+	if timer, ok := c.timers[mode]; ok {
+		timer.Stop()
+		delete(c.timers, mode)
+	}
+	c.lastFlush[mode] = time.Now()
+
+	buf, ok := c.pending[mode]
+	if !ok || buf.Len() == 0 {
+		return ""
+	}
+	out := buf.String()
+	buf.Reset()
+	return out
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// recordingSender collects coalesced stream sends for assertions
+type recordingSender struct {
+	mu    sync.Mutex
+	sends map[review.Mode][]string
+}
+
+func newRecordingSender() *recordingSender {
+	return &recordingSender{sends: make(map[review.Mode][]string)}
+}
+
+func (r *recordingSender) send(mode review.Mode, content string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[mode] = append(r.sends[mode], content)
+}
+
+func (r *recordingSender) get(mode review.Mode) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sends[mode]...)
+}
+
+func TestStreamCoalescer_LimitsMessageRate(t *testing.T) {
+	rec := newRecordingSender()
+	interval := 50 * time.Millisecond
+	c := NewStreamCoalescer(interval, rec.send)
+
+	// Emit a chunk every millisecond for ~250ms
+	const chunks = 250
+	start := time.Now()
+	for i := 0; i < chunks; i++ {
+		c.Add(review.ModeSecurity, "x")
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	c.Flush()
+
+	sends := rec.get(review.ModeSecurity)
+	// One leading send plus at most one per elapsed interval, plus the final flush
+	maxSends := int(elapsed/interval) + 2
+	if len(sends) > maxSends {
+		t.Errorf("got %d sends for %d chunks over %v, want at most %d", len(sends), chunks, elapsed, maxSends)
+	}
+	if len(sends) >= chunks/10 {
+		t.Errorf("got %d sends for %d chunks, expected heavy coalescing", len(sends), chunks)
+	}
+
+	if got := strings.Join(sends, ""); got != strings.Repeat("x", chunks) {
+		t.Errorf("coalesced content length = %d, want %d", len(got), chunks)
+	}
+}
+
+func TestStreamCoalescer_FlushesPerModeIndependently(t *testing.T) {
+	rec := newRecordingSender()
+	c := NewStreamCoalescer(time.Hour, rec.send)
+
+	// The first chunk for each mode goes out immediately
+	c.Add(review.ModeSecurity, "a")
+	c.Add(review.ModeStyle, "b")
+	// Subsequent chunks are held until the next flush
+	c.Add(review.ModeSecurity, "c")
+	c.Add(review.ModeSecurity, "d")
+
+	if got := rec.get(review.ModeSecurity); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("security sends before flush = %v, want [a]", got)
+	}
+	if got := rec.get(review.ModeStyle); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("style sends before flush = %v, want [b]", got)
+	}
+
+	c.Flush()
+
+	if got := rec.get(review.ModeSecurity); len(got) != 2 || got[1] != "cd" {
+		t.Errorf("security sends after flush = %v, want [a cd]", got)
+	}
+	if got := rec.get(review.ModeStyle); len(got) != 1 {
+		t.Errorf("style sends after flush = %v, want no extra send", got)
+	}
+}
+
+func TestStreamCoalescer_TrailingFlushDeliversBufferedContent(t *testing.T) {
+	rec := newRecordingSender()
+	c := NewStreamCoalescer(20*time.Millisecond, rec.send)
+
+	c.Add(review.ModeDocs, "first")
+	c.Add(review.ModeDocs, "second")
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if len(rec.get(review.ModeDocs)) == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	got := rec.get(review.ModeDocs)
+	if len(got) != 2 || got[1] != "second" {
+		t.Errorf("sends = %v, want [first second] after trailing flush", got)
+	}
+}
@@ -51,6 +51,7 @@ const (
 	StatusIndicatorRunning  = shared.StatusIndicatorRunning
 	StatusIndicatorDone     = shared.StatusIndicatorDone
 	StatusIndicatorFailed   = shared.StatusIndicatorFailed
+	StatusIndicatorSkipped  = shared.StatusIndicatorSkipped
 	FixAvailableIndicator   = shared.FixAvailableIndicator
 	FixUnavailableIndicator = shared.FixUnavailableIndicator
 	SelectionChar           = shared.SelectionChar
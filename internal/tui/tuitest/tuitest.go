@@ -0,0 +1,226 @@
+// Package tuitest drives Bubble Tea models headlessly for interaction tests.
+// A Driver feeds messages and key presses to a model, runs the commands it
+// returns and records each rendered frame, so tests can script a session and
+// assert on what the user would see without a terminal or a running program.
+package tuitest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultCmdTimeout is how long the driver waits for a command to produce a
+// message. Commands that take longer, such as spinner and cursor ticks, are
+// dropped so animations do not keep a test running.
+const DefaultCmdTimeout = 50 * time.Millisecond
+
+// maxDepth bounds how many messages a single input may trigger through chained
+// commands, guarding against models that re-arm a command forever
+const maxDepth = 100
+
+// Driver runs a Bubble Tea model without a terminal.
+type Driver struct {
+	model      tea.Model
+	frames     []string
+	cmdTimeout time.Duration
+	quit       bool
+}
+
+// Option configures a Driver.
+type Option func(*Driver)
+
+// WithCmdTimeout sets how long each command may run before it is dropped.
+func WithCmdTimeout(timeout time.Duration) Option {
+	return func(d *Driver) {
+		d.cmdTimeout = timeout
+	}
+}
+
+// New returns a Driver for model. It runs the model's Init command and sends
+// a window size of width by height, as a program would on startup.
+func New(model tea.Model, width, height int, opts ...Option) *Driver {
+	d := &Driver{model: model, cmdTimeout: DefaultCmdTimeout}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.run(model.Init(), 0)
+	d.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	return d
+}
+
+// Send delivers msgs to the model in order, running the commands each update
+// returns and feeding their messages back in. Messages sent after the model
+// quit are ignored.
+func (d *Driver) Send(msgs ...tea.Msg) *Driver {
+	for _, msg := range msgs {
+		d.update(msg, 0)
+	}
+	return d
+}
+
+// Press sends a key press for each key, named as in tea.KeyMsg.String(), e.g.
+// "enter", "esc", "ctrl+c", "down" or "q". Any other single character is sent
+// as typed text.
+func (d *Driver) Press(keys ...string) *Driver {
+	for _, k := range keys {
+		d.Send(Key(k))
+	}
+	return d
+}
+
+// Type sends each rune of text as a separate key press.
+func (d *Driver) Type(text string) *Driver {
+	for _, r := range text {
+		d.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return d
+}
+
+// Model returns the model as last returned by Update.
+func (d *Driver) Model() tea.Model {
+	return d.model
+}
+
+// Quit reports whether the model has returned tea.Quit.
+func (d *Driver) Quit() bool {
+	return d.quit
+}
+
+// Frame returns the current view with ANSI escape sequences removed.
+func (d *Driver) Frame() string {
+	return ansi.Strip(d.model.View())
+}
+
+// Frames returns every distinct frame rendered so far, oldest first, with ANSI
+// escape sequences removed.
+func (d *Driver) Frames() []string {
+	return append([]string(nil), d.frames...)
+}
+
+// ExpectFrame fails the test unless the current frame contains each of want.
+func (d *Driver) ExpectFrame(t testing.TB, want ...string) {
+	t.Helper()
+	frame := d.Frame()
+	for _, w := range want {
+		if !strings.Contains(frame, w) {
+			t.Errorf("frame does not contain %q:\n%s", w, frame)
+		}
+	}
+}
+
+// RejectFrame fails the test if the current frame contains any of unwanted.
+func (d *Driver) RejectFrame(t testing.TB, unwanted ...string) {
+	t.Helper()
+	frame := d.Frame()
+	for _, u := range unwanted {
+		if strings.Contains(frame, u) {
+			t.Errorf("frame should not contain %q:\n%s", u, frame)
+		}
+	}
+}
+
+// Key returns the key press named k, as accepted by Press.
+func Key(k string) tea.KeyMsg {
+	if t, ok := keyTypes[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	if k == "space" {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	if alt, ok := strings.CutPrefix(k, "alt+"); ok && len([]rune(alt)) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(alt), Alt: true}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// keyTypes maps key names, as returned by tea.KeyType.String(), to key types
+var keyTypes = func() map[string]tea.KeyType {
+	types := make(map[string]tea.KeyType)
+	for t := tea.KeyType(-100); t <= tea.KeyCtrlQuestionMark; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			if _, ok := types[name]; !ok {
+				types[name] = t
+			}
+		}
+	}
+	return types
+}()
+
+// update applies msg to the model, records the frame and runs the command
+func (d *Driver) update(msg tea.Msg, depth int) {
+	if d.quit || depth > maxDepth {
+		return
+	}
+	model, cmd := d.model.Update(msg)
+	d.model = model
+	d.record()
+	d.run(cmd, depth+1)
+}
+
+// run executes cmd and delivers its message, expanding batches and sequences
+func (d *Driver) run(cmd tea.Cmd, depth int) {
+	if cmd == nil || d.quit {
+		return
+	}
+	msg, ok := d.exec(cmd)
+	if !ok || msg == nil {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case tea.QuitMsg:
+		d.quit = true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			d.run(c, depth)
+		}
+	default:
+		// tea.Sequence produces an unexported []tea.Cmd type
+		if seq, ok := asCmds(msg); ok {
+			for _, c := range seq {
+				d.run(c, depth)
+			}
+			return
+		}
+		d.update(msg, depth)
+	}
+}
+
+// exec runs cmd, giving up after the command timeout
+func (d *Driver) exec(cmd tea.Cmd) (tea.Msg, bool) {
+	done := make(chan tea.Msg, 1)
+	go func() {
+		done <- cmd()
+	}()
+	select {
+	case msg := <-done:
+		return msg, true
+	case <-time.After(d.cmdTimeout):
+		return nil, false
+	}
+}
+
+// record appends the current frame if it differs from the previous one
+func (d *Driver) record() {
+	frame := d.Frame()
+	if n := len(d.frames); n > 0 && d.frames[n-1] == frame {
+		return
+	}
+	d.frames = append(d.frames, frame)
+}
+
+var cmdsType = reflect.TypeOf([]tea.Cmd(nil))
+
+// asCmds converts msg to a list of commands if its type is a []tea.Cmd
+func asCmds(msg tea.Msg) ([]tea.Cmd, bool) {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || !v.Type().ConvertibleTo(cmdsType) {
+		return nil, false
+	}
+	return v.Convert(cmdsType).Interface().([]tea.Cmd), true
+}
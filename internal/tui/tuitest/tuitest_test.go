@@ -0,0 +1,132 @@
+package tuitest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type incMsg struct{}
+
+type tickMsg struct{}
+
+// counter is a minimal model: up/down change the count, typed text is echoed,
+// "b" and "s" increment twice through a batch and a sequence, "t" starts a
+// slow tick and "q" quits
+type counter struct {
+	count  int
+	text   string
+	width  int
+	ticked bool
+}
+
+func (c *counter) Init() tea.Cmd {
+	return func() tea.Msg { return incMsg{} }
+}
+
+func (c *counter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	inc := func() tea.Msg { return incMsg{} }
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width = msg.Width
+	case incMsg:
+		c.count++
+	case tickMsg:
+		c.ticked = true
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up":
+			c.count++
+		case "down":
+			c.count--
+		case "b":
+			return c, tea.Batch(inc, inc)
+		case "s":
+			return c, tea.Sequence(inc, inc)
+		case "t":
+			return c, tea.Tick(time.Second, func(time.Time) tea.Msg { return tickMsg{} })
+		case "q":
+			return c, tea.Quit
+		default:
+			c.text += msg.String()
+		}
+	}
+	return c, nil
+}
+
+func (c *counter) View() string {
+	return fmt.Sprintf("count=%d width=%d text=%s ticked=%v", c.count, c.width, c.text, c.ticked)
+}
+
+func TestNew_RunsInitAndSetsSize(t *testing.T) {
+	d := New(&counter{}, 80, 24)
+	d.ExpectFrame(t, "count=1", "width=80")
+}
+
+func TestDriver_PressAndType(t *testing.T) {
+	d := New(&counter{}, 80, 24)
+	d.Press("up", "up", "down").Type("hi")
+
+	d.ExpectFrame(t, "count=2", "text=hi")
+	if got := d.Model().(*counter).count; got != 2 {
+		t.Errorf("count = %d, want 2", got)
+	}
+}
+
+func TestDriver_RunsBatchesAndSequences(t *testing.T) {
+	d := New(&counter{}, 80, 24)
+	d.Press("b", "s")
+	d.ExpectFrame(t, "count=5")
+}
+
+func TestDriver_DropsSlowCommands(t *testing.T) {
+	d := New(&counter{}, 80, 24, WithCmdTimeout(10*time.Millisecond))
+	d.Press("t")
+	d.ExpectFrame(t, "ticked=false")
+}
+
+func TestDriver_Quit(t *testing.T) {
+	d := New(&counter{}, 80, 24)
+	d.Press("q", "up")
+	if !d.Quit() {
+		t.Error("Quit() = false after tea.Quit")
+	}
+	d.RejectFrame(t, "count=2")
+}
+
+func TestDriver_FramesSkipsRepeats(t *testing.T) {
+	d := New(&counter{}, 80, 24)
+	d.Press("up", "left", "left")
+
+	frames := d.Frames()
+	last := frames[len(frames)-1]
+	if !strings.Contains(last, "count=2 width=80 text=leftleft") {
+		t.Errorf("last frame = %q", last)
+	}
+	for i := 1; i < len(frames); i++ {
+		if frames[i] == frames[i-1] {
+			t.Errorf("frame %d repeats the previous frame", i)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"enter":  "enter",
+		"esc":    "esc",
+		"ctrl+c": "ctrl+c",
+		"down":   "down",
+		"tab":    "tab",
+		"space":  " ",
+		"alt+x":  "alt+x",
+		"q":      "q",
+	}
+	for name, want := range tests {
+		if got := Key(name).String(); got != want {
+			t.Errorf("Key(%q).String() = %q, want %q", name, got, want)
+		}
+	}
+}
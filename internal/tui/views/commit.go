@@ -6,6 +6,7 @@ import (
 
 	"github.com/buker/revi/internal/tui/shared"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -18,7 +19,15 @@ type CommitConfirmView struct {
 	issuesFixed   int
 	blocked       bool
 	editing       bool
+	compact       bool
 	textarea      textarea.Model
+
+	// Regenerating the message with feedback
+	canRegenerate  bool
+	askingFeedback bool // The feedback prompt is open
+	feedback       textinput.Model
+	regenerating   bool
+	regenerateErr  string
 }
 
 // NewCommitConfirmView creates a new commit confirm view
@@ -28,8 +37,13 @@ func NewCommitConfirmView() *CommitConfirmView {
 	ta.ShowLineNumbers = false
 	ta.CharLimit = 0
 
+	fb := textinput.New()
+	fb.Prompt = " Feedback: "
+	fb.Placeholder = "e.g. mention the migration, use scope api"
+
 	return &CommitConfirmView{
 		textarea: ta,
+		feedback: fb,
 	}
 }
 
@@ -53,7 +67,17 @@ func (v *CommitConfirmView) SetSize(width, height int) {
 
 	// Size the textarea for editing mode
 	v.textarea.SetWidth(min(width-10, 60))
-	v.textarea.SetHeight(8)
+	if v.compact {
+		v.textarea.SetHeight(4)
+	} else {
+		v.textarea.SetHeight(8)
+	}
+}
+
+// SetCompact switches to the compact layout used when rendering inline, with
+// a shorter editor
+func (v *CommitConfirmView) SetCompact(compact bool) {
+	v.compact = compact
 }
 
 // GetCommitMessage returns the current commit message (may be edited)
@@ -91,6 +115,57 @@ func (v *CommitConfirmView) CancelEditing() {
 	v.textarea.Blur()
 }
 
+// SetCanRegenerate sets whether the message can be regenerated with feedback
+func (v *CommitConfirmView) SetCanRegenerate(canRegenerate bool) {
+	v.canRegenerate = canRegenerate
+}
+
+// IsAskingFeedback returns true while the feedback prompt is open
+func (v *CommitConfirmView) IsAskingFeedback() bool {
+	return v.askingFeedback
+}
+
+// StartFeedback opens the prompt for feedback on the message, e.g. "mention
+// the migration", to regenerate it with
+func (v *CommitConfirmView) StartFeedback() tea.Cmd {
+	v.askingFeedback = true
+	v.regenerateErr = ""
+	v.feedback.SetValue("")
+	return v.feedback.Focus()
+}
+
+// SubmitFeedback closes the feedback prompt and returns the feedback typed
+func (v *CommitConfirmView) SubmitFeedback() string {
+	v.askingFeedback = false
+	v.feedback.Blur()
+	return strings.TrimSpace(v.feedback.Value())
+}
+
+// CancelFeedback closes the feedback prompt without regenerating the message
+func (v *CommitConfirmView) CancelFeedback() {
+	v.askingFeedback = false
+	v.feedback.Blur()
+}
+
+// IsRegenerating returns true while the message is being regenerated
+func (v *CommitConfirmView) IsRegenerating() bool {
+	return v.regenerating
+}
+
+// SetRegenerating marks the message as being regenerated, or done
+func (v *CommitConfirmView) SetRegenerating(regenerating bool) {
+	v.regenerating = regenerating
+	if regenerating {
+		v.regenerateErr = ""
+	}
+}
+
+// SetRegenerateError shows why the message could not be regenerated
+func (v *CommitConfirmView) SetRegenerateError(err string) {
+	v.regenerating = false
+	v.regenerateErr = err
+}
+
 // Init initializes the view
 func (v *CommitConfirmView) Init() tea.Cmd {
 	return nil
@@ -98,6 +173,11 @@ func (v *CommitConfirmView) Init() tea.Cmd {
 
 // Update handles messages
 func (v *CommitConfirmView) Update(msg tea.Msg) (*CommitConfirmView, tea.Cmd) {
+	if v.askingFeedback {
+		var cmd tea.Cmd
+		v.feedback, cmd = v.feedback.Update(msg)
+		return v, cmd
+	}
 	if v.editing {
 		var cmd tea.Cmd
 		v.textarea, cmd = v.textarea.Update(msg)
@@ -131,6 +211,20 @@ func (v *CommitConfirmView) View() string {
 		b.WriteString(v.renderMessageBox())
 	}
 
+	switch {
+	case v.askingFeedback:
+		b.WriteString("\n\n")
+		b.WriteString(v.feedback.View())
+		b.WriteString("\n")
+		b.WriteString(shared.HelpKeyStyle.Render(" [Enter] regenerate  [Esc] cancel"))
+	case v.regenerating:
+		b.WriteString("\n ")
+		b.WriteString(shared.StatusRunningStyle.Render("Regenerating commit message..."))
+	case v.regenerateErr != "":
+		b.WriteString("\n ")
+		b.WriteString(shared.HighSeverityStyle.Render("Regenerating failed: " + v.regenerateErr))
+	}
+
 	b.WriteString("\n\n")
 
 	// Review summary
@@ -163,7 +257,11 @@ func (v *CommitConfirmView) View() string {
 	b.WriteString("\n")
 
 	// Help (only show if not editing)
-	if !v.editing {
+	switch {
+	case v.editing, v.askingFeedback:
+	case v.canRegenerate && !v.regenerating:
+		b.WriteString(shared.HelpKeyStyle.Render(shared.CommitConfirmRegenerateHelp()))
+	default:
 		b.WriteString(shared.HelpKeyStyle.Render(shared.CommitConfirmHelp()))
 	}
 
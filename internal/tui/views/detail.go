@@ -18,6 +18,10 @@ type IssueDetailModal struct {
 	mode     review.Mode
 	viewport viewport.Model
 	ready    bool
+	// suggestion is set when the modal shows a suggestion, held in issue.Description
+	suggestion bool
+	// compact renders the modal at the top left with a shorter viewport
+	compact bool
 }
 
 // NewIssueDetailModal creates a new issue detail modal
@@ -29,9 +33,29 @@ func NewIssueDetailModal() *IssueDetailModal {
 func (v *IssueDetailModal) SetIssue(issue *review.Issue, mode review.Mode) {
 	v.issue = issue
 	v.mode = mode
+	v.suggestion = false
 	v.ready = false
 }
 
+// SetSuggestion sets a review suggestion to display
+func (v *IssueDetailModal) SetSuggestion(suggestion string, mode review.Mode) {
+	v.issue = &review.Issue{Description: suggestion}
+	v.mode = mode
+	v.suggestion = true
+	v.ready = false
+}
+
+// IsSuggestion returns true if the modal shows a suggestion rather than an issue
+func (v *IssueDetailModal) IsSuggestion() bool {
+	return v.suggestion
+}
+
+// SetCompact switches to the compact layout used when rendering inline: the
+// modal is not centered and shows fewer lines at a time.
+func (v *IssueDetailModal) SetCompact(compact bool) {
+	v.compact = compact
+}
+
 // SetSize updates the modal dimensions
 func (v *IssueDetailModal) SetSize(width, height int) {
 	v.width = width
@@ -40,6 +64,9 @@ func (v *IssueDetailModal) SetSize(width, height int) {
 	// Modal is 80% of screen, capped at reasonable max
 	modalWidth := min(width*80/100, 70)
 	modalHeight := min(height*80/100, 25)
+	if v.compact {
+		modalHeight = min(modalHeight, 16)
+	}
 
 	if !v.ready {
 		v.viewport = viewport.New(modalWidth-4, modalHeight-8)
@@ -87,6 +114,9 @@ func (v *IssueDetailModal) View() string {
 	// Title
 	info := review.GetModeInfo(v.mode)
 	title := fmt.Sprintf("%s Issue", info.Name)
+	if v.suggestion {
+		title = fmt.Sprintf("%s Suggestion", info.Name)
+	}
 	b.WriteString(shared.ModalTitleStyle.Render(title))
 	b.WriteString("\n")
 	b.WriteString(shared.RenderDivider(modalWidth - 4))
@@ -102,7 +132,11 @@ func (v *IssueDetailModal) View() string {
 	b.WriteString("\n")
 
 	// Help
-	b.WriteString(shared.HelpKeyStyle.Render(shared.IssueDetailHelp(v.HasFix())))
+	if v.suggestion {
+		b.WriteString(shared.HelpKeyStyle.Render(shared.SuggestionDetailHelp()))
+	} else {
+		b.WriteString(shared.HelpKeyStyle.Render(shared.IssueDetailHelp(v.HasFix())))
+	}
 
 	// Wrap in modal box
 	content := b.String()
@@ -118,6 +152,14 @@ func (v *IssueDetailModal) View() string {
 func (v *IssueDetailModal) renderContent() string {
 	var b strings.Builder
 
+	if v.suggestion {
+		b.WriteString(shared.HeaderStyle.Render("Suggestion:"))
+		b.WriteString("\n")
+		b.WriteString(wordWrap(v.issue.Description, 60))
+		b.WriteString("\n")
+		return b.String()
+	}
+
 	// Location
 	b.WriteString(shared.HeaderStyle.Render("Location: "))
 	if v.issue.Location != "" {
@@ -126,12 +168,55 @@ func (v *IssueDetailModal) renderContent() string {
 		b.WriteString("-")
 	}
 	b.WriteString("\n")
+	if v.issue.URL != "" {
+		b.WriteString(shared.HeaderStyle.Render("Link:     "))
+		b.WriteString(shared.HelpDescStyle.Render(v.issue.URL))
+		b.WriteString("\n")
+	}
+	if v.issue.Author != "" {
+		b.WriteString(shared.HeaderStyle.Render("Author:   "))
+		b.WriteString(v.issue.Author)
+		b.WriteString("\n")
+	}
 
 	// Severity
 	b.WriteString(shared.HeaderStyle.Render("Severity: "))
 	sevStyle := shared.SeverityStyle(v.issue.Severity)
-	b.WriteString(sevStyle.Render(strings.ToUpper(v.issue.Severity)))
-	b.WriteString("\n\n")
+	b.WriteString(sevStyle.Render(shared.SeverityLabel(v.issue.Severity)))
+	if v.issue.SeverityUnknown {
+		b.WriteString(shared.HelpDescStyle.Render(fmt.Sprintf(" (reported as %q, not recognized)", v.issue.RawSeverity)))
+	} else if v.issue.RawSeverity != "" {
+		b.WriteString(shared.HelpDescStyle.Render(fmt.Sprintf(" (reported as %q)", v.issue.RawSeverity)))
+	}
+	if v.issue.TriagedFrom != "" {
+		b.WriteString(shared.HelpDescStyle.Render(fmt.Sprintf(" (triaged from %s)", shared.SeverityLabel(v.issue.TriagedFrom))))
+	}
+	if v.issue.Baseline {
+		b.WriteString(shared.HelpDescStyle.Render(" (in the baseline, does not block)"))
+	}
+	if confidence := v.issue.ConfidenceLabel(); confidence != "" {
+		note := fmt.Sprintf(" (%s confidence)", confidence)
+		if v.issue.Speculative() {
+			note = fmt.Sprintf(" (speculative, %s confidence)", confidence)
+		}
+		b.WriteString(shared.HelpDescStyle.Render(note))
+	}
+	b.WriteString("\n")
+
+	// Other modes that reported the same issue
+	if also := v.issue.AlsoReportedByNames(); also != "" {
+		b.WriteString(shared.HeaderStyle.Render("Also in:  "))
+		b.WriteString(also)
+		b.WriteString("\n")
+	}
+
+	// Cross-check agreement
+	if agreement := shared.AgreementDescription(v.issue.Agreement); agreement != "" {
+		b.WriteString(shared.HeaderStyle.Render("Models:   "))
+		b.WriteString(agreement)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Description
 	b.WriteString(shared.HeaderStyle.Render("Description:"))
@@ -178,8 +263,11 @@ func (v *IssueDetailModal) renderContent() string {
 	return b.String()
 }
 
-// centerModal centers the modal in the terminal
+// centerModal centers the modal in the terminal, unless the layout is compact
 func (v *IssueDetailModal) centerModal(modal string) string {
+	if v.compact {
+		return modal
+	}
 	lines := strings.Split(modal, "\n")
 	modalHeight := len(lines)
 	modalWidth := 0
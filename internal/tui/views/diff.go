@@ -2,21 +2,42 @@ package views
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/buker/revi/internal/git/diff"
 	"github.com/buker/revi/internal/review"
 	"github.com/buker/revi/internal/tui/shared"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
+// sideBySideMinWidth is the narrowest terminal the side-by-side layout is
+// offered on, leaving each side room for a line of code
+const sideBySideMinWidth = 120
+
 // DiffPreviewModal displays a diff preview for a fix
 type DiffPreviewModal struct {
 	width    int
 	height   int
 	fix      *review.Fix
+	preview  string // Unified diff hunk with surrounding context, if available
 	viewport viewport.Model
 	ready    bool
+	compact  bool      // Render at the top left with a shorter viewport
+	lang     *language // Language of the fixed file, for syntax highlighting
+	// sideBySide shows the code before and after the fix in two columns, on
+	// terminals wide enough for it
+	sideBySide bool
+
+	// Hunk picking, for fixes that change several places
+	hunks      []string // Each change of the fix as a unified diff hunk
+	accepted   []bool   // Whether each hunk is applied
+	hunkCursor int      // Hunk selected while picking
+	picking    bool     // Showing the hunks to accept or reject one by one
 }
 
 // NewDiffPreviewModal creates a new diff preview modal
@@ -27,7 +48,105 @@ func NewDiffPreviewModal() *DiffPreviewModal {
 // SetFix sets the fix to preview
 func (v *DiffPreviewModal) SetFix(fix *review.Fix) {
 	v.fix = fix
+	v.lang = nil
+	if fix != nil {
+		v.lang = languageFor(fix.FilePath)
+	}
+	v.preview = ""
 	v.ready = false
+	v.SetHunks(nil)
+}
+
+// SetPreview sets a unified diff hunk for the fix that includes surrounding
+// context. When empty, only the replacement code is shown.
+func (v *DiffPreviewModal) SetPreview(preview string) {
+	v.preview = preview
+}
+
+// SetHunks sets the changes of the fix as unified diff hunks, which can be
+// accepted or rejected one by one when there are several. All start accepted.
+func (v *DiffPreviewModal) SetHunks(hunks []string) {
+	v.hunks = hunks
+	v.accepted = make([]bool, len(hunks))
+	for i := range v.accepted {
+		v.accepted[i] = true
+	}
+	v.hunkCursor = 0
+	v.picking = false
+}
+
+// CanPickHunks reports whether the fix has several hunks to pick from
+func (v *DiffPreviewModal) CanPickHunks() bool {
+	return len(v.hunks) > 1
+}
+
+// StartPicking shows the fix hunk by hunk so that each can be accepted or
+// rejected. Does nothing unless CanPickHunks.
+func (v *DiffPreviewModal) StartPicking() {
+	if !v.CanPickHunks() {
+		return
+	}
+	v.picking = true
+	v.refresh()
+}
+
+// Picking reports whether hunks are being picked
+func (v *DiffPreviewModal) Picking() bool {
+	return v.picking
+}
+
+// MoveHunk moves the hunk selection by delta, staying within the hunks
+func (v *DiffPreviewModal) MoveHunk(delta int) {
+	v.hunkCursor = max(0, min(v.hunkCursor+delta, len(v.hunks)-1))
+	v.refresh()
+}
+
+// ToggleHunk accepts the selected hunk if it was rejected and the other way round
+func (v *DiffPreviewModal) ToggleHunk() {
+	if v.hunkCursor < len(v.accepted) {
+		v.accepted[v.hunkCursor] = !v.accepted[v.hunkCursor]
+		v.refresh()
+	}
+}
+
+// Accepted reports, for each hunk, whether it is applied
+func (v *DiffPreviewModal) Accepted() []bool {
+	return v.accepted
+}
+
+// CanSideBySide reports whether the fix can be shown side by side: the
+// terminal is wide enough and the code before the fix is known
+func (v *DiffPreviewModal) CanSideBySide() bool {
+	return v.width >= sideBySideMinWidth && (v.preview != "" || len(v.hunks) > 0)
+}
+
+// SideBySide reports whether the fix is shown side by side
+func (v *DiffPreviewModal) SideBySide() bool {
+	return v.sideBySide && v.CanSideBySide()
+}
+
+// ToggleSideBySide switches between the unified and the side-by-side layout.
+// The choice is kept for later fixes. Does nothing unless CanSideBySide.
+func (v *DiffPreviewModal) ToggleSideBySide() {
+	if !v.CanSideBySide() {
+		return
+	}
+	v.sideBySide = !v.sideBySide
+	v.SetSize(v.width, v.height)
+}
+
+// modalWidth returns the width of the modal, which is wider side by side
+func (v *DiffPreviewModal) modalWidth() int {
+	if v.SideBySide() {
+		return min(v.width*90/100, 160)
+	}
+	return min(v.width*80/100, 80)
+}
+
+// SetCompact switches to the compact layout used when rendering inline: the
+// modal is not centered and shows fewer lines at a time.
+func (v *DiffPreviewModal) SetCompact(compact bool) {
+	v.compact = compact
 }
 
 // SetSize updates the modal dimensions
@@ -36,8 +155,11 @@ func (v *DiffPreviewModal) SetSize(width, height int) {
 	v.height = height
 
 	// Modal is 80% of screen, capped at reasonable max
-	modalWidth := min(width*80/100, 80)
+	modalWidth := v.modalWidth()
 	modalHeight := min(height*80/100, 30)
+	if v.compact {
+		modalHeight = min(modalHeight, 16)
+	}
 
 	if !v.ready {
 		v.viewport = viewport.New(modalWidth-4, modalHeight-6)
@@ -47,9 +169,23 @@ func (v *DiffPreviewModal) SetSize(width, height int) {
 		v.viewport.Height = modalHeight - 6
 	}
 
-	// Update content
-	if v.fix != nil {
+	v.refresh()
+}
+
+// refresh renders the content into the viewport, scrolled to the selected
+// hunk while picking
+func (v *DiffPreviewModal) refresh() {
+	if !v.ready || v.fix == nil {
+		return
+	}
+	if !v.picking {
 		v.viewport.SetContent(v.renderDiff())
+		return
+	}
+	content, offset := v.renderHunks()
+	v.viewport.SetContent(content)
+	if offset < v.viewport.YOffset || offset >= v.viewport.YOffset+v.viewport.Height {
+		v.viewport.SetYOffset(offset)
 	}
 }
 
@@ -73,7 +209,7 @@ func (v *DiffPreviewModal) View() string {
 		return ""
 	}
 
-	modalWidth := min(v.width*80/100, 80)
+	modalWidth := v.modalWidth()
 
 	var b strings.Builder
 
@@ -94,7 +230,19 @@ func (v *DiffPreviewModal) View() string {
 	b.WriteString("\n")
 
 	// Help
-	b.WriteString(shared.HelpKeyStyle.Render(shared.DiffPreviewHelp()))
+	var help string
+	switch {
+	case v.picking:
+		help = shared.HunkPickHelp()
+	case v.CanPickHunks():
+		help = shared.DiffPreviewHelp() + shared.PickHunksHelp()
+	default:
+		help = shared.DiffPreviewHelp()
+	}
+	if v.CanSideBySide() {
+		help += shared.SideBySideHelp(v.SideBySide())
+	}
+	b.WriteString(shared.HelpKeyStyle.Render(help))
 
 	// Wrap in modal box
 	content := b.String()
@@ -112,6 +260,10 @@ func (v *DiffPreviewModal) renderDiff() string {
 		return "No diff available"
 	}
 
+	if v.preview != "" {
+		return v.renderPreview()
+	}
+
 	var b strings.Builder
 
 	// Show hunk header
@@ -126,8 +278,7 @@ func (v *DiffPreviewModal) renderDiff() string {
 	// Show the replacement code with + prefix
 	lines := strings.Split(v.fix.Code, "\n")
 	for _, line := range lines {
-		styledLine := shared.DiffAddedStyle.Render("+ " + line)
-		b.WriteString(styledLine)
+		b.WriteString(v.renderDiffLine("+" + line))
 		b.WriteString("\n")
 	}
 
@@ -140,8 +291,164 @@ func (v *DiffPreviewModal) renderDiff() string {
 	return b.String()
 }
 
-// centerModal centers the modal in the terminal
+// renderPreview renders the unified diff preview with context lines
+func (v *DiffPreviewModal) renderPreview() string {
+	var b strings.Builder
+	for _, line := range v.renderHunkLines(strings.Split(strings.TrimRight(v.preview, "\n"), "\n")) {
+		b.WriteString(line)
+		if strings.HasPrefix(ansi.Strip(line), "@@") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderHunkLines renders the lines of a unified diff hunk in the current
+// layout, numbered as in the file before and after the fix. Side by side, a
+// run of removed lines is shown next to the added lines that follow it, and
+// may take fewer or more rows than the hunk has lines.
+func (v *DiffPreviewModal) renderHunkLines(lines []string) []string {
+	// Line numbers of the lines after the header, and the width they need
+	var before, after []int
+	if h := diff.ParseHunk(strings.Join(lines, "\n")); h != nil {
+		before, after = h.LineNumbers()
+		before, after = append([]int{0}, before...), append([]int{0}, after...)
+	} else {
+		before, after = make([]int, len(lines)), make([]int, len(lines))
+	}
+	digits := len(strconv.Itoa(max(slices.Max(append(before, 0)), slices.Max(append(after, 0)))))
+
+	if !v.SideBySide() {
+		out := make([]string, len(lines))
+		for i, line := range lines {
+			out[i] = v.renderDiffLine(line)
+			if !strings.HasPrefix(line, "@@") {
+				out[i] = lineGutter(before[i], digits) + lineGutter(after[i], digits) + out[i]
+			}
+		}
+		return out
+	}
+
+	// Two columns separated by " │ " within the viewport
+	width := (v.viewport.Width - 3) / 2
+	var out []string
+	var removed, added []int // Indexes of the lines in the current run
+	flush := func() {
+		for i := range max(len(removed), len(added)) {
+			left, right := strings.Repeat(" ", width), strings.Repeat(" ", width)
+			if i < len(removed) {
+				j := removed[i]
+				left = v.renderCell(lineGutter(before[j], digits), "-", lines[j][1:], width, shared.DiffRemovedStyle)
+			}
+			if i < len(added) {
+				j := added[i]
+				right = v.renderCell(lineGutter(after[j], digits), "+", lines[j][1:], width, shared.DiffAddedStyle)
+			}
+			out = append(out, left+shared.DividerStyle.Render(" │ ")+right)
+		}
+		removed, added = nil, nil
+	}
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			flush()
+			out = append(out, shared.DiffHunkStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			// Removed lines after added ones start a new run
+			if len(added) > 0 {
+				flush()
+			}
+			removed = append(removed, i)
+		case strings.HasPrefix(line, "+"):
+			added = append(added, i)
+		default:
+			flush()
+			code := strings.TrimPrefix(line, " ")
+			left := v.renderCell(lineGutter(before[i], digits), " ", code, width, shared.DiffContextStyle)
+			right := v.renderCell(lineGutter(after[i], digits), " ", code, width, shared.DiffContextStyle)
+			out = append(out, left+shared.DividerStyle.Render(" │ ")+right)
+		}
+	}
+	flush()
+	return out
+}
+
+// lineGutter renders line number n right-aligned in digits columns and
+// followed by a space, or blank columns for 0
+func lineGutter(n, digits int) string {
+	text := ""
+	if n > 0 {
+		text = strconv.Itoa(n)
+	}
+	return shared.LineNumberStyle.Render(fmt.Sprintf("%*s ", digits, text))
+}
+
+// renderCell renders one side of a side-by-side row: the line number gutter,
+// the marker and the highlighted code, cut to width and padded to it
+func (v *DiffPreviewModal) renderCell(gutter, marker, code string, width int, base lipgloss.Style) string {
+	prefixWidth := ansi.StringWidth(gutter) + len(marker) + 1
+	code = ansi.Truncate(strings.ReplaceAll(code, "\t", "    "), max(width-prefixWidth, 0), "…")
+	cell := gutter + base.Render(marker+" ") + highlightCode(v.lang, code, base)
+	return cell + strings.Repeat(" ", max(width-ansi.StringWidth(cell), 0))
+}
+
+// renderDiffLine styles one line of a unified diff hunk, highlighting the
+// syntax of its code
+func (v *DiffPreviewModal) renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "@@"):
+		return shared.DiffHunkStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return shared.DiffAddedStyle.Render("+ ") + highlightCode(v.lang, line[1:], shared.DiffAddedStyle)
+	case strings.HasPrefix(line, "-"):
+		return shared.DiffRemovedStyle.Render("- ") + highlightCode(v.lang, line[1:], shared.DiffRemovedStyle)
+	default:
+		return shared.DiffContextStyle.Render("  ") + highlightCode(v.lang, strings.TrimPrefix(line, " "), shared.DiffContextStyle)
+	}
+}
+
+// renderHunks renders every hunk of the fix with whether it is accepted,
+// marking the selected one, and returns the line the selected hunk starts at
+func (v *DiffPreviewModal) renderHunks() (string, int) {
+	var b strings.Builder
+	offset, line := 0, 0
+	for i, hunk := range v.hunks {
+		if i == v.hunkCursor {
+			offset = line
+		}
+		cursor, mark := "  ", "[ ] rejected"
+		if i == v.hunkCursor {
+			cursor = "> "
+		}
+		if v.accepted[i] {
+			mark = "[x] accepted"
+		}
+		header := fmt.Sprintf("%sHunk %d/%d %s", cursor, i+1, len(v.hunks), mark)
+		if i == v.hunkCursor {
+			b.WriteString(shared.SelectedRowStyle.Render(header))
+		} else {
+			b.WriteString(shared.HelpDescStyle.Render(header))
+		}
+		b.WriteString("\n")
+		line++
+
+		for _, l := range v.renderHunkLines(strings.Split(strings.TrimRight(hunk, "\n"), "\n")) {
+			b.WriteString(l)
+			b.WriteString("\n")
+			line++
+		}
+		b.WriteString("\n")
+		line++
+	}
+	return b.String(), offset
+}
+
+// centerModal centers the modal in the terminal, unless the layout is compact
 func (v *DiffPreviewModal) centerModal(modal string) string {
+	if v.compact {
+		return modal
+	}
 	lines := strings.Split(modal, "\n")
 	modalHeight := len(lines)
 	modalWidth := 0
@@ -0,0 +1,87 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// =============================================================================
+// Tests for the side-by-side diff preview
+// =============================================================================
+
+func newSideBySideModal(width int) *DiffPreviewModal {
+	modal := NewDiffPreviewModal()
+	modal.SetFix(&review.Fix{FilePath: "main.go", Code: "return nil"})
+	modal.SetPreview("@@ -1,3 +1,3 @@\n func run() error {\n-\treturn err\n+\treturn nil\n }\n")
+	modal.SetSize(width, 40)
+	return modal
+}
+
+func TestDiffPreviewModal_SideBySide_NeedsWideTerminal(t *testing.T) {
+	modal := newSideBySideModal(100)
+	if modal.CanSideBySide() {
+		t.Error("CanSideBySide() = true on a 100 column terminal")
+	}
+	modal.ToggleSideBySide()
+	if modal.SideBySide() {
+		t.Error("SideBySide() = true after toggling on a narrow terminal")
+	}
+
+	modal = newSideBySideModal(160)
+	if !modal.CanSideBySide() {
+		t.Error("CanSideBySide() = false on a 160 column terminal")
+	}
+}
+
+func TestDiffPreviewModal_SideBySide_PairsRemovedAndAddedLines(t *testing.T) {
+	modal := newSideBySideModal(160)
+	modal.ToggleSideBySide()
+	if !modal.SideBySide() {
+		t.Fatal("SideBySide() = false after toggling")
+	}
+
+	var rows []string
+	for _, line := range strings.Split(ansi.Strip(modal.renderPreview()), "\n") {
+		if strings.Contains(line, "│") {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d side-by-side rows, want 3:\n%s", len(rows), strings.Join(rows, "\n"))
+	}
+	left, right, _ := strings.Cut(rows[1], " │ ")
+	if !strings.Contains(left, "- ") || !strings.Contains(left, "return err") {
+		t.Errorf("left side = %q, want the removed line", left)
+	}
+	if !strings.Contains(right, "+ ") || !strings.Contains(right, "return nil") {
+		t.Errorf("right side = %q, want the added line", right)
+	}
+	if ansi.StringWidth(left) != ansi.StringWidth(strings.Split(rows[0], " │ ")[0]) {
+		t.Errorf("left sides of rows differ in width:\n%s", strings.Join(rows, "\n"))
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(left), "2 -") || !strings.HasPrefix(strings.TrimSpace(right), "2 +") {
+		t.Errorf("row = %q, want line 2 on both sides", rows[1])
+	}
+
+	modal.ToggleSideBySide()
+	if strings.Contains(ansi.Strip(modal.renderPreview()), "│") {
+		t.Error("renderPreview() still side by side after toggling back")
+	}
+}
+
+func TestDiffPreviewModal_NumbersPreviewLines(t *testing.T) {
+	modal := newSideBySideModal(100)
+
+	lines := strings.Split(ansi.Strip(modal.renderPreview()), "\n")
+	want := []string{"1 1   func run() error {", "2   -     return err", "  2 +     return nil", "3 3   }"}
+	got := strings.Join(lines, "\n")
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("preview has no line %q:\n%s", w, got)
+		}
+	}
+}
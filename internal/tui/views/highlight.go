@@ -0,0 +1,187 @@
+package views
+
+import (
+	"path"
+	"strings"
+
+	"github.com/buker/revi/internal/tui/shared"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// language holds what highlightCode needs to find the tokens of a language.
+// Lines are highlighted one at a time, so comments and strings spanning
+// several lines are only recognized on the line they start.
+type language struct {
+	keywords    map[string]bool
+	comments    []string // Prefixes starting a comment that runs to the end of the line
+	quotes      string   // Characters opening and closing a string
+	blockStarts []string // Prefixes starting a block comment, highlighted to its end on the same line
+}
+
+// words returns a set of the space-separated words in s
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	goLanguage = &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var nil true false iota`),
+		comments:    []string{"//"},
+		quotes:      "\"'`",
+		blockStarts: []string{"/*"},
+	}
+	cLikeLanguage = &language{
+		keywords: words(`abstract as async await break case catch class const continue default delete do else
+			enum export extends false final finally fn for function if impl implements import in instanceof
+			interface let match mod mut new null private protected pub public return self static struct super
+			switch this throw throws trait true try type typeof use var void while yield`),
+		comments:    []string{"//"},
+		quotes:      "\"'`",
+		blockStarts: []string{"/*"},
+	}
+	pythonLanguage = &language{
+		keywords: words(`and as assert async await break class continue def del elif else except False finally
+			for from global if import in is lambda None nonlocal not or pass raise return True try while with yield`),
+		comments: []string{"#"},
+		quotes:   "\"'",
+	}
+	shellLanguage = &language{
+		keywords: words(`case do done elif else esac export fi for function if in local return then until while`),
+		comments: []string{"#"},
+		quotes:   "\"'",
+	}
+	rubyLanguage = &language{
+		keywords: words(`begin class def do else elsif end ensure false if module nil require rescue return
+			self then true unless until when while yield`),
+		comments: []string{"#"},
+		quotes:   "\"'",
+	}
+)
+
+// languages maps file extensions to their language
+var languages = map[string]*language{
+	".go":    goLanguage,
+	".c":     cLikeLanguage,
+	".h":     cLikeLanguage,
+	".cc":    cLikeLanguage,
+	".cpp":   cLikeLanguage,
+	".hpp":   cLikeLanguage,
+	".cs":    cLikeLanguage,
+	".java":  cLikeLanguage,
+	".kt":    cLikeLanguage,
+	".js":    cLikeLanguage,
+	".jsx":   cLikeLanguage,
+	".mjs":   cLikeLanguage,
+	".ts":    cLikeLanguage,
+	".tsx":   cLikeLanguage,
+	".rs":    cLikeLanguage,
+	".swift": cLikeLanguage,
+	".py":    pythonLanguage,
+	".sh":    shellLanguage,
+	".bash":  shellLanguage,
+	".rb":    rubyLanguage,
+}
+
+// languageFor returns the language of the file at p, or nil if it is not one
+// highlightCode knows
+func languageFor(p string) *language {
+	return languages[strings.ToLower(path.Ext(p))]
+}
+
+// highlightCode renders a line of code with its keywords, strings, comments and
+// numbers styled, and the rest of it with base. Without a language the whole
+// line is rendered with base.
+func highlightCode(lang *language, line string, base lipgloss.Style) string {
+	if lang == nil || line == "" {
+		return base.Render(line)
+	}
+
+	var b strings.Builder
+	plain := 0 // Start of the text not yet written
+	flush := func(end int) {
+		if end > plain {
+			b.WriteString(base.Render(line[plain:end]))
+		}
+	}
+	token := func(start, end int, style lipgloss.Style) {
+		flush(start)
+		b.WriteString(style.Render(line[start:end]))
+		plain = end
+	}
+
+	for i := 0; i < len(line); {
+		rest := line[i:]
+		switch c := line[i]; {
+		case hasAnyPrefix(rest, lang.comments) || hasAnyPrefix(rest, lang.blockStarts):
+			end := len(line)
+			if hasAnyPrefix(rest, lang.blockStarts) {
+				if j := strings.Index(rest[2:], "*/"); j >= 0 {
+					end = i + 2 + j + 2
+				}
+			}
+			token(i, end, shared.SyntaxCommentStyle)
+			i = end
+		case strings.IndexByte(lang.quotes, c) >= 0:
+			end := stringEnd(line, i)
+			token(i, end, shared.SyntaxStringStyle)
+			i = end
+		case c >= '0' && c <= '9' && (i == 0 || !isWordByte(line[i-1])):
+			end := i
+			for end < len(line) && (isWordByte(line[end]) || line[end] == '.') {
+				end++
+			}
+			token(i, end, shared.SyntaxNumberStyle)
+			i = end
+		case isWordByte(c) && (i == 0 || !isWordByte(line[i-1])):
+			end := i
+			for end < len(line) && isWordByte(line[end]) {
+				end++
+			}
+			if lang.keywords[line[i:end]] {
+				token(i, end, shared.SyntaxKeywordStyle)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	flush(len(line))
+	return b.String()
+}
+
+// stringEnd returns the end of the string opened by the quote at start: just
+// past the matching quote, or the end of the line if it is not closed there
+func stringEnd(line string, start int) int {
+	quote := line[start]
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(line)
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWordByte reports whether c can be part of an identifier or keyword
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,65 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// =============================================================================
+// Tests for syntax highlighting
+// =============================================================================
+
+func TestLanguageFor(t *testing.T) {
+	tests := []struct {
+		path string
+		want *language
+	}{
+		{"internal/cli/review.go", goLanguage},
+		{"web/App.TSX", cLikeLanguage},
+		{"scripts/release.sh", shellLanguage},
+		{"tools/gen.py", pythonLanguage},
+		{"README.md", nil},
+		{"Makefile", nil},
+	}
+	for _, tt := range tests {
+		if got := languageFor(tt.path); got != tt.want {
+			t.Errorf("languageFor(%q) = %p, want %p", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestStringEnd(t *testing.T) {
+	tests := []struct {
+		line  string
+		start int
+		want  int
+	}{
+		{`x := "abc" + y`, 5, 10},
+		{`x := "a\"b" + y`, 5, 11},
+		{"x := `a\\` + y", 5, 9},
+		{`x := "unclosed`, 5, 14},
+	}
+	for _, tt := range tests {
+		if got := stringEnd(tt.line, tt.start); got != tt.want {
+			t.Errorf("stringEnd(%q, %d) = %d, want %d", tt.line, tt.start, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightCode_KeepsText(t *testing.T) {
+	lines := []string{
+		`func main() { return 42 } // done`,
+		`s := "if else" + x /* for */ + 0x1F`,
+		`return_value := iffy`,
+		"",
+	}
+	for _, line := range lines {
+		for _, lang := range []*language{goLanguage, pythonLanguage, nil} {
+			if got := ansi.Strip(highlightCode(lang, line, lipgloss.NewStyle())); got != line {
+				t.Errorf("highlightCode(%q) = %q, want the same text", line, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buker/revi/internal/tui/shared"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HunkItem is a hunk of a changed file shown in the hunk staging view
+type HunkItem struct {
+	Path   string
+	Header string   // The hunk's "@@ -a,b +c,d @@" line
+	Lines  []string // The hunk's lines, each with its '+' or '-' prefix
+	Staged bool
+}
+
+// HunkStagingView lists the hunks of the changed files and lets each be
+// staged or unstaged, to choose what is reviewed and committed at a finer
+// grain than whole files
+type HunkStagingView struct {
+	width    int
+	height   int
+	hunks    []HunkItem
+	cursor   int
+	keys     shared.KeyMap
+	viewport viewport.Model
+	ready    bool
+}
+
+// NewHunkStagingView creates a hunk staging view showing hunks
+func NewHunkStagingView(hunks []HunkItem) *HunkStagingView {
+	return &HunkStagingView{
+		hunks: hunks,
+		keys:  shared.DefaultKeyMap(),
+	}
+}
+
+// SetSize updates the view dimensions
+func (v *HunkStagingView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+
+	// Leave room for the title and help
+	vpHeight := max(height-6, 3)
+	if !v.ready {
+		v.viewport = viewport.New(width, vpHeight)
+		v.ready = true
+	} else {
+		v.viewport.Width = width
+		v.viewport.Height = vpHeight
+	}
+	v.refresh()
+}
+
+// Hunks returns the hunks with whether each is staged
+func (v *HunkStagingView) Hunks() []HunkItem {
+	return v.hunks
+}
+
+// Cursor returns the index of the selected hunk
+func (v *HunkStagingView) Cursor() int {
+	return v.cursor
+}
+
+// Toggle stages the selected hunk if it is not, and unstages it otherwise
+func (v *HunkStagingView) Toggle() {
+	if v.cursor < len(v.hunks) {
+		v.hunks[v.cursor].Staged = !v.hunks[v.cursor].Staged
+		v.refresh()
+	}
+}
+
+// StagedCount returns how many hunks are staged
+func (v *HunkStagingView) StagedCount() int {
+	n := 0
+	for _, h := range v.hunks {
+		if h.Staged {
+			n++
+		}
+	}
+	return n
+}
+
+// Init initializes the view
+func (v *HunkStagingView) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles key messages for moving between hunks and toggling them
+func (v *HunkStagingView) Update(msg tea.Msg) (*HunkStagingView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, v.keys.Up):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case key.Matches(msg, v.keys.Down):
+			if v.cursor < len(v.hunks)-1 {
+				v.cursor++
+			}
+		case key.Matches(msg, v.keys.Home):
+			v.cursor = 0
+		case key.Matches(msg, v.keys.End):
+			v.cursor = max(len(v.hunks)-1, 0)
+		case key.Matches(msg, v.keys.ToggleHunk):
+			v.Toggle()
+			return v, nil
+		default:
+			return v, nil
+		}
+		v.refresh()
+	}
+	return v, nil
+}
+
+// View renders the hunk staging view
+func (v *HunkStagingView) View() string {
+	var b strings.Builder
+
+	b.WriteString(shared.TitleStyle.Render("revi - Stage Hunks"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf(" %d of %d hunk(s) staged\n", v.StagedCount(), len(v.hunks)))
+	b.WriteString(shared.RenderDivider(54))
+	b.WriteString("\n")
+
+	if len(v.hunks) == 0 {
+		b.WriteString(" No changes to tracked files\n")
+	} else if v.ready {
+		b.WriteString(v.viewport.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString(shared.RenderDivider(54))
+	b.WriteString("\n")
+	b.WriteString(shared.HelpKeyStyle.Render(shared.HunkStagingHelp()))
+	return b.String()
+}
+
+// refresh renders the hunks into the viewport, scrolled to the selected one
+func (v *HunkStagingView) refresh() {
+	if !v.ready {
+		return
+	}
+	content, offset := v.renderHunks()
+	v.viewport.SetContent(content)
+	if offset < v.viewport.YOffset || offset >= v.viewport.YOffset+v.viewport.Height {
+		v.viewport.SetYOffset(offset)
+	}
+}
+
+// renderHunks renders every hunk under the path of its file with whether it
+// is staged, marking the selected one, and returns the line the selected hunk
+// starts at
+func (v *HunkStagingView) renderHunks() (string, int) {
+	var b strings.Builder
+	offset, line := 0, 0
+	for i, h := range v.hunks {
+		if i == 0 || h.Path != v.hunks[i-1].Path {
+			b.WriteString(shared.HeaderStyle.Render(h.Path))
+			b.WriteString("\n")
+			line++
+		}
+		if i == v.cursor {
+			offset = line
+		}
+
+		cursor, mark := "  ", "[ ]"
+		if i == v.cursor {
+			cursor = "> "
+		}
+		if h.Staged {
+			mark = "[x]"
+		}
+		header := fmt.Sprintf("%s%s %s", cursor, mark, h.Header)
+		if i == v.cursor {
+			b.WriteString(shared.SelectedRowStyle.Render(header))
+		} else {
+			b.WriteString(shared.DiffHunkStyle.Render(header))
+		}
+		b.WriteString("\n")
+		line++
+
+		for _, l := range h.Lines {
+			switch {
+			case strings.HasPrefix(l, "+"):
+				b.WriteString("    " + shared.DiffAddedStyle.Render(l))
+			case strings.HasPrefix(l, "-"):
+				b.WriteString("    " + shared.DiffRemovedStyle.Render(l))
+			default:
+				b.WriteString("    " + shared.DiffContextStyle.Render(l))
+			}
+			b.WriteString("\n")
+			line++
+		}
+	}
+	return b.String(), offset
+}
@@ -0,0 +1,80 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// =============================================================================
+// Tests for HunkStagingView
+// =============================================================================
+
+func testHunks() []HunkItem {
+	return []HunkItem{
+		{Path: "main.go", Header: "@@ -3,1 +3,1 @@", Lines: []string{"-old", "+new"}, Staged: true},
+		{Path: "main.go", Header: "@@ -9,0 +10,1 @@", Lines: []string{"+added"}},
+		{Path: "util.go", Header: "@@ -1,1 +1,0 @@", Lines: []string{"-removed"}},
+	}
+}
+
+func TestHunkStagingView_ToggleSelectedHunk(t *testing.T) {
+	view := NewHunkStagingView(testHunks())
+	view.SetSize(80, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	view.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if view.Cursor() != 1 || !view.Hunks()[1].Staged {
+		t.Errorf("cursor = %d, staged = %v, want the second hunk staged", view.Cursor(), view.Hunks()[1].Staged)
+	}
+	if view.StagedCount() != 2 {
+		t.Errorf("StagedCount() = %d, want 2", view.StagedCount())
+	}
+
+	view.Update(tea.KeyMsg{Type: tea.KeyUp})
+	view.Toggle()
+	if view.Hunks()[0].Staged {
+		t.Error("toggling a staged hunk should unstage it")
+	}
+}
+
+func TestHunkStagingView_CursorStaysInRange(t *testing.T) {
+	view := NewHunkStagingView(testHunks())
+	view.SetSize(80, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if view.Cursor() != 0 {
+		t.Errorf("cursor = %d after up on the first hunk, want 0", view.Cursor())
+	}
+	view.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if view.Cursor() != 2 {
+		t.Errorf("cursor = %d after down on the last hunk, want 2", view.Cursor())
+	}
+}
+
+func TestHunkStagingView_View(t *testing.T) {
+	view := NewHunkStagingView(testHunks())
+	view.SetSize(80, 30)
+
+	out := view.View()
+	for _, want := range []string{"Stage Hunks", "1 of 3 hunk(s) staged", "main.go", "> [x] @@ -3,1 +3,1 @@", "[ ] @@ -9,0 +10,1 @@", "+added", "util.go"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("View() should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "main.go") != 1 {
+		t.Errorf("each file's path should be shown once:\n%s", out)
+	}
+}
+
+func TestHunkStagingView_NoHunks(t *testing.T) {
+	view := NewHunkStagingView(nil)
+	view.SetSize(80, 30)
+
+	view.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !strings.Contains(view.View(), "No changes to tracked files") {
+		t.Errorf("View() should say there are no changes:\n%s", view.View())
+	}
+}
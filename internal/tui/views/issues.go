@@ -2,11 +2,14 @@ package views
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/buker/revi/internal/review"
+	"github.com/buker/revi/internal/triage"
 	"github.com/buker/revi/internal/tui/shared"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -15,6 +18,16 @@ type IssueItem struct {
 	Issue review.Issue
 	Mode  review.Mode
 	Fixed bool
+	// Rejected marks an issue whose fix was rolled back because verification failed
+	Rejected bool
+	// Suggestion marks a review suggestion, held in Issue.Description, that has
+	// not been promoted to an issue
+	Suggestion bool
+	Promoting  bool // Promotion to an issue is in progress
+	// WontFix marks an issue triaged as won't fix, which no longer blocks
+	WontFix bool
+	// Selected marks an issue chosen for a bulk action
+	Selected bool
 }
 
 // IssuesTableView displays a table of all issues
@@ -26,13 +39,27 @@ type IssuesTableView struct {
 	commitMessage string
 	blocked       bool
 	blockReason   string
+	notice        string
+	compact       bool
 	keys          shared.KeyMap
+	// filter is the active search; rows that do not match it are hidden.
+	// The cursor always stays on a shown row, or is -1 if none match.
+	filter    string
+	searching bool // The search prompt is open
+	search    textinput.Model
 }
 
+// compactRows is the number of table rows shown at once in the compact layout
+const compactRows = 8
+
 // NewIssuesTableView creates a new issues table view
 func NewIssuesTableView() *IssuesTableView {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "text, mode:, sev: or file:"
 	return &IssuesTableView{
-		keys: shared.DefaultKeyMap(),
+		keys:   shared.DefaultKeyMap(),
+		search: search,
 	}
 }
 
@@ -51,7 +78,21 @@ func (v *IssuesTableView) SetIssues(results []*review.Result) {
 			})
 		}
 	}
+	// Suggestions follow the issues so issue indices are unaffected
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, suggestion := range r.Suggestions {
+			v.issues = append(v.issues, IssueItem{
+				Issue:      review.Issue{Description: suggestion},
+				Mode:       r.Mode,
+				Suggestion: true,
+			})
+		}
+	}
 	v.cursor = 0
+	v.keepCursorShown()
 }
 
 // SetCommitMessage sets the commit message to display
@@ -69,7 +110,184 @@ func (v *IssuesTableView) SetBlocked(blocked bool, reason string) {
 func (v *IssuesTableView) MarkFixed(index int) {
 	if index >= 0 && index < len(v.issues) {
 		v.issues[index].Fixed = true
+		v.issues[index].Rejected = false
+	}
+}
+
+// MarkRejected marks an issue whose fix was rolled back by verification
+func (v *IssuesTableView) MarkRejected(index int) {
+	if index >= 0 && index < len(v.issues) {
+		v.issues[index].Rejected = true
+	}
+}
+
+// UnmarkFixed clears the fixed mark of an issue whose fix was undone
+func (v *IssuesTableView) UnmarkFixed(index int) {
+	if index >= 0 && index < len(v.issues) {
+		v.issues[index].Fixed = false
+	}
+}
+
+// SetSeverity gives the issue at index the severity chosen in triage
+func (v *IssuesTableView) SetSeverity(index int, severity string) {
+	if index >= 0 && index < len(v.issues) && !v.issues[index].Suggestion {
+		v.issues[index].Issue = triage.Adjust(v.issues[index].Issue, severity)
+		v.keepCursorShown()
+	}
+}
+
+// MarkWontFix marks the issue at index as triaged won't fix
+func (v *IssuesTableView) MarkWontFix(index int) {
+	if index >= 0 && index < len(v.issues) && !v.issues[index].Suggestion {
+		v.issues[index].WontFix = true
+	}
+}
+
+// ToggleSelected selects the issue at index for bulk actions, or deselects it
+func (v *IssuesTableView) ToggleSelected(index int) {
+	if index >= 0 && index < len(v.issues) && !v.issues[index].Suggestion {
+		v.issues[index].Selected = !v.issues[index].Selected
+	}
+}
+
+// ToggleSelectAll selects every shown issue, or deselects them if they are
+// all selected already
+func (v *IssuesTableView) ToggleSelectAll() {
+	all := true
+	for _, i := range v.shownRows() {
+		if !v.issues[i].Suggestion && !v.issues[i].Selected {
+			all = false
+		}
+	}
+	for _, i := range v.shownRows() {
+		if !v.issues[i].Suggestion {
+			v.issues[i].Selected = !all
+		}
+	}
+}
+
+// ClearSelection deselects every issue
+func (v *IssuesTableView) ClearSelection() {
+	for i := range v.issues {
+		v.issues[i].Selected = false
+	}
+}
+
+// Selected returns the indices of the selected issues
+func (v *IssuesTableView) Selected() []int {
+	var selected []int
+	for i, item := range v.issues {
+		if item.Selected {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// SetPromoting marks the suggestion at index as being promoted to an issue
+func (v *IssuesTableView) SetPromoting(index int, promoting bool) {
+	if index >= 0 && index < len(v.issues) && v.issues[index].Suggestion {
+		v.issues[index].Promoting = promoting
+	}
+}
+
+// Promote replaces the suggestion at index with the issue it was promoted to
+func (v *IssuesTableView) Promote(index int, issue review.Issue) {
+	if index >= 0 && index < len(v.issues) && v.issues[index].Suggestion {
+		v.issues[index] = IssueItem{Issue: issue, Mode: v.issues[index].Mode}
+		v.keepCursorShown()
+	}
+}
+
+// IsSearching returns true while the search prompt is open, when keys are
+// typed into the search instead of acting on the table
+func (v *IssuesTableView) IsSearching() bool {
+	return v.searching
+}
+
+// StartSearch opens the search prompt, starting from the active search
+func (v *IssuesTableView) StartSearch() tea.Cmd {
+	v.searching = true
+	v.search.SetValue(v.filter)
+	v.search.CursorEnd()
+	return v.search.Focus()
+}
+
+// Filter returns the active search, or "" if every row is shown
+func (v *IssuesTableView) Filter() string {
+	return v.filter
+}
+
+// SetFilter shows only the rows matching filter, or every row if filter is
+// empty. See matchesFilter for the syntax.
+func (v *IssuesTableView) SetFilter(filter string) {
+	v.filter = strings.TrimSpace(filter)
+	v.keepCursorShown()
+}
+
+// shownRows returns the indices of the rows that match the active search
+func (v *IssuesTableView) shownRows() []int {
+	rows := make([]int, 0, len(v.issues))
+	for i, item := range v.issues {
+		if matchesFilter(item, v.filter) {
+			rows = append(rows, i)
+		}
+	}
+	return rows
+}
+
+// keepCursorShown moves the cursor to the first shown row if the row under it
+// is hidden by the search, or to -1 if no row is shown
+func (v *IssuesTableView) keepCursorShown() {
+	rows := v.shownRows()
+	if slices.Contains(rows, v.cursor) {
+		return
+	}
+	v.cursor = 0
+	if len(rows) > 0 {
+		v.cursor = rows[0]
+	} else if len(v.issues) > 0 {
+		v.cursor = -1
+	}
+}
+
+// matchesFilter reports whether item matches every whitespace-separated term
+// of filter, ignoring case. "mode:", "sev:" (or "severity:") and "file:"
+// terms match the start of the mode, the start of the severity and any part
+// of the file path; other terms match any part of the mode, severity,
+// location or description.
+func matchesFilter(item IssueItem, filter string) bool {
+	mode := strings.ToLower(string(item.Mode))
+	severity := strings.ToLower(item.Issue.Severity)
+	file, _ := item.Issue.FileLine()
+	for _, term := range strings.Fields(strings.ToLower(filter)) {
+		field, value, ok := strings.Cut(term, ":")
+		if !ok {
+			field, value = "", term
+		}
+		var match bool
+		switch field {
+		case "mode":
+			match = strings.HasPrefix(mode, value) || strings.HasPrefix(strings.ToLower(review.GetModeInfo(item.Mode).Name), value)
+		case "sev", "severity":
+			match = !item.Suggestion && strings.HasPrefix(severity, value)
+		case "file":
+			match = strings.Contains(strings.ToLower(file), value)
+		default:
+			match = strings.Contains(mode, term) || strings.Contains(severity, term) ||
+				strings.Contains(strings.ToLower(item.Issue.Location), term) ||
+				strings.Contains(strings.ToLower(item.Issue.Description), term)
+		}
+		if !match {
+			return false
+		}
 	}
+	return true
+}
+
+// SetNotice sets a one-line message shown below the table, or clears it
+func (v *IssuesTableView) SetNotice(notice string) {
+	v.notice = notice
 }
 
 // SetSize updates the view dimensions
@@ -78,7 +296,14 @@ func (v *IssuesTableView) SetSize(width, height int) {
 	v.height = height
 }
 
-// Cursor returns the current cursor position
+// SetCompact switches to the compact layout used when rendering inline, which
+// shows a window of rows around the cursor instead of the whole table
+func (v *IssuesTableView) SetCompact(compact bool) {
+	v.compact = compact
+}
+
+// Cursor returns the current cursor position, or -1 if the search hides
+// every row
 func (v *IssuesTableView) Cursor() int {
 	return v.cursor
 }
@@ -91,9 +316,40 @@ func (v *IssuesTableView) SelectedIssue() *IssueItem {
 	return nil
 }
 
-// IssueCount returns the total number of issues
+// IssueAt returns the issue at index, or nil if index is out of range
+func (v *IssuesTableView) IssueAt(index int) *IssueItem {
+	if index >= 0 && index < len(v.issues) {
+		return &v.issues[index]
+	}
+	return nil
+}
+
+// IssueCount returns the total number of issues, not counting suggestions
 func (v *IssuesTableView) IssueCount() int {
-	return len(v.issues)
+	return len(v.issues) - v.SuggestionCount()
+}
+
+// SuggestionCount returns the number of suggestions not promoted to issues
+func (v *IssuesTableView) SuggestionCount() int {
+	count := 0
+	for _, item := range v.issues {
+		if item.Suggestion {
+			count++
+		}
+	}
+	return count
+}
+
+// PendingFixes returns the indices of issues with an available fix that has
+// neither been applied nor rejected by verification
+func (v *IssuesTableView) PendingFixes() []int {
+	var pending []int
+	for i, item := range v.issues {
+		if !item.Suggestion && !item.Fixed && !item.Rejected && !item.WontFix && item.Issue.Fix != nil && item.Issue.Fix.Available {
+			pending = append(pending, i)
+		}
+	}
+	return pending
 }
 
 // Init initializes the view
@@ -101,40 +357,70 @@ func (v *IssuesTableView) Init() tea.Cmd {
 	return nil
 }
 
-// Update handles key messages for navigation
+// Update handles key messages for navigation among the shown rows, and for
+// typing into the search prompt while it is open
 func (v *IssuesTableView) Update(msg tea.Msg) (*IssuesTableView, tea.Cmd) {
+	if v.searching {
+		return v.updateSearch(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		rows := v.shownRows()
+		if len(rows) == 0 {
+			return v, nil
+		}
+		pos := slices.Index(rows, v.cursor)
 		switch {
 		case key.Matches(msg, v.keys.Up):
-			if v.cursor > 0 {
-				v.cursor--
+			if pos > 0 {
+				v.cursor = rows[pos-1]
 			}
 		case key.Matches(msg, v.keys.Down):
-			if v.cursor < len(v.issues)-1 {
-				v.cursor++
+			if pos < len(rows)-1 {
+				v.cursor = rows[pos+1]
 			}
 		case key.Matches(msg, v.keys.Home):
-			v.cursor = 0
+			v.cursor = rows[0]
 		case key.Matches(msg, v.keys.End):
-			v.cursor = len(v.issues) - 1
-			if v.cursor < 0 {
-				v.cursor = 0
-			}
+			v.cursor = rows[len(rows)-1]
 		}
 	}
 	return v, nil
 }
 
+// updateSearch handles keys while the search prompt is open. The table is
+// filtered as the search is typed; Enter keeps the search and closes the
+// prompt, and Esc clears it.
+func (v *IssuesTableView) updateSearch(msg tea.Msg) (*IssuesTableView, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, v.keys.Enter):
+			v.searching = false
+			v.search.Blur()
+			return v, nil
+		case key.Matches(msg, v.keys.Escape):
+			v.searching = false
+			v.search.Blur()
+			v.SetFilter("")
+			return v, nil
+		}
+	}
+	var cmd tea.Cmd
+	v.search, cmd = v.search.Update(msg)
+	v.SetFilter(v.search.Value())
+	return v, cmd
+}
+
 // View renders the issues table
 func (v *IssuesTableView) View() string {
 	var b strings.Builder
 
 	// Header with count and position
-	title := fmt.Sprintf("revi - Issues (%d found)", len(v.issues))
+	title := fmt.Sprintf("revi - Issues (%d found)", v.IssueCount())
+	rows := v.shownRows()
 	position := ""
-	if len(v.issues) > 0 {
-		position = fmt.Sprintf("[%d/%d]", v.cursor+1, len(v.issues))
+	if pos := slices.Index(rows, v.cursor); pos >= 0 {
+		position = fmt.Sprintf("[%d/%d]", pos+1, len(rows))
 	}
 
 	// Calculate spacing for right-aligned position
@@ -152,7 +438,7 @@ func (v *IssuesTableView) View() string {
 	b.WriteString("\n")
 
 	// Table header
-	header := fmt.Sprintf(" %-4s │ %-11s │ %-14s │ %-32s │ %s", "SEV", "MODE", "LOCATION", "SUMMARY", "FIX")
+	header := fmt.Sprintf("  %-4s │ %-11s │ %-14s │ %-32s │ %s", "SEV", "MODE", "LOCATION", "SUMMARY", "FIX")
 	b.WriteString(shared.TableHeaderStyle.Render(header))
 	b.WriteString("\n")
 	b.WriteString(shared.RenderDivider(headerWidth + 30))
@@ -161,17 +447,43 @@ func (v *IssuesTableView) View() string {
 	// Table rows
 	if len(v.issues) == 0 {
 		b.WriteString(" No issues found\n")
+	} else if len(rows) == 0 {
+		b.WriteString(" No issues match the search\n")
 	} else {
-		for i, item := range v.issues {
-			row := v.renderRow(i, item)
+		first, last := v.visibleRows(rows)
+		if first > 0 {
+			b.WriteString(shared.HelpDescStyle.Render(fmt.Sprintf(" ↑ %d more", first)))
+			b.WriteString("\n")
+		}
+		for _, i := range rows[first:last] {
+			row := v.renderRow(i, v.issues[i])
 			b.WriteString(row)
 			b.WriteString("\n")
 		}
+		if last < len(rows) {
+			b.WriteString(shared.HelpDescStyle.Render(fmt.Sprintf(" ↓ %d more", len(rows)-last)))
+			b.WriteString("\n")
+		}
 	}
 
 	b.WriteString(shared.RenderDivider(headerWidth + 30))
 	b.WriteString("\n")
 
+	// Search prompt, or the search the table is filtered by
+	if v.searching {
+		b.WriteString(" ")
+		b.WriteString(v.search.View())
+		b.WriteString("\n")
+	} else if v.filter != "" {
+		b.WriteString(shared.HelpKeyStyle.Render(fmt.Sprintf(" Filter: %s (%d of %d shown)", v.filter, len(rows), len(v.issues))))
+		b.WriteString("\n")
+	}
+
+	if v.notice != "" {
+		b.WriteString(shared.HelpDescStyle.Render(" " + v.notice))
+		b.WriteString("\n")
+	}
+
 	// Commit message preview (first line only) - only show when not blocked
 	if v.commitMessage != "" && !v.blocked {
 		firstLine := strings.Split(v.commitMessage, "\n")[0]
@@ -195,15 +507,53 @@ func (v *IssuesTableView) View() string {
 	}
 
 	// Help
+	if v.searching {
+		b.WriteString(shared.HelpKeyStyle.Render(shared.SearchHelp()))
+		return b.String()
+	}
+	help := shared.IssuesTableHelp()
 	if v.blocked {
-		b.WriteString(shared.HelpKeyStyle.Render(shared.IssuesTableHelpBlocked()))
-	} else {
-		b.WriteString(shared.HelpKeyStyle.Render(shared.IssuesTableHelp()))
+		help = shared.IssuesTableHelpBlocked()
+	}
+	if v.filter != "" {
+		help += shared.ClearSearchHelp()
+	} else if len(v.issues) > 0 {
+		help += shared.StartSearchHelp()
 	}
+	if selected := len(v.Selected()); selected > 0 {
+		b.WriteString(shared.HelpKeyStyle.Render(shared.BulkHelp(selected)))
+		b.WriteString("\n")
+	} else if v.IssueCount() > 0 {
+		help += shared.SelectHelp()
+	}
+	if v.SuggestionCount() > 0 {
+		help += shared.PromoteHelp()
+	}
+	if item := v.SelectedIssue(); item != nil && item.Fixed {
+		help += shared.UndoHelp()
+	}
+	if item := v.SelectedIssue(); item != nil && !item.Suggestion && !item.Fixed && !item.WontFix {
+		help += shared.TriageHelp()
+	}
+	if len(v.PendingFixes()) > 0 {
+		help += shared.ApplyAllHelp()
+	}
+	b.WriteString(shared.HelpKeyStyle.Render(help))
 
 	return b.String()
 }
 
+// visibleRows returns the range of the shown rows to render: all of them, or
+// in the compact layout a window of compactRows that keeps the cursor in view
+func (v *IssuesTableView) visibleRows(rows []int) (int, int) {
+	if !v.compact || len(rows) <= compactRows {
+		return 0, len(rows)
+	}
+	pos := max(slices.Index(rows, v.cursor), 0)
+	first := min(max(pos-compactRows/2, 0), len(rows)-compactRows)
+	return first, first + compactRows
+}
+
 // renderRow renders a single issue row
 func (v *IssuesTableView) renderRow(index int, item IssueItem) string {
 	isSelected := index == v.cursor
@@ -213,10 +563,18 @@ func (v *IssuesTableView) renderRow(index int, item IssueItem) string {
 	if isSelected {
 		marker = shared.SelectionMarker.Render(shared.SelectionChar)
 	}
+	check := " "
+	if item.Selected {
+		check = shared.SelectionMarker.Render(shared.CheckedChar)
+	}
 
 	// Severity
 	sevAbbrev := shared.SeverityAbbrev(item.Issue.Severity)
 	sevStyle := shared.SeverityStyle(item.Issue.Severity)
+	if item.Suggestion {
+		sevAbbrev = "SUG"
+		sevStyle = shared.HelpDescStyle
+	}
 	sev := sevStyle.Render(sevAbbrev)
 
 	// Mode
@@ -231,19 +589,41 @@ func (v *IssuesTableView) renderRow(index int, item IssueItem) string {
 
 	// Summary (truncated description)
 	summary := truncate(item.Issue.Description, 32)
+	badge := shared.AgreementBadge(item.Issue.Agreement)
+	if item.Issue.Baseline {
+		badge = strings.TrimSpace(badge + " [base]")
+	}
+	if n := len(item.Issue.AlsoReportedBy); n > 0 {
+		badge = strings.TrimSpace(fmt.Sprintf("%s [+%d]", badge, n))
+	}
+	if item.Issue.Speculative() {
+		badge = strings.TrimSpace(badge + " [?]")
+	}
+	if badge != "" {
+		summary = truncate(item.Issue.Description, 32-len(badge)-1) + " " + badge
+	}
 
 	// Fix indicator
 	var fixIndicator string
-	if item.Fixed {
+	if item.Promoting {
+		fixIndicator = shared.HelpDescStyle.Render("[...]")
+	} else if item.Suggestion {
+		fixIndicator = shared.HelpDescStyle.Render("-")
+	} else if item.Fixed {
 		fixIndicator = shared.StatusDoneStyle.Render("[FIXED]")
+	} else if item.WontFix {
+		fixIndicator = shared.FixUnavailableStyle.Render("[WON'T FIX]")
+	} else if item.Rejected {
+		fixIndicator = shared.FixUnavailableStyle.Render("[REJECTED]")
 	} else if item.Issue.Fix != nil && item.Issue.Fix.Available {
 		fixIndicator = shared.FixAvailableStyle.Render(shared.FixAvailableIndicator)
 	} else {
 		fixIndicator = shared.FixUnavailableStyle.Render(shared.FixUnavailableIndicator)
 	}
 
-	row := fmt.Sprintf("%s%-4s │ %-11s │ %-14s │ %-32s │ %s",
+	row := fmt.Sprintf("%s%s%-4s │ %-11s │ %-14s │ %-32s │ %s",
 		marker,
+		check,
 		sev,
 		modeName,
 		location,
@@ -1,10 +1,12 @@
 package views
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/buker/revi/internal/review"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // =============================================================================
@@ -149,3 +151,181 @@ func TestIssuesTableView_View_ShowsIssuesWhenBlocked(t *testing.T) {
 		t.Error("View() should show blocked message along with issues")
 	}
 }
+
+// =============================================================================
+// Tests for suggestions in the issues table
+// =============================================================================
+
+func TestIssuesTableView_SetIssues_ListsSuggestionsAfterIssues(t *testing.T) {
+	view := NewIssuesTableView()
+	view.SetIssues([]*review.Result{
+		{Mode: review.ModeStyle, Suggestions: []string{"rename x"}, Issues: []review.Issue{{Severity: "low", Description: "naming"}}},
+		{Mode: review.ModeErrors, Issues: []review.Issue{{Severity: "high", Description: "unchecked error"}}},
+	})
+
+	if view.IssueCount() != 2 || view.SuggestionCount() != 1 {
+		t.Fatalf("IssueCount() = %d, SuggestionCount() = %d, want 2 and 1", view.IssueCount(), view.SuggestionCount())
+	}
+	last := view.issues[len(view.issues)-1]
+	if !last.Suggestion || last.Issue.Description != "rename x" || last.Mode != review.ModeStyle {
+		t.Errorf("last item = %+v, want the style suggestion", last)
+	}
+
+	output := view.View()
+	if !strings.Contains(output, "2 found") {
+		t.Error("View() should count only issues in the title")
+	}
+	if !strings.Contains(output, "promote") {
+		t.Error("View() should offer promoting suggestions")
+	}
+
+	view.Promote(2, review.Issue{Severity: "medium", Description: "rename x to count"})
+	if view.IssueCount() != 3 || view.SuggestionCount() != 0 {
+		t.Errorf("after Promote() IssueCount() = %d, SuggestionCount() = %d, want 3 and 0", view.IssueCount(), view.SuggestionCount())
+	}
+	if strings.Contains(view.View(), "promote") {
+		t.Error("View() should not offer promoting without suggestions")
+	}
+}
+
+// =============================================================================
+// Tests for the compact layout
+// =============================================================================
+
+func TestIssuesTableView_Compact_ShowsWindowAroundCursor(t *testing.T) {
+	var issues []review.Issue
+	for i := 0; i < 20; i++ {
+		issues = append(issues, review.Issue{Severity: "low", Description: fmt.Sprintf("issue-%02d", i)})
+	}
+	view := NewIssuesTableView()
+	view.SetSize(100, 50)
+	view.SetCompact(true)
+	view.SetIssues([]*review.Result{{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: issues}})
+
+	output := view.View()
+	if !strings.Contains(output, "issue-00") || strings.Contains(output, "issue-08") {
+		t.Errorf("expected only the first %d rows, got:\n%s", compactRows, output)
+	}
+	if !strings.Contains(output, "↓ 12 more") {
+		t.Error("expected a count of the rows below the window")
+	}
+
+	for i := 0; i < 15; i++ {
+		view.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	output = view.View()
+	if !strings.Contains(output, "issue-15") || strings.Contains(output, "issue-10") {
+		t.Errorf("expected the window to follow the cursor, got:\n%s", output)
+	}
+	if !strings.Contains(output, "↑ 11 more") || !strings.Contains(output, "↓ 1 more") {
+		t.Errorf("expected counts above and below the window, got:\n%s", output)
+	}
+}
+
+func TestIssuesTableView_NotCompact_ShowsAllRows(t *testing.T) {
+	var issues []review.Issue
+	for i := 0; i < 20; i++ {
+		issues = append(issues, review.Issue{Severity: "low", Description: fmt.Sprintf("issue-%02d", i)})
+	}
+	view := NewIssuesTableView()
+	view.SetSize(100, 50)
+	view.SetIssues([]*review.Result{{Mode: review.ModeStyle, Status: review.StatusIssues, Issues: issues}})
+
+	output := view.View()
+	if !strings.Contains(output, "issue-19") || strings.Contains(output, "more") {
+		t.Errorf("expected every row without window markers, got:\n%s", output)
+	}
+}
+
+func TestIssuesTableView_Selection(t *testing.T) {
+	v := NewIssuesTableView()
+	v.SetIssues([]*review.Result{{
+		Mode:        review.ModeStyle,
+		Issues:      []review.Issue{{Severity: "low", Description: "a"}, {Severity: "low", Description: "b"}},
+		Suggestions: []string{"c"},
+	}})
+
+	v.ToggleSelected(1)
+	v.ToggleSelected(2) // Suggestions cannot be selected
+	if got := v.Selected(); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("Selected() = %v, want [1]", got)
+	}
+	if !strings.Contains(v.View(), "1 selected:") {
+		t.Error("View() should show the actions for the selection")
+	}
+
+	v.ToggleSelectAll()
+	if got := v.Selected(); len(got) != 2 {
+		t.Errorf("Selected() after select all = %v, want both issues", got)
+	}
+	v.ToggleSelectAll()
+	if got := v.Selected(); len(got) != 0 {
+		t.Errorf("Selected() after toggling all again = %v, want none", got)
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	item := IssueItem{
+		Mode:  review.ModeSecurity,
+		Issue: review.Issue{Severity: "medium", Description: "SQL built from user input", Location: "db/query.go:30"},
+	}
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{"sql", true},
+		{"SQL query.go", true},
+		{"mode:sec", true},
+		{"mode:style", false},
+		{"sev:med", true},
+		{"severity:high", false},
+		{"file:db/", true},
+		{"file:main.go", false},
+		{"sev:medium file:db missing", false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilter(item, tt.filter); got != tt.want {
+			t.Errorf("matchesFilter(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestIssuesTableView_FilterNavigatesShownRows(t *testing.T) {
+	v := NewIssuesTableView()
+	v.SetIssues([]*review.Result{{
+		Mode: review.ModeStyle,
+		Issues: []review.Issue{
+			{Severity: "low", Description: "a"},
+			{Severity: "high", Description: "b"},
+			{Severity: "low", Description: "c"},
+		},
+	}})
+
+	v.SetFilter("sev:low")
+	if v.Cursor() != 0 {
+		t.Fatalf("Cursor() = %d, want 0", v.Cursor())
+	}
+	v, _ = v.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if v.Cursor() != 2 {
+		t.Errorf("down should skip the hidden row, Cursor() = %d", v.Cursor())
+	}
+
+	v.ToggleSelectAll()
+	if got := v.Selected(); len(got) != 2 || got[0] != 0 || got[1] != 2 {
+		t.Errorf("select all should only select shown rows, Selected() = %v", got)
+	}
+
+	v.SetFilter("nothing matches")
+	if v.Cursor() != -1 || v.SelectedIssue() != nil {
+		t.Errorf("no row is shown, Cursor() = %d", v.Cursor())
+	}
+	if !strings.Contains(v.View(), "No issues match the search") {
+		t.Error("View() should say that no issues match")
+	}
+
+	v.SetFilter("")
+	if v.Cursor() != 0 {
+		t.Errorf("clearing the search should show every row, Cursor() = %d", v.Cursor())
+	}
+}
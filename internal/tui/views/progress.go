@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/buker/revi/internal/review"
 	"github.com/buker/revi/internal/tui/shared"
@@ -15,17 +16,25 @@ import (
 
 // ReviewStatus tracks the status and timing of a single review
 type ReviewStatus struct {
-	Mode          review.Mode
-	Status        review.Status
-	StartTime     time.Time
-	EndTime       time.Time
-	Issues        int
-	StreamPreview string // Preview of streaming content
+	Mode        review.Mode
+	Status      review.Status
+	StartTime   time.Time
+	EndTime     time.Time
+	Issues      int
+	StreamLines []string // Last lines of streamed output; the final one may be incomplete
+	Error       string   // Why the review failed, if it did
 }
 
+// DefaultStreamLines is the number of lines of streamed output shown for the
+// selected review
+const DefaultStreamLines = 6
+
+// maxStreamLineLen is the number of characters kept of each streamed line
+const maxStreamLineLen = 200
+
 // Duration returns the elapsed duration for this review
 func (rs *ReviewStatus) Duration() time.Duration {
-	if rs.Status == review.StatusPending {
+	if rs.Status == review.StatusPending || rs.StartTime.IsZero() {
 		return 0
 	}
 	if rs.Status == review.StatusRunning {
@@ -36,13 +45,25 @@ func (rs *ReviewStatus) Duration() time.Duration {
 
 // ProgressView displays the review progress table
 type ProgressView struct {
-	width    int
-	height   int
-	spinner  spinner.Model
-	reviews  map[review.Mode]*ReviewStatus
-	modes    []review.Mode
-	complete int
-	total    int
+	width     int
+	height    int
+	spinner   spinner.Model
+	reviews   map[review.Mode]*ReviewStatus
+	modes     []review.Mode
+	reasoning string
+	cursor    int
+	complete  int
+	finished  int // Completed reviews excluding skipped ones
+	total     int
+
+	authRequired bool   // Reviews are paused until the user logs in again
+	estimate     string // Estimated tokens and cost of the reviews
+	costConfirm  bool   // Reviews wait until the user confirms the estimated cost
+	compact      bool   // Inline layout without streaming output
+	streamLines  int    // Lines of streamed output shown, 0 to hide them
+
+	// payload describes what is sent to the AI, summary first; nil hides the panel
+	payload []string
 }
 
 // NewProgressView creates a new progress view
@@ -52,8 +73,9 @@ func NewProgressView() *ProgressView {
 	s.Style = lipgloss.NewStyle().Foreground(shared.ColorMedium)
 
 	return &ProgressView{
-		spinner: s,
-		reviews: make(map[review.Mode]*ReviewStatus),
+		spinner:     s,
+		reviews:     make(map[review.Mode]*ReviewStatus),
+		streamLines: DefaultStreamLines,
 	}
 }
 
@@ -61,6 +83,7 @@ func NewProgressView() *ProgressView {
 func (v *ProgressView) SetModes(modes []review.Mode) {
 	v.modes = modes
 	v.total = len(modes)
+	v.cursor = 0
 	v.reviews = make(map[review.Mode]*ReviewStatus)
 	for _, mode := range modes {
 		v.reviews[mode] = &ReviewStatus{
@@ -70,6 +93,38 @@ func (v *ProgressView) SetModes(modes []review.Mode) {
 	}
 }
 
+// SetReasoning sets the detector's explanation for the selected modes
+func (v *ProgressView) SetReasoning(reasoning string) {
+	v.reasoning = reasoning
+}
+
+// AddMode queues an additional mode to track as pending.
+// Returns false if the mode is already being tracked.
+func (v *ProgressView) AddMode(mode review.Mode) bool {
+	if _, ok := v.reviews[mode]; ok {
+		return false
+	}
+	v.modes = append(v.modes, mode)
+	v.reviews[mode] = &ReviewStatus{
+		Mode:   mode,
+		Status: review.StatusPending,
+	}
+	v.total++
+	return true
+}
+
+// HasMode returns true if the mode is part of the current run
+func (v *ProgressView) HasMode(mode review.Mode) bool {
+	_, ok := v.reviews[mode]
+	return ok
+}
+
+// CanChangeModes returns true while modes may still be added or removed,
+// which is until the first review result comes in
+func (v *ProgressView) CanChangeModes() bool {
+	return v.finished == 0
+}
+
 // SetReviewStarted marks a review as started
 func (v *ProgressView) SetReviewStarted(mode review.Mode) {
 	if rs, ok := v.reviews[mode]; ok {
@@ -85,19 +140,128 @@ func (v *ProgressView) SetReviewComplete(mode review.Mode, status review.Status,
 		rs.EndTime = time.Now()
 		rs.Issues = issues
 		v.complete++
+		if status != review.StatusSkipped {
+			v.finished++
+		}
 	}
 }
 
-// SetStreamContent updates the streaming preview for a mode
-func (v *ProgressView) SetStreamContent(mode review.Mode, content string) {
+// SetReviewFailed records why a review failed. raw, the response that could
+// not be read, replaces its streamed output so it can still be read.
+func (v *ProgressView) SetReviewFailed(mode review.Mode, reason, raw string) {
+	rs, ok := v.reviews[mode]
+	if !ok {
+		return
+	}
+	rs.Error = reason
+	if raw != "" {
+		rs.StreamLines = nil
+		v.SetStreamContent(mode, raw)
+	}
+}
+
+// SetReviewPaused returns a review to pending because it was paused before finishing
+func (v *ProgressView) SetReviewPaused(mode review.Mode) {
 	if rs, ok := v.reviews[mode]; ok {
-		// Append to existing preview, keeping last N characters for display
-		rs.StreamPreview += content
-		maxLen := 50 // Keep preview short to fit in view
-		if len(rs.StreamPreview) > maxLen {
-			rs.StreamPreview = rs.StreamPreview[len(rs.StreamPreview)-maxLen:]
+		rs.Status = review.StatusPending
+		rs.StartTime = time.Time{}
+		rs.StreamLines = nil
+		rs.Error = ""
+	}
+}
+
+// SetAuthRequired sets whether reviews are paused waiting for re-authentication
+func (v *ProgressView) SetAuthRequired(required bool) {
+	v.authRequired = required
+}
+
+// AuthRequired returns true while reviews are paused waiting for re-authentication
+func (v *ProgressView) AuthRequired() bool {
+	return v.authRequired
+}
+
+// SetCostEstimate sets the estimated cost of the reviews, and whether they wait
+// for the user to confirm it before starting
+func (v *ProgressView) SetCostEstimate(estimate string, confirm bool) {
+	v.estimate = estimate
+	v.costConfirm = confirm
+}
+
+// SetPayload sets the lines describing what is sent to the AI, starting with
+// a summary, which is all the compact layout shows. nil hides the panel.
+func (v *ProgressView) SetPayload(lines []string) {
+	v.payload = lines
+}
+
+// SetCostConfirmed records that the user confirmed the estimated cost
+func (v *ProgressView) SetCostConfirmed() {
+	v.costConfirm = false
+}
+
+// CostConfirmRequired returns true while reviews wait for the user to confirm
+// their estimated cost
+func (v *ProgressView) CostConfirmRequired() bool {
+	return v.costConfirm
+}
+
+// SetStreamContent appends streamed output for a mode, keeping its last lines
+func (v *ProgressView) SetStreamContent(mode review.Mode, content string) {
+	rs, ok := v.reviews[mode]
+	if !ok {
+		return
+	}
+	keep := max(v.streamLines, 1)
+	for i, part := range strings.Split(content, "\n") {
+		if i == 0 && len(rs.StreamLines) > 0 {
+			rs.StreamLines[len(rs.StreamLines)-1] += part
+		} else {
+			rs.StreamLines = append(rs.StreamLines, part)
+		}
+		last := &rs.StreamLines[len(rs.StreamLines)-1]
+		if len(*last) > maxStreamLineLen {
+			*last = (*last)[len(*last)-maxStreamLineLen:]
 		}
 	}
+	if len(rs.StreamLines) > keep {
+		rs.StreamLines = rs.StreamLines[len(rs.StreamLines)-keep:]
+	}
+}
+
+// SetStreamLines sets how many lines of streamed output are shown for the
+// selected review; 0 hides streamed output
+func (v *ProgressView) SetStreamLines(n int) {
+	v.streamLines = max(n, 0)
+}
+
+// SelectPrev moves the selection cursor to the previous mode
+func (v *ProgressView) SelectPrev() {
+	if v.cursor > 0 {
+		v.cursor--
+	}
+}
+
+// SelectNext moves the selection cursor to the next mode
+func (v *ProgressView) SelectNext() {
+	if v.cursor < len(v.modes)-1 {
+		v.cursor++
+	}
+}
+
+// SelectedMode returns the currently selected mode, or an empty mode if none
+func (v *ProgressView) SelectedMode() review.Mode {
+	if v.cursor >= 0 && v.cursor < len(v.modes) {
+		return v.modes[v.cursor]
+	}
+	return ""
+}
+
+// IsCancellable returns true if the mode has not finished yet and can be skipped
+func (v *ProgressView) IsCancellable(mode review.Mode) bool {
+	rs, ok := v.reviews[mode]
+	if !ok {
+		return false
+	}
+	return rs.Status == review.StatusPending || rs.Status == review.StatusRunning
 }
 
 // IsComplete returns true if all reviews are done
@@ -111,6 +275,12 @@ func (v *ProgressView) SetSize(width, height int) {
 	v.height = height
 }
 
+// SetCompact switches to the compact layout used when rendering inline,
+// which leaves out streamed output so the view keeps a stable height
+func (v *ProgressView) SetCompact(compact bool) {
+	v.compact = compact
+}
+
 // Init initializes the view
 func (v *ProgressView) Init() tea.Cmd {
 	return v.spinner.Tick
@@ -130,6 +300,30 @@ func (v *ProgressView) View() string {
 	// Header
 	b.WriteString(shared.TitleStyle.Render("revi - AI Code Review"))
 	b.WriteString("\n")
+	if v.reasoning != "" {
+		for _, line := range strings.Split(wordWrap("Detected: "+v.reasoning, 52), "\n") {
+			b.WriteString(shared.HelpDescStyle.Render(" " + line))
+			b.WriteString("\n")
+		}
+	}
+	if v.estimate != "" {
+		for _, line := range strings.Split(wordWrap("Estimated: "+v.estimate, 52), "\n") {
+			b.WriteString(shared.HelpDescStyle.Render(" " + line))
+			b.WriteString("\n")
+		}
+	}
+	if len(v.payload) > 0 {
+		payload := v.payload
+		if v.compact {
+			payload = payload[:1]
+		}
+		b.WriteString(shared.HelpDescStyle.Render(" Payload: " + payload[0]))
+		b.WriteString("\n")
+		for _, line := range payload[1:] {
+			b.WriteString(shared.HelpDescStyle.Render("   " + line))
+			b.WriteString("\n")
+		}
+	}
 	b.WriteString(shared.RenderDivider(54))
 	b.WriteString("\n")
 
@@ -141,7 +335,7 @@ func (v *ProgressView) View() string {
 	b.WriteString("\n")
 
 	// Table rows
-	for _, mode := range v.modes {
+	for i, mode := range v.modes {
 		rs := v.reviews[mode]
 		if rs == nil {
 			continue
@@ -150,6 +344,12 @@ func (v *ProgressView) View() string {
 		info := review.GetModeInfo(mode)
 		modeName := truncate(info.Name, 14)
 
+		// Selection marker
+		marker := " "
+		if i == v.cursor {
+			marker = shared.SelectionMarker.Render(shared.SelectionChar)
+		}
+
 		// Status with indicator
 		var statusStr string
 		var statusStyle lipgloss.Style
@@ -166,6 +366,12 @@ func (v *ProgressView) View() string {
 		case review.StatusFailed:
 			statusStr = shared.StatusIndicatorFailed + " Failed"
 			statusStyle = shared.StatusFailedStyle
+		case review.StatusSkipped:
+			statusStr = shared.StatusIndicatorSkipped + " Skipped"
+			statusStyle = shared.StatusPendingStyle
+		case review.StatusTimedOut:
+			statusStr = shared.StatusIndicatorFailed + " Timed out"
+			statusStyle = shared.StatusFailedStyle
 		default:
 			statusStr = string(rs.Status)
 			statusStyle = shared.StatusPendingStyle
@@ -183,13 +389,14 @@ func (v *ProgressView) View() string {
 		// Issues count
 		var issuesStr string
 		switch rs.Status {
-		case review.StatusPending, review.StatusRunning, review.StatusFailed:
+		case review.StatusPending, review.StatusRunning, review.StatusFailed, review.StatusSkipped, review.StatusTimedOut:
 			issuesStr = "-"
 		default:
 			issuesStr = fmt.Sprintf("%d", rs.Issues)
 		}
 
-		row := fmt.Sprintf(" %-14s │ %-11s │ %-8s │ %s",
+		row := fmt.Sprintf("%s%-14s │ %-11s │ %-8s │ %s",
+			marker,
 			modeName,
 			statusStyle.Render(padRight(statusStr, 11)),
 			durationStr,
@@ -197,28 +404,64 @@ func (v *ProgressView) View() string {
 		)
 		b.WriteString(row)
 		b.WriteString("\n")
-
-		// Show streaming preview if running and has content
-		if rs.Status == review.StatusRunning && rs.StreamPreview != "" {
-			preview := sanitizeStreamPreview(rs.StreamPreview)
-			if preview != "" {
-				previewStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
-				b.WriteString(previewStyle.Render("   " + truncate(preview, 48)))
-				b.WriteString("\n")
-			}
-		}
 	}
 
 	// Footer
 	b.WriteString(shared.RenderDivider(54))
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf(" Progress: %d/%d complete\n", v.complete, v.total))
-	b.WriteString("\n")
+	if !v.compact {
+		b.WriteString(v.renderStream())
+	}
+	if !v.compact {
+		b.WriteString("\n")
+	}
+	if v.authRequired {
+		b.WriteString(shared.HighSeverityStyle.Render(" ⚠ Claude authentication expired; remaining reviews are paused"))
+		b.WriteString("\n")
+		b.WriteString(shared.HelpDescStyle.Render(" Run `claude login` in another terminal, then press r to resume"))
+		b.WriteString("\n\n")
+		b.WriteString(shared.HelpKeyStyle.Render(shared.ProgressAuthHelp()))
+		return b.String()
+	}
+	if v.costConfirm {
+		b.WriteString(shared.MediumSeverityStyle.Render(" ⚠ The estimated cost is above the confirmation threshold"))
+		b.WriteString("\n")
+		b.WriteString(shared.HelpDescStyle.Render(" Reviews start once you confirm"))
+		b.WriteString("\n\n")
+		b.WriteString(shared.HelpKeyStyle.Render(shared.ProgressCostHelp()))
+		return b.String()
+	}
+	if v.CanChangeModes() {
+		b.WriteString(v.renderModeToggles())
+		b.WriteString("\n")
+	}
 	b.WriteString(shared.HelpKeyStyle.Render(shared.ProgressHelp()))
 
 	return b.String()
 }
 
+// renderModeToggles renders the numbered list of modes that can be toggled
+func (v *ProgressView) renderModeToggles() string {
+	var b strings.Builder
+	for i, mode := range review.AllModes() {
+		if i%3 == 0 {
+			b.WriteString(" ")
+		}
+		// Enabled modes are marked with a check as well as colored
+		label := fmt.Sprintf("[%d] %s", i+1, review.GetModeInfo(mode).Name)
+		if v.HasMode(mode) {
+			b.WriteString(shared.StatusDoneStyle.Render(padRight(shared.StatusIndicatorDone+" "+label, 20)))
+		} else {
+			b.WriteString(shared.StatusPendingStyle.Render(padRight("  "+label, 20)))
+		}
+		if i%3 == 2 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
 // truncate truncates a string to max length
 func truncate(s string, max int) string {
 	if len(s) <= max {
@@ -259,11 +502,46 @@ func visibleLen(s string) int {
 	return count
 }
 
-// sanitizeStreamPreview cleans up streaming content for display
-func sanitizeStreamPreview(s string) string {
-	// Replace newlines with spaces for single-line display
-	s = strings.ReplaceAll(s, "\n", " ")
+// renderStream renders the last lines streamed by the selected review, after
+// the reason it failed if it did, or an empty string if there is neither
+func (v *ProgressView) renderStream() string {
+	rs := v.reviews[v.SelectedMode()]
+	if v.streamLines == 0 || rs == nil {
+		return ""
+	}
+	lines := rs.StreamLines
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		// Output ending in a newline has not started its next line yet
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 && rs.Error == "" {
+		return ""
+	}
+
+	width := 72
+	if v.width > 0 && v.width-4 < width {
+		width = max(v.width-4, 10)
+	}
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+
+	var b strings.Builder
+	b.WriteString("\n")
+	b.WriteString(shared.HeaderStyle.Render(fmt.Sprintf(" %s output:", review.GetModeInfo(rs.Mode).Name)))
+	b.WriteString("\n")
+	if rs.Error != "" {
+		b.WriteString(shared.StatusFailedStyle.Render("   " + truncate(sanitizeStreamLine(rs.Error), width)))
+		b.WriteString("\n")
+	}
+	for _, line := range lines {
+		b.WriteString(lineStyle.Render("   " + truncate(sanitizeStreamLine(line), width)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// sanitizeStreamLine cleans up a line of streamed output for display
+func sanitizeStreamLine(s string) string {
 	s = strings.ReplaceAll(s, "\r", "")
-	s = strings.TrimSpace(s)
-	return s
+	s = strings.ReplaceAll(s, "\t", "    ")
+	return strings.TrimRightFunc(s, unicode.IsSpace)
 }
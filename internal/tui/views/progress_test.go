@@ -0,0 +1,132 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/buker/revi/internal/review"
+)
+
+// =============================================================================
+// Tests for ProgressView streamed output
+// =============================================================================
+
+func TestProgressView_SetStreamContent_KeepsLastLines(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+	view.SetStreamLines(3)
+
+	view.SetStreamContent(review.ModeSecurity, "one\ntw")
+	view.SetStreamContent(review.ModeSecurity, "o\nthree\nfour\nfi")
+
+	got := view.reviews[review.ModeSecurity].StreamLines
+	want := []string{"three", "four", "fi"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("StreamLines = %q, want %q", got, want)
+	}
+}
+
+func TestProgressView_SetStreamContent_JoinsLinesAcrossChunks(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+
+	view.SetStreamContent(review.ModeSecurity, "checking ")
+	view.SetStreamContent(review.ModeSecurity, "inputs\n")
+
+	got := view.reviews[review.ModeSecurity].StreamLines
+	if len(got) != 2 || got[0] != "checking inputs" || got[1] != "" {
+		t.Errorf("StreamLines = %q, want [\"checking inputs\" \"\"]", got)
+	}
+}
+
+func TestProgressView_SetStreamContent_UnknownModeIgnored(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+
+	view.SetStreamContent(review.ModeStyle, "text")
+
+	if view.HasMode(review.ModeStyle) {
+		t.Error("SetStreamContent() should not start tracking an unknown mode")
+	}
+}
+
+func TestProgressView_View_ShowsSelectedModeOutput(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+	view.AddMode(review.ModeStyle)
+	view.SetStreamContent(review.ModeSecurity, "security thoughts\n")
+	view.SetStreamContent(review.ModeStyle, "style thoughts\n")
+
+	out := view.View()
+	if !strings.Contains(out, "security thoughts") {
+		t.Errorf("View() should show the selected mode's output, got:\n%s", out)
+	}
+	if strings.Contains(out, "style thoughts") {
+		t.Errorf("View() should not show output of other modes, got:\n%s", out)
+	}
+
+	view.SelectNext()
+	if out := view.View(); !strings.Contains(out, "style thoughts") {
+		t.Errorf("View() should follow the selection, got:\n%s", out)
+	}
+}
+
+func TestProgressView_SetReviewFailed_ShowsReasonAndResponse(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+	view.SetStreamContent(review.ModeSecurity, "partial stream")
+
+	view.SetReviewComplete(review.ModeSecurity, review.StatusFailed, 0)
+	view.SetReviewFailed(review.ModeSecurity, "failed to parse review result", "I found one issue:\nthe query is unsafe")
+
+	out := view.View()
+	for _, want := range []string{"failed to parse review result", "the query is unsafe"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("View() should show %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "partial stream") {
+		t.Errorf("View() should replace the streamed output with the response, got:\n%s", out)
+	}
+}
+
+func TestProgressView_View_HidesOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*ProgressView)
+	}{
+		{"zero lines", func(v *ProgressView) { v.SetStreamLines(0) }},
+		{"compact", func(v *ProgressView) { v.SetCompact(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := NewProgressView()
+			view.AddMode(review.ModeSecurity)
+			tt.setup(view)
+			view.SetStreamContent(review.ModeSecurity, "hidden thoughts\n")
+
+			if out := view.View(); strings.Contains(out, "hidden thoughts") {
+				t.Errorf("View() should not show streamed output, got:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestProgressView_View_ShowsPayload(t *testing.T) {
+	view := NewProgressView()
+	view.AddMode(review.ModeSecurity)
+	if out := view.View(); strings.Contains(out, "Payload:") {
+		t.Errorf("View() should not show a payload panel by default, got:\n%s", out)
+	}
+
+	view.SetPayload([]string{"1 file(s), 120 B of diff", "  main.go: 1 hunk(s), 120 B"})
+	if out := view.View(); !strings.Contains(out, "Payload: 1 file(s), 120 B of diff") || !strings.Contains(out, "main.go: 1 hunk(s)") {
+		t.Errorf("View() should show the payload panel, got:\n%s", out)
+	}
+
+	view.SetCompact(true)
+	if out := view.View(); !strings.Contains(out, "Payload: 1 file(s)") || strings.Contains(out, "main.go") {
+		t.Errorf("compact View() should show only the payload summary, got:\n%s", out)
+	}
+}
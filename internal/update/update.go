@@ -0,0 +1,104 @@
+// Package update checks GitHub releases for a newer version of revi.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ReleasesURL is the GitHub API endpoint for the latest revi release.
+const ReleasesURL = "https://api.github.com/repos/buker/revi/releases/latest"
+
+// Status reports the latest release and whether it is newer than the running version.
+type Status struct {
+	Latest    string `json:"latest"`    // Tag of the latest release
+	URL       string `json:"url"`       // Release page of the latest release
+	Available bool   `json:"available"` // Whether Latest is newer than the running version
+}
+
+// release is the subset of the GitHub release API response we use
+type release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Check fetches the latest release from url and compares it with current.
+// Development builds (versions that are not vX.Y.Z) never report an update.
+func Check(ctx context.Context, client *http.Client, url, current string) (*Status, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch latest release: %s", resp.Status)
+	}
+
+	var rel release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return nil, fmt.Errorf("failed to parse latest release: %w", err)
+	}
+	if rel.TagName == "" {
+		return nil, fmt.Errorf("latest release has no tag")
+	}
+
+	return &Status{
+		Latest:    rel.TagName,
+		URL:       rel.HTMLURL,
+		Available: Newer(rel.TagName, current),
+	}, nil
+}
+
+// Newer reports whether version latest is newer than current. Both are
+// compared as vMAJOR.MINOR.PATCH; a pre-release sorts before its release.
+// Returns false if either version cannot be parsed.
+func Newer(latest, current string) bool {
+	l, lPre, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	c, cPre, ok := parse(current)
+	if !ok {
+		return false
+	}
+
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	// Same version number: a release is newer than its pre-release
+	return lPre == "" && cPre != ""
+}
+
+// parse splits a version like v1.2.3-rc.1 into its numbers and pre-release suffix
+func parse(version string) ([3]int, string, bool) {
+	var nums [3]int
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, pre, _ := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return nums, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nums, "", false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
@@ -0,0 +1,69 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// =============================================================================
+// Tests for Newer
+// =============================================================================
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2.0.0", "1.9.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.1.0", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.1", "v1.2.0", false},
+		{"v1.2.0", "dev", false},
+		{"v1.2.0", "v1.1.0-3-gabc1234-dirty", true},
+		{"latest", "v1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Tests for Check
+// =============================================================================
+
+func TestCheck_ReportsNewerRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v1.3.0","html_url":"https://github.com/buker/revi/releases/tag/v1.3.0"}`))
+	}))
+	defer server.Close()
+
+	status, err := Check(context.Background(), server.Client(), server.URL, "v1.2.0")
+	if err != nil {
+		t.Fatalf("Check() failed: %v", err)
+	}
+	if status.Latest != "v1.3.0" || !status.Available {
+		t.Errorf("Check() = %+v, want v1.3.0 available", status)
+	}
+	if status.URL == "" {
+		t.Error("expected release URL")
+	}
+}
+
+func TestCheck_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := Check(context.Background(), server.Client(), server.URL, "v1.2.0"); err == nil {
+		t.Error("expected error for non-200 response")
+	}
+}